@@ -0,0 +1,378 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+// エクスポートするZIPファイルの名前。
+const exportFileName = "account-book-export.zip"
+
+// アカウント削除用のRequestのpayload。
+// 誤操作や乗っ取りに備え、パスワードの再入力を求める。
+type deleteAccountRequest struct {
+	Password string `json:"password" binding:"required" log:"secret"`
+}
+
+// エクスポートするセッションの構造体。
+type exportSession struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// エクスポートする支出の構造体。ゴミ箱にある支出も含める。
+type exportExpense struct {
+	expenseResponse
+	// ゴミ箱にない場合は null。
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// エクスポートするレシートの構造体。ゴミ箱にあるレシートも含める。
+type exportReceipt struct {
+	ID        int64               `json:"id"`
+	StoreName string              `json:"store_name"`
+	Items     []exportReceiptItem `json:"items"`
+	// ゴミ箱にない場合は null。
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// エクスポートするレシートの明細の構造体。
+type exportReceiptItem struct {
+	FoodContentID int64   `json:"food_content_id"`
+	Name          string  `json:"name"`
+	Amount        int64   `json:"amount"`
+	Calories      float32 `json:"calories"`
+	Lipid         float32 `json:"lipid"`
+	Carbohydrate  float32 `json:"carbohydrate"`
+	Protein       float32 `json:"protein"`
+}
+
+// エクスポートする送金の構造体。
+type exportTransfer struct {
+	ID         int64     `json:"id"`
+	FromUserID int64     `json:"from_user_id"`
+	ToUserID   int64     `json:"to_user_id"`
+	Amount     int64     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// 自分の個人データを、JSONとCSVを含むZIPファイルとしてエクスポートするエンドポイント。
+// ゴミ箱にある支出・レシートも、完全に削除されるまでは個人データとして含める。
+func (server *Server) exportAccount(c *gin.Context) {
+	userID := authUserID(c)
+
+	user, err := server.store.GetUserByID(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	sessions, err := server.store.ListUserSessions(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListUserSessions: %w", err))
+		return
+	}
+	expenses, err := server.store.ListExportExpenses(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListExportExpenses: %w", err))
+		return
+	}
+	receiptContents, err := server.store.ListUserFoodReceiptContents(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListUserFoodReceiptContents: %w", err))
+		return
+	}
+	transfers, err := server.store.ListUserTransfers(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListUserTransfers: %w", err))
+		return
+	}
+
+	data, err := buildExportZip(user, sessions, expenses, receiptContents, transfers)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to buildExportZip: %w", err))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFileName))
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// エクスポートするデータから、ZIPファイルを作成する。
+// 機械で扱いやすいJSONと、表計算ソフトで開きやすいCSVの両方を含める。
+func buildExportZip(
+	user db.User,
+	sessions []db.Session,
+	expenses []db.ListExportExpensesRow,
+	receiptContents []db.ListUserFoodReceiptContentsRow,
+	transfers []db.Transfer,
+) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	if err := writeZipJSON(zw, "profile.json", newUserResponse(user)); err != nil {
+		return nil, err
+	}
+
+	exportSessions := []exportSession{}
+	sessionRows := [][]string{}
+	for _, s := range sessions {
+		exportSessions = append(exportSessions, exportSession{
+			ID:        s.ID.String(),
+			UserAgent: s.UserAgent,
+			ClientIp:  s.ClientIp,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+		})
+		sessionRows = append(sessionRows, []string{
+			s.ID.String(),
+			s.UserAgent,
+			s.ClientIp,
+			formatExportTime(s.CreatedAt),
+			formatExportTime(s.ExpiresAt),
+		})
+	}
+	if err := writeZipJSON(zw, "sessions.json", exportSessions); err != nil {
+		return nil, err
+	}
+	if err := writeZipCSV(zw, "sessions.csv", []string{"id", "user_agent", "client_ip", "created_at", "expires_at"}, sessionRows); err != nil {
+		return nil, err
+	}
+
+	exportExpenses := []exportExpense{}
+	expenseRows := [][]string{}
+	for _, e := range expenses {
+		expense := exportExpense{
+			expenseResponse: newExpenseResponse(db.ListExpensesRow{
+				ID:         e.ID,
+				UserID:     e.UserID,
+				CategoryID: e.CategoryID,
+				Amount:     e.Amount,
+				StoreName:  e.StoreName,
+				Comment:    e.Comment,
+				CreatedAt:  e.CreatedAt,
+				Version:    e.Version,
+			}),
+		}
+		if e.DeletedAt.Valid {
+			expense.DeletedAt = &e.DeletedAt.Time
+		}
+		exportExpenses = append(exportExpenses, expense)
+		expenseRows = append(expenseRows, []string{
+			strconv.FormatInt(expense.ID, 10),
+			strconv.FormatInt(expense.CategoryID, 10),
+			strconv.FormatInt(expense.Amount, 10),
+			expense.StoreName,
+			expense.Comment,
+			formatExportTime(expense.CreatedAt),
+			formatExportNullTime(e.DeletedAt),
+		})
+	}
+	if err := writeZipJSON(zw, "expenses.json", exportExpenses); err != nil {
+		return nil, err
+	}
+	if err := writeZipCSV(zw, "expenses.csv", []string{"id", "category_id", "amount", "store_name", "comment", "created_at", "deleted_at"}, expenseRows); err != nil {
+		return nil, err
+	}
+
+	// 明細の行をレシートごとにまとめる（クエリでレシートの順に並べている）。
+	// 明細のないレシートは、明細の列が空の1行になる。
+	exportReceipts := []exportReceipt{}
+	receiptRows := [][]string{}
+	for _, r := range receiptContents {
+		if len(exportReceipts) == 0 || exportReceipts[len(exportReceipts)-1].ID != r.FoodReceiptID {
+			receipt := exportReceipt{
+				ID:        r.FoodReceiptID,
+				StoreName: r.StoreName,
+				Items:     []exportReceiptItem{},
+			}
+			if r.DeletedAt.Valid {
+				receipt.DeletedAt = &r.DeletedAt.Time
+			}
+			exportReceipts = append(exportReceipts, receipt)
+		}
+		if !r.FoodContentID.Valid {
+			receiptRows = append(receiptRows, []string{
+				strconv.FormatInt(r.FoodReceiptID, 10),
+				r.StoreName,
+				"", "", "", "", "", "", "",
+				formatExportNullTime(r.DeletedAt),
+			})
+			continue
+		}
+		receipt := &exportReceipts[len(exportReceipts)-1]
+		item := exportReceiptItem{
+			FoodContentID: r.FoodContentID.Int64,
+			Name:          r.Name.String,
+			Amount:        r.Amount.Int64,
+			Calories:      float32(r.Calories.Float64),
+			Lipid:         float32(r.Lipid.Float64),
+			Carbohydrate:  float32(r.Carbohydrate.Float64),
+			Protein:       float32(r.Protein.Float64),
+		}
+		receipt.Items = append(receipt.Items, item)
+		receiptRows = append(receiptRows, []string{
+			strconv.FormatInt(r.FoodReceiptID, 10),
+			r.StoreName,
+			strconv.FormatInt(item.FoodContentID, 10),
+			item.Name,
+			strconv.FormatInt(item.Amount, 10),
+			formatExportFloat(item.Calories),
+			formatExportFloat(item.Lipid),
+			formatExportFloat(item.Carbohydrate),
+			formatExportFloat(item.Protein),
+			formatExportNullTime(r.DeletedAt),
+		})
+	}
+	if err := writeZipJSON(zw, "receipts.json", exportReceipts); err != nil {
+		return nil, err
+	}
+	receiptHeader := []string{"receipt_id", "store_name", "food_content_id", "name", "amount", "calories", "lipid", "carbohydrate", "protein", "deleted_at"}
+	if err := writeZipCSV(zw, "receipts.csv", receiptHeader, receiptRows); err != nil {
+		return nil, err
+	}
+
+	exportTransfers := []exportTransfer{}
+	transferRows := [][]string{}
+	for _, t := range transfers {
+		exportTransfers = append(exportTransfers, exportTransfer{
+			ID:         t.ID,
+			FromUserID: t.FromUserID,
+			ToUserID:   t.ToUserID,
+			Amount:     t.Amount,
+			CreatedAt:  t.CreatedAt,
+		})
+		transferRows = append(transferRows, []string{
+			strconv.FormatInt(t.ID, 10),
+			strconv.FormatInt(t.FromUserID, 10),
+			strconv.FormatInt(t.ToUserID, 10),
+			strconv.FormatInt(t.Amount, 10),
+			formatExportTime(t.CreatedAt),
+		})
+	}
+	if err := writeZipJSON(zw, "transfers.json", exportTransfers); err != nil {
+		return nil, err
+	}
+	if err := writeZipCSV(zw, "transfers.csv", []string{"id", "from_user_id", "to_user_id", "amount", "created_at"}, transferRows); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeZipCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatExportTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// ゴミ箱にない場合は空文字にする。
+func formatExportNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return formatExportTime(t.Time)
+}
+
+func formatExportFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+// アカウントの削除を予約するエンドポイント。
+// 猶予期間が過ぎるまでは取り消すことができ、過ぎるとバックグラウンドで個人データを削除する。
+func (server *Server) deleteAccount(c *gin.Context) {
+	var req deleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	user, err := server.store.GetUserByID(c, authUserID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	if err := util.CheckPassword(req.Password, user.Password); err != nil {
+		requestLogger(c).Warnf("invalid password for account deletion of user [%d]", user.ID)
+		abortWithError(c, http.StatusBadRequest, codeInvalidPassword, "error.invalid_password")
+		return
+	}
+
+	arg := db.UpdateUserDeletionScheduledAtParams{
+		DeletionScheduledAt: sql.NullTime{
+			Time:  time.Now().Add(server.config.AccountDeletionGracePeriod),
+			Valid: true,
+		},
+		ID: user.ID,
+	}
+	user, err = server.store.UpdateUserDeletionScheduledAt(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserDeletionScheduledAt: %w", err))
+		return
+	}
+
+	requestLogger(c).Infof("deletion of user [%d] was scheduled at %s", user.ID, user.DeletionScheduledAt.Time)
+	c.JSON(http.StatusAccepted, newUserResponse(user))
+}
+
+// 予約したアカウントの削除を取り消すエンドポイント。
+func (server *Server) cancelAccountDeletion(c *gin.Context) {
+	arg := db.UpdateUserDeletionScheduledAtParams{
+		DeletionScheduledAt: sql.NullTime{},
+		ID:                  authUserID(c),
+	}
+	user, err := server.store.UpdateUserDeletionScheduledAt(c, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserDeletionScheduledAt: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
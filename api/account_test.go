@@ -0,0 +1,455 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// ZIPファイルの中身をファイル名ごとに読み出す。
+func readZipFiles(t *testing.T, data []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		files[f.Name] = b
+	}
+	return files
+}
+
+func TestExportAccount(t *testing.T) {
+	user := randomProfileUser()
+	sessions := []db.Session{
+		{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			UserAgent: "MacOS",
+			ClientIp:  util.RandomIPAddress(),
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+	deletedAt := time.Now().UTC().Truncate(time.Second)
+	expenses := []db.ListExportExpensesRow{
+		{
+			ID:         util.RandomID(),
+			UserID:     user.ID,
+			CategoryID: util.RandomID(),
+			Amount:     1200,
+			StoreName:  "store, with comma",
+			CreatedAt:  time.Now(),
+		},
+		// ゴミ箱にある支出。
+		{
+			ID:         util.RandomID(),
+			UserID:     user.ID,
+			CategoryID: util.RandomID(),
+			Amount:     300,
+			StoreName:  "",
+			CreatedAt:  time.Now(),
+			DeletedAt:  sql.NullTime{Time: deletedAt, Valid: true},
+		},
+	}
+	receiptID := util.RandomID()
+	emptyReceiptID := util.RandomID()
+	receiptContents := []db.ListUserFoodReceiptContentsRow{
+		{
+			FoodReceiptID: receiptID,
+			StoreName:     "store",
+			FoodContentID: sql.NullInt64{Int64: 1, Valid: true},
+			Amount:        sql.NullInt64{Int64: 1, Valid: true},
+			Name:          sql.NullString{String: "apple", Valid: true},
+			Calories:      sql.NullFloat64{Float64: 52.5, Valid: true},
+		},
+		{
+			FoodReceiptID: receiptID,
+			StoreName:     "store",
+			FoodContentID: sql.NullInt64{Int64: 2, Valid: true},
+			Amount:        sql.NullInt64{Int64: 2, Valid: true},
+			Name:          sql.NullString{String: "milk", Valid: true},
+			Calories:      sql.NullFloat64{Float64: 67, Valid: true},
+		},
+		// 明細のない、ゴミ箱にあるレシート。
+		{
+			FoodReceiptID: emptyReceiptID,
+			StoreName:     "kiosk",
+			DeletedAt:     sql.NullTime{Time: deletedAt, Valid: true},
+		},
+	}
+	transfers := []db.Transfer{
+		{ID: util.RandomID(), FromUserID: user.ID, ToUserID: util.RandomID(), Amount: 500, CreatedAt: time.Now()},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(sessions, nil)
+				store.EXPECT().
+					ListExportExpenses(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(expenses, nil)
+				store.EXPECT().
+					ListUserFoodReceiptContents(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(receiptContents, nil)
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), exportFileName)
+
+				files := readZipFiles(t, recorder.Body.Bytes())
+				for _, name := range []string{
+					"profile.json",
+					"sessions.json", "sessions.csv",
+					"expenses.json", "expenses.csv",
+					"receipts.json", "receipts.csv",
+					"transfers.json", "transfers.csv",
+				} {
+					require.Contains(t, files, name)
+				}
+
+				// プロフィールにパスワードのハッシュを含めない。
+				var profile userResponse
+				require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+				require.Equal(t, user.Email, profile.Email)
+				require.NotContains(t, string(files["profile.json"]), user.Password)
+
+				// ゴミ箱にある支出も含める。
+				var exported []exportExpense
+				require.NoError(t, json.Unmarshal(files["expenses.json"], &exported))
+				require.Len(t, exported, 2)
+				require.Nil(t, exported[0].DeletedAt)
+				require.NotNil(t, exported[1].DeletedAt)
+				require.True(t, deletedAt.Equal(*exported[1].DeletedAt))
+
+				// 明細はレシートごとにまとめ、明細のないレシートも含める。
+				var receipts []exportReceipt
+				require.NoError(t, json.Unmarshal(files["receipts.json"], &receipts))
+				require.Len(t, receipts, 2)
+				require.Len(t, receipts[0].Items, 2)
+				require.Nil(t, receipts[0].DeletedAt)
+				require.Equal(t, emptyReceiptID, receipts[1].ID)
+				require.Empty(t, receipts[1].Items)
+				require.NotNil(t, receipts[1].DeletedAt)
+
+				// CSVはヘッダーを含め、カンマを含む値も正しく読み込めること。
+				rows, err := csv.NewReader(bytes.NewReader(files["expenses.csv"])).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, len(expenses)+1)
+				require.Equal(t, "store, with comma", rows[1][3])
+				require.Equal(t, "", rows[1][6])
+				require.Equal(t, deletedAt.Format(time.RFC3339), rows[2][6])
+
+				rows, err = csv.NewReader(bytes.NewReader(files["receipts.csv"])).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, len(receiptContents)+1)
+				require.Equal(t, "52.5", rows[1][5])
+				require.Equal(t, []string{strconv.FormatInt(emptyReceiptID, 10), "kiosk", "", "", "", "", "", "", "", deletedAt.Format(time.RFC3339)}, rows[3])
+			},
+		},
+		{
+			name: "DBErrorWhenListUserTransfers",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListUserSessions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sessions, nil)
+				store.EXPECT().
+					ListExportExpenses(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(expenses, nil)
+				store.EXPECT().
+					ListUserFoodReceiptContents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(receiptContents, nil)
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Transfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me/export", nil)
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := randomProfileUser()
+	user.Password = hashedPassword
+	gracePeriod := 7 * 24 * time.Hour
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserDeletionScheduledAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateUserDeletionScheduledAtParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.True(t, arg.DeletionScheduledAt.Valid)
+						// 猶予期間の後に削除する。
+						require.WithinDuration(t, time.Now().Add(gracePeriod), arg.DeletionScheduledAt.Time, time.Second)
+						scheduled := user
+						scheduled.DeletionScheduledAt = arg.DeletionScheduledAt
+						return scheduled, nil
+					})
+				// 削除自体はバックグラウンドで行う。
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				checkBodyContains(t, recorder, "deletion_scheduled_at")
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"password": "wrong_password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserDeletionScheduledAt(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BindRequestErrorWithoutPassword",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DBErrorWhenUpdateUserDeletionScheduledAt",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserDeletionScheduledAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+
+			config := newTestConfig()
+			config.AccountDeletionGracePeriod = gracePeriod
+			server := NewServer(config, store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodDelete, "/users/me", bytes.NewReader(data))
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCancelAccountDeletion(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomProfileUser()
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.UpdateUserDeletionScheduledAtParams{
+		ID: user.ID,
+	}
+	store.EXPECT().
+		UpdateUserDeletionScheduledAt(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(user, nil)
+	// authのmiddlewareを通すため。
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+	manager := auth.NewMockManager(store)
+	manager.UserID = user.ID
+
+	server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, "/users/me/deletion", nil)
+	require.NoError(t, err)
+	addCompleteAuth(t, request, manager)
+
+	// Act
+	server.router.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var rsp userResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Nil(t, rsp.DeletionScheduledAt)
+}
+
+// セッションが有効なまま、ユーザーの行が見つからない場合は401を返すこと。
+func TestCancelAccountDeletionUserNotFound(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomProfileUser()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		UpdateUserDeletionScheduledAt(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	// authのmiddlewareを通すため。
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+	manager := auth.NewMockManager(store)
+	manager.UserID = user.ID
+
+	server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, "/users/me/deletion", nil)
+	require.NoError(t, err)
+	addCompleteAuth(t, request, manager)
+
+	// Act
+	server.router.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	checkError(t, codeUnauthenticated, recorder.Body)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// 一覧取得時のページングのRequestのpayload。
+type pageRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// URIでIDを指定するRequestのpayload。
+type idRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// 管理者向けのユーザーのResponseのpayload。
+type adminUserResponse struct {
+	userResponse
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+// 管理者向けのユーザー一覧取得用のResponseのpayload。
+type listUsersResponse struct {
+	Users []adminUserResponse `json:"users"`
+}
+
+func newAdminUserResponse(user db.User) adminUserResponse {
+	rsp := adminUserResponse{
+		userResponse: newUserResponse(user),
+	}
+	if user.DisabledAt.Valid {
+		rsp.DisabledAt = &user.DisabledAt.Time
+	}
+	return rsp
+}
+
+// ユーザーの一覧を取得するエンドポイント。
+func (server *Server) listUsers(c *gin.Context) {
+	var req pageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	arg := db.ListUsersParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+	users, err := server.store.ListUsers(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListUsers: %w", err))
+		return
+	}
+
+	rsp := listUsersResponse{
+		Users: []adminUserResponse{},
+	}
+	for _, user := range users {
+		rsp.Users = append(rsp.Users, newAdminUserResponse(user))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// ユーザーのアカウントを無効化するエンドポイント。
+// 無効化と同時に、そのユーザーの全てのセッションを失効させる。
+func (server *Server) disableUser(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	// 管理者が誰もいなくなることを防ぐため、自分自身は無効化させない。
+	if req.ID == authUserID(c) {
+		abortWithError(c, http.StatusBadRequest, codeInvalidRequest, "error.cannot_disable_self")
+		return
+	}
+
+	arg := db.UpdateUserDisabledAtParams{
+		DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:         req.ID,
+	}
+	user, err := server.store.UpdateUserDisabledAt(c, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserDisabledAt: %w", err))
+		return
+	}
+
+	if err := server.store.DeleteUserSessions(c, user.ID); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteUserSessions: %w", err))
+		return
+	}
+
+	requestLogger(c).Infof("user [%d] was disabled by admin [%d]", user.ID, authUserID(c))
+	c.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// 無効化したユーザーのアカウントを有効に戻すエンドポイント。
+func (server *Server) enableUser(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	arg := db.UpdateUserDisabledAtParams{
+		DisabledAt: sql.NullTime{},
+		ID:         req.ID,
+	}
+	user, err := server.store.UpdateUserDisabledAt(c, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserDisabledAt: %w", err))
+		return
+	}
+
+	requestLogger(c).Infof("user [%d] was enabled by admin [%d]", user.ID, authUserID(c))
+	c.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// ユーザーの全てのセッションを失効させ、強制的にログアウトさせるエンドポイント。
+func (server *Server) logoutUserSessions(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	if _, err := server.store.GetUserByID(c, req.ID); err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+
+	if err := server.store.DeleteUserSessions(c, req.ID); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteUserSessions: %w", err))
+		return
+	}
+
+	requestLogger(c).Infof("sessions of user [%d] were deleted by admin [%d]", req.ID, authUserID(c))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomUser(role string) db.User {
+	return db.User{
+		ID:                util.RandomID(),
+		Name:              util.RandomUserName(),
+		Email:             util.RandomEmail(),
+		Age:               util.RandomAge(),
+		Balance:           util.RandomBalance(),
+		Role:              role,
+		PasswordChangedAt: time.Now(),
+		CreatedAt:         time.Now(),
+	}
+}
+
+// 管理者として認証のmiddlewareを通すためのスタブを登録する。
+func addAdminAuthMock(store *mockdb.MockStore, admin db.User) {
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+	store.EXPECT().
+		GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).
+		Times(1).
+		Return(admin, nil)
+}
+
+// 管理者用のエンドポイントにリクエストを送る。
+func serveAdminRequest(
+	t *testing.T,
+	store *mockdb.MockStore,
+	adminID int64,
+	method string,
+	url string,
+	body interface{},
+) *httptest.ResponseRecorder {
+	return serveAdminRequestWithHeader(t, store, adminID, method, url, body, nil)
+}
+
+// ヘッダーを追加して、管理者用のエンドポイントにリクエストを送る。
+func serveAdminRequestWithHeader(
+	t *testing.T,
+	store *mockdb.MockStore,
+	adminID int64,
+	method string,
+	url string,
+	body interface{},
+	header http.Header,
+) *httptest.ResponseRecorder {
+	manager := auth.NewMockManager(store)
+	manager.UserID = adminID
+
+	server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+	recorder := httptest.NewRecorder()
+
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	addCompleteAuth(t, request, manager)
+
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAdminRoutesRequireAdmin(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomUser(auth.RoleUser)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+	store.EXPECT().
+		GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		ListUsers(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	// Act
+	recorder := serveAdminRequest(t, store, user.ID, http.MethodGet, "/admin/users?page_id=1&page_size=10", nil)
+
+	// Assert
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestListUsers(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	users := []db.User{
+		admin,
+		randomUser(auth.RoleUser),
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUsersParams{
+					Limit:  5,
+					Offset: 5,
+				}
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(users, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp listUsersResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Len(t, rsp.Users, len(users))
+				for i, user := range rsp.Users {
+					require.Equal(t, users[i].ID, user.Id)
+					require.Equal(t, users[i].Role, user.Role)
+				}
+				// パスワードのハッシュは返さない。
+				require.NotContains(t, string(data), "password\"")
+			},
+		},
+		{
+			name:  "BindRequestErrorWithoutPage",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "DBErrorWhenListUsers",
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequest(t, store, admin.ID, http.MethodGet, "/admin/users?"+tc.query, nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDisableUser(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	user := randomUser(auth.RoleUser)
+
+	testCases := []struct {
+		name          string
+		userID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			userID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserDisabledAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateUserDisabledAtParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.True(t, arg.DisabledAt.Valid)
+						disabled := user
+						disabled.DisabledAt = arg.DisabledAt
+						return disabled, nil
+					})
+				// 無効化と同時に強制ログアウトさせる。
+				store.EXPECT().
+					DeleteUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp adminUserResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Equal(t, user.ID, rsp.Id)
+				require.NotNil(t, rsp.DisabledAt)
+			},
+		},
+		{
+			name:   "CannotDisableSelf",
+			userID: admin.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserDisabledAt(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			userID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserDisabledAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					DeleteUserSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "DBErrorWhenDeleteUserSessions",
+			userID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserDisabledAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUserSessions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/users/%d/disable", tc.userID)
+
+			// Act
+			recorder := serveAdminRequest(t, store, admin.ID, http.MethodPost, url, nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEnableUser(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	user := randomUser(auth.RoleUser)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserDisabledAtParams{
+					ID: user.ID,
+				}
+				store.EXPECT().
+					UpdateUserDisabledAt(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp adminUserResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Nil(t, rsp.DisabledAt)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserDisabledAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/users/%d/enable", user.ID)
+
+			// Act
+			recorder := serveAdminRequest(t, store, admin.ID, http.MethodPost, url, nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutUserSessions(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	user := randomUser(auth.RoleUser)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					DeleteUserSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/users/%d/sessions", user.ID)
+
+			// Act
+			recorder := serveAdminRequest(t, store, admin.ID, http.MethodDelete, url, nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
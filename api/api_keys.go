@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/auth"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+// APIキー発行用のRequestのpayload。
+type createAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=read:expenses write:expenses read:receipts write:receipts"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIキーのResponseのpayload。
+// キー本体は発行時の１度しか返さない。
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	KeyPrefix  string     `json:"key_prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIキー一覧取得用のResponseのpayload。
+type listAPIKeysResponse struct {
+	APIKeys []apiKeyResponse `json:"api_keys"`
+}
+
+// APIキー失効用のRequestのpayload。
+type revokeAPIKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func newAPIKeyResponse(key db.ApiKey) apiKeyResponse {
+	rsp := apiKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		KeyPrefix: key.KeyPrefix,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+	}
+	if key.ExpiresAt.Valid {
+		rsp.ExpiresAt = &key.ExpiresAt.Time
+	}
+	if key.LastUsedAt.Valid {
+		rsp.LastUsedAt = &key.LastUsedAt.Time
+	}
+	return rsp
+}
+
+// APIキーを発行するエンドポイント。
+func (server *Server) createAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		abortWithFieldErrors(c, []fieldError{{Field: "expires_at", Reason: "future"}})
+		return
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to GenerateAPIKey: %w", err))
+		return
+	}
+
+	arg := db.CreateAPIKeyParams{
+		UserID:    authUserID(c),
+		Name:      req.Name,
+		KeyPrefix: prefix,
+		KeyHash:   util.HashToken(key),
+		Scopes:    req.Scopes,
+	}
+	if req.ExpiresAt != nil {
+		arg.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	apiKey, err := server.store.CreateAPIKey(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to CreateAPIKey: %w", err))
+		return
+	}
+
+	rsp := newAPIKeyResponse(apiKey)
+	rsp.Key = key
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// 有効なAPIキーの一覧を取得するエンドポイント。
+func (server *Server) listAPIKeys(c *gin.Context) {
+	keys, err := server.store.ListAPIKeys(c, authUserID(c))
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListAPIKeys: %w", err))
+		return
+	}
+
+	rsp := listAPIKeysResponse{
+		APIKeys: []apiKeyResponse{},
+	}
+	for _, key := range keys {
+		rsp.APIKeys = append(rsp.APIKeys, newAPIKeyResponse(key))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// APIキーを失効させるエンドポイント。
+func (server *Server) revokeAPIKey(c *gin.Context) {
+	var req revokeAPIKeyRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	arg := db.RevokeAPIKeyParams{
+		ID:     req.ID,
+		UserID: authUserID(c),
+	}
+	if _, err := server.store.RevokeAPIKey(c, arg); err != nil {
+		// 他のユーザーのAPIキーも存在しないものとして扱う。
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.api_key_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to RevokeAPIKey: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAPIKey(userID int64) db.ApiKey {
+	return db.ApiKey{
+		ID:        util.RandomID(),
+		UserID:    userID,
+		Name:      util.RandomString(8),
+		KeyPrefix: "abk_" + util.RandomString(8),
+		KeyHash:   util.HashToken(util.RandomString(32)),
+		Scopes:    []string{auth.ScopeReadExpenses, auth.ScopeWriteExpenses},
+		CreatedAt: time.Now(),
+	}
+}
+
+// 認証時に取得する、APIキーと所有者の言語設定を作成する。
+func apiKeyAuthRow(key db.ApiKey, locale string) db.GetAPIKeyByHashRow {
+	return db.GetAPIKeyByHashRow{
+		ID:         key.ID,
+		UserID:     key.UserID,
+		Name:       key.Name,
+		KeyPrefix:  key.KeyPrefix,
+		KeyHash:    key.KeyHash,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		Locale:     locale,
+	}
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	userID := util.RandomID()
+	apiKey := randomAPIKey(userID)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":   apiKey.Name,
+				"scopes": apiKey.Scopes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, userID, arg.UserID)
+						require.Equal(t, apiKey.Scopes, arg.Scopes)
+						require.False(t, arg.ExpiresAt.Valid)
+						key := apiKey
+						key.KeyPrefix = arg.KeyPrefix
+						key.KeyHash = arg.KeyHash
+						return key, nil
+					})
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp apiKeyResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				// キー本体は発行時のみ返されること。
+				require.True(t, strings.HasPrefix(rsp.Key, rsp.KeyPrefix))
+				require.Equal(t, apiKey.Scopes, rsp.Scopes)
+			},
+		},
+		{
+			name: "BindRequestErrorWithUnknownScope",
+			body: gin.H{
+				"name":   apiKey.Name,
+				"scopes": []string{"admin:everything"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiresAtInThePast",
+			body: gin.H{
+				"name":       apiKey.Name,
+				"scopes":     apiKey.Scopes,
+				"expires_at": time.Now().Add(-time.Hour),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ForbiddenWithAPIKey",
+			body: gin.H{
+				"name":   apiKey.Name,
+				"scopes": apiKey.Scopes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addAPIKeyAuthorization(t, request, "abk_key")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(apiKeyAuthRow(apiKey, i18n.DefaultLocale), nil)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				// APIキーからAPIキーを発行することはできない。
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "DBErrorWhenCreateAPIKey",
+			body: gin.H{
+				"name":   apiKey.Name,
+				"scopes": apiKey.Scopes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrConnDone)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			manager := auth.NewMockManager(store)
+			manager.UserID = userID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/api-keys", bytes.NewReader(data))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAPIKeys(t *testing.T) {
+	userID := util.RandomID()
+	apiKeys := []db.ApiKey{
+		randomAPIKey(userID),
+		randomAPIKey(userID),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAPIKeys(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(apiKeys, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp listAPIKeysResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Len(t, rsp.APIKeys, len(apiKeys))
+				for i, key := range rsp.APIKeys {
+					require.Equal(t, apiKeys[i].ID, key.ID)
+					// 一覧ではキー本体を返さない。
+					require.Empty(t, key.Key)
+				}
+			},
+		},
+		{
+			name: "DBErrorWhenListAPIKeys",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAPIKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = userID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me/api-keys", nil)
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	userID := util.RandomID()
+	apiKey := randomAPIKey(userID)
+
+	testCases := []struct {
+		name          string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			url:  fmt.Sprintf("/users/me/api-keys/%d", apiKey.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RevokeAPIKeyParams{
+					ID:     apiKey.ID,
+					UserID: userID,
+				}
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			url:  fmt.Sprintf("/users/me/api-keys/%d", apiKey.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "BindRequestErrorWithInvalidID",
+			url:  "/users/me/api-keys/abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = userID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, tc.url, nil)
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/auth"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// 監査ログ一覧取得用のRequestのpayload。
+type listAuditEventsRequest struct {
+	pageRequest
+	// 管理者のみ他のユーザーを指定できる。
+	// 管理者が省略した場合は全てのユーザーの監査ログを返す。
+	UserID int64 `form:"user_id" binding:"omitempty,min=1"`
+}
+
+// 監査ログのResponseのpayload。
+// セッションIDはCookieの値そのものであるため返さない。
+type auditEventResponse struct {
+	ID int64 `json:"id"`
+	// バックグラウンドの処理による操作の場合は null になる。
+	ActorID    *int64          `json:"actor_id"`
+	ClientIP   string          `json:"client_ip"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	// 操作したユーザーの退会で個人データを消した日時。消していない場合は null になる。
+	RedactedAt *time.Time `json:"redacted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// 監査ログ一覧取得用のResponseのpayload。
+type listAuditEventsResponse struct {
+	Events []auditEventResponse `json:"events"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) auditEventResponse {
+	rsp := auditEventResponse{
+		ID:         event.ID,
+		ClientIP:   event.ClientIp,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Before:     event.Before,
+		After:      event.After,
+		CreatedAt:  event.CreatedAt,
+	}
+	if event.ActorID.Valid {
+		rsp.ActorID = &event.ActorID.Int64
+	}
+	if event.RedactedAt.Valid {
+		rsp.RedactedAt = &event.RedactedAt.Time
+	}
+	return rsp
+}
+
+// 監査ログを新しい順に取得するエンドポイント。
+// 一般のユーザーは自身の操作のみ、管理者は全てのユーザーの操作を取得できる。
+func (server *Server) listAuditEvents(c *gin.Context) {
+	var req listAuditEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	userID := authUserID(c)
+	user, err := server.store.GetUserByID(c, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	isAdmin := auth.HasRole(user.Role, auth.RoleAdmin)
+	if req.UserID != 0 && req.UserID != userID && !isAdmin {
+		abortWithError(c, http.StatusForbidden, codePermissionDenied, "error.permission_denied")
+		return
+	}
+
+	limit := req.PageSize
+	offset := (req.PageID - 1) * req.PageSize
+	var events []db.AuditEvent
+	if isAdmin && req.UserID == 0 {
+		events, err = server.store.ListAuditEvents(c, db.ListAuditEventsParams{
+			Limit:  limit,
+			Offset: offset,
+		})
+	} else {
+		actorID := userID
+		if req.UserID != 0 {
+			actorID = req.UserID
+		}
+		events, err = server.store.ListUserAuditEvents(c, db.ListUserAuditEventsParams{
+			ActorID: actorID,
+			Limit:   limit,
+			Offset:  offset,
+		})
+	}
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to list audit events: %w", err))
+		return
+	}
+
+	rsp := listAuditEventsResponse{
+		Events: []auditEventResponse{},
+	}
+	for _, event := range events {
+		rsp.Events = append(rsp.Events, newAuditEventResponse(event))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
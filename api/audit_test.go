@@ -0,0 +1,300 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAuditEvent(actorID int64, action string) db.AuditEvent {
+	event := db.AuditEvent{
+		ID:         util.RandomID(),
+		ActorID:    sql.NullInt64{Int64: actorID, Valid: true},
+		SessionID:  uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		ClientIp:   "192.0.2.1",
+		Action:     action,
+		EntityType: db.AuditEntityCategory,
+		EntityID:   util.RandomID(),
+		Before:     json.RawMessage("null"),
+		After:      json.RawMessage("null"),
+		CreatedAt:  time.Now(),
+	}
+	category, _ := json.Marshal(randomCategory())
+	if action != db.AuditActionCreate {
+		event.Before = category
+	}
+	if action != db.AuditActionDelete {
+		event.After = category
+	}
+	return event
+}
+
+func TestListAuditEvents(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+	admin := randomUser(auth.RoleAdmin)
+	other := randomUser(auth.RoleUser)
+	events := []db.AuditEvent{
+		randomAuditEvent(user.ID, db.AuditActionUpdate),
+		randomAuditEvent(user.ID, db.AuditActionCreate),
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OwnTrail",
+			user:  user,
+			query: "?page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUserAuditEventsParams{
+					ActorID: user.ID,
+					Limit:   5,
+					Offset:  5,
+				}
+				store.EXPECT().
+					ListUserAuditEvents(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp listAuditEventsResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Len(t, rsp.Events, len(events))
+				require.Equal(t, events[0].ID, rsp.Events[0].ID)
+				require.JSONEq(t, string(events[0].Before), string(rsp.Events[0].Before))
+				require.JSONEq(t, "null", string(rsp.Events[1].Before))
+				require.Nil(t, rsp.Events[0].RedactedAt)
+				// セッションIDは返さない。
+				require.NotContains(t, string(data), events[0].SessionID.UUID.String())
+			},
+		},
+		{
+			name:  "OwnTrailWithUserID",
+			user:  user,
+			query: fmt.Sprintf("?page_id=1&page_size=5&user_id=%d", user.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserAuditEvents(gomock.Any(), gomock.Eq(db.ListUserAuditEventsParams{ActorID: user.ID, Limit: 5})).
+					Times(1).
+					Return([]db.AuditEvent{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				checkBodyContains(t, recorder, `"events":[]`)
+			},
+		},
+		{
+			name:  "OtherUserForbidden",
+			user:  user,
+			query: fmt.Sprintf("?page_id=1&page_size=5&user_id=%d", other.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "AdminAllUsers",
+			user:  admin,
+			query: "?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(db.ListAuditEventsParams{Limit: 5})).
+					Times(1).
+					Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "AdminSystemEvent",
+			user:  admin,
+			query: "?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				event := randomAuditEvent(user.ID, db.AuditActionPurge)
+				event.ActorID = sql.NullInt64{}
+				event.SessionID = uuid.NullUUID{}
+				event.ClientIp = ""
+				event.Before = json.RawMessage("null")
+				event.After = json.RawMessage("null")
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(db.ListAuditEventsParams{Limit: 5})).
+					Times(1).
+					Return([]db.AuditEvent{event}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				checkBodyContains(t, recorder, `"actor_id":null`)
+			},
+		},
+		{
+			name:  "AdminOtherUser",
+			user:  admin,
+			query: fmt.Sprintf("?page_id=1&page_size=5&user_id=%d", other.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserAuditEvents(gomock.Any(), gomock.Eq(db.ListUserAuditEventsParams{ActorID: other.ID, Limit: 5})).
+					Times(1).
+					Return([]db.AuditEvent{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "AdminRedactedEvent",
+			user:  admin,
+			query: fmt.Sprintf("?page_id=1&page_size=5&user_id=%d", other.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				event := randomAuditEvent(other.ID, db.AuditActionUpdate)
+				event.SessionID = uuid.NullUUID{}
+				event.ClientIp = ""
+				event.Before = json.RawMessage("null")
+				event.After = json.RawMessage("null")
+				event.RedactedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					ListUserAuditEvents(gomock.Any(), gomock.Eq(db.ListUserAuditEventsParams{ActorID: other.ID, Limit: 5})).
+					Times(1).
+					Return([]db.AuditEvent{event}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var rsp listAuditEventsResponse
+				require.NoError(t, json.NewDecoder(recorder.Body).Decode(&rsp))
+				require.Len(t, rsp.Events, 1)
+				require.NotNil(t, rsp.Events[0].RedactedAt)
+				require.JSONEq(t, "null", string(rsp.Events[0].Before))
+				require.JSONEq(t, "null", string(rsp.Events[0].After))
+			},
+		},
+		{
+			name:  "DBError",
+			user:  user,
+			query: "?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, tc.user)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequest(t, store, tc.user.ID, http.MethodGet, "/audit"+tc.query, nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAuditEventsBindError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomUser(auth.RoleUser)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	// Act
+	recorder := serveAdminRequest(t, store, user.ID, http.MethodGet, "/audit", nil)
+
+	// Assert
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestAuditActor(t *testing.T) {
+	session := uuid.New()
+
+	testCases := []struct {
+		name     string
+		setup    func(c *gin.Context)
+		expected db.AuditActor
+	}{
+		{
+			name: "Session",
+			setup: func(c *gin.Context) {
+				c.Set(authUserIDKey, int64(1))
+				c.Set(authSessionIDKey, session)
+			},
+			expected: db.AuditActor{
+				UserID:    1,
+				SessionID: uuid.NullUUID{UUID: session, Valid: true},
+				ClientIP:  "192.0.2.1",
+			},
+		},
+		{
+			name: "APIKey",
+			setup: func(c *gin.Context) {
+				c.Set(authUserIDKey, int64(2))
+			},
+			expected: db.AuditActor{
+				UserID:   2,
+				ClientIP: "192.0.2.1",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/expenses", nil)
+			c.Request.RemoteAddr = "192.0.2.1:12345"
+			tc.setup(c)
+
+			// Act
+			actor := auditActor(c)
+
+			// Assert
+			require.Equal(t, tc.expected, actor)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
+)
+
+// カテゴリーの作成・更新用のRequestのpayload。
+type categoryRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// カテゴリーのResponseのpayload。
+type categoryResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// リクエストの言語での表示名。
+	DisplayName string `json:"display_name"`
+	// 更新のたびに増える値。ETag と同じ値。
+	Version int64 `json:"version"`
+}
+
+// カテゴリー一覧取得用のResponseのpayload。
+type listCategoriesResponse struct {
+	Categories []categoryResponse `json:"categories"`
+}
+
+// 標準のカテゴリー（food など）は、カタログの表示名に翻訳する。
+// 管理者が独自に作成したカテゴリーは、名前をそのまま表示名とする。
+func newCategoryResponse(c *gin.Context, category db.Category) categoryResponse {
+	displayName := category.Name
+	if key := "category." + category.Name; i18n.Has(requestLocale(c), key) {
+		displayName = translate(c, key)
+	}
+	return categoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		DisplayName: displayName,
+		Version:     category.Version,
+	}
+}
+
+// 全ユーザー共通のカテゴリーの一覧を取得するエンドポイント。
+// 支出の登録に使うため、管理者以外にも公開する。
+func (server *Server) listCategories(c *gin.Context) {
+	categories, err := server.store.ListCategories(c)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListCategories: %w", err))
+		return
+	}
+
+	rsp := listCategoriesResponse{
+		Categories: []categoryResponse{},
+	}
+	for _, category := range categories {
+		rsp.Categories = append(rsp.Categories, newCategoryResponse(c, category))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// カテゴリーを作成するエンドポイント。
+func (server *Server) createCategory(c *gin.Context) {
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	category, err := server.store.CreateCategoryTx(c, req.Name, auditActor(c))
+	if err != nil {
+		if errors.Is(err, db.ErrUniqueViolation) {
+			abortWithError(c, http.StatusConflict, codeAlreadyExists, "error.category_already_exists")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to CreateCategoryTx: %w", err))
+		return
+	}
+
+	setETag(c, category.Version)
+	c.JSON(http.StatusCreated, newCategoryResponse(c, category))
+}
+
+// カテゴリー名を変更するエンドポイント。
+// If-Match の version が一致する場合のみ変更する。
+func (server *Server) updateCategory(c *gin.Context) {
+	var uri idRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateCategoryParams{
+		Name:    req.Name,
+		ID:      uri.ID,
+		Version: version,
+	}
+	category, err := server.store.UpdateCategoryTx(c, arg, auditActor(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.category_not_found")
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			abortWithPreconditionFailed(c)
+			return
+		}
+		if errors.Is(err, db.ErrUniqueViolation) {
+			abortWithError(c, http.StatusConflict, codeAlreadyExists, "error.category_already_exists")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateCategoryTx: %w", err))
+		return
+	}
+
+	setETag(c, category.Version)
+	c.JSON(http.StatusOK, newCategoryResponse(c, category))
+}
+
+// カテゴリーをゴミ箱に移すエンドポイント。
+// ゴミ箱にない支出から参照されているカテゴリーは削除できない。
+// If-Match の version が一致する場合のみ削除する。
+func (server *Server) deleteCategory(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	arg := db.DeleteCategoryParams{
+		ID:      req.ID,
+		Version: version,
+	}
+	if _, err := server.store.DeleteCategoryTx(c, arg, auditActor(c)); err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.category_not_found")
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			abortWithPreconditionFailed(c)
+			return
+		}
+		if errors.Is(err, db.ErrCategoryInUse) {
+			abortWithError(c, http.StatusConflict, codeResourceInUse, "error.category_in_use")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteCategoryTx: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,393 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomCategory() db.Category {
+	return db.Category{
+		ID:      util.RandomID(),
+		Name:    util.RandomString(8),
+		Version: util.RandomInt(1, 10),
+	}
+}
+
+func TestListCategories(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	admin := randomUser(auth.RoleAdmin)
+	categories := []db.Category{randomCategory(), randomCategory()}
+	store := mockdb.NewMockStore(ctrl)
+	addAdminAuthMock(store, admin)
+	store.EXPECT().
+		ListCategories(gomock.Any()).
+		Times(1).
+		Return(categories, nil)
+
+	// Act
+	recorder := serveAdminRequest(t, store, admin.ID, http.MethodGet, "/admin/categories", nil)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	data, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	var rsp listCategoriesResponse
+	require.NoError(t, json.Unmarshal(data, &rsp))
+	require.Len(t, rsp.Categories, len(categories))
+}
+
+func TestCreateCategory(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	category := randomCategory()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Eq(category.Name), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				checkBodyContains(t, recorder, category.Name)
+			},
+		},
+		{
+			name: "BindRequestErrorWithoutName",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Duplicated",
+			body: gin.H{"name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "DBError",
+			body: gin.H{"name": category.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequest(t, store, admin.ID, http.MethodPost, "/admin/categories", tc.body)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateCategory(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	category := randomCategory()
+
+	etag := fmt.Sprintf(`"%d"`, category.Version)
+
+	testCases := []struct {
+		name          string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "AnyVersion",
+			header: http.Header{"If-Match": {"*"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				// * の場合は、version を条件にしない。
+				arg := db.UpdateCategoryParams{
+					Name:    category.Name,
+					ID:      category.ID,
+					Version: db.AnyVersion,
+				}
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, etag, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:   "IfMatch",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCategoryParams{
+					Name:    category.Name,
+					ID:      category.ID,
+					Version: category.Version,
+				}
+				updated := category
+				updated.Version++
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, fmt.Sprintf(`"%d"`, category.Version+1), recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:   "VersionMismatch",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(category, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_failed")
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_required")
+			},
+		},
+		{
+			// 強い比較で判定するため、弱い ETag は一致しない。
+			name:   "WeakETag",
+			header: http.Header{"If-Match": {"W/" + etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/categories/%d", category.ID)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, admin.ID, http.MethodPut, url, gin.H{"name": category.Name}, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	category := randomCategory()
+
+	etag := fmt.Sprintf(`"%d"`, category.Version)
+
+	testCases := []struct {
+		name          string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "AnyVersion",
+			header: http.Header{"If-Match": {"*"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteCategoryParams{ID: category.ID, Version: db.AnyVersion}
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "IfMatch",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteCategoryParams{ID: category.ID, Version: category.Version}
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "VersionMismatch",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(category, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidIfMatch",
+			header: http.Header{"If-Match": {"abc"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InUse",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, db.ErrCategoryInUse)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/categories/%d", category.ID)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, admin.ID, http.MethodDelete, url, nil, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+// Cookieを書き込む。
+// Domain, Secure, SameSite は設定ファイルの値を使うため、Cookieの書き込みは全てここを通す。
+func (server *Server) setCookie(c *gin.Context, name string, value string, maxAge int, httpOnly bool) {
+	// 設定値は起動時に検証済みのため、エラーは無視する。
+	sameSite, _ := util.ParseSameSite(server.config.CookieSameSite)
+	c.SetSameSite(sameSite)
+	c.SetCookie(name, value, maxAge, "/", server.config.CookieDomain, server.config.CookieSecure, httpOnly)
+}
+
+// セッションIDをCookieに書き込む。
+// maxAge に負の値を指定した場合は、Cookieを削除する。
+func (server *Server) setSessionCookie(c *gin.Context, sessionID string, maxAge int) {
+	server.setCookie(c, server.config.CookieName, sessionID, maxAge, true)
+}
+
+// Cookieからセッションの値を取得する。
+func (server *Server) sessionCookie(c *gin.Context) (string, error) {
+	return c.Cookie(server.config.CookieName)
+}
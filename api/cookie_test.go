@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSessionCookie(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(config *util.Config)
+		checkResponse func(t *testing.T, setCookie string)
+	}{
+		{
+			name:         "Default",
+			updateConfig: func(config *util.Config) {},
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.Equal(t, "session=test-session; Path=/; Max-Age=60; HttpOnly; Secure", setCookie)
+			},
+		},
+		{
+			name: "CustomNameAndDomain",
+			updateConfig: func(config *util.Config) {
+				config.CookieName = "abk_session"
+				config.CookieDomain = "example.com"
+			},
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.Equal(t, "abk_session=test-session; Path=/; Domain=example.com; Max-Age=60; HttpOnly; Secure", setCookie)
+			},
+		},
+		{
+			name: "NotSecure",
+			updateConfig: func(config *util.Config) {
+				config.CookieSecure = false
+				config.CookieSameSite = "lax"
+			},
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.Equal(t, "session=test-session; Path=/; Max-Age=60; HttpOnly; SameSite=Lax", setCookie)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			config := newTestConfig()
+			tc.updateConfig(&config)
+			server := &Server{config: config}
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			c.Request = request
+
+			// Act
+			server.setSessionCookie(c, "test-session", 60)
+
+			// Assert
+			tc.checkResponse(t, recorder.Header().Get("Set-Cookie"))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+const (
+	// double-submit cookie 方式で使う、CSRFトークンを保持するCookie名。
+	// JavaScriptから読み出してヘッダーに付与するため HttpOnly にはしない。
+	csrfCookieName = "csrf_token"
+	// CSRFトークンを送信するヘッダー名。
+	csrfHeaderKey = "X-CSRF-Token"
+	// CSRFトークンのバイト数。
+	csrfTokenSize = 32
+)
+
+// Cookieで認証されるリクエストのうち、状態を変更するメソッドに対してCSRFトークンを検証するmiddleware。
+//
+// Cookieの csrf_token と X-CSRF-Token ヘッダーの値が一致する場合のみ通す。
+// APIキーによる認証はブラウザから自動送信されないため、検証を行わない。
+func (server *Server) csrfMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) ||
+			c.GetString(authMethodKey) == authMethodAPIKey {
+			c.Next()
+			return
+		}
+
+		cookie, err := c.Cookie(csrfCookieName)
+		header := c.GetHeader(csrfHeaderKey)
+		if err != nil || cookie == "" || header == "" ||
+			subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			abortWithError(c, http.StatusForbidden, codeCSRFTokenMismatch, "error.csrf_token_mismatch")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RFC 7231 で安全とされているメソッドか判定する。
+func isSafeMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// 新しいCSRFトークンを発行し、Cookieにセットする。
+// セッションの作成時に呼び、ログイン前に仕込まれたトークンを引き継がないようにする。
+func (server *Server) setCSRFCookie(c *gin.Context, maxAge int) error {
+	token, err := util.GenerateToken(csrfTokenSize)
+	if err != nil {
+		return err
+	}
+	server.setCookie(c, csrfCookieName, token, maxAge, false)
+	return nil
+}
+
+// 認証済みのセッションに紐づくCSRFトークンの有効期限を更新する。
+// トークンを持っていない場合は新しく発行する。
+func (server *Server) extendCSRFCookie(c *gin.Context, maxAge int) error {
+	token, err := c.Cookie(csrfCookieName)
+	if err != nil || token == "" {
+		return server.setCSRFCookie(c, maxAge)
+	}
+	server.setCookie(c, csrfCookieName, token, maxAge, false)
+	return nil
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		authMethod   string
+		setupRequest func(request *http.Request)
+		expectedCode int
+	}{
+		{
+			name:       "OK",
+			method:     http.MethodPost,
+			authMethod: authMethodSession,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("Cookie", fmt.Sprintf("%s=%s", csrfCookieName, testCSRFToken))
+				request.Header.Set(csrfHeaderKey, testCSRFToken)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "SafeMethodWithoutToken",
+			method:     http.MethodGet,
+			authMethod: authMethodSession,
+			setupRequest: func(request *http.Request) {
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "NoHeader",
+			method:     http.MethodPost,
+			authMethod: authMethodSession,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("Cookie", fmt.Sprintf("%s=%s", csrfCookieName, testCSRFToken))
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:       "NoCookie",
+			method:     http.MethodDelete,
+			authMethod: authMethodSession,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(csrfHeaderKey, testCSRFToken)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:       "Mismatch",
+			method:     http.MethodPost,
+			authMethod: authMethodSession,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("Cookie", fmt.Sprintf("%s=%s", csrfCookieName, testCSRFToken))
+				request.Header.Set(csrfHeaderKey, "another-token")
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			// APIキーはブラウザから自動送信されないため、CSRFの対象外とする。
+			name:       "SkipWithAPIKey",
+			method:     http.MethodPost,
+			authMethod: authMethodAPIKey,
+			setupRequest: func(request *http.Request) {
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			server := &Server{}
+			router := gin.New()
+			router.Handle(
+				tc.method,
+				"/csrf",
+				func(ctx *gin.Context) {
+					ctx.Set(authMethodKey, tc.authMethod)
+				},
+				server.csrfMiddleware(),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, "/csrf", nil)
+			require.NoError(t, err)
+			tc.setupRequest(request)
+
+			// Act
+			router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestSetCSRFCookie(t *testing.T) {
+	testCases := []struct {
+		name          string
+		sameSite      string
+		cookie        string
+		extend        bool
+		checkResponse func(t *testing.T, setCookie string)
+	}{
+		{
+			name: "NewToken",
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.True(t, strings.HasPrefix(setCookie, csrfCookieName+"="))
+				// JavaScriptから読めるように HttpOnly は付けない。
+				require.NotContains(t, setCookie, "HttpOnly")
+				require.NotContains(t, setCookie, "SameSite")
+			},
+		},
+		{
+			// ログイン前に仕込まれたトークンを引き継がない。
+			name:   "ReplaceExistingToken",
+			cookie: testCSRFToken,
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.True(t, strings.HasPrefix(setCookie, csrfCookieName+"="))
+				require.NotContains(t, setCookie, testCSRFToken)
+			},
+		},
+		{
+			name:   "ExtendExistingToken",
+			cookie: testCSRFToken,
+			extend: true,
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.True(t, strings.HasPrefix(setCookie, fmt.Sprintf("%s=%s;", csrfCookieName, testCSRFToken)))
+			},
+		},
+		{
+			name:   "ExtendWithoutToken",
+			extend: true,
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.True(t, strings.HasPrefix(setCookie, csrfCookieName+"="))
+				require.False(t, strings.HasPrefix(setCookie, csrfCookieName+"=;"))
+			},
+		},
+		{
+			name:     "SameSiteStrict",
+			sameSite: "strict",
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.Contains(t, setCookie, "SameSite=Strict")
+			},
+		},
+		{
+			name:     "SameSiteLax",
+			sameSite: "Lax",
+			checkResponse: func(t *testing.T, setCookie string) {
+				require.Contains(t, setCookie, "SameSite=Lax")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			config := newTestConfig()
+			config.CookieSameSite = tc.sameSite
+			server := &Server{config: config}
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			if tc.cookie != "" {
+				request.Header.Set("Cookie", fmt.Sprintf("%s=%s", csrfCookieName, tc.cookie))
+			}
+			c.Request = request
+
+			// Act
+			if tc.extend {
+				err = server.extendCSRFCookie(c, 60)
+			} else {
+				err = server.setCSRFCookie(c, 60)
+			}
+
+			// Assert
+			require.NoError(t, err)
+			tc.checkResponse(t, recorder.Header().Get("Set-Cookie"))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// 支出・レシートの登録時に、二重登録の確認を省略するかを指定するクエリ。
+type duplicateCheckRequest struct {
+	// true の場合は、同じ内容のものがあっても登録する。
+	Force bool `form:"force"`
+}
+
+// 二重登録の疑いがある場合に返す、409のResponseのpayload。
+type duplicateErrorResponse struct {
+	Error errorBody `json:"error"`
+	// 同じ内容で登録済みの支出またはレシート。新しい順に並べる。
+	Duplicates interface{} `json:"duplicates"`
+}
+
+// 二重登録の疑いがあるレシートのResponseのpayload。
+type duplicateReceiptResponse struct {
+	ID         int64     `json:"id"`
+	StoreName  string    `json:"store_name"`
+	TotalPrice int64     `json:"total_price"`
+	CreatedAt  time.Time `json:"created_at"`
+	Version    int64     `json:"version"`
+}
+
+// 重複している支出の一覧取得用のResponseのpayload。
+type listDuplicateExpensesResponse struct {
+	Groups []duplicateExpenseGroup `json:"groups"`
+}
+
+// 同じカテゴリー・金額で、互いに DUPLICATE_WINDOW 以内に作成された支出の組。
+type duplicateExpenseGroup struct {
+	Expenses []createExpenseResponse `json:"expenses"`
+}
+
+// 登録済みの同じ内容の支出・レシートと共に、409を返す。
+func abortWithDuplicates(c *gin.Context, duplicates interface{}) {
+	c.Header(contentLanguageHeaderKey, requestLocale(c))
+	c.AbortWithStatusJSON(http.StatusConflict, duplicateErrorResponse{
+		Error: errorBody{
+			Code:      codePossibleDuplicate,
+			Message:   translate(c, "error.possible_duplicate"),
+			RequestID: requestID(c),
+		},
+		Duplicates: duplicates,
+	})
+}
+
+// 明細の並び順によらない要約を作る。
+// 商品名と価格が同じ明細を、同じ数だけ含むレシートは同じ値になる。
+func receiptItemsDigest(contents []foodContent) string {
+	lines := make([]string, 0, len(contents))
+	for _, content := range contents {
+		lines = append(lines, fmt.Sprintf("%q %d\n", content.Name, content.Price))
+	}
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// カテゴリー・金額・作成日時の順に並んだ支出を、重複の組ごとにまとめる。
+// 同じカテゴリー・金額で、直前の支出から window 以内に作成されたものを同じ組とする。
+func groupDuplicateExpenses(expenses []db.Expense, window time.Duration) [][]db.Expense {
+	var groups [][]db.Expense
+	for i, expense := range expenses {
+		if i > 0 {
+			prev := expenses[i-1]
+			if prev.CategoryID == expense.CategoryID &&
+				prev.Amount == expense.Amount &&
+				expense.CreatedAt.Sub(prev.CreatedAt) <= window {
+				groups[len(groups)-1] = append(groups[len(groups)-1], expense)
+				continue
+			}
+		}
+		groups = append(groups, []db.Expense{expense})
+	}
+	return groups
+}
+
+// 認証したユーザーの支出のうち、二重登録の疑いがあるものを組ごとに返すエンドポイント。
+// ゴミ箱にある支出は含めない。
+func (server *Server) listDuplicateExpenses(c *gin.Context) {
+	window := server.config.DuplicateWindow
+	expenses, err := server.store.ListDuplicateExpenses(c, db.ListDuplicateExpensesParams{
+		UserID:        authUserID(c),
+		WindowSeconds: window.Seconds(),
+	})
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListDuplicateExpenses: %w", err))
+		return
+	}
+
+	rsp := listDuplicateExpensesResponse{Groups: []duplicateExpenseGroup{}}
+	for _, group := range groupDuplicateExpenses(expenses, window) {
+		rsp.Groups = append(rsp.Groups, duplicateExpenseGroup{Expenses: newCreateExpenseResponses(group)})
+	}
+	c.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptItemsDigest(t *testing.T) {
+	// Arrange
+	apple := foodContent{Name: "apple", Price: 100}
+	milk := foodContent{Name: "milk", Price: 200}
+
+	// Act
+	digest := receiptItemsDigest([]foodContent{apple, milk})
+
+	// Assert
+	// 明細の並び順によらない。
+	require.Equal(t, digest, receiptItemsDigest([]foodContent{milk, apple}))
+	require.NotEqual(t, digest, receiptItemsDigest([]foodContent{apple}))
+	require.NotEqual(t, digest, receiptItemsDigest([]foodContent{apple, milk, milk}))
+	require.NotEqual(t, digest, receiptItemsDigest([]foodContent{apple, {Name: "milk", Price: 210}}))
+}
+
+func TestGroupDuplicateExpenses(t *testing.T) {
+	now := time.Now()
+	window := 10 * time.Minute
+
+	testCases := []struct {
+		name     string
+		expenses []db.Expense
+		want     [][]int64
+	}{
+		{
+			name: "Empty",
+			want: nil,
+		},
+		{
+			name: "ChainedWithinWindow",
+			// 先頭と末尾は window より離れているが、直前との差が window 以内なら同じ組とする。
+			expenses: []db.Expense{
+				{ID: 1, CategoryID: 1, Amount: 300, CreatedAt: now},
+				{ID: 2, CategoryID: 1, Amount: 300, CreatedAt: now.Add(8 * time.Minute)},
+				{ID: 3, CategoryID: 1, Amount: 300, CreatedAt: now.Add(16 * time.Minute)},
+			},
+			want: [][]int64{{1, 2, 3}},
+		},
+		{
+			name: "SplitByWindow",
+			expenses: []db.Expense{
+				{ID: 1, CategoryID: 1, Amount: 300, CreatedAt: now},
+				{ID: 2, CategoryID: 1, Amount: 300, CreatedAt: now.Add(time.Minute)},
+				{ID: 3, CategoryID: 1, Amount: 300, CreatedAt: now.Add(time.Hour)},
+				{ID: 4, CategoryID: 1, Amount: 300, CreatedAt: now.Add(time.Hour + time.Minute)},
+			},
+			want: [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name: "SplitByAmount",
+			expenses: []db.Expense{
+				{ID: 1, CategoryID: 1, Amount: 300, CreatedAt: now},
+				{ID: 2, CategoryID: 1, Amount: 300, CreatedAt: now},
+				{ID: 3, CategoryID: 1, Amount: 500, CreatedAt: now},
+				{ID: 4, CategoryID: 1, Amount: 500, CreatedAt: now},
+			},
+			want: [][]int64{{1, 2}, {3, 4}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			groups := groupDuplicateExpenses(tc.expenses, window)
+
+			// Assert
+			var got [][]int64
+			for _, group := range groups {
+				ids := []int64{}
+				for _, expense := range group {
+					ids = append(ids, expense.ID)
+				}
+				got = append(got, ids)
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/kokoichi206/account-book-api/i18n"
+)
+
+// クライアントが分岐に使う、機械的に判別できるエラーコード。
+// メッセージは変わりうるため、クライアントはコードのみに依存すること。
+const (
+	codeInvalidRequest           = "invalid_request"
+	codeValidationFailed         = "validation_failed"
+	codeUnauthenticated          = "unauthenticated"
+	codeInvalidCredentials       = "invalid_credentials"
+	codeInvalidPassword          = "invalid_password"
+	codeAccountDisabled          = "account_disabled"
+	codeCSRFTokenMismatch        = "csrf_token_mismatch"
+	codeInsufficientScope        = "insufficient_scope"
+	codeSessionRequired          = "session_required"
+	codePermissionDenied         = "permission_denied"
+	codeNotFound                 = "not_found"
+	codeAlreadyExists            = "already_exists"
+	codeEmailAlreadyRegistered   = "email_already_registered"
+	codeResourceInUse            = "resource_in_use"
+	codePreconditionFailed       = "precondition_failed"
+	codePreconditionRequired     = "precondition_required"
+	codeIdempotencyKeyReused     = "idempotency_key_reused"
+	codeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+	codePossibleDuplicate        = "possible_duplicate"
+	codeInvalidToken             = "invalid_token"
+	codeTwoFactorAlreadyEnabled  = "two_factor_already_enabled"
+	codeTwoFactorNotStarted      = "two_factor_not_started"
+	codeInvalidTwoFactorCode     = "invalid_two_factor_code"
+	codeInvalidRecoveryCode      = "invalid_recovery_code"
+	codeTwoFactorNotVerified     = "two_factor_not_verified"
+	codeMailUnavailable          = "mail_unavailable"
+	codeRouteNotFound            = "route_not_found"
+	codeMethodNotAllowed         = "method_not_allowed"
+	codeInternal                 = "internal_error"
+)
+
+// 全てのエラーレスポンスで共通して返すJSONの構造体。
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// エラーの内容。
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// バリデーションに失敗した場合のみ、項目ごとの詳細を返す。
+	Details []fieldError `json:"details,omitempty"`
+	// 問い合わせの際にログと突き合わせるための、リクエストごとのID。
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// バリデーションに失敗した項目の詳細。
+type fieldError struct {
+	// JSONのキー名。
+	Field string `json:"field"`
+	// 失敗したバリデーションのタグ（required, email など）。
+	Reason string `json:"reason"`
+	// タグのパラメーター（min=6 の 6 など）。
+	Param string `json:"param,omitempty"`
+	// リクエストの言語に翻訳したメッセージ。
+	Message string `json:"message"`
+}
+
+// エラーレスポンスを返し、以降のハンドラーを実行しない。
+// メッセージはカタログのキーで指定し、リクエストの言語に翻訳して返す。
+// args には i18n.T と同様に、埋め込む値を名前と値の組で渡す。
+func abortWithError(c *gin.Context, status int, code string, key string, args ...string) {
+	abortWithDetails(c, status, code, translate(c, key, args...), nil)
+}
+
+func abortWithDetails(c *gin.Context, status int, code string, message string, details []fieldError) {
+	c.Header(contentLanguageHeaderKey, requestLocale(c))
+	c.AbortWithStatusJSON(status, errorResponse{
+		Error: errorBody{
+			Code:      code,
+			Message:   message,
+			Details:   details,
+			RequestID: requestID(c),
+		},
+	})
+}
+
+// 予期しないエラーをログに残し、500を返す。
+// DBのエラーメッセージなどの内部情報はクライアントに返さない。
+func abortWithInternalError(c *gin.Context, err error) {
+	requestLogger(c).Error(err)
+
+	c.Error(err)
+	abortWithError(c, http.StatusInternalServerError, codeInternal, "error.internal")
+}
+
+// panic から復帰した際に、他のエラーと同じ形式で 500 を返す。
+// panic の内容とスタックトレースは GinRecovery がログに出力する。
+func abortWithPanic(c *gin.Context) {
+	abortWithError(c, http.StatusInternalServerError, codeInternal, "error.internal")
+}
+
+// 項目ごとのバリデーションのエラーを返す。
+func abortWithFieldErrors(c *gin.Context, details []fieldError) {
+	for i := range details {
+		details[i].Message = fieldErrorMessage(c, details[i])
+	}
+	abortWithDetails(c, http.StatusBadRequest, codeValidationFailed, translate(c, "error.validation_failed"), details)
+}
+
+// 項目ごとのバリデーションのエラーメッセージを、リクエストの言語で返す。
+// カタログにないバリデーションの場合は、汎用のメッセージを返す。
+func fieldErrorMessage(c *gin.Context, fe fieldError) string {
+	key := "validation." + fe.Reason
+	if !i18n.Has(i18n.DefaultLocale, key) {
+		key = "validation.invalid"
+	}
+	return translate(c, key, "field", fe.Field, "param", fe.Param)
+}
+
+// リクエストのバインドに失敗した場合のエラーを返す。
+// バリデーションのエラーは、項目ごとの詳細に変換する。
+func abortWithBindError(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		details := make([]fieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			details = append(details, fieldError{
+				Field:  fe.Field(),
+				Reason: fe.Tag(),
+				Param:  fe.Param(),
+			})
+		}
+		abortWithFieldErrors(c, details)
+		return
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		abortWithFieldErrors(c, []fieldError{{Field: typeError.Field, Reason: "type", Param: typeError.Type.String()}})
+		return
+	}
+
+	requestLogger(c).Debugf("failed to bind request: %s", err)
+	abortWithError(c, http.StatusBadRequest, codeInvalidRequest, "error.invalid_request")
+}
+
+// バリデーションのエラーで、構造体のフィールド名ではなくJSONやクエリのキー名を返すようにする。
+func registerValidatorTagName() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
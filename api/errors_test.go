@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          string
+		requestID     string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ValidationFailed",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   `{"username": "", "password": "12345", "email": "invalid"}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				body := checkError(t, codeValidationFailed, recorder.Body)
+				// 構造体のフィールド名ではなく、JSONのキー名で返すこと。
+				require.ElementsMatch(t, []fieldError{
+					{Field: "username", Reason: "required", Message: "username is required"},
+					{Field: "password", Reason: "min", Param: "6", Message: "password must be at least 6"},
+					{Field: "email", Reason: "email", Message: "email must be a valid email address"},
+				}, body.Error.Details)
+			},
+		},
+		{
+			name:   "TypeMismatch",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   `{"username": "name", "password": "123456", "email": "test@example.com", "age": "twenty"}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				body := checkError(t, codeValidationFailed, recorder.Body)
+				require.Len(t, body.Error.Details, 1)
+				require.Equal(t, "age", body.Error.Details[0].Field)
+			},
+		},
+		{
+			name:   "MalformedJSON",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   `{"username": `,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				body := checkError(t, codeInvalidRequest, recorder.Body)
+				require.Empty(t, body.Error.Details)
+			},
+		},
+		{
+			name:      "RequestIDFromHeader",
+			method:    http.MethodGet,
+			url:       "/not-found",
+			requestID: "client-request-id.1",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				body := checkError(t, codeRouteNotFound, recorder.Body)
+				require.Equal(t, "client-request-id.1", body.Error.RequestID)
+				require.Equal(t, "client-request-id.1", recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+		{
+			name:      "InvalidRequestIDIsReplaced",
+			method:    http.MethodGet,
+			url:       "/not-found",
+			requestID: "invalid id\n",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := checkError(t, codeRouteNotFound, recorder.Body)
+				require.NotEqual(t, "invalid id\n", body.Error.RequestID)
+				require.Equal(t, body.Error.RequestID, recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+		{
+			name:      "TooLongRequestIDIsReplaced",
+			method:    http.MethodGet,
+			url:       "/not-found",
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := checkError(t, codeRouteNotFound, recorder.Body)
+				require.Len(t, body.Error.RequestID, 36)
+			},
+		},
+		{
+			name:      "Panic",
+			method:    http.MethodGet,
+			url:       "/panic",
+			requestID: "panic-request-id",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				body := checkError(t, codeInternal, recorder.Body)
+				require.Equal(t, "panic-request-id", body.Error.RequestID)
+			},
+		},
+		{
+			name:   "MethodNotAllowed",
+			method: http.MethodPut,
+			url:    "/login",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+				checkError(t, codeMethodNotAllowed, recorder.Body)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// DBまで到達しないこと。
+			store := mockdb.NewMockStore(ctrl)
+			server := NewServer(newTestConfig(), store, nil, util.InitLogger(), newTestMetrics())
+			server.router.GET("/panic", func(c *gin.Context) {
+				panic("unexpected")
+			})
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
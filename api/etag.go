@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const (
+	etagHeaderKey    = "ETag"
+	ifMatchHeaderKey = "If-Match"
+)
+
+// 行の version から ETag を作り、レスポンスのヘッダーに設定する。
+func setETag(c *gin.Context, version int64) {
+	c.Header(etagHeaderKey, strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// If-Match ヘッダーから、更新・削除の条件にする version を取り出す。
+// 他のリクエストによる変更を上書きしないよう、ヘッダーがない場合は428を返し、ok に false を返す。
+// * の場合は、version を条件にしない db.AnyVersion を返す。
+// ETag は強い比較で判定するため、弱い ETag（W/"1"）や複数の ETag、
+// version として解釈できない値は、どの行とも一致しないとして412を返し、ok に false を返す。
+func ifMatchVersion(c *gin.Context) (version int64, ok bool) {
+	value := strings.TrimSpace(c.GetHeader(ifMatchHeaderKey))
+	if value == "" {
+		abortWithError(c, http.StatusPreconditionRequired, codePreconditionRequired, "error.precondition_required")
+		return 0, false
+	}
+	if value == "*" {
+		return db.AnyVersion, true
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		abortWithPreconditionFailed(c)
+		return 0, false
+	}
+	version, err = strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		abortWithPreconditionFailed(c)
+		return 0, false
+	}
+	return version, true
+}
+
+// If-Match で指定した version が現在の行と一致しない場合の412を返す。
+func abortWithPreconditionFailed(c *gin.Context) {
+	abortWithError(c, http.StatusPreconditionFailed, codePreconditionFailed, "error.precondition_failed")
+}
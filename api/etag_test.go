@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ifMatch     string
+		wantVersion int64
+		wantOK      bool
+		// ok が false の場合に返すステータスコード。0 の場合は412。
+		wantStatus int
+	}{
+		{
+			name:       "NoHeader",
+			ifMatch:    "",
+			wantOK:     false,
+			wantStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:        "Any",
+			ifMatch:     "*",
+			wantVersion: db.AnyVersion,
+			wantOK:      true,
+		},
+		{
+			name:        "Version",
+			ifMatch:     `"12"`,
+			wantVersion: 12,
+			wantOK:      true,
+		},
+		{
+			name:    "WeakETag",
+			ifMatch: `W/"12"`,
+			wantOK:  false,
+		},
+		{
+			name:    "Unquoted",
+			ifMatch: "12",
+			wantOK:  false,
+		},
+		{
+			name:    "MultipleETags",
+			ifMatch: `"12", "13"`,
+			wantOK:  false,
+		},
+		{
+			name:    "NotNumber",
+			ifMatch: `"abc"`,
+			wantOK:  false,
+		},
+		{
+			name:    "Zero",
+			ifMatch: `"0"`,
+			wantOK:  false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			request, err := http.NewRequest(http.MethodDelete, "/", nil)
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
+			c.Request = request
+
+			// Act
+			version, ok := ifMatchVersion(c)
+
+			// Assert
+			require.Equal(t, tc.wantOK, ok)
+			if tc.wantOK {
+				require.Equal(t, tc.wantVersion, version)
+				return
+			}
+			wantStatus := tc.wantStatus
+			if wantStatus == 0 {
+				wantStatus = http.StatusPreconditionFailed
+			}
+			require.Equal(t, wantStatus, recorder.Code)
+		})
+	}
+}
+
+func TestSetETag(t *testing.T) {
+	// Arrange
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	// Act
+	setETag(c, 7)
+
+	// Assert
+	require.Equal(t, `"7"`, recorder.Header().Get("ETag"))
+}
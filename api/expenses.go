@@ -1,31 +1,24 @@
 package api
 
 import (
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
-	"go.uber.org/zap"
+	"github.com/kokoichi206/account-book-api/util"
 )
 
 // 新規の支出作成用のRequestのpayload。
 type createExpenseRequest struct {
-	UserID     int64  `json:"user_id" binding:"required"`
+	// 省略した場合は、認証したユーザーの支出とする。
+	UserID     int64  `json:"user_id"`
 	CategoryID int64  `json:"category_id" binding:"required"`
-	Amount     int64  `json:"amount" binding:"required"`
-	Comment    string `json:"comment"`
-}
-
-// 出力用のJSONを取得する。
-func (request createExpenseRequest) MustJSONString() string {
-	bytes, err := json.Marshal(request)
-	if err != nil {
-		return ""
-	}
-	return string(bytes)
+	Amount     int64  `json:"amount" binding:"required" log:"secret"`
+	Comment    string `json:"comment" log:"secret"`
 }
 
 // 新規の支出作成用のResponseのpayload。
@@ -37,21 +30,75 @@ type createExpenseResponse struct {
 	FoodReceiptID int64     `json:"food_receipt_id"`
 	Comment       string    `json:"comment"`
 	CreatedAt     time.Time `json:"created_at"`
+	// 更新のたびに増える値。ETag と同じ値。
+	Version int64 `json:"version"`
+}
+
+func newCreateExpenseResponse(expense db.Expense) createExpenseResponse {
+	return createExpenseResponse{
+		ID:            expense.ID,
+		UserID:        expense.UserID,
+		CategoryID:    expense.CategoryID,
+		Amount:        expense.Amount,
+		FoodReceiptID: expense.FoodReceiptID.Int64,
+		Comment:       expense.Comment.String,
+		CreatedAt:     expense.CreatedAt,
+		Version:       expense.Version,
+	}
+}
+
+func newCreateExpenseResponses(expenses []db.Expense) []createExpenseResponse {
+	rsp := make([]createExpenseResponse, 0, len(expenses))
+	for _, expense := range expenses {
+		rsp = append(rsp, newCreateExpenseResponse(expense))
+	}
+	return rsp
 }
 
 // 支出の作成のエンドポイント。
+// 認証したユーザー自身の支出のみ作成でき、他のユーザーの user_id を指定した場合は403を返す。
+// DUPLICATE_WINDOW 以内に同じカテゴリー・金額の支出がある場合は、
+// force=true を指定しない限り登録せずに409を返す。
 func (server *Server) createExpense(c *gin.Context) {
 	var req createExpenseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, err)
+		abortWithBindError(c, err)
+		return
+	}
+	var check duplicateCheckRequest
+	if err := c.ShouldBindQuery(&check); err != nil {
+		abortWithBindError(c, err)
 		return
 	}
 
 	// MAYBE: これはDebugかInfoか。
-	zap.S().Debug(req.MustJSONString())
+	requestLogger(c).Debugw("request", "body", util.Redact(req))
+
+	userID := authUserID(c)
+	if req.UserID != 0 && req.UserID != userID {
+		abortWithError(c, http.StatusForbidden, codePermissionDenied, "error.permission_denied")
+		return
+	}
+
+	if !check.Force {
+		duplicates, err := server.store.ListDuplicateExpenseCandidates(c, db.ListDuplicateExpenseCandidatesParams{
+			UserID:     userID,
+			CategoryID: req.CategoryID,
+			Amount:     req.Amount,
+			Since:      time.Now().Add(-server.config.DuplicateWindow),
+		})
+		if err != nil {
+			abortWithInternalError(c, fmt.Errorf("failed to ListDuplicateExpenseCandidates: %w", err))
+			return
+		}
+		if len(duplicates) > 0 {
+			abortWithDuplicates(c, newCreateExpenseResponses(duplicates))
+			return
+		}
+	}
 
 	arg := db.CreateExpenseParams{
-		UserID:     req.UserID,
+		UserID:     userID,
 		CategoryID: req.CategoryID,
 		Amount:     req.Amount,
 	}
@@ -60,40 +107,21 @@ func (server *Server) createExpense(c *gin.Context) {
 		arg.Comment.String = req.Comment
 	}
 
-	expense, err := server.querier.CreateExpense(c, arg)
+	expense, err := server.store.CreateExpenseTx(c, arg, auditActor(c))
 	if err != nil {
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+		abortWithInternalError(c, err)
 		return
 	}
+	server.metrics.ExpenseCreated()
 
-	rsp := createExpenseResponse{
-		ID:            expense.ID,
-		UserID:        expense.UserID,
-		CategoryID:    expense.CategoryID,
-		Amount:        expense.Amount,
-		FoodReceiptID: expense.FoodReceiptID.Int64,
-		Comment:       expense.Comment.String,
-		CreatedAt:     expense.CreatedAt,
-	}
-
-	c.JSON(http.StatusCreated, rsp)
+	setETag(c, expense.Version)
+	c.JSON(http.StatusCreated, newCreateExpenseResponse(expense))
 }
 
 // 支出一覧取得用のRequestのpayload。
 type getAllExpensesRequest struct {
-	UserID int64 `form:"user_id" binding:"required"`
-}
-
-// 出力用のJSONを取得する。
-func (request getAllExpensesRequest) MustJSONString() string {
-	bytes, err := json.Marshal(request)
-	if err != nil {
-		return ""
-	}
-	return string(bytes)
+	// 省略した場合は、認証したユーザーの支出を返す。
+	UserID int64 `form:"user_id"`
 }
 
 // 支出一覧取得用のResponseのpayload。
@@ -108,45 +136,146 @@ type expenseResponse struct {
 	StoreName  string    `json:"store_name"`
 	Comment    string    `json:"comment"`
 	CreatedAt  time.Time `json:"created_at"`
+	Version    int64     `json:"version"`
+}
+
+func newExpenseResponse(expense db.ListExpensesRow) expenseResponse {
+	if expense.StoreName == nil {
+		expense.StoreName = ""
+	}
+	return expenseResponse{
+		ID:         expense.ID,
+		UserID:     expense.UserID,
+		CategoryID: expense.CategoryID,
+		Amount:     expense.Amount,
+		StoreName:  expense.StoreName.(string),
+		Comment:    expense.Comment.String,
+		CreatedAt:  expense.CreatedAt,
+		Version:    expense.Version,
+	}
 }
 
 // 支出一覧取得用のエンドポイント。
+// 認証したユーザー自身の支出のみ取得でき、他のユーザーの user_id を指定した場合は403を返す。
 func (server *Server) getAllExpenses(c *gin.Context) {
 	var req getAllExpensesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, err)
+		abortWithBindError(c, err)
 		return
 	}
 
 	// MAYBE: これはDebugかInfoか。
-	zap.S().Debug(req.MustJSONString())
+	requestLogger(c).Debugw("request", "body", util.Redact(req))
 
-	listExpenses, err := server.querier.ListExpenses(c, req.UserID)
-	if err != nil {
-		err = fmt.Errorf("failed to ListExpenses: %w", err)
-		zap.S().Error(err)
+	userID := authUserID(c)
+	if req.UserID != 0 && req.UserID != userID {
+		abortWithError(c, http.StatusForbidden, codePermissionDenied, "error.permission_denied")
+		return
+	}
 
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+	listExpenses, err := server.store.ListExpenses(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListExpenses: %w", err))
 		return
 	}
 
 	var rsp getAllExpensesResponse
 	for _, expense := range listExpenses {
-		if expense.StoreName == nil {
-			expense.StoreName = ""
+		rsp.ListExpenseResponse = append(rsp.ListExpenseResponse, newExpenseResponse(expense))
+	}
+
+	c.JSON(http.StatusOK, rsp)
+}
+
+// 支出の更新用のRequestのpayload。
+// 指定しなかった項目は現在の値のまま更新する。
+type updateExpenseRequest struct {
+	CategoryID *int64 `json:"category_id" binding:"omitempty,min=1"`
+	Amount     *int64 `json:"amount" binding:"omitempty,ne=0" log:"secret"`
+	// 空文字列を指定した場合はコメントを削除する。
+	Comment *string `json:"comment" log:"secret"`
+}
+
+// 支出を更新するエンドポイント。
+// 認証したユーザー自身の支出のみ更新できる。
+// If-Match の version が一致する場合のみ更新する。
+func (server *Server) updateExpense(c *gin.Context) {
+	var uri idRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	var req updateExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateExpenseTxParams{
+		ID:         uri.ID,
+		UserID:     authUserID(c),
+		Version:    version,
+		CategoryID: req.CategoryID,
+		Amount:     req.Amount,
+		Comment:    req.Comment,
+	}
+	expense, err := server.store.UpdateExpenseTx(c, arg, auditActor(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.expense_not_found")
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			abortWithPreconditionFailed(c)
+			return
 		}
-		e := expenseResponse{
-			ID:         expense.ID,
-			UserID:     expense.UserID,
-			CategoryID: expense.CategoryID,
-			Amount:     expense.Amount,
-			StoreName:  expense.StoreName.(string),
-			Comment:    expense.Comment.String,
-			CreatedAt:  expense.CreatedAt,
+		if errors.Is(err, db.ErrForeignKeyViolation) {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.category_not_found")
+			return
 		}
-		rsp.ListExpenseResponse = append(rsp.ListExpenseResponse, e)
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateExpenseTx: %w", err))
+		return
 	}
 
-	c.JSON(http.StatusOK, rsp)
+	setETag(c, expense.Version)
+	c.JSON(http.StatusOK, newCreateExpenseResponse(expense))
+}
+
+// 支出をゴミ箱に移すエンドポイント。
+// 認証したユーザー自身の支出のみ削除できる。
+// If-Match の version が一致する場合のみ削除する。
+func (server *Server) deleteExpense(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	arg := db.SoftDeleteExpenseParams{
+		ID:      req.ID,
+		UserID:  authUserID(c),
+		Version: version,
+	}
+	if _, err := server.store.DeleteExpenseTx(c, arg, auditActor(c)); err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.expense_not_found")
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			abortWithPreconditionFailed(c)
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteExpenseTx: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
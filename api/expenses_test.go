@@ -16,6 +16,7 @@ import (
 	"github.com/kokoichi206/account-book-api/auth"
 	mockdb "github.com/kokoichi206/account-book-api/db/mock"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
 	"github.com/kokoichi206/account-book-api/util"
 	"github.com/stretchr/testify/require"
 )
@@ -38,6 +39,12 @@ func TestCreateExpense(t *testing.T) {
 		"amount":  amount,
 		"comment": comment,
 	}
+	otherUserBody := gin.H{
+		"user_id":     userId + 1,
+		"category_id": categoryId,
+		"amount":      amount,
+		"comment":     comment,
+	}
 	expense := db.Expense{
 		ID:            util.RandomID(),
 		UserID:        userId,
@@ -53,8 +60,9 @@ func TestCreateExpense(t *testing.T) {
 	testCases := []struct {
 		name          string
 		body          gin.H
+		query         string
 		setupAuth     func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager)
-		buildStubs    func(querier *mockdb.MockQuerier)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -63,14 +71,27 @@ func TestCreateExpense(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateExpense(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(expense, nil)
+					DoAndReturn(func(_ interface{}, arg db.ListDuplicateExpenseCandidatesParams) ([]db.Expense, error) {
+						require.Equal(t, userId, arg.UserID)
+						require.Equal(t, categoryId, arg.CategoryID)
+						require.Equal(t, amount, arg.Amount)
+						require.WithinDuration(t, time.Now().Add(-10*time.Minute), arg.Since, time.Minute)
+						return []db.Expense{}, nil
+					})
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateExpenseParams, _ db.AuditActor) (db.Expense, error) {
+						require.Equal(t, userId, arg.UserID)
+						return expense, nil
+					})
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -80,19 +101,97 @@ func TestCreateExpense(t *testing.T) {
 				assertBody(t, expense, recorder.Body)
 			},
 		},
+		{
+			// user_id を省略した場合は、認証したユーザーの支出とする。
+			name: "OmittedUserID",
+			body: gin.H{"category_id": categoryId, "amount": amount},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Expense{}, nil)
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateExpenseParams, _ db.AuditActor) (db.Expense, error) {
+						require.Equal(t, userId, arg.UserID)
+						return expense, nil
+					})
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			// 他のユーザーの支出の重複を確認したり、作成したりできない。
+			name: "OtherUsersID",
+			body: otherUserBody,
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				checkBodyContains(t, recorder, codePermissionDenied)
+			},
+		},
+		{
+			name:  "OtherUsersIDWithForce",
+			body:  otherUserBody,
+			query: "?force=true",
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "BindRequestErrorWithMissingParam",
 			body: missingBody,
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateExpense(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(0)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -107,14 +206,125 @@ func TestCreateExpense(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateExpense(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Expense{}, nil)
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Expense{}, sql.ErrConnDone)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "PossibleDuplicate",
+			body: correctBody,
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Expense{expense}, nil)
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+
+				var body struct {
+					Error      errorBody               `json:"error"`
+					Duplicates []createExpenseResponse `json:"duplicates"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, codePossibleDuplicate, body.Error.Code)
+				require.Len(t, body.Duplicates, 1)
+				require.Equal(t, expense.ID, body.Duplicates[0].ID)
+			},
+		},
+		{
+			name:  "ForceSkipsDuplicateCheck",
+			body:  correctBody,
+			query: "?force=true",
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(expense, nil)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidForce",
+			body:  correctBody,
+			query: "?force=maybe",
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ListDuplicateCandidatesDBError",
+			body: correctBody,
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().
+					CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -133,17 +343,18 @@ func TestCreateExpense(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			querier := mockdb.NewMockQuerier(ctrl)
-			tc.buildStubs(querier)
-			manager := auth.NewMockManager(querier)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			manager := auth.NewMockManager(store)
+			manager.UserID = userId
 
-			server := NewServer(util.Config{}, querier, manager, util.InitLogger())
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
 			require.NoError(t, err)
 
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request, err := http.NewRequest(http.MethodPost, url+tc.query, bytes.NewReader(data))
 			require.NoError(t, err)
 			tc.setupAuth(t, request, manager)
 
@@ -213,7 +424,7 @@ func TestGetAllExpenses(t *testing.T) {
 		name          string
 		url           string
 		setupAuth     func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager)
-		buildStubs    func(querier *mockdb.MockQuerier)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -222,14 +433,14 @@ func TestGetAllExpenses(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					ListExpenses(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpenses(gomock.Any(), gomock.Eq(userId)).
 					Times(1).
 					Return(listExpense, nil)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -245,14 +456,14 @@ func TestGetAllExpenses(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
 					ListExpenses(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(listExpenseWithoutComment, nil)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -267,13 +478,13 @@ func TestGetAllExpenses(t *testing.T) {
 			url:  fmt.Sprintf("/expenses?user_id=%d", userId),
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
 					ListExpenses(gomock.Any(), gomock.Any()).
 					Times(0)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -282,18 +493,63 @@ func TestGetAllExpenses(t *testing.T) {
 			},
 		},
 		{
-			name: "BindRequestErrorWithMissingParam",
-			url:  fmt.Sprintf("/expenses"),
+			name: "OKWithoutUserID",
+			url:  "/expenses",
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpenses(gomock.Any(), gomock.Eq(userId)).
+					Times(1).
+					Return(listExpense, nil)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				assertListBody(t, listExpense, recorder.Body)
+			},
+		},
+		{
+			name: "OtherUserID",
+			url:  fmt.Sprintf("/expenses?user_id=%d", userId+1),
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
 					ListExpenses(gomock.Any(), gomock.Any()).
 					Times(0)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				checkBodyContains(t, recorder, codePermissionDenied)
+			},
+		},
+		{
+			name: "BindRequestErrorWithInvalidUserID",
+			url:  "/expenses?user_id=abc",
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addCompleteAuth(t, request, manager)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpenses(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -308,14 +564,14 @@ func TestGetAllExpenses(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
 				addCompleteAuth(t, request, manager)
 			},
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
 					ListExpenses(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.ListExpensesRow{}, sql.ErrConnDone)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -334,11 +590,12 @@ func TestGetAllExpenses(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			querier := mockdb.NewMockQuerier(ctrl)
-			tc.buildStubs(querier)
-			manager := auth.NewMockManager(querier)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			manager := auth.NewMockManager(store)
+			manager.UserID = userId
 
-			server := NewServer(util.Config{}, querier, manager, util.InitLogger())
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
 			recorder := httptest.NewRecorder()
 			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
 			require.NoError(t, err)
@@ -381,3 +638,397 @@ func assertListBody(t *testing.T, listExpense []db.ListExpensesRow, responseBody
 	}
 	require.NotZero(t, expense.CreatedAt)
 }
+
+// APIキーで認証した場合も、キーを発行したユーザーの支出のみ参照・作成できること。
+func TestExpensesScopeWithAPIKey(t *testing.T) {
+	key, prefix, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	apiKey := randomAPIKey(util.RandomID())
+	apiKey.KeyPrefix = prefix
+	apiKey.KeyHash = util.HashToken(key)
+	otherUserID := apiKey.UserID + 1
+	categoryID := util.RandomID()
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ListOwnExpenses",
+			method: http.MethodGet,
+			url:    "/expenses",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpenses(gomock.Any(), gomock.Eq(apiKey.UserID)).
+					Times(1).
+					Return([]db.ListExpensesRow{{ID: util.RandomID(), UserID: apiKey.UserID}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ListOtherUserExpenses",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/expenses?user_id=%d", otherUserID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpenses(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				checkBodyContains(t, recorder, codePermissionDenied)
+			},
+		},
+		{
+			name:   "CreateOtherUserExpense",
+			method: http.MethodPost,
+			url:    "/expenses",
+			body: gin.H{
+				"user_id":     otherUserID,
+				"category_id": categoryID,
+				"amount":      util.RandomExpense(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateExpense(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				checkBodyContains(t, recorder, codePermissionDenied)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetAPIKeyByHash(gomock.Any(), gomock.Eq(apiKey.KeyHash)).
+				Times(1).
+				Return(apiKeyAuthRow(apiKey, i18n.DefaultLocale), nil)
+			store.EXPECT().
+				UpdateAPIKeyLastUsed(gomock.Any(), gomock.Eq(apiKey.ID)).
+				Times(1).
+				Return(nil)
+			tc.buildStubs(store)
+
+			server := NewServer(newTestConfig(), store, auth.NewMockManager(store), util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+			addAPIKeyAuthorization(t, request, key)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteExpense(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+	expenseID := util.RandomID()
+
+	testCases := []struct {
+		name          string
+		url           string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "AnyVersion",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {"*"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteExpenseParams{
+					ID:      expenseID,
+					UserID:  user.ID,
+					Version: db.AnyVersion,
+				}
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.Expense{ID: expenseID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "IfMatch",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteExpenseParams{
+					ID:      expenseID,
+					UserID:  user.ID,
+					Version: 3,
+				}
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.Expense{ID: expenseID, Version: 4}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "VersionMismatch",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{ID: expenseID, Version: 5}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_failed")
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			url:  fmt.Sprintf("/expenses/%d", expenseID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_required")
+			},
+		},
+		{
+			name:   "NotFound",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidID",
+			url:  "/expenses/0",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DBError",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, user.ID, http.MethodDelete, tc.url, nil, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateExpense(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+	expenseID := util.RandomID()
+	amount := int64(-1200)
+	comment := "lunch"
+
+	testCases := []struct {
+		name          string
+		url           string
+		header        http.Header
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			body: gin.H{
+				"amount":  amount,
+				"comment": comment,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateExpenseTxParams{
+					ID:      expenseID,
+					UserID:  user.ID,
+					Version: 3,
+					Amount:  &amount,
+					Comment: &comment,
+				}
+				store.EXPECT().
+					UpdateExpenseTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.Expense{ID: expenseID, UserID: user.ID, Amount: amount, Version: 4}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"4"`, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			url:  fmt.Sprintf("/expenses/%d", expenseID),
+			body: gin.H{"amount": amount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_required")
+			},
+		},
+		{
+			name:   "VersionMismatch",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			body:   gin.H{"amount": amount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{ID: expenseID, Version: 5}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_failed")
+			},
+		},
+		{
+			name:   "NotFound",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			body:   gin.H{"amount": amount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "ZeroAmount",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			body:   gin.H{"amount": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DBError",
+			url:    fmt.Sprintf("/expenses/%d", expenseID),
+			header: http.Header{"If-Match": {`"3"`}},
+			body:   gin.H{"amount": amount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, user.ID, http.MethodPatch, tc.url, tc.body, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
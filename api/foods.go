@@ -0,0 +1,204 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// 食品カタログの作成・更新用のRequestのpayload。
+// 栄養素は0になりうるため required を付けない。
+type foodRequest struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	Calories     float32 `json:"calories" binding:"min=0"`
+	Lipid        float32 `json:"lipid" binding:"min=0"`
+	Carbohydrate float32 `json:"carbohydrate" binding:"min=0"`
+	Protein      float32 `json:"protein" binding:"min=0"`
+}
+
+// 食品カタログのResponseのpayload。
+type foodResponse struct {
+	ID           int64   `json:"id"`
+	Name         string  `json:"name"`
+	Calories     float32 `json:"calories"`
+	Lipid        float32 `json:"lipid"`
+	Carbohydrate float32 `json:"carbohydrate"`
+	Protein      float32 `json:"protein"`
+	// 更新のたびに増える値。ETag と同じ値。
+	Version int64 `json:"version"`
+}
+
+// 食品カタログ一覧取得用のResponseのpayload。
+type listFoodsResponse struct {
+	Foods []foodResponse `json:"foods"`
+}
+
+func newFoodResponse(food db.FoodContent) foodResponse {
+	return foodResponse{
+		ID:           food.ID,
+		Name:         food.Name,
+		Calories:     food.Calories,
+		Lipid:        food.Lipid,
+		Carbohydrate: food.Carbohydrate,
+		Protein:      food.Protein,
+		Version:      food.Version,
+	}
+}
+
+// 食品カタログの一覧を取得するエンドポイント。
+func (server *Server) listFoods(c *gin.Context) {
+	var req pageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	arg := db.ListFoodContentsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+	foods, err := server.store.ListFoodContents(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListFoodContents: %w", err))
+		return
+	}
+
+	rsp := listFoodsResponse{
+		Foods: []foodResponse{},
+	}
+	for _, food := range foods {
+		rsp.Foods = append(rsp.Foods, newFoodResponse(food))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// 食品カタログに食品を登録するエンドポイント。
+func (server *Server) createFood(c *gin.Context) {
+	var req foodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	arg := db.CreateFoodContentParams{
+		Name:         req.Name,
+		Calories:     req.Calories,
+		Lipid:        req.Lipid,
+		Carbohydrate: req.Carbohydrate,
+		Protein:      req.Protein,
+	}
+	food, err := server.store.CreateFoodContent(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to CreateFoodContent: %w", err))
+		return
+	}
+
+	setETag(c, food.Version)
+	c.JSON(http.StatusCreated, newFoodResponse(food))
+}
+
+// 食品カタログの食品を更新するエンドポイント。
+// If-Match の version が一致する場合のみ更新する。
+func (server *Server) updateFood(c *gin.Context) {
+	var uri idRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	var req foodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := server.currentFoodVersion(c, uri.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateFoodContentParams{
+		Name:         req.Name,
+		Calories:     req.Calories,
+		Lipid:        req.Lipid,
+		Carbohydrate: req.Carbohydrate,
+		Protein:      req.Protein,
+		ID:           uri.ID,
+		Version:      version,
+	}
+	food, err := server.store.UpdateFoodContent(c, arg)
+	if err != nil {
+		// 取得してから更新するまでに、他のリクエストで更新・削除された。
+		if err == sql.ErrNoRows {
+			abortWithPreconditionFailed(c)
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateFoodContent: %w", err))
+		return
+	}
+
+	setETag(c, food.Version)
+	c.JSON(http.StatusOK, newFoodResponse(food))
+}
+
+// 食品カタログから食品を削除するエンドポイント。
+// レシートから参照されている食品は削除できない。
+// If-Match の version が一致する場合のみ削除する。
+func (server *Server) deleteFood(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := server.currentFoodVersion(c, req.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.DeleteFoodContentParams{
+		ID:      req.ID,
+		Version: version,
+	}
+	if _, err := server.store.DeleteFoodContent(c, arg); err != nil {
+		// 取得してから削除するまでに、他のリクエストで更新・削除された。
+		if err == sql.ErrNoRows {
+			abortWithPreconditionFailed(c)
+			return
+		}
+		if errors.Is(err, db.ErrForeignKeyViolation) {
+			abortWithError(c, http.StatusConflict, codeResourceInUse, "error.food_in_use")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteFoodContent: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// 食品の現在の version を取得し、If-Match と一致するか確認する。
+// 食品の更新は監査ログの対象外で、トランザクションを使わないため、
+// 取得した version を UPDATE の条件にして、その間の変更を検出する。
+// 一致しない場合はエラーレスポンスを返し、ok に false を返す。
+func (server *Server) currentFoodVersion(c *gin.Context, id int64) (version int64, ok bool) {
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return 0, false
+	}
+	food, err := server.store.GetFoodContent(c, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.food_not_found")
+			return 0, false
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetFoodContent: %w", err))
+		return 0, false
+	}
+	if version, err = db.CheckVersion(food.Version, expected); err != nil {
+		abortWithPreconditionFailed(c)
+		return 0, false
+	}
+	return version, true
+}
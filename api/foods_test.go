@@ -0,0 +1,359 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomFood() db.FoodContent {
+	return db.FoodContent{
+		ID:           util.RandomID(),
+		Name:         util.RandomString(8),
+		Calories:     120.5,
+		Lipid:        3.2,
+		Carbohydrate: 20,
+		Protein:      4.1,
+		Version:      util.RandomInt(1, 10),
+	}
+}
+
+func TestListFoods(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	admin := randomUser(auth.RoleAdmin)
+	foods := []db.FoodContent{randomFood(), randomFood()}
+	store := mockdb.NewMockStore(ctrl)
+	addAdminAuthMock(store, admin)
+	arg := db.ListFoodContentsParams{
+		Limit:  10,
+		Offset: 0,
+	}
+	store.EXPECT().
+		ListFoodContents(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(foods, nil)
+
+	// Act
+	recorder := serveAdminRequest(t, store, admin.ID, http.MethodGet, "/admin/foods?page_id=1&page_size=10", nil)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	data, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	var rsp listFoodsResponse
+	require.NoError(t, json.Unmarshal(data, &rsp))
+	require.Len(t, rsp.Foods, len(foods))
+	require.Equal(t, newFoodResponse(foods[0]), rsp.Foods[0])
+}
+
+func TestCreateFood(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	food := randomFood()
+	body := gin.H{
+		"name":         food.Name,
+		"calories":     food.Calories,
+		"lipid":        food.Lipid,
+		"carbohydrate": food.Carbohydrate,
+		"protein":      food.Protein,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateFoodContentParams{
+					Name:         food.Name,
+					Calories:     food.Calories,
+					Lipid:        food.Lipid,
+					Carbohydrate: food.Carbohydrate,
+					Protein:      food.Protein,
+				}
+				store.EXPECT().
+					CreateFoodContent(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(food, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				checkBodyContains(t, recorder, food.Name)
+			},
+		},
+		{
+			name: "BindRequestErrorWithNegativeCalories",
+			body: gin.H{
+				"name":     food.Name,
+				"calories": -1,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DBError",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFoodContent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodContent{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequest(t, store, admin.ID, http.MethodPost, "/admin/foods", tc.body)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateFood(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	food := randomFood()
+	etag := fmt.Sprintf(`"%d"`, food.Version)
+
+	testCases := []struct {
+		name          string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Eq(food.ID)).
+					Times(1).
+					Return(food, nil)
+				arg := db.UpdateFoodContentParams{
+					Name:    food.Name,
+					ID:      food.ID,
+					Version: food.Version,
+				}
+				updated := food
+				updated.Version++
+				store.EXPECT().
+					UpdateFoodContent(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, fmt.Sprintf(`"%d"`, food.Version+1), recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:   "VersionMismatch",
+			header: http.Header{"If-Match": {fmt.Sprintf(`"%d"`, food.Version+1)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Eq(food.ID)).
+					Times(1).
+					Return(food, nil)
+				store.EXPECT().
+					UpdateFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			// 取得してから更新するまでに、他のリクエストで更新された。
+			name:   "ConcurrentUpdate",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Eq(food.ID)).
+					Times(1).
+					Return(food, nil)
+				store.EXPECT().
+					UpdateFoodContent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodContent{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodContent{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpdateFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/foods/%d", food.ID)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, admin.ID, http.MethodPut, url, gin.H{"name": food.Name}, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteFood(t *testing.T) {
+	admin := randomUser(auth.RoleAdmin)
+	food := randomFood()
+	etag := fmt.Sprintf(`"%d"`, food.Version)
+
+	testCases := []struct {
+		name          string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Eq(food.ID)).
+					Times(1).
+					Return(food, nil)
+				arg := db.DeleteFoodContentParams{ID: food.ID, Version: food.Version}
+				store.EXPECT().
+					DeleteFoodContent(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(food, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					DeleteFoodContent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:   "InUse",
+			header: http.Header{"If-Match": {etag}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFoodContent(gomock.Any(), gomock.Eq(food.ID)).
+					Times(1).
+					Return(food, nil)
+				store.EXPECT().
+					DeleteFoodContent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodContent{}, db.ErrForeignKeyViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, admin)
+			tc.buildStubs(store)
+			url := fmt.Sprintf("/admin/foods/%d", food.ID)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, admin.ID, http.MethodDelete, url, nil, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
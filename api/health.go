@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/db/migration"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/kokoichi206/account-book-api/worker"
+	"go.uber.org/zap"
+)
+
+// 死活監視とメトリクスの収集のためのパス。定期的に呼ばれるため、ログは debug レベルで出力する。
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+	metricsPath   = "/metrics"
+)
+
+// 確認結果の状態。
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+// 状態を readyz で公開するバックグラウンドのワーカー。
+type WorkerStatusReporter interface {
+	Status() worker.Status
+}
+
+type healthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// 依存先ごとの確認結果。
+type checkResult struct {
+	Status string `json:"status"`
+	// 確認にかかった時間（ミリ秒）。
+	LatencyMs float64 `json:"latency_ms"`
+	// 失敗した理由。内部の情報を含めないよう、エラーの詳細はログにのみ出力する。
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// 依存先を確認する関数。失敗した場合はエラーを返す。
+type healthCheckFunc func(ctx context.Context) (details map[string]interface{}, err error)
+
+// 状態を確認したいワーカーを登録する。
+// サーバーを起動する前に呼び出すこと。
+func (server *Server) RegisterWorker(name string, w WorkerStatusReporter) {
+	server.workers[name] = w
+}
+
+// プロセスが応答できるか（liveness）を返す。
+// DBの障害でプロセスが再起動されないよう、依存先は確認しない。
+func (server *Server) getLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResponse{Status: healthStatusOK})
+}
+
+// リクエストを受け付けられるか（readiness）を、依存先ごとに確認して返す。
+// 1つでも失敗した場合は 503 を返す。
+func (server *Server) getReadiness(c *gin.Context) {
+	checks := map[string]healthCheckFunc{
+		"database":   server.checkDatabase,
+		"migrations": server.checkMigrations,
+	}
+	for name, w := range server.workers {
+		checks["worker."+name] = checkWorker(w)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), server.config.HealthCheckTimeout)
+	defer cancel()
+
+	// 全体の時間がタイムアウトを超えないよう、並行して確認する。
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	rsp := healthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]checkResult, len(checks)),
+	}
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check healthCheckFunc) {
+			defer wg.Done()
+
+			start := time.Now()
+			details, err := check(ctx)
+			result := checkResult{
+				Status:    healthStatusOK,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+				Details:   details,
+			}
+			if err != nil {
+				util.Logger(ctx).Warn("readiness check failed", zap.String("check", name), zap.Error(err))
+				result.Status = healthStatusFail
+				result.Message = checkFailureMessage(name, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			rsp.Checks[name] = result
+			if err != nil {
+				rsp.Status = healthStatusFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if rsp.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, rsp)
+}
+
+// 失敗した理由を、内部の情報を含まない形で返す。
+func checkFailureMessage(name string, err error) string {
+	var mismatch *migrationMismatchError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.As(err, &mismatch), errors.Is(err, errWorkerNotRunning):
+		return err.Error()
+	}
+	return name + " is unavailable"
+}
+
+func (server *Server) checkDatabase(ctx context.Context) (map[string]interface{}, error) {
+	return nil, server.store.Ping(ctx)
+}
+
+// DBのマイグレーションのバージョンが、想定しているバージョンと一致しない。
+type migrationMismatchError struct {
+	version  uint
+	expected uint
+	dirty    bool
+}
+
+func (e *migrationMismatchError) Error() string {
+	if e.dirty {
+		return fmt.Sprintf("migration %d is dirty", e.version)
+	}
+	return fmt.Sprintf("migration version is %d, expected %d", e.version, e.expected)
+}
+
+func (server *Server) checkMigrations(ctx context.Context) (map[string]interface{}, error) {
+	expected, err := migration.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"version":          version,
+		"expected_version": expected,
+		"dirty":            dirty,
+	}
+	// ローリングデプロイ中は、新しいバージョンで先にマイグレーションされたDBを古いサーバーが使う。
+	// マイグレーションは後方互換に保つ前提とし、想定より新しいバージョンは許容する。
+	if dirty || version < expected {
+		return details, &migrationMismatchError{version: version, expected: expected, dirty: dirty}
+	}
+	return details, nil
+}
+
+var errWorkerNotRunning = errors.New("worker is not running")
+
+func checkWorker(w WorkerStatusReporter) healthCheckFunc {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		status := w.Status()
+		details := map[string]interface{}{}
+		if !status.LastRunAt.IsZero() {
+			details["last_run_at"] = status.LastRunAt
+		}
+		// 一時的な失敗は次の実行で回復しうるため、最後のエラーは報告のみとする。
+		if status.LastError != nil {
+			details["last_error"] = "last run failed"
+		}
+		if !status.Running {
+			return details, errWorkerNotRunning
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/db/migration"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/kokoichi206/account-book-api/worker"
+	"github.com/stretchr/testify/require"
+)
+
+type stubWorker struct {
+	status worker.Status
+}
+
+func (w stubWorker) Status() worker.Status {
+	return w.status
+}
+
+func TestGetLiveness(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// DBに障害があっても、liveness は確認しないこと。
+	store := mockdb.NewMockStore(ctrl)
+	server := NewServer(newTestConfig(), store, nil, util.InitLogger(), newTestMetrics())
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	// Act
+	server.router.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+}
+
+func TestGetReadiness(t *testing.T) {
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+	running := worker.Status{Running: true, LastRunAt: time.Now()}
+
+	testCases := []struct {
+		name          string
+		worker        worker.Status
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse)
+	}{
+		{
+			name:   "OK",
+			worker: running,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, healthStatusOK, rsp.Status)
+				require.Len(t, rsp.Checks, 3)
+				for name, check := range rsp.Checks {
+					require.Equalf(t, healthStatusOK, check.Status, "check [%s]", name)
+					require.GreaterOrEqual(t, check.LatencyMs, 0.0)
+				}
+				require.Equal(t, float64(latest), rsp.Checks["migrations"].Details["version"])
+				require.Contains(t, rsp.Checks["worker.account_deletion"].Details, "last_run_at")
+			},
+		},
+		{
+			name:   "NewerMigration",
+			worker: running,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest+1, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "DatabaseUnavailable",
+			worker: running,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(uint(0), false, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, healthStatusFail, rsp.Status)
+				require.Equal(t, healthStatusFail, rsp.Checks["database"].Status)
+				require.Equal(t, "database is unavailable", rsp.Checks["database"].Message)
+				require.Equal(t, healthStatusOK, rsp.Checks["worker.account_deletion"].Status)
+				require.NotContains(t, recorder.Body.String(), sql.ErrConnDone.Error())
+			},
+		},
+		{
+			name:   "DatabaseTimeout",
+			worker: running,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).DoAndReturn(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, "timed out", rsp.Checks["database"].Message)
+				require.Equal(t, healthStatusOK, rsp.Checks["migrations"].Status)
+			},
+		},
+		{
+			name:   "OldMigration",
+			worker: running,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest-1, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, healthStatusFail, rsp.Checks["migrations"].Status)
+				require.Contains(t, rsp.Checks["migrations"].Message, "expected")
+			},
+		},
+		{
+			name:   "DirtyMigration",
+			worker: running,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Contains(t, rsp.Checks["migrations"].Message, "dirty")
+			},
+		},
+		{
+			name:   "WorkerStopped",
+			worker: worker.Status{Running: false, LastRunAt: time.Now(), LastError: sql.ErrConnDone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				check := rsp.Checks["worker.account_deletion"]
+				require.Equal(t, healthStatusFail, check.Status)
+				require.Equal(t, errWorkerNotRunning.Error(), check.Message)
+				require.Contains(t, check.Details, "last_error")
+				require.NotContains(t, recorder.Body.String(), sql.ErrConnDone.Error())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config := newTestConfig()
+			config.HealthCheckTimeout = 100 * time.Millisecond
+			server := NewServer(config, store, nil, util.InitLogger(), newTestMetrics())
+			server.RegisterWorker("account_deletion", stubWorker{status: tc.worker})
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			var rsp healthResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			tc.checkResponse(t, recorder, rsp)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const (
+	// 同じリクエストの再送であることを示すキーを送信するヘッダー名。
+	idempotencyKeyHeaderKey = "Idempotency-Key"
+	// 保存したレスポンスを再送したことを示すヘッダー名。
+	idempotentReplayedHeaderKey = "Idempotent-Replayed"
+	// クライアントから受け取る Idempotency-Key の最大長。
+	maxIdempotencyKeyLength = 255
+	// 処理中のキーを、処理が中断されたとみなして再利用できるようにするまでの時間。
+	// サーバーが異常終了して完了を記録できなかった場合も、再送できるようにする。
+	idempotencyLockTimeout = time.Minute
+)
+
+// 保存したレスポンスを再送する際に、あわせて返すヘッダー。
+var idempotencyReplayHeaders = []string{
+	"Content-Type",
+	contentLanguageHeaderKey,
+	etagHeaderKey,
+}
+
+// Idempotency-Key ヘッダーが付与されたリクエストのレスポンスを保存し、
+// 同じキーで再送された場合は処理を実行せずに保存したレスポンスを返すmiddleware。
+//
+// キーはユーザーごとに区別し、IDEMPOTENCY_KEY_TTL の間保存する。
+// 同じキーで異なるリクエストが送られた場合は422を、
+// 最初のリクエストが処理中の場合は409を返す。
+// 5xx のレスポンスは保存せず、同じキーで再試行できるようにする。
+// 重複の可能性による409も保存せず、同じキーのまま ?force=true を付けて再送できるようにする。
+func (server *Server) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, http.StatusBadRequest, codeInvalidRequest, "error.invalid_idempotency_key")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, codeInvalidRequest, "error.invalid_request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID := authUserID(c)
+		fingerprint := requestFingerprint(c.Request, body)
+		now := time.Now()
+		_, err = server.store.CreateIdempotencyKey(c, db.CreateIdempotencyKeyParams{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(server.config.IdempotencyKeyTTL),
+			StaleBefore: now.Add(-idempotencyLockTimeout),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			server.replayIdempotentResponse(c, userID, key, fingerprint)
+			return
+		}
+		if err != nil {
+			abortWithInternalError(c, err)
+			return
+		}
+
+		writer := &recordingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// クライアントが切断しても結果を記録できるよう、リクエストの context は使わない。
+		ctx := context.Background()
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusConflict {
+			err := server.store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{UserID: userID, Key: key})
+			if err != nil {
+				requestLogger(c).Warn(fmt.Errorf("failed to DeleteIdempotencyKey: %w", err))
+			}
+			return
+		}
+
+		headers, err := json.Marshal(replayHeaders(writer.Header()))
+		if err != nil {
+			requestLogger(c).Warn(fmt.Errorf("failed to marshal response headers: %w", err))
+			return
+		}
+		err = server.store.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+			StatusCode:      int32(status),
+			ResponseHeaders: headers,
+			ResponseBody:    writer.body.Bytes(),
+			UserID:          userID,
+			Key:             key,
+		})
+		if err != nil {
+			requestLogger(c).Warn(fmt.Errorf("failed to CompleteIdempotencyKey: %w", err))
+		}
+	}
+}
+
+// 保存済みのキーで送られたリクエストに対して、保存したレスポンスを返す。
+func (server *Server) replayIdempotentResponse(c *gin.Context, userID int64, key, fingerprint string) {
+	saved, err := server.store.GetIdempotencyKey(c, db.GetIdempotencyKeyParams{UserID: userID, Key: key})
+	if err != nil {
+		// 確認するまでの間に期限切れで削除された場合も、再送を促すため500とする。
+		abortWithInternalError(c, err)
+		return
+	}
+	if saved.Fingerprint != fingerprint {
+		abortWithError(c, http.StatusUnprocessableEntity, codeIdempotencyKeyReused, "error.idempotency_key_reused")
+		return
+	}
+	if !saved.StatusCode.Valid {
+		abortWithError(c, http.StatusConflict, codeIdempotencyKeyInProgress, "error.idempotency_key_in_progress")
+		return
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal(saved.ResponseHeaders, &headers); err != nil {
+		abortWithInternalError(c, err)
+		return
+	}
+	for name, value := range headers {
+		c.Header(name, value)
+	}
+	c.Header(idempotentReplayedHeaderKey, "true")
+	c.Data(int(saved.StatusCode.Int32), headers["Content-Type"], saved.ResponseBody)
+	c.Abort()
+}
+
+// メソッド・パス・クエリ・ボディから、同じリクエストかを判定するための値を作る。
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// レスポンスのヘッダーのうち、再送時に返すものを取り出す。
+func replayHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for _, name := range idempotencyReplayHeaders {
+		if value := header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// 書き込まれたレスポンスのボディを記録する gin.ResponseWriter。
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
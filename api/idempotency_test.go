@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	userID := util.RandomID()
+	key := util.RandomString(16)
+	body := `{"amount":100}`
+	fingerprint := requestFingerprint(httptest.NewRequest(http.MethodPost, "/idempotent", nil), []byte(body))
+	completed := db.IdempotencyKey{
+		UserID:          userID,
+		Key:             key,
+		Fingerprint:     fingerprint,
+		StatusCode:      sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+		ResponseHeaders: json.RawMessage(`{"Content-Type":"application/json; charset=utf-8","ETag":"\"1\""}`),
+		ResponseBody:    []byte(`{"id":1}`),
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		handlerStatus int
+		buildStubs    func(store *mockdb.MockStore)
+		expectHandled bool
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "NoKey",
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectHandled: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:          "TooLongKey",
+			key:           strings.Repeat("a", maxIdempotencyKeyLength+1),
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				checkBodyContains(t, recorder, codeInvalidRequest)
+			},
+		},
+		{
+			name:          "FirstRequest",
+			key:           key,
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+						require.Equal(t, userID, arg.UserID)
+						require.Equal(t, key, arg.Key)
+						require.Equal(t, fingerprint, arg.Fingerprint)
+						require.True(t, arg.ExpiresAt.After(arg.StaleBefore))
+						return db.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}, nil
+					})
+				store.EXPECT().
+					CompleteIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CompleteIdempotencyKeyParams) error {
+						require.Equal(t, int32(http.StatusCreated), arg.StatusCode)
+						require.JSONEq(t, `{"Content-Type":"application/json; charset=utf-8","ETag":"\"1\""}`, string(arg.ResponseHeaders))
+						require.JSONEq(t, `{"id":1}`, string(arg.ResponseBody))
+						return nil
+					})
+			},
+			expectHandled: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeaderKey))
+			},
+		},
+		{
+			name:          "Replay",
+			key:           key,
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{UserID: userID, Key: key})).
+					Times(1).
+					Return(completed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeaderKey))
+				require.Equal(t, `"1"`, recorder.Header().Get(etagHeaderKey))
+				require.JSONEq(t, `{"id":1}`, recorder.Body.String())
+			},
+		},
+		{
+			name:          "ReusedWithDifferentRequest",
+			key:           key,
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := completed
+				other.Fingerprint = util.RandomString(64)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(other, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				checkBodyContains(t, recorder, codeIdempotencyKeyReused)
+			},
+		},
+		{
+			name:          "InProgress",
+			key:           key,
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				checkBodyContains(t, recorder, codeIdempotencyKeyInProgress)
+			},
+		},
+		{
+			// 5xx は保存せず、同じキーで再試行できるようにする。
+			name:          "HandlerInternalError",
+			key:           key,
+			handlerStatus: http.StatusInternalServerError,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}, nil)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserID: userID, Key: key})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CompleteIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectHandled: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			// 重複の可能性による409は保存せず、同じキーで force=true を付けて再送できるようにする。
+			name:          "HandlerConflict",
+			key:           key,
+			handlerStatus: http.StatusConflict,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}, nil)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserID: userID, Key: key})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CompleteIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectHandled: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:          "CreateDBError",
+			key:           key,
+			handlerStatus: http.StatusCreated,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := &Server{config: newTestConfig(), store: store}
+
+			handled := false
+			router := gin.New()
+			router.POST(
+				"/idempotent",
+				func(ctx *gin.Context) {
+					ctx.Set(authUserIDKey, userID)
+				},
+				server.idempotencyMiddleware(),
+				func(ctx *gin.Context) {
+					handled = true
+					// 後続のハンドラーがボディを読めること。
+					data, err := ctx.GetRawData()
+					require.NoError(t, err)
+					require.Equal(t, body, string(data))
+					setETag(ctx, 1)
+					ctx.JSON(tc.handlerStatus, gin.H{"id": 1})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/idempotent", bytes.NewBufferString(body))
+			require.NoError(t, err)
+			if tc.key != "" {
+				request.Header.Set(idempotencyKeyHeaderKey, tc.key)
+			}
+
+			// Act
+			router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, tc.expectHandled, handled)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	// Arrange
+	body := []byte(`{"amount":100}`)
+	base := httptest.NewRequest(http.MethodPost, "/expenses", nil)
+
+	// Act
+	fingerprint := requestFingerprint(base, body)
+
+	// Assert
+	require.Equal(t, fingerprint, requestFingerprint(httptest.NewRequest(http.MethodPost, "/expenses", nil), body))
+	require.NotEqual(t, fingerprint, requestFingerprint(base, []byte(`{"amount":200}`)))
+	require.NotEqual(t, fingerprint, requestFingerprint(httptest.NewRequest(http.MethodPost, "/receipts", nil), body))
+	require.NotEqual(t, fingerprint, requestFingerprint(httptest.NewRequest(http.MethodPost, "/expenses?force=true", nil), body))
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/i18n"
+)
+
+const (
+	acceptLanguageHeaderKey  = "Accept-Language"
+	contentLanguageHeaderKey = "Content-Language"
+	// DBから取得したユーザーの言語設定を gin.Context に保持する際のキー。
+	userLocaleKey = "user_locale"
+)
+
+// レスポンスのメッセージに使う言語を決める。
+//
+// Accept-Language で対応している言語が指定されていればそれを、
+// なければ認証時に取得したユーザーのプロフィールの言語設定を使う。
+// どちらもない場合は既定の言語とする。
+func requestLocale(c *gin.Context) string {
+	if locale, ok := i18n.ParseAcceptLanguage(c.GetHeader(acceptLanguageHeaderKey)); ok {
+		return locale
+	}
+	return userLocale(c)
+}
+
+// 認証されたユーザーのプロフィールの言語設定を返す。
+// メールなど、リクエストの言語に関わらずユーザーに届けるメッセージに使う。
+func userLocale(c *gin.Context) string {
+	if locale := c.GetString(userLocaleKey); i18n.IsSupported(locale) {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
+
+// ユーザーのプロフィールの言語設定を、以降のメッセージに使えるようにする。
+// 認証済みのルートでは authMiddleware が設定するため、
+// ログインや言語設定の変更など、認証ユーザーが変わる・言語が変わる場合にのみ呼び出す。
+func setUserLocale(c *gin.Context, locale string) {
+	c.Set(userLocaleKey, locale)
+}
+
+// キーに対応するメッセージを、リクエストの言語で返す。
+func translate(c *gin.Context, key string, args ...string) string {
+	return i18n.T(requestLocale(c), key, args...)
+}
@@ -0,0 +1,346 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizedErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		acceptLanguage string
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "DefaultIsEnglish",
+			method: http.MethodGet,
+			url:    "/expenses",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, i18n.English, recorder.Header().Get(contentLanguageHeaderKey))
+				body := checkError(t, codeUnauthenticated, recorder.Body)
+				require.Equal(t, "cannot find cookie", body.Error.Message)
+			},
+		},
+		{
+			name:           "Japanese",
+			method:         http.MethodGet,
+			url:            "/expenses",
+			acceptLanguage: "ja-JP,ja;q=0.9,en;q=0.8",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, i18n.Japanese, recorder.Header().Get(contentLanguageHeaderKey))
+				body := checkError(t, codeUnauthenticated, recorder.Body)
+				require.Equal(t, "Cookieが見つかりません", body.Error.Message)
+			},
+		},
+		{
+			name:           "UnsupportedLanguageFallsBackToDefault",
+			method:         http.MethodGet,
+			url:            "/expenses",
+			acceptLanguage: "fr-FR",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := checkError(t, codeUnauthenticated, recorder.Body)
+				require.Equal(t, "cannot find cookie", body.Error.Message)
+			},
+		},
+		{
+			name:           "JapaneseValidationDetails",
+			method:         http.MethodPost,
+			url:            "/users",
+			body:           `{"username": "name", "password": "12345", "email": "test@example.com"}`,
+			acceptLanguage: "ja",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				body := checkError(t, codeValidationFailed, recorder.Body)
+				require.Equal(t, "入力内容に誤りがあります", body.Error.Message)
+				require.Len(t, body.Error.Details, 1)
+				require.Equal(t, "passwordは6以上で入力してください", body.Error.Details[0].Message)
+			},
+		},
+		{
+			name:           "EnglishValidationDetails",
+			method:         http.MethodPost,
+			url:            "/users",
+			body:           `{"username": "name", "password": "123456", "email": "invalid"}`,
+			acceptLanguage: "en",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := checkError(t, codeValidationFailed, recorder.Body)
+				require.Len(t, body.Error.Details, 1)
+				require.Equal(t, "email must be a valid email address", body.Error.Details[0].Message)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := NewServer(newTestConfig(), store, nil, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			if tc.acceptLanguage != "" {
+				request.Header.Set(acceptLanguageHeaderKey, tc.acceptLanguage)
+			}
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// Accept-Language がない場合は、プロフィールの言語設定を使うこと。
+func TestLocalizedErrorResponseWithProfileLocale(t *testing.T) {
+	key, _, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	apiKey := randomAPIKey(util.RandomID())
+	apiKey.KeyHash = util.HashToken(key)
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		buildStubs     func(store *mockdb.MockStore)
+		setupAuth      func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager)
+		wantMessage    string
+	}{
+		{
+			// ユーザーを取得しないハンドラーでも、プロフィールの言語設定を使う。
+			name: "SessionProfileLocale",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				manager.Locale = i18n.Japanese
+				addCompleteAuth(t, request, manager)
+			},
+			wantMessage: "入力内容に誤りがあります",
+		},
+		{
+			name: "APIKeyProfileLocale",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Eq(apiKey.KeyHash)).
+					Times(1).
+					Return(apiKeyAuthRow(apiKey, i18n.Japanese), nil)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(nil)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				addAPIKeyAuthorization(t, request, key)
+			},
+			wantMessage: "入力内容に誤りがあります",
+		},
+		{
+			name:           "AcceptLanguageTakesPrecedence",
+			acceptLanguage: "en",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
+				manager.Locale = i18n.Japanese
+				addCompleteAuth(t, request, manager)
+			},
+			wantMessage: "request validation failed",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+				CreateExpense(gomock.Any(), gomock.Any()).
+				Times(0)
+			manager := auth.NewMockManager(store)
+			manager.UserID = apiKey.UserID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/expenses", strings.NewReader(`{"amount": -1}`))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, manager)
+			if tc.acceptLanguage != "" {
+				request.Header.Set(acceptLanguageHeaderKey, tc.acceptLanguage)
+			}
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			body := checkError(t, codeValidationFailed, recorder.Body)
+			require.Equal(t, tc.wantMessage, body.Error.Message)
+		})
+	}
+}
+
+func TestListCategoriesWithDisplayName(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	categories := []db.Category{
+		{ID: 1, Name: "food"},
+		{ID: 2, Name: "custom category"},
+	}
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCategories(gomock.Any()).
+		Times(1).
+		Return(categories, nil)
+	// authのmiddlewareを通すため。
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+	manager := auth.NewMockManager(store)
+	manager.UserID = util.RandomID()
+
+	server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/categories", nil)
+	require.NoError(t, err)
+	addCompleteAuth(t, request, manager)
+	request.Header.Set(acceptLanguageHeaderKey, "ja")
+
+	// Act
+	server.router.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var rsp listCategoriesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, []categoryResponse{
+		{ID: 1, Name: "food", DisplayName: "食費"},
+		// 独自のカテゴリーは名前をそのまま返す。
+		{ID: 2, Name: "custom category", DisplayName: "custom category"},
+	}, rsp.Categories)
+}
+
+// api パッケージで使っているメッセージのキーが、全ての言語のカタログに存在すること。
+// カタログへの追加漏れがあると、このテストが失敗する。
+func TestCatalogsCoverMessageKeys(t *testing.T) {
+	// Arrange
+	keys := collectMessageKeys(t)
+	require.NotEmpty(t, keys)
+
+	for _, locale := range []string{i18n.Japanese, i18n.English} {
+		for _, key := range keys {
+			// Assert
+			require.Truef(t, i18n.Has(locale, key), "key [%s] is missing from [%s]", key, locale)
+		}
+	}
+}
+
+// ソースコードから、メッセージのキーとバリデーションのタグを集める。
+func collectMessageKeys(t *testing.T) []string {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		require.NoError(t, err)
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			// abortWithError(c, status, code, key, ...) のキー。
+			case *ast.CallExpr:
+				if ident, ok := n.Fun.(*ast.Ident); ok && ident.Name == "abortWithError" && len(n.Args) >= 4 {
+					if key, ok := stringLiteral(n.Args[3]); ok {
+						seen[key] = true
+					}
+				}
+			// fieldError{Reason: ...} のバリデーション。
+			case *ast.KeyValueExpr:
+				if ident, ok := n.Key.(*ast.Ident); ok && ident.Name == "Reason" {
+					if reason, ok := stringLiteral(n.Value); ok {
+						seen["validation."+reason] = true
+					}
+				}
+			// binding:"..." のバリデーション。
+			case *ast.Field:
+				if n.Tag == nil {
+					break
+				}
+				tag, err := strconv.Unquote(n.Tag.Value)
+				require.NoError(t, err)
+				for _, rule := range strings.Split(reflect.StructTag(tag).Get("binding"), ",") {
+					name := strings.SplitN(rule, "=", 2)[0]
+					// 値を検証しない指定は除く。
+					if name == "" || name == "omitempty" || name == "dive" {
+						continue
+					}
+					seen["validation."+name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
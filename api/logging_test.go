@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// ハンドラー、アクセスログ、クエリのログが、同じリクエストIDとユーザーIDで出力されること。
+func TestRequestLogger(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		authUserID int64
+		buildStubs func(store *mockdb.MockStore)
+		// ハンドラーが出力するログのメッセージ。
+		handlerMessage string
+		// クエリのログに出力されるメソッド名。
+		query string
+	}{
+		{
+			name:   "Anonymous",
+			method: http.MethodPost,
+			url:    "/login",
+			body:   `{"email": "unknown@example.com", "password": "password"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			handlerMessage: "The Email [u***@example.com] has not registered yet.",
+			query:          "GetUser",
+		},
+		{
+			name:       "Authenticated",
+			method:     http.MethodGet,
+			url:        "/admin/users",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				addAdminAuthMock(store, user)
+			},
+			handlerMessage: fmt.Sprintf("user [%d] with role [user] was denied access to /admin/users", user.ID),
+			query:          "GetUserByID",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(mockStore)
+			store := db.NewInstrumentedStore(mockStore, db.LogQueries(time.Second))
+			manager := auth.NewMockManager(mockStore)
+			manager.UserID = tc.authUserID
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			server := NewServer(newTestConfig(), store, manager, zap.New(core), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			request.Header.Set(requestIDHeaderKey, "request-id-1")
+			if tc.authUserID != 0 {
+				addCompleteAuth(t, request, manager)
+			}
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			fields := []zap.Field{zap.String(requestIDKey, "request-id-1")}
+			if tc.authUserID != 0 {
+				fields = append(fields, zap.Int64("user_id", tc.authUserID))
+			}
+			checkLogged := func(message string) {
+				entries := logs.FilterMessage(message).All()
+				require.Lenf(t, entries, 1, "log [%s] was not found", message)
+				for _, field := range fields {
+					require.Containsf(t, entries[0].Context, field, "log [%s] does not have [%s]", message, field.Key)
+				}
+			}
+			checkLogged(tc.handlerMessage)
+			// アクセスログ。
+			checkLogged(tc.url)
+
+			queries := logs.FilterMessage("query").FilterField(zap.String("query", tc.query)).All()
+			require.Len(t, queries, 1)
+			for _, field := range fields {
+				require.Contains(t, queries[0].Context, field)
+			}
+		})
+	}
+}
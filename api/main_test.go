@@ -3,8 +3,12 @@ package api
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/metrics"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestMain(m *testing.M) {
@@ -12,3 +16,25 @@ func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
 }
+
+// テスト用の設定を取得する。
+// 本番と同様に Secure かつ HttpOnly なCookieを発行する設定とする。
+func newTestConfig() util.Config {
+	return util.Config{
+		SessionDuration:   30 * time.Minute,
+		TOTPEncryptionKey: testTOTPEncryptionKey,
+		CookieName:        "session",
+		CookieSecure:      true,
+
+		TrashRetentionPeriod: 30 * 24 * time.Hour,
+		IdempotencyKeyTTL:    24 * time.Hour,
+		DuplicateWindow:      10 * time.Minute,
+		HealthCheckTimeout:   time.Second,
+	}
+}
+
+// テスト用のメトリクスを作成する。
+// テストごとにメモリ上のレジストリを使い、他のテストの記録と混ざらないようにする。
+func newTestMetrics() *metrics.Metrics {
+	return metrics.New(prometheus.NewRegistry())
+}
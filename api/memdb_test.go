@@ -0,0 +1,434 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/auth"
+	memdb "github.com/kokoichi206/account-book-api/db/memory"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// メモリ上のDBを使うサーバーに、ログインしたユーザーとしてリクエストを送るクライアント。
+type memoryClient struct {
+	t              *testing.T
+	server         *Server
+	cookies        []*http.Cookie
+	csrfToken      string
+	idempotencyKey string
+	ifMatch        string
+}
+
+func newMemoryClient(t *testing.T, server *Server) *memoryClient {
+	return &memoryClient{t: t, server: server}
+}
+
+func (client *memoryClient) do(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(client.t, json.NewEncoder(&buf).Encode(body))
+	}
+	request := httptest.NewRequest(method, path, &buf)
+	for _, cookie := range client.cookies {
+		request.AddCookie(cookie)
+	}
+	if client.csrfToken != "" {
+		request.Header.Set(csrfHeaderKey, client.csrfToken)
+	}
+	if client.idempotencyKey != "" {
+		request.Header.Set(idempotencyKeyHeaderKey, client.idempotencyKey)
+	}
+	if client.ifMatch != "" {
+		request.Header.Set(ifMatchHeaderKey, client.ifMatch)
+	}
+	recorder := httptest.NewRecorder()
+	client.server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// ユーザーを登録してログインし、登録したユーザーを返す。
+func (client *memoryClient) signupAndLogin(name string) userResponse {
+	email := util.RandomEmail()
+	password := util.RandomPassword()
+	recorder := client.do(http.MethodPost, "/users", gin.H{"username": name, "password": password, "email": email, "age": 20, "balance": 1000})
+	require.Equal(client.t, http.StatusCreated, recorder.Code)
+	var user userResponse
+	require.NoError(client.t, json.Unmarshal(recorder.Body.Bytes(), &user))
+
+	client.login(email, password)
+	return user
+}
+
+// ログインし、セッションとCSRFトークンのCookieを保持する。
+func (client *memoryClient) login(email, password string) {
+	recorder := client.do(http.MethodPost, "/login", gin.H{"email": email, "password": password})
+	require.Equal(client.t, http.StatusOK, recorder.Code)
+	client.cookies = recorder.Result().Cookies()
+	client.csrfToken = ""
+	for _, cookie := range client.cookies {
+		if cookie.Name == csrfCookieName {
+			client.csrfToken = cookie.Value
+		}
+	}
+	require.NotEmpty(client.t, client.csrfToken)
+}
+
+// ログイン前にCookieへ仕込まれたCSRFトークンが、ログイン後に使えないこと。
+func TestLoginRotatesCSRFTokenWithMemoryStore(t *testing.T) {
+	// Arrange
+	store := memdb.New()
+	server := NewServer(newTestConfig(), store, auth.NewManager(store), util.InitLogger(), newTestMetrics())
+	category, err := store.CreateCategory(context.Background(), "food")
+	require.NoError(t, err)
+
+	client := newMemoryClient(t, server)
+	email := util.RandomEmail()
+	password := util.RandomPassword()
+	recorder := client.do(http.MethodPost, "/users", gin.H{"username": "fixation", "password": password, "email": email, "age": 20, "balance": 1000})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	fixed := util.RandomString(32)
+	client.cookies = []*http.Cookie{{Name: csrfCookieName, Value: fixed}}
+
+	// Act
+	client.login(email, password)
+
+	// Assert
+	require.NotEqual(t, fixed, client.csrfToken)
+	expense := gin.H{"category_id": category.ID, "amount": 1200}
+	client.csrfToken = fixed
+	recorder = client.do(http.MethodPost, "/expenses", expense)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	checkBodyContains(t, recorder, "csrf_token_mismatch")
+}
+
+// gomock の代わりにメモリ上のDBを使い、複数のエンドポイントをまたぐ流れを確かめる。
+func TestExpenseFlowWithMemoryStore(t *testing.T) {
+	// Arrange
+	store := memdb.New()
+	server := NewServer(newTestConfig(), store, auth.NewManager(store), util.InitLogger(), newTestMetrics())
+	category, err := store.CreateCategory(context.Background(), "food")
+	require.NoError(t, err)
+
+	client := newMemoryClient(t, server)
+	email := util.RandomEmail()
+	password := util.RandomPassword()
+
+	// Act & Assert
+	signup := gin.H{"username": "memory", "password": password, "email": email, "age": 20, "balance": 1000}
+	recorder := client.do(http.MethodPost, "/users", signup)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var user userResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &user))
+
+	// 同じメールアドレスでは登録できない。
+	recorder = client.do(http.MethodPost, "/users", signup)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	client.login(email, password)
+
+	recorder = client.do(http.MethodPost, "/expenses", gin.H{
+		"user_id":     user.Id,
+		"category_id": category.ID,
+		"amount":      1200,
+		"comment":     "lunch",
+	})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = client.do(http.MethodGet, fmt.Sprintf("/expenses?user_id=%d", user.Id), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var expenses getAllExpensesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &expenses))
+	require.Len(t, expenses.ListExpenseResponse, 1)
+	require.Equal(t, int64(1200), expenses.ListExpenseResponse[0].Amount)
+	require.Equal(t, "lunch", expenses.ListExpenseResponse[0].Comment)
+
+	// 同じ Idempotency-Key で再送しても、支出は1件しか作成されない。
+	client.idempotencyKey = util.RandomString(16)
+	dinner := gin.H{"user_id": user.Id, "category_id": category.ID, "amount": 800, "comment": "dinner"}
+	first := client.do(http.MethodPost, "/expenses", dinner)
+	require.Equal(t, http.StatusCreated, first.Code)
+	retried := client.do(http.MethodPost, "/expenses", dinner)
+	require.Equal(t, http.StatusCreated, retried.Code)
+	require.Equal(t, "true", retried.Header().Get(idempotentReplayedHeaderKey))
+	require.JSONEq(t, first.Body.String(), retried.Body.String())
+	require.Equal(t, first.Header().Get(etagHeaderKey), retried.Header().Get(etagHeaderKey))
+	dinner["amount"] = 900
+	recorder = client.do(http.MethodPost, "/expenses", dinner)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	client.idempotencyKey = ""
+
+	recorder = client.do(http.MethodGet, fmt.Sprintf("/expenses?user_id=%d", user.Id), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &expenses))
+	require.Len(t, expenses.ListExpenseResponse, 2)
+
+	// 一般ユーザーは管理者用のエンドポイントを使えない。
+	recorder = client.do(http.MethodPost, "/admin/categories", gin.H{"name": "other"})
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+// 他のユーザーの user_id を指定しても、そのユーザーの支出を参照・作成できないこと。
+func TestCreateExpenseForOtherUserWithMemoryStore(t *testing.T) {
+	// Arrange
+	store := memdb.New()
+	server := NewServer(newTestConfig(), store, auth.NewManager(store), util.InitLogger(), newTestMetrics())
+	category, err := store.CreateCategory(context.Background(), "food")
+	require.NoError(t, err)
+
+	victim := newMemoryClient(t, server)
+	victimUser := victim.signupAndLogin("victim")
+	expense := gin.H{"user_id": victimUser.Id, "category_id": category.ID, "amount": 1200, "comment": "private note"}
+	recorder := victim.do(http.MethodPost, "/expenses", expense)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	attacker := newMemoryClient(t, server)
+	attacker.signupAndLogin("attacker")
+
+	for _, path := range []string{"/expenses", "/expenses?force=true"} {
+		// Act
+		recorder = attacker.do(http.MethodPost, path, expense)
+
+		// Assert
+		require.Equal(t, http.StatusForbidden, recorder.Code, path)
+		require.NotContains(t, recorder.Body.String(), "private note")
+		checkBodyContains(t, recorder, codePermissionDenied)
+	}
+	recorder = attacker.do(http.MethodGet, fmt.Sprintf("/expenses?user_id=%d", victimUser.Id), nil)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "private note")
+
+	recorder = victim.do(http.MethodGet, fmt.Sprintf("/expenses?user_id=%d", victimUser.Id), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var expenses getAllExpensesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &expenses))
+	require.Len(t, expenses.ListExpenseResponse, 1)
+}
+
+// 支出から参照していないレシートも、登録したユーザーが削除・復元できること。
+func TestReceiptTrashFlowWithMemoryStore(t *testing.T) {
+	// Arrange
+	store := memdb.New()
+	server := NewServer(newTestConfig(), store, auth.NewManager(store), util.InitLogger(), newTestMetrics())
+
+	client := newMemoryClient(t, server)
+	user := client.signupAndLogin("owner")
+	other := newMemoryClient(t, server)
+	other.signupAndLogin("other")
+
+	// 支出からは参照しない。
+	recorder := client.do(http.MethodPost, "/receipts", gin.H{
+		"store_name":    "supermarket",
+		"food_contents": []gin.H{{"name": "apple", "price": 100}},
+		"total_price":   100,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	ids, err := store.ListUserFoodReceiptIDs(context.Background(), user.Id)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	receipt, err := store.GetFoodReceipt(context.Background(), ids[0])
+	require.NoError(t, err)
+	path := fmt.Sprintf("/receipts/%d", receipt.ID)
+
+	// Act & Assert
+	// 他のユーザーは削除できない。
+	etag := strconv.Quote(strconv.FormatInt(receipt.Version, 10))
+	other.ifMatch = etag
+	recorder = other.do(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	other.ifMatch = ""
+
+	client.ifMatch = etag
+	recorder = client.do(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	client.ifMatch = ""
+
+	recorder = client.do(http.MethodGet, "/trash", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var trash listTrashResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &trash))
+	require.Len(t, trash.Receipts, 1)
+	require.Equal(t, receipt.ID, trash.Receipts[0].ID)
+	require.Equal(t, "supermarket", trash.Receipts[0].StoreName)
+
+	recorder = other.do(http.MethodGet, "/trash", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var otherTrash listTrashResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &otherTrash))
+	require.Empty(t, otherTrash.Receipts)
+
+	restorePath := fmt.Sprintf("/trash/%s/%d/restore", trashTypeReceipts, receipt.ID)
+	recorder = other.do(http.MethodPost, restorePath, nil)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = client.do(http.MethodPost, restorePath, nil)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = client.do(http.MethodGet, "/trash", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &trash))
+	require.Empty(t, trash.Receipts)
+	restored, err := store.GetFoodReceipt(context.Background(), receipt.ID)
+	require.NoError(t, err)
+	require.False(t, restored.DeletedAt.Valid)
+}
+
+// POST /receipts が明細の食品を商品名で解決し、二重登録と再送を判定すること。
+func TestCreateReceiptFlowWithMemoryStore(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memdb.New()
+	server := NewServer(newTestConfig(), store, auth.NewManager(store), util.InitLogger(), newTestMetrics())
+	apple, err := store.CreateFoodContent(ctx, db.CreateFoodContentParams{Name: "apple", Calories: 52})
+	require.NoError(t, err)
+
+	client := newMemoryClient(t, server)
+	user := client.signupAndLogin("owner")
+	receipt := gin.H{
+		"store_name":    "supermarket",
+		"food_contents": []gin.H{{"name": "apple", "price": 100}, {"name": "dragon fruit", "price": 400}},
+		"total_price":   500,
+	}
+	receiptIDs := func() []int64 {
+		ids, err := store.ListUserFoodReceiptIDs(ctx, user.Id)
+		require.NoError(t, err)
+		return ids
+	}
+
+	// Act & Assert
+	recorder := client.do(http.MethodPost, "/receipts", receipt)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	ids := receiptIDs()
+	require.Len(t, ids, 1)
+	// 登録済みの食品はそのまま使い、未登録の食品は作成する。
+	contents, err := store.ListFoodReceiptContents(ctx, ids[0])
+	require.NoError(t, err)
+	require.Len(t, contents, 2)
+	names := map[string]db.ListFoodReceiptContentsRow{}
+	for _, content := range contents {
+		names[content.Name] = content
+	}
+	require.Equal(t, apple.ID, names["apple"].FoodContentID)
+	require.Equal(t, float32(52), names["apple"].Calories)
+	require.Contains(t, names, "dragon fruit")
+
+	// 同じ内容のレシートは、force=true を指定しない限り登録しない。
+	recorder = client.do(http.MethodPost, "/receipts", receipt)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	var duplicate struct {
+		Error      errorBody                  `json:"error"`
+		Duplicates []duplicateReceiptResponse `json:"duplicates"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &duplicate))
+	require.Equal(t, codePossibleDuplicate, duplicate.Error.Code)
+	require.Len(t, duplicate.Duplicates, 1)
+	require.Equal(t, ids[0], duplicate.Duplicates[0].ID)
+	require.Len(t, receiptIDs(), 1)
+
+	recorder = client.do(http.MethodPost, "/receipts?force=true", receipt)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, receiptIDs(), 2)
+	// 同じ名前の食品は作成し直さない。
+	food, err := store.GetFoodContentByName(ctx, "dragon fruit")
+	require.NoError(t, err)
+	foods, err := store.ListFoodContents(ctx, db.ListFoodContentsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, foods, 2)
+	require.Contains(t, foods, food)
+
+	// 同じ Idempotency-Key の再送は、最初のレスポンスを返して登録し直さない。
+	client.idempotencyKey = util.RandomString(16)
+	other := gin.H{
+		"store_name":    "bakery",
+		"food_contents": []gin.H{{"name": "bread", "price": 300}},
+		"total_price":   300,
+	}
+	first := client.do(http.MethodPost, "/receipts", other)
+	require.Equal(t, http.StatusOK, first.Code)
+	retry := client.do(http.MethodPost, "/receipts", other)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotentReplayedHeaderKey))
+	require.Len(t, receiptIDs(), 3)
+
+	// 重複の可能性による409は保存しないため、同じ Idempotency-Key のまま force=true で再送できる。
+	client.idempotencyKey = util.RandomString(16)
+	recorder = client.do(http.MethodPost, "/receipts", other)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	require.Empty(t, recorder.Header().Get(idempotentReplayedHeaderKey))
+	forced := client.do(http.MethodPost, "/receipts?force=true", other)
+	require.Equal(t, http.StatusOK, forced.Code)
+	require.Empty(t, forced.Header().Get(idempotentReplayedHeaderKey))
+	require.Len(t, receiptIDs(), 4)
+	retry = client.do(http.MethodPost, "/receipts?force=true", other)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotentReplayedHeaderKey))
+	require.Equal(t, forced.Body.String(), retry.Body.String())
+	require.Len(t, receiptIDs(), 4)
+}
+
+// 支出の更新は If-Match を必須とし、古い ETag による更新で他の変更を上書きしないこと。
+func TestUpdateExpenseFlowWithMemoryStore(t *testing.T) {
+	// Arrange
+	store := memdb.New()
+	server := NewServer(newTestConfig(), store, auth.NewManager(store), util.InitLogger(), newTestMetrics())
+	food, err := store.CreateCategory(context.Background(), "food")
+	require.NoError(t, err)
+	daily, err := store.CreateCategory(context.Background(), "daily")
+	require.NoError(t, err)
+
+	client := newMemoryClient(t, server)
+	user := client.signupAndLogin("owner")
+	other := newMemoryClient(t, server)
+	other.signupAndLogin("other")
+
+	recorder := client.do(http.MethodPost, "/expenses", gin.H{"category_id": food.ID, "amount": 1200, "comment": "lunch"})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var created createExpenseResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	etag := recorder.Header().Get(etagHeaderKey)
+	path := fmt.Sprintf("/expenses/%d", created.ID)
+
+	// Act & Assert
+	recorder = client.do(http.MethodPatch, path, gin.H{"amount": 1500})
+	require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+
+	// 他のユーザーは更新できない。
+	other.ifMatch = etag
+	recorder = other.do(http.MethodPatch, path, gin.H{"amount": 1})
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	client.ifMatch = etag
+	recorder = client.do(http.MethodPatch, path, gin.H{"category_id": daily.ID, "amount": 1500, "comment": ""})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var updated createExpenseResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &updated))
+	require.Equal(t, daily.ID, updated.CategoryID)
+	require.Equal(t, int64(1500), updated.Amount)
+	require.Empty(t, updated.Comment)
+	require.Equal(t, created.Version+1, updated.Version)
+	require.Equal(t, strconv.Quote(strconv.FormatInt(updated.Version, 10)), recorder.Header().Get(etagHeaderKey))
+
+	// 古い ETag による更新は、間の変更を上書きしない。
+	recorder = client.do(http.MethodPatch, path, gin.H{"amount": 1})
+	require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	recorder = client.do(http.MethodGet, "/expenses", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var expenses getAllExpensesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &expenses))
+	require.Len(t, expenses.ListExpenseResponse, 1)
+	require.Equal(t, int64(1500), expenses.ListExpenseResponse[0].Amount)
+
+	events, err := store.ListUserAuditEvents(context.Background(), db.ListUserAuditEventsParams{ActorID: user.Id, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, db.AuditActionUpdate, events[0].Action)
+	require.Equal(t, created.ID, events[0].EntityID)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// リクエストの結果が /metrics に反映されること。
+func TestMetrics(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().
+		ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Expense{}, nil)
+	store.EXPECT().
+		CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Expense{ID: 1}, nil)
+	// authのmiddlewareを通すため。
+	store.EXPECT().
+		UpdateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+	manager := auth.NewMockManager(store)
+	manager.UserID = util.RandomID()
+
+	server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+
+	requests := []struct {
+		method string
+		url    string
+		body   gin.H
+		auth   bool
+	}{
+		{method: http.MethodPost, url: "/login", body: gin.H{"email": util.RandomEmail(), "password": util.RandomPassword()}},
+		{method: http.MethodPost, url: "/expenses", body: gin.H{"user_id": manager.UserID, "category_id": 1, "amount": 300}, auth: true},
+		{method: http.MethodGet, url: "/not-found/1"},
+		{method: http.MethodGet, url: "/not-found/2"},
+	}
+	for _, r := range requests {
+		data, err := json.Marshal(r.body)
+		require.NoError(t, err)
+		request, err := http.NewRequest(r.method, r.url, bytes.NewReader(data))
+		require.NoError(t, err)
+		if r.auth {
+			addCompleteAuth(t, request, manager)
+		}
+		server.router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+
+	// Act
+	server.router.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	for _, line := range []string{
+		`account_book_http_requests_total{method="POST",route="/login",status="400"} 1`,
+		`account_book_http_requests_total{method="POST",route="/expenses",status="201"} 1`,
+		// 存在しないパスは、パスごとではなく1つの系列にまとめること。
+		`account_book_http_requests_total{method="GET",route="unmatched",status="404"} 2`,
+		`account_book_http_request_duration_seconds_count{method="POST",route="/login",status="400"} 1`,
+		`account_book_login_failures_total{reason="unknown_user"} 1`,
+		`account_book_expenses_created_total 1`,
+	} {
+		require.Contains(t, body, line+"\n")
+	}
+}
@@ -2,33 +2,119 @@ package api
 
 import (
 	"context"
-	"errors"
+	"database/sql"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/kokoichi206/account-book-api/auth"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
 	"go.uber.org/zap"
 )
 
 const (
-	cookieName = "session"
+	authorizationHeaderKey  = "Authorization"
+	authorizationTypeBearer = "bearer"
+	// 認証済みのユーザーIDを gin.Context に保持する際のキー。
+	authUserIDKey = "auth_user_id"
+	// セッションで認証された場合に、セッションIDを gin.Context に保持する際のキー。
+	authSessionIDKey = "auth_session_id"
+	// 認証方式を gin.Context に保持する際のキー。
+	authMethodKey = "auth_method"
+	// APIキーに付与された権限を gin.Context に保持する際のキー。
+	authScopesKey = "auth_scopes"
+	// 認証済みのユーザーのロールを gin.Context に保持する際のキー。
+	authRoleKey = "auth_role"
+	// リクエストIDをやり取りするヘッダー名。
+	requestIDHeaderKey = "X-Request-ID"
+	// リクエストIDを gin.Context に保持する際のキー。
+	requestIDKey = "request_id"
+	// クライアントから受け取るリクエストIDの最大長。
+	maxRequestIDLength = 128
 )
 
+// 認証方式。
+const (
+	authMethodSession = "session"
+	authMethodAPIKey  = "api_key"
+)
+
+// リクエストごとにIDを割り当て、レスポンスのヘッダーにも付与するmiddleware。
+// クライアントから妥当なIDが送られた場合は、それを引き継ぐ。
+func (server *Server) requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeaderKey)
+		if !isValidRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeaderKey, id)
+		// 同じリクエストのログを突き合わせられるよう、以降のログにリクエストIDを含める。
+		setRequestLogger(c, server.logger.With(zap.String(requestIDKey, id)))
+		c.Next()
+	}
+}
+
+// ログやヘッダーにそのまま出力しても問題ない値か判定する。
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDMiddleware で割り当てたリクエストIDを取得する。
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// リクエストごとのLoggerを返す。
+// リクエストIDと、認証済みの場合はユーザーIDを含む。
+func requestLogger(c *gin.Context) *zap.SugaredLogger {
+	return util.Logger(c).Sugar()
+}
+
+// リクエストごとのLoggerを差し替える。
+// c を context として受け取るクエリなどからも参照できるよう、リクエストの context に保持する。
+func setRequestLogger(c *gin.Context, logger *zap.Logger) {
+	c.Request = c.Request.WithContext(util.WithLogger(c.Request.Context(), logger))
+}
+
+// 認証されたユーザーを記録し、以降のログにユーザーIDを含める。
+func setAuthUser(c *gin.Context, userID int64) {
+	c.Set(authUserIDKey, userID)
+	setRequestLogger(c, util.Logger(c).With(zap.Int64("user_id", userID)))
+}
+
+// Cookieのセッション、もしくはAuthorizationヘッダーのAPIキーで認証するmiddleware。
 func (server *Server) authMiddleware(m auth.SessionManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		sessionString, err := c.Cookie(cookieName)
+		// Authorizationヘッダーがある場合は、APIキーで認証する。
+		if c.GetHeader(authorizationHeaderKey) != "" {
+			server.authenticateAPIKey(c)
+			return
+		}
+
+		sessionString, err := server.sessionCookie(c)
 		// Cookieから値が取得できない場合。
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("cannot find cookie")))
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.cannot_find_cookie")
 			return
 		}
 		session, err := uuid.Parse(sessionString)
 		// 取得したCookieが、uuidの形式になってない場合。
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("wrong cookie value found")))
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.wrong_cookie_value")
 			return
 		}
 
@@ -38,10 +124,10 @@ func (server *Server) authMiddleware(m auth.SessionManager) gin.HandlerFunc {
 			ClientIp:  c.ClientIP(),
 		}
 
-		verify, err := m.VerifySession(arg)
+		verified, verify, err := m.VerifySession(arg)
 		// セッションが有効ではない場合。
 		if err != nil || !verify {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("session was not verified")))
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.session_not_verified")
 			return
 		}
 
@@ -52,19 +138,128 @@ func (server *Server) authMiddleware(m auth.SessionManager) gin.HandlerFunc {
 			ExpiresAt: time.Now().Add(duration),
 			ID:        session,
 		}
-		err = server.querier.UpdateSession(context.Background(), updateArg)
+		err = server.store.UpdateSession(context.Background(), updateArg)
 		if err != nil {
-			zap.S().Error(err)
-
-			c.Error(err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			abortWithInternalError(c, err)
 			return
 		}
 		// Cookieに渡しているセッションを自動更新する。
 		maxAge := int(duration.Seconds())
-		domain := server.config.ServerAddress
-		c.SetCookie(cookieName, session.String(), maxAge, "/", domain, true, true)
+		server.setSessionCookie(c, session.String(), maxAge)
+		// CSRFトークンもセッションと同じだけ有効期限を延ばす。
+		if err := server.extendCSRFCookie(c, maxAge); err != nil {
+			abortWithInternalError(c, err)
+			return
+		}
 
+		setAuthUser(c, verified.UserID)
+		// 以降のエラーメッセージは、プロフィールに設定された言語で返す。
+		setUserLocale(c, verified.Locale)
+		c.Set(authMethodKey, authMethodSession)
+		c.Set(authSessionIDKey, session)
 		c.Next()
 	}
 }
+
+// AuthorizationヘッダーのAPIキーで認証する。
+func (server *Server) authenticateAPIKey(c *gin.Context) {
+	fields := strings.Fields(c.GetHeader(authorizationHeaderKey))
+	if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+		abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.invalid_authorization_header")
+		return
+	}
+
+	// DBにはハッシュ化した値のみ保存している。
+	key, err := server.store.GetAPIKeyByHash(c, util.HashToken(fields[1]))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.api_key_not_verified")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetAPIKeyByHash: %w", err))
+		return
+	}
+	// 失効済み、もしくは有効期限切れのAPIキーは受け付けない。
+	if key.RevokedAt.Valid || (key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(time.Now())) {
+		abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.api_key_not_verified")
+		return
+	}
+
+	// 最終利用日時は参考情報のため、更新に失敗しても処理は続ける。
+	if err := server.store.UpdateAPIKeyLastUsed(c, key.ID); err != nil {
+		requestLogger(c).Warn(fmt.Errorf("failed to UpdateAPIKeyLastUsed: %w", err))
+	}
+
+	setAuthUser(c, key.UserID)
+	setUserLocale(c, key.Locale)
+	c.Set(authMethodKey, authMethodAPIKey)
+	c.Set(authScopesKey, key.Scopes)
+	c.Next()
+}
+
+// APIキーで認証された場合に、指定の権限を持つか確かめるmiddleware。
+// セッションで認証された場合は全ての権限を持つものとする。
+func (server *Server) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(authMethodKey) == authMethodAPIKey &&
+			!auth.HasScope(c.GetStringSlice(authScopesKey), scope) {
+			abortWithError(c, http.StatusForbidden, codeInsufficientScope, "error.insufficient_scope", "scope", scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// セッションで認証された場合のみ通すmiddleware。
+// APIキーの発行や2段階認証の設定など、APIキーに許可しない操作で使う。
+func (server *Server) requireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(authMethodKey) != authMethodSession {
+			abortWithError(c, http.StatusForbidden, codeSessionRequired, "error.session_required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// 認証されたユーザーが指定のロールのいずれかを持つ場合のみ通すmiddleware。
+// ロールはセッションに保持せず、権限の剥奪を即座に反映するため毎回DBから取得する。
+func (server *Server) requireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := server.store.GetUserByID(c, authUserID(c))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+				return
+			}
+			abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+			return
+		}
+		if user.DisabledAt.Valid || !auth.HasRole(user.Role, roles...) {
+			requestLogger(c).Warnf("user [%d] with role [%s] was denied access to %s", user.ID, user.Role, c.FullPath())
+			abortWithError(c, http.StatusForbidden, codePermissionDenied, "error.permission_denied")
+			return
+		}
+
+		c.Set(authRoleKey, user.Role)
+		c.Next()
+	}
+}
+
+// authMiddleware で認証されたユーザーのIDを取得する。
+func authUserID(c *gin.Context) int64 {
+	return c.GetInt64(authUserIDKey)
+}
+
+// 監査ログに記録する、認証されたユーザーとリクエストの情報を取得する。
+// APIキーで認証された場合は、セッションIDを記録しない。
+func auditActor(c *gin.Context) db.AuditActor {
+	actor := db.AuditActor{
+		UserID:   authUserID(c),
+		ClientIP: c.ClientIP(),
+	}
+	if session, ok := c.Get(authSessionIDKey); ok {
+		actor.SessionID = uuid.NullUUID{UUID: session.(uuid.UUID), Valid: true}
+	}
+	return actor
+}
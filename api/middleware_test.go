@@ -15,17 +15,24 @@ import (
 	"github.com/google/uuid"
 	"github.com/kokoichi206/account-book-api/auth"
 	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
 	"github.com/kokoichi206/account-book-api/util"
 	"github.com/stretchr/testify/require"
 )
 
+// テスト用のCSRFトークン。
+const testCSRFToken = "test-csrf-token"
+
 func addAuthorization(
 	t *testing.T,
 	request *http.Request,
 	session string,
 ) {
-	cookie := fmt.Sprintf("session=%s", session)
+	// ブラウザと同様に、CSRFトークンをCookieとヘッダーの両方に付与する。
+	cookie := fmt.Sprintf("session=%s; %s=%s", session, csrfCookieName, testCSRFToken)
 	request.Header.Set("Cookie", cookie)
+	request.Header.Set(csrfHeaderKey, testCSRFToken)
 }
 
 func addCompleteAuth(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager) {
@@ -40,7 +47,7 @@ func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, manager auth.SessionManager)
-		buildStubs    func(t *testing.T, querier *mockdb.MockQuerier, manager auth.SessionManager)
+		buildStubs    func(t *testing.T, store *mockdb.MockStore, manager auth.SessionManager)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
 	}{
 		{
@@ -49,8 +56,8 @@ func TestAuthMiddleware(t *testing.T) {
 				session := manager.(*auth.MockUuidSessionManager).Uuid
 				addAuthorization(t, request, session.String())
 			},
-			buildStubs: func(t *testing.T, querier *mockdb.MockQuerier, manager auth.SessionManager) {
-				querier.EXPECT().
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, manager auth.SessionManager) {
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -74,7 +81,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, manager auth.SessionManager) {
 				// Not setup cookie
 			},
-			buildStubs: func(t *testing.T, querier *mockdb.MockQuerier, manager auth.SessionManager) {
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, manager auth.SessionManager) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -87,7 +94,7 @@ func TestAuthMiddleware(t *testing.T) {
 				// Cookie value is not uuid type.
 				addAuthorization(t, request, "wrong session type")
 			},
-			buildStubs: func(t *testing.T, querier *mockdb.MockQuerier, manager auth.SessionManager) {
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, manager auth.SessionManager) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -100,7 +107,7 @@ func TestAuthMiddleware(t *testing.T) {
 				session := manager.(*auth.MockUuidSessionManager).Uuid
 				addAuthorization(t, request, session.String())
 			},
-			buildStubs: func(t *testing.T, querier *mockdb.MockQuerier, manager auth.SessionManager) {
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, manager auth.SessionManager) {
 				mockManager := manager.(*auth.MockUuidSessionManager)
 				mockManager.Uuid = uuid.New()
 				mockManager.Verify = false
@@ -117,8 +124,8 @@ func TestAuthMiddleware(t *testing.T) {
 				session := manager.(*auth.MockUuidSessionManager).Uuid
 				addAuthorization(t, request, session.String())
 			},
-			buildStubs: func(t *testing.T, querier *mockdb.MockQuerier, manager auth.SessionManager) {
-				querier.EXPECT().
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, manager auth.SessionManager) {
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(sql.ErrConnDone)
@@ -131,7 +138,8 @@ func TestAuthMiddleware(t *testing.T) {
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 
-				checkError(t, sql.ErrConnDone.Error(), recorder.Body)
+				body := checkError(t, codeInternal, recorder.Body)
+				require.NotContains(t, body.Error.Message, sql.ErrConnDone.Error())
 			},
 		},
 	}
@@ -144,14 +152,13 @@ func TestAuthMiddleware(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			config := util.Config{
-				SessionDuration: 10 * time.Minute,
-			}
-			querier := mockdb.NewMockQuerier(ctrl)
-			manager := auth.NewMockManager(querier)
-			tc.buildStubs(t, querier, manager)
+			config := newTestConfig()
+			config.SessionDuration = 10 * time.Minute
+			store := mockdb.NewMockStore(ctrl)
+			manager := auth.NewMockManager(store)
+			tc.buildStubs(t, store, manager)
 
-			server := NewServer(config, querier, manager, util.InitLogger())
+			server := NewServer(config, store, manager, util.InitLogger(), newTestMetrics())
 			// テスト用のパスを用意する。
 			authPath := "/auth"
 			server.router.GET(
@@ -186,3 +193,334 @@ func checkBodyContains(t *testing.T, recorder *httptest.ResponseRecorder, body s
 	t.Log(body)
 	require.True(t, strings.Contains(d, body))
 }
+
+// AuthorizationヘッダーにAPIキーをセットする。
+func addAPIKeyAuthorization(t *testing.T, request *http.Request, key string) {
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", key))
+}
+
+func TestAuthMiddlewareWithAPIKey(t *testing.T) {
+	key, prefix, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	apiKey := db.GetAPIKeyByHashRow{
+		ID:        util.RandomID(),
+		UserID:    util.RandomID(),
+		Name:      "import script",
+		KeyPrefix: prefix,
+		KeyHash:   util.HashToken(key),
+		Scopes:    []string{auth.ScopeReadExpenses},
+		CreatedAt: time.Now(),
+		Locale:    i18n.DefaultLocale,
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addAPIKeyAuthorization(t, request, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Eq(apiKey.KeyHash)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(nil)
+				// セッションは使わない。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Set-Cookie"))
+			},
+		},
+		{
+			name: "InvalidHeaderFormat",
+			setupAuth: func(t *testing.T, request *http.Request) {
+				request.Header.Set(authorizationHeaderKey, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				checkBodyContains(t, recorder, "invalid authorization header format")
+			},
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addAPIKeyAuthorization(t, request, "abk_unknown")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetAPIKeyByHashRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				checkBodyContains(t, recorder, "api key was not verified")
+			},
+		},
+		{
+			name: "Expired",
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addAPIKeyAuthorization(t, request, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := apiKey
+				expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Revoked",
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addAPIKeyAuthorization(t, request, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(revoked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "DBErrorWhenGetAPIKeyByHash",
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addAPIKeyAuthorization(t, request, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetAPIKeyByHashRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			manager := auth.NewMockManager(store)
+			tc.buildStubs(store)
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			authPath := "/auth"
+			server.router.GET(
+				authPath,
+				server.authMiddleware(server.sessionManager),
+				func(ctx *gin.Context) {
+					require.Equal(t, apiKey.UserID, authUserID(ctx))
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+			tc.setupAuth(t, request)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		scopes       []string
+		expectedCode int
+	}{
+		{
+			name:         "SessionHasAllScopes",
+			method:       authMethodSession,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "APIKeyWithScope",
+			method:       authMethodAPIKey,
+			scopes:       []string{auth.ScopeReadReceipts, auth.ScopeWriteExpenses},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "APIKeyWithoutScope",
+			method:       authMethodAPIKey,
+			scopes:       []string{auth.ScopeReadExpenses},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			server := &Server{}
+			router := gin.New()
+			router.GET(
+				"/scope",
+				func(ctx *gin.Context) {
+					ctx.Set(authMethodKey, tc.method)
+					ctx.Set(authScopesKey, tc.scopes)
+				},
+				server.requireScope(auth.ScopeWriteExpenses),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/scope", nil)
+			require.NoError(t, err)
+
+			// Act
+			router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	userID := util.RandomID()
+
+	testCases := []struct {
+		name         string
+		buildStubs   func(store *mockdb.MockStore)
+		expectedCode int
+	}{
+		{
+			name: "Admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(db.User{ID: userID, Role: auth.RoleAdmin}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "User",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(db.User{ID: userID, Role: auth.RoleUser}, nil)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name: "DisabledAdmin",
+			buildStubs: func(store *mockdb.MockStore) {
+				user := db.User{
+					ID:         userID,
+					Role:       auth.RoleAdmin,
+					DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(user, nil)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name: "UserNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "DBError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := &Server{store: store}
+			router := gin.New()
+			router.GET(
+				"/role",
+				func(ctx *gin.Context) {
+					ctx.Set(authUserIDKey, userID)
+				},
+				server.requireRole(auth.RoleAdmin),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/role", nil)
+			require.NoError(t, err)
+
+			// Act
+			router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIの仕様を記述した OpenAPI 3 のドキュメント。
+// ルーティングやRequest・Responseの構造体を変更した場合は、合わせて更新する。
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// Swagger UI を表示するHTML。
+// JavaScriptとCSSはCDNから読み込み、/openapi.json を表示する。
+const swaggerUIHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>account-book-api</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@4/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@4/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// OpenAPI のドキュメントを返すエンドポイント。
+func (server *Server) getOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPISpec)
+}
+
+// Swagger UI を表示するエンドポイント。
+func (server *Server) getSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIHTML))
+}
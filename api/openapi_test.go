@@ -0,0 +1,744 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func loadOpenAPISpec(t *testing.T) map[string]interface{} {
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(openAPISpec, &spec))
+	require.Equal(t, "3.0.3", spec["openapi"])
+	return spec
+}
+
+// OpenAPI のパス（/users/{id}）を gin のパス（/users/:id）に変換する。
+func ginPath(openAPIPath string) string {
+	parts := strings.Split(openAPIPath, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			parts[i] = ":" + strings.Trim(part, "{}")
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// setupRouter の全てのルートがドキュメントに記載され、記載されたルートが全て存在すること。
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	// Arrange
+	spec := loadOpenAPISpec(t)
+	config := newTestConfig()
+	config.SwaggerUIEnabled = true
+	server := NewServer(config, nil, nil, util.InitLogger(), newTestMetrics())
+
+	documented := []string{}
+	for path, item := range spec["paths"].(map[string]interface{}) {
+		for method := range item.(map[string]interface{}) {
+			documented = append(documented, strings.ToUpper(method)+" "+ginPath(path))
+		}
+	}
+
+	// Act
+	routes := []string{}
+	for _, route := range server.router.Routes() {
+		routes = append(routes, route.Method+" "+route.Path)
+	}
+
+	// Assert
+	sort.Strings(documented)
+	sort.Strings(routes)
+	require.Equal(t, routes, documented)
+}
+
+func TestOpenAPIRefsAreResolvable(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				require.NotNilf(t, lookupRef(spec, ref), "unresolvable $ref [%s]", ref)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(spec)
+}
+
+func TestSwaggerUI(t *testing.T) {
+	testCases := []struct {
+		name       string
+		enabled    bool
+		wantStatus int
+	}{
+		{name: "Enabled", enabled: true, wantStatus: http.StatusOK},
+		{name: "Disabled", enabled: false, wantStatus: http.StatusNotFound},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			config := newTestConfig()
+			config.SwaggerUIEnabled = tc.enabled
+			server := NewServer(config, nil, nil, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/docs", nil)
+			require.NoError(t, err)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, tc.wantStatus, recorder.Code)
+		})
+	}
+}
+
+// mockのStoreに対して実際にリクエストを送り、リクエストとResponseがドキュメントと一致すること。
+// ドキュメントにないプロパティを返した場合も失敗とし、実装とドキュメントの乖離を防ぐ。
+func TestOpenAPIContract(t *testing.T) {
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := randomProfileUser()
+	user.Password = hashedPassword
+	// DBでは locale に既定値があるため、空文字にはならない。
+	admin := randomUser(auth.RoleAdmin)
+	admin.Locale = i18n.English
+	disabled := randomUser(auth.RoleUser)
+	disabled.Locale = i18n.Japanese
+	disabled.DisabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	apiKey := randomAPIKey(user.ID)
+	apiKey.ExpiresAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+
+	testCases := []struct {
+		name string
+		// ドキュメントに記載しているパス。
+		path   string
+		method string
+		url    string
+		body   gin.H
+		header http.Header
+		// 認証するユーザーのID。0の場合は認証しない。
+		authUserID int64
+		buildStubs func(store *mockdb.MockStore)
+		wantStatus int
+	}{
+		{
+			name:   "CreateUser",
+			path:   "/users",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   gin.H{"username": user.Name, "password": password, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(db.Session{ID: uuid.New(), UserID: user.ID}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "CreateUserValidationError",
+			path:       "/users",
+			method:     http.MethodPost,
+			url:        "/users",
+			body:       gin.H{"username": user.Name, "password": "short", "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "LoginUser",
+			path:   "/login",
+			method: http.MethodPost,
+			url:    "/login",
+			body:   gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Email)).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(db.Session{ID: uuid.New(), UserID: user.ID}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "GetAllExpensesUnauthorized",
+			path:       "/expenses",
+			method:     http.MethodGet,
+			url:        "/expenses?user_id=1",
+			buildStubs: func(store *mockdb.MockStore) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "GetAllExpenses",
+			path:       "/expenses",
+			method:     http.MethodGet,
+			url:        fmt.Sprintf("/expenses?user_id=%d", user.ID),
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				storeName := interface{}("store")
+				store.EXPECT().ListExpenses(gomock.Any(), gomock.Eq(user.ID)).Return([]db.ListExpensesRow{
+					{ID: 1, UserID: user.ID, CategoryID: 2, Amount: 300, StoreName: storeName, CreatedAt: time.Now()},
+					{ID: 2, UserID: user.ID, CategoryID: 2, Amount: 500, CreatedAt: time.Now()},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "CreateExpense",
+			path:       "/expenses",
+			method:     http.MethodPost,
+			url:        "/expenses",
+			body:       gin.H{"user_id": user.ID, "category_id": 2, "amount": 300, "comment": "lunch"},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).Return([]db.Expense{}, nil)
+				store.EXPECT().CreateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(db.Expense{
+					ID:         1,
+					UserID:     user.ID,
+					CategoryID: 2,
+					Amount:     300,
+					Comment:    sql.NullString{String: "lunch", Valid: true},
+					CreatedAt:  time.Now(),
+				}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "CreateExpensePossibleDuplicate",
+			path:       "/expenses",
+			method:     http.MethodPost,
+			url:        "/expenses",
+			body:       gin.H{"user_id": user.ID, "category_id": 2, "amount": 300},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListDuplicateExpenseCandidates(gomock.Any(), gomock.Any()).Return([]db.Expense{
+					{ID: 1, UserID: user.ID, CategoryID: 2, Amount: 300, CreatedAt: time.Now(), Version: 1},
+				}, nil)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "ListDuplicateExpenses",
+			path:       "/expenses/duplicates",
+			method:     http.MethodGet,
+			url:        "/expenses/duplicates",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				now := time.Now()
+				store.EXPECT().ListDuplicateExpenses(gomock.Any(), gomock.Any()).Return([]db.Expense{
+					{ID: 1, UserID: user.ID, CategoryID: 2, Amount: 300, CreatedAt: now.Add(-time.Minute), Version: 1},
+					{ID: 2, UserID: user.ID, CategoryID: 2, Amount: 300, CreatedAt: now, Version: 1},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "CreateReceipt",
+			path:   "/receipts",
+			method: http.MethodPost,
+			url:    "/receipts",
+			body: gin.H{
+				"store_name":    "store",
+				"food_contents": []gin.H{{"name": "apple", "price": 100}},
+				"total_price":   100,
+			},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).Return([]db.FoodReceipt{}, nil)
+				store.EXPECT().CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(db.CreateReceiptTxResult{FoodReceipt: db.FoodReceipt{ID: 1, StoreName: "store"}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "CreateReceiptPossibleDuplicate",
+			path:   "/receipts",
+			method: http.MethodPost,
+			url:    "/receipts",
+			body: gin.H{
+				"store_name":    "store",
+				"food_contents": []gin.H{{"name": "apple", "price": 100}},
+				"total_price":   100,
+			},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).Return([]db.FoodReceipt{
+					{ID: 1, StoreName: "store", TotalPrice: sql.NullInt64{Int64: 100, Valid: true}, CreatedAt: time.Now(), Version: 1},
+				}, nil)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "ListCategories",
+			path:       "/categories",
+			method:     http.MethodGet,
+			url:        "/categories",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListCategories(gomock.Any()).Return([]db.Category{randomCategory(), {ID: 1, Name: "food"}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "GetProfile",
+			path:       "/users/me",
+			method:     http.MethodGet,
+			url:        "/users/me",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Return(user, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "UpdateProfile",
+			path:       "/users/me",
+			method:     http.MethodPatch,
+			url:        "/users/me",
+			body:       gin.H{"locale": "en", "timezone": "America/New_York"},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				scheduled := user
+				scheduled.DeletionScheduledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Return(user, nil)
+				store.EXPECT().UpdateUserProfile(gomock.Any(), gomock.Any()).Return(scheduled, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "CreateAPIKey",
+			path:       "/users/me/api-keys",
+			method:     http.MethodPost,
+			url:        "/users/me/api-keys",
+			body:       gin.H{"name": apiKey.Name, "scopes": apiKey.Scopes},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(apiKey, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "ListAPIKeys",
+			path:       "/users/me/api-keys",
+			method:     http.MethodGet,
+			url:        "/users/me/api-keys",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAPIKeys(gomock.Any(), gomock.Eq(user.ID)).Return([]db.ApiKey{apiKey, randomAPIKey(user.ID)}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "RevokeAPIKeyNotFound",
+			path:       "/users/me/api-keys/{id}",
+			method:     http.MethodDelete,
+			url:        "/users/me/api-keys/1",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any()).Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "ListAuditEvents",
+			path:       "/audit",
+			method:     http.MethodGet,
+			url:        "/audit?page_id=1&page_size=5",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Return(user, nil)
+				store.EXPECT().ListUserAuditEvents(gomock.Any(), gomock.Any()).Return([]db.AuditEvent{
+					randomAuditEvent(user.ID, db.AuditActionCreate),
+					randomAuditEvent(user.ID, db.AuditActionDelete),
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "ListUsers",
+			path:       "/admin/users",
+			method:     http.MethodGet,
+			url:        "/admin/users?page_id=1&page_size=5",
+			authUserID: admin.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).Return(admin, nil)
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return([]db.User{admin, disabled}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "ListUsersForbidden",
+			path:       "/admin/users",
+			method:     http.MethodGet,
+			url:        "/admin/users?page_id=1&page_size=5",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Return(user, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "CreateFood",
+			path:       "/admin/foods",
+			method:     http.MethodPost,
+			url:        "/admin/foods",
+			body:       gin.H{"name": "apple", "calories": 52.5},
+			authUserID: admin.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).Return(admin, nil)
+				store.EXPECT().CreateFoodContent(gomock.Any(), gomock.Any()).Return(randomFood(), nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "DeleteCategory",
+			path:       "/admin/categories/{id}",
+			method:     http.MethodDelete,
+			url:        "/admin/categories/1",
+			header:     http.Header{"If-Match": {`"1"`}},
+			authUserID: admin.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).Return(admin, nil)
+				store.EXPECT().DeleteCategoryTx(gomock.Any(), gomock.Eq(db.DeleteCategoryParams{ID: 1, Version: 1}), gomock.Any()).Return(db.Category{ID: 1}, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "PreconditionRequired",
+			path:       "/admin/categories/{id}",
+			method:     http.MethodDelete,
+			url:        "/admin/categories/1",
+			authUserID: admin.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).Return(admin, nil)
+			},
+			wantStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:       "UpdateExpense",
+			path:       "/expenses/{id}",
+			method:     http.MethodPatch,
+			url:        "/expenses/1",
+			body:       gin.H{"amount": 1500, "comment": ""},
+			header:     http.Header{"If-Match": {`"2"`}},
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(db.Expense{ID: 1, UserID: user.ID, CategoryID: 1, Amount: 1500, CreatedAt: time.Now(), Version: 3}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "InternalError",
+			path:       "/users/me",
+			method:     http.MethodGet,
+			url:        "/users/me",
+			authUserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Return(db.User{}, sql.ErrConnDone)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "Readiness",
+			path:   "/readyz",
+			method: http.MethodGet,
+			url:    "/readyz",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Return(uint(1), false, nil)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "Liveness",
+			path:       "/healthz",
+			method:     http.MethodGet,
+			url:        "/healthz",
+			buildStubs: func(store *mockdb.MockStore) {},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Metrics",
+			path:       "/metrics",
+			method:     http.MethodGet,
+			url:        "/metrics",
+			buildStubs: func(store *mockdb.MockStore) {},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "OpenAPISpec",
+			path:       "/openapi.json",
+			method:     http.MethodGet,
+			url:        "/openapi.json",
+			buildStubs: func(store *mockdb.MockStore) {},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	spec := loadOpenAPISpec(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			operation := lookupOperation(t, spec, tc.path, tc.method)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			manager := auth.NewMockManager(store)
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			var data []byte
+			if tc.body != nil {
+				data, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+				validateRequestBody(t, spec, operation, data)
+			}
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+			for key, values := range tc.header {
+				request.Header[key] = values
+			}
+			if tc.authUserID != 0 {
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				manager.UserID = tc.authUserID
+				addCompleteAuth(t, request, manager)
+			}
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, tc.wantStatus, recorder.Code, recorder.Body.String())
+			validateResponse(t, spec, operation, recorder)
+		})
+	}
+}
+
+func lookupOperation(t *testing.T, spec map[string]interface{}, path string, method string) map[string]interface{} {
+	item, ok := spec["paths"].(map[string]interface{})[path].(map[string]interface{})
+	require.Truef(t, ok, "path [%s] is not documented", path)
+	operation, ok := item[strings.ToLower(method)].(map[string]interface{})
+	require.Truef(t, ok, "operation [%s %s] is not documented", method, path)
+	return operation
+}
+
+// "#/components/schemas/User" のような参照先を取得する。
+func lookupRef(spec map[string]interface{}, ref string) map[string]interface{} {
+	var current interface{} = spec
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	m, _ := current.(map[string]interface{})
+	return m
+}
+
+func resolve(t *testing.T, spec map[string]interface{}, v map[string]interface{}) map[string]interface{} {
+	for {
+		ref, ok := v["$ref"].(string)
+		if !ok {
+			return v
+		}
+		v = lookupRef(spec, ref)
+		require.NotNilf(t, v, "unresolvable $ref [%s]", ref)
+	}
+}
+
+func validateRequestBody(t *testing.T, spec map[string]interface{}, operation map[string]interface{}, data []byte) {
+	requestBody, ok := operation["requestBody"].(map[string]interface{})
+	require.True(t, ok, "request body is not documented")
+	schema := requestBody["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+
+	var body interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	require.NoError(t, validateSchema(t, spec, schema, body, "request"))
+}
+
+func validateResponse(t *testing.T, spec map[string]interface{}, operation map[string]interface{}, recorder *httptest.ResponseRecorder) {
+	response, ok := operation["responses"].(map[string]interface{})[strconv.Itoa(recorder.Code)].(map[string]interface{})
+	require.Truef(t, ok, "status [%d] is not documented", recorder.Code)
+	response = resolve(t, spec, response)
+
+	content, ok := response["content"].(map[string]interface{})
+	if !ok {
+		require.Empty(t, recorder.Body.String(), "response body is not documented")
+		return
+	}
+	mediaType := strings.Split(recorder.Header().Get("Content-Type"), ";")[0]
+	media, ok := content[mediaType].(map[string]interface{})
+	require.Truef(t, ok, "content type [%s] is not documented", mediaType)
+	if mediaType != "application/json" {
+		return
+	}
+
+	var body interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.NoError(t, validateSchema(t, spec, media["schema"].(map[string]interface{}), body, "response"))
+}
+
+// allOf を1つのオブジェクトのスキーマにまとめる。
+func mergeAllOf(t *testing.T, spec map[string]interface{}, schemas []interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []interface{}{}
+	for _, s := range schemas {
+		schema := resolve(t, spec, s.(map[string]interface{}))
+		if allOf, ok := schema["allOf"].([]interface{}); ok {
+			schema = mergeAllOf(t, spec, allOf)
+		}
+		for name, property := range schema["properties"].(map[string]interface{}) {
+			properties[name] = property
+		}
+		if r, ok := schema["required"].([]interface{}); ok {
+			required = append(required, r...)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// テストで使う範囲の JSON Schema（type, nullable, required, properties, items, enum, format）で値を検証する。
+func validateSchema(t *testing.T, spec map[string]interface{}, schema map[string]interface{}, value interface{}, path string) error {
+	schema = resolve(t, spec, schema)
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		schema = mergeAllOf(t, spec, allOf)
+	}
+
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", path)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if e == value {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an object", path)
+		}
+		properties, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			// キーが任意のオブジェクトは、値のスキーマのみ検証する。
+			additional, ok := schema["additionalProperties"].(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			for name, v := range obj {
+				if err := validateSchema(t, spec, additional, v, path+"."+name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := obj[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required property [%s]", path, name)
+				}
+			}
+		}
+		for name, v := range obj {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: undocumented property [%s]", path, name)
+			}
+			if err := validateSchema(t, spec, property, v, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an array", path)
+		}
+		for i, v := range arr {
+			if err := validateSchema(t, spec, schema["items"].(map[string]interface{}), v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: must be a string", path)
+		}
+		switch schema["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: must be a date-time: %w", path, err)
+			}
+		case "uuid":
+			if _, err := uuid.Parse(s); err != nil {
+				return fmt.Errorf("%s: must be a uuid: %w", path, err)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: must be an integer", path)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: must be a number", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: must be a boolean", path)
+		}
+	}
+	return nil
+}
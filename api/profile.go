@@ -0,0 +1,195 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+const (
+	// メールアドレス変更の確認トークンの有効期限。
+	emailChangeTokenDuration = 24 * time.Hour
+	// メールアドレス変更の確認トークンのバイト数。
+	emailChangeTokenSize = 32
+)
+
+// プロフィール更新用のRequestのpayload。
+// 指定された項目のみを更新する。
+type updateProfileRequest struct {
+	Name              *string `json:"username" binding:"omitempty,min=1,max=100"`
+	Age               *int32  `json:"age" binding:"omitempty,min=0,max=150"`
+	PreferredCurrency *string `json:"preferred_currency" binding:"omitempty,len=3,alpha,uppercase"`
+	Locale            *string `json:"locale" binding:"omitempty,oneof=ja en"`
+	Timezone          *string `json:"timezone" binding:"omitempty,timezone"`
+}
+
+// メールアドレス変更用のRequestのpayload。
+type changeEmailRequest struct {
+	Email string `json:"email" binding:"required,email" log:"email"`
+}
+
+// メールアドレス変更の確認用のRequestのpayload。
+type confirmEmailRequest struct {
+	Token string `json:"token" binding:"required" log:"secret"`
+}
+
+// 自分のプロフィールを取得するエンドポイント。
+func (server *Server) getProfile(c *gin.Context) {
+	user, err := server.store.GetUserByID(c, authUserID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// 自分のプロフィールを更新するエンドポイント。
+func (server *Server) updateProfile(c *gin.Context) {
+	var req updateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	user, err := server.store.GetUserByID(c, authUserID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	setUserLocale(c, user.Locale)
+
+	// 指定されなかった項目は現在の値のまま更新する。
+	arg := db.UpdateUserProfileParams{
+		Name:              user.Name,
+		Age:               user.Age,
+		PreferredCurrency: user.PreferredCurrency,
+		Locale:            user.Locale,
+		Timezone:          user.Timezone,
+		ID:                user.ID,
+	}
+	if req.Name != nil {
+		arg.Name = *req.Name
+	}
+	if req.Age != nil {
+		arg.Age = *req.Age
+	}
+	if req.PreferredCurrency != nil {
+		arg.PreferredCurrency = *req.PreferredCurrency
+	}
+	if req.Locale != nil {
+		arg.Locale = *req.Locale
+	}
+	if req.Timezone != nil {
+		arg.Timezone = *req.Timezone
+	}
+
+	user, err = server.store.UpdateUserProfile(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserProfile: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// メールアドレスの変更を受け付けるエンドポイント。
+// 新しいメールアドレスに確認トークンを送り、確認されるまでは変更しない。
+func (server *Server) changeEmail(c *gin.Context) {
+	if server.mailSender == nil {
+		abortWithError(c, http.StatusServiceUnavailable, codeMailUnavailable, "error.mail_unavailable")
+		return
+	}
+
+	var req changeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	// 登録済みのメールアドレスには変更できない。
+	_, err := server.store.GetUser(c, req.Email)
+	if err != sql.ErrNoRows {
+		if err == nil {
+			abortWithError(c, http.StatusConflict, codeEmailAlreadyRegistered, "error.email_already_registered")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUser: %w", err))
+		return
+	}
+
+	token, err := util.GenerateToken(emailChangeTokenSize)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to GenerateToken: %w", err))
+		return
+	}
+
+	arg := db.CreateEmailChangeTokenParams{
+		UserID:    authUserID(c),
+		NewEmail:  req.Email,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: time.Now().Add(emailChangeTokenDuration),
+	}
+	if _, err := server.store.CreateEmailChangeToken(c, arg); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to CreateEmailChangeToken: %w", err))
+		return
+	}
+
+	// メールはリクエストの言語ではなく、プロフィールに設定された言語で送る。
+	locale := userLocale(c)
+	subject := i18n.T(locale, "mail.email_change.subject")
+	body := i18n.T(locale, "mail.email_change.body",
+		"token", token,
+		"hours", strconv.Itoa(int(emailChangeTokenDuration.Hours())))
+	if err := server.mailSender.Send(c, req.Email, subject, body); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to Send: %w", err))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// 確認トークンを検証し、メールアドレスを変更するエンドポイント。
+// トークンの受け取りを以て本人確認とするため、ログインは不要とする。
+func (server *Server) confirmEmail(c *gin.Context) {
+	var req confirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	// 使用済み、もしくは有効期限切れのトークンは見つからない。
+	// メールアドレスを変更できなかった場合は、トークンを使用済みにしない。
+	user, err := server.store.ConfirmEmailChangeTx(c, util.HashToken(req.Token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusBadRequest, codeInvalidToken, "error.invalid_token")
+			return
+		}
+		// トークン発行後に、他のユーザーが同じメールアドレスで登録した場合。
+		if errors.Is(err, db.ErrUniqueViolation) {
+			abortWithError(c, http.StatusConflict, codeEmailAlreadyRegistered, "error.email_already_registered")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to ConfirmEmailChangeTx: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -0,0 +1,482 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/i18n"
+	"github.com/kokoichi206/account-book-api/mail"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomProfileUser() db.User {
+	user := randomUser(auth.RoleUser)
+	user.Password = util.RandomString(60)
+	user.PreferredCurrency = "JPY"
+	user.Locale = "ja"
+	user.Timezone = "Asia/Tokyo"
+	return user
+}
+
+func TestGetProfile(t *testing.T) {
+	user := randomProfileUser()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp userResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Equal(t, newUserResponse(user).Email, rsp.Email)
+				require.Equal(t, user.Timezone, rsp.Timezone)
+				// パスワードのハッシュは返さない。
+				require.NotContains(t, string(data), user.Password)
+			},
+		},
+		{
+			// セッションが有効なまま、ユーザーの行が見つからない場合。
+			name: "UserNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				checkError(t, codeUnauthenticated, recorder.Body)
+			},
+		},
+		{
+			name: "DBError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me", nil)
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	user := randomProfileUser()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"locale":             "en",
+				"timezone":           "America/New_York",
+				"preferred_currency": "USD",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				// 指定していない項目は現在の値のまま。
+				arg := db.UpdateUserProfileParams{
+					Name:              user.Name,
+					Age:               user.Age,
+					PreferredCurrency: "USD",
+					Locale:            "en",
+					Timezone:          "America/New_York",
+					ID:                user.ID,
+				}
+				updated := user
+				updated.PreferredCurrency = arg.PreferredCurrency
+				updated.Locale = arg.Locale
+				updated.Timezone = arg.Timezone
+				store.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				checkBodyContains(t, recorder, "America/New_York")
+			},
+		},
+		{
+			name: "BindRequestErrorWithInvalidTimezone",
+			body: gin.H{
+				"timezone": "Mars/Olympus",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BindRequestErrorWithInvalidCurrency",
+			body: gin.H{
+				"preferred_currency": "yen",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DBErrorWhenUpdateUserProfile",
+			body: gin.H{
+				"username": util.RandomUserName(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewReader(data))
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	user := randomProfileUser()
+	newEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		noSender      bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, sender *mail.MockSender)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": newEmail},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, newEmail, arg.NewEmail)
+						require.WithinDuration(t, time.Now().Add(emailChangeTokenDuration), arg.ExpiresAt, time.Second)
+						return db.EmailChangeToken{UserID: arg.UserID, NewEmail: arg.NewEmail, TokenHash: arg.TokenHash}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, sender *mail.MockSender) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				// 新しいメールアドレス宛に確認トークンが送られること。
+				require.Equal(t, newEmail, sender.To)
+				// リクエストの言語ではなく、プロフィールの言語で送ること。
+				require.Equal(t, "Confirm your new email address", sender.Subject)
+				require.Contains(t, sender.Body, "The code expires in 24 hours.")
+				// トークンはレスポンスに含めない。
+				require.Empty(t, recorder.Body.String())
+			},
+		},
+		{
+			name: "AlreadyRegistered",
+			body: gin.H{"email": newEmail},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(randomProfileUser(), nil)
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, sender *mail.MockSender) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Empty(t, sender.To)
+			},
+		},
+		{
+			// SMTP_HOST が未指定の場合は、確認トークンを作成しない。
+			name:     "MailUnavailable",
+			body:     gin.H{"email": newEmail},
+			noSender: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, sender *mail.MockSender) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				checkBodyContains(t, recorder, "mail_unavailable")
+			},
+		},
+		{
+			name: "BindRequestErrorWithInvalidEmail",
+			body: gin.H{"email": "invalid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, sender *mail.MockSender) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+			manager.Locale = i18n.English
+
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
+			sender := mail.NewMockSender()
+			if !tc.noSender {
+				server.mailSender = sender
+			}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/me/email", bytes.NewReader(data))
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+			request.Header.Set(acceptLanguageHeaderKey, i18n.Japanese)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder, sender)
+		})
+	}
+}
+
+func TestConfirmEmail(t *testing.T) {
+	user := randomProfileUser()
+	token := util.RandomString(43)
+	emailChangeToken := db.EmailChangeToken{
+		ID:        util.RandomID(),
+		UserID:    user.ID,
+		NewEmail:  util.RandomEmail(),
+		TokenHash: util.HashToken(token),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := user
+				updated.Email = emailChangeToken.NewEmail
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Eq(emailChangeToken.TokenHash)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				checkBodyContains(t, recorder, emailChangeToken.NewEmail)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyRegistered",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "BindRequestErrorWithoutToken",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(newTestConfig(), store, auth.NewMockManager(store), util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/email/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// ユーザーごとに、最後に更新に成功した時刻を保持する。
+// プロセスごとに保持するため、複数台で動かす場合は同じサーバーへのリクエストにのみ効果がある。
+type recentWrites struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	writtenAt map[int64]time.Time
+}
+
+func newRecentWrites(window time.Duration) *recentWrites {
+	return &recentWrites{
+		window:    window,
+		writtenAt: map[int64]time.Time{},
+	}
+}
+
+// userID が now に更新したことを記録する。
+// 保持する件数が増え続けないよう、window を過ぎた記録はここで削除する。
+func (w *recentWrites) record(userID int64, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id, at := range w.writtenAt {
+		if now.Sub(at) >= w.window {
+			delete(w.writtenAt, id)
+		}
+	}
+	w.writtenAt[userID] = now
+}
+
+// userID が now から window 以内に更新したか。
+func (w *recentWrites) wroteWithin(userID int64, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	at, ok := w.writtenAt[userID]
+	return ok && now.Sub(at) < w.window
+}
+
+// 更新のリクエストと、更新してから READ_YOUR_WRITES_WINDOW 以内のリクエストの参照を、
+// レプリカではなくプライマリで実行するmiddleware。
+// レプリカの遅延によって、更新した直後の一覧に結果が反映されないことを防ぐ。
+// DB_REPLICA_SOURCE が未指定の場合は何もしない。
+func (server *Server) readYourWritesMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if server.config.DBReplicaSource == "" {
+			c.Next()
+			return
+		}
+
+		userID := authUserID(c)
+		mutation := isMutationMethod(c.Request.Method)
+		if mutation || server.recentWrites.wroteWithin(userID, time.Now()) {
+			c.Request = c.Request.WithContext(db.WithPrimary(c.Request.Context()))
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if mutation && status >= http.StatusOK && status < http.StatusMultipleChoices {
+			server.recentWrites.record(userID, time.Now())
+		}
+	}
+}
+
+// データを更新するHTTPメソッドか。
+func isMutationMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadYourWritesMiddleware(t *testing.T) {
+	userID := util.RandomID()
+	otherUserID := util.RandomID()
+
+	// 順に送るリクエストと、参照を実行すべきDB。
+	steps := []struct {
+		name         string
+		method       string
+		userID       int64
+		status       int
+		expired      bool
+		expectTarget string
+	}{
+		{name: "ReadBeforeWrite", method: http.MethodGet, userID: userID, status: http.StatusOK, expectTarget: "replica"},
+		{name: "FailedWrite", method: http.MethodPost, userID: userID, status: http.StatusBadRequest, expectTarget: "primary"},
+		{name: "ReadAfterFailedWrite", method: http.MethodGet, userID: userID, status: http.StatusOK, expectTarget: "replica"},
+		{name: "Write", method: http.MethodPost, userID: userID, status: http.StatusCreated, expectTarget: "primary"},
+		{name: "ReadAfterWrite", method: http.MethodGet, userID: userID, status: http.StatusOK, expectTarget: "primary"},
+		{name: "OtherUser", method: http.MethodGet, userID: otherUserID, status: http.StatusOK, expectTarget: "replica"},
+		{name: "ReadAfterWindow", method: http.MethodGet, userID: userID, status: http.StatusOK, expired: true, expectTarget: "replica"},
+	}
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	primary := mockdb.NewMockStore(ctrl)
+	replica := mockdb.NewMockStore(ctrl)
+	config := newTestConfig()
+	config.DBReplicaSource = "postgresql://replica"
+	config.ReadYourWritesWindow = time.Minute
+	server := &Server{config: config, store: db.NewReplicaStore(primary, replica), recentWrites: newRecentWrites(config.ReadYourWritesWindow)}
+
+	for _, step := range steps {
+		step := step
+		t.Run(step.name, func(t *testing.T) {
+			target := replica
+			if step.expectTarget == "primary" {
+				target = primary
+			}
+			target.EXPECT().
+				ListExpenses(gomock.Any(), gomock.Eq(step.userID)).
+				Times(1).
+				Return([]db.ListExpensesRow{}, nil)
+			if step.expired {
+				server.recentWrites.mu.Lock()
+				server.recentWrites.writtenAt[step.userID] = time.Now().Add(-config.ReadYourWritesWindow)
+				server.recentWrites.mu.Unlock()
+			}
+
+			router := gin.New()
+			router.Handle(
+				step.method,
+				"/expenses",
+				func(ctx *gin.Context) {
+					ctx.Set(authUserIDKey, step.userID)
+				},
+				server.readYourWritesMiddleware(),
+				func(ctx *gin.Context) {
+					_, err := server.store.ListExpenses(ctx, authUserID(ctx))
+					require.NoError(t, err)
+					ctx.Status(step.status)
+				},
+			)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(step.method, "/expenses", nil)
+			require.NoError(t, err)
+
+			// Act
+			router.ServeHTTP(recorder, request)
+
+			// Assert
+			require.Equal(t, step.status, recorder.Code)
+		})
+	}
+}
@@ -1,92 +1,131 @@
 package api
 
 import (
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
 	"github.com/kokoichi206/account-book-api/util"
-	"go.uber.org/zap"
 )
 
 // レシート登録用のpayload。
 type createReceiptRequest struct {
 	StoreName    string        `json:"store_name" binding:"required"`
 	FoodContents []foodContent `json:"food_contents" binding:"required"`
-	TotalPrice   int           `json:"total_price" binding:"required"`
-}
-
-// 出力用のJSONを取得する。
-func (request createReceiptRequest) MustJSONString() string {
-	bytes, err := json.Marshal(request)
-	if err != nil {
-		return ""
-	}
-	return string(bytes)
+	TotalPrice   int           `json:"total_price" binding:"required" log:"secret"`
 }
 
 // レシート登録時に使う、１つの食品用の構造体。
 type foodContent struct {
 	Name  string `json:"name" binding:"required"`
-	Price int    `json:"price" binding:"required"`
+	Price int    `json:"price" binding:"required" log:"secret"`
 }
 
 // １枚のレシートを登録するエンドポイント。
+// DUPLICATE_WINDOW 以内に店名・合計金額・明細が同じレシートを登録している場合は、
+// force=true を指定しない限り登録せずに409を返す。
 func (server *Server) createReceipt(c *gin.Context) {
 	var req createReceiptRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, err)
+		abortWithBindError(c, err)
+		return
+	}
+	var check duplicateCheckRequest
+	if err := c.ShouldBindQuery(&check); err != nil {
+		abortWithBindError(c, err)
 		return
 	}
 
 	// MAYBE: これはDebugかInfoか。
-	zap.S().Debug(req.MustJSONString())
+	requestLogger(c).Debugw("request", "body", util.Redact(req))
 
 	storeName := req.StoreName
-	foodReceipt, err := server.querier.CreateFoodReceipt(c, storeName)
-	if err != nil {
-		err = fmt.Errorf("failed to CreateFoodReceipt: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
-		return
+	arg := db.CreateReceiptTxParams{
+		StoreName:   storeName,
+		UserID:      authUserID(c),
+		TotalPrice:  int64(req.TotalPrice),
+		ItemsDigest: receiptItemsDigest(req.FoodContents),
 	}
-
-	for _, content := range req.FoodContents {
-		// TODO: 商品名（と店名）から栄養素を取得する。もし存在しなければ保存する（？）
-		foodContent := getFoodContent(storeName, content)
-		arg := db.CreateFoodReceiptContentParams{
-			FoodReceiptID: foodReceipt.ID,
-			FoodContentID: foodContent.ID,
-			Amount:        1,
-		}
-		// MAYBE: 一部商品だけ登録されることがあっていいのか、考える。
-		// もしかしたら transaction を行う必要があるかも。
-		_, err := server.querier.CreateFoodReceiptContent(c, arg)
+	if !check.Force {
+		duplicates, err := server.store.ListDuplicateFoodReceiptCandidates(c, db.ListDuplicateFoodReceiptCandidatesParams{
+			UserID:      sql.NullInt64{Int64: arg.UserID, Valid: true},
+			StoreName:   arg.StoreName,
+			TotalPrice:  sql.NullInt64{Int64: arg.TotalPrice, Valid: true},
+			ItemsDigest: sql.NullString{String: arg.ItemsDigest, Valid: true},
+			Since:       time.Now().Add(-server.config.DuplicateWindow),
+		})
 		if err != nil {
-			err = fmt.Errorf("failed to CreateFoodReceiptContent: %w", err)
-			zap.S().Error(err)
-
-			c.Error(err)
-			c.JSON(http.StatusInternalServerError, err)
+			abortWithInternalError(c, fmt.Errorf("failed to ListDuplicateFoodReceiptCandidates: %w", err))
+			return
+		}
+		if len(duplicates) > 0 {
+			rsp := make([]duplicateReceiptResponse, 0, len(duplicates))
+			for _, receipt := range duplicates {
+				rsp = append(rsp, duplicateReceiptResponse{
+					ID:         receipt.ID,
+					StoreName:  receipt.StoreName,
+					TotalPrice: receipt.TotalPrice.Int64,
+					CreatedAt:  receipt.CreatedAt,
+					Version:    receipt.Version,
+				})
+			}
+			abortWithDuplicates(c, rsp)
 			return
 		}
 	}
+
+	// 明細は商品名で食品に紐づけ、未登録の食品は同じトランザクションで作成する。
+	for _, content := range req.FoodContents {
+		arg.Contents = append(arg.Contents, db.CreateReceiptContentParams{
+			FoodName: content.Name,
+			Amount:   1,
+		})
+	}
+
+	// レシートと全ての明細を、監査ログと共に１つのトランザクションで登録する。
+	if _, err := server.store.CreateReceiptTx(c, arg, auditActor(c)); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to CreateReceiptTx: %w", err))
+		return
+	}
+	server.metrics.ReceiptParsed()
 	c.Status(http.StatusOK)
 }
 
-// 商品名と店名から栄養素を取得する。
-func getFoodContent(storeName string, foodContent foodContent) db.FoodContent {
-	// TODO: 検索するシステムを作成する。
-	return db.FoodContent{
-		ID:           util.RandomInt(0, 10_0000),
-		Name:         util.RandomFoodName(),
-		Calories:     util.RandomCalories(),
-		Lipid:        util.RandomNutrient(),
-		Carbohydrate: util.RandomNutrient(),
-		Protein:      util.RandomNutrient(),
+// レシートをゴミ箱に移すエンドポイント。
+// 認証したユーザーが登録したレシートのみ削除できる。
+// If-Match の version が一致する場合のみ削除する。
+func (server *Server) deleteReceipt(c *gin.Context) {
+	var req idRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	arg := db.SoftDeleteFoodReceiptParams{
+		ID:      req.ID,
+		UserID:  authUserID(c),
+		Version: version,
 	}
+	if _, err := server.store.DeleteReceiptTx(c, arg, auditActor(c)); err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, "error.receipt_not_found")
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			abortWithPreconditionFailed(c)
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteReceiptTx: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
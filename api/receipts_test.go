@@ -2,8 +2,10 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -36,11 +38,12 @@ func TestCreateReceipt(t *testing.T) {
 		foodContent2,
 		foodContent3,
 	}
+	totalPrice := foodContent1.Price + foodContent2.Price + foodContent3.Price
 	// １枚のレシートとして送られてくる情報。
 	correctBody := gin.H{
 		"store_name":    storeName,
 		"food_contents": foodContents,
-		"total_price":   foodContent1.Price + foodContent2.Price + foodContent3.Price,
+		"total_price":   totalPrice,
 	}
 	missingBody := gin.H{
 		"store_name":    storeName,
@@ -55,24 +58,40 @@ func TestCreateReceipt(t *testing.T) {
 	testCases := []struct {
 		name          string
 		body          gin.H
+		query         string
 		setupAuth     func(t *testing.T, request *http.Request, manager *auth.MockUuidSessionManager)
-		buildStubs    func(querier *mockdb.MockQuerier)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateFoodReceipt(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(foodReceipt, nil)
-				querier.EXPECT().
-					CreateFoodReceiptContent(gomock.Any(), gomock.Any()).
-					Times(3)
+					DoAndReturn(func(_ context.Context, arg db.ListDuplicateFoodReceiptCandidatesParams) ([]db.FoodReceipt, error) {
+						require.Equal(t, storeName, arg.StoreName)
+						require.Equal(t, int64(totalPrice), arg.TotalPrice.Int64)
+						require.Equal(t, receiptItemsDigest(foodContents), arg.ItemsDigest.String)
+						return []db.FoodReceipt{}, nil
+					})
+				store.EXPECT().
+					CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateReceiptTxParams, actor db.AuditActor) (db.CreateReceiptTxResult, error) {
+						// レシートの全ての明細を、１つのトランザクションで登録する。
+						require.Equal(t, storeName, arg.StoreName)
+						require.Len(t, arg.Contents, len(foodContents))
+						require.Equal(t, int64(totalPrice), arg.TotalPrice)
+						require.Equal(t, receiptItemsDigest(foodContents), arg.ItemsDigest)
+						// セッションで認証されたため、セッションIDを記録する。
+						require.True(t, actor.SessionID.Valid)
+						return db.CreateReceiptTxResult{FoodReceipt: foodReceipt}, nil
+					})
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -84,16 +103,13 @@ func TestCreateReceipt(t *testing.T) {
 		{
 			name: "BindRequestErrorWithMissingParam",
 			body: missingBody,
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateFoodReceipt(gomock.Any(), gomock.Any()).
-					Times(0)
-				querier.EXPECT().
-					CreateFoodReceiptContent(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(0)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -103,19 +119,20 @@ func TestCreateReceipt(t *testing.T) {
 			},
 		},
 		{
-			name: "CreateFoodReceiptDBError",
+			name: "CreateReceiptTxDBError",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateFoodReceipt(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.FoodReceipt{}, sql.ErrConnDone)
-				querier.EXPECT().
-					CreateFoodReceiptContent(gomock.Any(), gomock.Any()).
-					Times(0)
+					Return([]db.FoodReceipt{}, nil)
+				store.EXPECT().
+					CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateReceiptTxResult{}, sql.ErrConnDone)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -125,20 +142,73 @@ func TestCreateReceipt(t *testing.T) {
 			},
 		},
 		{
-			name: "CreateFoodReceiptContentDBError",
+			name: "PossibleDuplicate",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier) {
-				querier.EXPECT().
-					CreateFoodReceipt(gomock.Any(), gomock.Any()).
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.FoodReceipt{foodReceipt}, nil)
+				store.EXPECT().
+					CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(foodReceipt, nil)
-				querier.EXPECT().
-					CreateFoodReceiptContent(gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+
+				var body struct {
+					Error      errorBody                  `json:"error"`
+					Duplicates []duplicateReceiptResponse `json:"duplicates"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, codePossibleDuplicate, body.Error.Code)
+				require.Len(t, body.Duplicates, 1)
+				require.Equal(t, foodReceipt.ID, body.Duplicates[0].ID)
+			},
+		},
+		{
+			name:  "ForceSkipsDuplicateCheck",
+			body:  correctBody,
+			query: "?force=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.FoodReceiptContent{}, sql.ErrConnDone)
+					Return(db.CreateReceiptTxResult{FoodReceipt: foodReceipt}, nil)
 
 				// authのmiddlewareを通すため。
-				querier.EXPECT().
+				store.EXPECT().
+					UpdateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ListDuplicateCandidatesDBError",
+			body: correctBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDuplicateFoodReceiptCandidates(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().
+					CreateReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				// authのmiddlewareを通すため。
+				store.EXPECT().
 					UpdateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil)
@@ -157,18 +227,18 @@ func TestCreateReceipt(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			querier := mockdb.NewMockQuerier(ctrl)
-			tc.buildStubs(querier)
-			manager := auth.NewMockManager(querier)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			manager := auth.NewMockManager(store)
 
-			server := NewServer(util.Config{}, querier, manager, util.InitLogger())
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
 			recorder := httptest.NewRecorder()
 			url := "/receipts"
 
 			data, err := json.Marshal(tc.body)
 			require.NoError(t, err)
 
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request, err := http.NewRequest(http.MethodPost, url+tc.query, bytes.NewReader(data))
 			require.NoError(t, err)
 			addCompleteAuth(t, request, manager)
 
@@ -180,3 +250,128 @@ func TestCreateReceipt(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteReceipt(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+	receiptID := util.RandomID()
+	url := fmt.Sprintf("/receipts/%d", receiptID)
+
+	testCases := []struct {
+		name          string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "AnyVersion",
+			header: http.Header{"If-Match": {"*"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteFoodReceiptParams{
+					ID:      receiptID,
+					UserID:  user.ID,
+					Version: db.AnyVersion,
+				}
+				store.EXPECT().
+					DeleteReceiptTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{ID: receiptID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "IfMatch",
+			header: http.Header{"If-Match": {`"2"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteFoodReceiptParams{
+					ID:      receiptID,
+					UserID:  user.ID,
+					Version: 2,
+				}
+				store.EXPECT().
+					DeleteReceiptTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{ID: receiptID, Version: 3}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "VersionMismatch",
+			header: http.Header{"If-Match": {`"2"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{ID: receiptID, Version: 4}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+				checkBodyContains(t, recorder, "precondition_required")
+			},
+		},
+		{
+			name:   "NotFound",
+			header: http.Header{"If-Match": {`"2"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "DBError",
+			header: http.Header{"If-Match": {`"2"`}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequestWithHeader(t, store, user.ID, http.MethodDelete, url, nil, tc.header)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
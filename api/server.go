@@ -1,9 +1,17 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/kokoichi206/account-book-api/auth"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/mail"
+	"github.com/kokoichi206/account-book-api/metrics"
 	"github.com/kokoichi206/account-book-api/util"
 	"go.uber.org/zap"
 )
@@ -11,50 +19,172 @@ import (
 // サーバーに関する情報を保持する構造体。
 type Server struct {
 	config         util.Config
-	querier        db.Querier
+	store          db.Store
 	router         *gin.Engine
 	sessionManager auth.SessionManager
-	logger         *zap.Logger
+	// SMTP_HOST が未指定の場合は nil になり、メールアドレスの変更を受け付けない。
+	mailSender mail.Sender
+	logger     *zap.Logger
+	metrics    *metrics.Metrics
+	// readyz で状態を確認するワーカー。
+	workers map[string]WorkerStatusReporter
+	// 参照をプライマリで実行するため、最近更新したユーザーを記録する。
+	recentWrites *recentWrites
 }
 
 // サーバーを作成し、返り値として受け取る。
-func NewServer(config util.Config, querier db.Querier, manager auth.SessionManager, logger *zap.Logger) *Server {
+func NewServer(config util.Config, store db.Store, manager auth.SessionManager, logger *zap.Logger, metrics *metrics.Metrics) *Server {
 
 	server := &Server{
 		config:         config,
-		querier:        querier,
+		store:          store,
 		sessionManager: manager,
+		mailSender:     newMailSender(config),
 		logger:         logger,
+		metrics:        metrics,
+		workers:        map[string]WorkerStatusReporter{},
+		recentWrites:   newRecentWrites(config.ReadYourWritesWindow),
 	}
 
 	server.setupRouter()
 	return server
 }
 
+// 設定に応じて、メールを送信する Sender を作成する。
+// 確認トークンなどをログに残さないよう、送信手段がない場合は nil を返す。
+func newMailSender(config util.Config) mail.Sender {
+	if config.SMTPHost == "" {
+		return nil
+	}
+	return mail.NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+}
+
 // ルーティングの設定を行い、構造体の変数に設定する。
 func (server *Server) setupRouter() {
+	registerValidatorTagName()
+
 	router := gin.New()
-	router.Use(util.GinLogger(server.logger), util.GinRecovery(server.logger, true))
+	router.HandleMethodNotAllowed = true
+	router.Use(server.requestIDMiddleware(), util.GinLogger(server.logger, server.metrics.ObserveHTTPRequest, livenessPath, readinessPath, metricsPath), util.GinRecovery(server.logger, true, abortWithPanic))
+	router.NoRoute(func(c *gin.Context) {
+		abortWithError(c, http.StatusNotFound, codeRouteNotFound, "error.route_not_found")
+	})
+	router.NoMethod(func(c *gin.Context) {
+		abortWithError(c, http.StatusMethodNotAllowed, codeMethodNotAllowed, "error.method_not_allowed")
+	})
+
+	router.GET(livenessPath, server.getLiveness)
+	router.GET(readinessPath, server.getReadiness)
+	router.GET(metricsPath, gin.WrapH(server.metrics.Handler()))
+	router.GET("/openapi.json", server.getOpenAPISpec)
+	// 本番では外部のCDNを読み込むページを公開しないよう、設定で有効にした場合のみ提供する。
+	if server.config.SwaggerUIEnabled {
+		router.GET("/docs", server.getSwaggerUI)
+	}
 
 	router.POST("/users", server.createUser)
 	router.POST("/login", server.loginUser)
-	router.POST("/logout", server.logout)
+	router.POST("/login/2fa", server.loginTwoFactor)
+	router.POST("/logout", server.csrfMiddleware(), server.logout)
+	router.POST("/users/email/confirm", server.confirmEmail)
+
+	authRoutes := router.Group("/").Use(server.authMiddleware(server.sessionManager), server.csrfMiddleware(), server.readYourWritesMiddleware())
+
+	authRoutes.POST("/receipts", server.requireScope(auth.ScopeWriteReceipts), server.idempotencyMiddleware(), server.createReceipt)
+	authRoutes.DELETE("/receipts/:id", server.requireScope(auth.ScopeWriteReceipts), server.deleteReceipt)
+	authRoutes.GET("/expenses", server.requireScope(auth.ScopeReadExpenses), server.getAllExpenses)
+	authRoutes.POST("/expenses", server.requireScope(auth.ScopeWriteExpenses), server.idempotencyMiddleware(), server.createExpense)
+	authRoutes.GET("/expenses/duplicates", server.requireScope(auth.ScopeReadExpenses), server.listDuplicateExpenses)
+	authRoutes.PATCH("/expenses/:id", server.requireScope(auth.ScopeWriteExpenses), server.updateExpense)
+	authRoutes.DELETE("/expenses/:id", server.requireScope(auth.ScopeWriteExpenses), server.deleteExpense)
+	authRoutes.GET("/categories", server.requireScope(auth.ScopeReadExpenses), server.listCategories)
+
+	// APIキーからは操作させないエンドポイント。
+	authRoutes.GET("/users/me", server.requireSession(), server.getProfile)
+	authRoutes.PATCH("/users/me", server.requireSession(), server.updateProfile)
+	authRoutes.POST("/users/me/email", server.requireSession(), server.changeEmail)
+	authRoutes.GET("/users/me/export", server.requireSession(), server.exportAccount)
+	authRoutes.DELETE("/users/me", server.requireSession(), server.deleteAccount)
+	authRoutes.DELETE("/users/me/deletion", server.requireSession(), server.cancelAccountDeletion)
+	authRoutes.POST("/users/me/2fa/setup", server.requireSession(), server.setupTwoFactor)
+	authRoutes.POST("/users/me/2fa/verify", server.requireSession(), server.verifyTwoFactor)
+	authRoutes.POST("/users/me/api-keys", server.requireSession(), server.createAPIKey)
+	authRoutes.GET("/users/me/api-keys", server.requireSession(), server.listAPIKeys)
+	authRoutes.DELETE("/users/me/api-keys/:id", server.requireSession(), server.revokeAPIKey)
+	authRoutes.GET("/audit", server.requireSession(), server.listAuditEvents)
+	authRoutes.GET("/trash", server.requireSession(), server.listTrash)
+	authRoutes.POST("/trash/:type/:id/restore", server.requireSession(), server.restoreTrashItem)
+
+	// 管理者のみが操作できるエンドポイント。
+	adminRoutes := router.Group("/admin").Use(
+		server.authMiddleware(server.sessionManager),
+		server.csrfMiddleware(),
+		server.requireSession(),
+		server.requireRole(auth.RoleAdmin),
+	)
 
-	authRoutes := router.Group("/").Use(server.authMiddleware(server.sessionManager))
+	adminRoutes.GET("/users", server.listUsers)
+	adminRoutes.POST("/users/:id/disable", server.disableUser)
+	adminRoutes.POST("/users/:id/enable", server.enableUser)
+	adminRoutes.DELETE("/users/:id/sessions", server.logoutUserSessions)
 
-	authRoutes.POST("/receipts", server.createReceipt)
-	authRoutes.GET("/expenses", server.getAllExpenses)
-	authRoutes.POST("/expenses", server.createExpense)
+	adminRoutes.GET("/categories", server.listCategories)
+	adminRoutes.POST("/categories", server.createCategory)
+	adminRoutes.PUT("/categories/:id", server.updateCategory)
+	adminRoutes.DELETE("/categories/:id", server.deleteCategory)
+
+	adminRoutes.GET("/foods", server.listFoods)
+	adminRoutes.POST("/foods", server.createFood)
+	adminRoutes.PUT("/foods/:id", server.updateFood)
+	adminRoutes.DELETE("/foods/:id", server.deleteFood)
 
 	server.router = router
 }
 
 // 指定したアドレスに対してHTTP serverを起動する。
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+// ctx がキャンセルされると新しい接続の受け付けを止め、処理中のリクエストの完了を待ってから終了する。
+func (server *Server) Start(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen [%s]: %w", address, err)
+	}
+	return server.Serve(ctx, listener)
 }
 
-// エラー情報をJSONとして返すための関数。
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// listener で受け付けた接続に対してHTTP serverを起動する。
+// 終了の流れは Start と同じで、正常に終了した場合は nil を返す。
+func (server *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           server.router,
+		ReadTimeout:       server.config.HTTPReadTimeout,
+		ReadHeaderTimeout: server.config.HTTPReadHeaderTimeout,
+		WriteTimeout:      server.config.HTTPWriteTimeout,
+		IdleTimeout:       server.config.HTTPIdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	server.logger.Info("shutting down server", zap.Duration("timeout", server.config.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), server.config.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		// 期限までに終わらなかったリクエストは、接続を切断して終了する。
+		httpServer.Close()
+		return fmt.Errorf("failed to shutdown server gracefully: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	server.logger.Info("server stopped")
+	return nil
 }
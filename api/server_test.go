@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// 終了のシグナルを受け取った時に処理中だったリクエストは、完了してからサーバーが終了すること。
+func TestServeGracefulShutdown(t *testing.T) {
+	testCases := []struct {
+		name            string
+		shutdownTimeout time.Duration
+		// 処理中のリクエストにかかる時間。
+		handlerDuration time.Duration
+		checkResult     func(t *testing.T, rsp *http.Response, rspErr error, serveErr error)
+	}{
+		{
+			name:            "DrainInFlightRequest",
+			shutdownTimeout: 5 * time.Second,
+			handlerDuration: 100 * time.Millisecond,
+			checkResult: func(t *testing.T, rsp *http.Response, rspErr error, serveErr error) {
+				require.NoError(t, serveErr)
+				require.NoError(t, rspErr)
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "DrainDeadlineExceeded",
+			shutdownTimeout: 50 * time.Millisecond,
+			handlerDuration: 2 * time.Second,
+			checkResult: func(t *testing.T, rsp *http.Response, rspErr error, serveErr error) {
+				require.ErrorIs(t, serveErr, context.DeadlineExceeded)
+				// 期限を過ぎたリクエストは切断される。
+				require.Error(t, rspErr)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			config := newTestConfig()
+			config.HTTPReadTimeout = 5 * time.Second
+			config.HTTPReadHeaderTimeout = 5 * time.Second
+			config.HTTPWriteTimeout = 5 * time.Second
+			config.HTTPIdleTimeout = 5 * time.Second
+			config.ShutdownTimeout = tc.shutdownTimeout
+			server := NewServer(config, nil, nil, util.InitLogger(), newTestMetrics())
+
+			started := make(chan struct{})
+			server.router.GET("/slow", func(c *gin.Context) {
+				close(started)
+				time.Sleep(tc.handlerDuration)
+				c.Status(http.StatusOK)
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- server.Serve(ctx, listener)
+			}()
+
+			type result struct {
+				rsp *http.Response
+				err error
+			}
+			rspCh := make(chan result, 1)
+			go func() {
+				rsp, err := http.Get(fmt.Sprintf("http://%s/slow", listener.Addr()))
+				if err == nil {
+					rsp.Body.Close()
+				}
+				rspCh <- result{rsp: rsp, err: err}
+			}()
+			<-started
+
+			// Act
+			cancel()
+
+			// Assert
+			err = <-serveErr
+			r := <-rspCh
+			tc.checkResult(t, r.rsp, r.err, err)
+		})
+	}
+}
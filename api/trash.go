@@ -0,0 +1,191 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kokoichi206/account-book-api/auth"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// ゴミ箱から復元できるものの種類。URLの :type に指定する。
+const (
+	trashTypeExpenses   = "expenses"
+	trashTypeReceipts   = "receipts"
+	trashTypeCategories = "categories"
+)
+
+// ゴミ箱にある支出のResponseのpayload。
+type trashedExpenseResponse struct {
+	ID            int64     `json:"id"`
+	CategoryID    int64     `json:"category_id"`
+	Amount        int64     `json:"amount"`
+	FoodReceiptID int64     `json:"food_receipt_id"`
+	Comment       string    `json:"comment"`
+	CreatedAt     time.Time `json:"created_at"`
+	DeletedAt     time.Time `json:"deleted_at"`
+	// この時刻を過ぎると完全に削除され、復元できなくなる。
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// ゴミ箱にあるレシートのResponseのpayload。
+type trashedReceiptResponse struct {
+	ID        int64     `json:"id"`
+	StoreName string    `json:"store_name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// ゴミ箱にあるカテゴリーのResponseのpayload。
+type trashedCategoryResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// ゴミ箱の一覧取得用のResponseのpayload。
+type listTrashResponse struct {
+	Expenses []trashedExpenseResponse `json:"expenses"`
+	Receipts []trashedReceiptResponse `json:"receipts"`
+	// 管理者以外には空の配列を返す。
+	Categories []trashedCategoryResponse `json:"categories"`
+}
+
+// ゴミ箱から復元する際の、URLのパラメーター。
+type restoreTrashItemRequest struct {
+	Type string `uri:"type" binding:"required,oneof=expenses receipts categories"`
+	ID   int64  `uri:"id" binding:"required,min=1"`
+}
+
+// ゴミ箱の中身を、新しく削除した順に取得するエンドポイント。
+// 支出とレシートは自身のもののみ、カテゴリーは管理者のみ取得できる。
+func (server *Server) listTrash(c *gin.Context) {
+	userID := authUserID(c)
+	user, err := server.store.GetUserByID(c, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+
+	expenses, err := server.store.ListDeletedExpenses(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListDeletedExpenses: %w", err))
+		return
+	}
+	receipts, err := server.store.ListDeletedFoodReceipts(c, userID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to ListDeletedFoodReceipts: %w", err))
+		return
+	}
+	categories := []db.Category{}
+	if auth.HasRole(user.Role, auth.RoleAdmin) {
+		categories, err = server.store.ListDeletedCategories(c)
+		if err != nil {
+			abortWithInternalError(c, fmt.Errorf("failed to ListDeletedCategories: %w", err))
+			return
+		}
+	}
+
+	retention := server.config.TrashRetentionPeriod
+	rsp := listTrashResponse{
+		Expenses:   []trashedExpenseResponse{},
+		Receipts:   []trashedReceiptResponse{},
+		Categories: []trashedCategoryResponse{},
+	}
+	for _, expense := range expenses {
+		rsp.Expenses = append(rsp.Expenses, trashedExpenseResponse{
+			ID:            expense.ID,
+			CategoryID:    expense.CategoryID,
+			Amount:        expense.Amount,
+			FoodReceiptID: expense.FoodReceiptID.Int64,
+			Comment:       expense.Comment.String,
+			CreatedAt:     expense.CreatedAt,
+			DeletedAt:     expense.DeletedAt.Time,
+			PurgeAt:       expense.DeletedAt.Time.Add(retention),
+		})
+	}
+	for _, receipt := range receipts {
+		rsp.Receipts = append(rsp.Receipts, trashedReceiptResponse{
+			ID:        receipt.ID,
+			StoreName: receipt.StoreName,
+			DeletedAt: receipt.DeletedAt.Time,
+			PurgeAt:   receipt.DeletedAt.Time.Add(retention),
+		})
+	}
+	for _, category := range categories {
+		rsp.Categories = append(rsp.Categories, trashedCategoryResponse{
+			ID:        category.ID,
+			Name:      category.Name,
+			DeletedAt: category.DeletedAt.Time,
+			PurgeAt:   category.DeletedAt.Time.Add(retention),
+		})
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// ゴミ箱から復元するエンドポイント。
+// カテゴリーは管理者のみ復元できる。
+func (server *Server) restoreTrashItem(c *gin.Context) {
+	var req restoreTrashItemRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	userID := authUserID(c)
+	actor := auditActor(c)
+	var err error
+	var notFound string
+	// 復元でも version が増えるため、復元後の ETag を返す。
+	var version int64
+	switch req.Type {
+	case trashTypeExpenses:
+		notFound = "error.expense_not_found"
+		var expense db.Expense
+		expense, err = server.store.RestoreExpenseTx(c, db.RestoreExpenseParams{ID: req.ID, UserID: userID}, actor)
+		version = expense.Version
+	case trashTypeReceipts:
+		notFound = "error.receipt_not_found"
+		var receipt db.FoodReceipt
+		receipt, err = server.store.RestoreReceiptTx(c, db.RestoreFoodReceiptParams{ID: req.ID, UserID: userID}, actor)
+		version = receipt.Version
+	case trashTypeCategories:
+		var user db.User
+		user, err = server.store.GetUserByID(c, userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+				return
+			}
+			abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+			return
+		}
+		if !auth.HasRole(user.Role, auth.RoleAdmin) {
+			abortWithError(c, http.StatusForbidden, codePermissionDenied, "error.permission_denied")
+			return
+		}
+		notFound = "error.category_not_found"
+		var category db.Category
+		category, err = server.store.RestoreCategoryTx(c, req.ID, actor)
+		version = category.Version
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusNotFound, codeNotFound, notFound)
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to restore %s: %w", req.Type, err))
+		return
+	}
+
+	setETag(c, version)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTrash(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+	admin := randomUser(auth.RoleAdmin)
+	deletedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	expenses := []db.Expense{
+		{
+			ID:         util.RandomID(),
+			UserID:     user.ID,
+			CategoryID: util.RandomID(),
+			Amount:     util.RandomExpense(),
+			CreatedAt:  deletedAt.Add(-time.Hour),
+			DeletedAt:  sql.NullTime{Time: deletedAt, Valid: true},
+		},
+	}
+	receipts := []db.FoodReceipt{
+		{
+			ID:        util.RandomID(),
+			StoreName: util.RandomString(8),
+			DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+		},
+	}
+	category := randomCategory()
+	category.DeletedAt = sql.NullTime{Time: deletedAt, Valid: true}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDeletedExpenses(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(expenses, nil)
+				store.EXPECT().
+					ListDeletedFoodReceipts(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(receipts, nil)
+				// カテゴリーは管理者のみ。
+				store.EXPECT().
+					ListDeletedCategories(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp listTrashResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Len(t, rsp.Expenses, 1)
+				require.Equal(t, expenses[0].ID, rsp.Expenses[0].ID)
+				require.True(t, deletedAt.Equal(rsp.Expenses[0].DeletedAt))
+				// 保持期間を過ぎると完全に削除される。
+				require.True(t, deletedAt.Add(newTestConfig().TrashRetentionPeriod).Equal(rsp.Expenses[0].PurgeAt))
+				require.Len(t, rsp.Receipts, 1)
+				require.Equal(t, receipts[0].StoreName, rsp.Receipts[0].StoreName)
+				require.Empty(t, rsp.Categories)
+			},
+		},
+		{
+			name: "AdminWithCategories",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDeletedExpenses(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return([]db.Expense{}, nil)
+				store.EXPECT().
+					ListDeletedFoodReceipts(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return([]db.FoodReceipt{}, nil)
+				store.EXPECT().
+					ListDeletedCategories(gomock.Any()).
+					Times(1).
+					Return([]db.Category{category}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp listTrashResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Empty(t, rsp.Expenses)
+				require.Empty(t, rsp.Receipts)
+				require.Len(t, rsp.Categories, 1)
+				require.Equal(t, category.Name, rsp.Categories[0].Name)
+			},
+		},
+		{
+			name: "DBError",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDeletedExpenses(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().
+					ListDeletedFoodReceipts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			addAdminAuthMock(store, tc.user)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequest(t, store, tc.user.ID, http.MethodGet, "/trash", nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRestoreTrashItem(t *testing.T) {
+	user := randomUser(auth.RoleUser)
+	admin := randomUser(auth.RoleAdmin)
+	id := util.RandomID()
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Expense",
+			user: user,
+			url:  fmt.Sprintf("/trash/expenses/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RestoreExpenseParams{ID: id, UserID: user.ID}
+				store.EXPECT().
+					RestoreExpenseTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.Expense{ID: id, Version: 3}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+				// 復元でも version が増えるため、復元後の ETag を返す。
+				require.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name: "Receipt",
+			user: user,
+			url:  fmt.Sprintf("/trash/receipts/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RestoreFoodReceiptParams{ID: id, UserID: user.ID}
+				store.EXPECT().
+					RestoreReceiptTx(gomock.Any(), gomock.Eq(arg), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{ID: id}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "CategoryByAdmin",
+			user: admin,
+			url:  fmt.Sprintf("/trash/categories/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					RestoreCategoryTx(gomock.Any(), gomock.Eq(id), gomock.Any()).
+					Times(1).
+					Return(db.Category{ID: id}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "CategoryByUser",
+			user: user,
+			url:  fmt.Sprintf("/trash/categories/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RestoreCategoryTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			user: user,
+			url:  fmt.Sprintf("/trash/expenses/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreExpenseTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Expense{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				checkBodyContains(t, recorder, "expense was not found")
+			},
+		},
+		{
+			name: "InvalidType",
+			user: user,
+			url:  fmt.Sprintf("/trash/foods/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DBError",
+			user: user,
+			url:  fmt.Sprintf("/trash/receipts/%d/restore", id),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RestoreReceiptTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FoodReceipt{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			tc.buildStubs(store)
+
+			// Act
+			recorder := serveAdminRequest(t, store, tc.user.ID, http.MethodPost, tc.url, nil)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/auth"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/metrics"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+const (
+	// 認証アプリに表示される発行者名。
+	totpIssuer = "account-book"
+	// ログイン時の2段階認証チャレンジの有効期限。
+	twoFactorChallengeDuration = 5 * time.Minute
+	// 1つのチャレンジで試せるコードの数。超えた場合はパスワードからやり直す。
+	maxTwoFactorAttempts = 5
+)
+
+// 2段階認証の設定開始時のResponseのpayload。
+type setupTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// 2段階認証の有効化用のRequestのpayload。
+type verifyTwoFactorRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" log:"secret"`
+}
+
+// 2段階認証の有効化時のResponseのpayload。
+type verifyTwoFactorResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ログイン時に2段階認証が必要な場合のResponseのpayload。
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeID       string `json:"challenge_id"`
+}
+
+// 2段階認証を伴うログイン用のRequestのpayload。
+// Code と RecoveryCode のどちらか一方を指定する。
+type loginTwoFactorRequest struct {
+	ChallengeID  string `json:"challenge_id" binding:"required,uuid"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode" log:"secret"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=Code" log:"secret"`
+}
+
+// 2段階認証の設定を開始するエンドポイント。
+// 秘密鍵を発行し、暗号化してDBに保存する。
+func (server *Server) setupTwoFactor(c *gin.Context) {
+	user, err := server.store.GetUserByID(c, authUserID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	if user.TotpEnabled {
+		abortWithError(c, http.StatusBadRequest, codeTwoFactorAlreadyEnabled, "error.two_factor_already_enabled")
+		return
+	}
+
+	key, err := util.DecodeEncryptionKey(server.config.TOTPEncryptionKey)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to DecodeEncryptionKey: %w", err))
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to GenerateTOTPSecret: %w", err))
+		return
+	}
+	encrypted, err := util.Encrypt(key, secret)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to Encrypt: %w", err))
+		return
+	}
+
+	arg := db.UpdateUserTOTPSecretParams{
+		TotpSecret: sql.NullString{
+			String: encrypted,
+			Valid:  true,
+		},
+		ID: user.ID,
+	}
+	if _, err := server.store.UpdateUserTOTPSecret(c, arg); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserTOTPSecret: %w", err))
+		return
+	}
+
+	rsp := setupTwoFactorResponse{
+		Secret:     secret,
+		OtpauthURI: auth.TOTPAuthURI(totpIssuer, user.Email, secret),
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// 認証アプリのコードを確認し、2段階認証を有効にするエンドポイント。
+// 有効化と同時にリカバリーコードを発行する（一度しか表示しない）。
+func (server *Server) verifyTwoFactor(c *gin.Context) {
+	var req verifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	user, err := server.store.GetUserByID(c, authUserID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.user_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	if !user.TotpSecret.Valid {
+		abortWithError(c, http.StatusBadRequest, codeTwoFactorNotStarted, "error.two_factor_not_started")
+		return
+	}
+
+	secret, err := server.decryptTOTPSecret(user)
+	if err != nil {
+		abortWithInternalError(c, err)
+		return
+	}
+	counter, ok := auth.MatchTOTPCode(secret, req.Code, time.Now())
+	if !ok {
+		abortWithError(c, http.StatusBadRequest, codeInvalidTwoFactorCode, "error.invalid_two_factor_code")
+		return
+	}
+	// ログインと同様に受け付けたステップを記録し、有効化に使ったコードでログインできないようにする。
+	n, err := server.store.UpdateUserTOTPLastCounter(c, db.UpdateUserTOTPLastCounterParams{
+		ID:      user.ID,
+		Counter: counter,
+	})
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to UpdateUserTOTPLastCounter: %w", err))
+		return
+	}
+	if n == 0 {
+		requestLogger(c).Warnf("reused two-factor code for user [%d]", user.ID)
+		abortWithError(c, http.StatusBadRequest, codeInvalidTwoFactorCode, "error.invalid_two_factor_code")
+		return
+	}
+
+	if _, err := server.store.EnableUserTOTP(c, user.ID); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to EnableUserTOTP: %w", err))
+		return
+	}
+
+	codes, err := server.resetRecoveryCodes(c, user.ID)
+	if err != nil {
+		abortWithInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verifyTwoFactorResponse{RecoveryCodes: codes})
+}
+
+// パスワード認証に成功したユーザーに対し、2段階認証のチャレンジを発行する。
+func (server *Server) startTwoFactorChallenge(c *gin.Context, user db.User) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to uuid.NewRandom: %w", err))
+		return
+	}
+
+	arg := db.CreateTwoFactorChallengeParams{
+		ID:        id,
+		UserID:    user.ID,
+		UserAgent: c.Request.UserAgent(),
+		ClientIp:  c.ClientIP(),
+		ExpiresAt: time.Now().Add(twoFactorChallengeDuration),
+	}
+	challenge, err := server.store.CreateTwoFactorChallenge(c, arg)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to CreateTwoFactorChallenge: %w", err))
+		return
+	}
+
+	rsp := twoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeID:       challenge.ID.String(),
+	}
+	c.JSON(http.StatusAccepted, rsp)
+}
+
+// 2段階認証のコード（もしくはリカバリーコード）を確認し、セッションを発行するエンドポイント。
+// 1つのチャレンジで試せるコードは maxTwoFactorAttempts 回までで、
+// TOTP のコードは受け付け済みのステップより後のもののみ受け付ける。
+func (server *Server) loginTwoFactor(c *gin.Context) {
+	var req loginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	challengeID, err := uuid.Parse(req.ChallengeID)
+	if err != nil {
+		abortWithFieldErrors(c, []fieldError{{Field: "challenge_id", Reason: "uuid"}})
+		return
+	}
+
+	challenge, err := server.store.GetTwoFactorChallenge(c, challengeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(c, http.StatusUnauthorized, codeTwoFactorNotVerified, "error.two_factor_challenge_not_found")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to GetTwoFactorChallenge: %w", err))
+		return
+	}
+	// セッションと同様に、チャレンジの発行元と同一の端末からのみ受け付ける。
+	if !challenge.ExpiresAt.After(time.Now()) ||
+		challenge.UserAgent != c.Request.UserAgent() ||
+		challenge.ClientIp != c.ClientIP() {
+		abortWithError(c, http.StatusUnauthorized, codeTwoFactorNotVerified, "error.two_factor_challenge_not_verified")
+		return
+	}
+
+	user, err := server.store.GetUserByID(c, challenge.UserID)
+	if err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to GetUserByID: %w", err))
+		return
+	}
+	setUserLocale(c, user.Locale)
+	// チャレンジの発行後に無効化された場合。
+	if user.DisabledAt.Valid {
+		server.metrics.LoginFailed(metrics.LoginFailureAccountDisabled)
+		abortWithError(c, http.StatusForbidden, codeAccountDisabled, "error.account_disabled")
+		return
+	}
+
+	// コードを確かめる前に試行回数を消費する。
+	// 並行したリクエストでも、1つのチャレンジで maxTwoFactorAttempts 回までしか試せない。
+	attempt, err := server.store.UseTwoFactorChallengeAttempt(c, db.UseTwoFactorChallengeAttemptParams{
+		ID:          challenge.ID,
+		MaxAttempts: maxTwoFactorAttempts,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			requestLogger(c).Warnf("two-factor attempts exceeded for user [%d]", user.ID)
+			server.metrics.LoginFailed(metrics.LoginFailureTwoFactorAttemptsExceeded)
+			abortWithError(c, http.StatusUnauthorized, codeTwoFactorNotVerified, "error.two_factor_attempts_exceeded")
+			return
+		}
+		abortWithInternalError(c, fmt.Errorf("failed to UseTwoFactorChallengeAttempt: %w", err))
+		return
+	}
+
+	if req.Code != "" {
+		secret, err := server.decryptTOTPSecret(user)
+		if err != nil {
+			abortWithInternalError(c, err)
+			return
+		}
+		counter, ok := auth.MatchTOTPCode(secret, req.Code, time.Now())
+		if !ok {
+			requestLogger(c).Warnf("invalid two-factor code for user [%d]", user.ID)
+			server.failTwoFactorAttempt(c, attempt, metrics.LoginFailureInvalidTwoFactor, codeInvalidTwoFactorCode, "error.invalid_two_factor_code")
+			return
+		}
+		// 時刻ずれを許容しているため、同じコードは前後のステップの間も有効になる。
+		// 受け付け済みのステップ以前のコードは、盗み見たコードの再利用とみなして拒否する。
+		n, err := server.store.UpdateUserTOTPLastCounter(c, db.UpdateUserTOTPLastCounterParams{
+			ID:      user.ID,
+			Counter: counter,
+		})
+		if err != nil {
+			abortWithInternalError(c, fmt.Errorf("failed to UpdateUserTOTPLastCounter: %w", err))
+			return
+		}
+		if n == 0 {
+			requestLogger(c).Warnf("reused two-factor code for user [%d]", user.ID)
+			server.failTwoFactorAttempt(c, attempt, metrics.LoginFailureInvalidTwoFactor, codeInvalidTwoFactorCode, "error.invalid_two_factor_code")
+			return
+		}
+	} else {
+		arg := db.UseRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: util.HashToken(auth.NormalizeRecoveryCode(req.RecoveryCode)),
+		}
+		if _, err := server.store.UseRecoveryCode(c, arg); err != nil {
+			if err == sql.ErrNoRows {
+				requestLogger(c).Warnf("invalid recovery code for user [%d]", user.ID)
+				server.failTwoFactorAttempt(c, attempt, metrics.LoginFailureInvalidRecoveryCode, codeInvalidRecoveryCode, "error.invalid_recovery_code")
+				return
+			}
+			abortWithInternalError(c, fmt.Errorf("failed to UseRecoveryCode: %w", err))
+			return
+		}
+	}
+
+	// チャレンジは一度しか使えないようにする。
+	if err := server.store.DeleteTwoFactorChallenge(c, challenge.ID); err != nil {
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteTwoFactorChallenge: %w", err))
+		return
+	}
+
+	if err := server.issueSession(c, user.ID); err != nil {
+		abortWithInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// 2段階認証のコードが誤っていた場合の401を返す。
+// 試行回数が上限に達したチャレンジは、以降使えないよう削除する。
+func (server *Server) failTwoFactorAttempt(c *gin.Context, challenge db.TwoFactorChallenge, reason string, code string, key string) {
+	server.metrics.LoginFailed(reason)
+	if challenge.Attempts >= maxTwoFactorAttempts {
+		if err := server.store.DeleteTwoFactorChallenge(c, challenge.ID); err != nil {
+			abortWithInternalError(c, fmt.Errorf("failed to DeleteTwoFactorChallenge: %w", err))
+			return
+		}
+	}
+	abortWithError(c, http.StatusUnauthorized, code, key)
+}
+
+// DBに暗号化して保存しているTOTPの秘密鍵を復号する。
+func (server *Server) decryptTOTPSecret(user db.User) (string, error) {
+	key, err := util.DecodeEncryptionKey(server.config.TOTPEncryptionKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to DecodeEncryptionKey: %w", err)
+	}
+	secret, err := util.Decrypt(key, user.TotpSecret.String)
+	if err != nil {
+		return "", fmt.Errorf("failed to Decrypt: %w", err)
+	}
+	return secret, nil
+}
+
+// 既存のリカバリーコードを破棄し、新しいリカバリーコードを発行する。
+// DBにはハッシュ化した値のみを保存する。
+func (server *Server) resetRecoveryCodes(c *gin.Context, userID int64) ([]string, error) {
+	if err := server.store.DeleteRecoveryCodes(c, userID); err != nil {
+		return nil, fmt.Errorf("failed to DeleteRecoveryCodes: %w", err)
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GenerateRecoveryCodes: %w", err)
+	}
+	for _, code := range codes {
+		arg := db.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: util.HashToken(code),
+		}
+		if _, err := server.store.CreateRecoveryCode(c, arg); err != nil {
+			return nil, fmt.Errorf("failed to CreateRecoveryCode: %w", err)
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,653 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/auth"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// テスト用のTOTPの暗号化鍵。
+var testTOTPEncryptionKey = hex.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+// 2段階認証の設定途中（もしくは設定済み）のユーザーを作成する。
+func randomTwoFactorUser(t *testing.T, enabled bool) (db.User, string) {
+	secret, err := auth.GenerateTOTPSecret()
+	require.NoError(t, err)
+	key, err := util.DecodeEncryptionKey(testTOTPEncryptionKey)
+	require.NoError(t, err)
+	encrypted, err := util.Encrypt(key, secret)
+	require.NoError(t, err)
+
+	user := db.User{
+		ID:          util.RandomID(),
+		Name:        util.RandomUserName(),
+		Email:       util.RandomEmail(),
+		TotpSecret:  sql.NullString{String: encrypted, Valid: true},
+		TotpEnabled: enabled,
+	}
+	return user, secret
+}
+
+func TestSetupTwoFactor(t *testing.T) {
+	user := db.User{
+		ID:    util.RandomID(),
+		Name:  util.RandomUserName(),
+		Email: util.RandomEmail(),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateUserTOTPSecretParams) (db.User, error) {
+						// 秘密鍵は暗号化して保存されること。
+						require.True(t, arg.TotpSecret.Valid)
+						require.Equal(t, user.ID, arg.ID)
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp setupTwoFactorResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.NotEmpty(t, rsp.Secret)
+				require.Contains(t, rsp.OtpauthURI, "otpauth://totp/")
+				require.Contains(t, rsp.OtpauthURI, rsp.Secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockdb.MockStore) {
+				enabled := user
+				enabled.TotpEnabled = true
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UpdateUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DBErrorWhenUpdateUserTOTPSecret",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+
+			config := newTestConfig()
+			server := NewServer(config, store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/2fa/setup", nil)
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	user, secret := randomTwoFactorUser(t, false)
+	now := time.Now()
+	code, err := auth.GenerateTOTPCode(secret, now)
+	require.NoError(t, err)
+	counter, ok := auth.MatchTOTPCode(secret, code, now)
+	require.True(t, ok)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				// 有効化に使ったコードのステップを記録すること。
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Eq(db.UpdateUserTOTPLastCounterParams{
+						ID:      user.ID,
+						Counter: counter,
+					})).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					EnableUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteRecoveryCodes(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(auth.RecoveryCodeCount).
+					Return(db.RecoveryCode{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var rsp verifyTwoFactorResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Len(t, rsp.RecoveryCodes, auth.RecoveryCodeCount)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"code": "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			// 受け付け済みのステップ以前のコードは拒否する。
+			name: "ReusedCode",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					EnableUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				checkBodyContains(t, recorder, codeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "DBErrorWhenUpdateUserTOTPLastCounter",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+				store.EXPECT().
+					EnableUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "SetupNotStarted",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{ID: user.ID}, nil)
+				store.EXPECT().
+					EnableUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BindRequestErrorWithValidationError",
+			body: gin.H{"code": "abc"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// authのmiddlewareを通すため。
+			store.EXPECT().
+				UpdateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			manager := auth.NewMockManager(store)
+			manager.UserID = user.ID
+
+			config := newTestConfig()
+			server := NewServer(config, store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/2fa/verify", bytes.NewReader(data))
+			require.NoError(t, err)
+			addCompleteAuth(t, request, manager)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	user, secret := randomTwoFactorUser(t, true)
+	now := time.Now()
+	code, err := auth.GenerateTOTPCode(secret, now)
+	require.NoError(t, err)
+	// TOTP の1ステップは30秒。
+	counter := now.Unix() / 30
+
+	// httptestのリクエストは UserAgent と ClientIP が空になる。
+	challenge := db.TwoFactorChallenge{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(twoFactorChallengeDuration),
+	}
+	attempted := challenge
+	attempted.Attempts = 1
+	attemptArg := db.UseTwoFactorChallengeAttemptParams{ID: challenge.ID, MaxAttempts: maxTwoFactorAttempts}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"challenge_id": challenge.ID, "code": code},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(attempted, nil)
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Eq(db.UpdateUserTOTPLastCounterParams{ID: user.ID, Counter: counter})).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					DeleteTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(nil)
+				addAuthMock(*manager, store, user.ID)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Set-Cookie"))
+				checkResponseBody(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "OKWithRecoveryCode",
+			body: gin.H{"challenge_id": challenge.ID, "recovery_code": "ABCDE-FGHJK"},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(attempted, nil)
+				arg := db.UseRecoveryCodeParams{
+					UserID:   user.ID,
+					CodeHash: util.HashToken("abcde-fghjk"),
+				}
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.RecoveryCode{}, nil)
+				store.EXPECT().
+					DeleteTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(nil)
+				addAuthMock(*manager, store, user.ID)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"challenge_id": challenge.ID, "code": "000000"},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(attempted, nil)
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Any()).
+					Times(0)
+				// 上限に達するまでは、チャレンジを残す。
+				store.EXPECT().
+					DeleteTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Set-Cookie"))
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: gin.H{"challenge_id": challenge.ID, "recovery_code": "abcde-fghjk"},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(attempted, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ReusedCode",
+			body: gin.H{"challenge_id": challenge.ID, "code": code},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(attempted, nil)
+				// 同じステップのコードをすでに受け付けている。
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Eq(db.UpdateUserTOTPLastCounterParams{ID: user.ID, Counter: counter})).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					DeleteTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Set-Cookie"))
+				checkBodyContains(t, recorder, codeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "InvalidCodeOnLastAttempt",
+			body: gin.H{"challenge_id": challenge.ID, "code": "000000"},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				last := challenge
+				last.Attempts = maxTwoFactorAttempts
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(last, nil)
+				// 上限に達したチャレンジは削除する。
+				store.EXPECT().
+					DeleteTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				checkBodyContains(t, recorder, codeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "AttemptsExceeded",
+			body: gin.H{"challenge_id": challenge.ID, "code": code},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseTwoFactorChallengeAttempt(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(db.TwoFactorChallenge{}, sql.ErrNoRows)
+				// 正しいコードでも確かめない。
+				store.EXPECT().
+					UpdateUserTOTPLastCounter(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				checkBodyContains(t, recorder, codeTwoFactorNotVerified)
+			},
+		},
+		{
+			name: "ExpiredChallenge",
+			body: gin.H{"challenge_id": challenge.ID, "code": code},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				expired := challenge
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeNotFound",
+			body: gin.H{"challenge_id": challenge.ID, "code": code},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(db.TwoFactorChallenge{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BindRequestErrorWithMissingParam",
+			body: gin.H{"challenge_id": challenge.ID},
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
+					GetTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			manager := auth.NewMockManager(store)
+			tc.buildStubs(store, manager)
+
+			config := newTestConfig()
+			server := NewServer(config, store, manager, util.InitLogger(), newTestMetrics())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/login/2fa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			// Act
+			server.router.ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
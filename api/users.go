@@ -3,8 +3,6 @@ package api
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,29 +10,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/metrics"
 	"github.com/kokoichi206/account-book-api/util"
-	"go.uber.org/zap"
 )
 
 // 新規ユーザー作成用のpayload。
 type createUserRequest struct {
 	Name     string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
-	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6" log:"secret"`
+	Email    string `json:"email" binding:"required,email" log:"email"`
 	Age      int32  `json:"age"`
-	Balance  int64  `json:"balance"`
-}
-
-// Log用にパスワードをマスクした構造体をJSONの文字列で取得する。
-func (request createUserRequest) MustMasedJSONString() string {
-	type credentials createUserRequest
-	cr := credentials(request)
-	cr.Password = "[SECRET]"
-	bytes, err := json.Marshal((*credentials)(&cr))
-	if err != nil {
-		return ""
-	}
-	return string(bytes)
+	Balance  int64  `json:"balance" log:"secret"`
 }
 
 // 新規ユーザー作成用のpayload。
@@ -46,65 +32,74 @@ type userResponse struct {
 	Balance           int64     `json:"balance"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	TwoFactorEnabled  bool      `json:"two_factor_enabled"`
+	Role              string    `json:"role"`
+	PreferredCurrency string    `json:"preferred_currency"`
+	Locale            string    `json:"locale"`
+	Timezone          string    `json:"timezone"`
+	// アカウントの削除を予約している場合のみ、削除予定日時を返す。
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
-// ログイン用のpayload。
-type loginUserRequest struct {
-	Password string `json:"password" binding:"required,min=6"`
-	Email    string `json:"email" binding:"required,email"`
+// DBのユーザーから、パスワードなどを除いたレスポンス用の構造体を作成する。
+func newUserResponse(user db.User) userResponse {
+	rsp := userResponse{
+		Id:                user.ID,
+		Name:              user.Name,
+		Email:             user.Email,
+		Age:               user.Age,
+		Balance:           user.Balance,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		TwoFactorEnabled:  user.TotpEnabled,
+		Role:              user.Role,
+		PreferredCurrency: user.PreferredCurrency,
+		Locale:            user.Locale,
+		Timezone:          user.Timezone,
+	}
+	if user.DeletionScheduledAt.Valid {
+		rsp.DeletionScheduledAt = &user.DeletionScheduledAt.Time
+	}
+	return rsp
 }
 
-// Log用にパスワードをマスクした構造体をJSONの文字列で取得する。
-func (request loginUserRequest) MustMasedJSONString() string {
-	type credentials loginUserRequest
-	cr := credentials(request)
-	cr.Password = "[SECRET]"
-	bytes, err := json.Marshal((*credentials)(&cr))
-	if err != nil {
-		return ""
-	}
-	return string(bytes)
+// ログイン用のpayload。
+type loginUserRequest struct {
+	Password string `json:"password" binding:"required,min=6" log:"secret"`
+	Email    string `json:"email" binding:"required,email" log:"email"`
 }
 
 // 新規ユーザー作成のエンドポイント。
 func (server *Server) createUser(c *gin.Context) {
 	var req createUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, err)
+		abortWithBindError(c, err)
 		return
 	}
 
 	// MAYBE: これはDebugかInfoか。
-	zap.S().Debug(req.MustMasedJSONString())
+	requestLogger(c).Debugw("request", "body", util.Redact(req))
 
 	// Emailが登録されているかチェックする。
-	_, err := server.querier.GetUser(c, req.Email)
+	_, err := server.store.GetUser(c, req.Email)
 	if err != sql.ErrNoRows {
 		// エラーなし↔︎すでにEmailは登録済み
 		if err == nil {
-			message := fmt.Sprintf("The Email [%s] has already registered.", req.Email)
-			zap.S().Warn(message)
-			c.JSON(http.StatusBadRequest, errorResponse(errors.New("The Email has already registered.")))
+			message := fmt.Sprintf("The Email [%s] has already registered.", util.MaskEmail(req.Email))
+			requestLogger(c).Warn(message)
+			abortWithError(c, http.StatusBadRequest, codeEmailAlreadyRegistered, "error.email_already_registered")
 			return
 		}
 		// それ以外は、DBに何かしらの不備がある。
 		// dbのコードが自動生成されるためここで情報を付与する（本来はdbパッケージで行うべき）。
-		err = fmt.Errorf("failed to GetUser: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+		abortWithInternalError(c, fmt.Errorf("failed to GetUser: %w", err))
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		// パスワードのハッシュ化ができず先に進まないのは致命的。
-		err = fmt.Errorf("failed to util.HashPassword: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+		abortWithInternalError(c, fmt.Errorf("failed to util.HashPassword: %w", err))
 		return
 	}
 	arg := db.CreateUserParams{
@@ -115,56 +110,19 @@ func (server *Server) createUser(c *gin.Context) {
 		Balance:  req.Balance,
 	}
 
-	user, err := server.querier.CreateUser(c, arg)
+	user, err := server.store.CreateUser(c, arg)
 	if err != nil {
-		err = fmt.Errorf("failed to CreateUser: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+		abortWithInternalError(c, fmt.Errorf("failed to CreateUser: %w", err))
 		return
 	}
 
 	// セッションを発行し、Cookieにセットする。
-	id, err := server.sessionManager.CreateSession()
-	if err != nil {
-		err = fmt.Errorf("failed to sessionManager.CreateSession: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
-		return
-	}
-	sarg := db.CreateSessionParams{
-		ID:        id,
-		UserID:    user.ID,
-		UserAgent: c.Request.UserAgent(),
-		ClientIp:  c.ClientIP(),
-		ExpiresAt: time.Now().Add(server.config.SessionDuration),
-	}
-	session, err := server.querier.CreateSession(context.Background(), sarg)
-	if err != nil {
-		// DBに何かしらの不備がある。
-		err = fmt.Errorf("failed to querier.CreateSession: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+	if err := server.issueSession(c, user.ID); err != nil {
+		abortWithInternalError(c, err)
 		return
 	}
-	maxAge := int(server.config.SessionDuration.Seconds())
-	domain := server.config.ServerAddress
-	c.SetCookie(cookieName, session.ID.String(), maxAge, "/", domain, true, true)
 
-	res := userResponse{
-		Id:                user.ID,
-		Name:              user.Name,
-		Email:             user.Email,
-		Age:               user.Age,
-		Balance:           user.Balance,
-		PasswordChangedAt: user.PasswordChangedAt,
-		CreatedAt:         user.CreatedAt,
-	}
+	res := newUserResponse(user)
 	c.JSON(http.StatusCreated, res)
 }
 
@@ -172,117 +130,120 @@ func (server *Server) createUser(c *gin.Context) {
 func (server *Server) loginUser(c *gin.Context) {
 	var req loginUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, err)
+		abortWithBindError(c, err)
 		return
 	}
 
 	// MAYBE: これはDebugかInfoか。
-	zap.S().Debug(req.MustMasedJSONString())
+	requestLogger(c).Debugw("request", "body", util.Redact(req))
 
 	// Emailが登録されているかチェックする。
-	user, err := server.querier.GetUser(c, req.Email)
+	user, err := server.store.GetUser(c, req.Email)
 	if err != nil {
 		// 登録されていなければ、ユーザーのリクエストに不備がある。
 		if err == sql.ErrNoRows {
-			message := fmt.Sprintf("The Email [%s] has not registered yet.", req.Email)
-			zap.S().Warn(message)
-			c.JSON(http.StatusBadRequest, err)
+			message := fmt.Sprintf("The Email [%s] has not registered yet.", util.MaskEmail(req.Email))
+			requestLogger(c).Warn(message)
+			server.metrics.LoginFailed(metrics.LoginFailureUnknownUser)
+			// Emailの登録有無を推測させないよう、パスワードの誤りと区別しない。
+			abortWithError(c, http.StatusBadRequest, codeInvalidCredentials, "error.invalid_credentials")
 			return
 		}
 		// それ以外は、DBに何かしらの不備がある。
-		err = fmt.Errorf("failed to GetUser: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
+		abortWithInternalError(c, fmt.Errorf("failed to GetUser: %w", err))
 		return
 	}
+	setUserLocale(c, user.Locale)
 
 	// パスワードをチェックする。
 	if err := util.CheckPassword(req.Password, user.Password); err != nil {
-		zap.S().Error(err)
+		requestLogger(c).Warnf("invalid password for user [%d]", user.ID)
+		server.metrics.LoginFailed(metrics.LoginFailureWrongPassword)
+		abortWithError(c, http.StatusBadRequest, codeInvalidCredentials, "error.invalid_credentials")
+		return
+	}
+
+	// 管理者に無効化されたアカウントではログインさせない。
+	if user.DisabledAt.Valid {
+		requestLogger(c).Warnf("disabled user [%d] tried to login", user.ID)
+		server.metrics.LoginFailed(metrics.LoginFailureAccountDisabled)
+		abortWithError(c, http.StatusForbidden, codeAccountDisabled, "error.account_disabled")
+		return
+	}
 
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, err)
+	// 2段階認証が有効な場合は、セッションを発行せずにチャレンジを返す。
+	if user.TotpEnabled {
+		server.startTwoFactorChallenge(c, user)
 		return
 	}
 
 	// セッションを発行し、Cookieにセットする。
+	if err := server.issueSession(c, user.ID); err != nil {
+		abortWithInternalError(c, err)
+		return
+	}
+
+	res := newUserResponse(user)
+	c.JSON(http.StatusOK, res)
+}
+
+// セッションを発行し、Cookieにセットする。
+func (server *Server) issueSession(c *gin.Context, userID int64) error {
 	id, err := server.sessionManager.CreateSession()
 	if err != nil {
-		err = fmt.Errorf("failed to sessionManager.CreateSession: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
-		return
+		return fmt.Errorf("failed to sessionManager.CreateSession: %w", err)
 	}
-	sarg := db.CreateSessionParams{
+	arg := db.CreateSessionParams{
 		ID:        id,
-		UserID:    user.ID,
+		UserID:    userID,
 		UserAgent: c.Request.UserAgent(),
 		ClientIp:  c.ClientIP(),
 		ExpiresAt: time.Now().Add(server.config.SessionDuration),
 	}
-	session, err := server.querier.CreateSession(context.Background(), sarg)
+	session, err := server.store.CreateSession(context.Background(), arg)
 	if err != nil {
 		// DBに何かしらの不備がある。
-		err = fmt.Errorf("failed to querier.CreateSession: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, err)
-		return
+		return fmt.Errorf("failed to querier.CreateSession: %w", err)
 	}
 	maxAge := int(server.config.SessionDuration.Seconds())
-	domain := server.config.ServerAddress
-	c.SetCookie(cookieName, session.ID.String(), maxAge, "/", domain, true, true)
-
-	res := userResponse{
-		Id:                user.ID,
-		Name:              user.Name,
-		Email:             user.Email,
-		Age:               user.Age,
-		Balance:           user.Balance,
-		PasswordChangedAt: user.PasswordChangedAt,
-		CreatedAt:         user.CreatedAt,
+	server.setSessionCookie(c, session.ID.String(), maxAge)
+	// Cookieによる認証を使う以上、CSRFトークンも合わせて発行する。
+	if err := server.setCSRFCookie(c, maxAge); err != nil {
+		return fmt.Errorf("failed to setCSRFCookie: %w", err)
 	}
-	c.JSON(http.StatusOK, res)
+
+	return nil
 }
 
 // ログアウト用のエンドポイント。
 func (server *Server) logout(c *gin.Context) {
 
 	// authを通してるので、基本的に前半でこけることはない。
-	sessionString, err := c.Cookie(cookieName)
+	sessionString, err := server.sessionCookie(c)
 	// Cookieから値が取得できない場合。
 	if err != nil {
 		message := fmt.Errorf("could not find cookie: %w", err)
-		zap.S().Warn(message)
-		c.JSON(http.StatusUnauthorized, errorResponse(err))
+		requestLogger(c).Warn(message)
+		abortWithError(c, http.StatusUnauthorized, codeUnauthenticated, "error.cannot_find_cookie")
 		return
 	}
 	sessionID, err := uuid.Parse(sessionString)
 	// 取得したCookieが、uuidの形式になってない場合。
 	if err != nil {
 		message := fmt.Sprintf("could not convert session [%s] to uuid.", sessionID)
-		zap.S().Warn(message)
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		requestLogger(c).Warn(message)
+		abortWithError(c, http.StatusBadRequest, codeInvalidRequest, "error.wrong_cookie_value")
 		return
 	}
 
-	err = server.querier.DeleteSession(context.Background(), sessionID)
+	err = server.store.DeleteSession(context.Background(), sessionID)
 	if err != nil {
-		err = fmt.Errorf("failed to DeleteSession: %w", err)
-		zap.S().Error(err)
-
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithInternalError(c, fmt.Errorf("failed to DeleteSession: %w", err))
 		return
 	}
-	domain := server.config.ServerAddress
 	// Cookieの有効期限を負の値にし、論理的に削除にする。
-	c.SetCookie(cookieName, sessionID.String(), -1, "/", domain, true, true)
+	server.setSessionCookie(c, sessionID.String(), -1)
+	server.setCookie(c, csrfCookieName, "", -1, false)
 
 	c.Status(http.StatusOK)
 }
@@ -9,7 +9,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 	"time"
 
@@ -23,15 +22,15 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestMustMasedJSONString(t *testing.T) {
+func TestRedactRequests(t *testing.T) {
 
 	testCases := []struct {
 		name     string
-		input    createUserRequest
+		input    interface{}
 		expected string
 	}{
 		{
-			name: "OK",
+			name: "CreateUser",
 			input: createUserRequest{
 				Name:     "John Doe",
 				Password: "password",
@@ -39,8 +38,36 @@ func TestMustMasedJSONString(t *testing.T) {
 				Age:      123,
 				Balance:  13579000,
 			},
-			// [Password: "password"] がマスクされていること（SECRETになっていること）が期待値。
-			expected: "{\"username\":\"John Doe\",\"password\":\"[SECRET]\",\"email\":\"this.is.test@example.com\",\"age\":123,\"balance\":13579000}",
+			// パスワードと残高が [SECRET] に、メールアドレスのローカル部が伏せられていることが期待値。
+			expected: "{\"username\":\"John Doe\",\"password\":\"[SECRET]\",\"email\":\"t***@example.com\",\"age\":123,\"balance\":\"[SECRET]\"}",
+		},
+		{
+			name: "LoginUser",
+			input: loginUserRequest{
+				Password: "password",
+				Email:    "this.is.test@example.com",
+			},
+			expected: "{\"password\":\"[SECRET]\",\"email\":\"t***@example.com\"}",
+		},
+		{
+			name: "CreateExpense",
+			input: createExpenseRequest{
+				UserID:     1,
+				CategoryID: 2,
+				Amount:     1200,
+				Comment:    "dinner with a friend",
+			},
+			expected: "{\"user_id\":1,\"category_id\":2,\"amount\":\"[SECRET]\",\"comment\":\"[SECRET]\"}",
+		},
+		{
+			name: "CreateReceipt",
+			input: createReceiptRequest{
+				StoreName:    "store",
+				FoodContents: []foodContent{{Name: "apple", Price: 100}},
+				TotalPrice:   100,
+			},
+			// 入れ子の構造体の値も伏せられること。
+			expected: "{\"store_name\":\"store\",\"food_contents\":[{\"name\":\"apple\",\"price\":\"[SECRET]\"}],\"total_price\":\"[SECRET]\"}",
 		},
 	}
 
@@ -51,20 +78,20 @@ func TestMustMasedJSONString(t *testing.T) {
 			// Arrange
 
 			// Act
-			expected := tc.input.MustMasedJSONString()
+			actual := util.RedactedJSONString(tc.input)
 
 			// Assert
-			require.Equal(t, expected, tc.expected)
+			require.Equal(t, tc.expected, actual)
 		})
 	}
 }
 
-func addAuthMock(manager auth.MockUuidSessionManager, querier *mockdb.MockQuerier, userID int64) {
+func addAuthMock(manager auth.MockUuidSessionManager, store *mockdb.MockStore, userID int64) {
 	uuid := uuid.New()
 	manager.Uuid = uuid
 	manager.CreateUUIDError = nil
 
-	querier.EXPECT().
+	store.EXPECT().
 		CreateSession(gomock.Any(), gomock.Any()).
 		Times(1).
 		Return(db.Session{
@@ -108,24 +135,24 @@ func TestCreateUser(t *testing.T) {
 	testCases := []struct {
 		name          string
 		body          gin.H
-		buildStubs    func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager)
+		buildStubs    func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
 
-				addAuthMock(*manager, querier, correctUser.ID)
+				addAuthMock(*manager, store, correctUser.ID)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusCreated, recorder.Code)
@@ -140,8 +167,8 @@ func TestCreateUser(t *testing.T) {
 				"age":      age,
 				"balance":  balance,
 			},
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -158,8 +185,8 @@ func TestCreateUser(t *testing.T) {
 				"age":      age,
 				"balance":  balance,
 			},
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(0)
 
@@ -171,13 +198,13 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "AlreadyRegisteredEmailError",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, nil)
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -188,13 +215,13 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "DBErrorWhenGetUser",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -205,13 +232,13 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "DBErrorWhenCreateUser",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
@@ -223,20 +250,20 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "SessionManagerError",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
 
 				manager.CreateUUIDError = errors.New("session manager error")
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -247,13 +274,13 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "DBErrorWhenCreateSession",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
@@ -262,7 +289,7 @@ func TestCreateUser(t *testing.T) {
 				manager.Uuid = uuid
 				manager.CreateUUIDError = nil
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Session{}, errors.New("session manager error"))
@@ -281,11 +308,11 @@ func TestCreateUser(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			querier := mockdb.NewMockQuerier(ctrl)
-			manager := auth.NewMockManager(querier)
-			tc.buildStubs(querier, manager)
+			store := mockdb.NewMockStore(ctrl)
+			manager := auth.NewMockManager(store)
+			tc.buildStubs(store, manager)
 
-			server := NewServer(util.Config{}, querier, manager, util.InitLogger())
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
 			recorder := httptest.NewRecorder()
 			url := "/users"
 
@@ -330,31 +357,77 @@ func TestLoginUser(t *testing.T) {
 	testCases := []struct {
 		name          string
 		body          gin.H
-		buildStubs    func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager)
+		buildStubs    func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
-				addAuthMock(*manager, querier, correctUser.ID)
+				addAuthMock(*manager, store, correctUser.ID)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				checkResponseBody(t, recorder.Body, correctUser)
 			},
 		},
+		{
+			name: "TwoFactorRequired",
+			body: correctBody,
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				user := correctUser
+				user.TotpEnabled = true
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateTwoFactorChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+						return db.TwoFactorChallenge{ID: arg.ID, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				// 2段階認証が終わるまでセッションは発行しない。
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Set-Cookie"))
+				checkBodyContains(t, recorder, "challenge_id")
+			},
+		},
+		{
+			name: "DisabledUser",
+			body: correctBody,
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				user := correctUser
+				user.DisabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				checkError(t, codeAccountDisabled, recorder.Body)
+			},
+		},
 		{
 			name: "BindRequestErrorWithMissingParam",
 			body: gin.H{
 				"password": password,
 			},
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -365,8 +438,8 @@ func TestLoginUser(t *testing.T) {
 		{
 			name: "DBErrorWithNotRegistered",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
@@ -378,8 +451,8 @@ func TestLoginUser(t *testing.T) {
 		{
 			name: "DBError",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
@@ -394,8 +467,8 @@ func TestLoginUser(t *testing.T) {
 				"password": "wrong_password",
 				"email":    email,
 			},
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
@@ -407,15 +480,15 @@ func TestLoginUser(t *testing.T) {
 		{
 			name: "SessionManagerError",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
 
 				manager.CreateUUIDError = errors.New("session manager error")
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
@@ -426,8 +499,8 @@ func TestLoginUser(t *testing.T) {
 		{
 			name: "CreateSessionDBError",
 			body: correctBody,
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(correctUser, nil)
@@ -436,7 +509,7 @@ func TestLoginUser(t *testing.T) {
 				manager.Uuid = uuid
 				manager.CreateUUIDError = nil
 
-				querier.EXPECT().
+				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Session{}, errors.New("session manager error"))
@@ -455,11 +528,11 @@ func TestLoginUser(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			querier := mockdb.NewMockQuerier(ctrl)
-			manager := auth.NewMockManager(querier)
-			tc.buildStubs(querier, manager)
+			store := mockdb.NewMockStore(ctrl)
+			manager := auth.NewMockManager(store)
+			tc.buildStubs(store, manager)
 
-			server := NewServer(util.Config{}, querier, manager, util.InitLogger())
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
 			recorder := httptest.NewRecorder()
 			url := "/login"
 
@@ -496,13 +569,13 @@ func TestLogoutUser(t *testing.T) {
 
 	testCases := []struct {
 		name          string
-		buildStubs    func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager)
+		buildStubs    func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, manager *auth.MockUuidSessionManager)
 	}{
 		{
 			name: "OK",
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					DeleteSession(gomock.Any(), manager.Uuid).
 					Times(1).
 					Return(nil)
@@ -515,16 +588,17 @@ func TestLogoutUser(t *testing.T) {
 		},
 		{
 			name: "DBErrorWhenDeleteSession",
-			buildStubs: func(querier *mockdb.MockQuerier, manager *auth.MockUuidSessionManager) {
-				querier.EXPECT().
+			buildStubs: func(store *mockdb.MockStore, manager *auth.MockUuidSessionManager) {
+				store.EXPECT().
 					DeleteSession(gomock.Any(), manager.Uuid).
 					Times(1).
 					Return(sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, manager *auth.MockUuidSessionManager) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
-				// ResponseBodyにエラーメッセージが乗っていること。
-				checkError(t, sql.ErrConnDone.Error(), recorder.Body)
+				// DBのエラーメッセージはクライアントに返さないこと。
+				body := checkError(t, codeInternal, recorder.Body)
+				require.NotContains(t, body.Error.Message, sql.ErrConnDone.Error())
 			},
 		},
 	}
@@ -537,17 +611,17 @@ func TestLogoutUser(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			querier := mockdb.NewMockQuerier(ctrl)
-			manager := auth.NewMockManager(querier)
+			store := mockdb.NewMockStore(ctrl)
+			manager := auth.NewMockManager(store)
 
 			session := uuid.New()
 			manager.Verify = true
 			manager.VerifyError = nil
 			manager.Uuid = session
 
-			tc.buildStubs(querier, manager)
+			tc.buildStubs(store, manager)
 
-			server := NewServer(util.Config{}, querier, manager, util.InitLogger())
+			server := NewServer(newTestConfig(), store, manager, util.InitLogger(), newTestMetrics())
 			recorder := httptest.NewRecorder()
 			url := "/logout"
 
@@ -566,19 +640,18 @@ func TestLogoutUser(t *testing.T) {
 	}
 }
 
-func checkError(t *testing.T, errString string, responseBody *bytes.Buffer) {
+// 共通の形式でエラーが返され、指定のエラーコードであることを確認。
+func checkError(t *testing.T, code string, responseBody *bytes.Buffer) errorResponse {
 	data, err := ioutil.ReadAll(responseBody)
 	require.NoError(t, err)
 
-	type errorMsg struct {
-		Error string `json:"error"`
-	}
-
-	var body errorMsg
+	var body errorResponse
 	err = json.Unmarshal(data, &body)
 	require.NoError(t, err)
-	require.NotNil(t, body.Error)
-	require.True(t, strings.Contains(body.Error, errString))
+	require.Equal(t, code, body.Error.Code)
+	require.NotEmpty(t, body.Error.Message)
+	require.NotEmpty(t, body.Error.RequestID)
+	return body
 }
 
 // Cookieの削除指示が正しく行われているか確認。
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// APIキーに付与できる権限。
+const (
+	ScopeReadExpenses  = "read:expenses"
+	ScopeWriteExpenses = "write:expenses"
+	ScopeReadReceipts  = "read:receipts"
+	ScopeWriteReceipts = "write:receipts"
+)
+
+const (
+	// 発行するAPIキーに付ける接頭辞。
+	// 漏洩時にシークレットスキャナ等で検出しやすくするため。
+	apiKeyPrefix = "abk_"
+	// APIキーのランダム部分のバイト数。
+	apiKeySize = 32
+	// 一覧表示用に保存するAPIキーの先頭の文字数。
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+)
+
+// APIキーに付与できる権限の一覧。
+var Scopes = []string{
+	ScopeReadExpenses,
+	ScopeWriteExpenses,
+	ScopeReadReceipts,
+	ScopeWriteReceipts,
+}
+
+// 新しいAPIキーを生成する。
+// キー本体と、一覧表示用の先頭部分を返す。
+func GenerateAPIKey() (key string, displayPrefix string, err error) {
+	b := make([]byte, apiKeySize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyDisplayLength], nil
+}
+
+// 権限の一覧に指定の権限が含まれているか確かめる。
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	// Act
+	key, prefix, err := GenerateAPIKey()
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, apiKeyPrefix))
+	require.True(t, strings.HasPrefix(key, prefix))
+	require.Len(t, prefix, apiKeyDisplayLength)
+
+	other, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
+
+func TestHasScope(t *testing.T) {
+	scopes := []string{ScopeReadExpenses, ScopeWriteReceipts}
+
+	require.True(t, HasScope(scopes, ScopeReadExpenses))
+	require.True(t, HasScope(scopes, ScopeWriteReceipts))
+	require.False(t, HasScope(scopes, ScopeWriteExpenses))
+	require.False(t, HasScope(nil, ScopeReadExpenses))
+}
@@ -8,6 +8,8 @@ import (
 type MockUuidSessionManager struct {
 	Uuid            uuid.UUID
 	CreateUUIDError error
+	UserID          int64
+	Locale          string
 	Verify          bool
 	VerifyError     error
 	VerifyArg       VerifySessionParams
@@ -23,7 +25,7 @@ func (m *MockUuidSessionManager) CreateSession() (uuid.UUID, error) {
 	return m.Uuid, m.CreateUUIDError
 }
 
-func (m *MockUuidSessionManager) VerifySession(arg VerifySessionParams) (bool, error) {
+func (m *MockUuidSessionManager) VerifySession(arg VerifySessionParams) (VerifiedSession, bool, error) {
 	m.VerifyArg = arg
-	return m.Verify, m.VerifyError
+	return VerifiedSession{UserID: m.UserID, Locale: m.Locale}, m.Verify, m.VerifyError
 }
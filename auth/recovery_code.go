@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	// 一度に発行するリカバリーコードの数。
+	RecoveryCodeCount = 10
+	// 読み間違えやすい文字（0, o, 1, l など）を除いた文字集合。
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	// リカバリーコード１つあたりの文字数（区切りの '-' を除く）。
+	recoveryCodeLength = 10
+)
+
+// ワンタイムのリカバリーコードを n 個生成する。
+// xxxxx-xxxxx の形式の文字列を返す。
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < n; i++ {
+		var s strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				s.WriteByte('-')
+			}
+			idx, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, err
+			}
+			s.WriteByte(recoveryCodeAlphabet[idx.Int64()])
+		}
+		codes = append(codes, s.String())
+	}
+	return codes, nil
+}
+
+// ユーザーが入力したリカバリーコードを保存時と同じ形式に揃える。
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) == recoveryCodeLength && !strings.Contains(code, "-") {
+		code = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+	}
+	return code
+}
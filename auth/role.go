@@ -0,0 +1,18 @@
+package auth
+
+// ユーザーに付与するロール。
+// DBの users.role に保存する値と一致させる。
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// 指定したロールのいずれかに該当するか判定する。
+func HasRole(role string, allowed ...string) bool {
+	for _, r := range allowed {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
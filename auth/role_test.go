@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasRole(t *testing.T) {
+	require.True(t, HasRole(RoleAdmin, RoleAdmin))
+	require.True(t, HasRole(RoleUser, RoleUser, RoleAdmin))
+	require.False(t, HasRole(RoleUser, RoleAdmin))
+	require.False(t, HasRole(RoleAdmin))
+}
@@ -10,7 +10,14 @@ type VerifySessionParams struct {
 	ClientIp  string
 }
 
+// 検証したセッションに紐づくユーザーの情報。
+type VerifiedSession struct {
+	UserID int64
+	// ユーザーのプロフィールに設定された言語。
+	Locale string
+}
+
 type SessionManager interface {
 	CreateSession() (uuid.UUID, error)
-	VerifySession(arg VerifySessionParams) (bool, error)
+	VerifySession(arg VerifySessionParams) (VerifiedSession, bool, error)
 }
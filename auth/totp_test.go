@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 Appendix B のテストベクター（SHA1）を6桁にしたもの。
+func TestGenerateTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	testCases := []struct {
+		name     string
+		time     time.Time
+		expected string
+	}{
+		{name: "59", time: time.Unix(59, 0), expected: "287082"},
+		{name: "1111111109", time: time.Unix(1111111109, 0), expected: "081804"},
+		{name: "1111111111", time: time.Unix(1111111111, 0), expected: "050471"},
+		{name: "1234567890", time: time.Unix(1234567890, 0), expected: "005924"},
+		{name: "2000000000", time: time.Unix(2000000000, 0), expected: "279037"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			code, err := GenerateTOTPCode(secret, tc.time)
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, code)
+		})
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := GenerateTOTPCode(secret, now)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		secret   string
+		code     string
+		time     time.Time
+		expected bool
+	}{
+		{name: "OK", secret: secret, code: code, time: now, expected: true},
+		// 時刻ずれは前後１ステップまで許容する。
+		{name: "OKWithSkew", secret: secret, code: code, time: now.Add(totpPeriod), expected: true},
+		{name: "Expired", secret: secret, code: code, time: now.Add(3 * totpPeriod), expected: false},
+		{name: "WrongCode", secret: secret, code: "abcdef", time: now, expected: false},
+		{name: "WrongLength", secret: secret, code: code[:5], time: now, expected: false},
+		{name: "WrongSecret", secret: "!!invalid!!", code: code, time: now, expected: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			valid := ValidateTOTPCode(tc.secret, tc.code, tc.time)
+
+			// Assert
+			require.Equal(t, tc.expected, valid)
+		})
+	}
+}
+
+func TestMatchTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	// 1111111111 はステップ 37037037 に含まれる。
+	now := time.Unix(1111111111, 0)
+	code, err := GenerateTOTPCode(secret, now)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name            string
+		time            time.Time
+		expectedCounter int64
+		expectedOK      bool
+	}{
+		{name: "OK", time: now, expectedCounter: 37037037, expectedOK: true},
+		// 時刻ずれを許容した場合も、コードを生成したステップを返す。
+		{name: "OKWithSkew", time: now.Add(totpPeriod), expectedCounter: 37037037, expectedOK: true},
+		{name: "Expired", time: now.Add(3 * totpPeriod), expectedOK: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			counter, ok := MatchTOTPCode(secret, code, tc.time)
+
+			// Assert
+			require.Equal(t, tc.expectedOK, ok)
+			require.Equal(t, tc.expectedCounter, counter)
+		})
+	}
+}
+
+func TestTOTPAuthURI(t *testing.T) {
+	// Act
+	uri := TOTPAuthURI("account-book", "test@example.com", "JBSWY3DPEHPK3PXP")
+
+	// Assert
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/account-book:test@example.com", u.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	require.Equal(t, "account-book", u.Query().Get("issuer"))
+}
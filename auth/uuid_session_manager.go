@@ -29,23 +29,23 @@ func (m *UuidSessionManager) CreateSession() (uuid.UUID, error) {
 	return uuid.NewRandom()
 }
 
-// セッションが有効か確かめる。
+// セッションが有効か確かめ、セッションに紐づくユーザーIDと言語設定を返す。
 //
 // 以下の条件を全て満たす時、有効とする。
 // * DBにセッションIDが存在する。
 // * 有効期限が現在よりも長い。
 // * アクセス元のUserAgentが発行時と同一。
 // * アクセス元のClientIPが発行時と同一。
-func (m *UuidSessionManager) VerifySession(arg VerifySessionParams) (bool, error) {
-	s, err := m.querier.GetSession(context.Background(), arg.SessionID)
+func (m *UuidSessionManager) VerifySession(arg VerifySessionParams) (VerifiedSession, bool, error) {
+	s, err := m.querier.GetAuthSession(context.Background(), arg.SessionID)
 	if err != nil {
 		// DBにセッションIDが存在しない時はエラーが返される。
 		// see: TestGetSessionWithWrongID in db/sqlc
-		return false, err
+		return VerifiedSession{}, false, err
 	}
 
 	valid := s.ExpiresAt.After(time.Now()) &&
 		arg.UserAgent == s.UserAgent &&
 		arg.ClientIp == s.ClientIp
-	return valid, nil
+	return VerifiedSession{UserID: s.UserID, Locale: s.Locale}, valid, nil
 }
@@ -84,33 +84,36 @@ func TestVerifySession(t *testing.T) {
 		UserAgent: "MacOS",
 		ClientIp:  ip,
 	}
-	session := db.Session{
+	session := db.GetAuthSessionRow{
 		ID:        arg.SessionID,
 		UserID:    util.RandomID(),
 		UserAgent: arg.UserAgent,
 		ClientIp:  arg.ClientIp,
 		CreatedAt: time.Now(),
 		ExpiresAt: time.Now().Add(30 * time.Minute),
+		Locale:    "en",
 	}
 
 	testCases := []struct {
 		name          string
 		arg           VerifySessionParams
 		buildStubs    func(querier *mockdb.MockQuerier)
-		checkResponse func(t *testing.T, valid bool, err error)
+		checkResponse func(t *testing.T, verified VerifiedSession, valid bool, err error)
 	}{
 		{
 			name: "OK",
 			arg:  arg,
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
-					GetSession(gomock.Any(), gomock.Any()).
+					GetAuthSession(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(session, nil)
 			},
-			checkResponse: func(t *testing.T, valid bool, err error) {
+			checkResponse: func(t *testing.T, verified VerifiedSession, valid bool, err error) {
 				require.True(t, valid)
 				require.NoError(t, err)
+				require.Equal(t, session.UserID, verified.UserID)
+				require.Equal(t, session.Locale, verified.Locale)
 			},
 		},
 		{
@@ -122,11 +125,11 @@ func TestVerifySession(t *testing.T) {
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
-					GetSession(gomock.Any(), gomock.Any()).
+					GetAuthSession(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Session{}, sql.ErrNoRows)
+					Return(db.GetAuthSessionRow{}, sql.ErrNoRows)
 			},
-			checkResponse: func(t *testing.T, valid bool, err error) {
+			checkResponse: func(t *testing.T, verified VerifiedSession, valid bool, err error) {
 				require.False(t, valid)
 				require.Error(t, err)
 				require.ErrorIs(t, err, sql.ErrNoRows)
@@ -137,9 +140,9 @@ func TestVerifySession(t *testing.T) {
 			arg:  arg,
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
-					GetSession(gomock.Any(), gomock.Any()).
+					GetAuthSession(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Session{
+					Return(db.GetAuthSessionRow{
 						ID:        arg.SessionID,
 						UserID:    util.RandomID(),
 						UserAgent: arg.UserAgent,
@@ -148,7 +151,7 @@ func TestVerifySession(t *testing.T) {
 						ExpiresAt: time.Now().Add(30 * time.Minute),
 					}, nil)
 			},
-			checkResponse: func(t *testing.T, valid bool, err error) {
+			checkResponse: func(t *testing.T, verified VerifiedSession, valid bool, err error) {
 				require.False(t, valid)
 				require.NoError(t, err)
 			},
@@ -158,9 +161,9 @@ func TestVerifySession(t *testing.T) {
 			arg:  arg,
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
-					GetSession(gomock.Any(), gomock.Any()).
+					GetAuthSession(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Session{
+					Return(db.GetAuthSessionRow{
 						ID:        arg.SessionID,
 						UserID:    util.RandomID(),
 						UserAgent: arg.UserAgent,
@@ -169,7 +172,7 @@ func TestVerifySession(t *testing.T) {
 						ExpiresAt: time.Now().Add(-10 * time.Second),
 					}, nil)
 			},
-			checkResponse: func(t *testing.T, valid bool, err error) {
+			checkResponse: func(t *testing.T, verified VerifiedSession, valid bool, err error) {
 				require.False(t, valid)
 				require.NoError(t, err)
 			},
@@ -190,10 +193,10 @@ func TestVerifySession(t *testing.T) {
 			m := NewManager(querier)
 
 			// Act
-			s, err := m.VerifySession(tc.arg)
+			verified, s, err := m.VerifySession(tc.arg)
 
 			// Assert
-			tc.checkResponse(t, s, err)
+			tc.checkResponse(t, verified, s, err)
 		})
 	}
 }
@@ -0,0 +1,1924 @@
+// Package dbtest は、db.Store の実装が満たすべき振る舞いを確かめる共通のテストを提供する。
+// PostgreSQL、SQLite、メモリ上の実装に同じテストを実行し、挙動が食い違わないようにする。
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// store に対して共通のテストを実行する。
+// PostgreSQL のように他のテストのデータが残っている場合も通るよう、
+// 作成したデータのみを確かめる。
+func RunStoreContract(t *testing.T, store db.Store) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, store) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, store) })
+	t.Run("Categories", func(t *testing.T) { testCategories(t, store) })
+	t.Run("Expenses", func(t *testing.T) { testExpenses(t, store) })
+	t.Run("Receipts", func(t *testing.T) { testReceipts(t, store) })
+	t.Run("Duplicates", func(t *testing.T) { testDuplicates(t, store) })
+	t.Run("Transfers", func(t *testing.T) { testTransfers(t, store) })
+	t.Run("TwoFactor", func(t *testing.T) { testTwoFactor(t, store) })
+	t.Run("APIKeys", func(t *testing.T) { testAPIKeys(t, store) })
+	t.Run("EmailChangeTokens", func(t *testing.T) { testEmailChangeTokens(t, store) })
+	t.Run("IdempotencyKeys", func(t *testing.T) { testIdempotencyKeys(t, store) })
+	t.Run("DeleteUserTx", func(t *testing.T) { testDeleteUserTx(t, store) })
+	t.Run("AuditEvents", func(t *testing.T) { testAuditEvents(t, store) })
+	t.Run("Trash", func(t *testing.T) { testTrash(t, store) })
+}
+
+// err が指定の種類の制約違反か確かめる。
+// DBの種類によらず、ドライバーのエラーではなく *db.ConstraintError が返ること。
+func requireConstraintError(t *testing.T, err error, kind error) {
+	t.Helper()
+	var constraintErr *db.ConstraintError
+	require.Truef(t, errors.As(err, &constraintErr), "expected *db.ConstraintError, got %v", err)
+	require.ErrorIs(t, err, kind)
+}
+
+// 存在しないIDとして使う値。
+const missingID int64 = 1 << 60
+
+func createUser(t *testing.T, store db.Store) db.User {
+	t.Helper()
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Name:     util.RandomUserName(),
+		Password: util.RandomPassword(),
+		Email:    util.RandomEmail(),
+		Age:      util.RandomAge(),
+		Balance:  util.RandomBalance(),
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func createCategory(t *testing.T, store db.Store) db.Category {
+	t.Helper()
+	category, err := store.CreateCategory(context.Background(), util.RandomString(12))
+	require.NoError(t, err)
+	return category
+}
+
+func createFoodContent(t *testing.T, store db.Store) db.FoodContent {
+	t.Helper()
+	content, err := store.CreateFoodContent(context.Background(), db.CreateFoodContentParams{
+		Name:         util.RandomFoodName(),
+		Calories:     util.RandomCalories(),
+		Lipid:        util.RandomNutrient(),
+		Carbohydrate: util.RandomNutrient(),
+		Protein:      util.RandomNutrient(),
+	})
+	require.NoError(t, err)
+	return content
+}
+
+func testUsers(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("CreateWithDefaults", func(t *testing.T) {
+		// Arrange
+		arg := db.CreateUserParams{
+			Name:     util.RandomUserName(),
+			Password: util.RandomPassword(),
+			Email:    util.RandomEmail(),
+			Age:      util.RandomAge(),
+			Balance:  util.RandomBalance(),
+		}
+
+		// Act
+		user, err := store.CreateUser(ctx, arg)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotZero(t, user.ID)
+		require.Equal(t, arg.Email, user.Email)
+		require.Equal(t, "user", user.Role)
+		require.Equal(t, "JPY", user.PreferredCurrency)
+		require.Equal(t, "ja", user.Locale)
+		require.Equal(t, "Asia/Tokyo", user.Timezone)
+		require.False(t, user.TotpEnabled)
+		require.False(t, user.DisabledAt.Valid)
+		require.WithinDuration(t, time.Now(), user.CreatedAt, 5*time.Second)
+
+		got, err := store.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, user.Email, got.Email)
+		require.True(t, user.CreatedAt.Equal(got.CreatedAt))
+	})
+
+	t.Run("UniqueEmail", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+
+		// Act
+		_, err := store.CreateUser(ctx, db.CreateUserParams{
+			Name:     util.RandomUserName(),
+			Password: util.RandomPassword(),
+			Email:    user.Email,
+		})
+
+		// Assert
+		requireConstraintError(t, err, db.ErrUniqueViolation)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		// Act
+		_, errByEmail := store.GetUser(ctx, util.RandomEmail())
+		_, errByID := store.GetUserByID(ctx, missingID)
+		_, errUpdate := store.UpdateUserProfile(ctx, db.UpdateUserProfileParams{ID: missingID})
+
+		// Assert
+		require.ErrorIs(t, errByEmail, sql.ErrNoRows)
+		require.ErrorIs(t, errByID, sql.ErrNoRows)
+		require.ErrorIs(t, errUpdate, sql.ErrNoRows)
+	})
+
+	t.Run("UpdateEmail", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		other := createUser(t, store)
+		newEmail := util.RandomEmail()
+
+		// Act
+		updated, err := store.UpdateUserEmail(ctx, db.UpdateUserEmailParams{ID: user.ID, Email: newEmail})
+		_, errDuplicate := store.UpdateUserEmail(ctx, db.UpdateUserEmailParams{ID: user.ID, Email: other.Email})
+		_, errSame := store.UpdateUserEmail(ctx, db.UpdateUserEmailParams{ID: user.ID, Email: newEmail})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, newEmail, updated.Email)
+		requireConstraintError(t, errDuplicate, db.ErrUniqueViolation)
+		// 自分自身のメールアドレスへの更新は違反にならない。
+		require.NoError(t, errSame)
+	})
+
+	t.Run("UpdateProfileAndTOTP", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+
+		// Act
+		profile, err := store.UpdateUserProfile(ctx, db.UpdateUserProfileParams{
+			ID:                user.ID,
+			Name:              "renamed",
+			Age:               42,
+			PreferredCurrency: "USD",
+			Locale:            "en",
+			Timezone:          "UTC",
+		})
+		require.NoError(t, err)
+		secret, err := store.UpdateUserTOTPSecret(ctx, db.UpdateUserTOTPSecretParams{
+			ID:         user.ID,
+			TotpSecret: sql.NullString{String: "secret", Valid: true},
+		})
+		require.NoError(t, err)
+		enabled, err := store.EnableUserTOTP(ctx, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, "renamed", profile.Name)
+		require.Equal(t, "USD", profile.PreferredCurrency)
+		require.Equal(t, "en", profile.Locale)
+		require.Equal(t, "UTC", profile.Timezone)
+		require.Equal(t, "secret", secret.TotpSecret.String)
+		require.False(t, secret.TotpEnabled)
+		require.True(t, enabled.TotpEnabled)
+		// RETURNING は更新後の行全体を返す。
+		require.Equal(t, "renamed", enabled.Name)
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		// Arrange
+		first := createUser(t, store)
+		second := createUser(t, store)
+
+		// Act
+		users, err := store.ListUsers(ctx, db.ListUsersParams{Limit: 1 << 30, Offset: 0})
+
+		// Assert
+		require.NoError(t, err)
+		var ids []int64
+		for _, user := range users {
+			ids = append(ids, user.ID)
+		}
+		require.Contains(t, ids, first.ID)
+		require.Contains(t, ids, second.ID)
+		require.True(t, sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }))
+
+		limited, err := store.ListUsers(ctx, db.ListUsersParams{Limit: 1, Offset: 0})
+		require.NoError(t, err)
+		require.Len(t, limited, 1)
+		require.Equal(t, ids[0], limited[0].ID)
+	})
+
+	t.Run("Deletion", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		scheduledAt := time.Now().Add(-time.Hour)
+
+		// Act
+		scheduled, err := store.UpdateUserDeletionScheduledAt(ctx, db.UpdateUserDeletionScheduledAtParams{
+			ID:                  user.ID,
+			DeletionScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+		})
+		require.NoError(t, err)
+		due, err := store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{Now: time.Now(), MaxUsers: 1 << 30})
+		require.NoError(t, err)
+		anonymized, err := store.AnonymizeUser(ctx, user.ID)
+		require.NoError(t, err)
+		_, errReschedule := store.UpdateUserDeletionScheduledAt(ctx, db.UpdateUserDeletionScheduledAtParams{
+			ID:                  user.ID,
+			DeletionScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+		})
+		dueAfter, err := store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{Now: time.Now(), MaxUsers: 1 << 30})
+
+		// Assert
+		require.NoError(t, err)
+		require.WithinDuration(t, scheduledAt, scheduled.DeletionScheduledAt.Time, time.Millisecond)
+		require.Contains(t, userIDs(due), user.ID)
+		require.Equal(t, "deleted user", anonymized.Name)
+		require.Empty(t, anonymized.Password)
+		require.NotEqual(t, user.Email, anonymized.Email)
+		require.True(t, anonymized.DisabledAt.Valid)
+		require.True(t, anonymized.AnonymizedAt.Valid)
+		require.False(t, anonymized.DeletionScheduledAt.Valid)
+		// 匿名化済みのユーザーは、削除の予定を更新できない。
+		require.ErrorIs(t, errReschedule, sql.ErrNoRows)
+		require.NotContains(t, userIDs(dueAfter), user.ID)
+	})
+}
+
+func userIDs(users []db.User) []int64 {
+	ids := []int64{}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func testSessions(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("ForeignKey", func(t *testing.T) {
+		// Act
+		_, err := store.CreateSession(ctx, db.CreateSessionParams{
+			ID:        uuid.New(),
+			UserID:    missingID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+
+		// Assert
+		requireConstraintError(t, err, db.ErrForeignKeyViolation)
+	})
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		arg := db.CreateSessionParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			UserAgent: "MacOS",
+			ClientIp:  util.RandomIPAddress(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		before, err := store.CountActiveSessions(ctx)
+		require.NoError(t, err)
+
+		// Act
+		session, err := store.CreateSession(ctx, arg)
+		require.NoError(t, err)
+		_, errDuplicate := store.CreateSession(ctx, arg)
+		active, err := store.CountActiveSessions(ctx)
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteSession(ctx, session.ID))
+		deleted, err := store.GetSession(ctx, session.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Millisecond)
+		requireConstraintError(t, errDuplicate, db.ErrUniqueViolation)
+		require.GreaterOrEqual(t, active, before+1)
+		// 行は削除されず、有効期限が切れる。
+		require.False(t, deleted.ExpiresAt.After(time.Now()))
+
+		_, err = store.GetSession(ctx, uuid.New())
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("AuthSession", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		_, err := store.UpdateUserProfile(ctx, db.UpdateUserProfileParams{
+			ID:                user.ID,
+			Name:              user.Name,
+			Age:               user.Age,
+			PreferredCurrency: user.PreferredCurrency,
+			Locale:            "en",
+			Timezone:          user.Timezone,
+		})
+		require.NoError(t, err)
+		session, err := store.CreateSession(ctx, db.CreateSessionParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			UserAgent: "MacOS",
+			ClientIp:  util.RandomIPAddress(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		// Act
+		got, err := store.GetAuthSession(ctx, session.ID)
+		_, errMissing := store.GetAuthSession(ctx, uuid.New())
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, session.UserID, got.UserID)
+		require.Equal(t, session.UserAgent, got.UserAgent)
+		require.Equal(t, session.ClientIp, got.ClientIp)
+		require.WithinDuration(t, session.ExpiresAt, got.ExpiresAt, time.Millisecond)
+		require.Equal(t, "en", got.Locale)
+		require.ErrorIs(t, errMissing, sql.ErrNoRows)
+	})
+
+	t.Run("UserSessions", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		for i := 0; i < 2; i++ {
+			_, err := store.CreateSession(ctx, db.CreateSessionParams{
+				ID:        uuid.New(),
+				UserID:    user.ID,
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+			require.NoError(t, err)
+		}
+
+		// Act
+		require.NoError(t, store.DeleteUserSessions(ctx, user.ID))
+		sessions, err := store.ListUserSessions(ctx, user.ID)
+		require.NoError(t, err)
+		require.NoError(t, store.PurgeUserSessions(ctx, user.ID))
+		purged, err := store.ListUserSessions(ctx, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		for _, session := range sessions {
+			require.False(t, session.ExpiresAt.After(time.Now()))
+		}
+		require.NotNil(t, purged)
+		require.Empty(t, purged)
+	})
+}
+
+func testCategories(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("UniqueName", func(t *testing.T) {
+		// Arrange
+		category := createCategory(t, store)
+		other := createCategory(t, store)
+
+		// Act
+		_, errCreate := store.CreateCategory(ctx, category.Name)
+		_, errUpdate := store.UpdateCategory(ctx, db.UpdateCategoryParams{ID: other.ID, Name: category.Name, Version: other.Version})
+
+		// Assert
+		requireConstraintError(t, errCreate, db.ErrUniqueViolation)
+		requireConstraintError(t, errUpdate, db.ErrUniqueViolation)
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		// Arrange
+		category := createCategory(t, store)
+		newName := util.RandomString(12)
+
+		// Act
+		updated, err := store.UpdateCategory(ctx, db.UpdateCategoryParams{ID: category.ID, Name: newName, Version: category.Version})
+		require.NoError(t, err)
+		deleted, err := store.DeleteCategory(ctx, db.DeleteCategoryParams{ID: category.ID, Version: updated.Version})
+		require.NoError(t, err)
+		_, errUpdate := store.UpdateCategory(ctx, db.UpdateCategoryParams{ID: category.ID, Name: util.RandomString(12), Version: deleted.Version})
+		_, errDelete := store.DeleteCategory(ctx, db.DeleteCategoryParams{ID: category.ID, Version: deleted.Version})
+		categories, err := store.ListCategories(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, newName, updated.Name)
+		require.Equal(t, int64(1), category.Version)
+		require.Equal(t, category.Version+1, updated.Version)
+		// ゴミ箱に移した行を返す。
+		require.Equal(t, updated.ID, deleted.ID)
+		require.Equal(t, updated.Name, deleted.Name)
+		require.Equal(t, updated.Version+1, deleted.Version)
+		require.True(t, deleted.DeletedAt.Valid)
+		require.ErrorIs(t, errUpdate, sql.ErrNoRows)
+		require.ErrorIs(t, errDelete, sql.ErrNoRows)
+		for _, c := range categories {
+			require.NotEqual(t, category.ID, c.ID)
+		}
+	})
+
+	t.Run("StaleVersion", func(t *testing.T) {
+		// Arrange
+		category := createCategory(t, store)
+		actor := db.AuditActor{UserID: createUser(t, store).ID}
+		updated, err := store.UpdateCategory(ctx, db.UpdateCategoryParams{ID: category.ID, Name: util.RandomString(12), Version: category.Version})
+		require.NoError(t, err)
+
+		// Act
+		// 古い version を条件にした更新と削除は、どの行にも一致しない。
+		_, errUpdate := store.UpdateCategory(ctx, db.UpdateCategoryParams{ID: category.ID, Name: util.RandomString(12), Version: category.Version})
+		_, errDelete := store.DeleteCategory(ctx, db.DeleteCategoryParams{ID: category.ID, Version: category.Version})
+		_, errUpdateTx := store.UpdateCategoryTx(ctx, db.UpdateCategoryParams{ID: category.ID, Name: util.RandomString(12), Version: category.Version}, actor)
+		_, errDeleteTx := store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: category.ID, Version: category.Version}, actor)
+		categories, err := store.ListCategories(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		require.ErrorIs(t, errUpdate, sql.ErrNoRows)
+		require.ErrorIs(t, errDelete, sql.ErrNoRows)
+		require.ErrorIs(t, errUpdateTx, db.ErrVersionMismatch)
+		require.ErrorIs(t, errDeleteTx, db.ErrVersionMismatch)
+		require.Contains(t, categories, updated)
+	})
+
+	t.Run("ReferencedByExpense", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		_, err := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:     user.ID,
+			CategoryID: category.ID,
+			Amount:     util.RandomExpense(),
+		})
+		require.NoError(t, err)
+
+		// Act
+		_, err = store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: category.ID}, db.AuditActor{UserID: user.ID})
+
+		// Assert
+		require.ErrorIs(t, err, db.ErrCategoryInUse)
+		categories, err := store.ListCategories(ctx)
+		require.NoError(t, err)
+		require.Contains(t, categories, category)
+	})
+}
+
+func testExpenses(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("ForeignKeys", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+
+		// Act
+		_, errUser := store.CreateExpense(ctx, db.CreateExpenseParams{UserID: missingID, CategoryID: category.ID})
+		_, errCategory := store.CreateExpense(ctx, db.CreateExpenseParams{UserID: user.ID, CategoryID: missingID})
+		_, errReceipt := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:        user.ID,
+			CategoryID:    category.ID,
+			FoodReceiptID: sql.NullInt64{Int64: missingID, Valid: true},
+		})
+
+		// Assert
+		requireConstraintError(t, errUser, db.ErrForeignKeyViolation)
+		requireConstraintError(t, errCategory, db.ErrForeignKeyViolation)
+		requireConstraintError(t, errReceipt, db.ErrForeignKeyViolation)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		receipt, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{StoreName: util.RandomStoreName()})
+		require.NoError(t, err)
+		withReceipt, err := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:        user.ID,
+			CategoryID:    category.ID,
+			Amount:        util.RandomExpense(),
+			FoodReceiptID: sql.NullInt64{Int64: receipt.ID, Valid: true},
+			Comment:       sql.NullString{String: "lunch", Valid: true},
+		})
+		require.NoError(t, err)
+		withoutReceipt, err := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:     user.ID,
+			CategoryID: category.ID,
+			Amount:     util.RandomExpense(),
+		})
+		require.NoError(t, err)
+
+		// Act
+		rows, err := store.ListExpenses(ctx, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		// ORDER BY がないため、順序に依存しないよう並べ替える。
+		sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+		require.Equal(t, withReceipt.ID, rows[0].ID)
+		require.Equal(t, receipt.StoreName, rows[0].StoreName)
+		require.Equal(t, "lunch", rows[0].Comment.String)
+		require.Equal(t, withoutReceipt.ID, rows[1].ID)
+		require.Equal(t, "", rows[1].StoreName)
+		require.False(t, rows[1].Comment.Valid)
+
+		empty, err := store.ListExpenses(ctx, missingID)
+		require.NoError(t, err)
+		require.NotNil(t, empty)
+		require.Empty(t, empty)
+	})
+}
+
+func testReceipts(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("FoodContents", func(t *testing.T) {
+		// Arrange
+		content := createFoodContent(t, store)
+
+		// Act
+		got, err := store.GetFoodContent(ctx, content.ID)
+		require.NoError(t, err)
+		updated, err := store.UpdateFoodContent(ctx, db.UpdateFoodContentParams{
+			ID:           content.ID,
+			Name:         "updated",
+			Calories:     1.5,
+			Lipid:        2.5,
+			Carbohydrate: 3.5,
+			Protein:      4.5,
+			Version:      content.Version,
+		})
+		require.NoError(t, err)
+		_, errStaleUpdate := store.UpdateFoodContent(ctx, db.UpdateFoodContentParams{ID: content.ID, Version: content.Version})
+		_, errStaleDelete := store.DeleteFoodContent(ctx, db.DeleteFoodContentParams{ID: content.ID, Version: content.Version})
+		deleted, err := store.DeleteFoodContent(ctx, db.DeleteFoodContentParams{ID: content.ID, Version: updated.Version})
+		require.NoError(t, err)
+		_, errGet := store.GetFoodContent(ctx, content.ID)
+		_, errUpdate := store.UpdateFoodContent(ctx, db.UpdateFoodContentParams{ID: content.ID, Version: updated.Version})
+
+		// Assert
+		require.Equal(t, content, got)
+		require.Equal(t, "updated", updated.Name)
+		require.Equal(t, float32(4.5), updated.Protein)
+		require.Equal(t, content.Version+1, updated.Version)
+		require.ErrorIs(t, errStaleUpdate, sql.ErrNoRows)
+		require.ErrorIs(t, errStaleDelete, sql.ErrNoRows)
+		require.Equal(t, updated, deleted)
+		require.ErrorIs(t, errGet, sql.ErrNoRows)
+		require.ErrorIs(t, errUpdate, sql.ErrNoRows)
+	})
+
+	t.Run("GetFoodContentByName", func(t *testing.T) {
+		// Arrange
+		name := util.RandomString(16)
+		first, err := store.CreateFoodContent(ctx, db.CreateFoodContentParams{Name: name, Calories: 1})
+		require.NoError(t, err)
+		_, err = store.CreateFoodContent(ctx, db.CreateFoodContentParams{Name: name, Calories: 2})
+		require.NoError(t, err)
+
+		// Act
+		got, err := store.GetFoodContentByName(ctx, name)
+		_, errMissing := store.GetFoodContentByName(ctx, util.RandomString(16))
+
+		// Assert
+		require.NoError(t, err)
+		// 同じ名前の食品が複数ある場合は、最初に登録されたもの。
+		require.Equal(t, first, got)
+		require.ErrorIs(t, errMissing, sql.ErrNoRows)
+	})
+
+	t.Run("ReceiptContents", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		content := createFoodContent(t, store)
+		receipt, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{
+			StoreName: util.RandomStoreName(),
+			UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		})
+		require.NoError(t, err)
+
+		// Act
+		_, errReceipt := store.CreateFoodReceiptContent(ctx, db.CreateFoodReceiptContentParams{FoodReceiptID: missingID, FoodContentID: content.ID, Amount: 1})
+		_, errContent := store.CreateFoodReceiptContent(ctx, db.CreateFoodReceiptContentParams{FoodReceiptID: receipt.ID, FoodContentID: missingID, Amount: 1})
+		_, errAmount := store.CreateFoodReceiptContent(ctx, db.CreateFoodReceiptContentParams{FoodReceiptID: receipt.ID, FoodContentID: content.ID, Amount: 0})
+		rc, err := store.CreateFoodReceiptContent(ctx, db.CreateFoodReceiptContentParams{FoodReceiptID: receipt.ID, FoodContentID: content.ID, Amount: 2})
+		require.NoError(t, err)
+		_, errDeleteContent := store.DeleteFoodContent(ctx, db.DeleteFoodContentParams{ID: content.ID, Version: content.Version})
+		_, err = store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:        user.ID,
+			CategoryID:    category.ID,
+			Amount:        util.RandomExpense(),
+			FoodReceiptID: sql.NullInt64{Int64: receipt.ID, Valid: true},
+		})
+		require.NoError(t, err)
+		contents, err := store.ListFoodReceiptContents(ctx, receipt.ID)
+		require.NoError(t, err)
+		// 明細のないレシートも、エクスポートに含める。
+		empty, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{
+			StoreName: util.RandomStoreName(),
+			UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		})
+		require.NoError(t, err)
+		userContents, err := store.ListUserFoodReceiptContents(ctx, user.ID)
+		require.NoError(t, err)
+		ids, err := store.ListUserFoodReceiptIDs(ctx, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		requireConstraintError(t, errReceipt, db.ErrForeignKeyViolation)
+		requireConstraintError(t, errContent, db.ErrForeignKeyViolation)
+		// 明細の数量は正の値でなければならない。
+		requireConstraintError(t, errAmount, db.ErrCheckViolation)
+		require.Equal(t, int64(2), rc.Amount)
+		// 明細から参照されている食品は削除できない。
+		requireConstraintError(t, errDeleteContent, db.ErrForeignKeyViolation)
+		require.Equal(t, []db.ListFoodReceiptContentsRow{{
+			FoodReceiptID: receipt.ID,
+			FoodContentID: content.ID,
+			Amount:        2,
+			Name:          content.Name,
+			Calories:      content.Calories,
+			Lipid:         content.Lipid,
+			Carbohydrate:  content.Carbohydrate,
+			Protein:       content.Protein,
+		}}, contents)
+		require.Len(t, userContents, 2)
+		require.Equal(t, receipt.ID, userContents[0].FoodReceiptID)
+		require.Equal(t, receipt.StoreName, userContents[0].StoreName)
+		require.Equal(t, sql.NullInt64{Int64: content.ID, Valid: true}, userContents[0].FoodContentID)
+		require.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, userContents[0].Amount)
+		require.Equal(t, sql.NullString{String: content.Name, Valid: true}, userContents[0].Name)
+		require.Equal(t, content.Calories, float32(userContents[0].Calories.Float64))
+		require.Equal(t, db.ListUserFoodReceiptContentsRow{FoodReceiptID: empty.ID, StoreName: empty.StoreName}, userContents[1])
+		require.Equal(t, []int64{receipt.ID, empty.ID}, ids)
+	})
+
+	t.Run("DeleteUnreferenced", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		content := createFoodContent(t, store)
+		shared, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{StoreName: util.RandomStoreName()})
+		require.NoError(t, err)
+		orphan, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{StoreName: util.RandomStoreName()})
+		require.NoError(t, err)
+		for _, receipt := range []db.FoodReceipt{shared, orphan} {
+			_, err := store.CreateFoodReceiptContent(ctx, db.CreateFoodReceiptContentParams{FoodReceiptID: receipt.ID, FoodContentID: content.ID, Amount: 1})
+			require.NoError(t, err)
+		}
+		_, err = store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:        user.ID,
+			CategoryID:    category.ID,
+			FoodReceiptID: sql.NullInt64{Int64: shared.ID, Valid: true},
+		})
+		require.NoError(t, err)
+		ids := []int64{shared.ID, orphan.ID}
+
+		// Act
+		// 明細が残っている間は、レシートを削除できない。
+		errWithContents := store.DeleteFoodReceipts(ctx, ids)
+		require.NoError(t, store.DeleteFoodReceiptContents(ctx, ids))
+		require.NoError(t, store.DeleteFoodReceipts(ctx, ids))
+
+		// Assert
+		requireConstraintError(t, errWithContents, db.ErrForeignKeyViolation)
+		_, err = store.GetFoodReceipt(ctx, shared.ID)
+		require.NoError(t, err)
+		sharedContents, err := store.ListFoodReceiptContents(ctx, shared.ID)
+		require.NoError(t, err)
+		require.Len(t, sharedContents, 1)
+		_, err = store.GetFoodReceipt(ctx, orphan.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func testDuplicates(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	createExpense := func(t *testing.T, userID, categoryID, amount int64) db.Expense {
+		expense, err := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:     userID,
+			CategoryID: categoryID,
+			Amount:     amount,
+		})
+		require.NoError(t, err)
+		return expense
+	}
+	expenseIDs := func(expenses []db.Expense) []int64 {
+		ids := []int64{}
+		for _, expense := range expenses {
+			ids = append(ids, expense.ID)
+		}
+		return ids
+	}
+
+	t.Run("ExpenseCandidates", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		first := createExpense(t, user.ID, category.ID, 1200)
+		second := createExpense(t, user.ID, category.ID, 1200)
+		deleted := createExpense(t, user.ID, category.ID, 1200)
+		_, err := store.SoftDeleteExpense(ctx, db.SoftDeleteExpenseParams{ID: deleted.ID, UserID: user.ID, Version: deleted.Version})
+		require.NoError(t, err)
+		createExpense(t, user.ID, category.ID, 1300)
+		createExpense(t, user.ID, createCategory(t, store).ID, 1200)
+		createExpense(t, createUser(t, store).ID, category.ID, 1200)
+		arg := db.ListDuplicateExpenseCandidatesParams{
+			UserID:     user.ID,
+			CategoryID: category.ID,
+			Amount:     1200,
+			Since:      time.Now().Add(-time.Minute),
+		}
+
+		// Act
+		candidates, err := store.ListDuplicateExpenseCandidates(ctx, arg)
+		require.NoError(t, err)
+		arg.Since = time.Now().Add(time.Minute)
+		future, err := store.ListDuplicateExpenseCandidates(ctx, arg)
+
+		// Assert
+		require.NoError(t, err)
+		// 新しい順に返し、ゴミ箱にあるものは含めない。
+		require.Equal(t, []int64{second.ID, first.ID}, expenseIDs(candidates))
+		require.Empty(t, future)
+	})
+
+	t.Run("ListDuplicateExpenses", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		other := createCategory(t, store)
+		lunch1 := createExpense(t, user.ID, category.ID, 800)
+		createExpense(t, user.ID, category.ID, 900)
+		lunch2 := createExpense(t, user.ID, category.ID, 800)
+		train1 := createExpense(t, user.ID, other.ID, 200)
+		train2 := createExpense(t, user.ID, other.ID, 200)
+		createExpense(t, user.ID, other.ID, 800)
+
+		// Act
+		duplicates, err := store.ListDuplicateExpenses(ctx, db.ListDuplicateExpensesParams{
+			UserID:        user.ID,
+			WindowSeconds: 60,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		// カテゴリー・金額・作成日時の順に並ぶ。
+		require.Equal(t, []int64{lunch1.ID, lunch2.ID, train1.ID, train2.ID}, expenseIDs(duplicates))
+	})
+
+	t.Run("ReceiptCandidates", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		arg := db.CreateFoodReceiptParams{
+			StoreName:   util.RandomStoreName(),
+			UserID:      sql.NullInt64{Int64: user.ID, Valid: true},
+			TotalPrice:  sql.NullInt64{Int64: 980, Valid: true},
+			ItemsDigest: sql.NullString{String: util.RandomString(64), Valid: true},
+		}
+		first, err := store.CreateFoodReceipt(ctx, arg)
+		require.NoError(t, err)
+		second, err := store.CreateFoodReceipt(ctx, arg)
+		require.NoError(t, err)
+		otherItems := arg
+		otherItems.ItemsDigest = sql.NullString{String: util.RandomString(64), Valid: true}
+		_, err = store.CreateFoodReceipt(ctx, otherItems)
+		require.NoError(t, err)
+		unknown := arg
+		unknown.UserID = sql.NullInt64{}
+		_, err = store.CreateFoodReceipt(ctx, unknown)
+		require.NoError(t, err)
+
+		// Act
+		candidates, err := store.ListDuplicateFoodReceiptCandidates(ctx, db.ListDuplicateFoodReceiptCandidatesParams{
+			UserID:      arg.UserID,
+			StoreName:   arg.StoreName,
+			TotalPrice:  arg.TotalPrice,
+			ItemsDigest: arg.ItemsDigest,
+			Since:       time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		ids, err := store.ListUserFoodReceiptIDs(ctx, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, candidates, 2)
+		require.Equal(t, second.ID, candidates[0].ID)
+		require.Equal(t, first.ID, candidates[1].ID)
+		require.WithinDuration(t, time.Now(), first.CreatedAt, time.Minute)
+		// 支出から参照していなくても、登録したユーザーのレシートとして扱う。
+		require.Len(t, ids, 3)
+		require.Contains(t, ids, first.ID)
+	})
+
+	t.Run("ReceiptForeignKey", func(t *testing.T) {
+		// Act
+		_, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{
+			StoreName: util.RandomStoreName(),
+			UserID:    sql.NullInt64{Int64: -1, Valid: true},
+		})
+
+		// Assert
+		requireConstraintError(t, err, db.ErrForeignKeyViolation)
+	})
+}
+
+func testTransfers(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	// Arrange
+	from := createUser(t, store)
+	to := createUser(t, store)
+
+	// Act
+	_, errZero := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: from.ID, ToUserID: to.ID, Amount: 0})
+	_, errNegative := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: from.ID, ToUserID: to.ID, Amount: -100})
+	_, errFrom := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: missingID, ToUserID: to.ID, Amount: 1})
+	_, errTo := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: from.ID, ToUserID: missingID, Amount: 1})
+	sent, err := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: from.ID, ToUserID: to.ID, Amount: 100})
+	require.NoError(t, err)
+	received, err := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: to.ID, ToUserID: from.ID, Amount: 50})
+	require.NoError(t, err)
+	transfers, err := store.ListUserTransfers(ctx, from.ID)
+
+	// Assert
+	require.NoError(t, err)
+	// 送金額は正の値でなければならない。
+	requireConstraintError(t, errZero, db.ErrCheckViolation)
+	requireConstraintError(t, errNegative, db.ErrCheckViolation)
+	requireConstraintError(t, errFrom, db.ErrForeignKeyViolation)
+	requireConstraintError(t, errTo, db.ErrForeignKeyViolation)
+	require.Equal(t, []db.Transfer{sent, received}, transfers)
+}
+
+func testTwoFactor(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("RecoveryCodes", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		_, err := store.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{UserID: user.ID, CodeHash: "hash"})
+		require.NoError(t, err)
+
+		// Act
+		_, errFK := store.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{UserID: missingID, CodeHash: "hash"})
+		used, err := store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: user.ID, CodeHash: "hash"})
+		require.NoError(t, err)
+		_, errReuse := store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: user.ID, CodeHash: "hash"})
+		_, err = store.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{UserID: user.ID, CodeHash: "other"})
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteRecoveryCodes(ctx, user.ID))
+		_, errDeleted := store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: user.ID, CodeHash: "other"})
+
+		// Assert
+		requireConstraintError(t, errFK, db.ErrForeignKeyViolation)
+		require.True(t, used.UsedAt.Valid)
+		// 使用済みのコードは再利用できない。
+		require.ErrorIs(t, errReuse, sql.ErrNoRows)
+		require.ErrorIs(t, errDeleted, sql.ErrNoRows)
+	})
+
+	t.Run("Challenges", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		arg := db.CreateTwoFactorChallengeParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(5 * time.Minute),
+		}
+
+		// Act
+		challenge, err := store.CreateTwoFactorChallenge(ctx, arg)
+		require.NoError(t, err)
+		got, err := store.GetTwoFactorChallenge(ctx, challenge.ID)
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteTwoFactorChallenge(ctx, challenge.ID))
+		_, errDeleted := store.GetTwoFactorChallenge(ctx, challenge.ID)
+		other, err := store.CreateTwoFactorChallenge(ctx, db.CreateTwoFactorChallengeParams{ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now()})
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteUserTwoFactorChallenges(ctx, user.ID))
+		_, errUserDeleted := store.GetTwoFactorChallenge(ctx, other.ID)
+
+		// Assert
+		require.Equal(t, challenge.ID, got.ID)
+		require.True(t, challenge.CreatedAt.Equal(got.CreatedAt))
+		require.ErrorIs(t, errDeleted, sql.ErrNoRows)
+		require.ErrorIs(t, errUserDeleted, sql.ErrNoRows)
+	})
+
+	t.Run("ChallengeAttempts", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		challenge, err := store.CreateTwoFactorChallenge(ctx, db.CreateTwoFactorChallengeParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(5 * time.Minute),
+		})
+		require.NoError(t, err)
+		arg := db.UseTwoFactorChallengeAttemptParams{ID: challenge.ID, MaxAttempts: 2}
+
+		// Act
+		first, err := store.UseTwoFactorChallengeAttempt(ctx, arg)
+		require.NoError(t, err)
+		second, err := store.UseTwoFactorChallengeAttempt(ctx, arg)
+		require.NoError(t, err)
+		_, errExhausted := store.UseTwoFactorChallengeAttempt(ctx, arg)
+		_, errMissing := store.UseTwoFactorChallengeAttempt(ctx, db.UseTwoFactorChallengeAttemptParams{ID: uuid.New(), MaxAttempts: 2})
+		got, err := store.GetTwoFactorChallenge(ctx, challenge.ID)
+		require.NoError(t, err)
+
+		// Assert
+		require.Zero(t, challenge.Attempts)
+		require.Equal(t, int32(1), first.Attempts)
+		require.Equal(t, int32(2), second.Attempts)
+		// 上限に達した後は、試行回数を増やさない。
+		require.ErrorIs(t, errExhausted, sql.ErrNoRows)
+		require.ErrorIs(t, errMissing, sql.ErrNoRows)
+		require.Equal(t, int32(2), got.Attempts)
+	})
+
+	t.Run("TOTPLastCounter", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+
+		// Act
+		first, err := store.UpdateUserTOTPLastCounter(ctx, db.UpdateUserTOTPLastCounterParams{ID: user.ID, Counter: 100})
+		require.NoError(t, err)
+		same, err := store.UpdateUserTOTPLastCounter(ctx, db.UpdateUserTOTPLastCounterParams{ID: user.ID, Counter: 100})
+		require.NoError(t, err)
+		older, err := store.UpdateUserTOTPLastCounter(ctx, db.UpdateUserTOTPLastCounterParams{ID: user.ID, Counter: 99})
+		require.NoError(t, err)
+		newer, err := store.UpdateUserTOTPLastCounter(ctx, db.UpdateUserTOTPLastCounterParams{ID: user.ID, Counter: 101})
+		require.NoError(t, err)
+		got, err := store.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+
+		// Assert
+		require.False(t, user.TotpLastCounter.Valid)
+		require.Equal(t, int64(1), first)
+		// 受け付け済みのステップ以前のコードは、再利用とみなして更新しない。
+		require.Zero(t, same)
+		require.Zero(t, older)
+		require.Equal(t, int64(1), newer)
+		require.Equal(t, sql.NullInt64{Int64: 101, Valid: true}, got.TotpLastCounter)
+	})
+}
+
+func testAPIKeys(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	// Arrange
+	user := createUser(t, store)
+	arg := db.CreateAPIKeyParams{
+		UserID:    user.ID,
+		Name:      "ci",
+		KeyPrefix: "abk_1234",
+		KeyHash:   util.RandomString(32),
+		Scopes:    []string{"expenses:read"},
+	}
+
+	// Act
+	key, err := store.CreateAPIKey(ctx, arg)
+	require.NoError(t, err)
+	_, errDuplicate := store.CreateAPIKey(ctx, arg)
+	_, errFK := store.CreateAPIKey(ctx, db.CreateAPIKeyParams{UserID: missingID, KeyHash: util.RandomString(32), Scopes: []string{}})
+	require.NoError(t, store.UpdateAPIKeyLastUsed(ctx, key.ID))
+	got, err := store.GetAPIKeyByHash(ctx, arg.KeyHash)
+	require.NoError(t, err)
+	_, err = store.UpdateUserDisabledAt(ctx, db.UpdateUserDisabledAtParams{ID: user.ID, DisabledAt: sql.NullTime{Time: time.Now(), Valid: true}})
+	require.NoError(t, err)
+	_, errDisabled := store.GetAPIKeyByHash(ctx, arg.KeyHash)
+	revoked, err := store.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{ID: key.ID, UserID: user.ID})
+	require.NoError(t, err)
+	_, errRevokeTwice := store.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{ID: key.ID, UserID: user.ID})
+	keys, err := store.ListAPIKeys(ctx, user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, []string{"expenses:read"}, key.Scopes)
+	requireConstraintError(t, errDuplicate, db.ErrUniqueViolation)
+	requireConstraintError(t, errFK, db.ErrForeignKeyViolation)
+	require.True(t, got.LastUsedAt.Valid)
+	require.Equal(t, user.Locale, got.Locale)
+	// 無効化されたユーザーのキーは存在しないものとして扱う。
+	require.ErrorIs(t, errDisabled, sql.ErrNoRows)
+	require.True(t, revoked.RevokedAt.Valid)
+	require.ErrorIs(t, errRevokeTwice, sql.ErrNoRows)
+	require.NotNil(t, keys)
+	require.Empty(t, keys)
+}
+
+func testEmailChangeTokens(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	// Arrange
+	user := createUser(t, store)
+	valid := db.CreateEmailChangeTokenParams{
+		UserID:    user.ID,
+		NewEmail:  util.RandomEmail(),
+		TokenHash: util.RandomString(32),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expired := db.CreateEmailChangeTokenParams{
+		UserID:    user.ID,
+		NewEmail:  util.RandomEmail(),
+		TokenHash: util.RandomString(32),
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}
+
+	// Act
+	_, err := store.CreateEmailChangeToken(ctx, valid)
+	require.NoError(t, err)
+	_, errDuplicate := store.CreateEmailChangeToken(ctx, valid)
+	_, err = store.CreateEmailChangeToken(ctx, expired)
+	require.NoError(t, err)
+	used, err := store.UseEmailChangeToken(ctx, valid.TokenHash)
+	require.NoError(t, err)
+	_, errReuse := store.UseEmailChangeToken(ctx, valid.TokenHash)
+	_, errExpired := store.UseEmailChangeToken(ctx, expired.TokenHash)
+
+	// Assert
+	requireConstraintError(t, errDuplicate, db.ErrUniqueViolation)
+	require.Equal(t, valid.NewEmail, used.NewEmail)
+	require.True(t, used.UsedAt.Valid)
+	require.ErrorIs(t, errReuse, sql.ErrNoRows)
+	require.ErrorIs(t, errExpired, sql.ErrNoRows)
+
+	t.Run("ConfirmEmailChangeTx", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		taken := createUser(t, store)
+		arg := db.CreateEmailChangeTokenParams{
+			UserID:    user.ID,
+			NewEmail:  taken.Email,
+			TokenHash: util.RandomString(32),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		_, err := store.CreateEmailChangeToken(ctx, arg)
+		require.NoError(t, err)
+
+		// Act
+		_, errTaken := store.ConfirmEmailChangeTx(ctx, arg.TokenHash)
+		// 確認前に、先に登録していたユーザーがメールアドレスを変更した場合。
+		_, err = store.UpdateUserEmail(ctx, db.UpdateUserEmailParams{ID: taken.ID, Email: util.RandomEmail()})
+		require.NoError(t, err)
+		confirmed, err := store.ConfirmEmailChangeTx(ctx, arg.TokenHash)
+		require.NoError(t, err)
+		_, errReuse := store.ConfirmEmailChangeTx(ctx, arg.TokenHash)
+
+		// Assert
+		// 変更に失敗した場合は、トークンを使用済みにしない。
+		requireConstraintError(t, errTaken, db.ErrUniqueViolation)
+		require.Equal(t, user.ID, confirmed.ID)
+		require.Equal(t, arg.NewEmail, confirmed.Email)
+		require.ErrorIs(t, errReuse, sql.ErrNoRows)
+	})
+}
+
+func testIdempotencyKeys(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("ForeignKey", func(t *testing.T) {
+		// Act
+		_, err := store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			UserID:      -1,
+			Key:         util.RandomString(16),
+			Fingerprint: util.RandomString(64),
+			ExpiresAt:   time.Now().Add(time.Hour),
+			StaleBefore: time.Now().Add(-time.Minute),
+		})
+
+		// Assert
+		requireConstraintError(t, err, db.ErrForeignKeyViolation)
+	})
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		arg := db.CreateIdempotencyKeyParams{
+			UserID:      user.ID,
+			Key:         util.RandomString(16),
+			Fingerprint: util.RandomString(64),
+			ExpiresAt:   time.Now().Add(time.Hour),
+			StaleBefore: time.Now().Add(-time.Minute),
+		}
+		complete := db.CompleteIdempotencyKeyParams{
+			StatusCode:      201,
+			ResponseHeaders: json.RawMessage(`{"Content-Type":"application/json"}`),
+			ResponseBody:    []byte(`{"id":1}`),
+			UserID:          user.ID,
+			Key:             arg.Key,
+		}
+
+		// Act
+		created, err := store.CreateIdempotencyKey(ctx, arg)
+		require.NoError(t, err)
+		_, errInProgress := store.CreateIdempotencyKey(ctx, arg)
+		err = store.CompleteIdempotencyKey(ctx, complete)
+		require.NoError(t, err)
+		// 完了した行は、処理中とみなす期限を過ぎても置き換えない。
+		arg.StaleBefore = time.Now().Add(time.Minute)
+		_, errCompleted := store.CreateIdempotencyKey(ctx, arg)
+		got, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{UserID: user.ID, Key: arg.Key})
+		require.NoError(t, err)
+		err = store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{UserID: user.ID, Key: arg.Key})
+		require.NoError(t, err)
+		_, errDeleted := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{UserID: user.ID, Key: arg.Key})
+
+		// Assert
+		require.Equal(t, arg.Fingerprint, created.Fingerprint)
+		require.False(t, created.StatusCode.Valid)
+		require.JSONEq(t, `{}`, string(created.ResponseHeaders))
+		require.Empty(t, created.ResponseBody)
+		require.WithinDuration(t, arg.ExpiresAt, created.ExpiresAt, time.Second)
+		require.ErrorIs(t, errInProgress, sql.ErrNoRows)
+		require.ErrorIs(t, errCompleted, sql.ErrNoRows)
+		require.Equal(t, sql.NullInt32{Int32: 201, Valid: true}, got.StatusCode)
+		require.JSONEq(t, string(complete.ResponseHeaders), string(got.ResponseHeaders))
+		require.Equal(t, complete.ResponseBody, got.ResponseBody)
+		require.ErrorIs(t, errDeleted, sql.ErrNoRows)
+	})
+
+	t.Run("Takeover", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		stale := db.CreateIdempotencyKeyParams{
+			UserID:      user.ID,
+			Key:         util.RandomString(16),
+			Fingerprint: util.RandomString(64),
+			ExpiresAt:   time.Now().Add(time.Hour),
+			StaleBefore: time.Now().Add(-time.Minute),
+		}
+		expired := db.CreateIdempotencyKeyParams{
+			UserID:      user.ID,
+			Key:         util.RandomString(16),
+			Fingerprint: util.RandomString(64),
+			ExpiresAt:   time.Now().Add(-time.Hour),
+			StaleBefore: time.Now().Add(-time.Minute),
+		}
+		_, err := store.CreateIdempotencyKey(ctx, stale)
+		require.NoError(t, err)
+		_, err = store.CreateIdempotencyKey(ctx, expired)
+		require.NoError(t, err)
+		err = store.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+			StatusCode:      201,
+			ResponseHeaders: json.RawMessage(`{}`),
+			ResponseBody:    []byte(`{}`),
+			UserID:          user.ID,
+			Key:             expired.Key,
+		})
+		require.NoError(t, err)
+
+		// Act
+		stale.Fingerprint = util.RandomString(64)
+		stale.StaleBefore = time.Now().Add(time.Minute)
+		tookStale, errStale := store.CreateIdempotencyKey(ctx, stale)
+		expired.Fingerprint = util.RandomString(64)
+		expired.ExpiresAt = time.Now().Add(time.Hour)
+		tookExpired, errExpired := store.CreateIdempotencyKey(ctx, expired)
+
+		// Assert
+		require.NoError(t, errStale)
+		require.Equal(t, stale.Fingerprint, tookStale.Fingerprint)
+		require.NoError(t, errExpired)
+		require.Equal(t, expired.Fingerprint, tookExpired.Fingerprint)
+		require.False(t, tookExpired.StatusCode.Valid)
+		require.Empty(t, tookExpired.ResponseBody)
+	})
+
+	t.Run("PurgeExpired", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		expired := db.CreateIdempotencyKeyParams{
+			UserID:      user.ID,
+			Key:         util.RandomString(16),
+			Fingerprint: util.RandomString(64),
+			ExpiresAt:   time.Now().Add(-time.Hour),
+			StaleBefore: time.Now().Add(-time.Minute),
+		}
+		valid := expired
+		valid.Key = util.RandomString(16)
+		valid.ExpiresAt = time.Now().Add(time.Hour)
+		_, err := store.CreateIdempotencyKey(ctx, expired)
+		require.NoError(t, err)
+		_, err = store.CreateIdempotencyKey(ctx, valid)
+		require.NoError(t, err)
+
+		// Act
+		n, err := store.PurgeExpiredIdempotencyKeys(ctx, time.Now())
+
+		// Assert
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, n, int64(1))
+		_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{UserID: user.ID, Key: expired.Key})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{UserID: user.ID, Key: valid.Key})
+		require.NoError(t, err)
+	})
+}
+
+func testDeleteUserTx(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		other := createUser(t, store)
+		category := createCategory(t, store)
+		receipt, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{
+			StoreName: util.RandomStoreName(),
+			UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		})
+		require.NoError(t, err)
+		_, err = store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:        user.ID,
+			CategoryID:    category.ID,
+			FoodReceiptID: sql.NullInt64{Int64: receipt.ID, Valid: true},
+		})
+		require.NoError(t, err)
+		_, err = store.CreateSession(ctx, db.CreateSessionParams{ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		transfer, err := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: user.ID, ToUserID: other.ID, Amount: 10})
+		require.NoError(t, err)
+		registered, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{
+			StoreName: util.RandomStoreName(),
+			UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		})
+		require.NoError(t, err)
+		key, err := store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			UserID:      user.ID,
+			Key:         util.RandomString(16),
+			Fingerprint: util.RandomString(64),
+			ExpiresAt:   time.Now().Add(time.Hour),
+			StaleBefore: time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		actor := db.AuditActor{UserID: user.ID, SessionID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, ClientIP: "192.0.2.10"}
+		_, err = store.CreateCategoryTx(ctx, util.RandomString(12), actor)
+		require.NoError(t, err)
+		otherActor := db.AuditActor{UserID: other.ID, ClientIP: "192.0.2.11"}
+		otherCategory, err := store.CreateCategoryTx(ctx, util.RandomString(12), otherActor)
+		require.NoError(t, err)
+
+		// Act
+		err = store.DeleteUserTx(ctx, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		expenses, err := store.ListExpenses(ctx, user.ID)
+		require.NoError(t, err)
+		require.Empty(t, expenses)
+		_, err = store.GetFoodReceipt(ctx, receipt.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		// 支出から参照していなくても、ユーザーが登録したレシートは削除する。
+		_, err = store.GetFoodReceipt(ctx, registered.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		sessions, err := store.ListUserSessions(ctx, user.ID)
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+		got, err := store.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.True(t, got.AnonymizedAt.Valid)
+		_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{UserID: user.ID, Key: key.Key})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		// 送金は相手側の履歴として残る。
+		transfers, err := store.ListUserTransfers(ctx, other.ID)
+		require.NoError(t, err)
+		require.Contains(t, transfers, transfer)
+		// 削除した件数のみを、バックグラウンドの処理による操作として記録する。
+		event := findAuditEvent(t, store, db.AuditActionDelete, db.AuditEntityUser, user.ID)
+		require.False(t, event.ActorID.Valid)
+		require.False(t, event.SessionID.Valid)
+		require.JSONEq(t, `{"expenses": 1, "food_receipts": 2, "redacted_audit_events": 1}`, string(event.Before))
+		require.JSONEq(t, "null", string(event.After))
+		// ユーザー自身が操作した監査ログは、行を残して変更内容とアクセス元を消す。
+		redacted, err := store.ListUserAuditEvents(ctx, db.ListUserAuditEventsParams{ActorID: user.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, redacted, 1)
+		require.Equal(t, db.AuditActionCreate, redacted[0].Action)
+		require.False(t, redacted[0].SessionID.Valid)
+		require.Empty(t, redacted[0].ClientIp)
+		require.JSONEq(t, "null", string(redacted[0].Before))
+		require.JSONEq(t, "null", string(redacted[0].After))
+		require.True(t, redacted[0].RedactedAt.Valid)
+		// 他のユーザーの監査ログは変更しない。
+		kept := findAuditEvent(t, store, db.AuditActionCreate, db.AuditEntityCategory, otherCategory.ID)
+		require.Equal(t, otherActor.ClientIP, kept.ClientIp)
+		require.False(t, kept.RedactedAt.Valid)
+		require.NotEqual(t, "null", string(kept.After))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		// Act
+		err := store.DeleteUserTx(ctx, missingID)
+
+		// Assert
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+// 最近の監査ログから、操作と対象が一致するものを返す。
+func findAuditEvent(t *testing.T, store db.Store, action, entityType string, entityID int64) db.AuditEvent {
+	events, err := store.ListAuditEvents(context.Background(), db.ListAuditEventsParams{Limit: 100})
+	require.NoError(t, err)
+	for _, event := range events {
+		if event.Action == action && event.EntityType == entityType && event.EntityID == entityID {
+			return event
+		}
+	}
+	t.Fatalf("audit event [%s %s %d] is not found", action, entityType, entityID)
+	return db.AuditEvent{}
+}
+
+func testAuditEvents(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	newActor := func(t *testing.T) db.AuditActor {
+		return db.AuditActor{
+			UserID:    createUser(t, store).ID,
+			SessionID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+			ClientIP:  "192.0.2.1",
+		}
+	}
+	listEvents := func(t *testing.T, actor db.AuditActor) []db.AuditEvent {
+		events, err := store.ListUserAuditEvents(ctx, db.ListUserAuditEventsParams{ActorID: actor.UserID, Limit: 10})
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("CreateExpenseTx", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		category := createCategory(t, store)
+
+		// Act
+		expense, err := store.CreateExpenseTx(ctx, db.CreateExpenseParams{
+			UserID:     actor.UserID,
+			CategoryID: category.ID,
+			Amount:     1200,
+		}, actor)
+
+		// Assert
+		require.NoError(t, err)
+		events := listEvents(t, actor)
+		require.Len(t, events, 1)
+		event := events[0]
+		require.Equal(t, sql.NullInt64{Int64: actor.UserID, Valid: true}, event.ActorID)
+		require.Equal(t, actor.SessionID, event.SessionID)
+		require.Equal(t, actor.ClientIP, event.ClientIp)
+		require.Equal(t, db.AuditActionCreate, event.Action)
+		require.Equal(t, db.AuditEntityExpense, event.EntityType)
+		require.Equal(t, expense.ID, event.EntityID)
+		require.JSONEq(t, "null", string(event.Before))
+		var after db.Expense
+		require.NoError(t, json.Unmarshal(event.After, &after))
+		require.Equal(t, expense.ID, after.ID)
+		require.Equal(t, int64(1200), after.Amount)
+		require.WithinDuration(t, time.Now(), event.CreatedAt, time.Minute)
+	})
+
+	t.Run("UpdateExpenseTx", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		other := newActor(t)
+		category := createCategory(t, store)
+		expense, err := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:     actor.UserID,
+			CategoryID: category.ID,
+			Amount:     1200,
+			Comment:    sql.NullString{String: "lunch", Valid: true},
+		})
+		require.NoError(t, err)
+		amount := int64(-800)
+		empty := ""
+
+		// Act
+		_, errOther := store.UpdateExpenseTx(ctx, db.UpdateExpenseTxParams{ID: expense.ID, UserID: other.UserID, Version: db.AnyVersion, Amount: &amount}, other)
+		_, errStale := store.UpdateExpenseTx(ctx, db.UpdateExpenseTxParams{ID: expense.ID, UserID: actor.UserID, Version: expense.Version + 1, Amount: &amount}, actor)
+		updated, err := store.UpdateExpenseTx(ctx, db.UpdateExpenseTxParams{ID: expense.ID, UserID: actor.UserID, Version: expense.Version, Amount: &amount}, actor)
+		require.NoError(t, err)
+		cleared, err := store.UpdateExpenseTx(ctx, db.UpdateExpenseTxParams{ID: expense.ID, UserID: actor.UserID, Version: db.AnyVersion, Comment: &empty}, actor)
+		require.NoError(t, err)
+
+		// Assert
+		require.ErrorIs(t, errOther, sql.ErrNoRows)
+		require.ErrorIs(t, errStale, db.ErrVersionMismatch)
+		// 指定しなかった項目は変更しない。
+		require.Equal(t, amount, updated.Amount)
+		require.Equal(t, category.ID, updated.CategoryID)
+		require.Equal(t, expense.Comment, updated.Comment)
+		require.Equal(t, expense.Version+1, updated.Version)
+		// 空のコメントは NULL として保存する。
+		require.False(t, cleared.Comment.Valid)
+		require.Equal(t, amount, cleared.Amount)
+		require.Equal(t, expense.Version+2, cleared.Version)
+
+		events := listEvents(t, actor)
+		require.Len(t, events, 2)
+		event := events[1]
+		require.Equal(t, db.AuditActionUpdate, event.Action)
+		require.Equal(t, db.AuditEntityExpense, event.EntityType)
+		require.Equal(t, expense.ID, event.EntityID)
+		var before, after db.Expense
+		require.NoError(t, json.Unmarshal(event.Before, &before))
+		require.NoError(t, json.Unmarshal(event.After, &after))
+		require.Equal(t, int64(1200), before.Amount)
+		require.Equal(t, amount, after.Amount)
+		require.Empty(t, listEvents(t, other))
+	})
+
+	t.Run("CreateReceiptTx", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		content := createFoodContent(t, store)
+
+		// Act
+		result, err := store.CreateReceiptTx(ctx, db.CreateReceiptTxParams{
+			StoreName:   util.RandomString(8),
+			UserID:      actor.UserID,
+			TotalPrice:  300,
+			ItemsDigest: util.RandomString(64),
+			Contents:    []db.CreateReceiptContentParams{{FoodContentID: content.ID, Amount: 2}},
+		}, actor)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		require.Equal(t, result.FoodReceipt.ID, result.Contents[0].FoodReceiptID)
+		require.Equal(t, sql.NullInt64{Int64: actor.UserID, Valid: true}, result.FoodReceipt.UserID)
+		require.Equal(t, sql.NullInt64{Int64: 300, Valid: true}, result.FoodReceipt.TotalPrice)
+		events := listEvents(t, actor)
+		require.Len(t, events, 1)
+		require.Equal(t, db.AuditEntityReceipt, events[0].EntityType)
+		require.Equal(t, result.FoodReceipt.ID, events[0].EntityID)
+		// created_at のタイムゾーンは実装によって異なるため、JSON で比較する。
+		expected, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(events[0].After))
+	})
+
+	t.Run("CreateReceiptTxWithFoodName", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		existing, err := store.CreateFoodContent(ctx, db.CreateFoodContentParams{Name: util.RandomString(16), Calories: 100})
+		require.NoError(t, err)
+		newName := util.RandomString(16)
+
+		// Act
+		result, err := store.CreateReceiptTx(ctx, db.CreateReceiptTxParams{
+			StoreName: util.RandomString(8),
+			UserID:    actor.UserID,
+			Contents: []db.CreateReceiptContentParams{
+				{FoodName: existing.Name, Amount: 1},
+				{FoodName: newName, Amount: 1},
+			},
+		}, actor)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 2)
+		// 登録済みの食品は作成せずに使う。
+		require.Equal(t, existing.ID, result.Contents[0].FoodContentID)
+		// 未登録の食品は、栄養素を 0 として作成する。
+		created, err := store.GetFoodContent(ctx, result.Contents[1].FoodContentID)
+		require.NoError(t, err)
+		require.Equal(t, newName, created.Name)
+		require.Zero(t, created.Calories)
+	})
+
+	t.Run("CategoryLifecycle", func(t *testing.T) {
+		// Arrange
+		actor := db.AuditActor{UserID: createUser(t, store).ID, ClientIP: "192.0.2.2"}
+		name := util.RandomString(12)
+		newName := util.RandomString(12)
+
+		// Act
+		created, err := store.CreateCategoryTx(ctx, name, actor)
+		require.NoError(t, err)
+		updated, err := store.UpdateCategoryTx(ctx, db.UpdateCategoryParams{ID: created.ID, Name: newName}, actor)
+		require.NoError(t, err)
+		deleted, err := store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: created.ID, Version: updated.Version}, actor)
+		require.NoError(t, err)
+
+		// Assert
+		require.Equal(t, updated.Name, deleted.Name)
+		require.True(t, deleted.DeletedAt.Valid)
+		// 新しい順に返す。
+		events := listEvents(t, actor)
+		require.Len(t, events, 3)
+		require.Equal(t, db.AuditActionDelete, events[0].Action)
+		require.Equal(t, db.AuditActionUpdate, events[1].Action)
+		require.Equal(t, db.AuditActionCreate, events[2].Action)
+		for _, event := range events {
+			require.Equal(t, db.AuditEntityCategory, event.EntityType)
+			require.Equal(t, created.ID, event.EntityID)
+			// APIキーで認証された場合は、セッションIDを記録しない。
+			require.False(t, event.SessionID.Valid)
+		}
+
+		var before, after db.Category
+		require.NoError(t, json.Unmarshal(events[1].Before, &before))
+		require.NoError(t, json.Unmarshal(events[1].After, &after))
+		require.Equal(t, created, before)
+		require.Equal(t, updated, after)
+		require.NoError(t, json.Unmarshal(events[0].Before, &before))
+		require.NoError(t, json.Unmarshal(events[0].After, &after))
+		require.Equal(t, updated, before)
+		// 削除後の値として、ゴミ箱に移した行を記録する。
+		require.Equal(t, deleted.ID, after.ID)
+		require.True(t, after.DeletedAt.Valid)
+	})
+
+	t.Run("CreateTransferTx", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		from, err := store.GetUserByID(ctx, actor.UserID)
+		require.NoError(t, err)
+		to := createUser(t, store)
+
+		// Act
+		transfer, err := store.CreateTransferTx(ctx, db.CreateTransferParams{
+			FromUserID: from.ID,
+			ToUserID:   to.ID,
+			Amount:     300,
+		}, actor)
+
+		// Assert
+		require.NoError(t, err)
+		gotFrom, err := store.GetUserByID(ctx, from.ID)
+		require.NoError(t, err)
+		require.Equal(t, from.Balance-300, gotFrom.Balance)
+		gotTo, err := store.GetUserByID(ctx, to.ID)
+		require.NoError(t, err)
+		require.Equal(t, to.Balance+300, gotTo.Balance)
+		event := findAuditEvent(t, store, db.AuditActionCreate, db.AuditEntityTransfer, transfer.ID)
+		require.Equal(t, sql.NullInt64{Int64: actor.UserID, Valid: true}, event.ActorID)
+		require.JSONEq(t, "null", string(event.Before))
+		var after db.Transfer
+		require.NoError(t, json.Unmarshal(event.After, &after))
+		require.Equal(t, transfer.ID, after.ID)
+		require.Equal(t, int64(300), after.Amount)
+	})
+
+	t.Run("CreateTransferTxToDeletedUser", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		from, err := store.GetUserByID(ctx, actor.UserID)
+		require.NoError(t, err)
+		to := createUser(t, store)
+		require.NoError(t, store.DeleteUserTx(ctx, to.ID))
+
+		// Act
+		_, err = store.CreateTransferTx(ctx, db.CreateTransferParams{
+			FromUserID: from.ID,
+			ToUserID:   to.ID,
+			Amount:     300,
+		}, actor)
+
+		// Assert
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		// 送金と残高の変更は取り消される。
+		gotFrom, err := store.GetUserByID(ctx, from.ID)
+		require.NoError(t, err)
+		require.Equal(t, from.Balance, gotFrom.Balance)
+		transfers, err := store.ListUserTransfers(ctx, from.ID)
+		require.NoError(t, err)
+		require.Empty(t, transfers)
+		require.Empty(t, listEvents(t, actor))
+	})
+
+	t.Run("AdjustBalanceTx", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+		user, err := store.GetUserByID(ctx, actor.UserID)
+		require.NoError(t, err)
+
+		// Act
+		balance, err := store.AdjustBalanceTx(ctx, db.AdjustBalanceTxParams{UserID: user.ID, Amount: -500}, actor)
+		_, errMissing := store.AdjustBalanceTx(ctx, db.AdjustBalanceTxParams{UserID: missingID, Amount: 1}, actor)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, user.Balance-500, balance)
+		require.ErrorIs(t, errMissing, sql.ErrNoRows)
+		events := listEvents(t, actor)
+		require.Len(t, events, 1)
+		event := events[0]
+		require.Equal(t, db.AuditActionUpdate, event.Action)
+		require.Equal(t, db.AuditEntityBalance, event.EntityType)
+		require.Equal(t, user.ID, event.EntityID)
+		require.JSONEq(t, fmt.Sprintf(`{"balance": %d}`, user.Balance), string(event.Before))
+		require.JSONEq(t, fmt.Sprintf(`{"balance": %d}`, balance), string(event.After))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		// Arrange
+		actor := newActor(t)
+
+		// Act
+		_, errUpdate := store.UpdateCategoryTx(ctx, db.UpdateCategoryParams{ID: missingID, Name: util.RandomString(12)}, actor)
+		_, errDelete := store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: missingID}, actor)
+
+		// Assert
+		require.ErrorIs(t, errUpdate, sql.ErrNoRows)
+		require.ErrorIs(t, errDelete, sql.ErrNoRows)
+		require.Empty(t, listEvents(t, actor))
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		// 存在しないユーザーを操作者にすると、監査ログの記録に失敗する。
+		actor := db.AuditActor{UserID: missingID, ClientIP: "192.0.2.3"}
+
+		// Act
+		_, errExpense := store.CreateExpenseTx(ctx, db.CreateExpenseParams{
+			UserID:     user.ID,
+			CategoryID: category.ID,
+			Amount:     1200,
+		}, actor)
+		_, errCategory := store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: category.ID}, actor)
+
+		// Assert
+		requireConstraintError(t, errExpense, db.ErrForeignKeyViolation)
+		requireConstraintError(t, errCategory, db.ErrForeignKeyViolation)
+		// 監査ログを記録できなかった変更は取り消される。
+		expenses, err := store.ListExpenses(ctx, user.ID)
+		require.NoError(t, err)
+		require.Empty(t, expenses)
+		categories, err := store.ListCategories(ctx)
+		require.NoError(t, err)
+		require.Contains(t, categories, category)
+	})
+
+	t.Run("ListAuditEvents", func(t *testing.T) {
+		// Arrange
+		first := newActor(t)
+		second := newActor(t)
+		_, err := store.CreateCategoryTx(ctx, util.RandomString(12), first)
+		require.NoError(t, err)
+		_, err = store.CreateCategoryTx(ctx, util.RandomString(12), second)
+		require.NoError(t, err)
+
+		// Act
+		events, err := store.ListAuditEvents(ctx, db.ListAuditEventsParams{Limit: 2})
+		require.NoError(t, err)
+		paged, err := store.ListAuditEvents(ctx, db.ListAuditEventsParams{Limit: 1, Offset: 1})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, second.UserID, events[0].ActorID.Int64)
+		require.Equal(t, first.UserID, events[1].ActorID.Int64)
+		require.Len(t, paged, 1)
+		require.Equal(t, events[1].ID, paged[0].ID)
+	})
+}
+
+func testTrash(t *testing.T, store db.Store) {
+	ctx := context.Background()
+
+	createExpense := func(t *testing.T, user db.User, receiptID sql.NullInt64) db.Expense {
+		expense, err := store.CreateExpense(ctx, db.CreateExpenseParams{
+			UserID:        user.ID,
+			CategoryID:    createCategory(t, store).ID,
+			Amount:        util.RandomExpense(),
+			FoodReceiptID: receiptID,
+		})
+		require.NoError(t, err)
+		return expense
+	}
+	createReceipt := func(t *testing.T, user db.User) db.FoodReceipt {
+		result, err := store.CreateReceiptTx(ctx, db.CreateReceiptTxParams{
+			StoreName: util.RandomString(8),
+			UserID:    user.ID,
+			Contents:  []db.CreateReceiptContentParams{{FoodContentID: createFoodContent(t, store).ID, Amount: 1}},
+		}, db.AuditActor{UserID: user.ID})
+		require.NoError(t, err)
+		return result.FoodReceipt
+	}
+	expenseIDs := func(t *testing.T, userID int64) []int64 {
+		rows, err := store.ListExpenses(ctx, userID)
+		require.NoError(t, err)
+		ids := []int64{}
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		return ids
+	}
+
+	t.Run("Expense", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		other := createUser(t, store)
+		expense := createExpense(t, user, sql.NullInt64{})
+		actor := db.AuditActor{UserID: user.ID}
+
+		// Act
+		_, errOther := store.DeleteExpenseTx(ctx, db.SoftDeleteExpenseParams{ID: expense.ID, UserID: other.ID}, actor)
+		_, errNotDeleted := store.RestoreExpenseTx(ctx, db.RestoreExpenseParams{ID: expense.ID, UserID: user.ID}, actor)
+		_, errStale := store.DeleteExpenseTx(ctx, db.SoftDeleteExpenseParams{ID: expense.ID, UserID: user.ID, Version: expense.Version + 1}, actor)
+		deleted, err := store.DeleteExpenseTx(ctx, db.SoftDeleteExpenseParams{ID: expense.ID, UserID: user.ID, Version: expense.Version}, actor)
+		require.NoError(t, err)
+		_, errTwice := store.DeleteExpenseTx(ctx, db.SoftDeleteExpenseParams{ID: expense.ID, UserID: user.ID}, actor)
+		listed := expenseIDs(t, user.ID)
+		trash, err := store.ListDeletedExpenses(ctx, user.ID)
+		require.NoError(t, err)
+		exported, err := store.ListExportExpenses(ctx, user.ID)
+		require.NoError(t, err)
+		restored, err := store.RestoreExpenseTx(ctx, db.RestoreExpenseParams{ID: expense.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+
+		// Assert
+		require.ErrorIs(t, errOther, sql.ErrNoRows)
+		require.ErrorIs(t, errNotDeleted, sql.ErrNoRows)
+		require.ErrorIs(t, errStale, db.ErrVersionMismatch)
+		require.ErrorIs(t, errTwice, sql.ErrNoRows)
+		require.True(t, deleted.DeletedAt.Valid)
+		require.Equal(t, expense.Version+1, deleted.Version)
+		require.WithinDuration(t, time.Now(), deleted.DeletedAt.Time, time.Minute)
+		require.NotContains(t, listed, expense.ID)
+		require.Len(t, trash, 1)
+		require.Equal(t, expense.ID, trash[0].ID)
+		// エクスポートには、ゴミ箱にある支出も含める。
+		require.Len(t, exported, 1)
+		require.Equal(t, expense.ID, exported[0].ID)
+		require.Equal(t, deleted.Version, exported[0].Version)
+		require.True(t, exported[0].DeletedAt.Valid)
+		require.False(t, restored.DeletedAt.Valid)
+		require.Contains(t, expenseIDs(t, user.ID), expense.ID)
+
+		events, err := store.ListUserAuditEvents(ctx, db.ListUserAuditEventsParams{ActorID: user.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, db.AuditActionRestore, events[0].Action)
+		require.JSONEq(t, "null", string(events[0].Before))
+		require.Equal(t, db.AuditActionDelete, events[1].Action)
+		require.Equal(t, db.AuditEntityExpense, events[1].EntityType)
+	})
+
+	t.Run("Receipt", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		other := createUser(t, store)
+		receipt := createReceipt(t, user)
+		// 他のユーザーの支出から参照していても、レシートを登録したユーザーのものとする。
+		createExpense(t, other, sql.NullInt64{Int64: receipt.ID, Valid: true})
+		actor := db.AuditActor{UserID: user.ID}
+
+		// Act
+		// レシートを登録していないユーザーは削除できない。
+		_, errOther := store.DeleteReceiptTx(ctx, db.SoftDeleteFoodReceiptParams{ID: receipt.ID, UserID: other.ID}, actor)
+		_, errStale := store.DeleteReceiptTx(ctx, db.SoftDeleteFoodReceiptParams{ID: receipt.ID, UserID: user.ID, Version: receipt.Version + 1}, actor)
+		deleted, err := store.DeleteReceiptTx(ctx, db.SoftDeleteFoodReceiptParams{ID: receipt.ID, UserID: user.ID, Version: receipt.Version}, actor)
+		require.NoError(t, err)
+		_, errGet := store.GetFoodReceipt(ctx, receipt.ID)
+		contents, err := store.ListUserFoodReceiptContents(ctx, user.ID)
+		require.NoError(t, err)
+		trash, err := store.ListDeletedFoodReceipts(ctx, user.ID)
+		require.NoError(t, err)
+		otherTrash, err := store.ListDeletedFoodReceipts(ctx, other.ID)
+		require.NoError(t, err)
+		_, errRestoreOther := store.RestoreReceiptTx(ctx, db.RestoreFoodReceiptParams{ID: receipt.ID, UserID: other.ID}, actor)
+		restored, err := store.RestoreReceiptTx(ctx, db.RestoreFoodReceiptParams{ID: receipt.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+
+		// Assert
+		require.ErrorIs(t, errOther, sql.ErrNoRows)
+		require.ErrorIs(t, errStale, db.ErrVersionMismatch)
+		require.True(t, deleted.DeletedAt.Valid)
+		require.Equal(t, receipt.Version+1, deleted.Version)
+		require.ErrorIs(t, errGet, sql.ErrNoRows)
+		// ゴミ箱にあるレシートも、エクスポートに含める。
+		require.Len(t, contents, 1)
+		require.Equal(t, receipt.ID, contents[0].FoodReceiptID)
+		require.True(t, contents[0].DeletedAt.Valid)
+		require.Len(t, trash, 1)
+		require.Equal(t, receipt.ID, trash[0].ID)
+		require.Empty(t, otherTrash)
+		require.ErrorIs(t, errRestoreOther, sql.ErrNoRows)
+		require.False(t, restored.DeletedAt.Valid)
+		got, err := store.GetFoodReceipt(ctx, receipt.ID)
+		require.NoError(t, err)
+		require.Equal(t, receipt.StoreName, got.StoreName)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		category := createCategory(t, store)
+		actor := db.AuditActor{UserID: user.ID}
+		_, err := store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: category.ID}, actor)
+		require.NoError(t, err)
+
+		// Act
+		// ゴミ箱にあるカテゴリーの名前も使えない。
+		_, errName := store.CreateCategory(ctx, category.Name)
+		trash, err := store.ListDeletedCategories(ctx)
+		require.NoError(t, err)
+		restored, err := store.RestoreCategoryTx(ctx, category.ID, actor)
+		require.NoError(t, err)
+		_, errTwice := store.RestoreCategoryTx(ctx, category.ID, actor)
+
+		// Assert
+		requireConstraintError(t, errName, db.ErrUniqueViolation)
+		found := false
+		for _, c := range trash {
+			if c.ID == category.ID {
+				found = true
+			}
+		}
+		require.True(t, found)
+		require.Equal(t, category.Name, restored.Name)
+		require.False(t, restored.DeletedAt.Valid)
+		// 削除と復元のたびに version が増える。
+		require.Equal(t, category.Version+2, restored.Version)
+		require.ErrorIs(t, errTwice, sql.ErrNoRows)
+		categories, err := store.ListCategories(ctx)
+		require.NoError(t, err)
+		require.Contains(t, categories, restored)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, store)
+		actor := db.AuditActor{UserID: user.ID}
+		expense := createExpense(t, user, sql.NullInt64{})
+		kept := createExpense(t, user, sql.NullInt64{})
+		_, err := store.DeleteExpenseTx(ctx, db.SoftDeleteExpenseParams{ID: expense.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+
+		// 支出を完全に削除すると、どの支出からも参照されないレシートになる。
+		receipt := createReceipt(t, user)
+		withReceipt := createExpense(t, user, sql.NullInt64{Int64: receipt.ID, Valid: true})
+		_, err = store.DeleteReceiptTx(ctx, db.SoftDeleteFoodReceiptParams{ID: receipt.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+		_, err = store.DeleteExpenseTx(ctx, db.SoftDeleteExpenseParams{ID: withReceipt.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+
+		// ゴミ箱にない支出から参照されているレシートは残す。
+		referenced := createReceipt(t, user)
+		referencing := createExpense(t, user, sql.NullInt64{Int64: referenced.ID, Valid: true})
+		_, err = store.DeleteReceiptTx(ctx, db.SoftDeleteFoodReceiptParams{ID: referenced.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+
+		category := createCategory(t, store)
+		_, err = store.DeleteCategoryTx(ctx, db.DeleteCategoryParams{ID: category.ID}, actor)
+		require.NoError(t, err)
+
+		// Act
+		// 保持期間内のものは削除しない。
+		none, err := store.PurgeTrashTx(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		result, err := store.PurgeTrashTx(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		// Assert
+		require.Equal(t, db.PurgeTrashTxResult{}, none)
+		require.GreaterOrEqual(t, result.Expenses, int64(2))
+		require.GreaterOrEqual(t, result.FoodReceipts, int64(1))
+		require.GreaterOrEqual(t, result.Categories, int64(1))
+
+		trash, err := store.ListDeletedExpenses(ctx, user.ID)
+		require.NoError(t, err)
+		require.Empty(t, trash)
+		require.ElementsMatch(t, []int64{kept.ID, referencing.ID}, expenseIDs(t, user.ID))
+		_, err = store.RestoreReceiptTx(ctx, db.RestoreFoodReceiptParams{ID: receipt.ID, UserID: user.ID}, actor)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.RestoreReceiptTx(ctx, db.RestoreFoodReceiptParams{ID: referenced.ID, UserID: user.ID}, actor)
+		require.NoError(t, err)
+		_, err = store.RestoreCategoryTx(ctx, category.ID, actor)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		// 完全に削除した値は監査ログに残さない。
+		purged := []struct {
+			entityType string
+			id         int64
+		}{
+			{db.AuditEntityExpense, expense.ID},
+			{db.AuditEntityExpense, withReceipt.ID},
+			{db.AuditEntityReceipt, receipt.ID},
+			{db.AuditEntityCategory, category.ID},
+		}
+		for _, p := range purged {
+			event := findAuditEvent(t, store, db.AuditActionPurge, p.entityType, p.id)
+			require.False(t, event.ActorID.Valid)
+			require.JSONEq(t, "null", string(event.Before))
+			require.JSONEq(t, "null", string(event.After))
+		}
+	})
+}
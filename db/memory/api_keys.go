@@ -0,0 +1,126 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// scopes のスライスを共有しないように複製する。
+func copyAPIKey(key db.ApiKey) db.ApiKey {
+	key.Scopes = append([]string{}, key.Scopes...)
+	return key
+}
+
+func (store *Store) CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+	var key db.ApiKey
+	err := store.with(func(t *tables) error {
+		for _, k := range t.apiKeys {
+			if k.KeyHash == arg.KeyHash {
+				return uniqueViolation("api_keys", "api_keys_key_hash_key")
+			}
+		}
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("api_keys", "api_keys_user_id_fkey")
+		}
+		key = db.ApiKey{
+			ID:        t.nextID("api_keys"),
+			UserID:    arg.UserID,
+			Name:      arg.Name,
+			KeyPrefix: arg.KeyPrefix,
+			KeyHash:   arg.KeyHash,
+			Scopes:    append([]string{}, arg.Scopes...),
+			ExpiresAt: nullTimestamp(arg.ExpiresAt),
+			CreatedAt: now(),
+		}
+		t.apiKeys = append(t.apiKeys, key)
+		return nil
+	})
+	return copyAPIKey(key), err
+}
+
+// 無効化されたユーザーのAPIキーは存在しないものとして扱う。
+func (store *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (db.GetAPIKeyByHashRow, error) {
+	var row db.GetAPIKeyByHashRow
+	err := store.with(func(t *tables) error {
+		for _, k := range t.apiKeys {
+			if k.KeyHash != keyHash {
+				continue
+			}
+			i := t.userIndex(k.UserID)
+			if i < 0 || t.users[i].DisabledAt.Valid {
+				return sql.ErrNoRows
+			}
+			key := copyAPIKey(k)
+			row = db.GetAPIKeyByHashRow{
+				ID:         key.ID,
+				UserID:     key.UserID,
+				Name:       key.Name,
+				KeyPrefix:  key.KeyPrefix,
+				KeyHash:    key.KeyHash,
+				Scopes:     key.Scopes,
+				ExpiresAt:  key.ExpiresAt,
+				LastUsedAt: key.LastUsedAt,
+				RevokedAt:  key.RevokedAt,
+				CreatedAt:  key.CreatedAt,
+				Locale:     t.users[i].Locale,
+			}
+			return nil
+		}
+		return sql.ErrNoRows
+	})
+	return row, err
+}
+
+func (store *Store) ListAPIKeys(ctx context.Context, userID int64) ([]db.ApiKey, error) {
+	keys := []db.ApiKey{}
+	err := store.with(func(t *tables) error {
+		for _, key := range t.apiKeys {
+			if key.UserID == userID && !key.RevokedAt.Valid {
+				keys = append(keys, copyAPIKey(key))
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func (store *Store) RevokeAPIKey(ctx context.Context, arg db.RevokeAPIKeyParams) (db.ApiKey, error) {
+	var key db.ApiKey
+	err := store.with(func(t *tables) error {
+		for i, k := range t.apiKeys {
+			if k.ID == arg.ID && k.UserID == arg.UserID && !k.RevokedAt.Valid {
+				t.apiKeys[i].RevokedAt = sql.NullTime{Time: now(), Valid: true}
+				key = copyAPIKey(t.apiKeys[i])
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return key, err
+}
+
+func (store *Store) UpdateAPIKeyLastUsed(ctx context.Context, id int64) error {
+	return store.with(func(t *tables) error {
+		for i, key := range t.apiKeys {
+			if key.ID == id {
+				t.apiKeys[i].LastUsedAt = sql.NullTime{Time: now(), Valid: true}
+			}
+		}
+		return nil
+	})
+}
+
+func (store *Store) DeleteUserAPIKeys(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		keys := t.apiKeys[:0:0]
+		for _, key := range t.apiKeys {
+			if key.UserID != userID {
+				keys = append(keys, key)
+			}
+		}
+		t.apiKeys = keys
+		return nil
+	})
+}
@@ -0,0 +1,100 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// before と after のスライスを共有しないように複製する。
+func copyAuditEvent(event db.AuditEvent) db.AuditEvent {
+	event.Before = append(json.RawMessage{}, event.Before...)
+	event.After = append(json.RawMessage{}, event.After...)
+	return event
+}
+
+func (store *Store) CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+	var event db.AuditEvent
+	err := store.with(func(t *tables) error {
+		if arg.ActorID.Valid && !t.userExists(arg.ActorID.Int64) {
+			return foreignKeyViolation("audit_events", "audit_events_actor_id_fkey")
+		}
+		event = copyAuditEvent(db.AuditEvent{
+			ID:         t.nextID("audit_events"),
+			ActorID:    arg.ActorID,
+			SessionID:  arg.SessionID,
+			ClientIp:   arg.ClientIp,
+			Action:     arg.Action,
+			EntityType: arg.EntityType,
+			EntityID:   arg.EntityID,
+			Before:     arg.Before,
+			After:      arg.After,
+			CreatedAt:  now(),
+		})
+		t.auditEvents = append(t.auditEvents, event)
+		return nil
+	})
+	return copyAuditEvent(event), err
+}
+
+// 新しい順に、条件に合う監査ログを返す。
+func (t *tables) listAuditEvents(match func(db.AuditEvent) bool, limit, offset int32) []db.AuditEvent {
+	matched := []db.AuditEvent{}
+	for i := len(t.auditEvents) - 1; i >= 0; i-- {
+		if match(t.auditEvents[i]) {
+			matched = append(matched, t.auditEvents[i])
+		}
+	}
+	start, end := page(len(matched), limit, offset)
+
+	events := []db.AuditEvent{}
+	for _, event := range matched[start:end] {
+		events = append(events, copyAuditEvent(event))
+	}
+	return events
+}
+
+func (store *Store) ListAuditEvents(ctx context.Context, arg db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	var events []db.AuditEvent
+	err := store.with(func(t *tables) error {
+		events = t.listAuditEvents(func(db.AuditEvent) bool { return true }, arg.Limit, arg.Offset)
+		return nil
+	})
+	return events, err
+}
+
+func (store *Store) ListUserAuditEvents(ctx context.Context, arg db.ListUserAuditEventsParams) ([]db.AuditEvent, error) {
+	var events []db.AuditEvent
+	err := store.with(func(t *tables) error {
+		match := func(event db.AuditEvent) bool {
+			return event.ActorID.Valid && event.ActorID.Int64 == arg.ActorID
+		}
+		events = t.listAuditEvents(match, arg.Limit, arg.Offset)
+		return nil
+	})
+	return events, err
+}
+
+func (store *Store) RedactUserAuditEvents(ctx context.Context, actorID int64) (int64, error) {
+	var n int64
+	err := store.with(func(t *tables) error {
+		redactedAt := sql.NullTime{Time: now(), Valid: true}
+		for i, event := range t.auditEvents {
+			if !event.ActorID.Valid || event.ActorID.Int64 != actorID || event.RedactedAt.Valid {
+				continue
+			}
+			event.SessionID = uuid.NullUUID{}
+			event.ClientIp = ""
+			event.Before = json.RawMessage("null")
+			event.After = json.RawMessage("null")
+			event.RedactedAt = redactedAt
+			t.auditEvents[i] = event
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
@@ -0,0 +1,192 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (t *tables) categoryIndex(id int64) int {
+	for i, category := range t.categories {
+		if category.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ゴミ箱にないカテゴリーの位置を返す。
+func (t *tables) activeCategoryIndex(id int64) int {
+	i := t.categoryIndex(id)
+	if i < 0 || t.categories[i].DeletedAt.Valid {
+		return -1
+	}
+	return i
+}
+
+// name の一意制約を確かめる。id のカテゴリー自身は除く。
+// ゴミ箱にあるカテゴリーも名前を使っているものとして扱う。
+func (t *tables) checkCategoryName(name string, id int64) error {
+	for _, category := range t.categories {
+		if category.Name == name && category.ID != id {
+			return uniqueViolation("categories", "categories_name_key")
+		}
+	}
+	return nil
+}
+
+func (store *Store) CreateCategory(ctx context.Context, name string) (db.Category, error) {
+	var category db.Category
+	err := store.with(func(t *tables) error {
+		if err := t.checkCategoryName(name, 0); err != nil {
+			return err
+		}
+		category = db.Category{
+			ID:      t.nextID("categories"),
+			Name:    name,
+			Version: 1,
+		}
+		t.categories = append(t.categories, category)
+		return nil
+	})
+	return category, err
+}
+
+// メモリ上では行ロックは不要なため、取得するのみ。
+func (store *Store) GetCategoryForUpdate(ctx context.Context, id int64) (db.Category, error) {
+	var category db.Category
+	err := store.with(func(t *tables) error {
+		i := t.activeCategoryIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		category = t.categories[i]
+		return nil
+	})
+	return category, err
+}
+
+func (store *Store) ListCategories(ctx context.Context) ([]db.Category, error) {
+	categories := []db.Category{}
+	err := store.with(func(t *tables) error {
+		for _, category := range t.categories {
+			if !category.DeletedAt.Valid {
+				categories = append(categories, category)
+			}
+		}
+		return nil
+	})
+	return categories, err
+}
+
+func (store *Store) UpdateCategory(ctx context.Context, arg db.UpdateCategoryParams) (db.Category, error) {
+	var category db.Category
+	err := store.with(func(t *tables) error {
+		i := t.activeCategoryIndex(arg.ID)
+		if i < 0 || t.categories[i].Version != arg.Version {
+			return sql.ErrNoRows
+		}
+		if err := t.checkCategoryName(arg.Name, arg.ID); err != nil {
+			return err
+		}
+		t.categories[i].Name = arg.Name
+		t.categories[i].Version++
+		category = t.categories[i]
+		return nil
+	})
+	return category, err
+}
+
+// ゴミ箱に移す。完全に削除するのは PurgeDeletedCategories のみ。
+func (store *Store) DeleteCategory(ctx context.Context, arg db.DeleteCategoryParams) (db.Category, error) {
+	var category db.Category
+	err := store.with(func(t *tables) error {
+		i := t.activeCategoryIndex(arg.ID)
+		if i < 0 || t.categories[i].Version != arg.Version {
+			return sql.ErrNoRows
+		}
+		t.categories[i].DeletedAt = sql.NullTime{Time: now(), Valid: true}
+		t.categories[i].Version++
+		category = t.categories[i]
+		return nil
+	})
+	return category, err
+}
+
+// ゴミ箱にある支出は数えない。
+func (store *Store) CategoryHasExpenses(ctx context.Context, categoryID int64) (bool, error) {
+	var exists bool
+	err := store.with(func(t *tables) error {
+		for _, expense := range t.expenses {
+			if expense.CategoryID == categoryID && !expense.DeletedAt.Valid {
+				exists = true
+				break
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+func (store *Store) RestoreCategory(ctx context.Context, id int64) (db.Category, error) {
+	var category db.Category
+	err := store.with(func(t *tables) error {
+		i := t.categoryIndex(id)
+		if i < 0 || !t.categories[i].DeletedAt.Valid {
+			return sql.ErrNoRows
+		}
+		t.categories[i].DeletedAt = sql.NullTime{}
+		t.categories[i].Version++
+		category = t.categories[i]
+		return nil
+	})
+	return category, err
+}
+
+func (store *Store) ListDeletedCategories(ctx context.Context) ([]db.Category, error) {
+	categories := []db.Category{}
+	err := store.with(func(t *tables) error {
+		for _, category := range t.categories {
+			if category.DeletedAt.Valid {
+				categories = append(categories, category)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(categories, func(i, j int) bool {
+		return deletedBefore(categories[j].DeletedAt, categories[j].ID, categories[i].DeletedAt, categories[i].ID)
+	})
+	return categories, err
+}
+
+// 支出から参照されているカテゴリーは残す。
+func (store *Store) PurgeDeletedCategories(ctx context.Context, before time.Time) ([]int64, error) {
+	ids := []int64{}
+	err := store.with(func(t *tables) error {
+		categories := t.categories[:0:0]
+		for _, category := range t.categories {
+			if category.DeletedAt.Valid && category.DeletedAt.Time.Before(before) && !t.categoryReferenced(category.ID) {
+				ids = append(ids, category.ID)
+				continue
+			}
+			categories = append(categories, category)
+		}
+		t.categories = categories
+		return nil
+	})
+	return ids, err
+}
+
+// いずれかの支出から参照されているカテゴリーか。
+// ゴミ箱にある支出も含める。
+func (t *tables) categoryReferenced(id int64) bool {
+	for _, expense := range t.expenses {
+		if expense.CategoryID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (store *Store) CreateEmailChangeToken(ctx context.Context, arg db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+	var token db.EmailChangeToken
+	err := store.with(func(t *tables) error {
+		for _, tk := range t.emailChangeTokens {
+			if tk.TokenHash == arg.TokenHash {
+				return uniqueViolation("email_change_tokens", "email_change_tokens_token_hash_key")
+			}
+		}
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("email_change_tokens", "email_change_tokens_user_id_fkey")
+		}
+		token = db.EmailChangeToken{
+			ID:        t.nextID("email_change_tokens"),
+			UserID:    arg.UserID,
+			NewEmail:  arg.NewEmail,
+			TokenHash: arg.TokenHash,
+			ExpiresAt: timestamp(arg.ExpiresAt),
+			CreatedAt: now(),
+		}
+		t.emailChangeTokens = append(t.emailChangeTokens, token)
+		return nil
+	})
+	return token, err
+}
+
+// 未使用で有効期限内のトークンのみを使用済みにする。
+func (store *Store) UseEmailChangeToken(ctx context.Context, tokenHash string) (db.EmailChangeToken, error) {
+	var token db.EmailChangeToken
+	err := store.with(func(t *tables) error {
+		current := now()
+		for i, tk := range t.emailChangeTokens {
+			if tk.TokenHash == tokenHash && !tk.UsedAt.Valid && tk.ExpiresAt.After(current) {
+				t.emailChangeTokens[i].UsedAt = sql.NullTime{Time: current, Valid: true}
+				token = t.emailChangeTokens[i]
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return token, err
+}
+
+func (store *Store) DeleteUserEmailChangeTokens(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		tokens := t.emailChangeTokens[:0:0]
+		for _, token := range t.emailChangeTokens {
+			if token.UserID != userID {
+				tokens = append(tokens, token)
+			}
+		}
+		t.emailChangeTokens = tokens
+		return nil
+	})
+}
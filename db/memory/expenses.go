@@ -0,0 +1,287 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// ユーザーの支出の位置を返す。
+func (t *tables) userExpenseIndex(id, userID int64) int {
+	for i, expense := range t.expenses {
+		if expense.ID == id && expense.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (store *Store) CreateExpense(ctx context.Context, arg db.CreateExpenseParams) (db.Expense, error) {
+	var expense db.Expense
+	err := store.with(func(t *tables) error {
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("expenses", "expenses_user_id_fkey")
+		}
+		if t.categoryIndex(arg.CategoryID) < 0 {
+			return foreignKeyViolation("expenses", "expenses_category_id_fkey")
+		}
+		if arg.FoodReceiptID.Valid && t.foodReceiptIndex(arg.FoodReceiptID.Int64) < 0 {
+			return foreignKeyViolation("expenses", "expenses_food_receipt_id_fkey")
+		}
+		expense = db.Expense{
+			ID:            t.nextID("expenses"),
+			UserID:        arg.UserID,
+			CategoryID:    arg.CategoryID,
+			Amount:        arg.Amount,
+			FoodReceiptID: arg.FoodReceiptID,
+			Comment:       arg.Comment,
+			CreatedAt:     now(),
+			Version:       1,
+		}
+		t.expenses = append(t.expenses, expense)
+		return nil
+	})
+	return expense, err
+}
+
+// レシートに紐づかない支出の店名は空文字にする。
+func (store *Store) ListExpenses(ctx context.Context, userID int64) ([]db.ListExpensesRow, error) {
+	rows := []db.ListExpensesRow{}
+	err := store.with(func(t *tables) error {
+		for _, expense := range t.expenses {
+			if expense.UserID != userID || expense.DeletedAt.Valid {
+				continue
+			}
+			storeName := ""
+			if expense.FoodReceiptID.Valid {
+				if i := t.foodReceiptIndex(expense.FoodReceiptID.Int64); i >= 0 {
+					storeName = t.foodReceipts[i].StoreName
+				}
+			}
+			rows = append(rows, db.ListExpensesRow{
+				ID:         expense.ID,
+				UserID:     expense.UserID,
+				CategoryID: expense.CategoryID,
+				Amount:     expense.Amount,
+				StoreName:  storeName,
+				Comment:    expense.Comment,
+				CreatedAt:  expense.CreatedAt,
+				Version:    expense.Version,
+			})
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// ユーザーの全ての支出を返す（エクスポート用）。ゴミ箱にある支出も含める。
+func (store *Store) ListExportExpenses(ctx context.Context, userID int64) ([]db.ListExportExpensesRow, error) {
+	rows := []db.ListExportExpensesRow{}
+	err := store.with(func(t *tables) error {
+		for _, expense := range t.expenses {
+			if expense.UserID != userID {
+				continue
+			}
+			storeName := ""
+			if expense.FoodReceiptID.Valid {
+				if i := t.foodReceiptIndex(expense.FoodReceiptID.Int64); i >= 0 {
+					storeName = t.foodReceipts[i].StoreName
+				}
+			}
+			rows = append(rows, db.ListExportExpensesRow{
+				ID:         expense.ID,
+				UserID:     expense.UserID,
+				CategoryID: expense.CategoryID,
+				Amount:     expense.Amount,
+				StoreName:  storeName,
+				Comment:    expense.Comment,
+				CreatedAt:  expense.CreatedAt,
+				Version:    expense.Version,
+				DeletedAt:  expense.DeletedAt,
+			})
+		}
+		return nil
+	})
+	return rows, err
+}
+
+func (store *Store) DeleteUserExpenses(ctx context.Context, userID int64) (int64, error) {
+	var n int64
+	err := store.with(func(t *tables) error {
+		expenses := t.expenses[:0:0]
+		for _, expense := range t.expenses {
+			if expense.UserID == userID {
+				n++
+				continue
+			}
+			expenses = append(expenses, expense)
+		}
+		t.expenses = expenses
+		return nil
+	})
+	return n, err
+}
+
+// メモリ上では行ロックは不要なため、取得するのみ。
+func (store *Store) GetExpenseForUpdate(ctx context.Context, arg db.GetExpenseForUpdateParams) (db.Expense, error) {
+	var expense db.Expense
+	err := store.with(func(t *tables) error {
+		i := t.userExpenseIndex(arg.ID, arg.UserID)
+		if i < 0 || t.expenses[i].DeletedAt.Valid {
+			return sql.ErrNoRows
+		}
+		expense = t.expenses[i]
+		return nil
+	})
+	return expense, err
+}
+
+func (store *Store) UpdateExpense(ctx context.Context, arg db.UpdateExpenseParams) (db.Expense, error) {
+	var expense db.Expense
+	err := store.with(func(t *tables) error {
+		i := t.userExpenseIndex(arg.ID, arg.UserID)
+		if i < 0 || t.expenses[i].DeletedAt.Valid || t.expenses[i].Version != arg.Version {
+			return sql.ErrNoRows
+		}
+		if t.categoryIndex(arg.CategoryID) < 0 {
+			return foreignKeyViolation("expenses", "expenses_category_id_fkey")
+		}
+		t.expenses[i].CategoryID = arg.CategoryID
+		t.expenses[i].Amount = arg.Amount
+		t.expenses[i].Comment = arg.Comment
+		t.expenses[i].Version++
+		expense = t.expenses[i]
+		return nil
+	})
+	return expense, err
+}
+
+func (store *Store) SoftDeleteExpense(ctx context.Context, arg db.SoftDeleteExpenseParams) (db.Expense, error) {
+	var expense db.Expense
+	err := store.with(func(t *tables) error {
+		i := t.userExpenseIndex(arg.ID, arg.UserID)
+		if i < 0 || t.expenses[i].DeletedAt.Valid || t.expenses[i].Version != arg.Version {
+			return sql.ErrNoRows
+		}
+		t.expenses[i].DeletedAt = sql.NullTime{Time: now(), Valid: true}
+		t.expenses[i].Version++
+		expense = t.expenses[i]
+		return nil
+	})
+	return expense, err
+}
+
+func (store *Store) RestoreExpense(ctx context.Context, arg db.RestoreExpenseParams) (db.Expense, error) {
+	var expense db.Expense
+	err := store.with(func(t *tables) error {
+		i := t.userExpenseIndex(arg.ID, arg.UserID)
+		if i < 0 || !t.expenses[i].DeletedAt.Valid {
+			return sql.ErrNoRows
+		}
+		t.expenses[i].DeletedAt = sql.NullTime{}
+		t.expenses[i].Version++
+		expense = t.expenses[i]
+		return nil
+	})
+	return expense, err
+}
+
+func (store *Store) ListDeletedExpenses(ctx context.Context, userID int64) ([]db.Expense, error) {
+	expenses := []db.Expense{}
+	err := store.with(func(t *tables) error {
+		for _, expense := range t.expenses {
+			if expense.UserID == userID && expense.DeletedAt.Valid {
+				expenses = append(expenses, expense)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return deletedBefore(expenses[j].DeletedAt, expenses[j].ID, expenses[i].DeletedAt, expenses[i].ID)
+	})
+	return expenses, err
+}
+
+// 同じユーザー・カテゴリー・金額で、since 以降に作成された支出を新しい順に返す。
+func (store *Store) ListDuplicateExpenseCandidates(ctx context.Context, arg db.ListDuplicateExpenseCandidatesParams) ([]db.Expense, error) {
+	expenses := []db.Expense{}
+	err := store.with(func(t *tables) error {
+		for _, expense := range t.expenses {
+			if expense.UserID == arg.UserID &&
+				expense.CategoryID == arg.CategoryID &&
+				expense.Amount == arg.Amount &&
+				!expense.CreatedAt.Before(arg.Since) &&
+				!expense.DeletedAt.Valid {
+				expenses = append(expenses, expense)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return createdBefore(expenses[j].CreatedAt, expenses[j].ID, expenses[i].CreatedAt, expenses[i].ID)
+	})
+	return expenses, err
+}
+
+// 同じカテゴリー・金額の支出が、前後 WindowSeconds 秒以内に他にもある支出を返す。
+func (store *Store) ListDuplicateExpenses(ctx context.Context, arg db.ListDuplicateExpensesParams) ([]db.Expense, error) {
+	expenses := []db.Expense{}
+	err := store.with(func(t *tables) error {
+		window := time.Duration(arg.WindowSeconds * float64(time.Second))
+		for _, expense := range t.expenses {
+			if expense.UserID != arg.UserID || expense.DeletedAt.Valid {
+				continue
+			}
+			for _, other := range t.expenses {
+				if other.UserID == expense.UserID &&
+					other.ID != expense.ID &&
+					other.CategoryID == expense.CategoryID &&
+					other.Amount == expense.Amount &&
+					!other.DeletedAt.Valid &&
+					absDuration(other.CreatedAt.Sub(expense.CreatedAt)) <= window {
+					expenses = append(expenses, expense)
+					break
+				}
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(expenses, func(i, j int) bool {
+		a, b := expenses[i], expenses[j]
+		if a.CategoryID != b.CategoryID {
+			return a.CategoryID < b.CategoryID
+		}
+		if a.Amount != b.Amount {
+			return a.Amount < b.Amount
+		}
+		return createdBefore(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return expenses, err
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (store *Store) PurgeDeletedExpenses(ctx context.Context, before time.Time) ([]int64, error) {
+	ids := []int64{}
+	err := store.with(func(t *tables) error {
+		expenses := t.expenses[:0:0]
+		for _, expense := range t.expenses {
+			if expense.DeletedAt.Valid && expense.DeletedAt.Time.Before(before) {
+				ids = append(ids, expense.ID)
+				continue
+			}
+			expenses = append(expenses, expense)
+		}
+		t.expenses = expenses
+		return nil
+	})
+	return ids, err
+}
@@ -0,0 +1,119 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// 有効期限を過ぎた行と、処理中のまま StaleBefore より前に作成された行は置き換える。
+// それ以外の行が既にある場合は、sql.ErrNoRows を返す。
+func (store *Store) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	var key db.IdempotencyKey
+	err := store.with(func(t *tables) error {
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("idempotency_keys", "idempotency_keys_user_id_fkey")
+		}
+		current := now()
+		key = db.IdempotencyKey{
+			UserID:          arg.UserID,
+			Key:             arg.Key,
+			Fingerprint:     arg.Fingerprint,
+			ResponseHeaders: json.RawMessage("{}"),
+			ResponseBody:    []byte{},
+			ExpiresAt:       timestamp(arg.ExpiresAt),
+			CreatedAt:       current,
+		}
+		i := t.idempotencyKeyIndex(arg.UserID, arg.Key)
+		if i < 0 {
+			t.idempotencyKeys = append(t.idempotencyKeys, key)
+			return nil
+		}
+		existing := t.idempotencyKeys[i]
+		expired := !existing.ExpiresAt.After(current)
+		stale := !existing.StatusCode.Valid && existing.CreatedAt.Before(arg.StaleBefore)
+		if !expired && !stale {
+			return sql.ErrNoRows
+		}
+		t.idempotencyKeys[i] = key
+		return nil
+	})
+	return key, err
+}
+
+func (store *Store) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	var key db.IdempotencyKey
+	err := store.with(func(t *tables) error {
+		i := t.idempotencyKeyIndex(arg.UserID, arg.Key)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		key = t.idempotencyKeys[i]
+		return nil
+	})
+	return key, err
+}
+
+func (store *Store) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) error {
+	return store.with(func(t *tables) error {
+		i := t.idempotencyKeyIndex(arg.UserID, arg.Key)
+		if i < 0 {
+			return nil
+		}
+		t.idempotencyKeys[i].StatusCode = sql.NullInt32{Int32: arg.StatusCode, Valid: true}
+		t.idempotencyKeys[i].ResponseHeaders = append(json.RawMessage{}, arg.ResponseHeaders...)
+		t.idempotencyKeys[i].ResponseBody = append([]byte{}, arg.ResponseBody...)
+		return nil
+	})
+}
+
+func (store *Store) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	return store.with(func(t *tables) error {
+		if i := t.idempotencyKeyIndex(arg.UserID, arg.Key); i >= 0 {
+			t.idempotencyKeys = append(t.idempotencyKeys[:i:i], t.idempotencyKeys[i+1:]...)
+		}
+		return nil
+	})
+}
+
+func (store *Store) DeleteUserIdempotencyKeys(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		keys := t.idempotencyKeys[:0:0]
+		for _, key := range t.idempotencyKeys {
+			if key.UserID != userID {
+				keys = append(keys, key)
+			}
+		}
+		t.idempotencyKeys = keys
+		return nil
+	})
+}
+
+func (store *Store) PurgeExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	var n int64
+	err := store.with(func(t *tables) error {
+		keys := t.idempotencyKeys[:0:0]
+		for _, key := range t.idempotencyKeys {
+			if !key.ExpiresAt.After(expiresAt) {
+				n++
+				continue
+			}
+			keys = append(keys, key)
+		}
+		t.idempotencyKeys = keys
+		return nil
+	})
+	return n, err
+}
+
+func (t *tables) idempotencyKeyIndex(userID int64, key string) int {
+	for i, k := range t.idempotencyKeys {
+		if k.UserID == userID && k.Key == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,461 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (t *tables) foodReceiptIndex(id int64) int {
+	for i, receipt := range t.foodReceipts {
+		if receipt.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *tables) foodContentIndex(id int64) int {
+	for i, content := range t.foodContents {
+		if content.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// いずれかの支出から参照されているレシートか。
+func (t *tables) foodReceiptReferenced(id int64) bool {
+	for _, expense := range t.expenses {
+		if expense.FoodReceiptID.Valid && expense.FoodReceiptID.Int64 == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ユーザーが登録したレシートか。
+func foodReceiptOwnedBy(receipt db.FoodReceipt, userID int64) bool {
+	return receipt.UserID.Valid && receipt.UserID.Int64 == userID
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (store *Store) CreateFoodReceipt(ctx context.Context, arg db.CreateFoodReceiptParams) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.with(func(t *tables) error {
+		if arg.UserID.Valid && !t.userExists(arg.UserID.Int64) {
+			return foreignKeyViolation("food_receipts", "food_receipts_user_id_fkey")
+		}
+		receipt = db.FoodReceipt{
+			ID:          t.nextID("food_receipts"),
+			StoreName:   arg.StoreName,
+			Version:     1,
+			UserID:      arg.UserID,
+			TotalPrice:  arg.TotalPrice,
+			ItemsDigest: arg.ItemsDigest,
+			CreatedAt:   now(),
+		}
+		t.foodReceipts = append(t.foodReceipts, receipt)
+		return nil
+	})
+	return receipt, err
+}
+
+func (store *Store) GetFoodReceipt(ctx context.Context, id int64) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.with(func(t *tables) error {
+		i := t.foodReceiptIndex(id)
+		if i < 0 || t.foodReceipts[i].DeletedAt.Valid {
+			return sql.ErrNoRows
+		}
+		receipt = t.foodReceipts[i]
+		return nil
+	})
+	return receipt, err
+}
+
+func (store *Store) CreateFoodContent(ctx context.Context, arg db.CreateFoodContentParams) (db.FoodContent, error) {
+	var content db.FoodContent
+	err := store.with(func(t *tables) error {
+		content = db.FoodContent{
+			ID:           t.nextID("food_contents"),
+			Name:         arg.Name,
+			Calories:     arg.Calories,
+			Lipid:        arg.Lipid,
+			Carbohydrate: arg.Carbohydrate,
+			Protein:      arg.Protein,
+			Version:      1,
+		}
+		t.foodContents = append(t.foodContents, content)
+		return nil
+	})
+	return content, err
+}
+
+func (store *Store) GetFoodContent(ctx context.Context, id int64) (db.FoodContent, error) {
+	var content db.FoodContent
+	err := store.with(func(t *tables) error {
+		i := t.foodContentIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		content = t.foodContents[i]
+		return nil
+	})
+	return content, err
+}
+
+func (store *Store) GetFoodContentByName(ctx context.Context, name string) (db.FoodContent, error) {
+	var content db.FoodContent
+	err := store.with(func(t *tables) error {
+		// 行は id の昇順に並んでいるため、最初に見つかったものが最初に登録されたもの。
+		for _, c := range t.foodContents {
+			if c.Name == name {
+				content = c
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return content, err
+}
+
+func (store *Store) ListFoodContents(ctx context.Context, arg db.ListFoodContentsParams) ([]db.FoodContent, error) {
+	contents := []db.FoodContent{}
+	err := store.with(func(t *tables) error {
+		start, end := page(len(t.foodContents), arg.Limit, arg.Offset)
+		contents = append(contents, t.foodContents[start:end]...)
+		return nil
+	})
+	return contents, err
+}
+
+func (store *Store) UpdateFoodContent(ctx context.Context, arg db.UpdateFoodContentParams) (db.FoodContent, error) {
+	var content db.FoodContent
+	err := store.with(func(t *tables) error {
+		i := t.foodContentIndex(arg.ID)
+		if i < 0 || t.foodContents[i].Version != arg.Version {
+			return sql.ErrNoRows
+		}
+		t.foodContents[i] = db.FoodContent{
+			ID:           arg.ID,
+			Name:         arg.Name,
+			Calories:     arg.Calories,
+			Lipid:        arg.Lipid,
+			Carbohydrate: arg.Carbohydrate,
+			Protein:      arg.Protein,
+			Version:      arg.Version + 1,
+		}
+		content = t.foodContents[i]
+		return nil
+	})
+	return content, err
+}
+
+// レシートの明細から参照されている食品は削除できない。
+func (store *Store) DeleteFoodContent(ctx context.Context, arg db.DeleteFoodContentParams) (db.FoodContent, error) {
+	var content db.FoodContent
+	err := store.with(func(t *tables) error {
+		i := t.foodContentIndex(arg.ID)
+		if i < 0 || t.foodContents[i].Version != arg.Version {
+			return sql.ErrNoRows
+		}
+		for _, rc := range t.foodReceiptContents {
+			if rc.FoodContentID == arg.ID {
+				return referencedViolation("food_contents", "food_receipt_contents", "food_receipt_contents_food_content_id_fkey")
+			}
+		}
+		content = t.foodContents[i]
+		t.foodContents = append(t.foodContents[:i:i], t.foodContents[i+1:]...)
+		return nil
+	})
+	return content, err
+}
+
+func (store *Store) CreateFoodReceiptContent(ctx context.Context, arg db.CreateFoodReceiptContentParams) (db.FoodReceiptContent, error) {
+	var content db.FoodReceiptContent
+	err := store.with(func(t *tables) error {
+		if arg.Amount <= 0 {
+			return checkViolation("food_receipt_contents", "food_receipt_contents_amount_check")
+		}
+		if t.foodReceiptIndex(arg.FoodReceiptID) < 0 {
+			return foreignKeyViolation("food_receipt_contents", "food_receipt_contents_food_receipt_id_fkey")
+		}
+		if t.foodContentIndex(arg.FoodContentID) < 0 {
+			return foreignKeyViolation("food_receipt_contents", "food_receipt_contents_food_content_id_fkey")
+		}
+		content = db.FoodReceiptContent{
+			ID:            t.nextID("food_receipt_contents"),
+			FoodReceiptID: arg.FoodReceiptID,
+			FoodContentID: arg.FoodContentID,
+			Amount:        arg.Amount,
+		}
+		t.foodReceiptContents = append(t.foodReceiptContents, content)
+		return nil
+	})
+	return content, err
+}
+
+func (store *Store) ListFoodReceiptContents(ctx context.Context, foodReceiptID int64) ([]db.ListFoodReceiptContentsRow, error) {
+	rows := []db.ListFoodReceiptContentsRow{}
+	err := store.with(func(t *tables) error {
+		for _, rc := range t.foodReceiptContents {
+			if rc.FoodReceiptID != foodReceiptID {
+				continue
+			}
+			i := t.foodContentIndex(rc.FoodContentID)
+			if i < 0 {
+				continue
+			}
+			content := t.foodContents[i]
+			rows = append(rows, db.ListFoodReceiptContentsRow{
+				FoodReceiptID: rc.FoodReceiptID,
+				FoodContentID: rc.FoodContentID,
+				Amount:        rc.Amount,
+				Name:          content.Name,
+				Calories:      content.Calories,
+				Lipid:         content.Lipid,
+				Carbohydrate:  content.Carbohydrate,
+				Protein:       content.Protein,
+			})
+		}
+		return nil
+	})
+	return rows, err
+}
+
+func (store *Store) ListUserFoodReceiptIDs(ctx context.Context, userID int64) ([]int64, error) {
+	ids := []int64{}
+	err := store.with(func(t *tables) error {
+		for _, receipt := range t.foodReceipts {
+			if foodReceiptOwnedBy(receipt, userID) {
+				ids = append(ids, receipt.ID)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// 同じユーザーが since 以降に登録した、店名・合計金額・明細が同じレシートを新しい順に返す。
+// NULL の列は、SQL と同様にどの値とも一致しない。
+func (store *Store) ListDuplicateFoodReceiptCandidates(ctx context.Context, arg db.ListDuplicateFoodReceiptCandidatesParams) ([]db.FoodReceipt, error) {
+	receipts := []db.FoodReceipt{}
+	err := store.with(func(t *tables) error {
+		if !arg.UserID.Valid || !arg.TotalPrice.Valid || !arg.ItemsDigest.Valid {
+			return nil
+		}
+		for _, receipt := range t.foodReceipts {
+			if receipt.UserID == arg.UserID &&
+				receipt.StoreName == arg.StoreName &&
+				receipt.TotalPrice == arg.TotalPrice &&
+				receipt.ItemsDigest == arg.ItemsDigest &&
+				!receipt.CreatedAt.Before(arg.Since) &&
+				!receipt.DeletedAt.Valid {
+				receipts = append(receipts, receipt)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(receipts, func(i, j int) bool {
+		return createdBefore(receipts[j].CreatedAt, receipts[j].ID, receipts[i].CreatedAt, receipts[i].ID)
+	})
+	return receipts, err
+}
+
+// ユーザーが登録したレシートを、明細ごとに1行で返す（エクスポート用）。
+// 明細のないレシートも1行で返し、ゴミ箱にあるレシートも含める。
+func (store *Store) ListUserFoodReceiptContents(ctx context.Context, userID int64) ([]db.ListUserFoodReceiptContentsRow, error) {
+	rows := []db.ListUserFoodReceiptContentsRow{}
+	err := store.with(func(t *tables) error {
+		// food_receipts と food_receipt_contents は id の昇順のため、ORDER BY と同じ順になる。
+		for _, receipt := range t.foodReceipts {
+			if !foodReceiptOwnedBy(receipt, userID) {
+				continue
+			}
+			row := db.ListUserFoodReceiptContentsRow{
+				FoodReceiptID: receipt.ID,
+				StoreName:     receipt.StoreName,
+				DeletedAt:     receipt.DeletedAt,
+			}
+			found := false
+			for _, rc := range t.foodReceiptContents {
+				if rc.FoodReceiptID != receipt.ID {
+					continue
+				}
+				found = true
+				item := row
+				item.FoodContentID = sql.NullInt64{Int64: rc.FoodContentID, Valid: true}
+				item.Amount = sql.NullInt64{Int64: rc.Amount, Valid: true}
+				if c := t.foodContentIndex(rc.FoodContentID); c >= 0 {
+					content := t.foodContents[c]
+					item.Name = sql.NullString{String: content.Name, Valid: true}
+					item.Calories = sql.NullFloat64{Float64: float64(content.Calories), Valid: true}
+					item.Lipid = sql.NullFloat64{Float64: float64(content.Lipid), Valid: true}
+					item.Carbohydrate = sql.NullFloat64{Float64: float64(content.Carbohydrate), Valid: true}
+					item.Protein = sql.NullFloat64{Float64: float64(content.Protein), Valid: true}
+				}
+				rows = append(rows, item)
+			}
+			if !found {
+				rows = append(rows, row)
+			}
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// 他のユーザーの支出から参照されているレシートの明細は残す。
+func (store *Store) DeleteFoodReceiptContents(ctx context.Context, foodReceiptIds []int64) error {
+	return store.with(func(t *tables) error {
+		contents := t.foodReceiptContents[:0:0]
+		for _, rc := range t.foodReceiptContents {
+			if containsID(foodReceiptIds, rc.FoodReceiptID) && !t.foodReceiptReferenced(rc.FoodReceiptID) {
+				continue
+			}
+			contents = append(contents, rc)
+		}
+		t.foodReceiptContents = contents
+		return nil
+	})
+}
+
+// 他のユーザーの支出から参照されているレシートは残す。
+// 明細が残っているレシートは削除できない。
+func (store *Store) DeleteFoodReceipts(ctx context.Context, ids []int64) error {
+	return store.with(func(t *tables) error {
+		receipts := t.foodReceipts[:0:0]
+		for _, receipt := range t.foodReceipts {
+			if containsID(ids, receipt.ID) && !t.foodReceiptReferenced(receipt.ID) {
+				for _, rc := range t.foodReceiptContents {
+					if rc.FoodReceiptID == receipt.ID {
+						return referencedViolation("food_receipts", "food_receipt_contents", "food_receipt_contents_food_receipt_id_fkey")
+					}
+				}
+				continue
+			}
+			receipts = append(receipts, receipt)
+		}
+		t.foodReceipts = receipts
+		return nil
+	})
+}
+
+// レシートを登録したユーザーのみ取得できる。
+// メモリ上では行ロックは不要なため、取得するのみ。
+func (store *Store) GetFoodReceiptForUpdate(ctx context.Context, arg db.GetFoodReceiptForUpdateParams) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.with(func(t *tables) error {
+		i := t.foodReceiptIndex(arg.ID)
+		if i < 0 || t.foodReceipts[i].DeletedAt.Valid || !foodReceiptOwnedBy(t.foodReceipts[i], arg.UserID) {
+			return sql.ErrNoRows
+		}
+		receipt = t.foodReceipts[i]
+		return nil
+	})
+	return receipt, err
+}
+
+// レシートを登録したユーザーのみ、レシートを削除できる。
+func (store *Store) SoftDeleteFoodReceipt(ctx context.Context, arg db.SoftDeleteFoodReceiptParams) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.with(func(t *tables) error {
+		i := t.foodReceiptIndex(arg.ID)
+		if i < 0 || t.foodReceipts[i].DeletedAt.Valid || t.foodReceipts[i].Version != arg.Version ||
+			!foodReceiptOwnedBy(t.foodReceipts[i], arg.UserID) {
+			return sql.ErrNoRows
+		}
+		t.foodReceipts[i].DeletedAt = sql.NullTime{Time: now(), Valid: true}
+		t.foodReceipts[i].Version++
+		receipt = t.foodReceipts[i]
+		return nil
+	})
+	return receipt, err
+}
+
+func (store *Store) RestoreFoodReceipt(ctx context.Context, arg db.RestoreFoodReceiptParams) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.with(func(t *tables) error {
+		i := t.foodReceiptIndex(arg.ID)
+		if i < 0 || !t.foodReceipts[i].DeletedAt.Valid || !foodReceiptOwnedBy(t.foodReceipts[i], arg.UserID) {
+			return sql.ErrNoRows
+		}
+		t.foodReceipts[i].DeletedAt = sql.NullTime{}
+		t.foodReceipts[i].Version++
+		receipt = t.foodReceipts[i]
+		return nil
+	})
+	return receipt, err
+}
+
+func (store *Store) ListDeletedFoodReceipts(ctx context.Context, userID int64) ([]db.FoodReceipt, error) {
+	receipts := []db.FoodReceipt{}
+	err := store.with(func(t *tables) error {
+		for _, receipt := range t.foodReceipts {
+			if receipt.DeletedAt.Valid && foodReceiptOwnedBy(receipt, userID) {
+				receipts = append(receipts, receipt)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(receipts, func(i, j int) bool {
+		return deletedBefore(receipts[j].DeletedAt, receipts[j].ID, receipts[i].DeletedAt, receipts[i].ID)
+	})
+	return receipts, err
+}
+
+// 完全に削除できるレシートか。支出から参照されているレシートは残す。
+func (t *tables) foodReceiptPurgeable(receipt db.FoodReceipt, before time.Time) bool {
+	return receipt.DeletedAt.Valid && receipt.DeletedAt.Time.Before(before) && !t.foodReceiptReferenced(receipt.ID)
+}
+
+// 支出から参照されているレシートは削除できないため、明細も残す。
+func (store *Store) PurgeDeletedFoodReceiptContents(ctx context.Context, before time.Time) error {
+	return store.with(func(t *tables) error {
+		contents := t.foodReceiptContents[:0:0]
+		for _, rc := range t.foodReceiptContents {
+			if i := t.foodReceiptIndex(rc.FoodReceiptID); i >= 0 && t.foodReceiptPurgeable(t.foodReceipts[i], before) {
+				continue
+			}
+			contents = append(contents, rc)
+		}
+		t.foodReceiptContents = contents
+		return nil
+	})
+}
+
+// 支出から参照されているレシートは残す。
+// 明細が残っているレシートは削除できない。
+func (store *Store) PurgeDeletedFoodReceipts(ctx context.Context, before time.Time) ([]int64, error) {
+	ids := []int64{}
+	err := store.with(func(t *tables) error {
+		receipts := t.foodReceipts[:0:0]
+		for _, receipt := range t.foodReceipts {
+			if t.foodReceiptPurgeable(receipt, before) {
+				for _, rc := range t.foodReceiptContents {
+					if rc.FoodReceiptID == receipt.ID {
+						return referencedViolation("food_receipts", "food_receipt_contents", "food_receipt_contents_food_receipt_id_fkey")
+					}
+				}
+				ids = append(ids, receipt.ID)
+				continue
+			}
+			receipts = append(receipts, receipt)
+		}
+		t.foodReceipts = receipts
+		return nil
+	})
+	return ids, err
+}
@@ -0,0 +1,155 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kokoichi206/account-book-api/auth"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+)
+
+// デモモードでログインできるユーザー。
+const (
+	DemoEmail         = "demo@example.com"
+	DemoAdminEmail    = "admin@example.com"
+	DemoPassword      = "demo-password"
+	demoUserName      = "demo"
+	demoAdminUserName = "admin"
+)
+
+// 標準のカテゴリー（i18n で表示名を翻訳するもの）。
+var demoCategories = []string{
+	"food",
+	"daily_necessities",
+	"transportation",
+	"entertainment",
+	"utilities",
+	"rent",
+	"medical",
+	"education",
+	"clothing",
+	"other",
+}
+
+// デモ用の食品。
+var demoFoodContents = []db.CreateFoodContentParams{
+	{Name: "rice", Calories: 168, Lipid: 0.3, Carbohydrate: 37.1, Protein: 2.5},
+	{Name: "egg", Calories: 151, Lipid: 10.3, Carbohydrate: 0.3, Protein: 12.3},
+	{Name: "milk", Calories: 67, Lipid: 3.8, Carbohydrate: 4.8, Protein: 3.3},
+	{Name: "banana", Calories: 86, Lipid: 0.2, Carbohydrate: 22.5, Protein: 1.1},
+}
+
+// デモモード用のサンプルデータを登録する。
+// 一般ユーザーと管理者を１人ずつ作成し、一般ユーザーにはレシート付きの支出と送金を登録する。
+func Seed(ctx context.Context, store *Store) error {
+	password, err := util.HashPassword(DemoPassword)
+	if err != nil {
+		return err
+	}
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Name:     demoUserName,
+		Password: password,
+		Email:    DemoEmail,
+		Age:      30,
+		Balance:  200000,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create demo user: %w", err)
+	}
+	admin, err := store.CreateUser(ctx, db.CreateUserParams{
+		Name:     demoAdminUserName,
+		Password: password,
+		Email:    DemoAdminEmail,
+		Age:      40,
+		Balance:  0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create demo admin: %w", err)
+	}
+	// ロールを更新するクエリはないため、直接更新する。
+	if _, err := store.updateUser(admin.ID, func(t *tables, u *db.User) error {
+		u.Role = auth.RoleAdmin
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	categories := map[string]db.Category{}
+	for _, name := range demoCategories {
+		category, err := store.CreateCategory(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to create category [%s]: %w", name, err)
+		}
+		categories[name] = category
+	}
+
+	var contents []db.FoodContent
+	for _, arg := range demoFoodContents {
+		content, err := store.CreateFoodContent(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("failed to create food [%s]: %w", arg.Name, err)
+		}
+		contents = append(contents, content)
+	}
+
+	receipt, err := store.CreateFoodReceipt(ctx, db.CreateFoodReceiptParams{
+		StoreName:  "demo supermarket",
+		UserID:     sql.NullInt64{Int64: user.ID, Valid: true},
+		TotalPrice: sql.NullInt64{Int64: 1280, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	for i, content := range contents {
+		if _, err := store.CreateFoodReceiptContent(ctx, db.CreateFoodReceiptContentParams{
+			FoodReceiptID: receipt.ID,
+			FoodContentID: content.ID,
+			Amount:        int64(i + 1),
+		}); err != nil {
+			return err
+		}
+	}
+
+	expenses := []db.CreateExpenseParams{
+		{
+			UserID:        user.ID,
+			CategoryID:    categories["food"].ID,
+			Amount:        1280,
+			FoodReceiptID: sql.NullInt64{Int64: receipt.ID, Valid: true},
+		},
+		{
+			UserID:     user.ID,
+			CategoryID: categories["transportation"].ID,
+			Amount:     420,
+			Comment:    sql.NullString{String: "train", Valid: true},
+		},
+		{
+			UserID:     user.ID,
+			CategoryID: categories["rent"].ID,
+			Amount:     85000,
+		},
+		{
+			UserID:     user.ID,
+			CategoryID: categories["entertainment"].ID,
+			Amount:     1900,
+			Comment:    sql.NullString{String: "movie", Valid: true},
+		},
+	}
+	for _, arg := range expenses {
+		if _, err := store.CreateExpense(ctx, arg); err != nil {
+			return fmt.Errorf("failed to create expense: %w", err)
+		}
+	}
+
+	if _, err := store.CreateTransfer(ctx, db.CreateTransferParams{
+		FromUserID: user.ID,
+		ToUserID:   admin.ID,
+		Amount:     3000,
+	}); err != nil {
+		return fmt.Errorf("failed to create transfer: %w", err)
+	}
+	return nil
+}
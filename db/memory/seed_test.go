@@ -0,0 +1,35 @@
+package memdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kokoichi206/account-book-api/auth"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed(t *testing.T) {
+	// Arrange
+	store := New()
+
+	// Act
+	err := Seed(context.Background(), store)
+
+	// Assert
+	require.NoError(t, err)
+
+	user, err := store.GetUser(context.Background(), DemoEmail)
+	require.NoError(t, err)
+	require.NoError(t, util.CheckPassword(DemoPassword, user.Password))
+	expenses, err := store.ListExpenses(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, expenses, 4)
+	contents, err := store.ListUserFoodReceiptContents(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, contents, len(demoFoodContents))
+
+	admin, err := store.GetUser(context.Background(), DemoAdminEmail)
+	require.NoError(t, err)
+	require.Equal(t, auth.RoleAdmin, admin.Role)
+}
@@ -0,0 +1,155 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (t *tables) sessionIndex(id uuid.UUID) int {
+	for i, session := range t.sessions {
+		if session.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (store *Store) CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+	var session db.Session
+	err := store.with(func(t *tables) error {
+		if t.sessionIndex(arg.ID) >= 0 {
+			return uniqueViolation("sessions", "sessions_pkey")
+		}
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("sessions", "sessions_user_id_fkey")
+		}
+		session = db.Session{
+			ID:        arg.ID,
+			UserID:    arg.UserID,
+			UserAgent: arg.UserAgent,
+			ClientIp:  arg.ClientIp,
+			CreatedAt: now(),
+			ExpiresAt: timestamp(arg.ExpiresAt),
+		}
+		t.sessions = append(t.sessions, session)
+		return nil
+	})
+	return session, err
+}
+
+func (store *Store) GetAuthSession(ctx context.Context, id uuid.UUID) (db.GetAuthSessionRow, error) {
+	var row db.GetAuthSessionRow
+	err := store.with(func(t *tables) error {
+		i := t.sessionIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		session := t.sessions[i]
+		u := t.userIndex(session.UserID)
+		if u < 0 {
+			return sql.ErrNoRows
+		}
+		row = db.GetAuthSessionRow{
+			ID:        session.ID,
+			UserID:    session.UserID,
+			UserAgent: session.UserAgent,
+			ClientIp:  session.ClientIp,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Locale:    t.users[u].Locale,
+		}
+		return nil
+	})
+	return row, err
+}
+
+func (store *Store) GetSession(ctx context.Context, id uuid.UUID) (db.Session, error) {
+	var session db.Session
+	err := store.with(func(t *tables) error {
+		i := t.sessionIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		session = t.sessions[i]
+		return nil
+	})
+	return session, err
+}
+
+func (store *Store) UpdateSession(ctx context.Context, arg db.UpdateSessionParams) error {
+	return store.with(func(t *tables) error {
+		if i := t.sessionIndex(arg.ID); i >= 0 {
+			t.sessions[i].ExpiresAt = timestamp(arg.ExpiresAt)
+		}
+		return nil
+	})
+}
+
+// 行は削除せずに、有効期限を現在時刻にする。
+func (store *Store) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	return store.with(func(t *tables) error {
+		if i := t.sessionIndex(id); i >= 0 {
+			t.sessions[i].ExpiresAt = now()
+		}
+		return nil
+	})
+}
+
+func (store *Store) DeleteUserSessions(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		current := now()
+		for i, session := range t.sessions {
+			if session.UserID == userID && session.ExpiresAt.After(current) {
+				t.sessions[i].ExpiresAt = current
+			}
+		}
+		return nil
+	})
+}
+
+func (store *Store) ListUserSessions(ctx context.Context, userID int64) ([]db.Session, error) {
+	sessions := []db.Session{}
+	err := store.with(func(t *tables) error {
+		for _, session := range t.sessions {
+			if session.UserID == userID {
+				sessions = append(sessions, session)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, err
+}
+
+func (store *Store) PurgeUserSessions(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		sessions := t.sessions[:0:0]
+		for _, session := range t.sessions {
+			if session.UserID != userID {
+				sessions = append(sessions, session)
+			}
+		}
+		t.sessions = sessions
+		return nil
+	})
+}
+
+func (store *Store) CountActiveSessions(ctx context.Context) (int64, error) {
+	var count int64
+	err := store.with(func(t *tables) error {
+		current := now()
+		for _, session := range t.sessions {
+			if session.ExpiresAt.After(current) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
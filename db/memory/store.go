@@ -0,0 +1,368 @@
+// Package memdb は、db.Store をメモリ上で実装する。
+// PostgreSQL を起動せずに動かすテストやデモモードで使う。
+//
+// 一意制約や外部キー制約、RETURNING の挙動は PostgreSQL に合わせ、
+// 制約違反の場合は db.ErrUniqueViolation などの *db.ConstraintError を返す。
+// 挙動が PostgreSQL と一致していることは、dbtest の共通のテストで確かめる。
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kokoichi206/account-book-api/db/migration"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// メモリ上のテーブル。
+// 各テーブルの行は id の昇順に並べる。
+type tables struct {
+	users               []db.User
+	sessions            []db.Session
+	expenses            []db.Expense
+	categories          []db.Category
+	foodReceipts        []db.FoodReceipt
+	foodContents        []db.FoodContent
+	foodReceiptContents []db.FoodReceiptContent
+	transfers           []db.Transfer
+	recoveryCodes       []db.RecoveryCode
+	twoFactorChallenges []db.TwoFactorChallenge
+	apiKeys             []db.ApiKey
+	emailChangeTokens   []db.EmailChangeToken
+	auditEvents         []db.AuditEvent
+	idempotencyKeys     []db.IdempotencyKey
+
+	// bigserial の次の値。ロールバックしても戻さない点も PostgreSQL に合わせる。
+	sequences map[string]int64
+}
+
+// トランザクション用に複製する。
+func (t *tables) clone() *tables {
+	c := &tables{
+		users:               append([]db.User(nil), t.users...),
+		sessions:            append([]db.Session(nil), t.sessions...),
+		expenses:            append([]db.Expense(nil), t.expenses...),
+		categories:          append([]db.Category(nil), t.categories...),
+		foodReceipts:        append([]db.FoodReceipt(nil), t.foodReceipts...),
+		foodContents:        append([]db.FoodContent(nil), t.foodContents...),
+		foodReceiptContents: append([]db.FoodReceiptContent(nil), t.foodReceiptContents...),
+		transfers:           append([]db.Transfer(nil), t.transfers...),
+		recoveryCodes:       append([]db.RecoveryCode(nil), t.recoveryCodes...),
+		twoFactorChallenges: append([]db.TwoFactorChallenge(nil), t.twoFactorChallenges...),
+		apiKeys:             make([]db.ApiKey, len(t.apiKeys)),
+		emailChangeTokens:   append([]db.EmailChangeToken(nil), t.emailChangeTokens...),
+		auditEvents:         append([]db.AuditEvent(nil), t.auditEvents...),
+		idempotencyKeys:     append([]db.IdempotencyKey(nil), t.idempotencyKeys...),
+		sequences:           t.sequences,
+	}
+	for i, key := range t.apiKeys {
+		c.apiKeys[i] = copyAPIKey(key)
+	}
+	return c
+}
+
+// bigserial の次の値を払い出す。
+func (t *tables) nextID(table string) int64 {
+	t.sequences[table]++
+	return t.sequences[table]
+}
+
+// メモリ上で db.Store を実装した構造体。
+// 全ての操作は mu で排他するため、複数のgoroutineから使える。
+type Store struct {
+	mu   sync.Mutex
+	data *tables
+}
+
+var _ db.Store = (*Store)(nil)
+
+// 空の Store を作成する。
+func New() *Store {
+	return &Store{
+		data: &tables{sequences: map[string]int64{}},
+	}
+}
+
+// ロックを取得した上で、テーブルを操作する。
+func (store *Store) with(fn func(t *tables) error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return fn(store.data)
+}
+
+// トランザクション内で関数を実行する。
+// 複製したテーブルに対して実行し、エラーがなければ置き換える。
+// エラーを返した場合は、途中までの変更を破棄する。
+func (store *Store) execTx(ctx context.Context, fn func(db.Querier) error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	tx := &Store{data: store.data.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	store.data = tx.data
+	return nil
+}
+
+// メモリ上のため常に接続できる。
+func (store *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+// スキーマは常に最新のマイグレーションに対応するため、最新のバージョンを返す。
+func (store *Store) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	version, err := migration.LatestVersion()
+	if err != nil {
+		return 0, false, err
+	}
+	return version, false, nil
+}
+
+// ユーザーの個人データを削除・匿名化する。
+// 途中で失敗した場合は、全ての変更を破棄する。
+func (store *Store) DeleteUserTx(ctx context.Context, userID int64) error {
+	return store.execTx(ctx, func(q db.Querier) error {
+		return db.DeleteUserData(ctx, q, userID)
+	})
+}
+
+func (store *Store) ConfirmEmailChangeTx(ctx context.Context, tokenHash string) (db.User, error) {
+	var user db.User
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		user, err = db.ConfirmEmailChange(ctx, q, tokenHash)
+		return err
+	})
+	return user, err
+}
+
+func (store *Store) CreateExpenseTx(ctx context.Context, arg db.CreateExpenseParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		expense, err = db.CreateExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) CreateReceiptTx(ctx context.Context, arg db.CreateReceiptTxParams, actor db.AuditActor) (db.CreateReceiptTxResult, error) {
+	var result db.CreateReceiptTxResult
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		result, err = db.CreateReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return result, err
+}
+
+func (store *Store) CreateCategoryTx(ctx context.Context, name string, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		category, err = db.CreateCategoryWithAudit(ctx, q, name, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) UpdateCategoryTx(ctx context.Context, arg db.UpdateCategoryParams, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		category, err = db.UpdateCategoryWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) DeleteCategoryTx(ctx context.Context, arg db.DeleteCategoryParams, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		category, err = db.DeleteCategoryWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) UpdateExpenseTx(ctx context.Context, arg db.UpdateExpenseTxParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		expense, err = db.UpdateExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) DeleteExpenseTx(ctx context.Context, arg db.SoftDeleteExpenseParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		expense, err = db.DeleteExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) RestoreExpenseTx(ctx context.Context, arg db.RestoreExpenseParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		expense, err = db.RestoreExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) DeleteReceiptTx(ctx context.Context, arg db.SoftDeleteFoodReceiptParams, actor db.AuditActor) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		receipt, err = db.DeleteReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *Store) RestoreReceiptTx(ctx context.Context, arg db.RestoreFoodReceiptParams, actor db.AuditActor) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		receipt, err = db.RestoreReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *Store) RestoreCategoryTx(ctx context.Context, id int64, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		category, err = db.RestoreCategoryWithAudit(ctx, q, id, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) CreateTransferTx(ctx context.Context, arg db.CreateTransferParams, actor db.AuditActor) (db.Transfer, error) {
+	var transfer db.Transfer
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		transfer, err = db.CreateTransferWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return transfer, err
+}
+
+func (store *Store) AdjustBalanceTx(ctx context.Context, arg db.AdjustBalanceTxParams, actor db.AuditActor) (int64, error) {
+	var balance int64
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		balance, err = db.AdjustBalanceWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return balance, err
+}
+
+func (store *Store) PurgeTrashTx(ctx context.Context, before time.Time) (db.PurgeTrashTxResult, error) {
+	var result db.PurgeTrashTxResult
+	err := store.execTx(ctx, func(q db.Querier) error {
+		var err error
+		result, err = db.PurgeTrash(ctx, q, before)
+		return err
+	})
+	return result, err
+}
+
+// PostgreSQL の CURRENT_TIMESTAMP に相当する時刻。
+// timestamptz の精度に合わせて、マイクロ秒に切り捨てる。
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// 引数で受け取った時刻を、timestamptz の精度に切り捨てる。
+func timestamp(t time.Time) time.Time {
+	return t.Truncate(time.Microsecond)
+}
+
+func nullTimestamp(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: timestamp(t.Time), Valid: true}
+}
+
+// PostgreSQL の unique_violation に相当するエラーを返す。
+func uniqueViolation(table, constraint string) error {
+	return &db.ConstraintError{
+		Kind:       db.ErrUniqueViolation,
+		Err:        fmt.Errorf("duplicate key value violates unique constraint %q", constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+// PostgreSQL の foreign_key_violation に相当するエラーを返す。
+func foreignKeyViolation(table, constraint string) error {
+	return &db.ConstraintError{
+		Kind:       db.ErrForeignKeyViolation,
+		Err:        fmt.Errorf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+// 参照されている行を削除しようとした場合の foreign_key_violation を返す。
+func referencedViolation(table, referencingTable, constraint string) error {
+	return &db.ConstraintError{
+		Kind:       db.ErrForeignKeyViolation,
+		Err:        fmt.Errorf("update or delete on table %q violates foreign key constraint %q on table %q", table, constraint, referencingTable),
+		Table:      referencingTable,
+		Constraint: constraint,
+	}
+}
+
+// PostgreSQL の check_violation に相当するエラーを返す。
+func checkViolation(table, constraint string) error {
+	return &db.ConstraintError{
+		Kind:       db.ErrCheckViolation,
+		Err:        fmt.Errorf("new row for relation %q violates check constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+// LIMIT と OFFSET を適用した範囲を返す。
+func page(length int, limit, offset int32) (int, int) {
+	start := int(offset)
+	if start > length {
+		start = length
+	}
+	end := start + int(limit)
+	if limit < 0 || end > length {
+		end = length
+	}
+	return start, end
+}
+
+// ORDER BY deleted_at, id の昇順で、a が b より前に並ぶか。
+func deletedBefore(a sql.NullTime, aID int64, b sql.NullTime, bID int64) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.Before(b.Time)
+	}
+	return aID < bID
+}
+
+// ORDER BY created_at, id の昇順で、a が b より前に並ぶか。
+func createdBefore(a time.Time, aID int64, b time.Time, bID int64) bool {
+	if !a.Equal(b) {
+		return a.Before(b)
+	}
+	return aID < bID
+}
@@ -0,0 +1,98 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/kokoichi206/account-book-api/db/dbtest"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreContract(t *testing.T) {
+	dbtest.RunStoreContract(t, New())
+}
+
+func TestExecTxRollback(t *testing.T) {
+	// Arrange
+	store := New()
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{Name: "user", Email: util.RandomEmail()})
+	require.NoError(t, err)
+	errFailed := errors.New("failed")
+
+	// Act
+	err = store.execTx(context.Background(), func(q db.Querier) error {
+		if _, err := q.AnonymizeUser(context.Background(), user.ID); err != nil {
+			return err
+		}
+		return errFailed
+	})
+
+	// Assert
+	require.ErrorIs(t, err, errFailed)
+	got, err := store.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user, got)
+}
+
+func TestConcurrentCreateUser(t *testing.T) {
+	// Arrange
+	store := New()
+	email := util.RandomEmail()
+	const n = 20
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.CreateUser(context.Background(), db.CreateUserParams{Name: "user", Email: email})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	// 同じメールアドレスでは、1件のみ作成できる。
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	require.Equal(t, 1, succeeded)
+	users, err := store.ListUsers(context.Background(), db.ListUsersParams{Limit: n, Offset: 0})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+}
+
+func TestReturnedValuesAreCopies(t *testing.T) {
+	// Arrange
+	store := New()
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{Name: "user", Email: util.RandomEmail()})
+	require.NoError(t, err)
+	key, err := store.CreateAPIKey(context.Background(), db.CreateAPIKeyParams{
+		UserID:  user.ID,
+		KeyHash: "hash",
+		Scopes:  []string{"expenses:read"},
+	})
+	require.NoError(t, err)
+
+	// Act
+	// 返した値を書き換えても、保持している行は変わらない。
+	key.Scopes[0] = "admin"
+
+	// Assert
+	got, err := store.GetAPIKeyByHash(context.Background(), "hash")
+	require.NoError(t, err)
+	require.Equal(t, []string{"expenses:read"}, got.Scopes)
+	_, err = store.GetAPIKeyByHash(context.Background(), "missing")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
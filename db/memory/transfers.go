@@ -0,0 +1,46 @@
+package memdb
+
+import (
+	"context"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (store *Store) CreateTransfer(ctx context.Context, arg db.CreateTransferParams) (db.Transfer, error) {
+	var transfer db.Transfer
+	err := store.with(func(t *tables) error {
+		// PostgreSQL と同様に、外部キーより先に CHECK 制約を確かめる。
+		if arg.Amount <= 0 {
+			return checkViolation("transfers", "transfers_amount_check")
+		}
+		if !t.userExists(arg.FromUserID) {
+			return foreignKeyViolation("transfers", "transfers_from_user_id_fkey")
+		}
+		if !t.userExists(arg.ToUserID) {
+			return foreignKeyViolation("transfers", "transfers_to_user_id_fkey")
+		}
+		transfer = db.Transfer{
+			ID:         t.nextID("transfers"),
+			FromUserID: arg.FromUserID,
+			ToUserID:   arg.ToUserID,
+			Amount:     arg.Amount,
+			CreatedAt:  now(),
+		}
+		t.transfers = append(t.transfers, transfer)
+		return nil
+	})
+	return transfer, err
+}
+
+func (store *Store) ListUserTransfers(ctx context.Context, fromUserID int64) ([]db.Transfer, error) {
+	transfers := []db.Transfer{}
+	err := store.with(func(t *tables) error {
+		for _, transfer := range t.transfers {
+			if transfer.FromUserID == fromUserID || transfer.ToUserID == fromUserID {
+				transfers = append(transfers, transfer)
+			}
+		}
+		return nil
+	})
+	return transfers, err
+}
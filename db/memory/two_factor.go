@@ -0,0 +1,148 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (store *Store) CreateRecoveryCode(ctx context.Context, arg db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	var code db.RecoveryCode
+	err := store.with(func(t *tables) error {
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("recovery_codes", "recovery_codes_user_id_fkey")
+		}
+		code = db.RecoveryCode{
+			ID:        t.nextID("recovery_codes"),
+			UserID:    arg.UserID,
+			CodeHash:  arg.CodeHash,
+			CreatedAt: now(),
+		}
+		t.recoveryCodes = append(t.recoveryCodes, code)
+		return nil
+	})
+	return code, err
+}
+
+func (store *Store) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		codes := t.recoveryCodes[:0:0]
+		for _, code := range t.recoveryCodes {
+			if code.UserID != userID {
+				codes = append(codes, code)
+			}
+		}
+		t.recoveryCodes = codes
+		return nil
+	})
+}
+
+// 未使用のコードのみを使用済みにする。
+// 同じコードが複数ある場合は全て使用済みにし、最初の行を返す。
+func (store *Store) UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	var used db.RecoveryCode
+	err := store.with(func(t *tables) error {
+		found := false
+		usedAt := sql.NullTime{Time: now(), Valid: true}
+		for i, code := range t.recoveryCodes {
+			if code.UserID != arg.UserID || code.CodeHash != arg.CodeHash || code.UsedAt.Valid {
+				continue
+			}
+			t.recoveryCodes[i].UsedAt = usedAt
+			if !found {
+				used = t.recoveryCodes[i]
+				found = true
+			}
+		}
+		if !found {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	return used, err
+}
+
+func (t *tables) twoFactorChallengeIndex(id uuid.UUID) int {
+	for i, challenge := range t.twoFactorChallenges {
+		if challenge.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (store *Store) CreateTwoFactorChallenge(ctx context.Context, arg db.CreateTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+	var challenge db.TwoFactorChallenge
+	err := store.with(func(t *tables) error {
+		if t.twoFactorChallengeIndex(arg.ID) >= 0 {
+			return uniqueViolation("two_factor_challenges", "two_factor_challenges_pkey")
+		}
+		if !t.userExists(arg.UserID) {
+			return foreignKeyViolation("two_factor_challenges", "two_factor_challenges_user_id_fkey")
+		}
+		challenge = db.TwoFactorChallenge{
+			ID:        arg.ID,
+			UserID:    arg.UserID,
+			UserAgent: arg.UserAgent,
+			ClientIp:  arg.ClientIp,
+			CreatedAt: now(),
+			ExpiresAt: timestamp(arg.ExpiresAt),
+		}
+		t.twoFactorChallenges = append(t.twoFactorChallenges, challenge)
+		return nil
+	})
+	return challenge, err
+}
+
+func (store *Store) GetTwoFactorChallenge(ctx context.Context, id uuid.UUID) (db.TwoFactorChallenge, error) {
+	var challenge db.TwoFactorChallenge
+	err := store.with(func(t *tables) error {
+		i := t.twoFactorChallengeIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		challenge = t.twoFactorChallenges[i]
+		return nil
+	})
+	return challenge, err
+}
+
+// コードを確かめる前に、試行回数を1回分消費する。
+// 上限に達したチャレンジは返さない。
+func (store *Store) UseTwoFactorChallengeAttempt(ctx context.Context, arg db.UseTwoFactorChallengeAttemptParams) (db.TwoFactorChallenge, error) {
+	var challenge db.TwoFactorChallenge
+	err := store.with(func(t *tables) error {
+		i := t.twoFactorChallengeIndex(arg.ID)
+		if i < 0 || t.twoFactorChallenges[i].Attempts >= arg.MaxAttempts {
+			return sql.ErrNoRows
+		}
+		t.twoFactorChallenges[i].Attempts++
+		challenge = t.twoFactorChallenges[i]
+		return nil
+	})
+	return challenge, err
+}
+
+func (store *Store) DeleteTwoFactorChallenge(ctx context.Context, id uuid.UUID) error {
+	return store.with(func(t *tables) error {
+		if i := t.twoFactorChallengeIndex(id); i >= 0 {
+			t.twoFactorChallenges = append(t.twoFactorChallenges[:i:i], t.twoFactorChallenges[i+1:]...)
+		}
+		return nil
+	})
+}
+
+func (store *Store) DeleteUserTwoFactorChallenges(ctx context.Context, userID int64) error {
+	return store.with(func(t *tables) error {
+		challenges := t.twoFactorChallenges[:0:0]
+		for _, challenge := range t.twoFactorChallenges {
+			if challenge.UserID != userID {
+				challenges = append(challenges, challenge)
+			}
+		}
+		t.twoFactorChallenges = challenges
+		return nil
+	})
+}
@@ -0,0 +1,243 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+func (t *tables) userIndex(id int64) int {
+	for i, user := range t.users {
+		if user.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *tables) userExists(id int64) bool {
+	return t.userIndex(id) >= 0
+}
+
+// email の一意制約を確かめる。id のユーザー自身は除く。
+func (t *tables) checkUserEmail(email string, id int64) error {
+	for _, user := range t.users {
+		if user.Email == email && user.ID != id {
+			return uniqueViolation("users", "users_email_key")
+		}
+	}
+	return nil
+}
+
+// id のユーザーを更新して、更新後の行を返す。
+// ユーザーが存在しない場合は sql.ErrNoRows を返す。
+func (store *Store) updateUser(id int64, fn func(t *tables, user *db.User) error) (db.User, error) {
+	var updated db.User
+	err := store.with(func(t *tables) error {
+		i := t.userIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		user := t.users[i]
+		if err := fn(t, &user); err != nil {
+			return err
+		}
+		t.users[i] = user
+		updated = user
+		return nil
+	})
+	return updated, err
+}
+
+func (store *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	var user db.User
+	err := store.with(func(t *tables) error {
+		if err := t.checkUserEmail(arg.Email, 0); err != nil {
+			return err
+		}
+		createdAt := now()
+		user = db.User{
+			ID:                t.nextID("users"),
+			Name:              arg.Name,
+			Password:          arg.Password,
+			Email:             arg.Email,
+			Age:               arg.Age,
+			Balance:           arg.Balance,
+			PasswordChangedAt: createdAt,
+			CreatedAt:         createdAt,
+			Role:              "user",
+			PreferredCurrency: "JPY",
+			Locale:            "ja",
+			Timezone:          "Asia/Tokyo",
+		}
+		t.users = append(t.users, user)
+		return nil
+	})
+	return user, err
+}
+
+func (store *Store) GetUser(ctx context.Context, email string) (db.User, error) {
+	var user db.User
+	err := store.with(func(t *tables) error {
+		for _, u := range t.users {
+			if u.Email == email {
+				user = u
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return user, err
+}
+
+func (store *Store) GetUserByID(ctx context.Context, id int64) (db.User, error) {
+	var user db.User
+	err := store.with(func(t *tables) error {
+		i := t.userIndex(id)
+		if i < 0 {
+			return sql.ErrNoRows
+		}
+		user = t.users[i]
+		return nil
+	})
+	return user, err
+}
+
+func (store *Store) UpdateUserTOTPSecret(ctx context.Context, arg db.UpdateUserTOTPSecretParams) (db.User, error) {
+	return store.updateUser(arg.ID, func(t *tables, user *db.User) error {
+		user.TotpSecret = arg.TotpSecret
+		user.TotpEnabled = false
+		return nil
+	})
+}
+
+func (store *Store) EnableUserTOTP(ctx context.Context, id int64) (db.User, error) {
+	return store.updateUser(id, func(t *tables, user *db.User) error {
+		user.TotpEnabled = true
+		return nil
+	})
+}
+
+// 前回受け付けたコードより後のステップの場合のみ更新する。
+// 0行の場合は、受け付け済みのコードの再利用とみなす。
+func (store *Store) UpdateUserTOTPLastCounter(ctx context.Context, arg db.UpdateUserTOTPLastCounterParams) (int64, error) {
+	var n int64
+	err := store.with(func(t *tables) error {
+		i := t.userIndex(arg.ID)
+		if i < 0 {
+			return nil
+		}
+		user := &t.users[i]
+		if user.TotpLastCounter.Valid && user.TotpLastCounter.Int64 >= arg.Counter {
+			return nil
+		}
+		user.TotpLastCounter = sql.NullInt64{Int64: arg.Counter, Valid: true}
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+func (store *Store) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+	users := []db.User{}
+	err := store.with(func(t *tables) error {
+		start, end := page(len(t.users), arg.Limit, arg.Offset)
+		users = append(users, t.users[start:end]...)
+		return nil
+	})
+	return users, err
+}
+
+func (store *Store) UpdateUserDisabledAt(ctx context.Context, arg db.UpdateUserDisabledAtParams) (db.User, error) {
+	return store.updateUser(arg.ID, func(t *tables, user *db.User) error {
+		user.DisabledAt = nullTimestamp(arg.DisabledAt)
+		return nil
+	})
+}
+
+func (store *Store) UpdateUserProfile(ctx context.Context, arg db.UpdateUserProfileParams) (db.User, error) {
+	return store.updateUser(arg.ID, func(t *tables, user *db.User) error {
+		user.Name = arg.Name
+		user.Age = arg.Age
+		user.PreferredCurrency = arg.PreferredCurrency
+		user.Locale = arg.Locale
+		user.Timezone = arg.Timezone
+		return nil
+	})
+}
+
+func (store *Store) UpdateUserEmail(ctx context.Context, arg db.UpdateUserEmailParams) (db.User, error) {
+	return store.updateUser(arg.ID, func(t *tables, user *db.User) error {
+		if err := t.checkUserEmail(arg.Email, arg.ID); err != nil {
+			return err
+		}
+		user.Email = arg.Email
+		return nil
+	})
+}
+
+func (store *Store) UpdateUserDeletionScheduledAt(ctx context.Context, arg db.UpdateUserDeletionScheduledAtParams) (db.User, error) {
+	return store.updateUser(arg.ID, func(t *tables, user *db.User) error {
+		// 匿名化済みのユーザーは更新の対象にならない。
+		if user.AnonymizedAt.Valid {
+			return sql.ErrNoRows
+		}
+		user.DeletionScheduledAt = nullTimestamp(arg.DeletionScheduledAt)
+		return nil
+	})
+}
+
+func (store *Store) ListUsersDueForDeletion(ctx context.Context, arg db.ListUsersDueForDeletionParams) ([]db.User, error) {
+	users := []db.User{}
+	err := store.with(func(t *tables) error {
+		for _, user := range t.users {
+			if user.DeletionScheduledAt.Valid && !user.DeletionScheduledAt.Time.After(arg.Now) && !user.AnonymizedAt.Valid {
+				users = append(users, user)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].DeletionScheduledAt.Time.Before(users[j].DeletionScheduledAt.Time)
+	})
+	if int(arg.MaxUsers) < len(users) {
+		users = users[:arg.MaxUsers]
+	}
+	return users, err
+}
+
+// 退会したユーザーの残高は変更せず、sql.ErrNoRows を返す。
+func (store *Store) AddUserBalance(ctx context.Context, arg db.AddUserBalanceParams) (int64, error) {
+	var balance int64
+	err := store.with(func(t *tables) error {
+		i := t.userIndex(arg.ID)
+		if i < 0 || t.users[i].AnonymizedAt.Valid {
+			return sql.ErrNoRows
+		}
+		t.users[i].Balance += arg.Amount
+		balance = t.users[i].Balance
+		return nil
+	})
+	return balance, err
+}
+
+func (store *Store) AnonymizeUser(ctx context.Context, id int64) (db.User, error) {
+	return store.updateUser(id, func(t *tables, user *db.User) error {
+		anonymizedAt := sql.NullTime{Time: now(), Valid: true}
+		user.Name = "deleted user"
+		user.Password = ""
+		user.Email = fmt.Sprintf("deleted-%d@invalid", user.ID)
+		user.Age = 0
+		user.Balance = 0
+		user.TotpSecret = sql.NullString{}
+		user.TotpEnabled = false
+		user.TotpLastCounter = sql.NullInt64{}
+		user.DisabledAt = anonymizedAt
+		user.DeletionScheduledAt = sql.NullTime{}
+		user.AnonymizedAt = anonymizedAt
+		return nil
+	})
+}
@@ -0,0 +1,25 @@
+ALTER TABLE "users" ADD COLUMN "totp_secret" varchar;
+ALTER TABLE "users" ADD COLUMN "totp_enabled" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "recovery_codes" (
+	"id" bigserial PRIMARY KEY,
+	"user_id" bigint NOT NULL,
+	"code_hash" varchar NOT NULL,
+	"used_at" timestamptz,
+	"created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "two_factor_challenges" (
+	"id" uuid PRIMARY KEY,
+	"user_id" bigint NOT NULL,
+	"user_agent" varchar NOT NULL,
+	"client_ip" varchar NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT (now()),
+	"expires_at" timestamptz NOT NULL
+);
+
+COMMENT ON COLUMN "users"."totp_secret" IS 'encrypted with AES-GCM';
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'sha256 of the recovery code';
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "two_factor_challenges" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
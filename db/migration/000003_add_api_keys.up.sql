@@ -0,0 +1,17 @@
+CREATE TABLE "api_keys" (
+	"id" bigserial PRIMARY KEY,
+	"user_id" bigint NOT NULL,
+	"name" varchar NOT NULL,
+	"key_prefix" varchar NOT NULL,
+	"key_hash" varchar UNIQUE NOT NULL,
+	"scopes" varchar[] NOT NULL,
+	"expires_at" timestamptz,
+	"last_used_at" timestamptz,
+	"revoked_at" timestamptz,
+	"created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "api_keys"."key_prefix" IS 'first characters of the key to identify it in lists';
+COMMENT ON COLUMN "api_keys"."key_hash" IS 'sha256 of the key';
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'user';
+ALTER TABLE "users" ADD COLUMN "disabled_at" timestamptz;
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('user', 'admin'));
+
+COMMENT ON COLUMN "users"."role" IS 'user or admin';
+COMMENT ON COLUMN "users"."disabled_at" IS 'disabled by an admin when not null';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS email_change_tokens;
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "timezone";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "locale";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "preferred_currency";
@@ -0,0 +1,19 @@
+ALTER TABLE "users" ADD COLUMN "preferred_currency" varchar NOT NULL DEFAULT 'JPY';
+ALTER TABLE "users" ADD COLUMN "locale" varchar NOT NULL DEFAULT 'ja';
+ALTER TABLE "users" ADD COLUMN "timezone" varchar NOT NULL DEFAULT 'Asia/Tokyo';
+
+CREATE TABLE "email_change_tokens" (
+	"id" bigserial PRIMARY KEY,
+	"user_id" bigint NOT NULL,
+	"new_email" varchar NOT NULL,
+	"token_hash" varchar UNIQUE NOT NULL,
+	"expires_at" timestamptz NOT NULL,
+	"used_at" timestamptz,
+	"created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "users"."preferred_currency" IS 'ISO 4217 currency code';
+COMMENT ON COLUMN "users"."timezone" IS 'IANA time zone name';
+COMMENT ON COLUMN "email_change_tokens"."token_hash" IS 'sha256 of the confirmation token';
+
+ALTER TABLE "email_change_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
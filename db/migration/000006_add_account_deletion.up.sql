@@ -0,0 +1,5 @@
+ALTER TABLE "users" ADD COLUMN "deletion_scheduled_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN "anonymized_at" timestamptz;
+
+COMMENT ON COLUMN "users"."deletion_scheduled_at" IS 'personal data is deleted after this time';
+COMMENT ON COLUMN "users"."anonymized_at" IS 'kept as a tombstone for transfers after deletion';
@@ -0,0 +1,34 @@
+ALTER TABLE "sessions" DROP CONSTRAINT "sessions_user_id_fkey";
+ALTER TABLE "sessions" ADD CONSTRAINT "sessions_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "recovery_codes" DROP CONSTRAINT "recovery_codes_user_id_fkey";
+ALTER TABLE "recovery_codes" ADD CONSTRAINT "recovery_codes_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "two_factor_challenges" DROP CONSTRAINT "two_factor_challenges_user_id_fkey";
+ALTER TABLE "two_factor_challenges" ADD CONSTRAINT "two_factor_challenges_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "api_keys" DROP CONSTRAINT "api_keys_user_id_fkey";
+ALTER TABLE "api_keys" ADD CONSTRAINT "api_keys_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "email_change_tokens" DROP CONSTRAINT "email_change_tokens_user_id_fkey";
+ALTER TABLE "email_change_tokens" ADD CONSTRAINT "email_change_tokens_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "expenses" DROP CONSTRAINT "expenses_user_id_fkey";
+ALTER TABLE "expenses" ADD CONSTRAINT "expenses_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "expenses" DROP CONSTRAINT "expenses_category_id_fkey";
+ALTER TABLE "expenses" ADD CONSTRAINT "expenses_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "categories" ("id");
+ALTER TABLE "expenses" DROP CONSTRAINT "expenses_food_receipt_id_fkey";
+ALTER TABLE "expenses" ADD CONSTRAINT "expenses_food_receipt_id_fkey" FOREIGN KEY ("food_receipt_id") REFERENCES "food_receipts" ("id");
+ALTER TABLE "food_receipt_contents" DROP CONSTRAINT "food_receipt_contents_food_receipt_id_fkey";
+ALTER TABLE "food_receipt_contents" ADD CONSTRAINT "food_receipt_contents_food_receipt_id_fkey" FOREIGN KEY ("food_receipt_id") REFERENCES "food_receipts" ("id");
+ALTER TABLE "food_receipt_contents" DROP CONSTRAINT "food_receipt_contents_food_content_id_fkey";
+ALTER TABLE "food_receipt_contents" ADD CONSTRAINT "food_receipt_contents_food_content_id_fkey" FOREIGN KEY ("food_content_id") REFERENCES "food_contents" ("id");
+ALTER TABLE "transfers" DROP CONSTRAINT "transfers_from_user_id_fkey";
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_from_user_id_fkey" FOREIGN KEY ("from_user_id") REFERENCES "users" ("id");
+ALTER TABLE "transfers" DROP CONSTRAINT "transfers_to_user_id_fkey";
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_to_user_id_fkey" FOREIGN KEY ("to_user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "food_receipt_contents" DROP CONSTRAINT IF EXISTS "food_receipt_contents_amount_check";
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_amount_check";
+
+DROP INDEX IF EXISTS "transfers_to_user_id_idx";
+DROP INDEX IF EXISTS "transfers_from_user_id_idx";
+DROP INDEX IF EXISTS "food_receipt_contents_food_receipt_id_idx";
+DROP INDEX IF EXISTS "sessions_user_id_idx";
+DROP INDEX IF EXISTS "expenses_created_at_idx";
+DROP INDEX IF EXISTS "expenses_user_id_idx";
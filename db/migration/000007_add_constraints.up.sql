@@ -0,0 +1,39 @@
+CREATE INDEX "expenses_user_id_idx" ON "expenses" ("user_id");
+CREATE INDEX "expenses_created_at_idx" ON "expenses" ("created_at");
+CREATE INDEX "sessions_user_id_idx" ON "sessions" ("user_id");
+CREATE INDEX "food_receipt_contents_food_receipt_id_idx" ON "food_receipt_contents" ("food_receipt_id");
+CREATE INDEX "transfers_from_user_id_idx" ON "transfers" ("from_user_id");
+CREATE INDEX "transfers_to_user_id_idx" ON "transfers" ("to_user_id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_amount_check" CHECK ("amount" > 0);
+ALTER TABLE "food_receipt_contents" ADD CONSTRAINT "food_receipt_contents_amount_check" CHECK ("amount" > 0);
+
+-- ユーザーの行は匿名化して残すため、ユーザーを削除するのは手動で操作する場合のみ。
+-- 認証のためのデータはユーザーと一緒に削除し、支出や送金などの記録が残っている場合は削除させない。
+ALTER TABLE "sessions" DROP CONSTRAINT "sessions_user_id_fkey";
+ALTER TABLE "sessions" ADD CONSTRAINT "sessions_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "recovery_codes" DROP CONSTRAINT "recovery_codes_user_id_fkey";
+ALTER TABLE "recovery_codes" ADD CONSTRAINT "recovery_codes_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "two_factor_challenges" DROP CONSTRAINT "two_factor_challenges_user_id_fkey";
+ALTER TABLE "two_factor_challenges" ADD CONSTRAINT "two_factor_challenges_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "api_keys" DROP CONSTRAINT "api_keys_user_id_fkey";
+ALTER TABLE "api_keys" ADD CONSTRAINT "api_keys_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "email_change_tokens" DROP CONSTRAINT "email_change_tokens_user_id_fkey";
+ALTER TABLE "email_change_tokens" ADD CONSTRAINT "email_change_tokens_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "expenses" DROP CONSTRAINT "expenses_user_id_fkey";
+ALTER TABLE "expenses" ADD CONSTRAINT "expenses_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
+ALTER TABLE "expenses" DROP CONSTRAINT "expenses_category_id_fkey";
+ALTER TABLE "expenses" ADD CONSTRAINT "expenses_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE RESTRICT;
+ALTER TABLE "expenses" DROP CONSTRAINT "expenses_food_receipt_id_fkey";
+ALTER TABLE "expenses" ADD CONSTRAINT "expenses_food_receipt_id_fkey" FOREIGN KEY ("food_receipt_id") REFERENCES "food_receipts" ("id") ON DELETE RESTRICT;
+
+ALTER TABLE "food_receipt_contents" DROP CONSTRAINT "food_receipt_contents_food_receipt_id_fkey";
+ALTER TABLE "food_receipt_contents" ADD CONSTRAINT "food_receipt_contents_food_receipt_id_fkey" FOREIGN KEY ("food_receipt_id") REFERENCES "food_receipts" ("id") ON DELETE RESTRICT;
+ALTER TABLE "food_receipt_contents" DROP CONSTRAINT "food_receipt_contents_food_content_id_fkey";
+ALTER TABLE "food_receipt_contents" ADD CONSTRAINT "food_receipt_contents_food_content_id_fkey" FOREIGN KEY ("food_content_id") REFERENCES "food_contents" ("id") ON DELETE RESTRICT;
+
+ALTER TABLE "transfers" DROP CONSTRAINT "transfers_from_user_id_fkey";
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_from_user_id_fkey" FOREIGN KEY ("from_user_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
+ALTER TABLE "transfers" DROP CONSTRAINT "transfers_to_user_id_fkey";
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_to_user_id_fkey" FOREIGN KEY ("to_user_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
@@ -0,0 +1,31 @@
+CREATE TABLE "audit_events" (
+	"id" bigserial PRIMARY KEY,
+	"actor_id" bigint NOT NULL,
+	"session_id" uuid,
+	"client_ip" varchar NOT NULL,
+	"action" varchar NOT NULL,
+	"entity_type" varchar NOT NULL,
+	"entity_id" bigint NOT NULL,
+	"before" jsonb NOT NULL,
+	"after" jsonb NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "audit_events"."session_id" IS 'null when authenticated with an API key';
+COMMENT ON COLUMN "audit_events"."before" IS 'JSON null when the entity was created';
+COMMENT ON COLUMN "audit_events"."after" IS 'JSON null when the entity was deleted';
+
+-- 監査ログはユーザーを匿名化した後も残すため、操作したユーザーの削除は許可しない。
+ALTER TABLE "audit_events" ADD CONSTRAINT "audit_events_actor_id_fkey" FOREIGN KEY ("actor_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
+CREATE INDEX "audit_events_actor_id_idx" ON "audit_events" ("actor_id");
+
+-- 追記のみを許可し、記録済みの行の更新・削除は拒否する。
+CREATE FUNCTION "reject_audit_event_change"() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_append_only"
+BEFORE UPDATE OR DELETE ON "audit_events"
+FOR EACH ROW EXECUTE FUNCTION "reject_audit_event_change"();
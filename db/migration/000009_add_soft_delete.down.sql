@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS categories_deleted_at_idx;
+DROP INDEX IF EXISTS food_receipts_deleted_at_idx;
+DROP INDEX IF EXISTS expenses_deleted_at_idx;
+
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "food_receipts" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "expenses" DROP COLUMN IF EXISTS "deleted_at";
+
+COMMENT ON COLUMN "audit_events"."before" IS 'JSON null when the entity was created';
+COMMENT ON COLUMN "audit_events"."after" IS 'JSON null when the entity was deleted';
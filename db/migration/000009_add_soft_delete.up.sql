@@ -0,0 +1,16 @@
+ALTER TABLE "expenses" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "food_receipts" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "categories" ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "expenses"."deleted_at" IS 'moved to the trash at this time';
+COMMENT ON COLUMN "food_receipts"."deleted_at" IS 'moved to the trash at this time';
+COMMENT ON COLUMN "categories"."deleted_at" IS 'moved to the trash at this time';
+
+-- ゴミ箱の一覧と完全削除で使う。削除されていない行は含めない。
+CREATE INDEX "expenses_deleted_at_idx" ON "expenses" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "food_receipts_deleted_at_idx" ON "food_receipts" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "categories_deleted_at_idx" ON "categories" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+-- 削除は行を残すため、削除時の after にはゴミ箱に移した行を記録する。
+COMMENT ON COLUMN "audit_events"."before" IS 'JSON null when the entity was created or restored';
+COMMENT ON COLUMN "audit_events"."after" IS 'the entity as moved to the trash when it was deleted';
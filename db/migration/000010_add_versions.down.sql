@@ -0,0 +1,4 @@
+ALTER TABLE "food_contents" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "food_receipts" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "expenses" DROP COLUMN IF EXISTS "version";
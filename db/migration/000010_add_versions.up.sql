@@ -0,0 +1,10 @@
+-- 楽観的排他制御に使う。更新・削除・復元のたびに１ずつ増やし、ETag として返す。
+ALTER TABLE "expenses" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "food_receipts" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "categories" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "food_contents" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "expenses"."version" IS 'incremented on every update, delete and restore';
+COMMENT ON COLUMN "food_receipts"."version" IS 'incremented on every update, delete and restore';
+COMMENT ON COLUMN "categories"."version" IS 'incremented on every update, delete and restore';
+COMMENT ON COLUMN "food_contents"."version" IS 'incremented on every update';
@@ -0,0 +1,18 @@
+CREATE TABLE "idempotency_keys" (
+	"user_id" bigint NOT NULL,
+	"key" varchar NOT NULL,
+	"fingerprint" varchar NOT NULL,
+	"status_code" integer,
+	"response_headers" jsonb NOT NULL DEFAULT '{}',
+	"response_body" bytea NOT NULL DEFAULT '',
+	"expires_at" timestamptz NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT (now()),
+	PRIMARY KEY ("user_id", "key")
+);
+
+COMMENT ON COLUMN "idempotency_keys"."key" IS 'Idempotency-Key header sent by the client';
+COMMENT ON COLUMN "idempotency_keys"."fingerprint" IS 'sha256 of the method, path and body of the first request';
+COMMENT ON COLUMN "idempotency_keys"."status_code" IS 'null while the first request is in progress';
+
+ALTER TABLE "idempotency_keys" ADD CONSTRAINT "idempotency_keys_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+CREATE INDEX "idempotency_keys_expires_at_idx" ON "idempotency_keys" ("expires_at");
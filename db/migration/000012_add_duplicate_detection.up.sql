@@ -0,0 +1,26 @@
+-- レシートを登録したユーザーを所有者として記録し、削除・復元・エクスポートの範囲をこの列で判定する。
+-- 同じレシートの二重登録を検出するため、合計金額と明細の要約も記録する。
+-- 合計金額と明細の要約は、既存の行では不明なため NULL のままにする。
+ALTER TABLE "food_receipts" ADD COLUMN "user_id" bigint;
+ALTER TABLE "food_receipts" ADD COLUMN "total_price" bigint;
+ALTER TABLE "food_receipts" ADD COLUMN "items_digest" varchar;
+ALTER TABLE "food_receipts" ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT (now());
+
+COMMENT ON COLUMN "food_receipts"."user_id" IS 'user who registered the receipt';
+
+-- 既存の行は、最初に参照した支出のユーザーを所有者とする。
+-- どの支出からも参照されていないレシートは、所有者が不明なため NULL のままにする。
+UPDATE "food_receipts"
+SET "user_id" = (
+	SELECT "expenses"."user_id" FROM "expenses"
+	WHERE "expenses"."food_receipt_id" = "food_receipts"."id"
+	ORDER BY "expenses"."id"
+	LIMIT 1
+)
+WHERE "user_id" IS NULL;
+COMMENT ON COLUMN "food_receipts"."items_digest" IS 'sha256 of the line items, independent of their order';
+
+ALTER TABLE "food_receipts" ADD CONSTRAINT "food_receipts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
+
+CREATE INDEX "food_receipts_user_id_created_at_idx" ON "food_receipts" ("user_id", "created_at");
+CREATE INDEX "expenses_user_id_created_at_idx" ON "expenses" ("user_id", "created_at");
@@ -0,0 +1,2 @@
+ALTER TABLE "two_factor_challenges" DROP COLUMN "attempts";
+ALTER TABLE "users" DROP COLUMN "totp_last_counter";
@@ -0,0 +1,5 @@
+ALTER TABLE "users" ADD COLUMN "totp_last_counter" bigint;
+ALTER TABLE "two_factor_challenges" ADD COLUMN "attempts" integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "users"."totp_last_counter" IS 'time step of the last accepted TOTP code';
+COMMENT ON COLUMN "two_factor_challenges"."attempts" IS 'number of codes tried against the challenge';
@@ -0,0 +1,3 @@
+ALTER TABLE "audit_events" ALTER COLUMN "actor_id" SET NOT NULL;
+
+COMMENT ON COLUMN "audit_events"."actor_id" IS NULL;
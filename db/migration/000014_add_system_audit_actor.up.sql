@@ -0,0 +1,3 @@
+ALTER TABLE "audit_events" ALTER COLUMN "actor_id" DROP NOT NULL;
+
+COMMENT ON COLUMN "audit_events"."actor_id" IS 'null when performed by a background worker';
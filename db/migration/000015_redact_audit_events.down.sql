@@ -0,0 +1,8 @@
+-- 消した個人データは元に戻らない。
+CREATE OR REPLACE FUNCTION "reject_audit_event_change"() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE "audit_events" DROP COLUMN "redacted_at";
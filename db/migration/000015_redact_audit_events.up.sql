@@ -0,0 +1,26 @@
+ALTER TABLE "audit_events" ADD COLUMN "redacted_at" timestamptz;
+
+COMMENT ON COLUMN "audit_events"."redacted_at" IS 'set when before, after, client_ip and session_id were cleared because the actor deleted the account';
+
+-- 追記のみとするが、アカウントを削除したユーザーの行から個人データを消す更新だけは許可する。
+-- 消した行は、再び更新できない。
+CREATE OR REPLACE FUNCTION "reject_audit_event_change"() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'UPDATE'
+		AND OLD.redacted_at IS NULL
+		AND NEW.redacted_at IS NOT NULL
+		AND NEW.id = OLD.id
+		AND NEW.actor_id IS NOT DISTINCT FROM OLD.actor_id
+		AND NEW.action = OLD.action
+		AND NEW.entity_type = OLD.entity_type
+		AND NEW.entity_id = OLD.entity_id
+		AND NEW.created_at = OLD.created_at
+		AND NEW.session_id IS NULL
+		AND NEW.client_ip = ''
+		AND NEW.before = 'null'::jsonb
+		AND NEW.after = 'null'::jsonb THEN
+		RETURN NEW;
+	END IF;
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
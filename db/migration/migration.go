@@ -0,0 +1,82 @@
+// Package migration は、DBのマイグレーションのSQLファイルを提供する。
+//
+// PostgreSQL 用のファイルに加えて、同じバージョンの SQLite 用のファイルを sqlite ディレクトリに置く。
+// スキーマを変更する場合は、両方に同じバージョンのファイルを追加する。
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// マイグレーションのSQLファイル（golang-migrate の形式）。
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLite 用のマイグレーションのSQLファイル。
+//
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// マイグレーションを適用するDBの種類。
+type Dialect int
+
+const (
+	PostgreSQL Dialect = iota
+	SQLite
+)
+
+// DBの種類に対応するマイグレーションのSQLファイルを返す。
+func Files(dialect Dialect) fs.FS {
+	if dialect == SQLite {
+		sub, err := fs.Sub(sqliteFS, "sqlite")
+		if err != nil {
+			// 埋め込んだディレクトリのため、失敗することはない。
+			panic(err)
+		}
+		return sub
+	}
+	return FS
+}
+
+// ファイル名からマイグレーションのバージョンを取得する。
+// 000001_init_schema.up.sql の場合は 1 を返す。
+func Version(name string) (uint, error) {
+	v, err := strconv.ParseUint(strings.SplitN(name, "_", 2)[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration file name [%s]: %w", name, err)
+	}
+	return uint(v), nil
+}
+
+// 最新のマイグレーションのバージョンを返す。
+// サーバーは、DBがこのバージョンまでマイグレーションされていることを前提とする。
+func LatestVersion() (uint, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		v, err := Version(entry.Name())
+		if err != nil {
+			return 0, err
+		}
+		if v > latest {
+			latest = v
+		}
+	}
+	if latest == 0 {
+		return 0, errors.New("no migration file found")
+	}
+	return latest, nil
+}
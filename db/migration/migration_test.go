@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// バージョンが1から連番で、全ての up に対応する down があること。
+func TestMigrationFiles(t *testing.T) {
+	// Arrange
+	entries, err := fs.ReadDir(FS, ".")
+	require.NoError(t, err)
+
+	ups := map[uint]string{}
+	downs := map[uint]string{}
+	for _, entry := range entries {
+		v, err := Version(entry.Name())
+		require.NoError(t, err)
+		switch {
+		case strings.HasSuffix(entry.Name(), ".up.sql"):
+			ups[v] = strings.TrimSuffix(entry.Name(), ".up.sql")
+		case strings.HasSuffix(entry.Name(), ".down.sql"):
+			downs[v] = strings.TrimSuffix(entry.Name(), ".down.sql")
+		default:
+			t.Fatalf("unexpected file [%s]", entry.Name())
+		}
+	}
+
+	// Act
+	latest, err := LatestVersion()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, ups, int(latest))
+	for v := uint(1); v <= latest; v++ {
+		require.Containsf(t, ups, v, "migration [%d] is missing", v)
+		require.Equalf(t, ups[v], downs[v], "down migration of [%s] is missing", ups[v])
+	}
+}
+
+// SQLite 用のファイルが、PostgreSQL 用のファイルと同じバージョン・名前で揃っていること。
+func TestSQLiteMigrationFiles(t *testing.T) {
+	// Arrange
+	names := func(fsys fs.FS) []string {
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	// Act
+	postgres := names(Files(PostgreSQL))
+	sqlite := names(Files(SQLite))
+
+	// Assert
+	require.NotEmpty(t, sqlite)
+	require.Equal(t, postgres, sqlite)
+}
+
+func TestVersion(t *testing.T) {
+	v, err := Version("000012_add_index.up.sql")
+	require.NoError(t, err)
+	require.Equal(t, uint(12), v)
+
+	_, err = Version("add_index.up.sql")
+	require.Error(t, err)
+}
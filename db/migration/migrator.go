@@ -0,0 +1,362 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// マイグレーションの実行中に他のプロセスが実行しないよう取得する、advisory lock のキー。
+// アプリケーション内で他の advisory lock と重ならない値にする。
+const advisoryLockKey int64 = 4_207_310_001
+
+// DBが dirty な状態（マイグレーションが途中で失敗した状態）の場合のエラー。
+var ErrDirty = errors.New("database is dirty")
+
+// １つのバージョンのマイグレーション。
+type Migration struct {
+	Version uint
+	Name    string
+	up      string
+	down    string
+}
+
+// DBのマイグレーションの状態。
+type Status struct {
+	// 適用済みのバージョン。未適用の場合は0。
+	Current uint
+	// マイグレーションが途中で失敗しているか。
+	Dirty bool
+	// 埋め込まれた最新のバージョン。
+	Latest uint
+	// 未適用のバージョン。
+	Pending []uint
+}
+
+// 埋め込まれたSQLファイルを使って、DBをマイグレーションする。
+// バージョンは golang-migrate と同じ schema_migrations テーブルで管理するため、
+// migrate コマンドで適用したDBにもそのまま使える。
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	logger     *zap.Logger
+}
+
+// 埋め込まれたSQLファイルのうち、dialect に対応するものを使う Migrator を作成する。
+func NewMigrator(db *sql.DB, dialect Dialect, logger *zap.Logger) (*Migrator, error) {
+	migrations, err := load(Files(dialect))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// SQLファイルを読み込み、バージョンの昇順に並べる。
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		v, err := Version(name)
+		if err != nil {
+			return nil, err
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[v]
+		if !ok {
+			m = &Migration{Version: v, Name: strings.TrimSuffix(name, "."+direction+".sql")}
+			byVersion[v] = m
+		}
+		if direction == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("up migration of [%s] is missing", m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// １回分の実行内容。
+type step struct {
+	migration Migration
+	up        bool
+	// 実行後のバージョン。
+	version uint
+}
+
+// current から target に移行するために実行するマイグレーションを、実行する順に返す。
+func plan(migrations []Migration, current, target uint) ([]step, error) {
+	if target != 0 && !contains(migrations, target) {
+		return nil, fmt.Errorf("migration version [%d] does not exist", target)
+	}
+	if current != 0 && !contains(migrations, current) {
+		return nil, fmt.Errorf("current version [%d] is unknown to this binary", current)
+	}
+
+	var steps []step
+	if target >= current {
+		for _, m := range migrations {
+			if m.Version > current && m.Version <= target {
+				steps = append(steps, step{migration: m, up: true, version: m.Version})
+			}
+		}
+		return steps, nil
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= target {
+			continue
+		}
+		// down の後のバージョンは、１つ前のマイグレーションのバージョンになる。
+		var prev uint
+		if i > 0 {
+			prev = migrations[i-1].Version
+		}
+		if m.down == "" {
+			return nil, fmt.Errorf("down migration of [%s] is missing", m.Name)
+		}
+		steps = append(steps, step{migration: m, up: false, version: prev})
+	}
+	return steps, nil
+}
+
+func contains(migrations []Migration, version uint) bool {
+	for _, m := range migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// 最新のバージョンまでマイグレーションする。
+func (migrator *Migrator) Up(ctx context.Context) error {
+	return migrator.migrate(ctx, func(current uint) (uint, error) {
+		return migrator.latest(), nil
+	})
+}
+
+// 最後に適用したマイグレーションを１つ取り消す。
+func (migrator *Migrator) Down(ctx context.Context) error {
+	return migrator.migrate(ctx, func(current uint) (uint, error) {
+		if current == 0 {
+			return 0, errors.New("no migration to roll back")
+		}
+		for i, m := range migrator.migrations {
+			if m.Version == current {
+				if i == 0 {
+					return 0, nil
+				}
+				return migrator.migrations[i-1].Version, nil
+			}
+		}
+		return 0, fmt.Errorf("current version [%d] is unknown to this binary", current)
+	})
+}
+
+// 指定したバージョンまで、マイグレーションを適用または取り消す。
+// 0を指定した場合は全て取り消す。
+func (migrator *Migrator) Goto(ctx context.Context, version uint) error {
+	return migrator.migrate(ctx, func(current uint) (uint, error) {
+		return version, nil
+	})
+}
+
+// DBのマイグレーションの状態を取得する。
+func (migrator *Migrator) Status(ctx context.Context) (Status, error) {
+	if err := ensureTable(ctx, migrator.db); err != nil {
+		return Status{}, err
+	}
+	current, dirty, err := readVersion(ctx, migrator.db)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{
+		Current: current,
+		Dirty:   dirty,
+		Latest:  migrator.latest(),
+	}
+	for _, m := range migrator.migrations {
+		if m.Version > current {
+			status.Pending = append(status.Pending, m.Version)
+		}
+	}
+	return status, nil
+}
+
+func (migrator *Migrator) latest() uint {
+	if len(migrator.migrations) == 0 {
+		return 0
+	}
+	return migrator.migrations[len(migrator.migrations)-1].Version
+}
+
+// advisory lock を取得した上で、target が返すバージョンまでマイグレーションする。
+// 複数のレプリカが同時に起動しても、１つずつ順番に実行される。
+// SQLite は１つのプロセスからのみ使うため、ロックは取得しない。
+func (migrator *Migrator) migrate(ctx context.Context, target func(current uint) (uint, error)) (err error) {
+	// advisory lock はセッション単位のため、取得から解放まで同じ接続を使う。
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if migrator.dialect == PostgreSQL {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+			return fmt.Errorf("cannot acquire migration lock: %w", err)
+		}
+		defer func() {
+			// ctx がキャンセルされていても解放できるよう、新しい context を使う。
+			if _, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey); unlockErr != nil && err == nil {
+				err = fmt.Errorf("cannot release migration lock: %w", unlockErr)
+			}
+		}()
+	}
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	// ロックを待つ間に、他のレプリカがマイグレーションしている場合があるため、取得後に読み込む。
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w at version [%d]: fix the schema manually and force the version", ErrDirty, current)
+	}
+
+	version, err := target(current)
+	if err != nil {
+		return err
+	}
+	steps, err := plan(migrator.migrations, current, version)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		migrator.logger.Info("no migration to apply", zap.Uint("version", current))
+		return nil
+	}
+
+	for _, s := range steps {
+		if err := migrator.apply(ctx, conn, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// １つのマイグレーションを、バージョンの更新と同じトランザクションで実行する。
+// 失敗した場合はロールバックされるため、dirty な状態にはならない。
+func (migrator *Migrator) apply(ctx context.Context, conn *sql.Conn, s step) error {
+	query := s.migration.down
+	direction := "down"
+	if s.up {
+		query = s.migration.up
+		direction = "up"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration [%s] %s failed: %w", s.migration.Name, direction, err)
+	}
+	if err := writeVersion(ctx, tx, s.version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	migrator.logger.Info("migration applied",
+		zap.String("migration", s.migration.Name),
+		zap.String("direction", direction),
+		zap.Uint("version", s.version),
+	)
+	return nil
+}
+
+// *sql.DB, *sql.Conn, *sql.Tx のいずれでもクエリを実行できるようにする。
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// golang-migrate と同じ定義で schema_migrations テーブルを作成する。
+func ensureTable(ctx context.Context, db execQuerier) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`)
+	return err
+}
+
+func readVersion(ctx context.Context, db execQuerier) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// バージョンを更新する。全て取り消した場合は、golang-migrate と同様に行を残さない。
+func writeVersion(ctx context.Context, db execQuerier, version uint) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", int64(version))
+	return err
+}
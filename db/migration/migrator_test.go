@@ -0,0 +1,199 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestMigrations(t *testing.T) []Migration {
+	fsys := fstest.MapFS{
+		"000002_add_index.up.sql":     {Data: []byte("CREATE INDEX;")},
+		"000002_add_index.down.sql":   {Data: []byte("DROP INDEX;")},
+		"000001_init_schema.up.sql":   {Data: []byte("CREATE TABLE;")},
+		"000001_init_schema.down.sql": {Data: []byte("DROP TABLE;")},
+		"000003_add_column.up.sql":    {Data: []byte("ALTER TABLE ADD;")},
+		"000003_add_column.down.sql":  {Data: []byte("ALTER TABLE DROP;")},
+		"README.md":                   {Data: []byte("ignored")},
+	}
+	migrations, err := load(fsys)
+	require.NoError(t, err)
+	return migrations
+}
+
+func TestLoad(t *testing.T) {
+	// Arrange
+
+	// Act
+	migrations := newTestMigrations(t)
+
+	// Assert
+	require.Len(t, migrations, 3)
+	for i, m := range migrations {
+		require.Equal(t, uint(i+1), m.Version)
+	}
+	require.Equal(t, "000001_init_schema", migrations[0].Name)
+	require.Equal(t, "CREATE TABLE;", migrations[0].up)
+	require.Equal(t, "DROP TABLE;", migrations[0].down)
+}
+
+func TestLoadMissingUp(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"000001_init_schema.down.sql": {Data: []byte("DROP TABLE;")},
+	}
+
+	// Act
+	_, err := load(fsys)
+
+	// Assert
+	require.Error(t, err)
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	// Arrange
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+
+	// Act
+	migrations, err := load(FS)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, latest, migrations[len(migrations)-1].Version)
+}
+
+func TestPlan(t *testing.T) {
+	migrations := newTestMigrations(t)
+
+	type expectedStep struct {
+		name    string
+		up      bool
+		version uint
+	}
+
+	testCases := []struct {
+		name     string
+		current  uint
+		target   uint
+		expected []expectedStep
+		hasError bool
+	}{
+		{
+			name:    "UpFromEmpty",
+			current: 0,
+			target:  3,
+			expected: []expectedStep{
+				{name: "000001_init_schema", up: true, version: 1},
+				{name: "000002_add_index", up: true, version: 2},
+				{name: "000003_add_column", up: true, version: 3},
+			},
+		},
+		{
+			name:    "UpPending",
+			current: 2,
+			target:  3,
+			expected: []expectedStep{
+				{name: "000003_add_column", up: true, version: 3},
+			},
+		},
+		{
+			name:     "NoChange",
+			current:  3,
+			target:   3,
+			expected: nil,
+		},
+		{
+			name:    "Down",
+			current: 3,
+			target:  1,
+			expected: []expectedStep{
+				{name: "000003_add_column", up: false, version: 2},
+				{name: "000002_add_index", up: false, version: 1},
+			},
+		},
+		{
+			name:    "DownToZero",
+			current: 2,
+			target:  0,
+			expected: []expectedStep{
+				{name: "000002_add_index", up: false, version: 1},
+				{name: "000001_init_schema", up: false, version: 0},
+			},
+		},
+		{
+			name:     "UnknownTarget",
+			current:  1,
+			target:   4,
+			hasError: true,
+		},
+		{
+			// 新しいバイナリで適用されたDBを、古いバイナリで操作しようとした場合。
+			name:     "UnknownCurrent",
+			current:  5,
+			target:   3,
+			hasError: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+
+			// Act
+			steps, err := plan(migrations, tc.current, tc.target)
+
+			// Assert
+			if tc.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var actual []expectedStep
+			for _, s := range steps {
+				actual = append(actual, expectedStep{name: s.migration.Name, up: s.up, version: s.version})
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+// SQLite 用のマイグレーションを、実際のDBに全て適用してから全て取り消す。
+func TestMigratorSQLite(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "migrate.db")+"?_foreign_keys=on")
+	require.NoError(t, err)
+	defer conn.Close()
+	migrator, err := NewMigrator(conn, SQLite, zap.NewNop())
+	require.NoError(t, err)
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, migrator.Up(ctx))
+	applied, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Goto(ctx, 0))
+	reverted, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	// 取り消した後に、もう一度適用できる。
+	require.NoError(t, migrator.Up(ctx))
+	reapplied, err := migrator.Status(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, Status{Current: latest, Latest: latest}, applied)
+	require.Equal(t, uint(0), reverted.Current)
+	require.Len(t, reverted.Pending, int(latest))
+	require.Equal(t, applied, reapplied)
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS food_receipt_contents;
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS food_contents;
+DROP TABLE IF EXISTS food_receipts;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,67 @@
+-- 時刻は UTC の "YYYY-MM-DD HH:MM:SS.SSSSSS" 形式で保存し、文字列として比較できるようにする。
+CREATE TABLE "users" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"name" TEXT NOT NULL,
+	"password" TEXT NOT NULL,
+	"email" TEXT UNIQUE NOT NULL,
+	"age" INTEGER NOT NULL,
+	"balance" INTEGER NOT NULL,
+	"password_changed_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE TABLE "sessions" (
+	"id" TEXT PRIMARY KEY NOT NULL,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"user_agent" TEXT NOT NULL,
+	"client_ip" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+	"expires_at" DATETIME NOT NULL
+);
+
+CREATE TABLE "categories" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"name" TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE "food_receipts" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"store_name" TEXT NOT NULL
+);
+
+CREATE TABLE "food_contents" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"name" TEXT NOT NULL,
+	"calories" REAL NOT NULL,
+	"lipid" REAL NOT NULL,
+	"carbohydrate" REAL NOT NULL,
+	"protein" REAL NOT NULL
+);
+
+CREATE TABLE "expenses" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"category_id" INTEGER NOT NULL REFERENCES "categories" ("id"),
+	-- can be negative or positive
+	"amount" INTEGER NOT NULL,
+	"food_receipt_id" INTEGER REFERENCES "food_receipts" ("id"),
+	"comment" TEXT,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE TABLE "food_receipt_contents" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"food_receipt_id" INTEGER NOT NULL REFERENCES "food_receipts" ("id"),
+	"food_content_id" INTEGER NOT NULL REFERENCES "food_contents" ("id"),
+	-- must be positive
+	"amount" INTEGER NOT NULL
+);
+
+CREATE TABLE "transfers" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"from_user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"to_user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	-- must be positive
+	"amount" INTEGER NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS two_factor_challenges;
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE "users" DROP COLUMN "totp_enabled";
+ALTER TABLE "users" DROP COLUMN "totp_secret";
@@ -0,0 +1,21 @@
+-- encrypted with AES-GCM
+ALTER TABLE "users" ADD COLUMN "totp_secret" TEXT;
+ALTER TABLE "users" ADD COLUMN "totp_enabled" BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE "recovery_codes" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	-- sha256 of the recovery code
+	"code_hash" TEXT NOT NULL,
+	"used_at" DATETIME,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE TABLE "two_factor_challenges" (
+	"id" TEXT PRIMARY KEY NOT NULL,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"user_agent" TEXT NOT NULL,
+	"client_ip" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+	"expires_at" DATETIME NOT NULL
+);
@@ -0,0 +1,15 @@
+CREATE TABLE "api_keys" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"name" TEXT NOT NULL,
+	-- first characters of the key to identify it in lists
+	"key_prefix" TEXT NOT NULL,
+	-- sha256 of the key
+	"key_hash" TEXT UNIQUE NOT NULL,
+	-- JSON array of scopes (varchar[] in PostgreSQL)
+	"scopes" TEXT NOT NULL,
+	"expires_at" DATETIME,
+	"last_used_at" DATETIME,
+	"revoked_at" DATETIME,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
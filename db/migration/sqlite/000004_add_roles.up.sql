@@ -0,0 +1,4 @@
+-- user or admin
+ALTER TABLE "users" ADD COLUMN "role" TEXT NOT NULL DEFAULT 'user' CONSTRAINT "users_role_check" CHECK ("role" IN ('user', 'admin'));
+-- disabled by an admin when not null
+ALTER TABLE "users" ADD COLUMN "disabled_at" DATETIME;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS email_change_tokens;
+
+ALTER TABLE "users" DROP COLUMN "timezone";
+ALTER TABLE "users" DROP COLUMN "locale";
+ALTER TABLE "users" DROP COLUMN "preferred_currency";
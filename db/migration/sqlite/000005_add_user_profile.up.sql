@@ -0,0 +1,16 @@
+-- ISO 4217 currency code
+ALTER TABLE "users" ADD COLUMN "preferred_currency" TEXT NOT NULL DEFAULT 'JPY';
+ALTER TABLE "users" ADD COLUMN "locale" TEXT NOT NULL DEFAULT 'ja';
+-- IANA time zone name
+ALTER TABLE "users" ADD COLUMN "timezone" TEXT NOT NULL DEFAULT 'Asia/Tokyo';
+
+CREATE TABLE "email_change_tokens" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"new_email" TEXT NOT NULL,
+	-- sha256 of the confirmation token
+	"token_hash" TEXT UNIQUE NOT NULL,
+	"expires_at" DATETIME NOT NULL,
+	"used_at" DATETIME,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
@@ -0,0 +1,4 @@
+-- personal data is deleted after this time
+ALTER TABLE "users" ADD COLUMN "deletion_scheduled_at" DATETIME;
+-- kept as a tombstone for transfers after deletion
+ALTER TABLE "users" ADD COLUMN "anonymized_at" DATETIME;
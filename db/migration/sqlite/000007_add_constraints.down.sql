@@ -0,0 +1,116 @@
+DROP INDEX IF EXISTS "transfers_to_user_id_idx";
+DROP INDEX IF EXISTS "transfers_from_user_id_idx";
+DROP INDEX IF EXISTS "food_receipt_contents_food_receipt_id_idx";
+DROP INDEX IF EXISTS "sessions_user_id_idx";
+DROP INDEX IF EXISTS "expenses_created_at_idx";
+DROP INDEX IF EXISTS "expenses_user_id_idx";
+
+CREATE TABLE "sessions_new" (
+	"id" TEXT PRIMARY KEY NOT NULL,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"user_agent" TEXT NOT NULL,
+	"client_ip" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+	"expires_at" DATETIME NOT NULL
+);
+INSERT INTO "sessions_new" ("id", "user_id", "user_agent", "client_ip", "created_at", "expires_at") SELECT "id", "user_id", "user_agent", "client_ip", "created_at", "expires_at" FROM "sessions";
+DROP TABLE "sessions";
+ALTER TABLE "sessions_new" RENAME TO "sessions";
+
+CREATE TABLE "recovery_codes_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"code_hash" TEXT NOT NULL,
+	"used_at" DATETIME,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+INSERT INTO "recovery_codes_new" ("id", "user_id", "code_hash", "used_at", "created_at") SELECT "id", "user_id", "code_hash", "used_at", "created_at" FROM "recovery_codes";
+DELETE FROM sqlite_sequence WHERE name = 'recovery_codes_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'recovery_codes_new', seq FROM sqlite_sequence WHERE name = 'recovery_codes';
+DROP TABLE "recovery_codes";
+ALTER TABLE "recovery_codes_new" RENAME TO "recovery_codes";
+
+CREATE TABLE "two_factor_challenges_new" (
+	"id" TEXT PRIMARY KEY NOT NULL,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"user_agent" TEXT NOT NULL,
+	"client_ip" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+	"expires_at" DATETIME NOT NULL
+);
+INSERT INTO "two_factor_challenges_new" ("id", "user_id", "user_agent", "client_ip", "created_at", "expires_at") SELECT "id", "user_id", "user_agent", "client_ip", "created_at", "expires_at" FROM "two_factor_challenges";
+DROP TABLE "two_factor_challenges";
+ALTER TABLE "two_factor_challenges_new" RENAME TO "two_factor_challenges";
+
+CREATE TABLE "api_keys_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"name" TEXT NOT NULL,
+	"key_prefix" TEXT NOT NULL,
+	"key_hash" TEXT UNIQUE NOT NULL,
+	"scopes" TEXT NOT NULL,
+	"expires_at" DATETIME,
+	"last_used_at" DATETIME,
+	"revoked_at" DATETIME,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+INSERT INTO "api_keys_new" ("id", "user_id", "name", "key_prefix", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at") SELECT "id", "user_id", "name", "key_prefix", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at" FROM "api_keys";
+DELETE FROM sqlite_sequence WHERE name = 'api_keys_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'api_keys_new', seq FROM sqlite_sequence WHERE name = 'api_keys';
+DROP TABLE "api_keys";
+ALTER TABLE "api_keys_new" RENAME TO "api_keys";
+
+CREATE TABLE "email_change_tokens_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"new_email" TEXT NOT NULL,
+	"token_hash" TEXT UNIQUE NOT NULL,
+	"expires_at" DATETIME NOT NULL,
+	"used_at" DATETIME,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+INSERT INTO "email_change_tokens_new" ("id", "user_id", "new_email", "token_hash", "expires_at", "used_at", "created_at") SELECT "id", "user_id", "new_email", "token_hash", "expires_at", "used_at", "created_at" FROM "email_change_tokens";
+DELETE FROM sqlite_sequence WHERE name = 'email_change_tokens_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'email_change_tokens_new', seq FROM sqlite_sequence WHERE name = 'email_change_tokens';
+DROP TABLE "email_change_tokens";
+ALTER TABLE "email_change_tokens_new" RENAME TO "email_change_tokens";
+
+CREATE TABLE "expenses_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"category_id" INTEGER NOT NULL REFERENCES "categories" ("id"),
+	"amount" INTEGER NOT NULL,
+	"food_receipt_id" INTEGER REFERENCES "food_receipts" ("id"),
+	"comment" TEXT,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+INSERT INTO "expenses_new" ("id", "user_id", "category_id", "amount", "food_receipt_id", "comment", "created_at") SELECT "id", "user_id", "category_id", "amount", "food_receipt_id", "comment", "created_at" FROM "expenses";
+DELETE FROM sqlite_sequence WHERE name = 'expenses_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'expenses_new', seq FROM sqlite_sequence WHERE name = 'expenses';
+DROP TABLE "expenses";
+ALTER TABLE "expenses_new" RENAME TO "expenses";
+
+CREATE TABLE "food_receipt_contents_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"food_receipt_id" INTEGER NOT NULL REFERENCES "food_receipts" ("id"),
+	"food_content_id" INTEGER NOT NULL REFERENCES "food_contents" ("id"),
+	"amount" INTEGER NOT NULL
+);
+INSERT INTO "food_receipt_contents_new" ("id", "food_receipt_id", "food_content_id", "amount") SELECT "id", "food_receipt_id", "food_content_id", "amount" FROM "food_receipt_contents";
+DELETE FROM sqlite_sequence WHERE name = 'food_receipt_contents_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'food_receipt_contents_new', seq FROM sqlite_sequence WHERE name = 'food_receipt_contents';
+DROP TABLE "food_receipt_contents";
+ALTER TABLE "food_receipt_contents_new" RENAME TO "food_receipt_contents";
+
+CREATE TABLE "transfers_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"from_user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"to_user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+	"amount" INTEGER NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+INSERT INTO "transfers_new" ("id", "from_user_id", "to_user_id", "amount", "created_at") SELECT "id", "from_user_id", "to_user_id", "amount", "created_at" FROM "transfers";
+DELETE FROM sqlite_sequence WHERE name = 'transfers_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'transfers_new', seq FROM sqlite_sequence WHERE name = 'transfers';
+DROP TABLE "transfers";
+ALTER TABLE "transfers_new" RENAME TO "transfers";
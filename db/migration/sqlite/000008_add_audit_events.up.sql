@@ -0,0 +1,29 @@
+CREATE TABLE "audit_events" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"actor_id" INTEGER NOT NULL CONSTRAINT "audit_events_actor_id_fkey" REFERENCES "users" ("id") ON DELETE RESTRICT,
+	-- null when authenticated with an API key
+	"session_id" TEXT,
+	"client_ip" TEXT NOT NULL,
+	"action" TEXT NOT NULL,
+	"entity_type" TEXT NOT NULL,
+	"entity_id" INTEGER NOT NULL,
+	-- JSON null when the entity was created
+	"before" TEXT NOT NULL,
+	-- JSON null when the entity was deleted
+	"after" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE INDEX "audit_events_actor_id_idx" ON "audit_events" ("actor_id");
+
+CREATE TRIGGER "audit_events_reject_update"
+BEFORE UPDATE ON "audit_events"
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER "audit_events_reject_delete"
+BEFORE DELETE ON "audit_events"
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS categories_deleted_at_idx;
+DROP INDEX IF EXISTS food_receipts_deleted_at_idx;
+DROP INDEX IF EXISTS expenses_deleted_at_idx;
+
+ALTER TABLE "categories" DROP COLUMN "deleted_at";
+ALTER TABLE "food_receipts" DROP COLUMN "deleted_at";
+ALTER TABLE "expenses" DROP COLUMN "deleted_at";
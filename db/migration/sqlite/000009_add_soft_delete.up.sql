@@ -0,0 +1,10 @@
+-- moved to the trash at this time
+ALTER TABLE "expenses" ADD COLUMN "deleted_at" DATETIME;
+-- moved to the trash at this time
+ALTER TABLE "food_receipts" ADD COLUMN "deleted_at" DATETIME;
+-- moved to the trash at this time
+ALTER TABLE "categories" ADD COLUMN "deleted_at" DATETIME;
+
+CREATE INDEX "expenses_deleted_at_idx" ON "expenses" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "food_receipts_deleted_at_idx" ON "food_receipts" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "categories_deleted_at_idx" ON "categories" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
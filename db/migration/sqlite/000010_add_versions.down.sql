@@ -0,0 +1,4 @@
+ALTER TABLE "food_contents" DROP COLUMN "version";
+ALTER TABLE "categories" DROP COLUMN "version";
+ALTER TABLE "food_receipts" DROP COLUMN "version";
+ALTER TABLE "expenses" DROP COLUMN "version";
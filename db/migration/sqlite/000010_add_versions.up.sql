@@ -0,0 +1,8 @@
+-- incremented on every update, delete and restore
+ALTER TABLE "expenses" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+-- incremented on every update, delete and restore
+ALTER TABLE "food_receipts" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+-- incremented on every update, delete and restore
+ALTER TABLE "categories" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+-- incremented on every update
+ALTER TABLE "food_contents" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
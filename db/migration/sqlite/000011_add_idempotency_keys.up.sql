@@ -0,0 +1,16 @@
+CREATE TABLE "idempotency_keys" (
+	"user_id" INTEGER NOT NULL CONSTRAINT "idempotency_keys_user_id_fkey" REFERENCES "users" ("id") ON DELETE CASCADE,
+	-- Idempotency-Key header sent by the client
+	"key" TEXT NOT NULL,
+	-- sha256 of the method, path and body of the first request
+	"fingerprint" TEXT NOT NULL,
+	-- null while the first request is in progress
+	"status_code" INTEGER,
+	"response_headers" TEXT NOT NULL DEFAULT '{}',
+	"response_body" BLOB NOT NULL DEFAULT X'',
+	"expires_at" DATETIME NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+	PRIMARY KEY ("user_id", "key")
+);
+
+CREATE INDEX "idempotency_keys_expires_at_idx" ON "idempotency_keys" ("expires_at");
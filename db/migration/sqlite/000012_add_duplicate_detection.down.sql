@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "expenses_user_id_created_at_idx";
+DROP INDEX IF EXISTS "food_receipts_user_id_created_at_idx";
+
+ALTER TABLE "food_receipts" DROP COLUMN "created_at";
+ALTER TABLE "food_receipts" DROP COLUMN "items_digest";
+ALTER TABLE "food_receipts" DROP COLUMN "total_price";
+ALTER TABLE "food_receipts" DROP COLUMN "user_id";
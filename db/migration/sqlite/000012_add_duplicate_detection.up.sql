@@ -0,0 +1,23 @@
+-- user who registered the receipt
+ALTER TABLE "food_receipts" ADD COLUMN "user_id" INTEGER CONSTRAINT "food_receipts_user_id_fkey" REFERENCES "users" ("id") ON DELETE RESTRICT;
+ALTER TABLE "food_receipts" ADD COLUMN "total_price" INTEGER;
+-- sha256 of the line items, independent of their order
+ALTER TABLE "food_receipts" ADD COLUMN "items_digest" TEXT;
+
+-- 既存の行は、最初に参照した支出のユーザーを所有者とする。
+-- どの支出からも参照されていないレシートは、所有者が不明なため NULL のままにする。
+UPDATE "food_receipts"
+SET "user_id" = (
+	SELECT "expenses"."user_id" FROM "expenses"
+	WHERE "expenses"."food_receipt_id" = "food_receipts"."id"
+	ORDER BY "expenses"."id"
+	LIMIT 1
+)
+WHERE "user_id" IS NULL;
+-- SQLite では、関数を既定値にした列を ALTER TABLE で追加できない。
+-- 既存の行は現在時刻で埋め、新しい行は CreateFoodReceipt で時刻を指定する。
+ALTER TABLE "food_receipts" ADD COLUMN "created_at" DATETIME;
+UPDATE "food_receipts" SET "created_at" = strftime('%Y-%m-%d %H:%M:%f000', 'now');
+
+CREATE INDEX "food_receipts_user_id_created_at_idx" ON "food_receipts" ("user_id", "created_at");
+CREATE INDEX "expenses_user_id_created_at_idx" ON "expenses" ("user_id", "created_at");
@@ -0,0 +1,4 @@
+-- time step of the last accepted TOTP code
+ALTER TABLE "users" ADD COLUMN "totp_last_counter" INTEGER;
+-- number of codes tried against the challenge
+ALTER TABLE "two_factor_challenges" ADD COLUMN "attempts" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,39 @@
+-- SQLite は制約を後から変更できないため、テーブルを作り直す。
+-- 他のテーブルから参照されていないため、外部キーには影響しない。
+-- DROP TABLE ではトリガーが実行されないため、追記のみの制約に関わらず作り直せる。
+-- AUTOINCREMENT の値が戻らないよう、sqlite_sequence も引き継ぐ。
+CREATE TABLE "audit_events_new" (
+	"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+	-- null when performed by a background worker
+	"actor_id" INTEGER CONSTRAINT "audit_events_actor_id_fkey" REFERENCES "users" ("id") ON DELETE RESTRICT,
+	-- null when authenticated with an API key
+	"session_id" TEXT,
+	"client_ip" TEXT NOT NULL,
+	"action" TEXT NOT NULL,
+	"entity_type" TEXT NOT NULL,
+	"entity_id" INTEGER NOT NULL,
+	-- JSON null when the entity was created
+	"before" TEXT NOT NULL,
+	-- JSON null when the entity was deleted
+	"after" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+INSERT INTO "audit_events_new" ("id", "actor_id", "session_id", "client_ip", "action", "entity_type", "entity_id", "before", "after", "created_at") SELECT "id", "actor_id", "session_id", "client_ip", "action", "entity_type", "entity_id", "before", "after", "created_at" FROM "audit_events";
+DELETE FROM sqlite_sequence WHERE name = 'audit_events_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'audit_events_new', seq FROM sqlite_sequence WHERE name = 'audit_events';
+DROP TABLE "audit_events";
+ALTER TABLE "audit_events_new" RENAME TO "audit_events";
+
+CREATE INDEX "audit_events_actor_id_idx" ON "audit_events" ("actor_id");
+
+CREATE TRIGGER "audit_events_reject_update"
+BEFORE UPDATE ON "audit_events"
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER "audit_events_reject_delete"
+BEFORE DELETE ON "audit_events"
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
@@ -0,0 +1,10 @@
+-- 消した個人データは元に戻らない。
+DROP TRIGGER "audit_events_reject_update";
+
+CREATE TRIGGER "audit_events_reject_update"
+BEFORE UPDATE ON "audit_events"
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+ALTER TABLE "audit_events" DROP COLUMN "redacted_at";
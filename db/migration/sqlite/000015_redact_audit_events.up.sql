@@ -0,0 +1,25 @@
+ALTER TABLE "audit_events" ADD COLUMN "redacted_at" DATETIME;
+
+-- 追記のみとするが、アカウントを削除したユーザーの行から個人データを消す更新だけは許可する。
+-- 消した行は、再び更新できない。
+DROP TRIGGER "audit_events_reject_update";
+
+CREATE TRIGGER "audit_events_reject_update"
+BEFORE UPDATE ON "audit_events"
+WHEN NOT (
+	OLD."redacted_at" IS NULL
+	AND NEW."redacted_at" IS NOT NULL
+	AND NEW."id" = OLD."id"
+	AND NEW."actor_id" IS OLD."actor_id"
+	AND NEW."action" = OLD."action"
+	AND NEW."entity_type" = OLD."entity_type"
+	AND NEW."entity_id" = OLD."entity_id"
+	AND NEW."created_at" = OLD."created_at"
+	AND NEW."session_id" IS NULL
+	AND NEW."client_ip" = ''
+	AND NEW."before" = 'null'
+	AND NEW."after" = 'null'
+)
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
@@ -7,6 +7,7 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,6 +37,110 @@ func (m *MockQuerier) EXPECT() *MockQuerierMockRecorder {
 	return m.recorder
 }
 
+// AddUserBalance mocks base method.
+func (m *MockQuerier) AddUserBalance(arg0 context.Context, arg1 db.AddUserBalanceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserBalance", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddUserBalance indicates an expected call of AddUserBalance.
+func (mr *MockQuerierMockRecorder) AddUserBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserBalance", reflect.TypeOf((*MockQuerier)(nil).AddUserBalance), arg0, arg1)
+}
+
+// AnonymizeUser mocks base method.
+func (m *MockQuerier) AnonymizeUser(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockQuerierMockRecorder) AnonymizeUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockQuerier)(nil).AnonymizeUser), arg0, arg1)
+}
+
+// CategoryHasExpenses mocks base method.
+func (m *MockQuerier) CategoryHasExpenses(arg0 context.Context, arg1 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CategoryHasExpenses", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CategoryHasExpenses indicates an expected call of CategoryHasExpenses.
+func (mr *MockQuerierMockRecorder) CategoryHasExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CategoryHasExpenses", reflect.TypeOf((*MockQuerier)(nil).CategoryHasExpenses), arg0, arg1)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockQuerier) CompleteIdempotencyKey(arg0 context.Context, arg1 db.CompleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockQuerierMockRecorder) CompleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockQuerier)(nil).CompleteIdempotencyKey), arg0, arg1)
+}
+
+// CountActiveSessions mocks base method.
+func (m *MockQuerier) CountActiveSessions(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountActiveSessions", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountActiveSessions indicates an expected call of CountActiveSessions.
+func (mr *MockQuerierMockRecorder) CountActiveSessions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountActiveSessions", reflect.TypeOf((*MockQuerier)(nil).CountActiveSessions), arg0)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockQuerier) CreateAPIKey(arg0 context.Context, arg1 db.CreateAPIKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockQuerierMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockQuerier)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockQuerier) CreateAuditEvent(arg0 context.Context, arg1 db.CreateAuditEventParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockQuerierMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockQuerier)(nil).CreateAuditEvent), arg0, arg1)
+}
+
 // CreateCategory mocks base method.
 func (m *MockQuerier) CreateCategory(arg0 context.Context, arg1 string) (db.Category, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +156,21 @@ func (mr *MockQuerierMockRecorder) CreateCategory(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockQuerier)(nil).CreateCategory), arg0, arg1)
 }
 
+// CreateEmailChangeToken mocks base method.
+func (m *MockQuerier) CreateEmailChangeToken(arg0 context.Context, arg1 db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChangeToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChangeToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChangeToken indicates an expected call of CreateEmailChangeToken.
+func (mr *MockQuerierMockRecorder) CreateEmailChangeToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChangeToken", reflect.TypeOf((*MockQuerier)(nil).CreateEmailChangeToken), arg0, arg1)
+}
+
 // CreateExpense mocks base method.
 func (m *MockQuerier) CreateExpense(arg0 context.Context, arg1 db.CreateExpenseParams) (db.Expense, error) {
 	m.ctrl.T.Helper()
@@ -82,7 +202,7 @@ func (mr *MockQuerierMockRecorder) CreateFoodContent(arg0, arg1 interface{}) *go
 }
 
 // CreateFoodReceipt mocks base method.
-func (m *MockQuerier) CreateFoodReceipt(arg0 context.Context, arg1 string) (db.FoodReceipt, error) {
+func (m *MockQuerier) CreateFoodReceipt(arg0 context.Context, arg1 db.CreateFoodReceiptParams) (db.FoodReceipt, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateFoodReceipt", arg0, arg1)
 	ret0, _ := ret[0].(db.FoodReceipt)
@@ -111,6 +231,36 @@ func (mr *MockQuerierMockRecorder) CreateFoodReceiptContent(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFoodReceiptContent", reflect.TypeOf((*MockQuerier)(nil).CreateFoodReceiptContent), arg0, arg1)
 }
 
+// CreateIdempotencyKey mocks base method.
+func (m *MockQuerier) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockQuerierMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockQuerier)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockQuerier) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockQuerierMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockQuerier)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockQuerier) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +276,36 @@ func (mr *MockQuerierMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockQuerier)(nil).CreateSession), arg0, arg1)
 }
 
+// CreateTransfer mocks base method.
+func (m *MockQuerier) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockQuerierMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockQuerier)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateTwoFactorChallenge mocks base method.
+func (m *MockQuerier) CreateTwoFactorChallenge(arg0 context.Context, arg1 db.CreateTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTwoFactorChallenge indicates an expected call of CreateTwoFactorChallenge.
+func (mr *MockQuerierMockRecorder) CreateTwoFactorChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTwoFactorChallenge", reflect.TypeOf((*MockQuerier)(nil).CreateTwoFactorChallenge), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockQuerier) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -141,6 +321,92 @@ func (mr *MockQuerierMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockQuerier)(nil).CreateUser), arg0, arg1)
 }
 
+// DeleteCategory mocks base method.
+func (m *MockQuerier) DeleteCategory(arg0 context.Context, arg1 db.DeleteCategoryParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockQuerierMockRecorder) DeleteCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockQuerier)(nil).DeleteCategory), arg0, arg1)
+}
+
+// DeleteFoodContent mocks base method.
+func (m *MockQuerier) DeleteFoodContent(arg0 context.Context, arg1 db.DeleteFoodContentParams) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFoodContent indicates an expected call of DeleteFoodContent.
+func (mr *MockQuerierMockRecorder) DeleteFoodContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFoodContent", reflect.TypeOf((*MockQuerier)(nil).DeleteFoodContent), arg0, arg1)
+}
+
+// DeleteFoodReceiptContents mocks base method.
+func (m *MockQuerier) DeleteFoodReceiptContents(arg0 context.Context, arg1 []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFoodReceiptContents indicates an expected call of DeleteFoodReceiptContents.
+func (mr *MockQuerierMockRecorder) DeleteFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFoodReceiptContents", reflect.TypeOf((*MockQuerier)(nil).DeleteFoodReceiptContents), arg0, arg1)
+}
+
+// DeleteFoodReceipts mocks base method.
+func (m *MockQuerier) DeleteFoodReceipts(arg0 context.Context, arg1 []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFoodReceipts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFoodReceipts indicates an expected call of DeleteFoodReceipts.
+func (mr *MockQuerierMockRecorder) DeleteFoodReceipts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFoodReceipts", reflect.TypeOf((*MockQuerier)(nil).DeleteFoodReceipts), arg0, arg1)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockQuerier) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockQuerierMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockQuerier)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockQuerier) DeleteRecoveryCodes(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockQuerierMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockQuerier)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
 // DeleteSession mocks base method.
 func (m *MockQuerier) DeleteSession(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -155,106 +421,1012 @@ func (mr *MockQuerierMockRecorder) DeleteSession(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockQuerier)(nil).DeleteSession), arg0, arg1)
 }
 
-// GetFoodContent mocks base method.
-func (m *MockQuerier) GetFoodContent(arg0 context.Context, arg1 int64) (db.FoodContent, error) {
+// DeleteTwoFactorChallenge mocks base method.
+func (m *MockQuerier) DeleteTwoFactorChallenge(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFoodContent", arg0, arg1)
-	ret0, _ := ret[0].(db.FoodContent)
+	ret := m.ctrl.Call(m, "DeleteTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTwoFactorChallenge indicates an expected call of DeleteTwoFactorChallenge.
+func (mr *MockQuerierMockRecorder) DeleteTwoFactorChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTwoFactorChallenge", reflect.TypeOf((*MockQuerier)(nil).DeleteTwoFactorChallenge), arg0, arg1)
+}
+
+// DeleteUserAPIKeys mocks base method.
+func (m *MockQuerier) DeleteUserAPIKeys(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserAPIKeys indicates an expected call of DeleteUserAPIKeys.
+func (mr *MockQuerierMockRecorder) DeleteUserAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAPIKeys", reflect.TypeOf((*MockQuerier)(nil).DeleteUserAPIKeys), arg0, arg1)
+}
+
+// DeleteUserEmailChangeTokens mocks base method.
+func (m *MockQuerier) DeleteUserEmailChangeTokens(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserEmailChangeTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserEmailChangeTokens indicates an expected call of DeleteUserEmailChangeTokens.
+func (mr *MockQuerierMockRecorder) DeleteUserEmailChangeTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserEmailChangeTokens", reflect.TypeOf((*MockQuerier)(nil).DeleteUserEmailChangeTokens), arg0, arg1)
+}
+
+// DeleteUserExpenses mocks base method.
+func (m *MockQuerier) DeleteUserExpenses(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserExpenses", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFoodContent indicates an expected call of GetFoodContent.
-func (mr *MockQuerierMockRecorder) GetFoodContent(arg0, arg1 interface{}) *gomock.Call {
+// DeleteUserExpenses indicates an expected call of DeleteUserExpenses.
+func (mr *MockQuerierMockRecorder) DeleteUserExpenses(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodContent", reflect.TypeOf((*MockQuerier)(nil).GetFoodContent), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserExpenses", reflect.TypeOf((*MockQuerier)(nil).DeleteUserExpenses), arg0, arg1)
 }
 
-// GetFoodReceipt mocks base method.
-func (m *MockQuerier) GetFoodReceipt(arg0 context.Context, arg1 int64) (db.FoodReceipt, error) {
+// DeleteUserIdempotencyKeys mocks base method.
+func (m *MockQuerier) DeleteUserIdempotencyKeys(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFoodReceipt", arg0, arg1)
-	ret0, _ := ret[0].(db.FoodReceipt)
+	ret := m.ctrl.Call(m, "DeleteUserIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserIdempotencyKeys indicates an expected call of DeleteUserIdempotencyKeys.
+func (mr *MockQuerierMockRecorder) DeleteUserIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIdempotencyKeys", reflect.TypeOf((*MockQuerier)(nil).DeleteUserIdempotencyKeys), arg0, arg1)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockQuerier) DeleteUserSessions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockQuerierMockRecorder) DeleteUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockQuerier)(nil).DeleteUserSessions), arg0, arg1)
+}
+
+// DeleteUserTwoFactorChallenges mocks base method.
+func (m *MockQuerier) DeleteUserTwoFactorChallenges(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTwoFactorChallenges", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTwoFactorChallenges indicates an expected call of DeleteUserTwoFactorChallenges.
+func (mr *MockQuerierMockRecorder) DeleteUserTwoFactorChallenges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTwoFactorChallenges", reflect.TypeOf((*MockQuerier)(nil).DeleteUserTwoFactorChallenges), arg0, arg1)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockQuerier) EnableUserTOTP(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFoodReceipt indicates an expected call of GetFoodReceipt.
-func (mr *MockQuerierMockRecorder) GetFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockQuerierMockRecorder) EnableUserTOTP(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodReceipt", reflect.TypeOf((*MockQuerier)(nil).GetFoodReceipt), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockQuerier)(nil).EnableUserTOTP), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockQuerier) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// GetAPIKeyByHash mocks base method.
+func (m *MockQuerier) GetAPIKeyByHash(arg0 context.Context, arg1 string) (db.GetAPIKeyByHashRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAPIKeyByHashRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockQuerierMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockQuerierMockRecorder) GetAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockQuerier)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockQuerier)(nil).GetAPIKeyByHash), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockQuerier) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// GetAuthSession mocks base method.
+func (m *MockQuerier) GetAuthSession(arg0 context.Context, arg1 uuid.UUID) (db.GetAuthSessionRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "GetAuthSession", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAuthSessionRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockQuerierMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// GetAuthSession indicates an expected call of GetAuthSession.
+func (mr *MockQuerierMockRecorder) GetAuthSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockQuerier)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthSession", reflect.TypeOf((*MockQuerier)(nil).GetAuthSession), arg0, arg1)
 }
 
-// ListExpenses mocks base method.
-func (m *MockQuerier) ListExpenses(arg0 context.Context, arg1 int64) ([]db.ListExpensesRow, error) {
+// GetCategoryForUpdate mocks base method.
+func (m *MockQuerier) GetCategoryForUpdate(arg0 context.Context, arg1 int64) (db.Category, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListExpenses", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListExpensesRow)
+	ret := m.ctrl.Call(m, "GetCategoryForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListExpenses indicates an expected call of ListExpenses.
-func (mr *MockQuerierMockRecorder) ListExpenses(arg0, arg1 interface{}) *gomock.Call {
+// GetCategoryForUpdate indicates an expected call of GetCategoryForUpdate.
+func (mr *MockQuerierMockRecorder) GetCategoryForUpdate(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpenses", reflect.TypeOf((*MockQuerier)(nil).ListExpenses), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetCategoryForUpdate), arg0, arg1)
 }
 
-// ListFoodReceiptContents mocks base method.
-func (m *MockQuerier) ListFoodReceiptContents(arg0 context.Context, arg1 int64) ([]db.ListFoodReceiptContentsRow, error) {
+// GetExpenseForUpdate mocks base method.
+func (m *MockQuerier) GetExpenseForUpdate(arg0 context.Context, arg1 db.GetExpenseForUpdateParams) (db.Expense, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListFoodReceiptContents", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListFoodReceiptContentsRow)
+	ret := m.ctrl.Call(m, "GetExpenseForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListFoodReceiptContents indicates an expected call of ListFoodReceiptContents.
-func (mr *MockQuerierMockRecorder) ListFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+// GetExpenseForUpdate indicates an expected call of GetExpenseForUpdate.
+func (mr *MockQuerierMockRecorder) GetExpenseForUpdate(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFoodReceiptContents", reflect.TypeOf((*MockQuerier)(nil).ListFoodReceiptContents), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpenseForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetExpenseForUpdate), arg0, arg1)
 }
 
-// UpdateSession mocks base method.
-func (m *MockQuerier) UpdateSession(arg0 context.Context, arg1 db.UpdateSessionParams) error {
+// GetFoodContent mocks base method.
+func (m *MockQuerier) GetFoodContent(arg0 context.Context, arg1 int64) (db.FoodContent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSession", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateSession indicates an expected call of UpdateSession.
-func (mr *MockQuerierMockRecorder) UpdateSession(arg0, arg1 interface{}) *gomock.Call {
+// GetFoodContent indicates an expected call of GetFoodContent.
+func (mr *MockQuerierMockRecorder) GetFoodContent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockQuerier)(nil).UpdateSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodContent", reflect.TypeOf((*MockQuerier)(nil).GetFoodContent), arg0, arg1)
+}
+
+// GetFoodContentByName mocks base method.
+func (m *MockQuerier) GetFoodContentByName(arg0 context.Context, arg1 string) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodContentByName", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodContentByName indicates an expected call of GetFoodContentByName.
+func (mr *MockQuerierMockRecorder) GetFoodContentByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodContentByName", reflect.TypeOf((*MockQuerier)(nil).GetFoodContentByName), arg0, arg1)
+}
+
+// GetFoodReceipt mocks base method.
+func (m *MockQuerier) GetFoodReceipt(arg0 context.Context, arg1 int64) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodReceipt indicates an expected call of GetFoodReceipt.
+func (mr *MockQuerierMockRecorder) GetFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodReceipt", reflect.TypeOf((*MockQuerier)(nil).GetFoodReceipt), arg0, arg1)
+}
+
+// GetFoodReceiptForUpdate mocks base method.
+func (m *MockQuerier) GetFoodReceiptForUpdate(arg0 context.Context, arg1 db.GetFoodReceiptForUpdateParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodReceiptForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodReceiptForUpdate indicates an expected call of GetFoodReceiptForUpdate.
+func (mr *MockQuerierMockRecorder) GetFoodReceiptForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodReceiptForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetFoodReceiptForUpdate), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockQuerier) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockQuerierMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockQuerier)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockQuerier) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockQuerierMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockQuerier)(nil).GetSession), arg0, arg1)
+}
+
+// GetTwoFactorChallenge mocks base method.
+func (m *MockQuerier) GetTwoFactorChallenge(arg0 context.Context, arg1 uuid.UUID) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorChallenge indicates an expected call of GetTwoFactorChallenge.
+func (mr *MockQuerierMockRecorder) GetTwoFactorChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorChallenge", reflect.TypeOf((*MockQuerier)(nil).GetTwoFactorChallenge), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockQuerier) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockQuerierMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockQuerier)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByID mocks base method.
+func (m *MockQuerier) GetUserByID(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockQuerierMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockQuerier)(nil).GetUserByID), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockQuerier) ListAPIKeys(arg0 context.Context, arg1 int64) ([]db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockQuerierMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockQuerier)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockQuerier) ListAuditEvents(arg0 context.Context, arg1 db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockQuerierMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockQuerier)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListCategories mocks base method.
+func (m *MockQuerier) ListCategories(arg0 context.Context) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", arg0)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockQuerierMockRecorder) ListCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockQuerier)(nil).ListCategories), arg0)
+}
+
+// ListDeletedCategories mocks base method.
+func (m *MockQuerier) ListDeletedCategories(arg0 context.Context) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedCategories", arg0)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedCategories indicates an expected call of ListDeletedCategories.
+func (mr *MockQuerierMockRecorder) ListDeletedCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedCategories", reflect.TypeOf((*MockQuerier)(nil).ListDeletedCategories), arg0)
+}
+
+// ListDeletedExpenses mocks base method.
+func (m *MockQuerier) ListDeletedExpenses(arg0 context.Context, arg1 int64) ([]db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedExpenses indicates an expected call of ListDeletedExpenses.
+func (mr *MockQuerierMockRecorder) ListDeletedExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedExpenses", reflect.TypeOf((*MockQuerier)(nil).ListDeletedExpenses), arg0, arg1)
+}
+
+// ListDeletedFoodReceipts mocks base method.
+func (m *MockQuerier) ListDeletedFoodReceipts(arg0 context.Context, arg1 int64) ([]db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedFoodReceipts", arg0, arg1)
+	ret0, _ := ret[0].([]db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedFoodReceipts indicates an expected call of ListDeletedFoodReceipts.
+func (mr *MockQuerierMockRecorder) ListDeletedFoodReceipts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedFoodReceipts", reflect.TypeOf((*MockQuerier)(nil).ListDeletedFoodReceipts), arg0, arg1)
+}
+
+// ListDuplicateExpenseCandidates mocks base method.
+func (m *MockQuerier) ListDuplicateExpenseCandidates(arg0 context.Context, arg1 db.ListDuplicateExpenseCandidatesParams) ([]db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDuplicateExpenseCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDuplicateExpenseCandidates indicates an expected call of ListDuplicateExpenseCandidates.
+func (mr *MockQuerierMockRecorder) ListDuplicateExpenseCandidates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDuplicateExpenseCandidates", reflect.TypeOf((*MockQuerier)(nil).ListDuplicateExpenseCandidates), arg0, arg1)
+}
+
+// ListDuplicateExpenses mocks base method.
+func (m *MockQuerier) ListDuplicateExpenses(arg0 context.Context, arg1 db.ListDuplicateExpensesParams) ([]db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDuplicateExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDuplicateExpenses indicates an expected call of ListDuplicateExpenses.
+func (mr *MockQuerierMockRecorder) ListDuplicateExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDuplicateExpenses", reflect.TypeOf((*MockQuerier)(nil).ListDuplicateExpenses), arg0, arg1)
+}
+
+// ListDuplicateFoodReceiptCandidates mocks base method.
+func (m *MockQuerier) ListDuplicateFoodReceiptCandidates(arg0 context.Context, arg1 db.ListDuplicateFoodReceiptCandidatesParams) ([]db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDuplicateFoodReceiptCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDuplicateFoodReceiptCandidates indicates an expected call of ListDuplicateFoodReceiptCandidates.
+func (mr *MockQuerierMockRecorder) ListDuplicateFoodReceiptCandidates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDuplicateFoodReceiptCandidates", reflect.TypeOf((*MockQuerier)(nil).ListDuplicateFoodReceiptCandidates), arg0, arg1)
+}
+
+// ListExpenses mocks base method.
+func (m *MockQuerier) ListExpenses(arg0 context.Context, arg1 int64) ([]db.ListExpensesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListExpensesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpenses indicates an expected call of ListExpenses.
+func (mr *MockQuerierMockRecorder) ListExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpenses", reflect.TypeOf((*MockQuerier)(nil).ListExpenses), arg0, arg1)
+}
+
+// ListExportExpenses mocks base method.
+func (m *MockQuerier) ListExportExpenses(arg0 context.Context, arg1 int64) ([]db.ListExportExpensesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExportExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListExportExpensesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExportExpenses indicates an expected call of ListExportExpenses.
+func (mr *MockQuerierMockRecorder) ListExportExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExportExpenses", reflect.TypeOf((*MockQuerier)(nil).ListExportExpenses), arg0, arg1)
+}
+
+// ListFoodContents mocks base method.
+func (m *MockQuerier) ListFoodContents(arg0 context.Context, arg1 db.ListFoodContentsParams) ([]db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFoodContents", arg0, arg1)
+	ret0, _ := ret[0].([]db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFoodContents indicates an expected call of ListFoodContents.
+func (mr *MockQuerierMockRecorder) ListFoodContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFoodContents", reflect.TypeOf((*MockQuerier)(nil).ListFoodContents), arg0, arg1)
+}
+
+// ListFoodReceiptContents mocks base method.
+func (m *MockQuerier) ListFoodReceiptContents(arg0 context.Context, arg1 int64) ([]db.ListFoodReceiptContentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListFoodReceiptContentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFoodReceiptContents indicates an expected call of ListFoodReceiptContents.
+func (mr *MockQuerierMockRecorder) ListFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFoodReceiptContents", reflect.TypeOf((*MockQuerier)(nil).ListFoodReceiptContents), arg0, arg1)
+}
+
+// ListUserAuditEvents mocks base method.
+func (m *MockQuerier) ListUserAuditEvents(arg0 context.Context, arg1 db.ListUserAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserAuditEvents indicates an expected call of ListUserAuditEvents.
+func (mr *MockQuerierMockRecorder) ListUserAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAuditEvents", reflect.TypeOf((*MockQuerier)(nil).ListUserAuditEvents), arg0, arg1)
+}
+
+// ListUserFoodReceiptContents mocks base method.
+func (m *MockQuerier) ListUserFoodReceiptContents(arg0 context.Context, arg1 int64) ([]db.ListUserFoodReceiptContentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListUserFoodReceiptContentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserFoodReceiptContents indicates an expected call of ListUserFoodReceiptContents.
+func (mr *MockQuerierMockRecorder) ListUserFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserFoodReceiptContents", reflect.TypeOf((*MockQuerier)(nil).ListUserFoodReceiptContents), arg0, arg1)
+}
+
+// ListUserFoodReceiptIDs mocks base method.
+func (m *MockQuerier) ListUserFoodReceiptIDs(arg0 context.Context, arg1 int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserFoodReceiptIDs", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserFoodReceiptIDs indicates an expected call of ListUserFoodReceiptIDs.
+func (mr *MockQuerierMockRecorder) ListUserFoodReceiptIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserFoodReceiptIDs", reflect.TypeOf((*MockQuerier)(nil).ListUserFoodReceiptIDs), arg0, arg1)
+}
+
+// ListUserSessions mocks base method.
+func (m *MockQuerier) ListUserSessions(arg0 context.Context, arg1 int64) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockQuerierMockRecorder) ListUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockQuerier)(nil).ListUserSessions), arg0, arg1)
+}
+
+// ListUserTransfers mocks base method.
+func (m *MockQuerier) ListUserTransfers(arg0 context.Context, arg1 int64) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserTransfers indicates an expected call of ListUserTransfers.
+func (mr *MockQuerierMockRecorder) ListUserTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserTransfers", reflect.TypeOf((*MockQuerier)(nil).ListUserTransfers), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockQuerier) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockQuerierMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockQuerier)(nil).ListUsers), arg0, arg1)
+}
+
+// ListUsersDueForDeletion mocks base method.
+func (m *MockQuerier) ListUsersDueForDeletion(arg0 context.Context, arg1 db.ListUsersDueForDeletionParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersDueForDeletion", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersDueForDeletion indicates an expected call of ListUsersDueForDeletion.
+func (mr *MockQuerierMockRecorder) ListUsersDueForDeletion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersDueForDeletion", reflect.TypeOf((*MockQuerier)(nil).ListUsersDueForDeletion), arg0, arg1)
+}
+
+// PurgeDeletedCategories mocks base method.
+func (m *MockQuerier) PurgeDeletedCategories(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedCategories", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedCategories indicates an expected call of PurgeDeletedCategories.
+func (mr *MockQuerierMockRecorder) PurgeDeletedCategories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedCategories", reflect.TypeOf((*MockQuerier)(nil).PurgeDeletedCategories), arg0, arg1)
+}
+
+// PurgeDeletedExpenses mocks base method.
+func (m *MockQuerier) PurgeDeletedExpenses(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedExpenses indicates an expected call of PurgeDeletedExpenses.
+func (mr *MockQuerierMockRecorder) PurgeDeletedExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedExpenses", reflect.TypeOf((*MockQuerier)(nil).PurgeDeletedExpenses), arg0, arg1)
+}
+
+// PurgeDeletedFoodReceiptContents mocks base method.
+func (m *MockQuerier) PurgeDeletedFoodReceiptContents(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeDeletedFoodReceiptContents indicates an expected call of PurgeDeletedFoodReceiptContents.
+func (mr *MockQuerierMockRecorder) PurgeDeletedFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedFoodReceiptContents", reflect.TypeOf((*MockQuerier)(nil).PurgeDeletedFoodReceiptContents), arg0, arg1)
+}
+
+// PurgeDeletedFoodReceipts mocks base method.
+func (m *MockQuerier) PurgeDeletedFoodReceipts(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedFoodReceipts", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedFoodReceipts indicates an expected call of PurgeDeletedFoodReceipts.
+func (mr *MockQuerierMockRecorder) PurgeDeletedFoodReceipts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedFoodReceipts", reflect.TypeOf((*MockQuerier)(nil).PurgeDeletedFoodReceipts), arg0, arg1)
+}
+
+// PurgeExpiredIdempotencyKeys mocks base method.
+func (m *MockQuerier) PurgeExpiredIdempotencyKeys(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredIdempotencyKeys indicates an expected call of PurgeExpiredIdempotencyKeys.
+func (mr *MockQuerierMockRecorder) PurgeExpiredIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredIdempotencyKeys", reflect.TypeOf((*MockQuerier)(nil).PurgeExpiredIdempotencyKeys), arg0, arg1)
+}
+
+// PurgeUserSessions mocks base method.
+func (m *MockQuerier) PurgeUserSessions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUserSessions indicates an expected call of PurgeUserSessions.
+func (mr *MockQuerierMockRecorder) PurgeUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUserSessions", reflect.TypeOf((*MockQuerier)(nil).PurgeUserSessions), arg0, arg1)
+}
+
+// RedactUserAuditEvents mocks base method.
+func (m *MockQuerier) RedactUserAuditEvents(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedactUserAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedactUserAuditEvents indicates an expected call of RedactUserAuditEvents.
+func (mr *MockQuerierMockRecorder) RedactUserAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedactUserAuditEvents", reflect.TypeOf((*MockQuerier)(nil).RedactUserAuditEvents), arg0, arg1)
+}
+
+// RestoreCategory mocks base method.
+func (m *MockQuerier) RestoreCategory(arg0 context.Context, arg1 int64) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreCategory indicates an expected call of RestoreCategory.
+func (mr *MockQuerierMockRecorder) RestoreCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCategory", reflect.TypeOf((*MockQuerier)(nil).RestoreCategory), arg0, arg1)
+}
+
+// RestoreExpense mocks base method.
+func (m *MockQuerier) RestoreExpense(arg0 context.Context, arg1 db.RestoreExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreExpense indicates an expected call of RestoreExpense.
+func (mr *MockQuerierMockRecorder) RestoreExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreExpense", reflect.TypeOf((*MockQuerier)(nil).RestoreExpense), arg0, arg1)
+}
+
+// RestoreFoodReceipt mocks base method.
+func (m *MockQuerier) RestoreFoodReceipt(arg0 context.Context, arg1 db.RestoreFoodReceiptParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFoodReceipt indicates an expected call of RestoreFoodReceipt.
+func (mr *MockQuerierMockRecorder) RestoreFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFoodReceipt", reflect.TypeOf((*MockQuerier)(nil).RestoreFoodReceipt), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockQuerier) RevokeAPIKey(arg0 context.Context, arg1 db.RevokeAPIKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockQuerierMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockQuerier)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// SoftDeleteExpense mocks base method.
+func (m *MockQuerier) SoftDeleteExpense(arg0 context.Context, arg1 db.SoftDeleteExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteExpense indicates an expected call of SoftDeleteExpense.
+func (mr *MockQuerierMockRecorder) SoftDeleteExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteExpense", reflect.TypeOf((*MockQuerier)(nil).SoftDeleteExpense), arg0, arg1)
+}
+
+// SoftDeleteFoodReceipt mocks base method.
+func (m *MockQuerier) SoftDeleteFoodReceipt(arg0 context.Context, arg1 db.SoftDeleteFoodReceiptParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteFoodReceipt indicates an expected call of SoftDeleteFoodReceipt.
+func (mr *MockQuerierMockRecorder) SoftDeleteFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteFoodReceipt", reflect.TypeOf((*MockQuerier)(nil).SoftDeleteFoodReceipt), arg0, arg1)
+}
+
+// UpdateAPIKeyLastUsed mocks base method.
+func (m *MockQuerier) UpdateAPIKeyLastUsed(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed.
+func (mr *MockQuerierMockRecorder) UpdateAPIKeyLastUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockQuerier)(nil).UpdateAPIKeyLastUsed), arg0, arg1)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockQuerier) UpdateCategory(arg0 context.Context, arg1 db.UpdateCategoryParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockQuerierMockRecorder) UpdateCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockQuerier)(nil).UpdateCategory), arg0, arg1)
+}
+
+// UpdateExpense mocks base method.
+func (m *MockQuerier) UpdateExpense(arg0 context.Context, arg1 db.UpdateExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateExpense indicates an expected call of UpdateExpense.
+func (mr *MockQuerierMockRecorder) UpdateExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExpense", reflect.TypeOf((*MockQuerier)(nil).UpdateExpense), arg0, arg1)
+}
+
+// UpdateFoodContent mocks base method.
+func (m *MockQuerier) UpdateFoodContent(arg0 context.Context, arg1 db.UpdateFoodContentParams) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFoodContent indicates an expected call of UpdateFoodContent.
+func (mr *MockQuerierMockRecorder) UpdateFoodContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFoodContent", reflect.TypeOf((*MockQuerier)(nil).UpdateFoodContent), arg0, arg1)
+}
+
+// UpdateSession mocks base method.
+func (m *MockQuerier) UpdateSession(arg0 context.Context, arg1 db.UpdateSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSession indicates an expected call of UpdateSession.
+func (mr *MockQuerierMockRecorder) UpdateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockQuerier)(nil).UpdateSession), arg0, arg1)
+}
+
+// UpdateUserDeletionScheduledAt mocks base method.
+func (m *MockQuerier) UpdateUserDeletionScheduledAt(arg0 context.Context, arg1 db.UpdateUserDeletionScheduledAtParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDeletionScheduledAt", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserDeletionScheduledAt indicates an expected call of UpdateUserDeletionScheduledAt.
+func (mr *MockQuerierMockRecorder) UpdateUserDeletionScheduledAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDeletionScheduledAt", reflect.TypeOf((*MockQuerier)(nil).UpdateUserDeletionScheduledAt), arg0, arg1)
+}
+
+// UpdateUserDisabledAt mocks base method.
+func (m *MockQuerier) UpdateUserDisabledAt(arg0 context.Context, arg1 db.UpdateUserDisabledAtParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDisabledAt", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserDisabledAt indicates an expected call of UpdateUserDisabledAt.
+func (mr *MockQuerierMockRecorder) UpdateUserDisabledAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDisabledAt", reflect.TypeOf((*MockQuerier)(nil).UpdateUserDisabledAt), arg0, arg1)
+}
+
+// UpdateUserEmail mocks base method.
+func (m *MockQuerier) UpdateUserEmail(arg0 context.Context, arg1 db.UpdateUserEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockQuerierMockRecorder) UpdateUserEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockQuerier)(nil).UpdateUserEmail), arg0, arg1)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockQuerier) UpdateUserProfile(arg0 context.Context, arg1 db.UpdateUserProfileParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockQuerierMockRecorder) UpdateUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockQuerier)(nil).UpdateUserProfile), arg0, arg1)
+}
+
+// UpdateUserTOTPLastCounter mocks base method.
+func (m *MockQuerier) UpdateUserTOTPLastCounter(arg0 context.Context, arg1 db.UpdateUserTOTPLastCounterParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPLastCounter", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPLastCounter indicates an expected call of UpdateUserTOTPLastCounter.
+func (mr *MockQuerierMockRecorder) UpdateUserTOTPLastCounter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPLastCounter", reflect.TypeOf((*MockQuerier)(nil).UpdateUserTOTPLastCounter), arg0, arg1)
+}
+
+// UpdateUserTOTPSecret mocks base method.
+func (m *MockQuerier) UpdateUserTOTPSecret(arg0 context.Context, arg1 db.UpdateUserTOTPSecretParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPSecret indicates an expected call of UpdateUserTOTPSecret.
+func (mr *MockQuerierMockRecorder) UpdateUserTOTPSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPSecret", reflect.TypeOf((*MockQuerier)(nil).UpdateUserTOTPSecret), arg0, arg1)
+}
+
+// UseEmailChangeToken mocks base method.
+func (m *MockQuerier) UseEmailChangeToken(arg0 context.Context, arg1 string) (db.EmailChangeToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailChangeToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChangeToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailChangeToken indicates an expected call of UseEmailChangeToken.
+func (mr *MockQuerierMockRecorder) UseEmailChangeToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailChangeToken", reflect.TypeOf((*MockQuerier)(nil).UseEmailChangeToken), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockQuerier) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockQuerierMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockQuerier)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTwoFactorChallengeAttempt mocks base method.
+func (m *MockQuerier) UseTwoFactorChallengeAttempt(arg0 context.Context, arg1 db.UseTwoFactorChallengeAttemptParams) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTwoFactorChallengeAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTwoFactorChallengeAttempt indicates an expected call of UseTwoFactorChallengeAttempt.
+func (mr *MockQuerierMockRecorder) UseTwoFactorChallengeAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTwoFactorChallengeAttempt", reflect.TypeOf((*MockQuerier)(nil).UseTwoFactorChallengeAttempt), arg0, arg1)
 }
@@ -0,0 +1,1701 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/kokoichi206/account-book-api/db/sqlc (interfaces: Store)
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// AddUserBalance mocks base method.
+func (m *MockStore) AddUserBalance(arg0 context.Context, arg1 db.AddUserBalanceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserBalance", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddUserBalance indicates an expected call of AddUserBalance.
+func (mr *MockStoreMockRecorder) AddUserBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserBalance", reflect.TypeOf((*MockStore)(nil).AddUserBalance), arg0, arg1)
+}
+
+// AdjustBalanceTx mocks base method.
+func (m *MockStore) AdjustBalanceTx(arg0 context.Context, arg1 db.AdjustBalanceTxParams, arg2 db.AuditActor) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalanceTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalanceTx indicates an expected call of AdjustBalanceTx.
+func (mr *MockStoreMockRecorder) AdjustBalanceTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalanceTx", reflect.TypeOf((*MockStore)(nil).AdjustBalanceTx), arg0, arg1, arg2)
+}
+
+// AnonymizeUser mocks base method.
+func (m *MockStore) AnonymizeUser(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockStoreMockRecorder) AnonymizeUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockStore)(nil).AnonymizeUser), arg0, arg1)
+}
+
+// CategoryHasExpenses mocks base method.
+func (m *MockStore) CategoryHasExpenses(arg0 context.Context, arg1 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CategoryHasExpenses", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CategoryHasExpenses indicates an expected call of CategoryHasExpenses.
+func (mr *MockStoreMockRecorder) CategoryHasExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CategoryHasExpenses", reflect.TypeOf((*MockStore)(nil).CategoryHasExpenses), arg0, arg1)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockStore) CompleteIdempotencyKey(arg0 context.Context, arg1 db.CompleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) CompleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CompleteIdempotencyKey), arg0, arg1)
+}
+
+// ConfirmEmailChangeTx mocks base method.
+func (m *MockStore) ConfirmEmailChangeTx(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChangeTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChangeTx indicates an expected call of ConfirmEmailChangeTx.
+func (mr *MockStoreMockRecorder) ConfirmEmailChangeTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChangeTx", reflect.TypeOf((*MockStore)(nil).ConfirmEmailChangeTx), arg0, arg1)
+}
+
+// CountActiveSessions mocks base method.
+func (m *MockStore) CountActiveSessions(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountActiveSessions", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountActiveSessions indicates an expected call of CountActiveSessions.
+func (mr *MockStoreMockRecorder) CountActiveSessions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountActiveSessions", reflect.TypeOf((*MockStore)(nil).CountActiveSessions), arg0)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockStore) CreateAPIKey(arg0 context.Context, arg1 db.CreateAPIKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStoreMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(arg0 context.Context, arg1 db.CreateAuditEventParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateCategory mocks base method.
+func (m *MockStore) CreateCategory(arg0 context.Context, arg1 string) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockStoreMockRecorder) CreateCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockStore)(nil).CreateCategory), arg0, arg1)
+}
+
+// CreateCategoryTx mocks base method.
+func (m *MockStore) CreateCategoryTx(arg0 context.Context, arg1 string, arg2 db.AuditActor) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategoryTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategoryTx indicates an expected call of CreateCategoryTx.
+func (mr *MockStoreMockRecorder) CreateCategoryTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryTx", reflect.TypeOf((*MockStore)(nil).CreateCategoryTx), arg0, arg1, arg2)
+}
+
+// CreateEmailChangeToken mocks base method.
+func (m *MockStore) CreateEmailChangeToken(arg0 context.Context, arg1 db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChangeToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChangeToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChangeToken indicates an expected call of CreateEmailChangeToken.
+func (mr *MockStoreMockRecorder) CreateEmailChangeToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChangeToken", reflect.TypeOf((*MockStore)(nil).CreateEmailChangeToken), arg0, arg1)
+}
+
+// CreateExpense mocks base method.
+func (m *MockStore) CreateExpense(arg0 context.Context, arg1 db.CreateExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExpense indicates an expected call of CreateExpense.
+func (mr *MockStoreMockRecorder) CreateExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExpense", reflect.TypeOf((*MockStore)(nil).CreateExpense), arg0, arg1)
+}
+
+// CreateExpenseTx mocks base method.
+func (m *MockStore) CreateExpenseTx(arg0 context.Context, arg1 db.CreateExpenseParams, arg2 db.AuditActor) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExpenseTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExpenseTx indicates an expected call of CreateExpenseTx.
+func (mr *MockStoreMockRecorder) CreateExpenseTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExpenseTx", reflect.TypeOf((*MockStore)(nil).CreateExpenseTx), arg0, arg1, arg2)
+}
+
+// CreateFoodContent mocks base method.
+func (m *MockStore) CreateFoodContent(arg0 context.Context, arg1 db.CreateFoodContentParams) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFoodContent indicates an expected call of CreateFoodContent.
+func (mr *MockStoreMockRecorder) CreateFoodContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFoodContent", reflect.TypeOf((*MockStore)(nil).CreateFoodContent), arg0, arg1)
+}
+
+// CreateFoodReceipt mocks base method.
+func (m *MockStore) CreateFoodReceipt(arg0 context.Context, arg1 db.CreateFoodReceiptParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFoodReceipt indicates an expected call of CreateFoodReceipt.
+func (mr *MockStoreMockRecorder) CreateFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFoodReceipt", reflect.TypeOf((*MockStore)(nil).CreateFoodReceipt), arg0, arg1)
+}
+
+// CreateFoodReceiptContent mocks base method.
+func (m *MockStore) CreateFoodReceiptContent(arg0 context.Context, arg1 db.CreateFoodReceiptContentParams) (db.FoodReceiptContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFoodReceiptContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceiptContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFoodReceiptContent indicates an expected call of CreateFoodReceiptContent.
+func (mr *MockStoreMockRecorder) CreateFoodReceiptContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFoodReceiptContent", reflect.TypeOf((*MockStore)(nil).CreateFoodReceiptContent), arg0, arg1)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
+// CreateReceiptTx mocks base method.
+func (m *MockStore) CreateReceiptTx(arg0 context.Context, arg1 db.CreateReceiptTxParams, arg2 db.AuditActor) (db.CreateReceiptTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReceiptTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.CreateReceiptTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReceiptTx indicates an expected call of CreateReceiptTx.
+func (mr *MockStoreMockRecorder) CreateReceiptTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReceiptTx", reflect.TypeOf((*MockStore)(nil).CreateReceiptTx), arg0, arg1, arg2)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateTransferTx mocks base method.
+func (m *MockStore) CreateTransferTx(arg0 context.Context, arg1 db.CreateTransferParams, arg2 db.AuditActor) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferTx indicates an expected call of CreateTransferTx.
+func (mr *MockStoreMockRecorder) CreateTransferTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferTx", reflect.TypeOf((*MockStore)(nil).CreateTransferTx), arg0, arg1, arg2)
+}
+
+// CreateTwoFactorChallenge mocks base method.
+func (m *MockStore) CreateTwoFactorChallenge(arg0 context.Context, arg1 db.CreateTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTwoFactorChallenge indicates an expected call of CreateTwoFactorChallenge.
+func (mr *MockStoreMockRecorder) CreateTwoFactorChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTwoFactorChallenge", reflect.TypeOf((*MockStore)(nil).CreateTwoFactorChallenge), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockStore) DeleteCategory(arg0 context.Context, arg1 db.DeleteCategoryParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockStoreMockRecorder) DeleteCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockStore)(nil).DeleteCategory), arg0, arg1)
+}
+
+// DeleteCategoryTx mocks base method.
+func (m *MockStore) DeleteCategoryTx(arg0 context.Context, arg1 db.DeleteCategoryParams, arg2 db.AuditActor) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategoryTx indicates an expected call of DeleteCategoryTx.
+func (mr *MockStoreMockRecorder) DeleteCategoryTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryTx", reflect.TypeOf((*MockStore)(nil).DeleteCategoryTx), arg0, arg1, arg2)
+}
+
+// DeleteExpenseTx mocks base method.
+func (m *MockStore) DeleteExpenseTx(arg0 context.Context, arg1 db.SoftDeleteExpenseParams, arg2 db.AuditActor) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpenseTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpenseTx indicates an expected call of DeleteExpenseTx.
+func (mr *MockStoreMockRecorder) DeleteExpenseTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpenseTx", reflect.TypeOf((*MockStore)(nil).DeleteExpenseTx), arg0, arg1, arg2)
+}
+
+// DeleteFoodContent mocks base method.
+func (m *MockStore) DeleteFoodContent(arg0 context.Context, arg1 db.DeleteFoodContentParams) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFoodContent indicates an expected call of DeleteFoodContent.
+func (mr *MockStoreMockRecorder) DeleteFoodContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFoodContent", reflect.TypeOf((*MockStore)(nil).DeleteFoodContent), arg0, arg1)
+}
+
+// DeleteFoodReceiptContents mocks base method.
+func (m *MockStore) DeleteFoodReceiptContents(arg0 context.Context, arg1 []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFoodReceiptContents indicates an expected call of DeleteFoodReceiptContents.
+func (mr *MockStoreMockRecorder) DeleteFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFoodReceiptContents", reflect.TypeOf((*MockStore)(nil).DeleteFoodReceiptContents), arg0, arg1)
+}
+
+// DeleteFoodReceipts mocks base method.
+func (m *MockStore) DeleteFoodReceipts(arg0 context.Context, arg1 []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFoodReceipts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFoodReceipts indicates an expected call of DeleteFoodReceipts.
+func (mr *MockStoreMockRecorder) DeleteFoodReceipts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFoodReceipts", reflect.TypeOf((*MockStore)(nil).DeleteFoodReceipts), arg0, arg1)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteReceiptTx mocks base method.
+func (m *MockStore) DeleteReceiptTx(arg0 context.Context, arg1 db.SoftDeleteFoodReceiptParams, arg2 db.AuditActor) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReceiptTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReceiptTx indicates an expected call of DeleteReceiptTx.
+func (mr *MockStoreMockRecorder) DeleteReceiptTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReceiptTx", reflect.TypeOf((*MockStore)(nil).DeleteReceiptTx), arg0, arg1, arg2)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DeleteSession mocks base method.
+func (m *MockStore) DeleteSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockStoreMockRecorder) DeleteSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), arg0, arg1)
+}
+
+// DeleteTwoFactorChallenge mocks base method.
+func (m *MockStore) DeleteTwoFactorChallenge(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTwoFactorChallenge indicates an expected call of DeleteTwoFactorChallenge.
+func (mr *MockStoreMockRecorder) DeleteTwoFactorChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTwoFactorChallenge", reflect.TypeOf((*MockStore)(nil).DeleteTwoFactorChallenge), arg0, arg1)
+}
+
+// DeleteUserAPIKeys mocks base method.
+func (m *MockStore) DeleteUserAPIKeys(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserAPIKeys indicates an expected call of DeleteUserAPIKeys.
+func (mr *MockStoreMockRecorder) DeleteUserAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAPIKeys", reflect.TypeOf((*MockStore)(nil).DeleteUserAPIKeys), arg0, arg1)
+}
+
+// DeleteUserEmailChangeTokens mocks base method.
+func (m *MockStore) DeleteUserEmailChangeTokens(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserEmailChangeTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserEmailChangeTokens indicates an expected call of DeleteUserEmailChangeTokens.
+func (mr *MockStoreMockRecorder) DeleteUserEmailChangeTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserEmailChangeTokens", reflect.TypeOf((*MockStore)(nil).DeleteUserEmailChangeTokens), arg0, arg1)
+}
+
+// DeleteUserExpenses mocks base method.
+func (m *MockStore) DeleteUserExpenses(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserExpenses", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserExpenses indicates an expected call of DeleteUserExpenses.
+func (mr *MockStoreMockRecorder) DeleteUserExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserExpenses", reflect.TypeOf((*MockStore)(nil).DeleteUserExpenses), arg0, arg1)
+}
+
+// DeleteUserIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteUserIdempotencyKeys(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserIdempotencyKeys indicates an expected call of DeleteUserIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteUserIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteUserIdempotencyKeys), arg0, arg1)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockStore) DeleteUserSessions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockStoreMockRecorder) DeleteUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockStore)(nil).DeleteUserSessions), arg0, arg1)
+}
+
+// DeleteUserTwoFactorChallenges mocks base method.
+func (m *MockStore) DeleteUserTwoFactorChallenges(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTwoFactorChallenges", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTwoFactorChallenges indicates an expected call of DeleteUserTwoFactorChallenges.
+func (mr *MockStoreMockRecorder) DeleteUserTwoFactorChallenges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTwoFactorChallenges", reflect.TypeOf((*MockStore)(nil).DeleteUserTwoFactorChallenges), arg0, arg1)
+}
+
+// DeleteUserTx mocks base method.
+func (m *MockStore) DeleteUserTx(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTx indicates an expected call of DeleteUserTx.
+func (mr *MockStoreMockRecorder) DeleteUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), arg0, arg1)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockStore) EnableUserTOTP(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockStoreMockRecorder) EnableUserTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockStore)(nil).EnableUserTOTP), arg0, arg1)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockStore) GetAPIKeyByHash(arg0 context.Context, arg1 string) (db.GetAPIKeyByHashRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAPIKeyByHashRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockStoreMockRecorder) GetAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByHash), arg0, arg1)
+}
+
+// GetAuthSession mocks base method.
+func (m *MockStore) GetAuthSession(arg0 context.Context, arg1 uuid.UUID) (db.GetAuthSessionRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthSession", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAuthSessionRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthSession indicates an expected call of GetAuthSession.
+func (mr *MockStoreMockRecorder) GetAuthSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthSession", reflect.TypeOf((*MockStore)(nil).GetAuthSession), arg0, arg1)
+}
+
+// GetCategoryForUpdate mocks base method.
+func (m *MockStore) GetCategoryForUpdate(arg0 context.Context, arg1 int64) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryForUpdate indicates an expected call of GetCategoryForUpdate.
+func (mr *MockStoreMockRecorder) GetCategoryForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryForUpdate", reflect.TypeOf((*MockStore)(nil).GetCategoryForUpdate), arg0, arg1)
+}
+
+// GetExpenseForUpdate mocks base method.
+func (m *MockStore) GetExpenseForUpdate(arg0 context.Context, arg1 db.GetExpenseForUpdateParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpenseForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpenseForUpdate indicates an expected call of GetExpenseForUpdate.
+func (mr *MockStoreMockRecorder) GetExpenseForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpenseForUpdate", reflect.TypeOf((*MockStore)(nil).GetExpenseForUpdate), arg0, arg1)
+}
+
+// GetFoodContent mocks base method.
+func (m *MockStore) GetFoodContent(arg0 context.Context, arg1 int64) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodContent indicates an expected call of GetFoodContent.
+func (mr *MockStoreMockRecorder) GetFoodContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodContent", reflect.TypeOf((*MockStore)(nil).GetFoodContent), arg0, arg1)
+}
+
+// GetFoodContentByName mocks base method.
+func (m *MockStore) GetFoodContentByName(arg0 context.Context, arg1 string) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodContentByName", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodContentByName indicates an expected call of GetFoodContentByName.
+func (mr *MockStoreMockRecorder) GetFoodContentByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodContentByName", reflect.TypeOf((*MockStore)(nil).GetFoodContentByName), arg0, arg1)
+}
+
+// GetFoodReceipt mocks base method.
+func (m *MockStore) GetFoodReceipt(arg0 context.Context, arg1 int64) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodReceipt indicates an expected call of GetFoodReceipt.
+func (mr *MockStoreMockRecorder) GetFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodReceipt", reflect.TypeOf((*MockStore)(nil).GetFoodReceipt), arg0, arg1)
+}
+
+// GetFoodReceiptForUpdate mocks base method.
+func (m *MockStore) GetFoodReceiptForUpdate(arg0 context.Context, arg1 db.GetFoodReceiptForUpdateParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoodReceiptForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoodReceiptForUpdate indicates an expected call of GetFoodReceiptForUpdate.
+func (mr *MockStoreMockRecorder) GetFoodReceiptForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoodReceiptForUpdate", reflect.TypeOf((*MockStore)(nil).GetFoodReceiptForUpdate), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetTwoFactorChallenge mocks base method.
+func (m *MockStore) GetTwoFactorChallenge(arg0 context.Context, arg1 uuid.UUID) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorChallenge indicates an expected call of GetTwoFactorChallenge.
+func (mr *MockStoreMockRecorder) GetTwoFactorChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorChallenge", reflect.TypeOf((*MockStore)(nil).GetTwoFactorChallenge), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockStore) ListAPIKeys(arg0 context.Context, arg1 int64) ([]db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockStoreMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockStore)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockStore) ListAuditEvents(arg0 context.Context, arg1 db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockStoreMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockStore)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListCategories mocks base method.
+func (m *MockStore) ListCategories(arg0 context.Context) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", arg0)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockStoreMockRecorder) ListCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockStore)(nil).ListCategories), arg0)
+}
+
+// ListDeletedCategories mocks base method.
+func (m *MockStore) ListDeletedCategories(arg0 context.Context) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedCategories", arg0)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedCategories indicates an expected call of ListDeletedCategories.
+func (mr *MockStoreMockRecorder) ListDeletedCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedCategories", reflect.TypeOf((*MockStore)(nil).ListDeletedCategories), arg0)
+}
+
+// ListDeletedExpenses mocks base method.
+func (m *MockStore) ListDeletedExpenses(arg0 context.Context, arg1 int64) ([]db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedExpenses indicates an expected call of ListDeletedExpenses.
+func (mr *MockStoreMockRecorder) ListDeletedExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedExpenses", reflect.TypeOf((*MockStore)(nil).ListDeletedExpenses), arg0, arg1)
+}
+
+// ListDeletedFoodReceipts mocks base method.
+func (m *MockStore) ListDeletedFoodReceipts(arg0 context.Context, arg1 int64) ([]db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedFoodReceipts", arg0, arg1)
+	ret0, _ := ret[0].([]db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedFoodReceipts indicates an expected call of ListDeletedFoodReceipts.
+func (mr *MockStoreMockRecorder) ListDeletedFoodReceipts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedFoodReceipts", reflect.TypeOf((*MockStore)(nil).ListDeletedFoodReceipts), arg0, arg1)
+}
+
+// ListDuplicateExpenseCandidates mocks base method.
+func (m *MockStore) ListDuplicateExpenseCandidates(arg0 context.Context, arg1 db.ListDuplicateExpenseCandidatesParams) ([]db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDuplicateExpenseCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDuplicateExpenseCandidates indicates an expected call of ListDuplicateExpenseCandidates.
+func (mr *MockStoreMockRecorder) ListDuplicateExpenseCandidates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDuplicateExpenseCandidates", reflect.TypeOf((*MockStore)(nil).ListDuplicateExpenseCandidates), arg0, arg1)
+}
+
+// ListDuplicateExpenses mocks base method.
+func (m *MockStore) ListDuplicateExpenses(arg0 context.Context, arg1 db.ListDuplicateExpensesParams) ([]db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDuplicateExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDuplicateExpenses indicates an expected call of ListDuplicateExpenses.
+func (mr *MockStoreMockRecorder) ListDuplicateExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDuplicateExpenses", reflect.TypeOf((*MockStore)(nil).ListDuplicateExpenses), arg0, arg1)
+}
+
+// ListDuplicateFoodReceiptCandidates mocks base method.
+func (m *MockStore) ListDuplicateFoodReceiptCandidates(arg0 context.Context, arg1 db.ListDuplicateFoodReceiptCandidatesParams) ([]db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDuplicateFoodReceiptCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDuplicateFoodReceiptCandidates indicates an expected call of ListDuplicateFoodReceiptCandidates.
+func (mr *MockStoreMockRecorder) ListDuplicateFoodReceiptCandidates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDuplicateFoodReceiptCandidates", reflect.TypeOf((*MockStore)(nil).ListDuplicateFoodReceiptCandidates), arg0, arg1)
+}
+
+// ListExpenses mocks base method.
+func (m *MockStore) ListExpenses(arg0 context.Context, arg1 int64) ([]db.ListExpensesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListExpensesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpenses indicates an expected call of ListExpenses.
+func (mr *MockStoreMockRecorder) ListExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpenses", reflect.TypeOf((*MockStore)(nil).ListExpenses), arg0, arg1)
+}
+
+// ListExportExpenses mocks base method.
+func (m *MockStore) ListExportExpenses(arg0 context.Context, arg1 int64) ([]db.ListExportExpensesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExportExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListExportExpensesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExportExpenses indicates an expected call of ListExportExpenses.
+func (mr *MockStoreMockRecorder) ListExportExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExportExpenses", reflect.TypeOf((*MockStore)(nil).ListExportExpenses), arg0, arg1)
+}
+
+// ListFoodContents mocks base method.
+func (m *MockStore) ListFoodContents(arg0 context.Context, arg1 db.ListFoodContentsParams) ([]db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFoodContents", arg0, arg1)
+	ret0, _ := ret[0].([]db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFoodContents indicates an expected call of ListFoodContents.
+func (mr *MockStoreMockRecorder) ListFoodContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFoodContents", reflect.TypeOf((*MockStore)(nil).ListFoodContents), arg0, arg1)
+}
+
+// ListFoodReceiptContents mocks base method.
+func (m *MockStore) ListFoodReceiptContents(arg0 context.Context, arg1 int64) ([]db.ListFoodReceiptContentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListFoodReceiptContentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFoodReceiptContents indicates an expected call of ListFoodReceiptContents.
+func (mr *MockStoreMockRecorder) ListFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFoodReceiptContents", reflect.TypeOf((*MockStore)(nil).ListFoodReceiptContents), arg0, arg1)
+}
+
+// ListUserAuditEvents mocks base method.
+func (m *MockStore) ListUserAuditEvents(arg0 context.Context, arg1 db.ListUserAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserAuditEvents indicates an expected call of ListUserAuditEvents.
+func (mr *MockStoreMockRecorder) ListUserAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAuditEvents", reflect.TypeOf((*MockStore)(nil).ListUserAuditEvents), arg0, arg1)
+}
+
+// ListUserFoodReceiptContents mocks base method.
+func (m *MockStore) ListUserFoodReceiptContents(arg0 context.Context, arg1 int64) ([]db.ListUserFoodReceiptContentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListUserFoodReceiptContentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserFoodReceiptContents indicates an expected call of ListUserFoodReceiptContents.
+func (mr *MockStoreMockRecorder) ListUserFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserFoodReceiptContents", reflect.TypeOf((*MockStore)(nil).ListUserFoodReceiptContents), arg0, arg1)
+}
+
+// ListUserFoodReceiptIDs mocks base method.
+func (m *MockStore) ListUserFoodReceiptIDs(arg0 context.Context, arg1 int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserFoodReceiptIDs", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserFoodReceiptIDs indicates an expected call of ListUserFoodReceiptIDs.
+func (mr *MockStoreMockRecorder) ListUserFoodReceiptIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserFoodReceiptIDs", reflect.TypeOf((*MockStore)(nil).ListUserFoodReceiptIDs), arg0, arg1)
+}
+
+// ListUserSessions mocks base method.
+func (m *MockStore) ListUserSessions(arg0 context.Context, arg1 int64) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockStoreMockRecorder) ListUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockStore)(nil).ListUserSessions), arg0, arg1)
+}
+
+// ListUserTransfers mocks base method.
+func (m *MockStore) ListUserTransfers(arg0 context.Context, arg1 int64) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserTransfers indicates an expected call of ListUserTransfers.
+func (mr *MockStoreMockRecorder) ListUserTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserTransfers", reflect.TypeOf((*MockStore)(nil).ListUserTransfers), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+}
+
+// ListUsersDueForDeletion mocks base method.
+func (m *MockStore) ListUsersDueForDeletion(arg0 context.Context, arg1 db.ListUsersDueForDeletionParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersDueForDeletion", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersDueForDeletion indicates an expected call of ListUsersDueForDeletion.
+func (mr *MockStoreMockRecorder) ListUsersDueForDeletion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersDueForDeletion", reflect.TypeOf((*MockStore)(nil).ListUsersDueForDeletion), arg0, arg1)
+}
+
+// MigrationVersion mocks base method.
+func (m *MockStore) MigrationVersion(arg0 context.Context) (uint, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", arg0)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockStoreMockRecorder) MigrationVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockStore)(nil).MigrationVersion), arg0)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// PurgeDeletedCategories mocks base method.
+func (m *MockStore) PurgeDeletedCategories(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedCategories", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedCategories indicates an expected call of PurgeDeletedCategories.
+func (mr *MockStoreMockRecorder) PurgeDeletedCategories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedCategories", reflect.TypeOf((*MockStore)(nil).PurgeDeletedCategories), arg0, arg1)
+}
+
+// PurgeDeletedExpenses mocks base method.
+func (m *MockStore) PurgeDeletedExpenses(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedExpenses", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedExpenses indicates an expected call of PurgeDeletedExpenses.
+func (mr *MockStoreMockRecorder) PurgeDeletedExpenses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedExpenses", reflect.TypeOf((*MockStore)(nil).PurgeDeletedExpenses), arg0, arg1)
+}
+
+// PurgeDeletedFoodReceiptContents mocks base method.
+func (m *MockStore) PurgeDeletedFoodReceiptContents(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedFoodReceiptContents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeDeletedFoodReceiptContents indicates an expected call of PurgeDeletedFoodReceiptContents.
+func (mr *MockStoreMockRecorder) PurgeDeletedFoodReceiptContents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedFoodReceiptContents", reflect.TypeOf((*MockStore)(nil).PurgeDeletedFoodReceiptContents), arg0, arg1)
+}
+
+// PurgeDeletedFoodReceipts mocks base method.
+func (m *MockStore) PurgeDeletedFoodReceipts(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedFoodReceipts", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedFoodReceipts indicates an expected call of PurgeDeletedFoodReceipts.
+func (mr *MockStoreMockRecorder) PurgeDeletedFoodReceipts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedFoodReceipts", reflect.TypeOf((*MockStore)(nil).PurgeDeletedFoodReceipts), arg0, arg1)
+}
+
+// PurgeExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) PurgeExpiredIdempotencyKeys(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredIdempotencyKeys indicates an expected call of PurgeExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) PurgeExpiredIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).PurgeExpiredIdempotencyKeys), arg0, arg1)
+}
+
+// PurgeTrashTx mocks base method.
+func (m *MockStore) PurgeTrashTx(arg0 context.Context, arg1 time.Time) (db.PurgeTrashTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrashTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PurgeTrashTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrashTx indicates an expected call of PurgeTrashTx.
+func (mr *MockStoreMockRecorder) PurgeTrashTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrashTx", reflect.TypeOf((*MockStore)(nil).PurgeTrashTx), arg0, arg1)
+}
+
+// PurgeUserSessions mocks base method.
+func (m *MockStore) PurgeUserSessions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUserSessions indicates an expected call of PurgeUserSessions.
+func (mr *MockStoreMockRecorder) PurgeUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUserSessions", reflect.TypeOf((*MockStore)(nil).PurgeUserSessions), arg0, arg1)
+}
+
+// RedactUserAuditEvents mocks base method.
+func (m *MockStore) RedactUserAuditEvents(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedactUserAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedactUserAuditEvents indicates an expected call of RedactUserAuditEvents.
+func (mr *MockStoreMockRecorder) RedactUserAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedactUserAuditEvents", reflect.TypeOf((*MockStore)(nil).RedactUserAuditEvents), arg0, arg1)
+}
+
+// RestoreCategory mocks base method.
+func (m *MockStore) RestoreCategory(arg0 context.Context, arg1 int64) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreCategory indicates an expected call of RestoreCategory.
+func (mr *MockStoreMockRecorder) RestoreCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCategory", reflect.TypeOf((*MockStore)(nil).RestoreCategory), arg0, arg1)
+}
+
+// RestoreCategoryTx mocks base method.
+func (m *MockStore) RestoreCategoryTx(arg0 context.Context, arg1 int64, arg2 db.AuditActor) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCategoryTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreCategoryTx indicates an expected call of RestoreCategoryTx.
+func (mr *MockStoreMockRecorder) RestoreCategoryTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCategoryTx", reflect.TypeOf((*MockStore)(nil).RestoreCategoryTx), arg0, arg1, arg2)
+}
+
+// RestoreExpense mocks base method.
+func (m *MockStore) RestoreExpense(arg0 context.Context, arg1 db.RestoreExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreExpense indicates an expected call of RestoreExpense.
+func (mr *MockStoreMockRecorder) RestoreExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreExpense", reflect.TypeOf((*MockStore)(nil).RestoreExpense), arg0, arg1)
+}
+
+// RestoreExpenseTx mocks base method.
+func (m *MockStore) RestoreExpenseTx(arg0 context.Context, arg1 db.RestoreExpenseParams, arg2 db.AuditActor) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreExpenseTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreExpenseTx indicates an expected call of RestoreExpenseTx.
+func (mr *MockStoreMockRecorder) RestoreExpenseTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreExpenseTx", reflect.TypeOf((*MockStore)(nil).RestoreExpenseTx), arg0, arg1, arg2)
+}
+
+// RestoreFoodReceipt mocks base method.
+func (m *MockStore) RestoreFoodReceipt(arg0 context.Context, arg1 db.RestoreFoodReceiptParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFoodReceipt indicates an expected call of RestoreFoodReceipt.
+func (mr *MockStoreMockRecorder) RestoreFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFoodReceipt", reflect.TypeOf((*MockStore)(nil).RestoreFoodReceipt), arg0, arg1)
+}
+
+// RestoreReceiptTx mocks base method.
+func (m *MockStore) RestoreReceiptTx(arg0 context.Context, arg1 db.RestoreFoodReceiptParams, arg2 db.AuditActor) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreReceiptTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreReceiptTx indicates an expected call of RestoreReceiptTx.
+func (mr *MockStoreMockRecorder) RestoreReceiptTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreReceiptTx", reflect.TypeOf((*MockStore)(nil).RestoreReceiptTx), arg0, arg1, arg2)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockStore) RevokeAPIKey(arg0 context.Context, arg1 db.RevokeAPIKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockStoreMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockStore)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// SoftDeleteExpense mocks base method.
+func (m *MockStore) SoftDeleteExpense(arg0 context.Context, arg1 db.SoftDeleteExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteExpense indicates an expected call of SoftDeleteExpense.
+func (mr *MockStoreMockRecorder) SoftDeleteExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteExpense", reflect.TypeOf((*MockStore)(nil).SoftDeleteExpense), arg0, arg1)
+}
+
+// SoftDeleteFoodReceipt mocks base method.
+func (m *MockStore) SoftDeleteFoodReceipt(arg0 context.Context, arg1 db.SoftDeleteFoodReceiptParams) (db.FoodReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteFoodReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteFoodReceipt indicates an expected call of SoftDeleteFoodReceipt.
+func (mr *MockStoreMockRecorder) SoftDeleteFoodReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteFoodReceipt", reflect.TypeOf((*MockStore)(nil).SoftDeleteFoodReceipt), arg0, arg1)
+}
+
+// UpdateAPIKeyLastUsed mocks base method.
+func (m *MockStore) UpdateAPIKeyLastUsed(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed.
+func (mr *MockStoreMockRecorder) UpdateAPIKeyLastUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateAPIKeyLastUsed), arg0, arg1)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockStore) UpdateCategory(arg0 context.Context, arg1 db.UpdateCategoryParams) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockStoreMockRecorder) UpdateCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockStore)(nil).UpdateCategory), arg0, arg1)
+}
+
+// UpdateCategoryTx mocks base method.
+func (m *MockStore) UpdateCategoryTx(arg0 context.Context, arg1 db.UpdateCategoryParams, arg2 db.AuditActor) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategoryTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategoryTx indicates an expected call of UpdateCategoryTx.
+func (mr *MockStoreMockRecorder) UpdateCategoryTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategoryTx", reflect.TypeOf((*MockStore)(nil).UpdateCategoryTx), arg0, arg1, arg2)
+}
+
+// UpdateExpense mocks base method.
+func (m *MockStore) UpdateExpense(arg0 context.Context, arg1 db.UpdateExpenseParams) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExpense", arg0, arg1)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateExpense indicates an expected call of UpdateExpense.
+func (mr *MockStoreMockRecorder) UpdateExpense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExpense", reflect.TypeOf((*MockStore)(nil).UpdateExpense), arg0, arg1)
+}
+
+// UpdateExpenseTx mocks base method.
+func (m *MockStore) UpdateExpenseTx(arg0 context.Context, arg1 db.UpdateExpenseTxParams, arg2 db.AuditActor) (db.Expense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExpenseTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Expense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateExpenseTx indicates an expected call of UpdateExpenseTx.
+func (mr *MockStoreMockRecorder) UpdateExpenseTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExpenseTx", reflect.TypeOf((*MockStore)(nil).UpdateExpenseTx), arg0, arg1, arg2)
+}
+
+// UpdateFoodContent mocks base method.
+func (m *MockStore) UpdateFoodContent(arg0 context.Context, arg1 db.UpdateFoodContentParams) (db.FoodContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFoodContent", arg0, arg1)
+	ret0, _ := ret[0].(db.FoodContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFoodContent indicates an expected call of UpdateFoodContent.
+func (mr *MockStoreMockRecorder) UpdateFoodContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFoodContent", reflect.TypeOf((*MockStore)(nil).UpdateFoodContent), arg0, arg1)
+}
+
+// UpdateSession mocks base method.
+func (m *MockStore) UpdateSession(arg0 context.Context, arg1 db.UpdateSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSession indicates an expected call of UpdateSession.
+func (mr *MockStoreMockRecorder) UpdateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockStore)(nil).UpdateSession), arg0, arg1)
+}
+
+// UpdateUserDeletionScheduledAt mocks base method.
+func (m *MockStore) UpdateUserDeletionScheduledAt(arg0 context.Context, arg1 db.UpdateUserDeletionScheduledAtParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDeletionScheduledAt", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserDeletionScheduledAt indicates an expected call of UpdateUserDeletionScheduledAt.
+func (mr *MockStoreMockRecorder) UpdateUserDeletionScheduledAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDeletionScheduledAt", reflect.TypeOf((*MockStore)(nil).UpdateUserDeletionScheduledAt), arg0, arg1)
+}
+
+// UpdateUserDisabledAt mocks base method.
+func (m *MockStore) UpdateUserDisabledAt(arg0 context.Context, arg1 db.UpdateUserDisabledAtParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDisabledAt", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserDisabledAt indicates an expected call of UpdateUserDisabledAt.
+func (mr *MockStoreMockRecorder) UpdateUserDisabledAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDisabledAt", reflect.TypeOf((*MockStore)(nil).UpdateUserDisabledAt), arg0, arg1)
+}
+
+// UpdateUserEmail mocks base method.
+func (m *MockStore) UpdateUserEmail(arg0 context.Context, arg1 db.UpdateUserEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockStoreMockRecorder) UpdateUserEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockStore)(nil).UpdateUserEmail), arg0, arg1)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockStore) UpdateUserProfile(arg0 context.Context, arg1 db.UpdateUserProfileParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockStoreMockRecorder) UpdateUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockStore)(nil).UpdateUserProfile), arg0, arg1)
+}
+
+// UpdateUserTOTPLastCounter mocks base method.
+func (m *MockStore) UpdateUserTOTPLastCounter(arg0 context.Context, arg1 db.UpdateUserTOTPLastCounterParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPLastCounter", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPLastCounter indicates an expected call of UpdateUserTOTPLastCounter.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPLastCounter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPLastCounter", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPLastCounter), arg0, arg1)
+}
+
+// UpdateUserTOTPSecret mocks base method.
+func (m *MockStore) UpdateUserTOTPSecret(arg0 context.Context, arg1 db.UpdateUserTOTPSecretParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPSecret indicates an expected call of UpdateUserTOTPSecret.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPSecret", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPSecret), arg0, arg1)
+}
+
+// UseEmailChangeToken mocks base method.
+func (m *MockStore) UseEmailChangeToken(arg0 context.Context, arg1 string) (db.EmailChangeToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailChangeToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChangeToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailChangeToken indicates an expected call of UseEmailChangeToken.
+func (mr *MockStoreMockRecorder) UseEmailChangeToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailChangeToken", reflect.TypeOf((*MockStore)(nil).UseEmailChangeToken), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTwoFactorChallengeAttempt mocks base method.
+func (m *MockStore) UseTwoFactorChallengeAttempt(arg0 context.Context, arg1 db.UseTwoFactorChallengeAttemptParams) (db.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTwoFactorChallengeAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTwoFactorChallengeAttempt indicates an expected call of UseTwoFactorChallengeAttempt.
+func (mr *MockStoreMockRecorder) UseTwoFactorChallengeAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTwoFactorChallengeAttempt", reflect.TypeOf((*MockStore)(nil).UseTwoFactorChallengeAttempt), arg0, arg1)
+}
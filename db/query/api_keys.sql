@@ -0,0 +1,43 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+	user_id,
+	name,
+	key_prefix,
+	key_hash,
+	scopes,
+	expires_at
+) VALUES (
+	$1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+-- 無効化されたユーザーのAPIキーは存在しないものとして扱う。
+-- 認証後のメッセージに使うため、ユーザーの言語設定も合わせて取得する。
+SELECT api_keys.*, users.locale FROM api_keys
+JOIN users ON users.id = api_keys.user_id
+WHERE api_keys.key_hash = $1
+	AND users.disabled_at IS NULL
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE user_id = $1
+	AND revoked_at IS NULL
+ORDER BY id;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = $1
+	AND user_id = $2
+	AND revoked_at IS NULL
+RETURNING *;
+
+-- name: UpdateAPIKeyLastUsed :exec
+UPDATE api_keys
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE user_id = $1;
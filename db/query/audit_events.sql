@@ -0,0 +1,38 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+	actor_id,
+	session_id,
+	client_ip,
+	action,
+	entity_type,
+	entity_id,
+	before,
+	after
+) VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: ListUserAuditEvents :many
+SELECT * FROM audit_events
+WHERE actor_id = @actor_id::bigint
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: RedactUserAuditEvents :execrows
+-- アカウントを削除したユーザーが操作した行から、変更内容とアクセス元を消す。
+UPDATE audit_events
+SET
+	session_id = NULL,
+	client_ip = '',
+	before = 'null'::jsonb,
+	after = 'null'::jsonb,
+	redacted_at = now()
+WHERE actor_id = @actor_id::bigint
+	AND redacted_at IS NULL;
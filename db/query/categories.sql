@@ -4,3 +4,68 @@ INSERT INTO categories (
 ) VALUES (
 	$1
 ) RETURNING *;
+
+-- name: ListCategories :many
+SELECT * FROM categories
+WHERE deleted_at IS NULL
+ORDER BY id;
+
+-- name: UpdateCategory :one
+UPDATE categories
+SET
+	name = $1,
+	version = version + 1
+WHERE id = $2
+	AND version = $3
+	AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteCategory :one
+-- ゴミ箱に移す。完全に削除するのは PurgeDeletedCategories のみ。
+UPDATE categories
+SET
+	deleted_at = now(),
+	version = version + 1
+WHERE id = $1
+	AND version = $2
+	AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetCategoryForUpdate :one
+SELECT * FROM categories
+WHERE id = $1
+	AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: CategoryHasExpenses :one
+-- ゴミ箱にある支出は数えない。
+SELECT EXISTS (
+	SELECT 1 FROM expenses
+	WHERE category_id = $1
+		AND deleted_at IS NULL
+);
+
+-- name: RestoreCategory :one
+UPDATE categories
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE id = $1
+	AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: ListDeletedCategories :many
+SELECT * FROM categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC;
+
+-- name: PurgeDeletedCategories :many
+-- 支出から参照されているカテゴリーは残す。
+DELETE FROM categories
+WHERE categories.deleted_at < @before::timestamptz
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.category_id = categories.id
+	)
+RETURNING id;
@@ -0,0 +1,21 @@
+-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+	user_id,
+	new_email,
+	token_hash,
+	expires_at
+) VALUES (
+	$1, $2, $3, $4
+) RETURNING *;
+
+-- name: UseEmailChangeToken :one
+UPDATE email_change_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1
+	AND used_at IS NULL
+	AND expires_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteUserEmailChangeTokens :exec
+DELETE FROM email_change_tokens
+WHERE user_id = $1;
@@ -24,8 +24,114 @@ SELECT
 		ELSE food_receipts.store_name
 	END AS store_name,
 	expenses.comment AS comment,
-	expenses.created_at AS created_at
+	expenses.created_at AS created_at,
+	expenses.version AS version
 FROM expenses
 LEFT OUTER JOIN food_receipts ON expenses.food_receipt_id = food_receipts.id
 INNER JOIN categories ON expenses.category_id = categories.id
-WHERE expenses.user_id = $1;
+WHERE expenses.user_id = $1
+	AND expenses.deleted_at IS NULL;
+
+-- name: ListExportExpenses :many
+-- ユーザーの全ての支出を返す（エクスポート用）。ゴミ箱にある支出も含める。
+SELECT
+	expenses.id AS id,
+	expenses.user_id AS user_id,
+	expenses.category_id AS category_id,
+	expenses.amount AS amount,
+	CASE
+		WHEN food_receipts.store_name IS NULL then ''
+		ELSE food_receipts.store_name
+	END AS store_name,
+	expenses.comment AS comment,
+	expenses.created_at AS created_at,
+	expenses.version AS version,
+	expenses.deleted_at AS deleted_at
+FROM expenses
+LEFT OUTER JOIN food_receipts ON expenses.food_receipt_id = food_receipts.id
+WHERE expenses.user_id = $1
+ORDER BY expenses.id;
+
+-- name: DeleteUserExpenses :execrows
+DELETE FROM expenses
+WHERE user_id = $1;
+
+-- name: GetExpenseForUpdate :one
+SELECT * FROM expenses
+WHERE id = $1
+	AND user_id = $2
+	AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateExpense :one
+UPDATE expenses
+SET
+	category_id = $1,
+	amount = $2,
+	comment = $3,
+	version = version + 1
+WHERE id = $4
+	AND user_id = $5
+	AND version = $6
+	AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteExpense :one
+UPDATE expenses
+SET
+	deleted_at = now(),
+	version = version + 1
+WHERE id = $1
+	AND user_id = $2
+	AND version = $3
+	AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreExpense :one
+UPDATE expenses
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE id = $1
+	AND user_id = $2
+	AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: ListDeletedExpenses :many
+SELECT * FROM expenses
+WHERE user_id = $1
+	AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC;
+
+-- name: PurgeDeletedExpenses :many
+DELETE FROM expenses
+WHERE deleted_at < @before::timestamptz
+RETURNING id;
+
+-- name: ListDuplicateExpenseCandidates :many
+-- 同じユーザー・カテゴリー・金額で、since 以降に作成された支出を新しい順に返す。
+SELECT * FROM expenses
+WHERE user_id = @user_id
+	AND category_id = @category_id
+	AND amount = @amount
+	AND created_at >= @since::timestamptz
+	AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListDuplicateExpenses :many
+-- 同じカテゴリー・金額の支出が、前後 window_seconds 秒以内に他にもある支出を返す。
+-- 重複の組ごとにまとめられるよう、カテゴリー・金額・作成日時の順に並べる。
+SELECT * FROM expenses
+WHERE expenses.user_id = @user_id
+	AND expenses.deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1 FROM expenses AS other
+		WHERE other.user_id = expenses.user_id
+			AND other.id <> expenses.id
+			AND other.category_id = expenses.category_id
+			AND other.amount = expenses.amount
+			AND other.deleted_at IS NULL
+			AND abs(extract(epoch FROM other.created_at - expenses.created_at)) <= @window_seconds::float8
+	)
+ORDER BY expenses.category_id, expenses.amount, expenses.created_at, expenses.id;
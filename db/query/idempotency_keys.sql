@@ -0,0 +1,53 @@
+-- name: CreateIdempotencyKey :one
+-- 有効期限を過ぎた行と、処理中のまま stale_before より前に作成された行は置き換える。
+-- それ以外の行が既にある場合は、行を返さない。
+INSERT INTO idempotency_keys (
+	user_id,
+	key,
+	fingerprint,
+	expires_at
+) VALUES (
+	@user_id, @key, @fingerprint, @expires_at
+)
+ON CONFLICT (user_id, key) DO UPDATE
+SET
+	fingerprint = EXCLUDED.fingerprint,
+	status_code = NULL,
+	response_headers = '{}',
+	response_body = '',
+	expires_at = EXCLUDED.expires_at,
+	created_at = now()
+WHERE idempotency_keys.expires_at <= now()
+	OR (
+		idempotency_keys.status_code IS NULL
+		AND idempotency_keys.created_at < @stale_before::timestamptz
+	)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1
+	AND key = $2
+LIMIT 1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+	status_code = @status_code::integer,
+	response_headers = @response_headers,
+	response_body = @response_body
+WHERE user_id = @user_id
+	AND key = @key;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1
+	AND key = $2;
+
+-- name: DeleteUserIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1;
+
+-- name: PurgeExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1;
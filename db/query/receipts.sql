@@ -1,13 +1,18 @@
 -- name: CreateFoodReceipt :one
 INSERT INTO food_receipts (
-	store_name
+	store_name,
+	user_id,
+	total_price,
+	items_digest
 ) VALUES (
-	$1
+	$1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetFoodReceipt :one
 SELECT * FROM food_receipts
-WHERE id = $1 LIMIT 1;
+WHERE id = $1
+	AND deleted_at IS NULL
+LIMIT 1;
 
 -- name: CreateFoodContent :one
 INSERT INTO food_contents (
@@ -24,6 +29,38 @@ INSERT INTO food_contents (
 SELECT * FROM food_contents
 WHERE id = $1 LIMIT 1;
 
+-- name: GetFoodContentByName :one
+-- 同じ名前の食品が複数ある場合は、最初に登録されたものを返す。
+SELECT * FROM food_contents
+WHERE name = $1
+ORDER BY id
+LIMIT 1;
+
+-- name: ListFoodContents :many
+SELECT * FROM food_contents
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: UpdateFoodContent :one
+UPDATE food_contents
+SET
+	name = $1,
+	calories = $2,
+	lipid = $3,
+	carbohydrate = $4,
+	protein = $5,
+	version = version + 1
+WHERE id = $6
+	AND version = $7
+RETURNING *;
+
+-- name: DeleteFoodContent :one
+DELETE FROM food_contents
+WHERE id = $1
+	AND version = $2
+RETURNING *;
+
 -- name: CreateFoodReceiptContent :one
 INSERT INTO food_receipt_contents (
 	food_receipt_id,
@@ -46,3 +83,118 @@ SELECT
 FROM food_receipt_contents
 INNER JOIN food_contents ON food_receipt_contents.food_content_id = food_contents.id
 WHERE food_receipt_contents.food_receipt_id = $1;
+
+-- name: ListUserFoodReceiptIDs :many
+-- ユーザーが登録したレシートを返す。
+SELECT id
+FROM food_receipts
+WHERE food_receipts.user_id = @user_id::bigint
+ORDER BY id;
+
+-- name: ListDuplicateFoodReceiptCandidates :many
+-- 同じユーザーが since 以降に登録した、店名・合計金額・明細が同じレシートを新しい順に返す。
+SELECT * FROM food_receipts
+WHERE user_id = @user_id
+	AND store_name = @store_name
+	AND total_price = @total_price
+	AND items_digest = @items_digest
+	AND created_at >= @since::timestamptz
+	AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListUserFoodReceiptContents :many
+-- ユーザーが登録したレシートを、明細ごとに1行で返す（エクスポート用）。
+-- 明細のないレシートも1行で返し、ゴミ箱にあるレシートも含める。
+SELECT
+	food_receipts.id AS food_receipt_id,
+	food_receipts.store_name AS store_name,
+	food_receipts.deleted_at AS deleted_at,
+	food_receipt_contents.food_content_id AS food_content_id,
+	food_receipt_contents.amount AS amount,
+	food_contents.name AS name,
+	food_contents.calories AS calories,
+	food_contents.lipid AS lipid,
+	food_contents.carbohydrate AS carbohydrate,
+	food_contents.protein AS protein
+FROM food_receipts
+LEFT OUTER JOIN food_receipt_contents ON food_receipt_contents.food_receipt_id = food_receipts.id
+LEFT OUTER JOIN food_contents ON food_receipt_contents.food_content_id = food_contents.id
+WHERE food_receipts.user_id = @user_id::bigint
+ORDER BY food_receipts.id, food_receipt_contents.id;
+
+-- name: DeleteFoodReceiptContents :exec
+-- 他のユーザーの支出から参照されているレシートの明細は残す。
+DELETE FROM food_receipt_contents
+WHERE food_receipt_id = ANY(@food_receipt_ids::bigint[])
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipt_contents.food_receipt_id
+	);
+
+-- name: DeleteFoodReceipts :exec
+-- 他のユーザーの支出から参照されているレシートは残す。
+DELETE FROM food_receipts
+WHERE id = ANY(@ids::bigint[])
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipts.id
+	);
+
+-- name: GetFoodReceiptForUpdate :one
+-- レシートを登録したユーザーのみ取得できる。
+SELECT * FROM food_receipts
+WHERE food_receipts.id = @id
+	AND food_receipts.deleted_at IS NULL
+	AND food_receipts.user_id = @user_id::bigint
+LIMIT 1
+FOR UPDATE;
+
+-- name: SoftDeleteFoodReceipt :one
+-- レシートを登録したユーザーのみ、レシートを削除できる。
+UPDATE food_receipts
+SET
+	deleted_at = now(),
+	version = version + 1
+WHERE food_receipts.id = @id
+	AND food_receipts.version = @version
+	AND food_receipts.deleted_at IS NULL
+	AND food_receipts.user_id = @user_id::bigint
+RETURNING *;
+
+-- name: RestoreFoodReceipt :one
+UPDATE food_receipts
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE food_receipts.id = @id
+	AND food_receipts.deleted_at IS NOT NULL
+	AND food_receipts.user_id = @user_id::bigint
+RETURNING *;
+
+-- name: ListDeletedFoodReceipts :many
+SELECT * FROM food_receipts
+WHERE food_receipts.deleted_at IS NOT NULL
+	AND food_receipts.user_id = @user_id::bigint
+ORDER BY food_receipts.deleted_at DESC, food_receipts.id DESC;
+
+-- name: PurgeDeletedFoodReceiptContents :exec
+-- 支出から参照されているレシートは削除できないため、明細も残す。
+DELETE FROM food_receipt_contents
+WHERE food_receipt_id IN (
+	SELECT food_receipts.id FROM food_receipts
+	WHERE food_receipts.deleted_at < @before::timestamptz
+		AND NOT EXISTS (
+			SELECT 1 FROM expenses
+			WHERE expenses.food_receipt_id = food_receipts.id
+		)
+);
+
+-- name: PurgeDeletedFoodReceipts :many
+-- 支出から参照されているレシートは残す。
+DELETE FROM food_receipts
+WHERE food_receipts.deleted_at < @before::timestamptz
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipts.id
+	)
+RETURNING id;
@@ -13,6 +13,12 @@ INSERT INTO sessions (
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAuthSession :one
+-- 認証後のメッセージに使うため、ユーザーの言語設定と合わせて取得する。
+SELECT sessions.*, users.locale FROM sessions
+JOIN users ON users.id = sessions.user_id
+WHERE sessions.id = $1 LIMIT 1;
+
 -- name: UpdateSession :exec
 UPDATE sessions
 SET expires_at = $1
@@ -24,3 +30,22 @@ UPDATE sessions
 SET expires_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING *;
+
+-- name: DeleteUserSessions :exec
+UPDATE sessions
+SET expires_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+	AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: PurgeUserSessions :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- name: CountActiveSessions :one
+SELECT COUNT(*) FROM sessions
+WHERE expires_at > CURRENT_TIMESTAMP;
@@ -0,0 +1,14 @@
+-- name: CreateTransfer :one
+INSERT INTO transfers (
+	from_user_id,
+	to_user_id,
+	amount
+) VALUES (
+	$1, $2, $3
+) RETURNING *;
+
+-- name: ListUserTransfers :many
+SELECT * FROM transfers
+WHERE from_user_id = $1
+	OR to_user_id = $1
+ORDER BY id;
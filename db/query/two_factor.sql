@@ -0,0 +1,51 @@
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+	user_id,
+	code_hash
+) VALUES (
+	$1, $2
+) RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+	AND code_hash = $2
+	AND used_at IS NULL
+RETURNING *;
+
+-- name: CreateTwoFactorChallenge :one
+INSERT INTO two_factor_challenges (
+	id,
+	user_id,
+	user_agent,
+	client_ip,
+	expires_at
+) VALUES (
+	$1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTwoFactorChallenge :one
+SELECT * FROM two_factor_challenges
+WHERE id = $1 LIMIT 1;
+
+-- name: UseTwoFactorChallengeAttempt :one
+-- コードを確かめる前に、試行回数を1回分消費する。
+-- 並行したリクエストでも上限を超えないよう、上限に達したチャレンジは返さない。
+UPDATE two_factor_challenges
+SET attempts = attempts + 1
+WHERE id = @id
+	AND attempts < @max_attempts::integer
+RETURNING *;
+
+-- name: DeleteTwoFactorChallenge :exec
+DELETE FROM two_factor_challenges
+WHERE id = $1;
+
+-- name: DeleteUserTwoFactorChallenges :exec
+DELETE FROM two_factor_challenges
+WHERE user_id = $1;
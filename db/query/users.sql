@@ -12,3 +12,98 @@ INSERT INTO users (
 -- name: GetUser :one
 SELECT * FROM users
 WHERE email = $1 LIMIT 1;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateUserTOTPSecret :one
+UPDATE users
+SET
+	totp_secret = $1,
+	totp_enabled = false
+WHERE id = $2
+RETURNING *;
+
+-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled = true
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserTOTPLastCounter :execrows
+-- 前回受け付けたコードより後のステップの場合のみ更新する。
+-- 0行の場合は、受け付け済みのコードの再利用とみなす。
+UPDATE users
+SET totp_last_counter = @counter::bigint
+WHERE id = @id
+	AND (totp_last_counter IS NULL OR totp_last_counter < @counter::bigint);
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: UpdateUserDisabledAt :one
+UPDATE users
+SET disabled_at = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET
+	name = $1,
+	age = $2,
+	preferred_currency = $3,
+	locale = $4,
+	timezone = $5
+WHERE id = $6
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateUserDeletionScheduledAt :one
+UPDATE users
+SET deletion_scheduled_at = $1
+WHERE id = $2
+	AND anonymized_at IS NULL
+RETURNING *;
+
+-- name: ListUsersDueForDeletion :many
+SELECT * FROM users
+WHERE deletion_scheduled_at <= @now::timestamptz
+	AND anonymized_at IS NULL
+ORDER BY deletion_scheduled_at
+LIMIT @max_users;
+
+-- name: AnonymizeUser :one
+-- 送金履歴の相手側から参照されるため、行は削除せずに個人を特定できる情報を消す。
+UPDATE users
+SET
+	name = 'deleted user',
+	password = '',
+	email = 'deleted-' || id || '@invalid',
+	age = 0,
+	balance = 0,
+	totp_secret = NULL,
+	totp_enabled = false,
+	totp_last_counter = NULL,
+	disabled_at = CURRENT_TIMESTAMP,
+	deletion_scheduled_at = NULL,
+	anonymized_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: AddUserBalance :one
+-- 退会したユーザーの残高は変更せず、sql.ErrNoRows を返す。
+UPDATE users
+SET balance = balance + @amount::bigint
+WHERE id = @id::bigint
+	AND anonymized_at IS NULL
+RETURNING balance;
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T, user User) ApiKey {
+	// Arrange
+	arg := CreateAPIKeyParams{
+		UserID:    user.ID,
+		Name:      util.RandomString(8),
+		KeyPrefix: "abk_" + util.RandomString(8),
+		KeyHash:   util.HashToken(util.RandomString(32)),
+		Scopes:    []string{"read:expenses", "write:expenses"},
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(24 * time.Hour), Valid: true},
+	}
+
+	// Act
+	key, err := testQueries.CreateAPIKey(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotZero(t, key.ID)
+	require.Equal(t, arg.UserID, key.UserID)
+	require.Equal(t, arg.Name, key.Name)
+	require.Equal(t, arg.KeyPrefix, key.KeyPrefix)
+	require.Equal(t, arg.KeyHash, key.KeyHash)
+	require.Equal(t, arg.Scopes, key.Scopes)
+	require.WithinDuration(t, arg.ExpiresAt.Time, key.ExpiresAt.Time, time.Second)
+	require.False(t, key.RevokedAt.Valid)
+
+	return key
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	createRandomAPIKey(t, createRandomUser(t))
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	// Arrange
+	key1 := createRandomAPIKey(t, createRandomUser(t))
+
+	// Act
+	key2, err := testQueries.GetAPIKeyByHash(context.Background(), key1.KeyHash)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, key1.ID, key2.ID)
+	require.Equal(t, key1.Scopes, key2.Scopes)
+}
+
+func TestGetAPIKeyByHashWithDisabledUser(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	key := createRandomAPIKey(t, user)
+	arg := UpdateUserDisabledAtParams{
+		DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:         user.ID,
+	}
+	_, err := testQueries.UpdateUserDisabledAt(context.Background(), arg)
+	require.NoError(t, err)
+
+	// Act
+	_, err = testQueries.GetAPIKeyByHash(context.Background(), key.KeyHash)
+
+	// Assert
+	// 無効化されたユーザーのAPIキーは使えない。
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	key1 := createRandomAPIKey(t, user)
+	key2 := createRandomAPIKey(t, user)
+
+	// Act
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{
+		ID:     key1.ID,
+		UserID: user.ID,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	// 失効したAPIキーは一覧に含まれない。
+	keys, err := testQueries.ListAPIKeys(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, key2.ID, keys[0].ID)
+
+	// 他のユーザーのAPIキーは失効できない。
+	_, err = testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{
+		ID:     key2.ID,
+		UserID: createRandomUser(t).ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
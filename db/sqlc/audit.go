@@ -0,0 +1,394 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// 監査ログに記録する操作の種類。
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	// ゴミ箱から復元する操作。
+	AuditActionRestore = "restore"
+	// ゴミ箱から完全に削除する操作。
+	AuditActionPurge = "purge"
+)
+
+// 監査ログに記録する対象の種類。
+const (
+	AuditEntityExpense  = "expense"
+	AuditEntityReceipt  = "receipt"
+	AuditEntityCategory = "category"
+	AuditEntityUser     = "user"
+	AuditEntityTransfer = "transfer"
+	// ユーザーの残高。entity_id にはユーザーIDを記録する。
+	AuditEntityBalance = "balance"
+)
+
+// 監査ログに記録する、操作したユーザーとリクエストの情報。
+type AuditActor struct {
+	// バックグラウンドの処理による操作の場合は 0 になる。
+	UserID int64
+	// APIキーで認証された場合は無効な値になる。
+	SessionID uuid.NullUUID
+	ClientIP  string
+}
+
+// バックグラウンドの処理による操作を記録する際の AuditActor。
+// actor_id は null、client_ip は空文字列として記録する。
+var SystemAuditActor = AuditActor{}
+
+// 支出から参照されているカテゴリーを削除しようとした場合のエラー。
+var ErrCategoryInUse = errors.New("category is referenced by expenses")
+
+// 変更前後の値を JSON にして監査ログに記録する。
+// 作成時と復元時の before には nil を渡し、JSON の null として記録する。
+// 復元前の値は、削除時の監査ログの after に記録済みのため。
+func RecordAuditEvent(ctx context.Context, q Querier, actor AuditActor, action, entityType string, entityID int64, before, after interface{}) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return fmt.Errorf("failed to marshal before: %w", err)
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("failed to marshal after: %w", err)
+	}
+
+	_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		ActorID:    sql.NullInt64{Int64: actor.UserID, Valid: actor.UserID != 0},
+		SessionID:  actor.SessionID,
+		ClientIp:   actor.ClientIP,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to CreateAuditEvent: %w", err)
+	}
+	return nil
+}
+
+// レシートを明細と共に登録する際の、１つの明細。
+type CreateReceiptContentParams struct {
+	// 0 の場合は FoodName から食品を決める。
+	FoodContentID int64 `json:"food_content_id"`
+	// 同じ名前の食品がなければ、栄養素を 0 として作成する。
+	FoodName string `json:"food_name"`
+	Amount   int64  `json:"amount"`
+}
+
+// CreateReceiptTx の引数。
+type CreateReceiptTxParams struct {
+	StoreName string `json:"store_name"`
+	// 登録したユーザー。重複の検出に使う。
+	UserID int64 `json:"user_id"`
+	// レシートの合計金額。
+	TotalPrice int64 `json:"total_price"`
+	// 明細の順序によらない要約。重複の検出に使う。
+	ItemsDigest string                       `json:"items_digest"`
+	Contents    []CreateReceiptContentParams `json:"contents"`
+}
+
+// UpdateExpenseTx の引数。
+// nil の項目は現在の値のまま更新する。
+type UpdateExpenseTxParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// AnyVersion（If-Match: *）の場合は version を条件にしない。
+	Version    int64  `json:"version"`
+	CategoryID *int64 `json:"category_id"`
+	Amount     *int64 `json:"amount"`
+	// 空文字列の場合はコメントを削除する。
+	Comment *string `json:"comment"`
+}
+
+// AdjustBalanceTx の引数。
+type AdjustBalanceTxParams struct {
+	UserID int64 `json:"user_id"`
+	// 残高に加える金額。減らす場合は負の値にする。
+	Amount int64 `json:"amount"`
+}
+
+// 残高の変更前後を監査ログに記録する際の値。
+// ユーザーの行はパスワードのハッシュなどを含むため、残高のみを記録する。
+type balanceAuditRecord struct {
+	Balance int64 `json:"balance"`
+}
+
+// CreateReceiptTx で登録したレシートと明細。
+type CreateReceiptTxResult struct {
+	FoodReceipt FoodReceipt          `json:"food_receipt"`
+	Contents    []FoodReceiptContent `json:"contents"`
+}
+
+// 以下は、変更と監査ログの記録を１つのトランザクションで行う Tx メソッドの手順。
+// Store の実装ごとに手順が食い違わないよう、トランザクション内の Querier を受け取って実行する。
+
+// 支出を作成し、監査ログに記録する。
+func CreateExpenseWithAudit(ctx context.Context, q Querier, arg CreateExpenseParams, actor AuditActor) (Expense, error) {
+	expense, err := q.CreateExpense(ctx, arg)
+	if err != nil {
+		return expense, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionCreate, AuditEntityExpense, expense.ID, nil, expense)
+	return expense, err
+}
+
+// レシートを明細と共に作成し、監査ログに記録する。
+func CreateReceiptWithAudit(ctx context.Context, q Querier, arg CreateReceiptTxParams, actor AuditActor) (CreateReceiptTxResult, error) {
+	var result CreateReceiptTxResult
+	receipt, err := q.CreateFoodReceipt(ctx, CreateFoodReceiptParams{
+		StoreName:   arg.StoreName,
+		UserID:      sql.NullInt64{Int64: arg.UserID, Valid: true},
+		TotalPrice:  sql.NullInt64{Int64: arg.TotalPrice, Valid: true},
+		ItemsDigest: sql.NullString{String: arg.ItemsDigest, Valid: true},
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to CreateFoodReceipt: %w", err)
+	}
+	result.FoodReceipt = receipt
+
+	result.Contents = []FoodReceiptContent{}
+	for _, content := range arg.Contents {
+		foodContentID := content.FoodContentID
+		if foodContentID == 0 {
+			food, err := resolveFoodContent(ctx, q, content.FoodName)
+			if err != nil {
+				return result, err
+			}
+			foodContentID = food.ID
+		}
+		rc, err := q.CreateFoodReceiptContent(ctx, CreateFoodReceiptContentParams{
+			FoodReceiptID: receipt.ID,
+			FoodContentID: foodContentID,
+			Amount:        content.Amount,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to CreateFoodReceiptContent: %w", err)
+		}
+		result.Contents = append(result.Contents, rc)
+	}
+
+	err = RecordAuditEvent(ctx, q, actor, AuditActionCreate, AuditEntityReceipt, receipt.ID, nil, result)
+	return result, err
+}
+
+// 名前の一致する食品を返す。登録されていなければ、栄養素を 0 として作成する。
+func resolveFoodContent(ctx context.Context, q Querier, name string) (FoodContent, error) {
+	food, err := q.GetFoodContentByName(ctx, name)
+	if err == nil {
+		return food, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return food, fmt.Errorf("failed to GetFoodContentByName: %w", err)
+	}
+	food, err = q.CreateFoodContent(ctx, CreateFoodContentParams{Name: name})
+	if err != nil {
+		return food, fmt.Errorf("failed to CreateFoodContent: %w", err)
+	}
+	return food, nil
+}
+
+// カテゴリーを作成し、監査ログに記録する。
+func CreateCategoryWithAudit(ctx context.Context, q Querier, name string, actor AuditActor) (Category, error) {
+	category, err := q.CreateCategory(ctx, name)
+	if err != nil {
+		return category, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionCreate, AuditEntityCategory, category.ID, nil, category)
+	return category, err
+}
+
+// カテゴリーを更新し、変更前後の値を監査ログに記録する。
+// arg.Version が現在の version と異なる場合は ErrVersionMismatch を返す。
+func UpdateCategoryWithAudit(ctx context.Context, q Querier, arg UpdateCategoryParams, actor AuditActor) (Category, error) {
+	before, err := q.GetCategoryForUpdate(ctx, arg.ID)
+	if err != nil {
+		return before, err
+	}
+	if arg.Version, err = CheckVersion(before.Version, arg.Version); err != nil {
+		return before, err
+	}
+	category, err := q.UpdateCategory(ctx, arg)
+	if err != nil {
+		return category, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionUpdate, AuditEntityCategory, category.ID, before, category)
+	return category, err
+}
+
+// カテゴリーをゴミ箱に移し、監査ログに記録する。
+// ゴミ箱にない支出から参照されている場合は ErrCategoryInUse を返す。
+// arg.Version が現在の version と異なる場合は ErrVersionMismatch を返す。
+func DeleteCategoryWithAudit(ctx context.Context, q Querier, arg DeleteCategoryParams, actor AuditActor) (Category, error) {
+	before, err := q.GetCategoryForUpdate(ctx, arg.ID)
+	if err != nil {
+		return before, err
+	}
+	if arg.Version, err = CheckVersion(before.Version, arg.Version); err != nil {
+		return before, err
+	}
+	inUse, err := q.CategoryHasExpenses(ctx, arg.ID)
+	if err != nil {
+		return before, fmt.Errorf("failed to CategoryHasExpenses: %w", err)
+	}
+	if inUse {
+		return before, ErrCategoryInUse
+	}
+	category, err := q.DeleteCategory(ctx, arg)
+	if err != nil {
+		return category, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionDelete, AuditEntityCategory, category.ID, before, category)
+	return category, err
+}
+
+// ゴミ箱からカテゴリーを復元し、監査ログに記録する。
+func RestoreCategoryWithAudit(ctx context.Context, q Querier, id int64, actor AuditActor) (Category, error) {
+	category, err := q.RestoreCategory(ctx, id)
+	if err != nil {
+		return category, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionRestore, AuditEntityCategory, category.ID, nil, category)
+	return category, err
+}
+
+// 支出をゴミ箱に移し、監査ログに記録する。
+// arg.Version が現在の version と異なる場合は ErrVersionMismatch を返す。
+func DeleteExpenseWithAudit(ctx context.Context, q Querier, arg SoftDeleteExpenseParams, actor AuditActor) (Expense, error) {
+	before, err := q.GetExpenseForUpdate(ctx, GetExpenseForUpdateParams{ID: arg.ID, UserID: arg.UserID})
+	if err != nil {
+		return before, err
+	}
+	if arg.Version, err = CheckVersion(before.Version, arg.Version); err != nil {
+		return before, err
+	}
+	expense, err := q.SoftDeleteExpense(ctx, arg)
+	if err != nil {
+		return expense, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionDelete, AuditEntityExpense, expense.ID, before, expense)
+	return expense, err
+}
+
+// 支出を更新し、変更前後の値を監査ログに記録する。
+// arg.Version が現在の version と異なる場合は ErrVersionMismatch を返す。
+func UpdateExpenseWithAudit(ctx context.Context, q Querier, arg UpdateExpenseTxParams, actor AuditActor) (Expense, error) {
+	before, err := q.GetExpenseForUpdate(ctx, GetExpenseForUpdateParams{ID: arg.ID, UserID: arg.UserID})
+	if err != nil {
+		return before, err
+	}
+	version, err := CheckVersion(before.Version, arg.Version)
+	if err != nil {
+		return before, err
+	}
+
+	params := UpdateExpenseParams{
+		CategoryID: before.CategoryID,
+		Amount:     before.Amount,
+		Comment:    before.Comment,
+		ID:         before.ID,
+		UserID:     before.UserID,
+		Version:    version,
+	}
+	if arg.CategoryID != nil {
+		params.CategoryID = *arg.CategoryID
+	}
+	if arg.Amount != nil {
+		params.Amount = *arg.Amount
+	}
+	if arg.Comment != nil {
+		params.Comment = sql.NullString{String: *arg.Comment, Valid: *arg.Comment != ""}
+	}
+
+	expense, err := q.UpdateExpense(ctx, params)
+	if err != nil {
+		return expense, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionUpdate, AuditEntityExpense, expense.ID, before, expense)
+	return expense, err
+}
+
+// ゴミ箱から支出を復元し、監査ログに記録する。
+func RestoreExpenseWithAudit(ctx context.Context, q Querier, arg RestoreExpenseParams, actor AuditActor) (Expense, error) {
+	expense, err := q.RestoreExpense(ctx, arg)
+	if err != nil {
+		return expense, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionRestore, AuditEntityExpense, expense.ID, nil, expense)
+	return expense, err
+}
+
+// レシートをゴミ箱に移し、監査ログに記録する。
+// arg.Version が現在の version と異なる場合は ErrVersionMismatch を返す。
+func DeleteReceiptWithAudit(ctx context.Context, q Querier, arg SoftDeleteFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	before, err := q.GetFoodReceiptForUpdate(ctx, GetFoodReceiptForUpdateParams{ID: arg.ID, UserID: arg.UserID})
+	if err != nil {
+		return before, err
+	}
+	if arg.Version, err = CheckVersion(before.Version, arg.Version); err != nil {
+		return before, err
+	}
+	receipt, err := q.SoftDeleteFoodReceipt(ctx, arg)
+	if err != nil {
+		return receipt, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionDelete, AuditEntityReceipt, receipt.ID, before, receipt)
+	return receipt, err
+}
+
+// ゴミ箱からレシートを復元し、監査ログに記録する。
+func RestoreReceiptWithAudit(ctx context.Context, q Querier, arg RestoreFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	receipt, err := q.RestoreFoodReceipt(ctx, arg)
+	if err != nil {
+		return receipt, err
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionRestore, AuditEntityReceipt, receipt.ID, nil, receipt)
+	return receipt, err
+}
+
+// 送金を記録して送金元と送金先の残高を変更し、監査ログに記録する。
+// 並行する送金とのデッドロックを避けるため、ユーザーIDの小さい順に残高を更新する。
+// どちらかのユーザーが退会済みの場合は sql.ErrNoRows を返す。
+func CreateTransferWithAudit(ctx context.Context, q Querier, arg CreateTransferParams, actor AuditActor) (Transfer, error) {
+	transfer, err := q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return transfer, err
+	}
+	moves := []AddUserBalanceParams{
+		{ID: arg.FromUserID, Amount: -arg.Amount},
+		{ID: arg.ToUserID, Amount: arg.Amount},
+	}
+	if arg.ToUserID < arg.FromUserID {
+		moves[0], moves[1] = moves[1], moves[0]
+	}
+	for _, move := range moves {
+		if _, err := q.AddUserBalance(ctx, move); err != nil {
+			return transfer, fmt.Errorf("failed to AddUserBalance: %w", err)
+		}
+	}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionCreate, AuditEntityTransfer, transfer.ID, nil, transfer)
+	return transfer, err
+}
+
+// ユーザーの残高を変更し、変更前後の残高を監査ログに記録する。
+// 退会済みのユーザーの場合は sql.ErrNoRows を返す。
+func AdjustBalanceWithAudit(ctx context.Context, q Querier, arg AdjustBalanceTxParams, actor AuditActor) (int64, error) {
+	balance, err := q.AddUserBalance(ctx, AddUserBalanceParams{ID: arg.UserID, Amount: arg.Amount})
+	if err != nil {
+		return balance, err
+	}
+	before := balanceAuditRecord{Balance: balance - arg.Amount}
+	after := balanceAuditRecord{Balance: balance}
+	err = RecordAuditEvent(ctx, q, actor, AuditActionUpdate, AuditEntityBalance, arg.UserID, before, after)
+	return balance, err
+}
@@ -7,19 +7,243 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
+const categoryHasExpenses = `-- name: CategoryHasExpenses :one
+SELECT EXISTS (
+	SELECT 1 FROM expenses
+	WHERE category_id = $1
+		AND deleted_at IS NULL
+)
+`
+
+// ゴミ箱にある支出は数えない。
+func (q *Queries) CategoryHasExpenses(ctx context.Context, categoryID int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, categoryHasExpenses, categoryID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const createCategory = `-- name: CreateCategory :one
 INSERT INTO categories (
 	name
 ) VALUES (
 	$1
-) RETURNING id, name
+) RETURNING id, name, deleted_at, version
 `
 
 func (q *Queries) CreateCategory(ctx context.Context, name string) (Category, error) {
 	row := q.db.QueryRowContext(ctx, createCategory, name)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const deleteCategory = `-- name: DeleteCategory :one
+UPDATE categories
+SET
+	deleted_at = now(),
+	version = version + 1
+WHERE id = $1
+	AND version = $2
+	AND deleted_at IS NULL
+RETURNING id, name, deleted_at, version
+`
+
+type DeleteCategoryParams struct {
+	ID      int64 `json:"id"`
+	Version int64 `json:"version"`
+}
+
+// ゴミ箱に移す。完全に削除するのは PurgeDeletedCategories のみ。
+func (q *Queries) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, deleteCategory, arg.ID, arg.Version)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getCategoryForUpdate = `-- name: GetCategoryForUpdate :one
+SELECT id, name, deleted_at, version FROM categories
+WHERE id = $1
+	AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetCategoryForUpdate(ctx context.Context, id int64) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryForUpdate, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT id, name, deleted_at, version FROM categories
+WHERE deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedCategories = `-- name: ListDeletedCategories :many
+SELECT id, name, deleted_at, version FROM categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+`
+
+func (q *Queries) ListDeletedCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedCategories = `-- name: PurgeDeletedCategories :many
+DELETE FROM categories
+WHERE categories.deleted_at < $1::timestamptz
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.category_id = categories.id
+	)
+RETURNING id
+`
+
+// 支出から参照されているカテゴリーは残す。
+func (q *Queries) PurgeDeletedCategories(ctx context.Context, before time.Time) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedCategories, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreCategory = `-- name: RestoreCategory :one
+UPDATE categories
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE id = $1
+	AND deleted_at IS NOT NULL
+RETURNING id, name, deleted_at, version
+`
+
+func (q *Queries) RestoreCategory(ctx context.Context, id int64) (Category, error) {
+	row := q.db.QueryRowContext(ctx, restoreCategory, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateCategory = `-- name: UpdateCategory :one
+UPDATE categories
+SET
+	name = $1,
+	version = version + 1
+WHERE id = $2
+	AND version = $3
+	AND deleted_at IS NULL
+RETURNING id, name, deleted_at, version
+`
+
+type UpdateCategoryParams struct {
+	Name    string `json:"name"`
+	ID      int64  `json:"id"`
+	Version int64  `json:"version"`
+}
+
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, updateCategory, arg.Name, arg.ID, arg.Version)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.Version,
+	)
 	return i, err
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/kokoichi206/account-book-api/util"
@@ -28,3 +29,62 @@ func createRandomCategory(t *testing.T) Category {
 func TestCreateCategory(t *testing.T) {
 	createRandomCategory(t)
 }
+
+func TestListCategories(t *testing.T) {
+	// Arrange
+	category := createRandomCategory(t)
+
+	// Act
+	categories, err := testQueries.ListCategories(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Contains(t, categories, category)
+}
+
+func TestUpdateCategory(t *testing.T) {
+	// Arrange
+	category := createRandomCategory(t)
+	arg := UpdateCategoryParams{
+		Name:    util.RandomString(8),
+		ID:      category.ID,
+		Version: category.Version,
+	}
+
+	// Act
+	updated, err := testQueries.UpdateCategory(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, category.ID, updated.ID)
+	require.Equal(t, arg.Name, updated.Name)
+	require.Equal(t, category.Version+1, updated.Version)
+
+	// 古い version では更新できない。
+	_, err = testQueries.UpdateCategory(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteCategory(t *testing.T) {
+	// Arrange
+	category := createRandomCategory(t)
+
+	// Act
+	arg := DeleteCategoryParams{
+		ID:      category.ID,
+		Version: category.Version,
+	}
+	deleted, err := testQueries.DeleteCategory(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, category.ID, deleted.ID)
+	require.Equal(t, category.Name, deleted.Name)
+	// ゴミ箱に移すのみで、行は残す。
+	require.True(t, deleted.DeletedAt.Valid)
+	require.Equal(t, category.Version+1, deleted.Version)
+
+	arg.Version = deleted.Version
+	_, err = testQueries.DeleteCategory(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
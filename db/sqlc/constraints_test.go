@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+const deleteUserForTest = `DELETE FROM users WHERE id = $1`
+
+func requireCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr))
+	require.Equal(t, code, pqErr.Code.Name())
+}
+
+func TestTransferAmountCheck(t *testing.T) {
+	// Arrange
+	from := createRandomUser(t)
+	to := createRandomUser(t)
+
+	for _, amount := range []int64{0, -1} {
+		// Act
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromUserID: from.ID,
+			ToUserID:   to.ID,
+			Amount:     amount,
+		})
+
+		// Assert
+		requireCode(t, err, "check_violation")
+	}
+}
+
+func TestFoodReceiptContentAmountCheck(t *testing.T) {
+	// Arrange
+	receipt, err := testQueries.CreateFoodReceipt(context.Background(), CreateFoodReceiptParams{StoreName: util.RandomString(8)})
+	require.NoError(t, err)
+	content, err := testQueries.CreateFoodContent(context.Background(), CreateFoodContentParams{Name: util.RandomString(8)})
+	require.NoError(t, err)
+
+	// Act
+	_, err = testQueries.CreateFoodReceiptContent(context.Background(), CreateFoodReceiptContentParams{
+		FoodReceiptID: receipt.ID,
+		FoodContentID: content.ID,
+		Amount:        0,
+	})
+
+	// Assert
+	requireCode(t, err, "check_violation")
+}
+
+func TestDeleteUserCascadesSessions(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	session, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserAgent: "test",
+		ClientIp:  "127.0.0.1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Act
+	_, err = testDB.ExecContext(context.Background(), deleteUserForTest, user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = testQueries.GetSession(context.Background(), session.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteUserRestrictedByExpenses(t *testing.T) {
+	// Arrange
+	expense := createRandomExpense(t)
+
+	// Act
+	_, err := testDB.ExecContext(context.Background(), deleteUserForTest, expense.UserID)
+
+	// Assert
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func TestDeleteUserRestrictedByTransfers(t *testing.T) {
+	// Arrange
+	from := createRandomUser(t)
+	to := createRandomUser(t)
+	createRandomTransfer(t, from, to)
+
+	// Act
+	_, err := testDB.ExecContext(context.Background(), deleteUserForTest, to.ID)
+
+	// Assert
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	event, err := testQueries.CreateAuditEvent(context.Background(), CreateAuditEventParams{
+		ActorID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		ClientIp:   "127.0.0.1",
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityCategory,
+		EntityID:   1,
+		Before:     []byte("null"),
+		After:      []byte(`{"id": 1}`),
+	})
+	require.NoError(t, err)
+
+	// Act
+	_, errUpdate := testDB.ExecContext(context.Background(), `UPDATE audit_events SET client_ip = '' WHERE id = $1`, event.ID)
+	_, errDelete := testDB.ExecContext(context.Background(), `DELETE FROM audit_events WHERE id = $1`, event.ID)
+
+	// Assert
+	require.ErrorContains(t, errUpdate, "append-only")
+	require.ErrorContains(t, errDelete, "append-only")
+}
+
+// 削除したユーザーの監査ログから個人データを消す更新のみ許可し、消した後は更新できないこと。
+func TestAuditEventsRedaction(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	event, err := testQueries.CreateAuditEvent(context.Background(), CreateAuditEventParams{
+		ActorID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		ClientIp:   "127.0.0.1",
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityCategory,
+		EntityID:   1,
+		Before:     []byte("null"),
+		After:      []byte(`{"id": 1}`),
+	})
+	require.NoError(t, err)
+
+	// Act
+	_, errPartial := testDB.ExecContext(context.Background(), `UPDATE audit_events SET client_ip = '', redacted_at = now() WHERE id = $1`, event.ID)
+	n, errRedact := testQueries.RedactUserAuditEvents(context.Background(), user.ID)
+	_, errAgain := testDB.ExecContext(context.Background(), `UPDATE audit_events SET action = 'update' WHERE id = $1`, event.ID)
+
+	// Assert
+	require.ErrorContains(t, errPartial, "append-only")
+	require.NoError(t, errRedact)
+	require.Equal(t, int64(1), n)
+	require.ErrorContains(t, errAgain, "append-only")
+}
@@ -0,0 +1,22 @@
+package db_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/kokoichi206/account-book-api/db/dbtest"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+// メモリ上の実装や SQLite と同じテストを、PostgreSQL に対して実行する。
+func TestStoreContract(t *testing.T) {
+	config, err := util.LoadConfig("../..")
+	require.NoError(t, err)
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	dbtest.RunStoreContract(t, db.NewStore(conn))
+}
@@ -0,0 +1,83 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: email_change_tokens.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createEmailChangeToken = `-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+	user_id,
+	new_email,
+	token_hash,
+	expires_at
+) VALUES (
+	$1, $2, $3, $4
+) RETURNING id, user_id, new_email, token_hash, expires_at, used_at, created_at
+`
+
+type CreateEmailChangeTokenParams struct {
+	UserID    int64     `json:"user_id"`
+	NewEmail  string    `json:"new_email"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateEmailChangeToken(ctx context.Context, arg CreateEmailChangeTokenParams) (EmailChangeToken, error) {
+	row := q.db.QueryRowContext(ctx, createEmailChangeToken,
+		arg.UserID,
+		arg.NewEmail,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i EmailChangeToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.NewEmail,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteUserEmailChangeTokens = `-- name: DeleteUserEmailChangeTokens :exec
+DELETE FROM email_change_tokens
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserEmailChangeTokens(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserEmailChangeTokens, userID)
+	return err
+}
+
+const useEmailChangeToken = `-- name: UseEmailChangeToken :one
+UPDATE email_change_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1
+	AND used_at IS NULL
+	AND expires_at > CURRENT_TIMESTAMP
+RETURNING id, user_id, new_email, token_hash, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseEmailChangeToken(ctx context.Context, tokenHash string) (EmailChangeToken, error) {
+	row := q.db.QueryRowContext(ctx, useEmailChangeToken, tokenHash)
+	var i EmailChangeToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.NewEmail,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailChangeToken(t *testing.T, expiresAt time.Time) EmailChangeToken {
+	// Arrange
+	user := createRandomUser(t)
+	arg := CreateEmailChangeTokenParams{
+		UserID:    user.ID,
+		NewEmail:  util.RandomEmail(),
+		TokenHash: util.HashToken(util.RandomString(32)),
+		ExpiresAt: expiresAt,
+	}
+
+	// Act
+	token, err := testQueries.CreateEmailChangeToken(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotZero(t, token.ID)
+	require.Equal(t, arg.UserID, token.UserID)
+	require.Equal(t, arg.NewEmail, token.NewEmail)
+	require.Equal(t, arg.TokenHash, token.TokenHash)
+	require.False(t, token.UsedAt.Valid)
+
+	return token
+}
+
+func TestCreateEmailChangeToken(t *testing.T) {
+	createRandomEmailChangeToken(t, time.Now().Add(time.Hour))
+}
+
+func TestUseEmailChangeToken(t *testing.T) {
+	// Arrange
+	token := createRandomEmailChangeToken(t, time.Now().Add(time.Hour))
+
+	// Act
+	used, err := testQueries.UseEmailChangeToken(context.Background(), token.TokenHash)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, token.ID, used.ID)
+	require.True(t, used.UsedAt.Valid)
+
+	// 同じトークンは２度使えない。
+	_, err = testQueries.UseEmailChangeToken(context.Background(), token.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseEmailChangeTokenWithExpired(t *testing.T) {
+	// Arrange
+	token := createRandomEmailChangeToken(t, time.Now().Add(-time.Minute))
+
+	// Act
+	_, err := testQueries.UseEmailChangeToken(context.Background(), token.TokenHash)
+
+	// Assert
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
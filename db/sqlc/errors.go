@@ -0,0 +1,65 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// 制約違反の種類を表すエラー。
+// DBの種類によらず、errors.Is で判定する。
+var (
+	ErrUniqueViolation     = errors.New("unique violation")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	ErrCheckViolation      = errors.New("check violation")
+	ErrNotNullViolation    = errors.New("not null violation")
+)
+
+// DBの制約違反のエラー。
+// Kind に指定した ErrUniqueViolation などとして errors.Is で判定でき、
+// ドライバーが返した元のエラーは Unwrap で取り出せる。
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// PostgreSQL の制約違反の条件名と、対応するエラー。
+var pqConstraintErrors = map[string]error{
+	"unique_violation":      ErrUniqueViolation,
+	"foreign_key_violation": ErrForeignKeyViolation,
+	"check_violation":       ErrCheckViolation,
+	"not_null_violation":    ErrNotNullViolation,
+}
+
+// PostgreSQL の制約違反を *ConstraintError に変換する。
+// 制約違反以外のエラーはそのまま返す。
+func convertPQError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	kind, ok := pqConstraintErrors[pqErr.Code.Name()]
+	if !ok {
+		return err
+	}
+	return &ConstraintError{
+		Kind:       kind,
+		Table:      pqErr.Table,
+		Constraint: pqErr.Constraint,
+		Err:        err,
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertPQError(t *testing.T) {
+	errOther := errors.New("other")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "Unique",
+			err:      &pq.Error{Code: "23505", Table: "users", Constraint: "users_email_key"},
+			expected: ErrUniqueViolation,
+		},
+		{
+			name:     "ForeignKey",
+			err:      &pq.Error{Code: "23503"},
+			expected: ErrForeignKeyViolation,
+		},
+		{
+			name:     "Check",
+			err:      &pq.Error{Code: "23514"},
+			expected: ErrCheckViolation,
+		},
+		{
+			name:     "NotNull",
+			err:      &pq.Error{Code: "23502"},
+			expected: ErrNotNullViolation,
+		},
+		{
+			name: "SerializationFailure",
+			err:  &pq.Error{Code: "40001"},
+		},
+		{
+			name: "NoRows",
+			err:  sql.ErrNoRows,
+		},
+		{
+			name: "Other",
+			err:  errOther,
+		},
+		{
+			name: "Nil",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := convertPQError(tc.err)
+
+			// Assert
+			var constraintErr *ConstraintError
+			if tc.expected == nil {
+				require.False(t, errors.As(err, &constraintErr))
+				require.Equal(t, tc.err, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expected)
+			require.True(t, errors.As(err, &constraintErr))
+			// 元の *pq.Error も取り出せること。
+			var pqErr *pq.Error
+			require.True(t, errors.As(err, &pqErr))
+			require.Equal(t, pqErr.Table, constraintErr.Table)
+			require.Equal(t, pqErr.Constraint, constraintErr.Constraint)
+		})
+	}
+}
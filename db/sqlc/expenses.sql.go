@@ -24,7 +24,7 @@ INSERT INTO expenses (
 	$3,
 	$4,
 	$5
-) RETURNING id, user_id, category_id, amount, food_receipt_id, comment, created_at
+) RETURNING id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version
 `
 
 type CreateExpenseParams struct {
@@ -52,10 +52,208 @@ func (q *Queries) CreateExpense(ctx context.Context, arg CreateExpenseParams) (E
 		&i.FoodReceiptID,
 		&i.Comment,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
+const deleteUserExpenses = `-- name: DeleteUserExpenses :execrows
+DELETE FROM expenses
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserExpenses(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserExpenses, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getExpenseForUpdate = `-- name: GetExpenseForUpdate :one
+SELECT id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version FROM expenses
+WHERE id = $1
+	AND user_id = $2
+	AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+type GetExpenseForUpdateParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetExpenseForUpdate(ctx context.Context, arg GetExpenseForUpdateParams) (Expense, error) {
+	row := q.db.QueryRowContext(ctx, getExpenseForUpdate, arg.ID, arg.UserID)
+	var i Expense
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Amount,
+		&i.FoodReceiptID,
+		&i.Comment,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const listDeletedExpenses = `-- name: ListDeletedExpenses :many
+SELECT id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version FROM expenses
+WHERE user_id = $1
+	AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+`
+
+func (q *Queries) ListDeletedExpenses(ctx context.Context, userID int64) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedExpenses, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Expense{}
+	for rows.Next() {
+		var i Expense
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.FoodReceiptID,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuplicateExpenseCandidates = `-- name: ListDuplicateExpenseCandidates :many
+SELECT id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version FROM expenses
+WHERE user_id = $1
+	AND category_id = $2
+	AND amount = $3
+	AND created_at >= $4::timestamptz
+	AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+`
+
+type ListDuplicateExpenseCandidatesParams struct {
+	UserID     int64     `json:"user_id"`
+	CategoryID int64     `json:"category_id"`
+	Amount     int64     `json:"amount"`
+	Since      time.Time `json:"since"`
+}
+
+// 同じユーザー・カテゴリー・金額で、since 以降に作成された支出を新しい順に返す。
+func (q *Queries) ListDuplicateExpenseCandidates(ctx context.Context, arg ListDuplicateExpenseCandidatesParams) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicateExpenseCandidates,
+		arg.UserID,
+		arg.CategoryID,
+		arg.Amount,
+		arg.Since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Expense{}
+	for rows.Next() {
+		var i Expense
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.FoodReceiptID,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuplicateExpenses = `-- name: ListDuplicateExpenses :many
+SELECT id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version FROM expenses
+WHERE expenses.user_id = $1
+	AND expenses.deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1 FROM expenses AS other
+		WHERE other.user_id = expenses.user_id
+			AND other.id <> expenses.id
+			AND other.category_id = expenses.category_id
+			AND other.amount = expenses.amount
+			AND other.deleted_at IS NULL
+			AND abs(extract(epoch FROM other.created_at - expenses.created_at)) <= $2::float8
+	)
+ORDER BY expenses.category_id, expenses.amount, expenses.created_at, expenses.id
+`
+
+type ListDuplicateExpensesParams struct {
+	UserID        int64   `json:"user_id"`
+	WindowSeconds float64 `json:"window_seconds"`
+}
+
+// 同じカテゴリー・金額の支出が、前後 window_seconds 秒以内に他にもある支出を返す。
+// 重複の組ごとにまとめられるよう、カテゴリー・金額・作成日時の順に並べる。
+func (q *Queries) ListDuplicateExpenses(ctx context.Context, arg ListDuplicateExpensesParams) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicateExpenses, arg.UserID, arg.WindowSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Expense{}
+	for rows.Next() {
+		var i Expense
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.FoodReceiptID,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listExpenses = `-- name: ListExpenses :many
 SELECT
 	expenses.id AS id,
@@ -67,11 +265,13 @@ SELECT
 		ELSE food_receipts.store_name
 	END AS store_name,
 	expenses.comment AS comment,
-	expenses.created_at AS created_at
+	expenses.created_at AS created_at,
+	expenses.version AS version
 FROM expenses
 LEFT OUTER JOIN food_receipts ON expenses.food_receipt_id = food_receipts.id
 INNER JOIN categories ON expenses.category_id = categories.id
 WHERE expenses.user_id = $1
+	AND expenses.deleted_at IS NULL
 `
 
 type ListExpensesRow struct {
@@ -82,6 +282,7 @@ type ListExpensesRow struct {
 	StoreName  interface{}    `json:"store_name"`
 	Comment    sql.NullString `json:"comment"`
 	CreatedAt  time.Time      `json:"created_at"`
+	Version    int64          `json:"version"`
 }
 
 func (q *Queries) ListExpenses(ctx context.Context, userID int64) ([]ListExpensesRow, error) {
@@ -101,6 +302,73 @@ func (q *Queries) ListExpenses(ctx context.Context, userID int64) ([]ListExpense
 			&i.StoreName,
 			&i.Comment,
 			&i.CreatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExportExpenses = `-- name: ListExportExpenses :many
+SELECT
+	expenses.id AS id,
+	expenses.user_id AS user_id,
+	expenses.category_id AS category_id,
+	expenses.amount AS amount,
+	CASE
+		WHEN food_receipts.store_name IS NULL then ''
+		ELSE food_receipts.store_name
+	END AS store_name,
+	expenses.comment AS comment,
+	expenses.created_at AS created_at,
+	expenses.version AS version,
+	expenses.deleted_at AS deleted_at
+FROM expenses
+LEFT OUTER JOIN food_receipts ON expenses.food_receipt_id = food_receipts.id
+WHERE expenses.user_id = $1
+ORDER BY expenses.id
+`
+
+type ListExportExpensesRow struct {
+	ID         int64          `json:"id"`
+	UserID     int64          `json:"user_id"`
+	CategoryID int64          `json:"category_id"`
+	Amount     int64          `json:"amount"`
+	StoreName  interface{}    `json:"store_name"`
+	Comment    sql.NullString `json:"comment"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Version    int64          `json:"version"`
+	DeletedAt  sql.NullTime   `json:"deleted_at"`
+}
+
+// ユーザーの全ての支出を返す（エクスポート用）。ゴミ箱にある支出も含める。
+func (q *Queries) ListExportExpenses(ctx context.Context, userID int64) ([]ListExportExpensesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listExportExpenses, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListExportExpensesRow{}
+	for rows.Next() {
+		var i ListExportExpensesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.StoreName,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.Version,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -114,3 +382,147 @@ func (q *Queries) ListExpenses(ctx context.Context, userID int64) ([]ListExpense
 	}
 	return items, nil
 }
+
+const purgeDeletedExpenses = `-- name: PurgeDeletedExpenses :many
+DELETE FROM expenses
+WHERE deleted_at < $1::timestamptz
+RETURNING id
+`
+
+func (q *Queries) PurgeDeletedExpenses(ctx context.Context, before time.Time) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedExpenses, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreExpense = `-- name: RestoreExpense :one
+UPDATE expenses
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE id = $1
+	AND user_id = $2
+	AND deleted_at IS NOT NULL
+RETURNING id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version
+`
+
+type RestoreExpenseParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) RestoreExpense(ctx context.Context, arg RestoreExpenseParams) (Expense, error) {
+	row := q.db.QueryRowContext(ctx, restoreExpense, arg.ID, arg.UserID)
+	var i Expense
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Amount,
+		&i.FoodReceiptID,
+		&i.Comment,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const softDeleteExpense = `-- name: SoftDeleteExpense :one
+UPDATE expenses
+SET
+	deleted_at = now(),
+	version = version + 1
+WHERE id = $1
+	AND user_id = $2
+	AND version = $3
+	AND deleted_at IS NULL
+RETURNING id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version
+`
+
+type SoftDeleteExpenseParams struct {
+	ID      int64 `json:"id"`
+	UserID  int64 `json:"user_id"`
+	Version int64 `json:"version"`
+}
+
+func (q *Queries) SoftDeleteExpense(ctx context.Context, arg SoftDeleteExpenseParams) (Expense, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteExpense, arg.ID, arg.UserID, arg.Version)
+	var i Expense
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Amount,
+		&i.FoodReceiptID,
+		&i.Comment,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateExpense = `-- name: UpdateExpense :one
+UPDATE expenses
+SET
+	category_id = $1,
+	amount = $2,
+	comment = $3,
+	version = version + 1
+WHERE id = $4
+	AND user_id = $5
+	AND version = $6
+	AND deleted_at IS NULL
+RETURNING id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version
+`
+
+type UpdateExpenseParams struct {
+	CategoryID int64          `json:"category_id"`
+	Amount     int64          `json:"amount"`
+	Comment    sql.NullString `json:"comment"`
+	ID         int64          `json:"id"`
+	UserID     int64          `json:"user_id"`
+	Version    int64          `json:"version"`
+}
+
+func (q *Queries) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (Expense, error) {
+	row := q.db.QueryRowContext(ctx, updateExpense,
+		arg.CategoryID,
+		arg.Amount,
+		arg.Comment,
+		arg.ID,
+		arg.UserID,
+		arg.Version,
+	)
+	var i Expense
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Amount,
+		&i.FoodReceiptID,
+		&i.Comment,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
@@ -0,0 +1,165 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: idempotency_keys.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+	status_code = $1::integer,
+	response_headers = $2,
+	response_body = $3
+WHERE user_id = $4
+	AND key = $5
+`
+
+type CompleteIdempotencyKeyParams struct {
+	StatusCode      int32           `json:"status_code"`
+	ResponseHeaders json.RawMessage `json:"response_headers"`
+	ResponseBody    []byte          `json:"response_body"`
+	UserID          int64           `json:"user_id"`
+	Key             string          `json:"key"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.StatusCode,
+		arg.ResponseHeaders,
+		arg.ResponseBody,
+		arg.UserID,
+		arg.Key,
+	)
+	return err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+	user_id,
+	key,
+	fingerprint,
+	expires_at
+) VALUES (
+	$1, $2, $3, $4
+)
+ON CONFLICT (user_id, key) DO UPDATE
+SET
+	fingerprint = EXCLUDED.fingerprint,
+	status_code = NULL,
+	response_headers = '{}',
+	response_body = '',
+	expires_at = EXCLUDED.expires_at,
+	created_at = now()
+WHERE idempotency_keys.expires_at <= now()
+	OR (
+		idempotency_keys.status_code IS NULL
+		AND idempotency_keys.created_at < $5::timestamptz
+	)
+RETURNING user_id, key, fingerprint, status_code, response_headers, response_body, expires_at, created_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	UserID      int64     `json:"user_id"`
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	StaleBefore time.Time `json:"stale_before"`
+}
+
+// 有効期限を過ぎた行と、処理中のまま stale_before より前に作成された行は置き換える。
+// それ以外の行が既にある場合は、行を返さない。
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, createIdempotencyKey,
+		arg.UserID,
+		arg.Key,
+		arg.Fingerprint,
+		arg.ExpiresAt,
+		arg.StaleBefore,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.ResponseHeaders,
+		&i.ResponseBody,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1
+	AND key = $2
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID int64  `json:"user_id"`
+	Key    string `json:"key"`
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.Key)
+	return err
+}
+
+const deleteUserIdempotencyKeys = `-- name: DeleteUserIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserIdempotencyKeys(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserIdempotencyKeys, userID)
+	return err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, key, fingerprint, status_code, response_headers, response_body, expires_at, created_at FROM idempotency_keys
+WHERE user_id = $1
+	AND key = $2
+LIMIT 1
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID int64  `json:"user_id"`
+	Key    string `json:"key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.UserID, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.ResponseHeaders,
+		&i.ResponseBody,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const purgeExpiredIdempotencyKeys = `-- name: PurgeExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1
+`
+
+func (q *Queries) PurgeExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeExpiredIdempotencyKeys, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
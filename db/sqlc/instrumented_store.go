@@ -0,0 +1,841 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/util"
+	"go.uber.org/zap"
+)
+
+// クエリの処理時間などを受け取る関数。
+// query には Querier のメソッド名（CreateUser など）が渡される。
+type QueryObserver func(ctx context.Context, query string, duration time.Duration, err error)
+
+// observers に順に渡す QueryObserver を返す。
+func MultiQueryObserver(observers ...QueryObserver) QueryObserver {
+	return func(ctx context.Context, query string, duration time.Duration, err error) {
+		for _, observe := range observers {
+			observe(ctx, query, duration, err)
+		}
+	}
+}
+
+// クエリを、ctx に保持されたリクエストごとのLoggerに出力する QueryObserver を返す。
+// 失敗したクエリは warn、slowThreshold より遅いクエリも warn、それ以外は debug レベルで出力する。
+// 該当する行がないことは失敗として扱わない。
+func LogQueries(slowThreshold time.Duration) QueryObserver {
+	return func(ctx context.Context, query string, duration time.Duration, err error) {
+		logger := util.Logger(ctx).With(zap.String("query", query), zap.Duration("duration", duration))
+		switch {
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			logger.Warn("query failed", zap.Error(err))
+		case duration > slowThreshold:
+			logger.Warn("slow query")
+		default:
+			logger.Debug("query")
+		}
+	}
+}
+
+// 全てのクエリの処理時間を observe に渡すよう、Store を装飾する。
+// Store を埋め込まないため、Store にメソッドを追加した場合はここにも追加しないとコンパイルが通らない。
+type instrumentedStore struct {
+	next    Store
+	observe QueryObserver
+}
+
+// store の全てのメソッドの処理時間を observe に渡す Store を作成する。
+func NewInstrumentedStore(store Store, observe QueryObserver) Store {
+	return &instrumentedStore{
+		next:    store,
+		observe: observe,
+	}
+}
+
+func (store *instrumentedStore) AddUserBalance(ctx context.Context, arg AddUserBalanceParams) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.AddUserBalance(ctx, arg)
+	store.observe(ctx, "AddUserBalance", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams, actor AuditActor) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.AdjustBalanceTx(ctx, arg, actor)
+	store.observe(ctx, "AdjustBalanceTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) AnonymizeUser(ctx context.Context, id int64) (User, error) {
+	start := time.Now()
+	r0, err := store.next.AnonymizeUser(ctx, id)
+	store.observe(ctx, "AnonymizeUser", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CategoryHasExpenses(ctx context.Context, categoryID int64) (bool, error) {
+	start := time.Now()
+	r0, err := store.next.CategoryHasExpenses(ctx, categoryID)
+	store.observe(ctx, "CategoryHasExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	start := time.Now()
+	err := store.next.CompleteIdempotencyKey(ctx, arg)
+	store.observe(ctx, "CompleteIdempotencyKey", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) ConfirmEmailChangeTx(ctx context.Context, tokenHash string) (User, error) {
+	start := time.Now()
+	r0, err := store.next.ConfirmEmailChangeTx(ctx, tokenHash)
+	store.observe(ctx, "ConfirmEmailChangeTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CountActiveSessions(ctx context.Context) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.CountActiveSessions(ctx)
+	store.observe(ctx, "CountActiveSessions", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	start := time.Now()
+	r0, err := store.next.CreateAPIKey(ctx, arg)
+	store.observe(ctx, "CreateAPIKey", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	start := time.Now()
+	r0, err := store.next.CreateAuditEvent(ctx, arg)
+	store.observe(ctx, "CreateAuditEvent", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateCategory(ctx context.Context, name string) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.CreateCategory(ctx, name)
+	store.observe(ctx, "CreateCategory", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateCategoryTx(ctx context.Context, name string, actor AuditActor) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.CreateCategoryTx(ctx, name, actor)
+	store.observe(ctx, "CreateCategoryTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateEmailChangeToken(ctx context.Context, arg CreateEmailChangeTokenParams) (EmailChangeToken, error) {
+	start := time.Now()
+	r0, err := store.next.CreateEmailChangeToken(ctx, arg)
+	store.observe(ctx, "CreateEmailChangeToken", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateExpense(ctx context.Context, arg CreateExpenseParams) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.CreateExpense(ctx, arg)
+	store.observe(ctx, "CreateExpense", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateExpenseTx(ctx context.Context, arg CreateExpenseParams, actor AuditActor) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.CreateExpenseTx(ctx, arg, actor)
+	store.observe(ctx, "CreateExpenseTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateFoodContent(ctx context.Context, arg CreateFoodContentParams) (FoodContent, error) {
+	start := time.Now()
+	r0, err := store.next.CreateFoodContent(ctx, arg)
+	store.observe(ctx, "CreateFoodContent", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateFoodReceipt(ctx context.Context, arg CreateFoodReceiptParams) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.CreateFoodReceipt(ctx, arg)
+	store.observe(ctx, "CreateFoodReceipt", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateFoodReceiptContent(ctx context.Context, arg CreateFoodReceiptContentParams) (FoodReceiptContent, error) {
+	start := time.Now()
+	r0, err := store.next.CreateFoodReceiptContent(ctx, arg)
+	store.observe(ctx, "CreateFoodReceiptContent", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	start := time.Now()
+	r0, err := store.next.CreateIdempotencyKey(ctx, arg)
+	store.observe(ctx, "CreateIdempotencyKey", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateReceiptTx(ctx context.Context, arg CreateReceiptTxParams, actor AuditActor) (CreateReceiptTxResult, error) {
+	start := time.Now()
+	r0, err := store.next.CreateReceiptTx(ctx, arg, actor)
+	store.observe(ctx, "CreateReceiptTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	start := time.Now()
+	r0, err := store.next.CreateRecoveryCode(ctx, arg)
+	store.observe(ctx, "CreateRecoveryCode", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	start := time.Now()
+	r0, err := store.next.CreateSession(ctx, arg)
+	store.observe(ctx, "CreateSession", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	start := time.Now()
+	r0, err := store.next.CreateTransfer(ctx, arg)
+	store.observe(ctx, "CreateTransfer", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateTransferTx(ctx context.Context, arg CreateTransferParams, actor AuditActor) (Transfer, error) {
+	start := time.Now()
+	r0, err := store.next.CreateTransferTx(ctx, arg, actor)
+	store.observe(ctx, "CreateTransferTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) (TwoFactorChallenge, error) {
+	start := time.Now()
+	r0, err := store.next.CreateTwoFactorChallenge(ctx, arg)
+	store.observe(ctx, "CreateTwoFactorChallenge", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	start := time.Now()
+	r0, err := store.next.CreateUser(ctx, arg)
+	store.observe(ctx, "CreateUser", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.DeleteCategory(ctx, arg)
+	store.observe(ctx, "DeleteCategory", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteCategoryTx(ctx context.Context, arg DeleteCategoryParams, actor AuditActor) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.DeleteCategoryTx(ctx, arg, actor)
+	store.observe(ctx, "DeleteCategoryTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteExpenseTx(ctx context.Context, arg SoftDeleteExpenseParams, actor AuditActor) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.DeleteExpenseTx(ctx, arg, actor)
+	store.observe(ctx, "DeleteExpenseTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteFoodContent(ctx context.Context, arg DeleteFoodContentParams) (FoodContent, error) {
+	start := time.Now()
+	r0, err := store.next.DeleteFoodContent(ctx, arg)
+	store.observe(ctx, "DeleteFoodContent", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteFoodReceiptContents(ctx context.Context, foodReceiptIds []int64) error {
+	start := time.Now()
+	err := store.next.DeleteFoodReceiptContents(ctx, foodReceiptIds)
+	store.observe(ctx, "DeleteFoodReceiptContents", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteFoodReceipts(ctx context.Context, ids []int64) error {
+	start := time.Now()
+	err := store.next.DeleteFoodReceipts(ctx, ids)
+	store.observe(ctx, "DeleteFoodReceipts", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	start := time.Now()
+	err := store.next.DeleteIdempotencyKey(ctx, arg)
+	store.observe(ctx, "DeleteIdempotencyKey", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteReceiptTx(ctx context.Context, arg SoftDeleteFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.DeleteReceiptTx(ctx, arg, actor)
+	store.observe(ctx, "DeleteReceiptTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteRecoveryCodes(ctx, userID)
+	store.observe(ctx, "DeleteRecoveryCodes", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := store.next.DeleteSession(ctx, id)
+	store.observe(ctx, "DeleteSession", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteTwoFactorChallenge(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := store.next.DeleteTwoFactorChallenge(ctx, id)
+	store.observe(ctx, "DeleteTwoFactorChallenge", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteUserAPIKeys(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteUserAPIKeys(ctx, userID)
+	store.observe(ctx, "DeleteUserAPIKeys", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteUserEmailChangeTokens(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteUserEmailChangeTokens(ctx, userID)
+	store.observe(ctx, "DeleteUserEmailChangeTokens", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteUserExpenses(ctx context.Context, userID int64) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.DeleteUserExpenses(ctx, userID)
+	store.observe(ctx, "DeleteUserExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) DeleteUserIdempotencyKeys(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteUserIdempotencyKeys(ctx, userID)
+	store.observe(ctx, "DeleteUserIdempotencyKeys", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteUserSessions(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteUserSessions(ctx, userID)
+	store.observe(ctx, "DeleteUserSessions", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteUserTwoFactorChallenges(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteUserTwoFactorChallenges(ctx, userID)
+	store.observe(ctx, "DeleteUserTwoFactorChallenges", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) DeleteUserTx(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.DeleteUserTx(ctx, userID)
+	store.observe(ctx, "DeleteUserTx", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) EnableUserTOTP(ctx context.Context, id int64) (User, error) {
+	start := time.Now()
+	r0, err := store.next.EnableUserTOTP(ctx, id)
+	store.observe(ctx, "EnableUserTOTP", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error) {
+	start := time.Now()
+	r0, err := store.next.GetAPIKeyByHash(ctx, keyHash)
+	store.observe(ctx, "GetAPIKeyByHash", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetAuthSession(ctx context.Context, id uuid.UUID) (GetAuthSessionRow, error) {
+	start := time.Now()
+	r0, err := store.next.GetAuthSession(ctx, id)
+	store.observe(ctx, "GetAuthSession", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetCategoryForUpdate(ctx context.Context, id int64) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.GetCategoryForUpdate(ctx, id)
+	store.observe(ctx, "GetCategoryForUpdate", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetExpenseForUpdate(ctx context.Context, arg GetExpenseForUpdateParams) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.GetExpenseForUpdate(ctx, arg)
+	store.observe(ctx, "GetExpenseForUpdate", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetFoodContent(ctx context.Context, id int64) (FoodContent, error) {
+	start := time.Now()
+	r0, err := store.next.GetFoodContent(ctx, id)
+	store.observe(ctx, "GetFoodContent", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetFoodContentByName(ctx context.Context, name string) (FoodContent, error) {
+	start := time.Now()
+	r0, err := store.next.GetFoodContentByName(ctx, name)
+	store.observe(ctx, "GetFoodContentByName", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetFoodReceipt(ctx context.Context, id int64) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.GetFoodReceipt(ctx, id)
+	store.observe(ctx, "GetFoodReceipt", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetFoodReceiptForUpdate(ctx context.Context, arg GetFoodReceiptForUpdateParams) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.GetFoodReceiptForUpdate(ctx, arg)
+	store.observe(ctx, "GetFoodReceiptForUpdate", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	start := time.Now()
+	r0, err := store.next.GetIdempotencyKey(ctx, arg)
+	store.observe(ctx, "GetIdempotencyKey", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	start := time.Now()
+	r0, err := store.next.GetSession(ctx, id)
+	store.observe(ctx, "GetSession", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetTwoFactorChallenge(ctx context.Context, id uuid.UUID) (TwoFactorChallenge, error) {
+	start := time.Now()
+	r0, err := store.next.GetTwoFactorChallenge(ctx, id)
+	store.observe(ctx, "GetTwoFactorChallenge", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetUser(ctx context.Context, email string) (User, error) {
+	start := time.Now()
+	r0, err := store.next.GetUser(ctx, email)
+	store.observe(ctx, "GetUser", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) GetUserByID(ctx context.Context, id int64) (User, error) {
+	start := time.Now()
+	r0, err := store.next.GetUserByID(ctx, id)
+	store.observe(ctx, "GetUserByID", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListAPIKeys(ctx context.Context, userID int64) ([]ApiKey, error) {
+	start := time.Now()
+	r0, err := store.next.ListAPIKeys(ctx, userID)
+	store.observe(ctx, "ListAPIKeys", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	start := time.Now()
+	r0, err := store.next.ListAuditEvents(ctx, arg)
+	store.observe(ctx, "ListAuditEvents", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListCategories(ctx context.Context) ([]Category, error) {
+	start := time.Now()
+	r0, err := store.next.ListCategories(ctx)
+	store.observe(ctx, "ListCategories", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListDeletedCategories(ctx context.Context) ([]Category, error) {
+	start := time.Now()
+	r0, err := store.next.ListDeletedCategories(ctx)
+	store.observe(ctx, "ListDeletedCategories", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListDeletedExpenses(ctx context.Context, userID int64) ([]Expense, error) {
+	start := time.Now()
+	r0, err := store.next.ListDeletedExpenses(ctx, userID)
+	store.observe(ctx, "ListDeletedExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListDeletedFoodReceipts(ctx context.Context, userID int64) ([]FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.ListDeletedFoodReceipts(ctx, userID)
+	store.observe(ctx, "ListDeletedFoodReceipts", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListDuplicateExpenseCandidates(ctx context.Context, arg ListDuplicateExpenseCandidatesParams) ([]Expense, error) {
+	start := time.Now()
+	r0, err := store.next.ListDuplicateExpenseCandidates(ctx, arg)
+	store.observe(ctx, "ListDuplicateExpenseCandidates", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListDuplicateExpenses(ctx context.Context, arg ListDuplicateExpensesParams) ([]Expense, error) {
+	start := time.Now()
+	r0, err := store.next.ListDuplicateExpenses(ctx, arg)
+	store.observe(ctx, "ListDuplicateExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListDuplicateFoodReceiptCandidates(ctx context.Context, arg ListDuplicateFoodReceiptCandidatesParams) ([]FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.ListDuplicateFoodReceiptCandidates(ctx, arg)
+	store.observe(ctx, "ListDuplicateFoodReceiptCandidates", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListExpenses(ctx context.Context, userID int64) ([]ListExpensesRow, error) {
+	start := time.Now()
+	r0, err := store.next.ListExpenses(ctx, userID)
+	store.observe(ctx, "ListExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListExportExpenses(ctx context.Context, userID int64) ([]ListExportExpensesRow, error) {
+	start := time.Now()
+	r0, err := store.next.ListExportExpenses(ctx, userID)
+	store.observe(ctx, "ListExportExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListFoodContents(ctx context.Context, arg ListFoodContentsParams) ([]FoodContent, error) {
+	start := time.Now()
+	r0, err := store.next.ListFoodContents(ctx, arg)
+	store.observe(ctx, "ListFoodContents", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListFoodReceiptContents(ctx context.Context, foodReceiptID int64) ([]ListFoodReceiptContentsRow, error) {
+	start := time.Now()
+	r0, err := store.next.ListFoodReceiptContents(ctx, foodReceiptID)
+	store.observe(ctx, "ListFoodReceiptContents", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUserAuditEvents(ctx context.Context, arg ListUserAuditEventsParams) ([]AuditEvent, error) {
+	start := time.Now()
+	r0, err := store.next.ListUserAuditEvents(ctx, arg)
+	store.observe(ctx, "ListUserAuditEvents", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUserFoodReceiptContents(ctx context.Context, userID int64) ([]ListUserFoodReceiptContentsRow, error) {
+	start := time.Now()
+	r0, err := store.next.ListUserFoodReceiptContents(ctx, userID)
+	store.observe(ctx, "ListUserFoodReceiptContents", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUserFoodReceiptIDs(ctx context.Context, userID int64) ([]int64, error) {
+	start := time.Now()
+	r0, err := store.next.ListUserFoodReceiptIDs(ctx, userID)
+	store.observe(ctx, "ListUserFoodReceiptIDs", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUserSessions(ctx context.Context, userID int64) ([]Session, error) {
+	start := time.Now()
+	r0, err := store.next.ListUserSessions(ctx, userID)
+	store.observe(ctx, "ListUserSessions", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUserTransfers(ctx context.Context, fromUserID int64) ([]Transfer, error) {
+	start := time.Now()
+	r0, err := store.next.ListUserTransfers(ctx, fromUserID)
+	store.observe(ctx, "ListUserTransfers", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	start := time.Now()
+	r0, err := store.next.ListUsers(ctx, arg)
+	store.observe(ctx, "ListUsers", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]User, error) {
+	start := time.Now()
+	r0, err := store.next.ListUsersDueForDeletion(ctx, arg)
+	store.observe(ctx, "ListUsersDueForDeletion", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) MigrationVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	start := time.Now()
+	r0, r1, err := store.next.MigrationVersion(ctx)
+	store.observe(ctx, "MigrationVersion", time.Since(start), err)
+	return r0, r1, err
+}
+
+func (store *instrumentedStore) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := store.next.Ping(ctx)
+	store.observe(ctx, "Ping", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) PurgeDeletedCategories(ctx context.Context, before time.Time) ([]int64, error) {
+	start := time.Now()
+	r0, err := store.next.PurgeDeletedCategories(ctx, before)
+	store.observe(ctx, "PurgeDeletedCategories", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) PurgeDeletedExpenses(ctx context.Context, before time.Time) ([]int64, error) {
+	start := time.Now()
+	r0, err := store.next.PurgeDeletedExpenses(ctx, before)
+	store.observe(ctx, "PurgeDeletedExpenses", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) PurgeDeletedFoodReceiptContents(ctx context.Context, before time.Time) error {
+	start := time.Now()
+	err := store.next.PurgeDeletedFoodReceiptContents(ctx, before)
+	store.observe(ctx, "PurgeDeletedFoodReceiptContents", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) PurgeDeletedFoodReceipts(ctx context.Context, before time.Time) ([]int64, error) {
+	start := time.Now()
+	r0, err := store.next.PurgeDeletedFoodReceipts(ctx, before)
+	store.observe(ctx, "PurgeDeletedFoodReceipts", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) PurgeExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.PurgeExpiredIdempotencyKeys(ctx, expiresAt)
+	store.observe(ctx, "PurgeExpiredIdempotencyKeys", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) PurgeTrashTx(ctx context.Context, before time.Time) (PurgeTrashTxResult, error) {
+	start := time.Now()
+	r0, err := store.next.PurgeTrashTx(ctx, before)
+	store.observe(ctx, "PurgeTrashTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) PurgeUserSessions(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := store.next.PurgeUserSessions(ctx, userID)
+	store.observe(ctx, "PurgeUserSessions", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) RedactUserAuditEvents(ctx context.Context, actorID int64) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.RedactUserAuditEvents(ctx, actorID)
+	store.observe(ctx, "RedactUserAuditEvents", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RestoreCategory(ctx context.Context, id int64) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.RestoreCategory(ctx, id)
+	store.observe(ctx, "RestoreCategory", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RestoreCategoryTx(ctx context.Context, id int64, actor AuditActor) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.RestoreCategoryTx(ctx, id, actor)
+	store.observe(ctx, "RestoreCategoryTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RestoreExpense(ctx context.Context, arg RestoreExpenseParams) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.RestoreExpense(ctx, arg)
+	store.observe(ctx, "RestoreExpense", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RestoreExpenseTx(ctx context.Context, arg RestoreExpenseParams, actor AuditActor) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.RestoreExpenseTx(ctx, arg, actor)
+	store.observe(ctx, "RestoreExpenseTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RestoreFoodReceipt(ctx context.Context, arg RestoreFoodReceiptParams) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.RestoreFoodReceipt(ctx, arg)
+	store.observe(ctx, "RestoreFoodReceipt", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RestoreReceiptTx(ctx context.Context, arg RestoreFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.RestoreReceiptTx(ctx, arg, actor)
+	store.observe(ctx, "RestoreReceiptTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	start := time.Now()
+	r0, err := store.next.RevokeAPIKey(ctx, arg)
+	store.observe(ctx, "RevokeAPIKey", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) SoftDeleteExpense(ctx context.Context, arg SoftDeleteExpenseParams) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.SoftDeleteExpense(ctx, arg)
+	store.observe(ctx, "SoftDeleteExpense", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) SoftDeleteFoodReceipt(ctx context.Context, arg SoftDeleteFoodReceiptParams) (FoodReceipt, error) {
+	start := time.Now()
+	r0, err := store.next.SoftDeleteFoodReceipt(ctx, arg)
+	store.observe(ctx, "SoftDeleteFoodReceipt", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateAPIKeyLastUsed(ctx context.Context, id int64) error {
+	start := time.Now()
+	err := store.next.UpdateAPIKeyLastUsed(ctx, id)
+	store.observe(ctx, "UpdateAPIKeyLastUsed", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateCategory(ctx, arg)
+	store.observe(ctx, "UpdateCategory", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateCategoryTx(ctx context.Context, arg UpdateCategoryParams, actor AuditActor) (Category, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateCategoryTx(ctx, arg, actor)
+	store.observe(ctx, "UpdateCategoryTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateExpense(ctx, arg)
+	store.observe(ctx, "UpdateExpense", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateExpenseTx(ctx context.Context, arg UpdateExpenseTxParams, actor AuditActor) (Expense, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateExpenseTx(ctx, arg, actor)
+	store.observe(ctx, "UpdateExpenseTx", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateFoodContent(ctx context.Context, arg UpdateFoodContentParams) (FoodContent, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateFoodContent(ctx, arg)
+	store.observe(ctx, "UpdateFoodContent", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateSession(ctx context.Context, arg UpdateSessionParams) error {
+	start := time.Now()
+	err := store.next.UpdateSession(ctx, arg)
+	store.observe(ctx, "UpdateSession", time.Since(start), err)
+	return err
+}
+
+func (store *instrumentedStore) UpdateUserDeletionScheduledAt(ctx context.Context, arg UpdateUserDeletionScheduledAtParams) (User, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateUserDeletionScheduledAt(ctx, arg)
+	store.observe(ctx, "UpdateUserDeletionScheduledAt", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateUserDisabledAt(ctx context.Context, arg UpdateUserDisabledAtParams) (User, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateUserDisabledAt(ctx, arg)
+	store.observe(ctx, "UpdateUserDisabledAt", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateUserEmail(ctx, arg)
+	store.observe(ctx, "UpdateUserEmail", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateUserProfile(ctx, arg)
+	store.observe(ctx, "UpdateUserProfile", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateUserTOTPLastCounter(ctx context.Context, arg UpdateUserTOTPLastCounterParams) (int64, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateUserTOTPLastCounter(ctx, arg)
+	store.observe(ctx, "UpdateUserTOTPLastCounter", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error) {
+	start := time.Now()
+	r0, err := store.next.UpdateUserTOTPSecret(ctx, arg)
+	store.observe(ctx, "UpdateUserTOTPSecret", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UseEmailChangeToken(ctx context.Context, tokenHash string) (EmailChangeToken, error) {
+	start := time.Now()
+	r0, err := store.next.UseEmailChangeToken(ctx, tokenHash)
+	store.observe(ctx, "UseEmailChangeToken", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	start := time.Now()
+	r0, err := store.next.UseRecoveryCode(ctx, arg)
+	store.observe(ctx, "UseRecoveryCode", time.Since(start), err)
+	return r0, err
+}
+
+func (store *instrumentedStore) UseTwoFactorChallengeAttempt(ctx context.Context, arg UseTwoFactorChallengeAttemptParams) (TwoFactorChallenge, error) {
+	start := time.Now()
+	r0, err := store.next.UseTwoFactorChallengeAttempt(ctx, arg)
+	store.observe(ctx, "UseTwoFactorChallengeAttempt", time.Since(start), err)
+	return r0, err
+}
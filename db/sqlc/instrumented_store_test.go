@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedStore(t *testing.T) {
+	// Arrange
+	type observation struct {
+		query string
+		err   error
+	}
+	var observations []observation
+	store := NewInstrumentedStore(NewStore(testDB), func(ctx context.Context, query string, duration time.Duration, err error) {
+		require.Positive(t, duration)
+		observations = append(observations, observation{query: query, err: err})
+	})
+	user := createRandomUser(t)
+
+	// Act
+	got, err1 := store.GetUserByID(context.Background(), user.ID)
+	_, err2 := store.GetUser(context.Background(), util.RandomEmail())
+
+	// Assert
+	require.NoError(t, err1)
+	require.Equal(t, user.ID, got.ID)
+	require.ErrorIs(t, err2, sql.ErrNoRows)
+	require.Equal(t, []observation{
+		{query: "GetUserByID"},
+		{query: "GetUser", err: sql.ErrNoRows},
+	}, observations)
+}
@@ -6,14 +6,64 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+	// first characters of the key to identify it in lists
+	KeyPrefix string `json:"key_prefix"`
+	// sha256 of the key
+	KeyHash    string       `json:"key_hash"`
+	Scopes     []string     `json:"scopes"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// null when performed by a background worker
+	ActorID sql.NullInt64 `json:"actor_id"`
+	// null when authenticated with an API key
+	SessionID  uuid.NullUUID `json:"session_id"`
+	ClientIp   string        `json:"client_ip"`
+	Action     string        `json:"action"`
+	EntityType string        `json:"entity_type"`
+	EntityID   int64         `json:"entity_id"`
+	// JSON null when the entity was created or restored
+	Before json.RawMessage `json:"before"`
+	// the entity as moved to the trash when it was deleted
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+	// set when before, after, client_ip and session_id were cleared because the actor deleted the account
+	RedactedAt sql.NullTime `json:"redacted_at"`
+}
+
 type Category struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
+	// moved to the trash at this time
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// incremented on every update, delete and restore
+	Version int64 `json:"version"`
+}
+
+type EmailChangeToken struct {
+	ID       int64  `json:"id"`
+	UserID   int64  `json:"user_id"`
+	NewEmail string `json:"new_email"`
+	// sha256 of the confirmation token
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
 
 type Expense struct {
@@ -25,6 +75,10 @@ type Expense struct {
 	FoodReceiptID sql.NullInt64  `json:"food_receipt_id"`
 	Comment       sql.NullString `json:"comment"`
 	CreatedAt     time.Time      `json:"created_at"`
+	// moved to the trash at this time
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// incremented on every update, delete and restore
+	Version int64 `json:"version"`
 }
 
 type FoodContent struct {
@@ -34,11 +88,23 @@ type FoodContent struct {
 	Lipid        float32 `json:"lipid"`
 	Carbohydrate float32 `json:"carbohydrate"`
 	Protein      float32 `json:"protein"`
+	// incremented on every update
+	Version int64 `json:"version"`
 }
 
 type FoodReceipt struct {
 	ID        int64  `json:"id"`
 	StoreName string `json:"store_name"`
+	// moved to the trash at this time
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// incremented on every update, delete and restore
+	Version int64 `json:"version"`
+	// user who registered the receipt
+	UserID     sql.NullInt64 `json:"user_id"`
+	TotalPrice sql.NullInt64 `json:"total_price"`
+	// sha256 of the line items, independent of their order
+	ItemsDigest sql.NullString `json:"items_digest"`
+	CreatedAt   time.Time      `json:"created_at"`
 }
 
 type FoodReceiptContent struct {
@@ -49,6 +115,29 @@ type FoodReceiptContent struct {
 	Amount int64 `json:"amount"`
 }
 
+type IdempotencyKey struct {
+	UserID int64 `json:"user_id"`
+	// Idempotency-Key header sent by the client
+	Key string `json:"key"`
+	// sha256 of the method, path and body of the first request
+	Fingerprint string `json:"fingerprint"`
+	// null while the first request is in progress
+	StatusCode      sql.NullInt32   `json:"status_code"`
+	ResponseHeaders json.RawMessage `json:"response_headers"`
+	ResponseBody    []byte          `json:"response_body"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// sha256 of the recovery code
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type Session struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    int64     `json:"user_id"`
@@ -67,6 +156,17 @@ type Transfer struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type TwoFactorChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    int64     `json:"user_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// number of codes tried against the challenge
+	Attempts int32 `json:"attempts"`
+}
+
 type User struct {
 	ID                int64     `json:"id"`
 	Name              string    `json:"name"`
@@ -76,4 +176,22 @@ type User struct {
 	Balance           int64     `json:"balance"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// encrypted with AES-GCM
+	TotpSecret  sql.NullString `json:"totp_secret"`
+	TotpEnabled bool           `json:"totp_enabled"`
+	// user or admin
+	Role string `json:"role"`
+	// disabled by an admin when not null
+	DisabledAt sql.NullTime `json:"disabled_at"`
+	// ISO 4217 currency code
+	PreferredCurrency string `json:"preferred_currency"`
+	Locale            string `json:"locale"`
+	// IANA time zone name
+	Timezone string `json:"timezone"`
+	// personal data is deleted after this time
+	DeletionScheduledAt sql.NullTime `json:"deletion_scheduled_at"`
+	// kept as a tombstone for transfers after deletion
+	AnonymizedAt sql.NullTime `json:"anonymized_at"`
+	// time step of the last accepted TOTP code
+	TotpLastCounter sql.NullInt64 `json:"totp_last_counter"`
 }
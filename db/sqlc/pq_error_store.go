@@ -0,0 +1,575 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostgreSQL の制約違反を、ErrUniqueViolation などの *ConstraintError に変換するよう Store を装飾する。
+// memdb や SQLite の Store と同じエラーで判定できるよう、NewStore が返す Store に適用する。
+// Store を埋め込まないため、Store にメソッドを追加した場合はここにも追加しないとコンパイルが通らない。
+type pqErrorStore struct {
+	next Store
+}
+
+func (store *pqErrorStore) AddUserBalance(ctx context.Context, arg AddUserBalanceParams) (int64, error) {
+	r0, err := store.next.AddUserBalance(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams, actor AuditActor) (int64, error) {
+	r0, err := store.next.AdjustBalanceTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) AnonymizeUser(ctx context.Context, id int64) (User, error) {
+	r0, err := store.next.AnonymizeUser(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CategoryHasExpenses(ctx context.Context, categoryID int64) (bool, error) {
+	r0, err := store.next.CategoryHasExpenses(ctx, categoryID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	err := store.next.CompleteIdempotencyKey(ctx, arg)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) ConfirmEmailChangeTx(ctx context.Context, tokenHash string) (User, error) {
+	r0, err := store.next.ConfirmEmailChangeTx(ctx, tokenHash)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CountActiveSessions(ctx context.Context) (int64, error) {
+	r0, err := store.next.CountActiveSessions(ctx)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	r0, err := store.next.CreateAPIKey(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	r0, err := store.next.CreateAuditEvent(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateCategory(ctx context.Context, name string) (Category, error) {
+	r0, err := store.next.CreateCategory(ctx, name)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateCategoryTx(ctx context.Context, name string, actor AuditActor) (Category, error) {
+	r0, err := store.next.CreateCategoryTx(ctx, name, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateEmailChangeToken(ctx context.Context, arg CreateEmailChangeTokenParams) (EmailChangeToken, error) {
+	r0, err := store.next.CreateEmailChangeToken(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateExpense(ctx context.Context, arg CreateExpenseParams) (Expense, error) {
+	r0, err := store.next.CreateExpense(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateExpenseTx(ctx context.Context, arg CreateExpenseParams, actor AuditActor) (Expense, error) {
+	r0, err := store.next.CreateExpenseTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateFoodContent(ctx context.Context, arg CreateFoodContentParams) (FoodContent, error) {
+	r0, err := store.next.CreateFoodContent(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateFoodReceipt(ctx context.Context, arg CreateFoodReceiptParams) (FoodReceipt, error) {
+	r0, err := store.next.CreateFoodReceipt(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateFoodReceiptContent(ctx context.Context, arg CreateFoodReceiptContentParams) (FoodReceiptContent, error) {
+	r0, err := store.next.CreateFoodReceiptContent(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	r0, err := store.next.CreateIdempotencyKey(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateReceiptTx(ctx context.Context, arg CreateReceiptTxParams, actor AuditActor) (CreateReceiptTxResult, error) {
+	r0, err := store.next.CreateReceiptTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	r0, err := store.next.CreateRecoveryCode(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	r0, err := store.next.CreateSession(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	r0, err := store.next.CreateTransfer(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateTransferTx(ctx context.Context, arg CreateTransferParams, actor AuditActor) (Transfer, error) {
+	r0, err := store.next.CreateTransferTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) (TwoFactorChallenge, error) {
+	r0, err := store.next.CreateTwoFactorChallenge(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	r0, err := store.next.CreateUser(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (Category, error) {
+	r0, err := store.next.DeleteCategory(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteCategoryTx(ctx context.Context, arg DeleteCategoryParams, actor AuditActor) (Category, error) {
+	r0, err := store.next.DeleteCategoryTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteExpenseTx(ctx context.Context, arg SoftDeleteExpenseParams, actor AuditActor) (Expense, error) {
+	r0, err := store.next.DeleteExpenseTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteFoodContent(ctx context.Context, arg DeleteFoodContentParams) (FoodContent, error) {
+	r0, err := store.next.DeleteFoodContent(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteFoodReceiptContents(ctx context.Context, foodReceiptIds []int64) error {
+	err := store.next.DeleteFoodReceiptContents(ctx, foodReceiptIds)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteFoodReceipts(ctx context.Context, ids []int64) error {
+	err := store.next.DeleteFoodReceipts(ctx, ids)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	err := store.next.DeleteIdempotencyKey(ctx, arg)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteReceiptTx(ctx context.Context, arg SoftDeleteFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	r0, err := store.next.DeleteReceiptTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	err := store.next.DeleteRecoveryCodes(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	err := store.next.DeleteSession(ctx, id)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteTwoFactorChallenge(ctx context.Context, id uuid.UUID) error {
+	err := store.next.DeleteTwoFactorChallenge(ctx, id)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserAPIKeys(ctx context.Context, userID int64) error {
+	err := store.next.DeleteUserAPIKeys(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserEmailChangeTokens(ctx context.Context, userID int64) error {
+	err := store.next.DeleteUserEmailChangeTokens(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserExpenses(ctx context.Context, userID int64) (int64, error) {
+	r0, err := store.next.DeleteUserExpenses(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserIdempotencyKeys(ctx context.Context, userID int64) error {
+	err := store.next.DeleteUserIdempotencyKeys(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserSessions(ctx context.Context, userID int64) error {
+	err := store.next.DeleteUserSessions(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserTwoFactorChallenges(ctx context.Context, userID int64) error {
+	err := store.next.DeleteUserTwoFactorChallenges(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) DeleteUserTx(ctx context.Context, userID int64) error {
+	err := store.next.DeleteUserTx(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) EnableUserTOTP(ctx context.Context, id int64) (User, error) {
+	r0, err := store.next.EnableUserTOTP(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error) {
+	r0, err := store.next.GetAPIKeyByHash(ctx, keyHash)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetAuthSession(ctx context.Context, id uuid.UUID) (GetAuthSessionRow, error) {
+	r0, err := store.next.GetAuthSession(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetCategoryForUpdate(ctx context.Context, id int64) (Category, error) {
+	r0, err := store.next.GetCategoryForUpdate(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetExpenseForUpdate(ctx context.Context, arg GetExpenseForUpdateParams) (Expense, error) {
+	r0, err := store.next.GetExpenseForUpdate(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetFoodContent(ctx context.Context, id int64) (FoodContent, error) {
+	r0, err := store.next.GetFoodContent(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetFoodContentByName(ctx context.Context, name string) (FoodContent, error) {
+	r0, err := store.next.GetFoodContentByName(ctx, name)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetFoodReceipt(ctx context.Context, id int64) (FoodReceipt, error) {
+	r0, err := store.next.GetFoodReceipt(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetFoodReceiptForUpdate(ctx context.Context, arg GetFoodReceiptForUpdateParams) (FoodReceipt, error) {
+	r0, err := store.next.GetFoodReceiptForUpdate(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	r0, err := store.next.GetIdempotencyKey(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	r0, err := store.next.GetSession(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetTwoFactorChallenge(ctx context.Context, id uuid.UUID) (TwoFactorChallenge, error) {
+	r0, err := store.next.GetTwoFactorChallenge(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetUser(ctx context.Context, email string) (User, error) {
+	r0, err := store.next.GetUser(ctx, email)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) GetUserByID(ctx context.Context, id int64) (User, error) {
+	r0, err := store.next.GetUserByID(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListAPIKeys(ctx context.Context, userID int64) ([]ApiKey, error) {
+	r0, err := store.next.ListAPIKeys(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	r0, err := store.next.ListAuditEvents(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListCategories(ctx context.Context) ([]Category, error) {
+	r0, err := store.next.ListCategories(ctx)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListDeletedCategories(ctx context.Context) ([]Category, error) {
+	r0, err := store.next.ListDeletedCategories(ctx)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListDeletedExpenses(ctx context.Context, userID int64) ([]Expense, error) {
+	r0, err := store.next.ListDeletedExpenses(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListDeletedFoodReceipts(ctx context.Context, userID int64) ([]FoodReceipt, error) {
+	r0, err := store.next.ListDeletedFoodReceipts(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListDuplicateExpenseCandidates(ctx context.Context, arg ListDuplicateExpenseCandidatesParams) ([]Expense, error) {
+	r0, err := store.next.ListDuplicateExpenseCandidates(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListDuplicateExpenses(ctx context.Context, arg ListDuplicateExpensesParams) ([]Expense, error) {
+	r0, err := store.next.ListDuplicateExpenses(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListDuplicateFoodReceiptCandidates(ctx context.Context, arg ListDuplicateFoodReceiptCandidatesParams) ([]FoodReceipt, error) {
+	r0, err := store.next.ListDuplicateFoodReceiptCandidates(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListExpenses(ctx context.Context, userID int64) ([]ListExpensesRow, error) {
+	r0, err := store.next.ListExpenses(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListExportExpenses(ctx context.Context, userID int64) ([]ListExportExpensesRow, error) {
+	r0, err := store.next.ListExportExpenses(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListFoodContents(ctx context.Context, arg ListFoodContentsParams) ([]FoodContent, error) {
+	r0, err := store.next.ListFoodContents(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListFoodReceiptContents(ctx context.Context, foodReceiptID int64) ([]ListFoodReceiptContentsRow, error) {
+	r0, err := store.next.ListFoodReceiptContents(ctx, foodReceiptID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUserAuditEvents(ctx context.Context, arg ListUserAuditEventsParams) ([]AuditEvent, error) {
+	r0, err := store.next.ListUserAuditEvents(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUserFoodReceiptContents(ctx context.Context, userID int64) ([]ListUserFoodReceiptContentsRow, error) {
+	r0, err := store.next.ListUserFoodReceiptContents(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUserFoodReceiptIDs(ctx context.Context, userID int64) ([]int64, error) {
+	r0, err := store.next.ListUserFoodReceiptIDs(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUserSessions(ctx context.Context, userID int64) ([]Session, error) {
+	r0, err := store.next.ListUserSessions(ctx, userID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUserTransfers(ctx context.Context, fromUserID int64) ([]Transfer, error) {
+	r0, err := store.next.ListUserTransfers(ctx, fromUserID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	r0, err := store.next.ListUsers(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]User, error) {
+	r0, err := store.next.ListUsersDueForDeletion(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) MigrationVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	r0, r1, err := store.next.MigrationVersion(ctx)
+	return r0, r1, convertPQError(err)
+}
+
+func (store *pqErrorStore) Ping(ctx context.Context) error {
+	err := store.next.Ping(ctx)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeDeletedCategories(ctx context.Context, before time.Time) ([]int64, error) {
+	r0, err := store.next.PurgeDeletedCategories(ctx, before)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeDeletedExpenses(ctx context.Context, before time.Time) ([]int64, error) {
+	r0, err := store.next.PurgeDeletedExpenses(ctx, before)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeDeletedFoodReceiptContents(ctx context.Context, before time.Time) error {
+	err := store.next.PurgeDeletedFoodReceiptContents(ctx, before)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeDeletedFoodReceipts(ctx context.Context, before time.Time) ([]int64, error) {
+	r0, err := store.next.PurgeDeletedFoodReceipts(ctx, before)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	r0, err := store.next.PurgeExpiredIdempotencyKeys(ctx, expiresAt)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeTrashTx(ctx context.Context, before time.Time) (PurgeTrashTxResult, error) {
+	r0, err := store.next.PurgeTrashTx(ctx, before)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) PurgeUserSessions(ctx context.Context, userID int64) error {
+	err := store.next.PurgeUserSessions(ctx, userID)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) RedactUserAuditEvents(ctx context.Context, actorID int64) (int64, error) {
+	r0, err := store.next.RedactUserAuditEvents(ctx, actorID)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RestoreCategory(ctx context.Context, id int64) (Category, error) {
+	r0, err := store.next.RestoreCategory(ctx, id)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RestoreCategoryTx(ctx context.Context, id int64, actor AuditActor) (Category, error) {
+	r0, err := store.next.RestoreCategoryTx(ctx, id, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RestoreExpense(ctx context.Context, arg RestoreExpenseParams) (Expense, error) {
+	r0, err := store.next.RestoreExpense(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RestoreExpenseTx(ctx context.Context, arg RestoreExpenseParams, actor AuditActor) (Expense, error) {
+	r0, err := store.next.RestoreExpenseTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RestoreFoodReceipt(ctx context.Context, arg RestoreFoodReceiptParams) (FoodReceipt, error) {
+	r0, err := store.next.RestoreFoodReceipt(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RestoreReceiptTx(ctx context.Context, arg RestoreFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	r0, err := store.next.RestoreReceiptTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	r0, err := store.next.RevokeAPIKey(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) SoftDeleteExpense(ctx context.Context, arg SoftDeleteExpenseParams) (Expense, error) {
+	r0, err := store.next.SoftDeleteExpense(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) SoftDeleteFoodReceipt(ctx context.Context, arg SoftDeleteFoodReceiptParams) (FoodReceipt, error) {
+	r0, err := store.next.SoftDeleteFoodReceipt(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateAPIKeyLastUsed(ctx context.Context, id int64) error {
+	err := store.next.UpdateAPIKeyLastUsed(ctx, id)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
+	r0, err := store.next.UpdateCategory(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateCategoryTx(ctx context.Context, arg UpdateCategoryParams, actor AuditActor) (Category, error) {
+	r0, err := store.next.UpdateCategoryTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (Expense, error) {
+	r0, err := store.next.UpdateExpense(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateExpenseTx(ctx context.Context, arg UpdateExpenseTxParams, actor AuditActor) (Expense, error) {
+	r0, err := store.next.UpdateExpenseTx(ctx, arg, actor)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateFoodContent(ctx context.Context, arg UpdateFoodContentParams) (FoodContent, error) {
+	r0, err := store.next.UpdateFoodContent(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateSession(ctx context.Context, arg UpdateSessionParams) error {
+	err := store.next.UpdateSession(ctx, arg)
+	return convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateUserDeletionScheduledAt(ctx context.Context, arg UpdateUserDeletionScheduledAtParams) (User, error) {
+	r0, err := store.next.UpdateUserDeletionScheduledAt(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateUserDisabledAt(ctx context.Context, arg UpdateUserDisabledAtParams) (User, error) {
+	r0, err := store.next.UpdateUserDisabledAt(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	r0, err := store.next.UpdateUserEmail(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	r0, err := store.next.UpdateUserProfile(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateUserTOTPLastCounter(ctx context.Context, arg UpdateUserTOTPLastCounterParams) (int64, error) {
+	r0, err := store.next.UpdateUserTOTPLastCounter(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error) {
+	r0, err := store.next.UpdateUserTOTPSecret(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UseEmailChangeToken(ctx context.Context, tokenHash string) (EmailChangeToken, error) {
+	r0, err := store.next.UseEmailChangeToken(ctx, tokenHash)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	r0, err := store.next.UseRecoveryCode(ctx, arg)
+	return r0, convertPQError(err)
+}
+
+func (store *pqErrorStore) UseTwoFactorChallengeAttempt(ctx context.Context, arg UseTwoFactorChallengeAttemptParams) (TwoFactorChallenge, error) {
+	r0, err := store.next.UseTwoFactorChallengeAttempt(ctx, arg)
+	return r0, convertPQError(err)
+}
@@ -6,26 +6,136 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// 退会したユーザーの残高は変更せず、sql.ErrNoRows を返す。
+	AddUserBalance(ctx context.Context, arg AddUserBalanceParams) (int64, error)
+	// 送金履歴の相手側から参照されるため、行は削除せずに個人を特定できる情報を消す。
+	AnonymizeUser(ctx context.Context, id int64) (User, error)
+	// ゴミ箱にある支出は数えない。
+	CategoryHasExpenses(ctx context.Context, categoryID int64) (bool, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
+	CountActiveSessions(ctx context.Context) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateCategory(ctx context.Context, name string) (Category, error)
+	CreateEmailChangeToken(ctx context.Context, arg CreateEmailChangeTokenParams) (EmailChangeToken, error)
 	CreateExpense(ctx context.Context, arg CreateExpenseParams) (Expense, error)
 	CreateFoodContent(ctx context.Context, arg CreateFoodContentParams) (FoodContent, error)
-	CreateFoodReceipt(ctx context.Context, storeName string) (FoodReceipt, error)
+	CreateFoodReceipt(ctx context.Context, arg CreateFoodReceiptParams) (FoodReceipt, error)
 	CreateFoodReceiptContent(ctx context.Context, arg CreateFoodReceiptContentParams) (FoodReceiptContent, error)
+	// 有効期限を過ぎた行と、処理中のまま stale_before より前に作成された行は置き換える。
+	// それ以外の行が既にある場合は、行を返さない。
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) (TwoFactorChallenge, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// ゴミ箱に移す。完全に削除するのは PurgeDeletedCategories のみ。
+	DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (Category, error)
+	DeleteFoodContent(ctx context.Context, arg DeleteFoodContentParams) (FoodContent, error)
+	// 他のユーザーの支出から参照されているレシートの明細は残す。
+	DeleteFoodReceiptContents(ctx context.Context, foodReceiptIds []int64) error
+	// 他のユーザーの支出から参照されているレシートは残す。
+	DeleteFoodReceipts(ctx context.Context, ids []int64) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteRecoveryCodes(ctx context.Context, userID int64) error
 	DeleteSession(ctx context.Context, id uuid.UUID) error
+	DeleteTwoFactorChallenge(ctx context.Context, id uuid.UUID) error
+	DeleteUserAPIKeys(ctx context.Context, userID int64) error
+	DeleteUserEmailChangeTokens(ctx context.Context, userID int64) error
+	DeleteUserExpenses(ctx context.Context, userID int64) (int64, error)
+	DeleteUserIdempotencyKeys(ctx context.Context, userID int64) error
+	DeleteUserSessions(ctx context.Context, userID int64) error
+	DeleteUserTwoFactorChallenges(ctx context.Context, userID int64) error
+	EnableUserTOTP(ctx context.Context, id int64) (User, error)
+	// 無効化されたユーザーのAPIキーは存在しないものとして扱う。
+	// 認証後のメッセージに使うため、ユーザーの言語設定も合わせて取得する。
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error)
+	// 認証後のメッセージに使うため、ユーザーの言語設定と合わせて取得する。
+	GetAuthSession(ctx context.Context, id uuid.UUID) (GetAuthSessionRow, error)
+	GetCategoryForUpdate(ctx context.Context, id int64) (Category, error)
+	GetExpenseForUpdate(ctx context.Context, arg GetExpenseForUpdateParams) (Expense, error)
 	GetFoodContent(ctx context.Context, id int64) (FoodContent, error)
+	// 同じ名前の食品が複数ある場合は、最初に登録されたものを返す。
+	GetFoodContentByName(ctx context.Context, name string) (FoodContent, error)
 	GetFoodReceipt(ctx context.Context, id int64) (FoodReceipt, error)
+	// レシートを登録したユーザーのみ取得できる。
+	GetFoodReceiptForUpdate(ctx context.Context, arg GetFoodReceiptForUpdateParams) (FoodReceipt, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTwoFactorChallenge(ctx context.Context, id uuid.UUID) (TwoFactorChallenge, error)
 	GetUser(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id int64) (User, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]ApiKey, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	ListDeletedCategories(ctx context.Context) ([]Category, error)
+	ListDeletedExpenses(ctx context.Context, userID int64) ([]Expense, error)
+	ListDeletedFoodReceipts(ctx context.Context, userID int64) ([]FoodReceipt, error)
+	// 同じユーザー・カテゴリー・金額で、since 以降に作成された支出を新しい順に返す。
+	ListDuplicateExpenseCandidates(ctx context.Context, arg ListDuplicateExpenseCandidatesParams) ([]Expense, error)
+	// 同じカテゴリー・金額の支出が、前後 window_seconds 秒以内に他にもある支出を返す。
+	// 重複の組ごとにまとめられるよう、カテゴリー・金額・作成日時の順に並べる。
+	ListDuplicateExpenses(ctx context.Context, arg ListDuplicateExpensesParams) ([]Expense, error)
+	// 同じユーザーが since 以降に登録した、店名・合計金額・明細が同じレシートを新しい順に返す。
+	ListDuplicateFoodReceiptCandidates(ctx context.Context, arg ListDuplicateFoodReceiptCandidatesParams) ([]FoodReceipt, error)
 	ListExpenses(ctx context.Context, userID int64) ([]ListExpensesRow, error)
+	// ユーザーの全ての支出を返す（エクスポート用）。ゴミ箱にある支出も含める。
+	ListExportExpenses(ctx context.Context, userID int64) ([]ListExportExpensesRow, error)
+	ListFoodContents(ctx context.Context, arg ListFoodContentsParams) ([]FoodContent, error)
 	ListFoodReceiptContents(ctx context.Context, foodReceiptID int64) ([]ListFoodReceiptContentsRow, error)
+	ListUserAuditEvents(ctx context.Context, arg ListUserAuditEventsParams) ([]AuditEvent, error)
+	// ユーザーが登録したレシートを、明細ごとに1行で返す（エクスポート用）。
+	// 明細のないレシートも1行で返し、ゴミ箱にあるレシートも含める。
+	ListUserFoodReceiptContents(ctx context.Context, userID int64) ([]ListUserFoodReceiptContentsRow, error)
+	// ユーザーが登録したレシートを返す。
+	ListUserFoodReceiptIDs(ctx context.Context, userID int64) ([]int64, error)
+	ListUserSessions(ctx context.Context, userID int64) ([]Session, error)
+	ListUserTransfers(ctx context.Context, fromUserID int64) ([]Transfer, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]User, error)
+	// 支出から参照されているカテゴリーは残す。
+	PurgeDeletedCategories(ctx context.Context, before time.Time) ([]int64, error)
+	PurgeDeletedExpenses(ctx context.Context, before time.Time) ([]int64, error)
+	// 支出から参照されているレシートは削除できないため、明細も残す。
+	PurgeDeletedFoodReceiptContents(ctx context.Context, before time.Time) error
+	// 支出から参照されているレシートは残す。
+	PurgeDeletedFoodReceipts(ctx context.Context, before time.Time) ([]int64, error)
+	PurgeExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error)
+	PurgeUserSessions(ctx context.Context, userID int64) error
+	// アカウントを削除したユーザーが操作した行から、変更内容とアクセス元を消す。
+	RedactUserAuditEvents(ctx context.Context, actorID int64) (int64, error)
+	RestoreCategory(ctx context.Context, id int64) (Category, error)
+	RestoreExpense(ctx context.Context, arg RestoreExpenseParams) (Expense, error)
+	RestoreFoodReceipt(ctx context.Context, arg RestoreFoodReceiptParams) (FoodReceipt, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	SoftDeleteExpense(ctx context.Context, arg SoftDeleteExpenseParams) (Expense, error)
+	// レシートを登録したユーザーのみ、レシートを削除できる。
+	SoftDeleteFoodReceipt(ctx context.Context, arg SoftDeleteFoodReceiptParams) (FoodReceipt, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id int64) error
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (Expense, error)
+	UpdateFoodContent(ctx context.Context, arg UpdateFoodContentParams) (FoodContent, error)
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) error
+	UpdateUserDeletionScheduledAt(ctx context.Context, arg UpdateUserDeletionScheduledAtParams) (User, error)
+	UpdateUserDisabledAt(ctx context.Context, arg UpdateUserDisabledAtParams) (User, error)
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
+	// 前回受け付けたコードより後のステップの場合のみ更新する。
+	// 0行の場合は、受け付け済みのコードの再利用とみなす。
+	UpdateUserTOTPLastCounter(ctx context.Context, arg UpdateUserTOTPLastCounterParams) (int64, error)
+	UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error)
+	UseEmailChangeToken(ctx context.Context, tokenHash string) (EmailChangeToken, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	// コードを確かめる前に、試行回数を1回分消費する。
+	// 並行したリクエストでも上限を超えないよう、上限に達したチャレンジは返さない。
+	UseTwoFactorChallengeAttempt(ctx context.Context, arg UseTwoFactorChallengeAttemptParams) (TwoFactorChallenge, error)
 }
 
 var _ Querier = (*Queries)(nil)
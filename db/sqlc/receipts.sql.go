@@ -7,6 +7,10 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const createFoodContent = `-- name: CreateFoodContent :one
@@ -18,7 +22,7 @@ INSERT INTO food_contents (
 	Protein
 ) VALUES (
   $1, $2, $3, $4, $5
-) RETURNING id, name, calories, lipid, carbohydrate, protein
+) RETURNING id, name, calories, lipid, carbohydrate, protein, version
 `
 
 type CreateFoodContentParams struct {
@@ -45,22 +49,47 @@ func (q *Queries) CreateFoodContent(ctx context.Context, arg CreateFoodContentPa
 		&i.Lipid,
 		&i.Carbohydrate,
 		&i.Protein,
+		&i.Version,
 	)
 	return i, err
 }
 
 const createFoodReceipt = `-- name: CreateFoodReceipt :one
 INSERT INTO food_receipts (
-	store_name
+	store_name,
+	user_id,
+	total_price,
+	items_digest
 ) VALUES (
-	$1
-) RETURNING id, store_name
+	$1, $2, $3, $4
+) RETURNING id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at
 `
 
-func (q *Queries) CreateFoodReceipt(ctx context.Context, storeName string) (FoodReceipt, error) {
-	row := q.db.QueryRowContext(ctx, createFoodReceipt, storeName)
+type CreateFoodReceiptParams struct {
+	StoreName   string         `json:"store_name"`
+	UserID      sql.NullInt64  `json:"user_id"`
+	TotalPrice  sql.NullInt64  `json:"total_price"`
+	ItemsDigest sql.NullString `json:"items_digest"`
+}
+
+func (q *Queries) CreateFoodReceipt(ctx context.Context, arg CreateFoodReceiptParams) (FoodReceipt, error) {
+	row := q.db.QueryRowContext(ctx, createFoodReceipt,
+		arg.StoreName,
+		arg.UserID,
+		arg.TotalPrice,
+		arg.ItemsDigest,
+	)
 	var i FoodReceipt
-	err := row.Scan(&i.ID, &i.StoreName)
+	err := row.Scan(
+		&i.ID,
+		&i.StoreName,
+		&i.DeletedAt,
+		&i.Version,
+		&i.UserID,
+		&i.TotalPrice,
+		&i.ItemsDigest,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
@@ -92,8 +121,65 @@ func (q *Queries) CreateFoodReceiptContent(ctx context.Context, arg CreateFoodRe
 	return i, err
 }
 
+const deleteFoodContent = `-- name: DeleteFoodContent :one
+DELETE FROM food_contents
+WHERE id = $1
+	AND version = $2
+RETURNING id, name, calories, lipid, carbohydrate, protein, version
+`
+
+type DeleteFoodContentParams struct {
+	ID      int64 `json:"id"`
+	Version int64 `json:"version"`
+}
+
+func (q *Queries) DeleteFoodContent(ctx context.Context, arg DeleteFoodContentParams) (FoodContent, error) {
+	row := q.db.QueryRowContext(ctx, deleteFoodContent, arg.ID, arg.Version)
+	var i FoodContent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Calories,
+		&i.Lipid,
+		&i.Carbohydrate,
+		&i.Protein,
+		&i.Version,
+	)
+	return i, err
+}
+
+const deleteFoodReceiptContents = `-- name: DeleteFoodReceiptContents :exec
+DELETE FROM food_receipt_contents
+WHERE food_receipt_id = ANY($1::bigint[])
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipt_contents.food_receipt_id
+	)
+`
+
+// 他のユーザーの支出から参照されているレシートの明細は残す。
+func (q *Queries) DeleteFoodReceiptContents(ctx context.Context, foodReceiptIds []int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFoodReceiptContents, pq.Array(foodReceiptIds))
+	return err
+}
+
+const deleteFoodReceipts = `-- name: DeleteFoodReceipts :exec
+DELETE FROM food_receipts
+WHERE id = ANY($1::bigint[])
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipts.id
+	)
+`
+
+// 他のユーザーの支出から参照されているレシートは残す。
+func (q *Queries) DeleteFoodReceipts(ctx context.Context, ids []int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFoodReceipts, pq.Array(ids))
+	return err
+}
+
 const getFoodContent = `-- name: GetFoodContent :one
-SELECT id, name, calories, lipid, carbohydrate, protein FROM food_contents
+SELECT id, name, calories, lipid, carbohydrate, protein, version FROM food_contents
 WHERE id = $1 LIMIT 1
 `
 
@@ -107,22 +193,228 @@ func (q *Queries) GetFoodContent(ctx context.Context, id int64) (FoodContent, er
 		&i.Lipid,
 		&i.Carbohydrate,
 		&i.Protein,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getFoodContentByName = `-- name: GetFoodContentByName :one
+SELECT id, name, calories, lipid, carbohydrate, protein, version FROM food_contents
+WHERE name = $1
+ORDER BY id
+LIMIT 1
+`
+
+// 同じ名前の食品が複数ある場合は、最初に登録されたものを返す。
+func (q *Queries) GetFoodContentByName(ctx context.Context, name string) (FoodContent, error) {
+	row := q.db.QueryRowContext(ctx, getFoodContentByName, name)
+	var i FoodContent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Calories,
+		&i.Lipid,
+		&i.Carbohydrate,
+		&i.Protein,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getFoodReceipt = `-- name: GetFoodReceipt :one
-SELECT id, store_name FROM food_receipts
-WHERE id = $1 LIMIT 1
+SELECT id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at FROM food_receipts
+WHERE id = $1
+	AND deleted_at IS NULL
+LIMIT 1
 `
 
 func (q *Queries) GetFoodReceipt(ctx context.Context, id int64) (FoodReceipt, error) {
 	row := q.db.QueryRowContext(ctx, getFoodReceipt, id)
 	var i FoodReceipt
-	err := row.Scan(&i.ID, &i.StoreName)
+	err := row.Scan(
+		&i.ID,
+		&i.StoreName,
+		&i.DeletedAt,
+		&i.Version,
+		&i.UserID,
+		&i.TotalPrice,
+		&i.ItemsDigest,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFoodReceiptForUpdate = `-- name: GetFoodReceiptForUpdate :one
+SELECT id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at FROM food_receipts
+WHERE food_receipts.id = $1
+	AND food_receipts.deleted_at IS NULL
+	AND food_receipts.user_id = $2::bigint
+LIMIT 1
+FOR UPDATE
+`
+
+type GetFoodReceiptForUpdateParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+// レシートを登録したユーザーのみ取得できる。
+func (q *Queries) GetFoodReceiptForUpdate(ctx context.Context, arg GetFoodReceiptForUpdateParams) (FoodReceipt, error) {
+	row := q.db.QueryRowContext(ctx, getFoodReceiptForUpdate, arg.ID, arg.UserID)
+	var i FoodReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.StoreName,
+		&i.DeletedAt,
+		&i.Version,
+		&i.UserID,
+		&i.TotalPrice,
+		&i.ItemsDigest,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
+const listDeletedFoodReceipts = `-- name: ListDeletedFoodReceipts :many
+SELECT id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at FROM food_receipts
+WHERE food_receipts.deleted_at IS NOT NULL
+	AND food_receipts.user_id = $1::bigint
+ORDER BY food_receipts.deleted_at DESC, food_receipts.id DESC
+`
+
+func (q *Queries) ListDeletedFoodReceipts(ctx context.Context, userID int64) ([]FoodReceipt, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedFoodReceipts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FoodReceipt{}
+	for rows.Next() {
+		var i FoodReceipt
+		if err := rows.Scan(
+			&i.ID,
+			&i.StoreName,
+			&i.DeletedAt,
+			&i.Version,
+			&i.UserID,
+			&i.TotalPrice,
+			&i.ItemsDigest,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuplicateFoodReceiptCandidates = `-- name: ListDuplicateFoodReceiptCandidates :many
+SELECT id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at FROM food_receipts
+WHERE user_id = $1
+	AND store_name = $2
+	AND total_price = $3
+	AND items_digest = $4
+	AND created_at >= $5::timestamptz
+	AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+`
+
+type ListDuplicateFoodReceiptCandidatesParams struct {
+	UserID      sql.NullInt64  `json:"user_id"`
+	StoreName   string         `json:"store_name"`
+	TotalPrice  sql.NullInt64  `json:"total_price"`
+	ItemsDigest sql.NullString `json:"items_digest"`
+	Since       time.Time      `json:"since"`
+}
+
+// 同じユーザーが since 以降に登録した、店名・合計金額・明細が同じレシートを新しい順に返す。
+func (q *Queries) ListDuplicateFoodReceiptCandidates(ctx context.Context, arg ListDuplicateFoodReceiptCandidatesParams) ([]FoodReceipt, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicateFoodReceiptCandidates,
+		arg.UserID,
+		arg.StoreName,
+		arg.TotalPrice,
+		arg.ItemsDigest,
+		arg.Since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FoodReceipt{}
+	for rows.Next() {
+		var i FoodReceipt
+		if err := rows.Scan(
+			&i.ID,
+			&i.StoreName,
+			&i.DeletedAt,
+			&i.Version,
+			&i.UserID,
+			&i.TotalPrice,
+			&i.ItemsDigest,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFoodContents = `-- name: ListFoodContents :many
+SELECT id, name, calories, lipid, carbohydrate, protein, version FROM food_contents
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListFoodContentsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListFoodContents(ctx context.Context, arg ListFoodContentsParams) ([]FoodContent, error) {
+	rows, err := q.db.QueryContext(ctx, listFoodContents, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FoodContent{}
+	for rows.Next() {
+		var i FoodContent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Calories,
+			&i.Lipid,
+			&i.Carbohydrate,
+			&i.Protein,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listFoodReceiptContents = `-- name: ListFoodReceiptContents :many
 SELECT
 	food_receipt_contents.food_receipt_id AS food_receipt_id,
@@ -180,3 +472,268 @@ func (q *Queries) ListFoodReceiptContents(ctx context.Context, foodReceiptID int
 	}
 	return items, nil
 }
+
+const listUserFoodReceiptContents = `-- name: ListUserFoodReceiptContents :many
+SELECT
+	food_receipts.id AS food_receipt_id,
+	food_receipts.store_name AS store_name,
+	food_receipts.deleted_at AS deleted_at,
+	food_receipt_contents.food_content_id AS food_content_id,
+	food_receipt_contents.amount AS amount,
+	food_contents.name AS name,
+	food_contents.calories AS calories,
+	food_contents.lipid AS lipid,
+	food_contents.carbohydrate AS carbohydrate,
+	food_contents.protein AS protein
+FROM food_receipts
+LEFT OUTER JOIN food_receipt_contents ON food_receipt_contents.food_receipt_id = food_receipts.id
+LEFT OUTER JOIN food_contents ON food_receipt_contents.food_content_id = food_contents.id
+WHERE food_receipts.user_id = $1::bigint
+ORDER BY food_receipts.id, food_receipt_contents.id
+`
+
+type ListUserFoodReceiptContentsRow struct {
+	FoodReceiptID int64           `json:"food_receipt_id"`
+	StoreName     string          `json:"store_name"`
+	DeletedAt     sql.NullTime    `json:"deleted_at"`
+	FoodContentID sql.NullInt64   `json:"food_content_id"`
+	Amount        sql.NullInt64   `json:"amount"`
+	Name          sql.NullString  `json:"name"`
+	Calories      sql.NullFloat64 `json:"calories"`
+	Lipid         sql.NullFloat64 `json:"lipid"`
+	Carbohydrate  sql.NullFloat64 `json:"carbohydrate"`
+	Protein       sql.NullFloat64 `json:"protein"`
+}
+
+// ユーザーが登録したレシートを、明細ごとに1行で返す（エクスポート用）。
+// 明細のないレシートも1行で返し、ゴミ箱にあるレシートも含める。
+func (q *Queries) ListUserFoodReceiptContents(ctx context.Context, userID int64) ([]ListUserFoodReceiptContentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserFoodReceiptContents, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserFoodReceiptContentsRow{}
+	for rows.Next() {
+		var i ListUserFoodReceiptContentsRow
+		if err := rows.Scan(
+			&i.FoodReceiptID,
+			&i.StoreName,
+			&i.DeletedAt,
+			&i.FoodContentID,
+			&i.Amount,
+			&i.Name,
+			&i.Calories,
+			&i.Lipid,
+			&i.Carbohydrate,
+			&i.Protein,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserFoodReceiptIDs = `-- name: ListUserFoodReceiptIDs :many
+SELECT id
+FROM food_receipts
+WHERE food_receipts.user_id = $1::bigint
+ORDER BY id
+`
+
+// ユーザーが登録したレシートを返す。
+func (q *Queries) ListUserFoodReceiptIDs(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listUserFoodReceiptIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedFoodReceiptContents = `-- name: PurgeDeletedFoodReceiptContents :exec
+DELETE FROM food_receipt_contents
+WHERE food_receipt_id IN (
+	SELECT food_receipts.id FROM food_receipts
+	WHERE food_receipts.deleted_at < $1::timestamptz
+		AND NOT EXISTS (
+			SELECT 1 FROM expenses
+			WHERE expenses.food_receipt_id = food_receipts.id
+		)
+)
+`
+
+// 支出から参照されているレシートは削除できないため、明細も残す。
+func (q *Queries) PurgeDeletedFoodReceiptContents(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, purgeDeletedFoodReceiptContents, before)
+	return err
+}
+
+const purgeDeletedFoodReceipts = `-- name: PurgeDeletedFoodReceipts :many
+DELETE FROM food_receipts
+WHERE food_receipts.deleted_at < $1::timestamptz
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipts.id
+	)
+RETURNING id
+`
+
+// 支出から参照されているレシートは残す。
+func (q *Queries) PurgeDeletedFoodReceipts(ctx context.Context, before time.Time) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedFoodReceipts, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreFoodReceipt = `-- name: RestoreFoodReceipt :one
+UPDATE food_receipts
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE food_receipts.id = $1
+	AND food_receipts.deleted_at IS NOT NULL
+	AND food_receipts.user_id = $2::bigint
+RETURNING id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at
+`
+
+type RestoreFoodReceiptParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) RestoreFoodReceipt(ctx context.Context, arg RestoreFoodReceiptParams) (FoodReceipt, error) {
+	row := q.db.QueryRowContext(ctx, restoreFoodReceipt, arg.ID, arg.UserID)
+	var i FoodReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.StoreName,
+		&i.DeletedAt,
+		&i.Version,
+		&i.UserID,
+		&i.TotalPrice,
+		&i.ItemsDigest,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const softDeleteFoodReceipt = `-- name: SoftDeleteFoodReceipt :one
+UPDATE food_receipts
+SET
+	deleted_at = now(),
+	version = version + 1
+WHERE food_receipts.id = $1
+	AND food_receipts.version = $2
+	AND food_receipts.deleted_at IS NULL
+	AND food_receipts.user_id = $3::bigint
+RETURNING id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at
+`
+
+type SoftDeleteFoodReceiptParams struct {
+	ID      int64 `json:"id"`
+	Version int64 `json:"version"`
+	UserID  int64 `json:"user_id"`
+}
+
+// レシートを登録したユーザーのみ、レシートを削除できる。
+func (q *Queries) SoftDeleteFoodReceipt(ctx context.Context, arg SoftDeleteFoodReceiptParams) (FoodReceipt, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteFoodReceipt, arg.ID, arg.Version, arg.UserID)
+	var i FoodReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.StoreName,
+		&i.DeletedAt,
+		&i.Version,
+		&i.UserID,
+		&i.TotalPrice,
+		&i.ItemsDigest,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateFoodContent = `-- name: UpdateFoodContent :one
+UPDATE food_contents
+SET
+	name = $1,
+	calories = $2,
+	lipid = $3,
+	carbohydrate = $4,
+	protein = $5,
+	version = version + 1
+WHERE id = $6
+	AND version = $7
+RETURNING id, name, calories, lipid, carbohydrate, protein, version
+`
+
+type UpdateFoodContentParams struct {
+	Name         string  `json:"name"`
+	Calories     float32 `json:"calories"`
+	Lipid        float32 `json:"lipid"`
+	Carbohydrate float32 `json:"carbohydrate"`
+	Protein      float32 `json:"protein"`
+	ID           int64   `json:"id"`
+	Version      int64   `json:"version"`
+}
+
+func (q *Queries) UpdateFoodContent(ctx context.Context, arg UpdateFoodContentParams) (FoodContent, error) {
+	row := q.db.QueryRowContext(ctx, updateFoodContent,
+		arg.Name,
+		arg.Calories,
+		arg.Lipid,
+		arg.Carbohydrate,
+		arg.Protein,
+		arg.ID,
+		arg.Version,
+	)
+	var i FoodContent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Calories,
+		&i.Lipid,
+		&i.Carbohydrate,
+		&i.Protein,
+		&i.Version,
+	)
+	return i, err
+}
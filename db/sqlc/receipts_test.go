@@ -11,17 +11,22 @@ import (
 
 func createRandomFoodReceipt(t *testing.T) FoodReceipt {
 	// Arrange
-	storeName := util.RandomStoreName()
+	arg := CreateFoodReceiptParams{
+		StoreName:  util.RandomStoreName(),
+		TotalPrice: sql.NullInt64{Int64: util.RandomExpense(), Valid: true},
+	}
 
 	// Act
-	foodReceipt, err := testQueries.CreateFoodReceipt(context.Background(), storeName)
+	foodReceipt, err := testQueries.CreateFoodReceipt(context.Background(), arg)
 
 	// Assert
 	require.NoError(t, err)
 	require.NotEmpty(t, foodReceipt)
 
 	require.NotZero(t, foodReceipt.ID)
-	require.Equal(t, storeName, foodReceipt.StoreName)
+	require.Equal(t, arg.StoreName, foodReceipt.StoreName)
+	require.Equal(t, arg.TotalPrice, foodReceipt.TotalPrice)
+	require.NotZero(t, foodReceipt.CreatedAt)
 
 	return foodReceipt
 }
@@ -210,3 +215,48 @@ func TestListFoodReceiptContentsWithEmpty(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, foodReceiptContents)
 }
+
+func TestUpdateFoodContent(t *testing.T) {
+	// Arrange
+	foodContent := createRandomFoodContent(t)
+	arg := UpdateFoodContentParams{
+		Name:         util.RandomFoodName(),
+		Calories:     util.RandomCalories(),
+		Lipid:        util.RandomNutrient(),
+		Carbohydrate: util.RandomNutrient(),
+		Protein:      util.RandomNutrient(),
+		ID:           foodContent.ID,
+		Version:      foodContent.Version,
+	}
+
+	// Act
+	updated, err := testQueries.UpdateFoodContent(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, foodContent.ID, updated.ID)
+	require.Equal(t, arg.Name, updated.Name)
+	require.Equal(t, arg.Calories, updated.Calories)
+	require.Equal(t, foodContent.Version+1, updated.Version)
+
+	// 古い version では更新できない。
+	_, err = testQueries.UpdateFoodContent(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteFoodContentInUse(t *testing.T) {
+	// Arrange
+	foodContent := createRandomFoodContent(t)
+	createRandomFoodReceiptContent(t, createRandomFoodReceipt(t), foodContent)
+
+	// Act
+	arg := DeleteFoodContentParams{
+		ID:      foodContent.ID,
+		Version: foodContent.Version,
+	}
+	_, err := testQueries.DeleteFoodContent(context.Background(), arg)
+
+	// Assert
+	// レシートから参照されている食品は削除できない。
+	require.Error(t, err)
+}
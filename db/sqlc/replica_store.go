@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+
+	"github.com/kokoichi206/account-book-api/util"
+	"go.uber.org/zap"
+)
+
+// context にプライマリでの実行を指定する際のキー。
+type primaryContextKey struct{}
+
+// ReplicaStore でも、全てのクエリをプライマリで実行する context を返す。
+// 更新した直後に、その結果を読む場合（read-your-writes）に使う。
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func primaryRequired(ctx context.Context) bool {
+	required, _ := util.RequestContext(ctx).Value(primaryContextKey{}).(bool)
+	return required
+}
+
+// 参照のみの重いクエリをレプリカで実行し、それ以外をプライマリで実行する Store。
+//
+// レプリカで実行するのは、履歴が増えるほど重くなる一覧・集計と、レシートの参照のみ。
+// 更新の前提となる確認（重複の確認など）は、レプリカの遅延の影響を受けないようプライマリで実行する。
+// レプリカが異常な場合や、WithPrimary を指定した context の場合はプライマリで実行する。
+type ReplicaStore struct {
+	Store
+	replica Store
+	// レプリカが正常か。別のgoroutineから更新されるため、atomic に読み書きする。
+	healthy int32
+}
+
+// primary と replica を使い分ける ReplicaStore を作成する。
+// レプリカは、CheckReplica で異常を検知するまで正常とみなす。
+func NewReplicaStore(primary Store, replica Store) *ReplicaStore {
+	return &ReplicaStore{
+		Store:   primary,
+		replica: replica,
+		healthy: 1,
+	}
+}
+
+// レプリカに接続できるか確かめ、結果を記録する。
+// 異常の間はプライマリで実行し、接続できるようになったらレプリカに戻す。
+func (store *ReplicaStore) CheckReplica(ctx context.Context) error {
+	err := store.replica.Ping(ctx)
+	store.setHealthy(err == nil)
+	return err
+}
+
+// レプリカが正常か。
+func (store *ReplicaStore) ReplicaHealthy() bool {
+	return atomic.LoadInt32(&store.healthy) == 1
+}
+
+func (store *ReplicaStore) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	atomic.StoreInt32(&store.healthy, v)
+}
+
+// fn をレプリカで実行する。
+// レプリカを使えない場合や、レプリカでの実行が接続などの理由で失敗した場合はプライマリで実行する。
+func (store *ReplicaStore) read(ctx context.Context, query string, fn func(Store) error) error {
+	if store.ReplicaHealthy() && !primaryRequired(ctx) {
+		err := fn(store.replica)
+		// 該当する行がないことや、リクエストのキャンセルはレプリカの異常ではない。
+		if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+			return err
+		}
+		util.Logger(ctx).Warn("query on replica failed, falling back to primary", zap.String("query", query), zap.Error(err))
+		store.setHealthy(false)
+	}
+	return fn(store.Store)
+}
+
+func (store *ReplicaStore) ListExpenses(ctx context.Context, userID int64) ([]ListExpensesRow, error) {
+	var rows []ListExpensesRow
+	err := store.read(ctx, "ListExpenses", func(s Store) error {
+		var err error
+		rows, err = s.ListExpenses(ctx, userID)
+		return err
+	})
+	return rows, err
+}
+
+func (store *ReplicaStore) ListDuplicateExpenses(ctx context.Context, arg ListDuplicateExpensesParams) ([]Expense, error) {
+	var expenses []Expense
+	err := store.read(ctx, "ListDuplicateExpenses", func(s Store) error {
+		var err error
+		expenses, err = s.ListDuplicateExpenses(ctx, arg)
+		return err
+	})
+	return expenses, err
+}
+
+func (store *ReplicaStore) GetFoodReceipt(ctx context.Context, id int64) (FoodReceipt, error) {
+	var receipt FoodReceipt
+	err := store.read(ctx, "GetFoodReceipt", func(s Store) error {
+		var err error
+		receipt, err = s.GetFoodReceipt(ctx, id)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *ReplicaStore) ListFoodReceiptContents(ctx context.Context, foodReceiptID int64) ([]ListFoodReceiptContentsRow, error) {
+	var contents []ListFoodReceiptContentsRow
+	err := store.read(ctx, "ListFoodReceiptContents", func(s Store) error {
+		var err error
+		contents, err = s.ListFoodReceiptContents(ctx, foodReceiptID)
+		return err
+	})
+	return contents, err
+}
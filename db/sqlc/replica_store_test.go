@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// 呼び出された回数と、返すエラーを指定できる Store。
+// テストで使わないメソッドは、埋め込んだ nil の Store により panic する。
+type fakeReadStore struct {
+	Store
+	calls   int
+	err     error
+	pingErr error
+}
+
+func (store *fakeReadStore) ListExpenses(ctx context.Context, userID int64) ([]ListExpensesRow, error) {
+	store.calls++
+	if store.err != nil {
+		return nil, store.err
+	}
+	return []ListExpensesRow{{UserID: userID}}, nil
+}
+
+func (store *fakeReadStore) Ping(ctx context.Context) error {
+	return store.pingErr
+}
+
+func TestReplicaStoreRouting(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ctx         func() context.Context
+		unhealthy   bool
+		replicaErr  error
+		wantReplica int
+		wantPrimary int
+		wantErr     error
+		wantHealthy bool
+	}{
+		{
+			name:        "Replica",
+			ctx:         context.Background,
+			wantReplica: 1,
+			wantHealthy: true,
+		},
+		{
+			name: "WithPrimary",
+			ctx: func() context.Context {
+				return WithPrimary(context.Background())
+			},
+			wantPrimary: 1,
+			wantHealthy: true,
+		},
+		{
+			// gin.Context を渡した場合も、リクエストの context の指定に従う。
+			name: "WithPrimaryInGinContext",
+			ctx: func() context.Context {
+				c, _ := gin.CreateTestContext(httptest.NewRecorder())
+				c.Request = httptest.NewRequest("GET", "/expenses", nil)
+				c.Request = c.Request.WithContext(WithPrimary(c.Request.Context()))
+				return c
+			},
+			wantPrimary: 1,
+			wantHealthy: true,
+		},
+		{
+			name:        "Unhealthy",
+			ctx:         context.Background,
+			unhealthy:   true,
+			wantPrimary: 1,
+		},
+		{
+			name:        "FallbackOnReplicaError",
+			ctx:         context.Background,
+			replicaErr:  sql.ErrConnDone,
+			wantReplica: 1,
+			wantPrimary: 1,
+		},
+		{
+			// 該当する行がないことはレプリカの異常ではない。
+			name:        "NoRows",
+			ctx:         context.Background,
+			replicaErr:  sql.ErrNoRows,
+			wantReplica: 1,
+			wantErr:     sql.ErrNoRows,
+			wantHealthy: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			primary := &fakeReadStore{}
+			replica := &fakeReadStore{err: tc.replicaErr}
+			store := NewReplicaStore(primary, replica)
+			if tc.unhealthy {
+				store.setHealthy(false)
+			}
+
+			// Act
+			rows, err := store.ListExpenses(tc.ctx(), 1)
+
+			// Assert
+			if tc.wantErr != nil {
+				require.True(t, errors.Is(err, tc.wantErr))
+			} else {
+				require.NoError(t, err)
+				require.Len(t, rows, 1)
+			}
+			require.Equal(t, tc.wantReplica, replica.calls)
+			require.Equal(t, tc.wantPrimary, primary.calls)
+			require.Equal(t, tc.wantHealthy, store.ReplicaHealthy())
+		})
+	}
+}
+
+func TestReplicaStoreCheckReplica(t *testing.T) {
+	// Arrange
+	replica := &fakeReadStore{pingErr: sql.ErrConnDone}
+	store := NewReplicaStore(&fakeReadStore{}, replica)
+
+	// Act & Assert
+	require.Error(t, store.CheckReplica(context.Background()))
+	require.False(t, store.ReplicaHealthy())
+
+	// 接続できるようになったら、レプリカに戻す。
+	replica.pingErr = nil
+	require.NoError(t, store.CheckReplica(context.Background()))
+	require.True(t, store.ReplicaHealthy())
+}
@@ -12,6 +12,18 @@ import (
 	"github.com/google/uuid"
 )
 
+const countActiveSessions = `-- name: CountActiveSessions :one
+SELECT COUNT(*) FROM sessions
+WHERE expires_at > CURRENT_TIMESTAMP
+`
+
+func (q *Queries) CountActiveSessions(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveSessions)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
 	id,
@@ -64,6 +76,50 @@ func (q *Queries) DeleteSession(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+UPDATE sessions
+SET expires_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+	AND expires_at > CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSessions, userID)
+	return err
+}
+
+const getAuthSession = `-- name: GetAuthSession :one
+SELECT sessions.id, sessions.user_id, sessions.user_agent, sessions.client_ip, sessions.created_at, sessions.expires_at, users.locale FROM sessions
+JOIN users ON users.id = sessions.user_id
+WHERE sessions.id = $1 LIMIT 1
+`
+
+type GetAuthSessionRow struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    int64     `json:"user_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Locale    string    `json:"locale"`
+}
+
+// 認証後のメッセージに使うため、ユーザーの言語設定と合わせて取得する。
+func (q *Queries) GetAuthSession(ctx context.Context, id uuid.UUID) (GetAuthSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, getAuthSession, id)
+	var i GetAuthSessionRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Locale,
+	)
+	return i, err
+}
+
 const getSession = `-- name: GetSession :one
 SELECT id, user_id, user_agent, client_ip, created_at, expires_at FROM sessions
 WHERE id = $1 LIMIT 1
@@ -83,6 +139,52 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	return i, err
 }
 
+const listUserSessions = `-- name: ListUserSessions :many
+SELECT id, user_id, user_agent, client_ip, created_at, expires_at FROM sessions
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListUserSessions(ctx context.Context, userID int64) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUserSessions = `-- name: PurgeUserSessions :exec
+DELETE FROM sessions
+WHERE user_id = $1
+`
+
+func (q *Queries) PurgeUserSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, purgeUserSessions, userID)
+	return err
+}
+
 const updateSession = `-- name: UpdateSession :exec
 UPDATE sessions
 SET expires_at = $1
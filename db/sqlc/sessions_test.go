@@ -128,3 +128,32 @@ func TestDeleteSession(t *testing.T) {
 	require.True(t, time.Now().After(ss.ExpiresAt))
 	require.Equal(t, s.CreatedAt, ss.CreatedAt)
 }
+
+func TestDeleteUserSessions(t *testing.T) {
+	// Arrange
+	session := createRandomSession(t)
+
+	// Act
+	err := testQueries.DeleteUserSessions(context.Background(), session.UserID)
+
+	// Assert
+	require.NoError(t, err)
+	got, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.False(t, got.ExpiresAt.After(time.Now()))
+}
+
+func TestCountActiveSessions(t *testing.T) {
+	// Arrange
+	before, err := testQueries.CountActiveSessions(context.Background())
+	require.NoError(t, err)
+
+	// Act
+	createRandomSession(t)
+	after, err := testQueries.CountActiveSessions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	// 他のテストで作成したセッションも数えるため、増えた件数のみ確かめる。
+	require.Equal(t, before+1, after)
+}
@@ -0,0 +1,350 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 全てのクエリと、トランザクションを伴う処理を提供するインターフェース。
+type Store interface {
+	Querier
+	// ユーザーの個人データを削除・匿名化する。
+	DeleteUserTx(ctx context.Context, userID int64) error
+	// メールアドレス変更の確認トークンを使用済みにし、同じトランザクションでメールアドレスを変更する。
+	ConfirmEmailChangeTx(ctx context.Context, tokenHash string) (User, error)
+	// 支出を作成し、同じトランザクションで監査ログに記録する。
+	CreateExpenseTx(ctx context.Context, arg CreateExpenseParams, actor AuditActor) (Expense, error)
+	// レシートを明細と共に作成し、同じトランザクションで監査ログに記録する。
+	CreateReceiptTx(ctx context.Context, arg CreateReceiptTxParams, actor AuditActor) (CreateReceiptTxResult, error)
+	// カテゴリーを作成し、同じトランザクションで監査ログに記録する。
+	CreateCategoryTx(ctx context.Context, name string, actor AuditActor) (Category, error)
+	// カテゴリーを更新し、同じトランザクションで監査ログに記録する。
+	UpdateCategoryTx(ctx context.Context, arg UpdateCategoryParams, actor AuditActor) (Category, error)
+	// カテゴリーをゴミ箱に移し、同じトランザクションで監査ログに記録する。
+	DeleteCategoryTx(ctx context.Context, arg DeleteCategoryParams, actor AuditActor) (Category, error)
+	// 支出を更新し、同じトランザクションで監査ログに記録する。
+	UpdateExpenseTx(ctx context.Context, arg UpdateExpenseTxParams, actor AuditActor) (Expense, error)
+	// 支出をゴミ箱に移し、同じトランザクションで監査ログに記録する。
+	DeleteExpenseTx(ctx context.Context, arg SoftDeleteExpenseParams, actor AuditActor) (Expense, error)
+	// ゴミ箱から支出を復元し、同じトランザクションで監査ログに記録する。
+	RestoreExpenseTx(ctx context.Context, arg RestoreExpenseParams, actor AuditActor) (Expense, error)
+	// レシートをゴミ箱に移し、同じトランザクションで監査ログに記録する。
+	DeleteReceiptTx(ctx context.Context, arg SoftDeleteFoodReceiptParams, actor AuditActor) (FoodReceipt, error)
+	// ゴミ箱からレシートを復元し、同じトランザクションで監査ログに記録する。
+	RestoreReceiptTx(ctx context.Context, arg RestoreFoodReceiptParams, actor AuditActor) (FoodReceipt, error)
+	// ゴミ箱からカテゴリーを復元し、同じトランザクションで監査ログに記録する。
+	RestoreCategoryTx(ctx context.Context, id int64, actor AuditActor) (Category, error)
+	// 送金を記録して送金元と送金先の残高を変更し、同じトランザクションで監査ログに記録する。
+	CreateTransferTx(ctx context.Context, arg CreateTransferParams, actor AuditActor) (Transfer, error)
+	// ユーザーの残高を変更し、同じトランザクションで監査ログに記録する。変更後の残高を返す。
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams, actor AuditActor) (int64, error)
+	// before より前にゴミ箱に移された行を、１つのトランザクションで完全に削除する。
+	PurgeTrashTx(ctx context.Context, before time.Time) (PurgeTrashTxResult, error)
+	// DBに接続できるか確かめる。
+	Ping(ctx context.Context) error
+	// 適用済みのマイグレーションのバージョンと、適用が途中で失敗した状態かを返す。
+	// マイグレーションを一度も適用していない場合は 0 を返す。
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// DBを使って Store を実装した構造体。
+type SQLStore struct {
+	*Queries
+	db *sql.DB
+}
+
+// Store を作成する。
+// 制約違反は *pq.Error ではなく、ErrUniqueViolation などの *ConstraintError として返す。
+func NewStore(db *sql.DB) Store {
+	return &pqErrorStore{
+		next: &SQLStore{
+			Queries: New(db),
+			db:      db,
+		},
+	}
+}
+
+// DBに接続できるか確かめる。
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// golang-migrate が管理する schema_migrations テーブルから、バージョンを取得する。
+// schema_migrations はマイグレーションのSQLに含まれないため、sqlcのクエリとしては定義できない。
+func (store *SQLStore) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// トランザクション内で関数を実行する。
+// 関数がエラーを返した場合はロールバックする。
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ユーザーの個人データを１つのトランザクションで削除・匿名化する。
+//
+// 支出やレシート、セッションなど本人しか参照しないデータは削除する。
+// 送金は相手側の履歴として残す必要があるため、ユーザーの行は削除せずに匿名化する。
+func (store *SQLStore) DeleteUserTx(ctx context.Context, userID int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return DeleteUserData(ctx, q, userID)
+	})
+}
+
+func (store *SQLStore) ConfirmEmailChangeTx(ctx context.Context, tokenHash string) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = ConfirmEmailChange(ctx, q, tokenHash)
+		return err
+	})
+	return user, err
+}
+
+func (store *SQLStore) CreateExpenseTx(ctx context.Context, arg CreateExpenseParams, actor AuditActor) (Expense, error) {
+	var expense Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = CreateExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *SQLStore) CreateReceiptTx(ctx context.Context, arg CreateReceiptTxParams, actor AuditActor) (CreateReceiptTxResult, error) {
+	var result CreateReceiptTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = CreateReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return result, err
+}
+
+func (store *SQLStore) CreateCategoryTx(ctx context.Context, name string, actor AuditActor) (Category, error) {
+	var category Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = CreateCategoryWithAudit(ctx, q, name, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *SQLStore) UpdateCategoryTx(ctx context.Context, arg UpdateCategoryParams, actor AuditActor) (Category, error) {
+	var category Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = UpdateCategoryWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *SQLStore) DeleteCategoryTx(ctx context.Context, arg DeleteCategoryParams, actor AuditActor) (Category, error) {
+	var category Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = DeleteCategoryWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *SQLStore) UpdateExpenseTx(ctx context.Context, arg UpdateExpenseTxParams, actor AuditActor) (Expense, error) {
+	var expense Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = UpdateExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *SQLStore) DeleteExpenseTx(ctx context.Context, arg SoftDeleteExpenseParams, actor AuditActor) (Expense, error) {
+	var expense Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = DeleteExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *SQLStore) RestoreExpenseTx(ctx context.Context, arg RestoreExpenseParams, actor AuditActor) (Expense, error) {
+	var expense Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = RestoreExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *SQLStore) DeleteReceiptTx(ctx context.Context, arg SoftDeleteFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	var receipt FoodReceipt
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		receipt, err = DeleteReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *SQLStore) RestoreReceiptTx(ctx context.Context, arg RestoreFoodReceiptParams, actor AuditActor) (FoodReceipt, error) {
+	var receipt FoodReceipt
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		receipt, err = RestoreReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *SQLStore) RestoreCategoryTx(ctx context.Context, id int64, actor AuditActor) (Category, error) {
+	var category Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = RestoreCategoryWithAudit(ctx, q, id, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *SQLStore) CreateTransferTx(ctx context.Context, arg CreateTransferParams, actor AuditActor) (Transfer, error) {
+	var transfer Transfer
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		transfer, err = CreateTransferWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return transfer, err
+}
+
+func (store *SQLStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams, actor AuditActor) (int64, error) {
+	var balance int64
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		balance, err = AdjustBalanceWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return balance, err
+}
+
+func (store *SQLStore) PurgeTrashTx(ctx context.Context, before time.Time) (PurgeTrashTxResult, error) {
+	var result PurgeTrashTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = PurgeTrash(ctx, q, before)
+		return err
+	})
+	return result, err
+}
+
+// アカウントの削除時に監査ログの before に記録する、削除した件数。
+// メールアドレスなどの個人情報を監査ログに残さないよう、ユーザーの値そのものは記録しない。
+type deletedUserAuditRecord struct {
+	Expenses     int64 `json:"expenses"`
+	FoodReceipts int64 `json:"food_receipts"`
+	// 変更内容とアクセス元を消した、ユーザー自身が操作した監査ログの件数。
+	RedactedAuditEvents int64 `json:"redacted_audit_events"`
+}
+
+// DeleteUserTx で実行する削除・匿名化の手順。
+// Store の実装ごとに手順が食い違わないよう、トランザクション内の Querier を受け取って実行する。
+//
+// 削除を予約したユーザーではなく、SystemAuditActor の操作として監査ログに記録する。
+// 削除の予約から猶予期間が過ぎた後に、バックグラウンドの処理が実行するため。
+func DeleteUserData(ctx context.Context, q Querier, userID int64) error {
+	// 支出を削除する前に、支出に紐づくレシートを取得しておく。
+	receiptIDs, err := q.ListUserFoodReceiptIDs(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to ListUserFoodReceiptIDs: %w", err)
+	}
+
+	expenses, err := q.DeleteUserExpenses(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to DeleteUserExpenses: %w", err)
+	}
+	if err := q.DeleteFoodReceiptContents(ctx, receiptIDs); err != nil {
+		return fmt.Errorf("failed to DeleteFoodReceiptContents: %w", err)
+	}
+	if err := q.DeleteFoodReceipts(ctx, receiptIDs); err != nil {
+		return fmt.Errorf("failed to DeleteFoodReceipts: %w", err)
+	}
+
+	if err := q.PurgeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to PurgeUserSessions: %w", err)
+	}
+	if err := q.DeleteUserTwoFactorChallenges(ctx, userID); err != nil {
+		return fmt.Errorf("failed to DeleteUserTwoFactorChallenges: %w", err)
+	}
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to DeleteRecoveryCodes: %w", err)
+	}
+	if err := q.DeleteUserAPIKeys(ctx, userID); err != nil {
+		return fmt.Errorf("failed to DeleteUserAPIKeys: %w", err)
+	}
+	if err := q.DeleteUserEmailChangeTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to DeleteUserEmailChangeTokens: %w", err)
+	}
+	if err := q.DeleteUserIdempotencyKeys(ctx, userID); err != nil {
+		return fmt.Errorf("failed to DeleteUserIdempotencyKeys: %w", err)
+	}
+
+	// 監査ログの行は残すが、支出の金額やコメント、アクセス元のIPアドレスは消す。
+	redacted, err := q.RedactUserAuditEvents(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to RedactUserAuditEvents: %w", err)
+	}
+
+	if _, err := q.AnonymizeUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to AnonymizeUser: %w", err)
+	}
+
+	deleted := deletedUserAuditRecord{
+		Expenses:            expenses,
+		FoodReceipts:        int64(len(receiptIDs)),
+		RedactedAuditEvents: redacted,
+	}
+	return RecordAuditEvent(ctx, q, SystemAuditActor, AuditActionDelete, AuditEntityUser, userID, deleted, nil)
+}
+
+// ConfirmEmailChangeTx で実行する手順。
+// メールアドレスの変更に失敗した場合に、トークンが使用済みのまま残らないよう同じトランザクションで実行する。
+func ConfirmEmailChange(ctx context.Context, q Querier, tokenHash string) (User, error) {
+	token, err := q.UseEmailChangeToken(ctx, tokenHash)
+	if err != nil {
+		return User{}, err
+	}
+	return q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+		Email: token.NewEmail,
+		ID:    token.UserID,
+	})
+}
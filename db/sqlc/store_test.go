@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/db/migration"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUserTx(t *testing.T) {
+	// Arrange
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	category := createRandomCategory(t)
+
+	// 自分だけが使っているレシートと、他のユーザーとも共有しているレシート。
+	ownReceipt := createRandomFoodReceipt(t)
+	createRandomFoodReceiptContent(t, ownReceipt, createRandomFoodContent(t))
+	sharedReceipt := createRandomFoodReceipt(t)
+	createRandomFoodReceiptContent(t, sharedReceipt, createRandomFoodContent(t))
+	for _, arg := range []CreateExpenseParams{
+		{UserID: user.ID, CategoryID: category.ID, Amount: util.RandomExpense(), FoodReceiptID: sql.NullInt64{Int64: ownReceipt.ID, Valid: true}},
+		{UserID: user.ID, CategoryID: category.ID, Amount: util.RandomExpense(), FoodReceiptID: sql.NullInt64{Int64: sharedReceipt.ID, Valid: true}},
+		{UserID: other.ID, CategoryID: category.ID, Amount: util.RandomExpense(), FoodReceiptID: sql.NullInt64{Int64: sharedReceipt.ID, Valid: true}},
+	} {
+		_, err := testQueries.CreateExpense(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	_, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserAgent: "MacOS",
+		ClientIp:  util.RandomIPAddress(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	createRandomAPIKey(t, user)
+	transfer := createRandomTransfer(t, user, other)
+
+	// Act
+	err = store.DeleteUserTx(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+
+	deleted, err := testQueries.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, deleted.AnonymizedAt.Valid)
+	require.True(t, deleted.DisabledAt.Valid)
+	require.NotEqual(t, user.Email, deleted.Email)
+	require.NotEqual(t, user.Name, deleted.Name)
+	require.Empty(t, deleted.Password)
+
+	expenses, err := testQueries.ListExpenses(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, expenses)
+
+	// 自分だけのレシートは削除し、共有しているレシートは残す。
+	_, err = testQueries.GetFoodReceipt(context.Background(), ownReceipt.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetFoodReceipt(context.Background(), sharedReceipt.ID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	// 送金は相手の履歴でもあるため、残す。
+	transfers, err := testQueries.ListUserTransfers(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.Contains(t, transfers, transfer)
+}
+
+func TestMigrationVersion(t *testing.T) {
+	// Arrange
+	store := NewStore(testDB)
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, store.Ping(context.Background()))
+	version, dirty, err := store.MigrationVersion(context.Background())
+
+	// Assert
+	// テスト用のDBは最新までマイグレーションされている前提。
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, latest, version)
+}
@@ -0,0 +1,75 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: transfers.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createTransfer = `-- name: CreateTransfer :one
+INSERT INTO transfers (
+	from_user_id,
+	to_user_id,
+	amount
+) VALUES (
+	$1, $2, $3
+) RETURNING id, from_user_id, to_user_id, amount, created_at
+`
+
+type CreateTransferParams struct {
+	FromUserID int64 `json:"from_user_id"`
+	ToUserID   int64 `json:"to_user_id"`
+	Amount     int64 `json:"amount"`
+}
+
+func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromUserID, arg.ToUserID, arg.Amount)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.Amount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT id, from_user_id, to_user_id, amount, created_at FROM transfers
+WHERE from_user_id = $1
+	OR to_user_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListUserTransfers(ctx context.Context, fromUserID int64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromUserID,
+			&i.ToUserID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTransfer(t *testing.T, from, to User) Transfer {
+	// Arrange
+	arg := CreateTransferParams{
+		FromUserID: from.ID,
+		ToUserID:   to.ID,
+		Amount:     util.RandomExpense(),
+	}
+
+	// Act
+	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEmpty(t, transfer)
+
+	require.NotZero(t, transfer.ID)
+	require.Equal(t, arg.FromUserID, transfer.FromUserID)
+	require.Equal(t, arg.ToUserID, transfer.ToUserID)
+	require.Equal(t, arg.Amount, transfer.Amount)
+	require.NotZero(t, transfer.CreatedAt)
+
+	return transfer
+}
+
+func TestCreateTransfer(t *testing.T) {
+	createRandomTransfer(t, createRandomUser(t), createRandomUser(t))
+}
+
+// 送金元・送金先のどちらであっても取得できること。
+func TestListUserTransfers(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	sent := createRandomTransfer(t, user, other)
+	received := createRandomTransfer(t, other, user)
+	createRandomTransfer(t, other, createRandomUser(t))
+
+	// Act
+	transfers, err := testQueries.ListUserTransfers(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, sent.ID, transfers[0].ID)
+	require.Equal(t, received.ID, transfers[1].ID)
+}
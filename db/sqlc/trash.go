@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PurgeTrashTx で完全に削除した件数。
+type PurgeTrashTxResult struct {
+	Expenses     int64 `json:"expenses"`
+	FoodReceipts int64 `json:"food_receipts"`
+	Categories   int64 `json:"categories"`
+}
+
+// PurgeTrashTx で実行する完全削除の手順。
+// Store の実装ごとに手順が食い違わないよう、トランザクション内の Querier を受け取って実行する。
+//
+// レシートとカテゴリーは支出から参照されていると削除できないため、先に支出を削除する。
+// ゴミ箱にない支出から参照されているものは、参照がなくなるまで残す。
+//
+// 削除した行ごとに、SystemAuditActor の操作として監査ログに記録する。
+// before と after は共に null として記録し、削除した値を監査ログに残さない。
+// ゴミ箱に移す前の値は、削除時の監査ログに記録済みのため。
+func PurgeTrash(ctx context.Context, q Querier, before time.Time) (PurgeTrashTxResult, error) {
+	var result PurgeTrashTxResult
+
+	expenseIDs, err := q.PurgeDeletedExpenses(ctx, before)
+	if err != nil {
+		return result, fmt.Errorf("failed to PurgeDeletedExpenses: %w", err)
+	}
+	if err := recordPurgeEvents(ctx, q, AuditEntityExpense, expenseIDs); err != nil {
+		return result, err
+	}
+	result.Expenses = int64(len(expenseIDs))
+
+	if err := q.PurgeDeletedFoodReceiptContents(ctx, before); err != nil {
+		return result, fmt.Errorf("failed to PurgeDeletedFoodReceiptContents: %w", err)
+	}
+	receiptIDs, err := q.PurgeDeletedFoodReceipts(ctx, before)
+	if err != nil {
+		return result, fmt.Errorf("failed to PurgeDeletedFoodReceipts: %w", err)
+	}
+	if err := recordPurgeEvents(ctx, q, AuditEntityReceipt, receiptIDs); err != nil {
+		return result, err
+	}
+	result.FoodReceipts = int64(len(receiptIDs))
+
+	categoryIDs, err := q.PurgeDeletedCategories(ctx, before)
+	if err != nil {
+		return result, fmt.Errorf("failed to PurgeDeletedCategories: %w", err)
+	}
+	if err := recordPurgeEvents(ctx, q, AuditEntityCategory, categoryIDs); err != nil {
+		return result, err
+	}
+	result.Categories = int64(len(categoryIDs))
+	return result, nil
+}
+
+func recordPurgeEvents(ctx context.Context, q Querier, entityType string, ids []int64) error {
+	for _, id := range ids {
+		if err := RecordAuditEvent(ctx, q, SystemAuditActor, AuditActionPurge, entityType, id, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: two_factor.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+	user_id,
+	code_hash
+) VALUES (
+	$1, $2
+) RETURNING id, user_id, code_hash, used_at, created_at
+`
+
+type CreateRecoveryCodeParams struct {
+	UserID   int64  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, createRecoveryCode, arg.UserID, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTwoFactorChallenge = `-- name: CreateTwoFactorChallenge :one
+INSERT INTO two_factor_challenges (
+	id,
+	user_id,
+	user_agent,
+	client_ip,
+	expires_at
+) VALUES (
+	$1, $2, $3, $4, $5
+) RETURNING id, user_id, user_agent, client_ip, created_at, expires_at, attempts
+`
+
+type CreateTwoFactorChallengeParams struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    int64     `json:"user_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) (TwoFactorChallenge, error) {
+	row := q.db.QueryRowContext(ctx, createTwoFactorChallenge,
+		arg.ID,
+		arg.UserID,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.ExpiresAt,
+	)
+	var i TwoFactorChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
+const deleteTwoFactorChallenge = `-- name: DeleteTwoFactorChallenge :exec
+DELETE FROM two_factor_challenges
+WHERE id = $1
+`
+
+func (q *Queries) DeleteTwoFactorChallenge(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTwoFactorChallenge, id)
+	return err
+}
+
+const deleteUserTwoFactorChallenges = `-- name: DeleteUserTwoFactorChallenges :exec
+DELETE FROM two_factor_challenges
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserTwoFactorChallenges(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTwoFactorChallenges, userID)
+	return err
+}
+
+const getTwoFactorChallenge = `-- name: GetTwoFactorChallenge :one
+SELECT id, user_id, user_agent, client_ip, created_at, expires_at, attempts FROM two_factor_challenges
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTwoFactorChallenge(ctx context.Context, id uuid.UUID) (TwoFactorChallenge, error) {
+	row := q.db.QueryRowContext(ctx, getTwoFactorChallenge, id)
+	var i TwoFactorChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+	AND code_hash = $2
+	AND used_at IS NULL
+RETURNING id, user_id, code_hash, used_at, created_at
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   int64  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, useRecoveryCode, arg.UserID, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useTwoFactorChallengeAttempt = `-- name: UseTwoFactorChallengeAttempt :one
+UPDATE two_factor_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+	AND attempts < $2::integer
+RETURNING id, user_id, user_agent, client_ip, created_at, expires_at, attempts
+`
+
+type UseTwoFactorChallengeAttemptParams struct {
+	ID          uuid.UUID `json:"id"`
+	MaxAttempts int32     `json:"max_attempts"`
+}
+
+// コードを確かめる前に、試行回数を1回分消費する。
+// 並行したリクエストでも上限を超えないよう、上限に達したチャレンジは返さない。
+func (q *Queries) UseTwoFactorChallengeAttempt(ctx context.Context, arg UseTwoFactorChallengeAttemptParams) (TwoFactorChallenge, error) {
+	row := q.db.QueryRowContext(ctx, useTwoFactorChallengeAttempt, arg.ID, arg.MaxAttempts)
+	var i TwoFactorChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Attempts,
+	)
+	return i, err
+}
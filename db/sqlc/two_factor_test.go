@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUserTOTPSecret(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	arg := UpdateUserTOTPSecretParams{
+		TotpSecret: sql.NullString{String: util.RandomString(32), Valid: true},
+		ID:         user.ID,
+	}
+
+	// Act
+	updated, err := testQueries.UpdateUserTOTPSecret(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, arg.TotpSecret, updated.TotpSecret)
+	// 秘密鍵を再発行した時は、有効化し直すまで無効とする。
+	require.False(t, updated.TotpEnabled)
+
+	enabled, err := testQueries.EnableUserTOTP(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, enabled.TotpEnabled)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	arg := CreateRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: util.HashToken(util.RandomString(10)),
+	}
+	code, err := testQueries.CreateRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, code.UsedAt.Valid)
+
+	// Act
+	used, err := testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: arg.CodeHash,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, code.ID, used.ID)
+	require.True(t, used.UsedAt.Valid)
+
+	// 同じリカバリーコードは二度使えない。
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: arg.CodeHash,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	arg := CreateTwoFactorChallengeParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserAgent: "MacOS",
+		ClientIp:  util.RandomIPAddress(),
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+
+	// Act
+	challenge, err := testQueries.CreateTwoFactorChallenge(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, challenge.ID)
+	require.Equal(t, arg.UserID, challenge.UserID)
+
+	got, err := testQueries.GetTwoFactorChallenge(context.Background(), arg.ID)
+	require.NoError(t, err)
+	require.Equal(t, challenge.ID, got.ID)
+	require.WithinDuration(t, arg.ExpiresAt, got.ExpiresAt, time.Second)
+
+	err = testQueries.DeleteTwoFactorChallenge(context.Background(), arg.ID)
+	require.NoError(t, err)
+	_, err = testQueries.GetTwoFactorChallenge(context.Background(), arg.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -7,8 +7,76 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addUserBalance = `-- name: AddUserBalance :one
+UPDATE users
+SET balance = balance + $1::bigint
+WHERE id = $2::bigint
+	AND anonymized_at IS NULL
+RETURNING balance
+`
+
+type AddUserBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+// 退会したユーザーの残高は変更せず、sql.ErrNoRows を返す。
+func (q *Queries) AddUserBalance(ctx context.Context, arg AddUserBalanceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addUserBalance, arg.Amount, arg.ID)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE users
+SET
+	name = 'deleted user',
+	password = '',
+	email = 'deleted-' || id || '@invalid',
+	age = 0,
+	balance = 0,
+	totp_secret = NULL,
+	totp_enabled = false,
+	totp_last_counter = NULL,
+	disabled_at = CURRENT_TIMESTAMP,
+	deletion_scheduled_at = NULL,
+	anonymized_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+// 送金履歴の相手側から参照されるため、行は削除せずに個人を特定できる情報を消す。
+func (q *Queries) AnonymizeUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, anonymizeUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   name,
@@ -18,7 +86,7 @@ INSERT INTO users (
   balance
 ) VALUES (
   $1, $2, $3, $4, $5
-) RETURNING id, name, password, email, age, balance, password_changed_at, created_at
+) RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
 `
 
 type CreateUserParams struct {
@@ -47,12 +115,55 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Balance,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled = true
+WHERE id = $1
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUserTOTP, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, password, email, age, balance, password_changed_at, created_at FROM users
+SELECT id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -68,6 +179,387 @@ func (q *Queries) GetUser(ctx context.Context, email string) (User, error) {
 		&i.Balance,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter FROM users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Password,
+			&i.Email,
+			&i.Age,
+			&i.Balance,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.Role,
+			&i.DisabledAt,
+			&i.PreferredCurrency,
+			&i.Locale,
+			&i.Timezone,
+			&i.DeletionScheduledAt,
+			&i.AnonymizedAt,
+			&i.TotpLastCounter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter FROM users
+WHERE deletion_scheduled_at <= $1::timestamptz
+	AND anonymized_at IS NULL
+ORDER BY deletion_scheduled_at
+LIMIT $2
+`
+
+type ListUsersDueForDeletionParams struct {
+	Now      time.Time `json:"now"`
+	MaxUsers int32     `json:"max_users"`
+}
+
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersDueForDeletion, arg.Now, arg.MaxUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Password,
+			&i.Email,
+			&i.Age,
+			&i.Balance,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.Role,
+			&i.DisabledAt,
+			&i.PreferredCurrency,
+			&i.Locale,
+			&i.Timezone,
+			&i.DeletionScheduledAt,
+			&i.AnonymizedAt,
+			&i.TotpLastCounter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserDeletionScheduledAt = `-- name: UpdateUserDeletionScheduledAt :one
+UPDATE users
+SET deletion_scheduled_at = $1
+WHERE id = $2
+	AND anonymized_at IS NULL
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+type UpdateUserDeletionScheduledAtParams struct {
+	DeletionScheduledAt sql.NullTime `json:"deletion_scheduled_at"`
+	ID                  int64        `json:"id"`
+}
+
+func (q *Queries) UpdateUserDeletionScheduledAt(ctx context.Context, arg UpdateUserDeletionScheduledAtParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserDeletionScheduledAt, arg.DeletionScheduledAt, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserDisabledAt = `-- name: UpdateUserDisabledAt :one
+UPDATE users
+SET disabled_at = $1
+WHERE id = $2
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+type UpdateUserDisabledAtParams struct {
+	DisabledAt sql.NullTime `json:"disabled_at"`
+	ID         int64        `json:"id"`
+}
+
+func (q *Queries) UpdateUserDisabledAt(ctx context.Context, arg UpdateUserDisabledAtParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserDisabledAt, arg.DisabledAt, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1
+WHERE id = $2
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+type UpdateUserEmailParams struct {
+	Email string `json:"email"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET
+	name = $1,
+	age = $2,
+	preferred_currency = $3,
+	locale = $4,
+	timezone = $5
+WHERE id = $6
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+type UpdateUserProfileParams struct {
+	Name              string `json:"name"`
+	Age               int32  `json:"age"`
+	PreferredCurrency string `json:"preferred_currency"`
+	Locale            string `json:"locale"`
+	Timezone          string `json:"timezone"`
+	ID                int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Name,
+		arg.Age,
+		arg.PreferredCurrency,
+		arg.Locale,
+		arg.Timezone,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserTOTPLastCounter = `-- name: UpdateUserTOTPLastCounter :execrows
+UPDATE users
+SET totp_last_counter = $1::bigint
+WHERE id = $2
+	AND (totp_last_counter IS NULL OR totp_last_counter < $1::bigint)
+`
+
+type UpdateUserTOTPLastCounterParams struct {
+	Counter int64 `json:"counter"`
+	ID      int64 `json:"id"`
+}
+
+// 前回受け付けたコードより後のステップの場合のみ更新する。
+// 0行の場合は、受け付け済みのコードの再利用とみなす。
+func (q *Queries) UpdateUserTOTPLastCounter(ctx context.Context, arg UpdateUserTOTPLastCounterParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserTOTPLastCounter, arg.Counter, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserTOTPSecret = `-- name: UpdateUserTOTPSecret :one
+UPDATE users
+SET
+	totp_secret = $1,
+	totp_enabled = false
+WHERE id = $2
+RETURNING id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter
+`
+
+type UpdateUserTOTPSecretParams struct {
+	TotpSecret sql.NullString `json:"totp_secret"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserTOTPSecret, arg.TotpSecret, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
 	)
 	return i, err
 }
@@ -112,3 +112,115 @@ func TestGetUserWithNotExist(t *testing.T) {
 
 	require.Equal(t, err, sql.ErrNoRows)
 }
+
+func TestGetUserByID(t *testing.T) {
+	// Arrange
+	user1 := createRandomUser(t)
+
+	// Act
+	user2, err := testQueries.GetUserByID(context.Background(), user1.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+	require.Equal(t, user1.Email, user2.Email)
+	require.False(t, user2.TotpEnabled)
+}
+
+func TestCreateUserHasDefaultRole(t *testing.T) {
+	// Act
+	user := createRandomUser(t)
+
+	// Assert
+	require.Equal(t, "user", user.Role)
+	require.False(t, user.DisabledAt.Valid)
+}
+
+func TestListUsers(t *testing.T) {
+	// Arrange
+	for i := 0; i < 3; i++ {
+		createRandomUser(t)
+	}
+	arg := ListUsersParams{
+		Limit:  2,
+		Offset: 1,
+	}
+
+	// Act
+	users, err := testQueries.ListUsers(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Less(t, users[0].ID, users[1].ID)
+}
+
+func TestUpdateUserDisabledAt(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	arg := UpdateUserDisabledAtParams{
+		DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:         user.ID,
+	}
+
+	// Act
+	disabled, err := testQueries.UpdateUserDisabledAt(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, disabled.DisabledAt.Valid)
+	require.WithinDuration(t, arg.DisabledAt.Time, disabled.DisabledAt.Time, time.Second)
+
+	// 有効に戻す。
+	enabled, err := testQueries.UpdateUserDisabledAt(context.Background(), UpdateUserDisabledAtParams{ID: user.ID})
+	require.NoError(t, err)
+	require.False(t, enabled.DisabledAt.Valid)
+}
+
+func TestUpdateUserProfile(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	arg := UpdateUserProfileParams{
+		Name:              util.RandomUserName(),
+		Age:               util.RandomAge(),
+		PreferredCurrency: "USD",
+		Locale:            "en",
+		Timezone:          "America/New_York",
+		ID:                user.ID,
+	}
+
+	// Act
+	updated, err := testQueries.UpdateUserProfile(context.Background(), arg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, updated.Name)
+	require.Equal(t, arg.Age, updated.Age)
+	require.Equal(t, arg.PreferredCurrency, updated.PreferredCurrency)
+	require.Equal(t, arg.Locale, updated.Locale)
+	require.Equal(t, arg.Timezone, updated.Timezone)
+	require.Equal(t, user.Email, updated.Email)
+}
+
+func TestUpdateUserEmail(t *testing.T) {
+	// Arrange
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+
+	// Act
+	updated, err := testQueries.UpdateUserEmail(context.Background(), UpdateUserEmailParams{
+		Email: util.RandomEmail(),
+		ID:    user.ID,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEqual(t, user.Email, updated.Email)
+
+	// 他のユーザーのメールアドレスには変更できない。
+	_, err = testQueries.UpdateUserEmail(context.Background(), UpdateUserEmailParams{
+		Email: other.Email,
+		ID:    user.ID,
+	})
+	require.Error(t, err)
+}
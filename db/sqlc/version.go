@@ -0,0 +1,19 @@
+package db
+
+import "errors"
+
+// 楽観的排他制御で、条件に指定した version が現在の行と一致しない場合のエラー。
+var ErrVersionMismatch = errors.New("version does not match")
+
+// version を条件にしない場合に指定する値。
+// API では If-Match: * のリクエストのみが使う。If-Match のないリクエストは更新せずに428を返す。
+const AnyVersion int64 = 0
+
+// expected が AnyVersion でなく、現在の version と異なる場合は ErrVersionMismatch を返す。
+// 一致した場合は、UPDATE の条件に使う version を返す。
+func CheckVersion(current, expected int64) (int64, error) {
+	if expected != AnyVersion && expected != current {
+		return current, ErrVersionMismatch
+	}
+	return current, nil
+}
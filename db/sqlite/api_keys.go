@@ -0,0 +1,138 @@
+package sqlitedb
+
+import (
+	"context"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const apiKeyColumns = `id, user_id, name, key_prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row scanner) (db.ApiKey, error) {
+	var i db.ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.KeyPrefix,
+		&i.KeyHash,
+		(*stringArray)(&i.Scopes),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, convertError(err)
+}
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+	user_id,
+	name,
+	key_prefix,
+	key_hash,
+	scopes,
+	expires_at
+) VALUES (
+	?, ?, ?, ?, ?, ?
+) RETURNING ` + apiKeyColumns
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+	return scanAPIKey(q.db.QueryRowContext(ctx, createAPIKey,
+		arg.UserID,
+		arg.Name,
+		arg.KeyPrefix,
+		arg.KeyHash,
+		stringArray(arg.Scopes),
+		nullTimestamp(arg.ExpiresAt),
+	))
+}
+
+const deleteUserAPIKeys = `-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE user_id = ?`
+
+func (q *Queries) DeleteUserAPIKeys(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserAPIKeys, userID)
+	return convertError(err)
+}
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
+SELECT api_keys.id, api_keys.user_id, api_keys.name, api_keys.key_prefix, api_keys.key_hash, api_keys.scopes,
+	api_keys.expires_at, api_keys.last_used_at, api_keys.revoked_at, api_keys.created_at, users.locale
+FROM api_keys
+JOIN users ON users.id = api_keys.user_id
+WHERE api_keys.key_hash = ?
+	AND users.disabled_at IS NULL
+LIMIT 1`
+
+// 無効化されたユーザーのAPIキーは存在しないものとして扱う。
+// 認証後のメッセージに使うため、ユーザーの言語設定も合わせて取得する。
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (db.GetAPIKeyByHashRow, error) {
+	var i db.GetAPIKeyByHashRow
+	err := q.db.QueryRowContext(ctx, getAPIKeyByHash, keyHash).Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.KeyPrefix,
+		&i.KeyHash,
+		(*stringArray)(&i.Scopes),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.Locale,
+	)
+	return i, convertError(err)
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT ` + apiKeyColumns + ` FROM api_keys
+WHERE user_id = ?
+	AND revoked_at IS NULL
+ORDER BY id`
+
+func (q *Queries) ListAPIKeys(ctx context.Context, userID int64) ([]db.ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeys, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.ApiKey{}
+	for rows.Next() {
+		i, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = ` + currentTimestamp + `
+WHERE id = ?
+	AND user_id = ?
+	AND revoked_at IS NULL
+RETURNING ` + apiKeyColumns
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg db.RevokeAPIKeyParams) (db.ApiKey, error) {
+	return scanAPIKey(q.db.QueryRowContext(ctx, revokeAPIKey, arg.ID, arg.UserID))
+}
+
+const updateAPIKeyLastUsed = `-- name: UpdateAPIKeyLastUsed :exec
+UPDATE api_keys
+SET last_used_at = ` + currentTimestamp + `
+WHERE id = ?`
+
+func (q *Queries) UpdateAPIKeyLastUsed(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, updateAPIKeyLastUsed, id)
+	return convertError(err)
+}
@@ -0,0 +1,121 @@
+package sqlitedb
+
+import (
+	"context"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const auditEventColumns = `id, actor_id, session_id, client_ip, action, entity_type, entity_id, before, after, created_at, redacted_at`
+
+func scanAuditEvent(row scanner) (db.AuditEvent, error) {
+	var i db.AuditEvent
+	// TEXT の列は json.RawMessage に直接読み込めないため、[]byte を経由する。
+	var before, after []byte
+	err := row.Scan(
+		&i.ID,
+		&i.ActorID,
+		&i.SessionID,
+		&i.ClientIp,
+		&i.Action,
+		&i.EntityType,
+		&i.EntityID,
+		&before,
+		&after,
+		&i.CreatedAt,
+		&i.RedactedAt,
+	)
+	i.Before = before
+	i.After = after
+	return i, convertError(err)
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+	actor_id,
+	session_id,
+	client_ip,
+	action,
+	entity_type,
+	entity_id,
+	before,
+	after
+) VALUES (
+	?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING ` + auditEventColumns
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+	return scanAuditEvent(q.db.QueryRowContext(ctx, createAuditEvent,
+		arg.ActorID,
+		arg.SessionID,
+		arg.ClientIp,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		string(arg.Before),
+		string(arg.After),
+	))
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT ` + auditEventColumns + ` FROM audit_events
+ORDER BY id DESC
+LIMIT ?
+OFFSET ?`
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	return q.queryAuditEvents(ctx, listAuditEvents, arg.Limit, arg.Offset)
+}
+
+const listUserAuditEvents = `-- name: ListUserAuditEvents :many
+SELECT ` + auditEventColumns + ` FROM audit_events
+WHERE actor_id = ?
+ORDER BY id DESC
+LIMIT ?
+OFFSET ?`
+
+func (q *Queries) ListUserAuditEvents(ctx context.Context, arg db.ListUserAuditEventsParams) ([]db.AuditEvent, error) {
+	return q.queryAuditEvents(ctx, listUserAuditEvents, arg.ActorID, arg.Limit, arg.Offset)
+}
+
+func (q *Queries) queryAuditEvents(ctx context.Context, query string, args ...interface{}) ([]db.AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.AuditEvent{}
+	for rows.Next() {
+		i, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const redactUserAuditEvents = `-- name: RedactUserAuditEvents :execrows
+UPDATE audit_events
+SET
+	session_id = NULL,
+	client_ip = '',
+	before = 'null',
+	after = 'null',
+	redacted_at = ` + currentTimestamp + `
+WHERE actor_id = ?
+	AND redacted_at IS NULL`
+
+func (q *Queries) RedactUserAuditEvents(ctx context.Context, actorID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, redactUserAuditEvents, actorID)
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,149 @@
+package sqlitedb
+
+import (
+	"context"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const categoryColumns = `id, name, deleted_at, version`
+
+func scanCategory(row scanner) (db.Category, error) {
+	var i db.Category
+	err := row.Scan(&i.ID, &i.Name, &i.DeletedAt, &i.Version)
+	return i, convertError(err)
+}
+
+const createCategory = `-- name: CreateCategory :one
+INSERT INTO categories (
+	name
+) VALUES (
+	?
+) RETURNING ` + categoryColumns
+
+func (q *Queries) CreateCategory(ctx context.Context, name string) (db.Category, error) {
+	return scanCategory(q.db.QueryRowContext(ctx, createCategory, name))
+}
+
+// ゴミ箱に移す。完全に削除するのは PurgeDeletedCategories のみ。
+const deleteCategory = `-- name: DeleteCategory :one
+UPDATE categories
+SET
+	deleted_at = ` + currentTimestamp + `,
+	version = version + 1
+WHERE id = ?
+	AND version = ?
+	AND deleted_at IS NULL
+RETURNING ` + categoryColumns
+
+func (q *Queries) DeleteCategory(ctx context.Context, arg db.DeleteCategoryParams) (db.Category, error) {
+	return scanCategory(q.db.QueryRowContext(ctx, deleteCategory, arg.ID, arg.Version))
+}
+
+// 接続を１つに制限しており、トランザクションは直列に実行されるため FOR UPDATE は不要。
+const getCategoryForUpdate = `-- name: GetCategoryForUpdate :one
+SELECT ` + categoryColumns + ` FROM categories
+WHERE id = ?
+	AND deleted_at IS NULL
+LIMIT 1`
+
+func (q *Queries) GetCategoryForUpdate(ctx context.Context, id int64) (db.Category, error) {
+	return scanCategory(q.db.QueryRowContext(ctx, getCategoryForUpdate, id))
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT ` + categoryColumns + ` FROM categories
+WHERE deleted_at IS NULL
+ORDER BY id`
+
+func (q *Queries) ListCategories(ctx context.Context) ([]db.Category, error) {
+	return q.queryCategories(ctx, listCategories)
+}
+
+const listDeletedCategories = `-- name: ListDeletedCategories :many
+SELECT ` + categoryColumns + ` FROM categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC`
+
+func (q *Queries) ListDeletedCategories(ctx context.Context) ([]db.Category, error) {
+	return q.queryCategories(ctx, listDeletedCategories)
+}
+
+func (q *Queries) queryCategories(ctx context.Context, query string, args ...interface{}) ([]db.Category, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Category{}
+	for rows.Next() {
+		i, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCategory = `-- name: UpdateCategory :one
+UPDATE categories
+SET
+	name = ?,
+	version = version + 1
+WHERE id = ?
+	AND version = ?
+	AND deleted_at IS NULL
+RETURNING ` + categoryColumns
+
+func (q *Queries) UpdateCategory(ctx context.Context, arg db.UpdateCategoryParams) (db.Category, error) {
+	return scanCategory(q.db.QueryRowContext(ctx, updateCategory, arg.Name, arg.ID, arg.Version))
+}
+
+// ゴミ箱にある支出は数えない。
+const categoryHasExpenses = `-- name: CategoryHasExpenses :one
+SELECT EXISTS (
+	SELECT 1 FROM expenses
+	WHERE category_id = ?
+		AND deleted_at IS NULL
+)`
+
+func (q *Queries) CategoryHasExpenses(ctx context.Context, categoryID int64) (bool, error) {
+	var exists bool
+	err := q.db.QueryRowContext(ctx, categoryHasExpenses, categoryID).Scan(&exists)
+	return exists, convertError(err)
+}
+
+const restoreCategory = `-- name: RestoreCategory :one
+UPDATE categories
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE id = ?
+	AND deleted_at IS NOT NULL
+RETURNING ` + categoryColumns
+
+func (q *Queries) RestoreCategory(ctx context.Context, id int64) (db.Category, error) {
+	return scanCategory(q.db.QueryRowContext(ctx, restoreCategory, id))
+}
+
+// 支出から参照されているカテゴリーは残す。
+const purgeDeletedCategories = `-- name: PurgeDeletedCategories :many
+DELETE FROM categories
+WHERE categories.deleted_at < ?
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.category_id = categories.id
+	)
+RETURNING id`
+
+func (q *Queries) PurgeDeletedCategories(ctx context.Context, before time.Time) ([]int64, error) {
+	return q.queryIDs(ctx, purgeDeletedCategories, timestamp(before))
+}
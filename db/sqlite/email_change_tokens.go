@@ -0,0 +1,63 @@
+package sqlitedb
+
+import (
+	"context"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const emailChangeTokenColumns = `id, user_id, new_email, token_hash, expires_at, used_at, created_at`
+
+func scanEmailChangeToken(row scanner) (db.EmailChangeToken, error) {
+	var i db.EmailChangeToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.NewEmail,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, convertError(err)
+}
+
+const createEmailChangeToken = `-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+	user_id,
+	new_email,
+	token_hash,
+	expires_at
+) VALUES (
+	?, ?, ?, ?
+) RETURNING ` + emailChangeTokenColumns
+
+func (q *Queries) CreateEmailChangeToken(ctx context.Context, arg db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+	return scanEmailChangeToken(q.db.QueryRowContext(ctx, createEmailChangeToken,
+		arg.UserID,
+		arg.NewEmail,
+		arg.TokenHash,
+		timestamp(arg.ExpiresAt),
+	))
+}
+
+const deleteUserEmailChangeTokens = `-- name: DeleteUserEmailChangeTokens :exec
+DELETE FROM email_change_tokens
+WHERE user_id = ?`
+
+func (q *Queries) DeleteUserEmailChangeTokens(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserEmailChangeTokens, userID)
+	return convertError(err)
+}
+
+const useEmailChangeToken = `-- name: UseEmailChangeToken :one
+UPDATE email_change_tokens
+SET used_at = ` + currentTimestamp + `
+WHERE token_hash = ?
+	AND used_at IS NULL
+	AND expires_at > ` + currentTimestamp + `
+RETURNING ` + emailChangeTokenColumns
+
+func (q *Queries) UseEmailChangeToken(ctx context.Context, tokenHash string) (db.EmailChangeToken, error) {
+	return scanEmailChangeToken(q.db.QueryRowContext(ctx, useEmailChangeToken, tokenHash))
+}
@@ -0,0 +1,310 @@
+package sqlitedb
+
+import (
+	"context"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const expenseColumns = `id, user_id, category_id, amount, food_receipt_id, comment, created_at, deleted_at, version`
+
+func scanExpense(row scanner) (db.Expense, error) {
+	var i db.Expense
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Amount,
+		&i.FoodReceiptID,
+		&i.Comment,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, convertError(err)
+}
+
+const createExpense = `-- name: CreateExpense :one
+INSERT INTO expenses (
+	user_id,
+	category_id,
+	amount,
+	food_receipt_id,
+	comment
+) VALUES (
+	?, ?, ?, ?, ?
+) RETURNING ` + expenseColumns
+
+func (q *Queries) CreateExpense(ctx context.Context, arg db.CreateExpenseParams) (db.Expense, error) {
+	return scanExpense(q.db.QueryRowContext(ctx, createExpense,
+		arg.UserID,
+		arg.CategoryID,
+		arg.Amount,
+		arg.FoodReceiptID,
+		arg.Comment,
+	))
+}
+
+const deleteUserExpenses = `-- name: DeleteUserExpenses :execrows
+DELETE FROM expenses
+WHERE user_id = ?`
+
+func (q *Queries) DeleteUserExpenses(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserExpenses, userID)
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return result.RowsAffected()
+}
+
+const listExpenses = `-- name: ListExpenses :many
+SELECT
+	expenses.id AS id,
+	expenses.user_id AS user_id,
+	expenses.category_id AS category_id,
+	expenses.amount AS amount,
+	CASE
+		WHEN food_receipts.store_name IS NULL then ''
+		ELSE food_receipts.store_name
+	END AS store_name,
+	expenses.comment AS comment,
+	expenses.created_at AS created_at,
+	expenses.version AS version
+FROM expenses
+LEFT OUTER JOIN food_receipts ON expenses.food_receipt_id = food_receipts.id
+INNER JOIN categories ON expenses.category_id = categories.id
+WHERE expenses.user_id = ?
+	AND expenses.deleted_at IS NULL`
+
+func (q *Queries) ListExpenses(ctx context.Context, userID int64) ([]db.ListExpensesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listExpenses, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.ListExpensesRow{}
+	for rows.Next() {
+		var i db.ListExpensesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.StoreName,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExportExpenses = `-- name: ListExportExpenses :many
+SELECT
+	expenses.id AS id,
+	expenses.user_id AS user_id,
+	expenses.category_id AS category_id,
+	expenses.amount AS amount,
+	CASE
+		WHEN food_receipts.store_name IS NULL then ''
+		ELSE food_receipts.store_name
+	END AS store_name,
+	expenses.comment AS comment,
+	expenses.created_at AS created_at,
+	expenses.version AS version,
+	expenses.deleted_at AS deleted_at
+FROM expenses
+LEFT OUTER JOIN food_receipts ON expenses.food_receipt_id = food_receipts.id
+WHERE expenses.user_id = ?
+ORDER BY expenses.id`
+
+// ユーザーの全ての支出を返す（エクスポート用）。ゴミ箱にある支出も含める。
+func (q *Queries) ListExportExpenses(ctx context.Context, userID int64) ([]db.ListExportExpensesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listExportExpenses, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.ListExportExpensesRow{}
+	for rows.Next() {
+		var i db.ListExportExpensesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.StoreName,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// 接続を１つに制限しており、トランザクションは直列に実行されるため FOR UPDATE は不要。
+const getExpenseForUpdate = `-- name: GetExpenseForUpdate :one
+SELECT ` + expenseColumns + ` FROM expenses
+WHERE id = ?
+	AND user_id = ?
+	AND deleted_at IS NULL
+LIMIT 1`
+
+func (q *Queries) GetExpenseForUpdate(ctx context.Context, arg db.GetExpenseForUpdateParams) (db.Expense, error) {
+	return scanExpense(q.db.QueryRowContext(ctx, getExpenseForUpdate, arg.ID, arg.UserID))
+}
+
+const updateExpense = `-- name: UpdateExpense :one
+UPDATE expenses
+SET
+	category_id = ?,
+	amount = ?,
+	comment = ?,
+	version = version + 1
+WHERE id = ?
+	AND user_id = ?
+	AND version = ?
+	AND deleted_at IS NULL
+RETURNING ` + expenseColumns
+
+func (q *Queries) UpdateExpense(ctx context.Context, arg db.UpdateExpenseParams) (db.Expense, error) {
+	return scanExpense(q.db.QueryRowContext(ctx, updateExpense,
+		arg.CategoryID,
+		arg.Amount,
+		arg.Comment,
+		arg.ID,
+		arg.UserID,
+		arg.Version,
+	))
+}
+
+const softDeleteExpense = `-- name: SoftDeleteExpense :one
+UPDATE expenses
+SET
+	deleted_at = ` + currentTimestamp + `,
+	version = version + 1
+WHERE id = ?
+	AND user_id = ?
+	AND version = ?
+	AND deleted_at IS NULL
+RETURNING ` + expenseColumns
+
+func (q *Queries) SoftDeleteExpense(ctx context.Context, arg db.SoftDeleteExpenseParams) (db.Expense, error) {
+	return scanExpense(q.db.QueryRowContext(ctx, softDeleteExpense, arg.ID, arg.UserID, arg.Version))
+}
+
+const restoreExpense = `-- name: RestoreExpense :one
+UPDATE expenses
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE id = ?
+	AND user_id = ?
+	AND deleted_at IS NOT NULL
+RETURNING ` + expenseColumns
+
+func (q *Queries) RestoreExpense(ctx context.Context, arg db.RestoreExpenseParams) (db.Expense, error) {
+	return scanExpense(q.db.QueryRowContext(ctx, restoreExpense, arg.ID, arg.UserID))
+}
+
+const listDeletedExpenses = `-- name: ListDeletedExpenses :many
+SELECT ` + expenseColumns + ` FROM expenses
+WHERE user_id = ?
+	AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC`
+
+func (q *Queries) ListDeletedExpenses(ctx context.Context, userID int64) ([]db.Expense, error) {
+	return q.queryExpenses(ctx, listDeletedExpenses, userID)
+}
+
+const purgeDeletedExpenses = `-- name: PurgeDeletedExpenses :many
+DELETE FROM expenses
+WHERE deleted_at < ?
+RETURNING id`
+
+func (q *Queries) PurgeDeletedExpenses(ctx context.Context, before time.Time) ([]int64, error) {
+	return q.queryIDs(ctx, purgeDeletedExpenses, timestamp(before))
+}
+
+// 同じユーザー・カテゴリー・金額で、since 以降に作成された支出を新しい順に返す。
+const listDuplicateExpenseCandidates = `-- name: ListDuplicateExpenseCandidates :many
+SELECT ` + expenseColumns + ` FROM expenses
+WHERE user_id = ?
+	AND category_id = ?
+	AND amount = ?
+	AND created_at >= ?
+	AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC`
+
+func (q *Queries) ListDuplicateExpenseCandidates(ctx context.Context, arg db.ListDuplicateExpenseCandidatesParams) ([]db.Expense, error) {
+	return q.queryExpenses(ctx, listDuplicateExpenseCandidates,
+		arg.UserID,
+		arg.CategoryID,
+		arg.Amount,
+		timestamp(arg.Since),
+	)
+}
+
+// 同じカテゴリー・金額の支出が、前後 window_seconds 秒以内に他にもある支出を返す。
+// 重複の組ごとにまとめられるよう、カテゴリー・金額・作成日時の順に並べる。
+const listDuplicateExpenses = `-- name: ListDuplicateExpenses :many
+SELECT ` + expenseColumns + ` FROM expenses
+WHERE expenses.user_id = ?
+	AND expenses.deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1 FROM expenses AS other
+		WHERE other.user_id = expenses.user_id
+			AND other.id <> expenses.id
+			AND other.category_id = expenses.category_id
+			AND other.amount = expenses.amount
+			AND other.deleted_at IS NULL
+			AND abs(julianday(other.created_at) - julianday(expenses.created_at)) * 86400 <= ?
+	)
+ORDER BY expenses.category_id, expenses.amount, expenses.created_at, expenses.id`
+
+func (q *Queries) ListDuplicateExpenses(ctx context.Context, arg db.ListDuplicateExpensesParams) ([]db.Expense, error) {
+	return q.queryExpenses(ctx, listDuplicateExpenses, arg.UserID, arg.WindowSeconds)
+}
+
+func (q *Queries) queryExpenses(ctx context.Context, query string, args ...interface{}) ([]db.Expense, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Expense{}
+	for rows.Next() {
+		i, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
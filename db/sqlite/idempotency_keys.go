@@ -0,0 +1,125 @@
+package sqlitedb
+
+import (
+	"context"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const idempotencyKeyColumns = `user_id, key, fingerprint, status_code, response_headers, response_body, expires_at, created_at`
+
+func scanIdempotencyKey(row scanner) (db.IdempotencyKey, error) {
+	var i db.IdempotencyKey
+	// TEXT の列は json.RawMessage に直接読み込めないため、[]byte を経由する。
+	var headers []byte
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&headers,
+		&i.ResponseBody,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	i.ResponseHeaders = headers
+	return i, convertError(err)
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+	status_code = ?,
+	response_headers = ?,
+	response_body = ?
+WHERE user_id = ?
+	AND key = ?`
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.StatusCode,
+		string(arg.ResponseHeaders),
+		arg.ResponseBody,
+		arg.UserID,
+		arg.Key,
+	)
+	return convertError(err)
+}
+
+// 有効期限を過ぎた行と、処理中のまま stale_before より前に作成された行は置き換える。
+// それ以外の行が既にある場合は、行を返さない。
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+	user_id,
+	key,
+	fingerprint,
+	expires_at
+) VALUES (
+	?, ?, ?, ?
+)
+ON CONFLICT (user_id, key) DO UPDATE
+SET
+	fingerprint = excluded.fingerprint,
+	status_code = NULL,
+	response_headers = '{}',
+	response_body = X'',
+	expires_at = excluded.expires_at,
+	created_at = ` + currentTimestamp + `
+WHERE idempotency_keys.expires_at <= ` + currentTimestamp + `
+	OR (
+		idempotency_keys.status_code IS NULL
+		AND idempotency_keys.created_at < ?
+	)
+RETURNING ` + idempotencyKeyColumns
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	return scanIdempotencyKey(q.db.QueryRowContext(ctx, createIdempotencyKey,
+		arg.UserID,
+		arg.Key,
+		arg.Fingerprint,
+		timestamp(arg.ExpiresAt),
+		timestamp(arg.StaleBefore),
+	))
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = ?
+	AND key = ?`
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.Key)
+	return convertError(err)
+}
+
+const deleteUserIdempotencyKeys = `-- name: DeleteUserIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE user_id = ?`
+
+func (q *Queries) DeleteUserIdempotencyKeys(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserIdempotencyKeys, userID)
+	return convertError(err)
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT ` + idempotencyKeyColumns + ` FROM idempotency_keys
+WHERE user_id = ?
+	AND key = ?
+LIMIT 1`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	return scanIdempotencyKey(q.db.QueryRowContext(ctx, getIdempotencyKey, arg.UserID, arg.Key))
+}
+
+const purgeExpiredIdempotencyKeys = `-- name: PurgeExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?`
+
+func (q *Queries) PurgeExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeExpiredIdempotencyKeys, timestamp(expiresAt))
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return result.RowsAffected()
+}
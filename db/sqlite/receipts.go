@@ -0,0 +1,471 @@
+package sqlitedb
+
+import (
+	"context"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const foodContentColumns = `id, name, calories, lipid, carbohydrate, protein, version`
+
+func scanFoodContent(row scanner) (db.FoodContent, error) {
+	var i db.FoodContent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Calories,
+		&i.Lipid,
+		&i.Carbohydrate,
+		&i.Protein,
+		&i.Version,
+	)
+	return i, convertError(err)
+}
+
+const foodReceiptColumns = `id, store_name, deleted_at, version, user_id, total_price, items_digest, created_at`
+
+func scanFoodReceipt(row scanner) (db.FoodReceipt, error) {
+	var i db.FoodReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.StoreName,
+		&i.DeletedAt,
+		&i.Version,
+		&i.UserID,
+		&i.TotalPrice,
+		&i.ItemsDigest,
+		&i.CreatedAt,
+	)
+	return i, convertError(err)
+}
+
+const createFoodContent = `-- name: CreateFoodContent :one
+INSERT INTO food_contents (
+	name,
+	calories,
+	lipid,
+	carbohydrate,
+	protein
+) VALUES (
+	?, ?, ?, ?, ?
+) RETURNING ` + foodContentColumns
+
+func (q *Queries) CreateFoodContent(ctx context.Context, arg db.CreateFoodContentParams) (db.FoodContent, error) {
+	return scanFoodContent(q.db.QueryRowContext(ctx, createFoodContent,
+		arg.Name,
+		arg.Calories,
+		arg.Lipid,
+		arg.Carbohydrate,
+		arg.Protein,
+	))
+}
+
+// created_at は ALTER TABLE で追加したため既定値がなく、ここで指定する。
+const createFoodReceipt = `-- name: CreateFoodReceipt :one
+INSERT INTO food_receipts (
+	store_name,
+	user_id,
+	total_price,
+	items_digest,
+	created_at
+) VALUES (
+	?, ?, ?, ?, ` + currentTimestamp + `
+) RETURNING ` + foodReceiptColumns
+
+func (q *Queries) CreateFoodReceipt(ctx context.Context, arg db.CreateFoodReceiptParams) (db.FoodReceipt, error) {
+	return scanFoodReceipt(q.db.QueryRowContext(ctx, createFoodReceipt,
+		arg.StoreName,
+		arg.UserID,
+		arg.TotalPrice,
+		arg.ItemsDigest,
+	))
+}
+
+const createFoodReceiptContent = `-- name: CreateFoodReceiptContent :one
+INSERT INTO food_receipt_contents (
+	food_receipt_id,
+	food_content_id,
+	amount
+) VALUES (
+	?, ?, ?
+) RETURNING id, food_receipt_id, food_content_id, amount`
+
+func (q *Queries) CreateFoodReceiptContent(ctx context.Context, arg db.CreateFoodReceiptContentParams) (db.FoodReceiptContent, error) {
+	row := q.db.QueryRowContext(ctx, createFoodReceiptContent, arg.FoodReceiptID, arg.FoodContentID, arg.Amount)
+	var i db.FoodReceiptContent
+	err := row.Scan(
+		&i.ID,
+		&i.FoodReceiptID,
+		&i.FoodContentID,
+		&i.Amount,
+	)
+	return i, convertError(err)
+}
+
+const deleteFoodContent = `-- name: DeleteFoodContent :one
+DELETE FROM food_contents
+WHERE id = ?
+	AND version = ?
+RETURNING ` + foodContentColumns
+
+func (q *Queries) DeleteFoodContent(ctx context.Context, arg db.DeleteFoodContentParams) (db.FoodContent, error) {
+	return scanFoodContent(q.db.QueryRowContext(ctx, deleteFoodContent, arg.ID, arg.Version))
+}
+
+const deleteFoodReceiptContents = `-- name: DeleteFoodReceiptContents :exec
+DELETE FROM food_receipt_contents
+WHERE food_receipt_id IN (SELECT value FROM json_each(?))
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipt_contents.food_receipt_id
+	)`
+
+// 他のユーザーの支出から参照されているレシートの明細は残す。
+func (q *Queries) DeleteFoodReceiptContents(ctx context.Context, foodReceiptIds []int64) error {
+	ids, err := int64Array(foodReceiptIds)
+	if err != nil {
+		return err
+	}
+	_, err = q.db.ExecContext(ctx, deleteFoodReceiptContents, ids)
+	return convertError(err)
+}
+
+const deleteFoodReceipts = `-- name: DeleteFoodReceipts :exec
+DELETE FROM food_receipts
+WHERE id IN (SELECT value FROM json_each(?))
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipts.id
+	)`
+
+// 他のユーザーの支出から参照されているレシートは残す。
+func (q *Queries) DeleteFoodReceipts(ctx context.Context, ids []int64) error {
+	array, err := int64Array(ids)
+	if err != nil {
+		return err
+	}
+	_, err = q.db.ExecContext(ctx, deleteFoodReceipts, array)
+	return convertError(err)
+}
+
+const getFoodContent = `-- name: GetFoodContent :one
+SELECT ` + foodContentColumns + ` FROM food_contents
+WHERE id = ? LIMIT 1`
+
+func (q *Queries) GetFoodContent(ctx context.Context, id int64) (db.FoodContent, error) {
+	return scanFoodContent(q.db.QueryRowContext(ctx, getFoodContent, id))
+}
+
+const getFoodContentByName = `-- name: GetFoodContentByName :one
+SELECT ` + foodContentColumns + ` FROM food_contents
+WHERE name = ?
+ORDER BY id
+LIMIT 1`
+
+func (q *Queries) GetFoodContentByName(ctx context.Context, name string) (db.FoodContent, error) {
+	return scanFoodContent(q.db.QueryRowContext(ctx, getFoodContentByName, name))
+}
+
+const getFoodReceipt = `-- name: GetFoodReceipt :one
+SELECT ` + foodReceiptColumns + ` FROM food_receipts
+WHERE id = ?
+	AND deleted_at IS NULL
+LIMIT 1`
+
+func (q *Queries) GetFoodReceipt(ctx context.Context, id int64) (db.FoodReceipt, error) {
+	return scanFoodReceipt(q.db.QueryRowContext(ctx, getFoodReceipt, id))
+}
+
+const listFoodContents = `-- name: ListFoodContents :many
+SELECT ` + foodContentColumns + ` FROM food_contents
+ORDER BY id
+LIMIT ?
+OFFSET ?`
+
+func (q *Queries) ListFoodContents(ctx context.Context, arg db.ListFoodContentsParams) ([]db.FoodContent, error) {
+	rows, err := q.db.QueryContext(ctx, listFoodContents, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.FoodContent{}
+	for rows.Next() {
+		i, err := scanFoodContent(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFoodReceiptContents = `-- name: ListFoodReceiptContents :many
+SELECT
+	food_receipt_contents.food_receipt_id AS food_receipt_id,
+	food_receipt_contents.food_content_id AS food_content_id,
+	food_receipt_contents.amount AS amount,
+	food_contents.name AS name,
+	food_contents.calories AS calories,
+	food_contents.lipid AS lipid,
+	food_contents.carbohydrate AS carbohydrate,
+	food_contents.protein AS protein
+FROM food_receipt_contents
+INNER JOIN food_contents ON food_receipt_contents.food_content_id = food_contents.id
+WHERE food_receipt_contents.food_receipt_id = ?`
+
+func (q *Queries) ListFoodReceiptContents(ctx context.Context, foodReceiptID int64) ([]db.ListFoodReceiptContentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFoodReceiptContents, foodReceiptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.ListFoodReceiptContentsRow{}
+	for rows.Next() {
+		var i db.ListFoodReceiptContentsRow
+		if err := rows.Scan(
+			&i.FoodReceiptID,
+			&i.FoodContentID,
+			&i.Amount,
+			&i.Name,
+			&i.Calories,
+			&i.Lipid,
+			&i.Carbohydrate,
+			&i.Protein,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserFoodReceiptContents = `-- name: ListUserFoodReceiptContents :many
+SELECT
+	food_receipts.id AS food_receipt_id,
+	food_receipts.store_name AS store_name,
+	food_receipts.deleted_at AS deleted_at,
+	food_receipt_contents.food_content_id AS food_content_id,
+	food_receipt_contents.amount AS amount,
+	food_contents.name AS name,
+	food_contents.calories AS calories,
+	food_contents.lipid AS lipid,
+	food_contents.carbohydrate AS carbohydrate,
+	food_contents.protein AS protein
+FROM food_receipts
+LEFT OUTER JOIN food_receipt_contents ON food_receipt_contents.food_receipt_id = food_receipts.id
+LEFT OUTER JOIN food_contents ON food_receipt_contents.food_content_id = food_contents.id
+WHERE food_receipts.user_id = ?
+ORDER BY food_receipts.id, food_receipt_contents.id`
+
+// ユーザーが登録したレシートを、明細ごとに1行で返す（エクスポート用）。
+// 明細のないレシートも1行で返し、ゴミ箱にあるレシートも含める。
+func (q *Queries) ListUserFoodReceiptContents(ctx context.Context, userID int64) ([]db.ListUserFoodReceiptContentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserFoodReceiptContents, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.ListUserFoodReceiptContentsRow{}
+	for rows.Next() {
+		var i db.ListUserFoodReceiptContentsRow
+		if err := rows.Scan(
+			&i.FoodReceiptID,
+			&i.StoreName,
+			&i.DeletedAt,
+			&i.FoodContentID,
+			&i.Amount,
+			&i.Name,
+			&i.Calories,
+			&i.Lipid,
+			&i.Carbohydrate,
+			&i.Protein,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ユーザーが登録したレシートを返す。
+const listUserFoodReceiptIDs = `-- name: ListUserFoodReceiptIDs :many
+SELECT id
+FROM food_receipts
+WHERE food_receipts.user_id = ?
+ORDER BY id`
+
+func (q *Queries) ListUserFoodReceiptIDs(ctx context.Context, userID int64) ([]int64, error) {
+	return q.queryIDs(ctx, listUserFoodReceiptIDs, userID)
+}
+
+const updateFoodContent = `-- name: UpdateFoodContent :one
+UPDATE food_contents
+SET
+	name = ?,
+	calories = ?,
+	lipid = ?,
+	carbohydrate = ?,
+	protein = ?,
+	version = version + 1
+WHERE id = ?
+	AND version = ?
+RETURNING ` + foodContentColumns
+
+func (q *Queries) UpdateFoodContent(ctx context.Context, arg db.UpdateFoodContentParams) (db.FoodContent, error) {
+	return scanFoodContent(q.db.QueryRowContext(ctx, updateFoodContent,
+		arg.Name,
+		arg.Calories,
+		arg.Lipid,
+		arg.Carbohydrate,
+		arg.Protein,
+		arg.ID,
+		arg.Version,
+	))
+}
+
+// レシートを登録したユーザーのみ取得できる。
+// 接続を１つに制限しており、トランザクションは直列に実行されるため FOR UPDATE は不要。
+const getFoodReceiptForUpdate = `-- name: GetFoodReceiptForUpdate :one
+SELECT ` + foodReceiptColumns + ` FROM food_receipts
+WHERE food_receipts.id = ?
+	AND food_receipts.deleted_at IS NULL
+	AND food_receipts.user_id = ?
+LIMIT 1`
+
+func (q *Queries) GetFoodReceiptForUpdate(ctx context.Context, arg db.GetFoodReceiptForUpdateParams) (db.FoodReceipt, error) {
+	return scanFoodReceipt(q.db.QueryRowContext(ctx, getFoodReceiptForUpdate, arg.ID, arg.UserID))
+}
+
+// レシートを登録したユーザーのみ、レシートを削除できる。
+const softDeleteFoodReceipt = `-- name: SoftDeleteFoodReceipt :one
+UPDATE food_receipts
+SET
+	deleted_at = ` + currentTimestamp + `,
+	version = version + 1
+WHERE food_receipts.id = ?
+	AND food_receipts.version = ?
+	AND food_receipts.deleted_at IS NULL
+	AND food_receipts.user_id = ?
+RETURNING ` + foodReceiptColumns
+
+func (q *Queries) SoftDeleteFoodReceipt(ctx context.Context, arg db.SoftDeleteFoodReceiptParams) (db.FoodReceipt, error) {
+	return scanFoodReceipt(q.db.QueryRowContext(ctx, softDeleteFoodReceipt, arg.ID, arg.Version, arg.UserID))
+}
+
+const restoreFoodReceipt = `-- name: RestoreFoodReceipt :one
+UPDATE food_receipts
+SET
+	deleted_at = NULL,
+	version = version + 1
+WHERE food_receipts.id = ?
+	AND food_receipts.deleted_at IS NOT NULL
+	AND food_receipts.user_id = ?
+RETURNING ` + foodReceiptColumns
+
+func (q *Queries) RestoreFoodReceipt(ctx context.Context, arg db.RestoreFoodReceiptParams) (db.FoodReceipt, error) {
+	return scanFoodReceipt(q.db.QueryRowContext(ctx, restoreFoodReceipt, arg.ID, arg.UserID))
+}
+
+const listDeletedFoodReceipts = `-- name: ListDeletedFoodReceipts :many
+SELECT ` + foodReceiptColumns + ` FROM food_receipts
+WHERE food_receipts.deleted_at IS NOT NULL
+	AND food_receipts.user_id = ?
+ORDER BY food_receipts.deleted_at DESC, food_receipts.id DESC`
+
+func (q *Queries) ListDeletedFoodReceipts(ctx context.Context, userID int64) ([]db.FoodReceipt, error) {
+	return q.queryFoodReceipts(ctx, listDeletedFoodReceipts, userID)
+}
+
+func (q *Queries) queryFoodReceipts(ctx context.Context, query string, args ...interface{}) ([]db.FoodReceipt, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.FoodReceipt{}
+	for rows.Next() {
+		i, err := scanFoodReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// 支出から参照されているレシートは削除できないため、明細も残す。
+const purgeDeletedFoodReceiptContents = `-- name: PurgeDeletedFoodReceiptContents :exec
+DELETE FROM food_receipt_contents
+WHERE food_receipt_id IN (
+	SELECT food_receipts.id FROM food_receipts
+	WHERE food_receipts.deleted_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM expenses
+			WHERE expenses.food_receipt_id = food_receipts.id
+		)
+)`
+
+func (q *Queries) PurgeDeletedFoodReceiptContents(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, purgeDeletedFoodReceiptContents, timestamp(before))
+	return convertError(err)
+}
+
+// 支出から参照されているレシートは残す。
+const purgeDeletedFoodReceipts = `-- name: PurgeDeletedFoodReceipts :many
+DELETE FROM food_receipts
+WHERE food_receipts.deleted_at < ?
+	AND NOT EXISTS (
+		SELECT 1 FROM expenses
+		WHERE expenses.food_receipt_id = food_receipts.id
+	)
+RETURNING id`
+
+func (q *Queries) PurgeDeletedFoodReceipts(ctx context.Context, before time.Time) ([]int64, error) {
+	return q.queryIDs(ctx, purgeDeletedFoodReceipts, timestamp(before))
+}
+
+// 同じユーザーが since 以降に登録した、店名・合計金額・明細が同じレシートを新しい順に返す。
+const listDuplicateFoodReceiptCandidates = `-- name: ListDuplicateFoodReceiptCandidates :many
+SELECT ` + foodReceiptColumns + ` FROM food_receipts
+WHERE user_id = ?
+	AND store_name = ?
+	AND total_price = ?
+	AND items_digest = ?
+	AND created_at >= ?
+	AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC`
+
+func (q *Queries) ListDuplicateFoodReceiptCandidates(ctx context.Context, arg db.ListDuplicateFoodReceiptCandidatesParams) ([]db.FoodReceipt, error) {
+	return q.queryFoodReceipts(ctx, listDuplicateFoodReceiptCandidates,
+		arg.UserID,
+		arg.StoreName,
+		arg.TotalPrice,
+		arg.ItemsDigest,
+		timestamp(arg.Since),
+	)
+}
@@ -0,0 +1,152 @@
+package sqlitedb
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const sessionColumns = `id, user_id, user_agent, client_ip, created_at, expires_at`
+
+func scanSession(row scanner) (db.Session, error) {
+	var i db.Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, convertError(err)
+}
+
+const countActiveSessions = `-- name: CountActiveSessions :one
+SELECT COUNT(*) FROM sessions
+WHERE expires_at > ` + currentTimestamp
+
+func (q *Queries) CountActiveSessions(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveSessions)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+	id,
+	user_id,
+	user_agent,
+	client_ip,
+	expires_at
+) VALUES (
+	?, ?, ?, ?, ?
+) RETURNING ` + sessionColumns
+
+func (q *Queries) CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+	return scanSession(q.db.QueryRowContext(ctx, createSession,
+		arg.ID,
+		arg.UserID,
+		arg.UserAgent,
+		arg.ClientIp,
+		timestamp(arg.ExpiresAt),
+	))
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+UPDATE sessions
+SET expires_at = ` + currentTimestamp + `
+WHERE id = ?`
+
+func (q *Queries) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	return convertError(err)
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+UPDATE sessions
+SET expires_at = ` + currentTimestamp + `
+WHERE user_id = ?
+	AND expires_at > ` + currentTimestamp
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSessions, userID)
+	return convertError(err)
+}
+
+const getAuthSession = `-- name: GetAuthSession :one
+SELECT sessions.id, sessions.user_id, sessions.user_agent, sessions.client_ip, sessions.created_at, sessions.expires_at, users.locale
+FROM sessions
+JOIN users ON users.id = sessions.user_id
+WHERE sessions.id = ? LIMIT 1`
+
+// 認証後のメッセージに使うため、ユーザーの言語設定と合わせて取得する。
+func (q *Queries) GetAuthSession(ctx context.Context, id uuid.UUID) (db.GetAuthSessionRow, error) {
+	var i db.GetAuthSessionRow
+	err := q.db.QueryRowContext(ctx, getAuthSession, id).Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Locale,
+	)
+	return i, convertError(err)
+}
+
+const getSession = `-- name: GetSession :one
+SELECT ` + sessionColumns + ` FROM sessions
+WHERE id = ? LIMIT 1`
+
+func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (db.Session, error) {
+	return scanSession(q.db.QueryRowContext(ctx, getSession, id))
+}
+
+const listUserSessions = `-- name: ListUserSessions :many
+SELECT ` + sessionColumns + ` FROM sessions
+WHERE user_id = ?
+ORDER BY created_at`
+
+func (q *Queries) ListUserSessions(ctx context.Context, userID int64) ([]db.Session, error) {
+	rows, err := q.db.QueryContext(ctx, listUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Session{}
+	for rows.Next() {
+		i, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUserSessions = `-- name: PurgeUserSessions :exec
+DELETE FROM sessions
+WHERE user_id = ?`
+
+func (q *Queries) PurgeUserSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, purgeUserSessions, userID)
+	return convertError(err)
+}
+
+const updateSession = `-- name: UpdateSession :exec
+UPDATE sessions
+SET expires_at = ?
+WHERE id = ?`
+
+func (q *Queries) UpdateSession(ctx context.Context, arg db.UpdateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, updateSession, timestamp(arg.ExpiresAt), arg.ID)
+	return convertError(err)
+}
@@ -0,0 +1,406 @@
+// Package sqlitedb は、db.Store を SQLite で実装する。
+// PostgreSQL を用意せずに、１人で使うためにセルフホストする場合に使う。
+//
+// sqlc v1.13 は SQLite の RETURNING を解析できないため、クエリは db/query と揃えて手で書く。
+// スキーマは db/migration/sqlite のマイグレーションで作成する。
+//
+// 制約違反の場合は memdb と同様に、db.ErrUniqueViolation などの *db.ConstraintError を返す。
+// 挙動が PostgreSQL と一致していることは、dbtest の共通のテストで確かめる。
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/mattn/go-sqlite3"
+)
+
+// database/sql に登録されている go-sqlite3 のドライバー名。
+const DriverName = "sqlite3"
+
+// SQLite に保存する時刻の形式。
+// 文字列のまま比較しても時刻の順序になるよう、UTC の固定長で保存する。
+const timeFormat = "2006-01-02 15:04:05.000000"
+
+// SQL の中で現在時刻を表す式。timeFormat と同じ形式になる。
+const currentTimestamp = "strftime('%Y-%m-%d %H:%M:%f000', 'now')"
+
+// 接続ごとに指定するパラメーター。
+// 外部キー制約は、接続ごとに有効にしないと検証されない。
+var connectionParams = []string{
+	"_foreign_keys=on",
+	"_busy_timeout=5000",
+}
+
+// SQLite のファイルを開く。
+// source には "file:account_book.db" のようなファイル名か、go-sqlite3 のDSNを指定する。
+//
+// SQLite は同時に１つの接続しか書き込めないため、接続は１つに制限する。
+// ":memory:" を指定した場合も、全てのクエリが同じDBを参照する。
+func Open(source string) (*sql.DB, error) {
+	for _, param := range connectionParams {
+		key := strings.SplitN(param, "=", 2)[0]
+		if strings.Contains(source, key+"=") {
+			continue
+		}
+		if strings.Contains(source, "?") {
+			source += "&" + param
+		} else {
+			source += "?" + param
+		}
+	}
+
+	conn, err := sql.Open(DriverName, source)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+	return conn, nil
+}
+
+// クエリを実行する構造体。
+// *sql.DB と *sql.Tx のどちらでも実行できるよう、db.DBTX を受け取る。
+type Queries struct {
+	db db.DBTX
+}
+
+var _ db.Querier = (*Queries)(nil)
+
+// SQLite を使って db.Store を実装した構造体。
+type Store struct {
+	*Queries
+	db *sql.DB
+}
+
+var _ db.Store = (*Store)(nil)
+
+// Store を作成する。conn は Open で開いたものを渡す。
+func NewStore(conn *sql.DB) db.Store {
+	return &Store{
+		Queries: &Queries{db: conn},
+		db:      conn,
+	}
+}
+
+// DBに接続できるか確かめる。
+func (store *Store) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// schema_migrations テーブルから、適用済みのマイグレーションのバージョンを取得する。
+func (store *Store) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// トランザクション内で関数を実行する。
+// 関数がエラーを返した場合はロールバックする。
+func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(&Queries{db: tx})
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ユーザーの個人データを１つのトランザクションで削除・匿名化する。
+func (store *Store) DeleteUserTx(ctx context.Context, userID int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return db.DeleteUserData(ctx, q, userID)
+	})
+}
+
+func (store *Store) ConfirmEmailChangeTx(ctx context.Context, tokenHash string) (db.User, error) {
+	var user db.User
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = db.ConfirmEmailChange(ctx, q, tokenHash)
+		return err
+	})
+	return user, err
+}
+
+func (store *Store) CreateExpenseTx(ctx context.Context, arg db.CreateExpenseParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = db.CreateExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) CreateReceiptTx(ctx context.Context, arg db.CreateReceiptTxParams, actor db.AuditActor) (db.CreateReceiptTxResult, error) {
+	var result db.CreateReceiptTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = db.CreateReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return result, err
+}
+
+func (store *Store) CreateCategoryTx(ctx context.Context, name string, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = db.CreateCategoryWithAudit(ctx, q, name, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) UpdateCategoryTx(ctx context.Context, arg db.UpdateCategoryParams, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = db.UpdateCategoryWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) DeleteCategoryTx(ctx context.Context, arg db.DeleteCategoryParams, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = db.DeleteCategoryWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) UpdateExpenseTx(ctx context.Context, arg db.UpdateExpenseTxParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = db.UpdateExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) DeleteExpenseTx(ctx context.Context, arg db.SoftDeleteExpenseParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = db.DeleteExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) RestoreExpenseTx(ctx context.Context, arg db.RestoreExpenseParams, actor db.AuditActor) (db.Expense, error) {
+	var expense db.Expense
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expense, err = db.RestoreExpenseWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return expense, err
+}
+
+func (store *Store) DeleteReceiptTx(ctx context.Context, arg db.SoftDeleteFoodReceiptParams, actor db.AuditActor) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		receipt, err = db.DeleteReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *Store) RestoreReceiptTx(ctx context.Context, arg db.RestoreFoodReceiptParams, actor db.AuditActor) (db.FoodReceipt, error) {
+	var receipt db.FoodReceipt
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		receipt, err = db.RestoreReceiptWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return receipt, err
+}
+
+func (store *Store) RestoreCategoryTx(ctx context.Context, id int64, actor db.AuditActor) (db.Category, error) {
+	var category db.Category
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		category, err = db.RestoreCategoryWithAudit(ctx, q, id, actor)
+		return err
+	})
+	return category, err
+}
+
+func (store *Store) CreateTransferTx(ctx context.Context, arg db.CreateTransferParams, actor db.AuditActor) (db.Transfer, error) {
+	var transfer db.Transfer
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		transfer, err = db.CreateTransferWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return transfer, err
+}
+
+func (store *Store) AdjustBalanceTx(ctx context.Context, arg db.AdjustBalanceTxParams, actor db.AuditActor) (int64, error) {
+	var balance int64
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		balance, err = db.AdjustBalanceWithAudit(ctx, q, arg, actor)
+		return err
+	})
+	return balance, err
+}
+
+func (store *Store) PurgeTrashTx(ctx context.Context, before time.Time) (db.PurgeTrashTxResult, error) {
+	var result db.PurgeTrashTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = db.PurgeTrash(ctx, q, before)
+		return err
+	})
+	return result, err
+}
+
+// Row と Rows のどちらからでも読み込めるようにする。
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// 時刻を timeFormat の文字列に変換する。
+// timestamptz の精度に合わせて、マイクロ秒に切り捨てる。
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+func nullTimestamp(t sql.NullTime) interface{} {
+	if !t.Valid {
+		return nil
+	}
+	return timestamp(t.Time)
+}
+
+// PostgreSQL の配列の代わりに、JSON の配列として保存する文字列の配列。
+type stringArray []string
+
+func (a stringArray) Value() (driver.Value, error) {
+	if a == nil {
+		a = stringArray{}
+	}
+	b, err := json.Marshal([]string(a))
+	return string(b), err
+}
+
+func (a *stringArray) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(a))
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(a))
+	}
+	return fmt.Errorf("cannot scan %T into stringArray", src)
+}
+
+// ID のみを返すクエリを実行する。
+func (q *Queries) queryIDs(ctx context.Context, query string, args ...interface{}) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertError(err)
+	}
+	return items, nil
+}
+
+// ANY($1::bigint[]) の代わりに json_each で展開する、IDのJSONの配列。
+func int64Array(ids []int64) (string, error) {
+	if ids == nil {
+		ids = []int64{}
+	}
+	b, err := json.Marshal(ids)
+	return string(b), err
+}
+
+// SQLite の制約違反を *db.ConstraintError に変換する。
+// ハンドラーは DB の種類によらず、同じエラーで判定できる。
+func convertError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	var kind error
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		kind = db.ErrUniqueViolation
+	case sqlite3.ErrConstraintForeignKey:
+		kind = db.ErrForeignKeyViolation
+	case sqlite3.ErrConstraintTrigger:
+		// ON DELETE RESTRICT の違反は、内部のトリガーのエラーとして返される。
+		if !strings.Contains(sqliteErr.Error(), "FOREIGN KEY") {
+			return err
+		}
+		kind = db.ErrForeignKeyViolation
+	case sqlite3.ErrConstraintNotNull:
+		kind = db.ErrNotNullViolation
+	case sqlite3.ErrConstraintCheck:
+		kind = db.ErrCheckViolation
+	default:
+		return err
+	}
+	return &db.ConstraintError{
+		Kind:  kind,
+		Table: constraintTable(sqliteErr.Error()),
+		Err:   err,
+	}
+}
+
+// "UNIQUE constraint failed: users.email" のようなメッセージから、テーブル名を取り出す。
+// 外部キー制約のメッセージにはテーブル名が含まれないため、空文字を返す。
+func constraintTable(message string) string {
+	i := strings.Index(message, ": ")
+	if i < 0 {
+		return ""
+	}
+	column := message[i+2:]
+	j := strings.Index(column, ".")
+	if j < 0 {
+		return ""
+	}
+	return column[:j]
+}
@@ -0,0 +1,256 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kokoichi206/account-book-api/db/dbtest"
+	"github.com/kokoichi206/account-book-api/db/migration"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// 一時ファイルのDBを作成し、最新のバージョンまでマイグレーションする。
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := Open("file:" + filepath.Join(t.TempDir(), "account_book.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	migrator, err := migration.NewMigrator(conn, migration.SQLite, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.Background()))
+	return conn
+}
+
+// PostgreSQL とメモリ上の実装と同じテストを、SQLite に対して実行する。
+func TestStoreContract(t *testing.T) {
+	dbtest.RunStoreContract(t, NewStore(newTestDB(t)))
+}
+
+func TestMigrationVersion(t *testing.T) {
+	// Arrange
+	store := NewStore(newTestDB(t))
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+
+	// Act
+	version, dirty, err := store.MigrationVersion(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+	require.False(t, dirty)
+}
+
+func TestOnDelete(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestDB(t)
+	store := NewStore(conn)
+
+	createUser := func(t *testing.T, email string) db.User {
+		user, err := store.CreateUser(ctx, db.CreateUserParams{Name: "user", Password: "secret", Email: email})
+		require.NoError(t, err)
+		return user
+	}
+	deleteUser := func(id int64) error {
+		_, err := conn.ExecContext(ctx, "DELETE FROM users WHERE id = ?", id)
+		return convertError(err)
+	}
+
+	t.Run("CascadeSessions", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, "cascade@example.com")
+		session, err := store.CreateSession(ctx, db.CreateSessionParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		// Act
+		err = deleteUser(user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		_, err = store.GetSession(ctx, session.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("RestrictExpenses", func(t *testing.T) {
+		// Arrange
+		user := createUser(t, "expense@example.com")
+		category, err := store.CreateCategory(ctx, "restrict")
+		require.NoError(t, err)
+		_, err = store.CreateExpense(ctx, db.CreateExpenseParams{UserID: user.ID, CategoryID: category.ID, Amount: 100})
+		require.NoError(t, err)
+
+		// Act
+		err = deleteUser(user.ID)
+
+		// Assert
+		require.ErrorIs(t, err, db.ErrForeignKeyViolation)
+	})
+
+	t.Run("RestrictTransfers", func(t *testing.T) {
+		// Arrange
+		from := createUser(t, "from@example.com")
+		to := createUser(t, "to@example.com")
+		_, err := store.CreateTransfer(ctx, db.CreateTransferParams{FromUserID: from.ID, ToUserID: to.ID, Amount: 100})
+		require.NoError(t, err)
+
+		// Act
+		err = deleteUser(to.ID)
+
+		// Assert
+		require.ErrorIs(t, err, db.ErrForeignKeyViolation)
+	})
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	conn := newTestDB(t)
+	store := NewStore(conn)
+	user, err := store.CreateUser(ctx, db.CreateUserParams{Name: "user", Password: "secret", Email: "audit@example.com"})
+	require.NoError(t, err)
+	_, err = store.CreateCategoryTx(ctx, "audit", db.AuditActor{UserID: user.ID})
+	require.NoError(t, err)
+
+	// Act
+	_, errUpdate := conn.ExecContext(ctx, "UPDATE audit_events SET client_ip = '127.0.0.1'")
+	_, errDelete := conn.ExecContext(ctx, "DELETE FROM audit_events")
+
+	// Assert
+	require.ErrorContains(t, errUpdate, "append-only")
+	require.ErrorContains(t, errDelete, "append-only")
+}
+
+// 削除したユーザーの監査ログから個人データを消す更新のみ許可し、消した後は更新できないこと。
+func TestAuditEventsRedaction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	conn := newTestDB(t)
+	store := NewStore(conn)
+	user, err := store.CreateUser(ctx, db.CreateUserParams{Name: "user", Password: "secret", Email: "redact@example.com"})
+	require.NoError(t, err)
+	_, err = store.CreateCategoryTx(ctx, "redact", db.AuditActor{UserID: user.ID, ClientIP: "192.0.2.1"})
+	require.NoError(t, err)
+
+	// Act
+	_, errPartial := conn.ExecContext(ctx, "UPDATE audit_events SET client_ip = '', redacted_at = '2024-01-01'")
+	n, errRedact := store.RedactUserAuditEvents(ctx, user.ID)
+	_, errAgain := conn.ExecContext(ctx, "UPDATE audit_events SET action = 'update'")
+
+	// Assert
+	require.ErrorContains(t, errPartial, "append-only")
+	require.NoError(t, errRedact)
+	require.Equal(t, int64(1), n)
+	require.ErrorContains(t, errAgain, "append-only")
+}
+
+func TestConvertError(t *testing.T) {
+	errOther := errors.New("other")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+		table    string
+	}{
+		{
+			name:     "Unique",
+			err:      sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique},
+			expected: db.ErrUniqueViolation,
+		},
+		{
+			name:     "ForeignKey",
+			err:      sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey},
+			expected: db.ErrForeignKeyViolation,
+		},
+		{
+			name:     "Check",
+			err:      sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintCheck},
+			expected: db.ErrCheckViolation,
+		},
+		{
+			name: "Busy",
+			err:  sqlite3.Error{Code: sqlite3.ErrBusy},
+		},
+		{
+			name: "NoRows",
+			err:  sql.ErrNoRows,
+		},
+		{
+			name: "Other",
+			err:  errOther,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := convertError(tc.err)
+
+			// Assert
+			var constraintErr *db.ConstraintError
+			if tc.expected == nil {
+				require.False(t, errors.As(err, &constraintErr))
+				require.Equal(t, tc.err, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expected)
+			// 元のドライバーのエラーも取り出せること。
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestConstraintTable(t *testing.T) {
+	require.Equal(t, "users", constraintTable("UNIQUE constraint failed: users.email"))
+	require.Equal(t, "", constraintTable("FOREIGN KEY constraint failed"))
+}
+
+// sqlc で生成できないため手書きしているクエリが、db/query と同じ名前とコマンドで揃っていることを確認する。
+func TestQueriesMirrorSQLC(t *testing.T) {
+	queryNames := func(t *testing.T, pattern string) []string {
+		files, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, files)
+
+		re := regexp.MustCompile(`-- name: (\w+) (:\w+)`)
+		names := []string{}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			for _, m := range re.FindAllStringSubmatch(string(data), -1) {
+				names = append(names, m[1]+" "+m[2])
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// Act
+	postgres := queryNames(t, filepath.Join("..", "query", "*.sql"))
+	sqlite := queryNames(t, "*.go")
+
+	// Assert
+	require.Equal(t, postgres, sqlite)
+}
@@ -0,0 +1,63 @@
+package sqlitedb
+
+import (
+	"context"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const transferColumns = `id, from_user_id, to_user_id, amount, created_at`
+
+func scanTransfer(row scanner) (db.Transfer, error) {
+	var i db.Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.Amount,
+		&i.CreatedAt,
+	)
+	return i, convertError(err)
+}
+
+const createTransfer = `-- name: CreateTransfer :one
+INSERT INTO transfers (
+	from_user_id,
+	to_user_id,
+	amount
+) VALUES (
+	?, ?, ?
+) RETURNING ` + transferColumns
+
+func (q *Queries) CreateTransfer(ctx context.Context, arg db.CreateTransferParams) (db.Transfer, error) {
+	return scanTransfer(q.db.QueryRowContext(ctx, createTransfer, arg.FromUserID, arg.ToUserID, arg.Amount))
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT ` + transferColumns + ` FROM transfers
+WHERE from_user_id = ?1
+	OR to_user_id = ?1
+ORDER BY id`
+
+func (q *Queries) ListUserTransfers(ctx context.Context, fromUserID int64) ([]db.Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Transfer{}
+	for rows.Next() {
+		i, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
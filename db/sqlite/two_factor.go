@@ -0,0 +1,131 @@
+package sqlitedb
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const recoveryCodeColumns = `id, user_id, code_hash, used_at, created_at`
+
+func scanRecoveryCode(row scanner) (db.RecoveryCode, error) {
+	var i db.RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, convertError(err)
+}
+
+const twoFactorChallengeColumns = `id, user_id, user_agent, client_ip, created_at, expires_at, attempts`
+
+func scanTwoFactorChallenge(row scanner) (db.TwoFactorChallenge, error) {
+	var i db.TwoFactorChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Attempts,
+	)
+	return i, convertError(err)
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+	user_id,
+	code_hash
+) VALUES (
+	?, ?
+) RETURNING ` + recoveryCodeColumns
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	return scanRecoveryCode(q.db.QueryRowContext(ctx, createRecoveryCode, arg.UserID, arg.CodeHash))
+}
+
+const createTwoFactorChallenge = `-- name: CreateTwoFactorChallenge :one
+INSERT INTO two_factor_challenges (
+	id,
+	user_id,
+	user_agent,
+	client_ip,
+	expires_at
+) VALUES (
+	?, ?, ?, ?, ?
+) RETURNING ` + twoFactorChallengeColumns
+
+func (q *Queries) CreateTwoFactorChallenge(ctx context.Context, arg db.CreateTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+	return scanTwoFactorChallenge(q.db.QueryRowContext(ctx, createTwoFactorChallenge,
+		arg.ID,
+		arg.UserID,
+		arg.UserAgent,
+		arg.ClientIp,
+		timestamp(arg.ExpiresAt),
+	))
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = ?`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return convertError(err)
+}
+
+const deleteTwoFactorChallenge = `-- name: DeleteTwoFactorChallenge :exec
+DELETE FROM two_factor_challenges
+WHERE id = ?`
+
+func (q *Queries) DeleteTwoFactorChallenge(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTwoFactorChallenge, id)
+	return convertError(err)
+}
+
+const deleteUserTwoFactorChallenges = `-- name: DeleteUserTwoFactorChallenges :exec
+DELETE FROM two_factor_challenges
+WHERE user_id = ?`
+
+func (q *Queries) DeleteUserTwoFactorChallenges(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTwoFactorChallenges, userID)
+	return convertError(err)
+}
+
+const getTwoFactorChallenge = `-- name: GetTwoFactorChallenge :one
+SELECT ` + twoFactorChallengeColumns + ` FROM two_factor_challenges
+WHERE id = ? LIMIT 1`
+
+func (q *Queries) GetTwoFactorChallenge(ctx context.Context, id uuid.UUID) (db.TwoFactorChallenge, error) {
+	return scanTwoFactorChallenge(q.db.QueryRowContext(ctx, getTwoFactorChallenge, id))
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = ` + currentTimestamp + `
+WHERE user_id = ?
+	AND code_hash = ?
+	AND used_at IS NULL
+RETURNING ` + recoveryCodeColumns
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	return scanRecoveryCode(q.db.QueryRowContext(ctx, useRecoveryCode, arg.UserID, arg.CodeHash))
+}
+
+const useTwoFactorChallengeAttempt = `-- name: UseTwoFactorChallengeAttempt :one
+UPDATE two_factor_challenges
+SET attempts = attempts + 1
+WHERE id = ?
+	AND attempts < ?
+RETURNING ` + twoFactorChallengeColumns
+
+// コードを確かめる前に、試行回数を1回分消費する。
+// 並行したリクエストでも上限を超えないよう、上限に達したチャレンジは返さない。
+func (q *Queries) UseTwoFactorChallengeAttempt(ctx context.Context, arg db.UseTwoFactorChallengeAttemptParams) (db.TwoFactorChallenge, error) {
+	return scanTwoFactorChallenge(q.db.QueryRowContext(ctx, useTwoFactorChallengeAttempt, arg.ID, arg.MaxAttempts))
+}
@@ -0,0 +1,242 @@
+package sqlitedb
+
+import (
+	"context"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+)
+
+const userColumns = `id, name, password, email, age, balance, password_changed_at, created_at, totp_secret, totp_enabled, role, disabled_at, preferred_currency, locale, timezone, deletion_scheduled_at, anonymized_at, totp_last_counter`
+
+func scanUser(row scanner) (db.User, error) {
+	var i db.User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Password,
+		&i.Email,
+		&i.Age,
+		&i.Balance,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PreferredCurrency,
+		&i.Locale,
+		&i.Timezone,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+		&i.TotpLastCounter,
+	)
+	return i, convertError(err)
+}
+
+func (q *Queries) queryUsers(ctx context.Context, query string, args ...interface{}) ([]db.User, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.User{}
+	for rows.Next() {
+		i, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const addUserBalance = `-- name: AddUserBalance :one
+UPDATE users
+SET balance = balance + ?
+WHERE id = ?
+	AND anonymized_at IS NULL
+RETURNING balance`
+
+// 退会したユーザーの残高は変更せず、sql.ErrNoRows を返す。
+func (q *Queries) AddUserBalance(ctx context.Context, arg db.AddUserBalanceParams) (int64, error) {
+	var balance int64
+	err := q.db.QueryRowContext(ctx, addUserBalance, arg.Amount, arg.ID).Scan(&balance)
+	return balance, convertError(err)
+}
+
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE users
+SET
+	name = 'deleted user',
+	password = '',
+	email = 'deleted-' || id || '@invalid',
+	age = 0,
+	balance = 0,
+	totp_secret = NULL,
+	totp_enabled = false,
+	totp_last_counter = NULL,
+	disabled_at = ` + currentTimestamp + `,
+	deletion_scheduled_at = NULL,
+	anonymized_at = ` + currentTimestamp + `
+WHERE id = ?
+RETURNING ` + userColumns
+
+// 送金履歴の相手側から参照されるため、行は削除せずに個人を特定できる情報を消す。
+func (q *Queries) AnonymizeUser(ctx context.Context, id int64) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, anonymizeUser, id))
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (
+	name,
+	password,
+	email,
+	age,
+	balance
+) VALUES (
+	?, ?, ?, ?, ?
+) RETURNING ` + userColumns
+
+func (q *Queries) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, createUser,
+		arg.Name,
+		arg.Password,
+		arg.Email,
+		arg.Age,
+		arg.Balance,
+	))
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled = true
+WHERE id = ?
+RETURNING ` + userColumns
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, id int64) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, enableUserTOTP, id))
+}
+
+const getUser = `-- name: GetUser :one
+SELECT ` + userColumns + ` FROM users
+WHERE email = ? LIMIT 1`
+
+func (q *Queries) GetUser(ctx context.Context, email string) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUser, email))
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT ` + userColumns + ` FROM users
+WHERE id = ? LIMIT 1`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUserByID, id))
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT ` + userColumns + ` FROM users
+ORDER BY id
+LIMIT ?
+OFFSET ?`
+
+func (q *Queries) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+	return q.queryUsers(ctx, listUsers, arg.Limit, arg.Offset)
+}
+
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT ` + userColumns + ` FROM users
+WHERE deletion_scheduled_at <= ?
+	AND anonymized_at IS NULL
+ORDER BY deletion_scheduled_at
+LIMIT ?`
+
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context, arg db.ListUsersDueForDeletionParams) ([]db.User, error) {
+	return q.queryUsers(ctx, listUsersDueForDeletion, timestamp(arg.Now), arg.MaxUsers)
+}
+
+const updateUserDeletionScheduledAt = `-- name: UpdateUserDeletionScheduledAt :one
+UPDATE users
+SET deletion_scheduled_at = ?
+WHERE id = ?
+	AND anonymized_at IS NULL
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserDeletionScheduledAt(ctx context.Context, arg db.UpdateUserDeletionScheduledAtParams) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserDeletionScheduledAt, nullTimestamp(arg.DeletionScheduledAt), arg.ID))
+}
+
+const updateUserDisabledAt = `-- name: UpdateUserDisabledAt :one
+UPDATE users
+SET disabled_at = ?
+WHERE id = ?
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserDisabledAt(ctx context.Context, arg db.UpdateUserDisabledAtParams) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserDisabledAt, nullTimestamp(arg.DisabledAt), arg.ID))
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = ?
+WHERE id = ?
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg db.UpdateUserEmailParams) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserEmail, arg.Email, arg.ID))
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET
+	name = ?,
+	age = ?,
+	preferred_currency = ?,
+	locale = ?,
+	timezone = ?
+WHERE id = ?
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg db.UpdateUserProfileParams) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Name,
+		arg.Age,
+		arg.PreferredCurrency,
+		arg.Locale,
+		arg.Timezone,
+		arg.ID,
+	))
+}
+
+const updateUserTOTPLastCounter = `-- name: UpdateUserTOTPLastCounter :execrows
+UPDATE users
+SET totp_last_counter = ?
+WHERE id = ?
+	AND (totp_last_counter IS NULL OR totp_last_counter < ?)`
+
+// 前回受け付けたコードより後のステップの場合のみ更新する。
+// 0行の場合は、受け付け済みのコードの再利用とみなす。
+func (q *Queries) UpdateUserTOTPLastCounter(ctx context.Context, arg db.UpdateUserTOTPLastCounterParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserTOTPLastCounter, arg.Counter, arg.ID, arg.Counter)
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return result.RowsAffected()
+}
+
+const updateUserTOTPSecret = `-- name: UpdateUserTOTPSecret :one
+UPDATE users
+SET
+	totp_secret = ?,
+	totp_enabled = false
+WHERE id = ?
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserTOTPSecret(ctx context.Context, arg db.UpdateUserTOTPSecretParams) (db.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserTOTPSecret, arg.TotpSecret, arg.ID))
+}
@@ -0,0 +1,90 @@
+package i18n
+
+// 英語のメッセージ。
+// キーを追加する場合は、日本語のカタログにも同じキーを追加すること。
+var english = map[string]string{
+	// 認証・認可のエラー。
+	"error.cannot_find_cookie":           "cannot find cookie",
+	"error.wrong_cookie_value":           "wrong cookie value found",
+	"error.session_not_verified":         "session was not verified",
+	"error.invalid_authorization_header": "invalid authorization header format",
+	"error.api_key_not_verified":         "api key was not verified",
+	"error.insufficient_scope":           "api key does not have the required scope [{scope}]",
+	"error.session_required":             "this endpoint requires a session",
+	"error.permission_denied":            "permission denied",
+	"error.csrf_token_mismatch":          "csrf token mismatch",
+	"error.account_disabled":             "account is disabled",
+	"error.invalid_credentials":          "email or password is incorrect",
+	"error.invalid_password":             "invalid password",
+	"error.invalid_token":                "invalid or expired token",
+
+	// 2段階認証のエラー。
+	"error.two_factor_already_enabled":        "two-factor authentication is already enabled",
+	"error.two_factor_not_started":            "two-factor setup has not been started",
+	"error.invalid_two_factor_code":           "invalid two-factor code",
+	"error.invalid_recovery_code":             "invalid recovery code",
+	"error.two_factor_challenge_not_found":    "two-factor challenge was not found",
+	"error.two_factor_challenge_not_verified": "two-factor challenge was not verified",
+	"error.two_factor_attempts_exceeded":      "too many failed two-factor attempts; please log in again",
+
+	// リソースに関するエラー。
+	"error.user_not_found":           "user was not found",
+	"error.api_key_not_found":        "api key was not found",
+	"error.category_not_found":       "category was not found",
+	"error.expense_not_found":        "expense was not found",
+	"error.receipt_not_found":        "receipt was not found",
+	"error.food_not_found":           "food was not found",
+	"error.category_already_exists":  "category already exists",
+	"error.category_in_use":          "category is still in use",
+	"error.food_in_use":              "food is still in use",
+	"error.precondition_failed":      "resource was modified by another request",
+	"error.precondition_required":    "If-Match header is required",
+	"error.possible_duplicate":       "the same record was registered recently; send force=true to register it anyway",
+	"error.email_already_registered": "The Email has already registered.",
+	"error.mail_unavailable":         "Email delivery is not configured on this server.",
+	"error.cannot_disable_self":      "cannot disable your own account",
+
+	// リクエストに関するエラー。
+	"error.invalid_request":             "request is malformed",
+	"error.invalid_idempotency_key":     "Idempotency-Key must be at most 255 characters",
+	"error.idempotency_key_reused":      "Idempotency-Key was already used for a different request",
+	"error.idempotency_key_in_progress": "a request with the same Idempotency-Key is still in progress",
+	"error.validation_failed":           "request validation failed",
+	"error.route_not_found":             "route was not found",
+	"error.method_not_allowed":          "method is not allowed",
+	"error.internal":                    "internal server error",
+
+	// 項目ごとのバリデーションのエラー。
+	"validation.required":         "{field} is required",
+	"validation.required_without": "{field} is required when {param} is not given",
+	"validation.email":            "{field} must be a valid email address",
+	"validation.min":              "{field} must be at least {param}",
+	"validation.max":              "{field} must be at most {param}",
+	"validation.ne":               "{field} must not be {param}",
+	"validation.len":              "{field} must be exactly {param} long",
+	"validation.oneof":            "{field} must be one of [{param}]",
+	"validation.alpha":            "{field} must contain only letters",
+	"validation.numeric":          "{field} must be numeric",
+	"validation.uppercase":        "{field} must be uppercase",
+	"validation.timezone":         "{field} must be a valid time zone",
+	"validation.uuid":             "{field} must be a uuid",
+	"validation.future":           "{field} must be in the future",
+	"validation.type":             "{field} must be of type {param}",
+	"validation.invalid":          "{field} is invalid",
+
+	// 標準のカテゴリー名。
+	"category.food":              "Food",
+	"category.daily_necessities": "Daily necessities",
+	"category.transportation":    "Transportation",
+	"category.entertainment":     "Entertainment",
+	"category.utilities":         "Utilities",
+	"category.rent":              "Rent",
+	"category.medical":           "Medical",
+	"category.education":         "Education",
+	"category.clothing":          "Clothing",
+	"category.other":             "Other",
+
+	// メールの件名と本文。
+	"mail.email_change.subject": "Confirm your new email address",
+	"mail.email_change.body":    "Enter the following code to finish changing your email address.\n\n{token}\n\nThe code expires in {hours} hours.",
+}
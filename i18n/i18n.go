@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 対応している言語。
+// DBの users.locale に保存する値と一致させる。
+const (
+	Japanese = "ja"
+	English  = "en"
+)
+
+// Accept-Language もプロフィールの言語設定も使えない場合の言語。
+// 翻訳を導入する前の英語のメッセージと互換性を保つため、英語とする。
+const DefaultLocale = English
+
+// 言語ごとのメッセージのカタログ。
+var catalogs = map[string]map[string]string{
+	Japanese: japanese,
+	English:  english,
+}
+
+// 対応している言語か判定する。
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// 指定した言語のカタログにキーが存在するか判定する。
+func Has(locale string, key string) bool {
+	_, ok := catalogs[locale][key]
+	return ok
+}
+
+// 指定した言語のカタログに含まれるキーを、ソートして返す。
+func Keys(locale string) []string {
+	keys := make([]string, 0, len(catalogs[locale]))
+	for key := range catalogs[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// キーに対応するメッセージを、指定した言語で返す。
+//
+// args には埋め込む値を名前と値の組で渡し、メッセージ中の {名前} を置き換える。
+// 指定した言語にキーがない場合は既定の言語で、それもない場合はキーをそのまま返す。
+func T(locale string, key string, args ...string) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// Accept-Language ヘッダーから、対応している言語のうち最も優先度の高いものを返す。
+// 対応している言語が含まれない場合は false を返す。
+func ParseAcceptLanguage(header string) (string, bool) {
+	best := ""
+	bestQ := 0.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		// en-US のような地域の指定は無視し、言語のみで判定する。
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.Index(tag, "-"); i >= 0 {
+			tag = tag[:i]
+		}
+		if !IsSupported(tag) {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				v = 0
+			}
+			q = v
+		}
+		// 同じ優先度の場合は、先に書かれたものを優先する。
+		if q > bestQ {
+			best = tag
+			bestQ = q
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,125 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 全ての言語のカタログに、同じキーが揃っていること。
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for locale := range catalogs {
+		for other := range catalogs {
+			for _, key := range Keys(locale) {
+				require.Truef(t, Has(other, key), "key [%s] in [%s] is missing from [%s]", key, locale, other)
+			}
+		}
+	}
+}
+
+var placeholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// 翻訳によって、埋め込む値が失われないこと。
+func TestCatalogsHaveSamePlaceholders(t *testing.T) {
+	for _, key := range Keys(DefaultLocale) {
+		want := placeholders(T(DefaultLocale, key))
+		for locale := range catalogs {
+			message := catalogs[locale][key]
+			require.NotEmptyf(t, message, "key [%s] in [%s] is empty", key, locale)
+			require.Equalf(t, want, placeholders(message), "placeholders of key [%s] in [%s] differ", key, locale)
+		}
+	}
+}
+
+func placeholders(message string) []string {
+	found := placeholderPattern.FindAllString(message, -1)
+	sort.Strings(found)
+	return found
+}
+
+func TestT(t *testing.T) {
+	testCases := []struct {
+		name   string
+		locale string
+		key    string
+		args   []string
+		want   string
+	}{
+		{
+			name:   "Japanese",
+			locale: Japanese,
+			key:    "error.permission_denied",
+			want:   "権限がありません",
+		},
+		{
+			name:   "English",
+			locale: English,
+			key:    "error.permission_denied",
+			want:   "permission denied",
+		},
+		{
+			name:   "WithArgs",
+			locale: Japanese,
+			key:    "validation.min",
+			args:   []string{"field", "password", "param", "6"},
+			want:   "passwordは6以上で入力してください",
+		},
+		{
+			name:   "UnsupportedLocaleFallsBackToDefault",
+			locale: "fr",
+			key:    "error.permission_denied",
+			want:   "permission denied",
+		},
+		{
+			name:   "MissingKeyReturnsKey",
+			locale: Japanese,
+			key:    "error.unknown",
+			want:   "error.unknown",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			got := T(tc.locale, tc.key, tc.args...)
+
+			// Assert
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		want   string
+		ok     bool
+	}{
+		{name: "Simple", header: "ja", want: Japanese, ok: true},
+		{name: "WithRegion", header: "en-US", want: English, ok: true},
+		{name: "Quality", header: "ja;q=0.5, en;q=0.8", want: English, ok: true},
+		{name: "FirstWinsOnTie", header: "en, ja", want: English, ok: true},
+		{name: "SkipUnsupported", header: "fr-FR, de;q=0.9, ja;q=0.7", want: Japanese, ok: true},
+		{name: "ZeroQualityIsNotAcceptable", header: "ja;q=0", want: "", ok: false},
+		{name: "OnlyUnsupported", header: "fr, *;q=0.5", want: "", ok: false},
+		{name: "Empty", header: "", want: "", ok: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			got, ok := ParseAcceptLanguage(tc.header)
+
+			// Assert
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
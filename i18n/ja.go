@@ -0,0 +1,90 @@
+package i18n
+
+// 日本語のメッセージ。
+// キーを追加する場合は、英語のカタログにも同じキーを追加すること。
+var japanese = map[string]string{
+	// 認証・認可のエラー。
+	"error.cannot_find_cookie":           "Cookieが見つかりません",
+	"error.wrong_cookie_value":           "Cookieの値が正しくありません",
+	"error.session_not_verified":         "セッションが無効です",
+	"error.invalid_authorization_header": "Authorizationヘッダーの形式が正しくありません",
+	"error.api_key_not_verified":         "APIキーが無効です",
+	"error.insufficient_scope":           "APIキーに必要な権限 [{scope}] がありません",
+	"error.session_required":             "この操作にはログインが必要です",
+	"error.permission_denied":            "権限がありません",
+	"error.csrf_token_mismatch":          "CSRFトークンが一致しません",
+	"error.account_disabled":             "アカウントは無効化されています",
+	"error.invalid_credentials":          "メールアドレスまたはパスワードが正しくありません",
+	"error.invalid_password":             "パスワードが正しくありません",
+	"error.invalid_token":                "トークンが無効か、有効期限が切れています",
+
+	// 2段階認証のエラー。
+	"error.two_factor_already_enabled":        "2段階認証はすでに有効です",
+	"error.two_factor_not_started":            "2段階認証の設定が開始されていません",
+	"error.invalid_two_factor_code":           "認証コードが正しくありません",
+	"error.invalid_recovery_code":             "リカバリーコードが正しくありません",
+	"error.two_factor_challenge_not_found":    "2段階認証のチャレンジが見つかりません",
+	"error.two_factor_challenge_not_verified": "2段階認証のチャレンジが無効です",
+	"error.two_factor_attempts_exceeded":      "2段階認証の試行回数が上限に達しました。もう一度ログインしてください",
+
+	// リソースに関するエラー。
+	"error.user_not_found":           "ユーザーが見つかりません",
+	"error.api_key_not_found":        "APIキーが見つかりません",
+	"error.category_not_found":       "カテゴリーが見つかりません",
+	"error.expense_not_found":        "支出が見つかりません",
+	"error.receipt_not_found":        "レシートが見つかりません",
+	"error.food_not_found":           "食品が見つかりません",
+	"error.category_already_exists":  "カテゴリーはすでに存在します",
+	"error.category_in_use":          "カテゴリーは使用されています",
+	"error.food_in_use":              "食品は使用されています",
+	"error.precondition_failed":      "他のリクエストによって更新されています",
+	"error.precondition_required":    "If-Match ヘッダーが必要です",
+	"error.possible_duplicate":       "同じ内容が最近登録されています。登録する場合は force=true を指定してください",
+	"error.email_already_registered": "メールアドレスはすでに登録されています",
+	"error.mail_unavailable":         "このサーバーではメールを送信できません",
+	"error.cannot_disable_self":      "自分のアカウントは無効化できません",
+
+	// リクエストに関するエラー。
+	"error.invalid_request":             "リクエストの形式が正しくありません",
+	"error.invalid_idempotency_key":     "Idempotency-Key は255文字以内で指定してください",
+	"error.idempotency_key_reused":      "Idempotency-Key はすでに別のリクエストで使用されています",
+	"error.idempotency_key_in_progress": "同じ Idempotency-Key のリクエストを処理中です",
+	"error.validation_failed":           "入力内容に誤りがあります",
+	"error.route_not_found":             "エンドポイントが見つかりません",
+	"error.method_not_allowed":          "許可されていないメソッドです",
+	"error.internal":                    "サーバーでエラーが発生しました",
+
+	// 項目ごとのバリデーションのエラー。
+	"validation.required":         "{field}は必須です",
+	"validation.required_without": "{param}を指定しない場合、{field}は必須です",
+	"validation.email":            "{field}はメールアドレスの形式で入力してください",
+	"validation.min":              "{field}は{param}以上で入力してください",
+	"validation.max":              "{field}は{param}以下で入力してください",
+	"validation.ne":               "{field}は{param}以外を入力してください",
+	"validation.len":              "{field}は{param}文字で入力してください",
+	"validation.oneof":            "{field}は[{param}]のいずれかを入力してください",
+	"validation.alpha":            "{field}は英字のみで入力してください",
+	"validation.numeric":          "{field}は数字で入力してください",
+	"validation.uppercase":        "{field}は大文字で入力してください",
+	"validation.timezone":         "{field}は正しいタイムゾーンを入力してください",
+	"validation.uuid":             "{field}はUUIDの形式で入力してください",
+	"validation.future":           "{field}は未来の日時を入力してください",
+	"validation.type":             "{field}は{param}型で入力してください",
+	"validation.invalid":          "{field}が正しくありません",
+
+	// 標準のカテゴリー名。
+	"category.food":              "食費",
+	"category.daily_necessities": "日用品",
+	"category.transportation":    "交通費",
+	"category.entertainment":     "娯楽",
+	"category.utilities":         "水道光熱費",
+	"category.rent":              "家賃",
+	"category.medical":           "医療費",
+	"category.education":         "教育費",
+	"category.clothing":          "衣服",
+	"category.other":             "その他",
+
+	// メールの件名と本文。
+	"mail.email_change.subject": "メールアドレス変更の確認",
+	"mail.email_change.body":    "メールアドレスの変更を完了するには、以下の確認コードを入力してください。\n\n{token}\n\n有効期限は{hours}時間以内です。",
+}
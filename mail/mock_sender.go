@@ -0,0 +1,24 @@
+package mail
+
+import (
+	"context"
+)
+
+// テスト用に、送信したメールを保持する Sender。
+type MockSender struct {
+	To        string
+	Subject   string
+	Body      string
+	SendError error
+}
+
+func NewMockSender() *MockSender {
+	return &MockSender{}
+}
+
+func (sender *MockSender) Send(ctx context.Context, to string, subject string, body string) error {
+	sender.To = to
+	sender.Subject = subject
+	sender.Body = body
+	return sender.SendError
+}
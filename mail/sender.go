@@ -0,0 +1,10 @@
+package mail
+
+import (
+	"context"
+)
+
+// メールを送信するためのインターフェース。
+type Sender interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPサーバーを経由してメールを送信する Sender。
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// SMTPSender を作成する。
+// username が空の場合は認証せずに送信する。
+func NewSMTPSender(host string, port int, username string, password string, from string) *SMTPSender {
+	sender := &SMTPSender{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+	}
+	if username != "" {
+		sender.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return sender
+}
+
+// メールを送信する。
+// net/smtp は context に対応していないため、ctx がキャンセルされていない場合のみ送信を始める。
+func (sender *SMTPSender) Send(ctx context.Context, to string, subject string, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg, err := buildMessage(sender.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(sender.addr, sender.auth, sender.from, []string{to}, msg)
+}
+
+// ヘッダーと本文を組み立てる。
+// ヘッダーを差し込まれないよう、改行を含むアドレスは受け付けない。
+func buildMessage(from string, to string, subject string, body string) ([]byte, error) {
+	if strings.ContainsAny(from+to, "\r\n") {
+		return nil, errors.New("mail address must not contain line breaks")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	testCases := []struct {
+		name    string
+		to      string
+		subject string
+		check   func(t *testing.T, msg string, err error)
+	}{
+		{
+			name:    "OK",
+			to:      "to@example.com",
+			subject: "メールアドレス変更の確認",
+			check: func(t *testing.T, msg string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, msg, "To: to@example.com\r\n")
+				require.Contains(t, msg, "Subject: =?UTF-8?b?")
+				require.True(t, strings.HasSuffix(msg, "\r\n\r\nline1\r\nline2"))
+			},
+		},
+		{
+			name:    "HeaderInjection",
+			to:      "to@example.com\r\nBcc: attacker@example.com",
+			subject: "subject",
+			check: func(t *testing.T, msg string, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			msg, err := buildMessage("from@example.com", tc.to, tc.subject, "line1\nline2")
+
+			// Assert
+			tc.check(t, string(msg), err)
+		})
+	}
+}
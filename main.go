@@ -1,40 +1,201 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/kokoichi206/account-book-api/api"
 	"github.com/kokoichi206/account-book-api/auth"
+	memdb "github.com/kokoichi206/account-book-api/db/memory"
 	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	sqlitedb "github.com/kokoichi206/account-book-api/db/sqlite"
+	"github.com/kokoichi206/account-book-api/metrics"
 	"github.com/kokoichi206/account-book-api/util"
+	"github.com/kokoichi206/account-book-api/worker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/zap"
 
 	_ "github.com/lib/pq"
 )
 
 func main() {
-	config, err := util.LoadConfig(".")
+	demo := flag.Bool("demo", false, "use an in-memory database seeded with sample data instead of PostgreSQL")
+	flag.Parse()
+
+	var err error
+	if flag.Arg(0) == "migrate" {
+		err = runMigrate(flag.Args()[1:])
+	} else {
+		err = run(*demo)
+	}
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		log.Fatal(err)
 	}
+}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
+// サーバーを起動し、SIGINT か SIGTERM を受け取るまで処理を続ける。
+// log.Fatal では defer が実行されないため、終了処理は全てこの関数の中で行う。
+// demo が true の場合は、DBに接続せずにサンプルデータを登録したメモリ上のDBを使う。
+func run(demo bool) error {
+	config, err := util.LoadConfig(".")
 	if err != nil {
-		log.Fatal("Cannot connect to db: ", err)
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+	// デモのデータは終了時に消えるため、鍵も起動ごとに生成したもので足りる。
+	if demo && config.TOTPEncryptionKey == "" {
+		config.TOTPEncryptionKey, err = util.GenerateEncryptionKey()
+		if err != nil {
+			return fmt.Errorf("cannot generate TOTP encryption key: %w", err)
+		}
+	}
+	if err := config.ValidateServer(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	querier := db.New(conn)
-	manager := auth.NewManager(querier)
-
-	logger := util.InitLogger()
+	logger, err := util.NewLogger(config)
+	if err != nil {
+		return fmt.Errorf("cannot create logger: %w", err)
+	}
 	// globalで上記設定を使えるようにする。
 	zap.ReplaceGlobals(logger)
+	// 終了時に、バッファに残っているログを書き出す。
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	server := api.NewServer(config, querier, manager, logger)
+	var baseStore db.Store
+	// DB_REPLICA_SOURCE を指定した場合のみ作成する。
+	var replicaStore *db.ReplicaStore
+	if demo {
+		memStore := memdb.New()
+		if err := memdb.Seed(ctx, memStore); err != nil {
+			return fmt.Errorf("cannot seed demo data: %w", err)
+		}
+		logger.Warn("running in demo mode: data is kept in memory and lost on exit",
+			zap.String("email", memdb.DemoEmail),
+			zap.String("admin_email", memdb.DemoAdminEmail),
+			zap.String("password", memdb.DemoPassword),
+		)
+		baseStore = memStore
+	} else {
+		conn, err := openDB(config)
+		if err != nil {
+			return fmt.Errorf("cannot connect to db: %w", err)
+		}
+		// 処理中のリクエストとワーカーが終了してから閉じる。
+		defer func() {
+			if err := conn.Close(); err != nil {
+				logger.Error("failed to close db", zap.Error(err))
+			}
+		}()
+
+		if err := autoMigrate(ctx, config, conn, logger); err != nil {
+			return fmt.Errorf("cannot migrate db: %w", err)
+		}
+		baseStore = newStore(config, conn)
+
+		if config.DBReplicaSource != "" {
+			replicaConn, err := sql.Open(config.DBDriver, config.DBReplicaSource)
+			if err != nil {
+				return fmt.Errorf("cannot connect to replica db: %w", err)
+			}
+			defer func() {
+				if err := replicaConn.Close(); err != nil {
+					logger.Error("failed to close replica db", zap.Error(err))
+				}
+			}()
+			replicaStore = db.NewReplicaStore(baseStore, db.NewStore(replicaConn))
+			baseStore = replicaStore
+		}
+	}
 
-	err = server.Start(config.ServerAddress)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	m := metrics.New(registry)
+
+	// 全てのクエリの処理時間を記録し、リクエストごとのLoggerに出力する。
+	store := db.NewInstrumentedStore(baseStore, db.MultiQueryObserver(
+		m.ObserveQuery,
+		db.LogQueries(config.SlowQueryThreshold),
+	))
+	manager := auth.NewManager(store)
+	if err := m.RegisterActiveSessions(store.CountActiveSessions, config.HealthCheckTimeout); err != nil {
+		return fmt.Errorf("cannot register metrics: %w", err)
+	}
+
+	// 削除の猶予期間を過ぎたアカウントを、バックグラウンドで削除する。
+	deletionWorker := worker.NewAccountDeletionWorker(store, config.AccountDeletionInterval, logger)
+	// 保持期間を過ぎたものを、バックグラウンドでゴミ箱から削除する。
+	trashWorker := worker.NewTrashPurgeWorker(store, config.TrashRetentionPeriod, config.TrashPurgeInterval, logger)
+	// 期限を過ぎた Idempotency-Key を、バックグラウンドで削除する。
+	idempotencyWorker := worker.NewIdempotencyKeyPurgeWorker(store, config.IdempotencyPurgeInterval, logger)
+	var workers sync.WaitGroup
+	workers.Add(3)
+	var replicaWorker *worker.ReplicaHealthWorker
+	if replicaStore != nil {
+		// レプリカの異常を検知し、復旧するまで参照をプライマリで実行する。
+		replicaWorker = worker.NewReplicaHealthWorker(replicaStore, config.ReplicaHealthCheckInterval, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			replicaWorker.Run(ctx)
+		}()
+	}
+	go func() {
+		defer workers.Done()
+		deletionWorker.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		trashWorker.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		idempotencyWorker.Run(ctx)
+	}()
+
+	server := api.NewServer(config, store, manager, logger, m)
+	server.RegisterWorker("account_deletion", deletionWorker)
+	server.RegisterWorker("trash_purge", trashWorker)
+	server.RegisterWorker("idempotency_key_purge", idempotencyWorker)
+	if replicaWorker != nil {
+		server.RegisterWorker("replica_health", replicaWorker)
+	}
+
+	err = server.Start(ctx, config.ServerAddress)
+	// サーバーが異常終了した場合も、ワーカーを止めてからDBを閉じる。
+	stop()
+	workers.Wait()
 	if err != nil {
-		log.Fatal("cannot start server:", err)
+		return fmt.Errorf("server stopped with error: %w", err)
+	}
+	return nil
+}
+
+// DB_DRIVER に応じて、DBに接続する。
+func openDB(config util.Config) (*sql.DB, error) {
+	if config.DBDriver == util.DBDriverSQLite {
+		return sqlitedb.Open(config.DBSource)
+	}
+	return sql.Open(config.DBDriver, config.DBSource)
+}
+
+// DB_DRIVER に応じた Store を作成する。
+func newStore(config util.Config, conn *sql.DB) db.Store {
+	if config.DBDriver == util.DBDriverSQLite {
+		return sqlitedb.NewStore(conn)
 	}
+	return db.NewStore(conn)
 }
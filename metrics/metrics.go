@@ -0,0 +1,184 @@
+// Package metrics は、Prometheus 形式のメトリクスを提供する。
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 全てのメトリクス名の接頭辞。
+const namespace = "account_book"
+
+// ルーティングにマッチしなかったリクエストの route ラベル。
+// 存在しないパスをそのままラベルにすると、系列の数が際限なく増えるため。
+const unmatchedRoute = "unmatched"
+
+// ログインに失敗した理由（login_failures_total の reason ラベル）。
+const (
+	LoginFailureUnknownUser               = "unknown_user"
+	LoginFailureWrongPassword             = "wrong_password"
+	LoginFailureAccountDisabled           = "account_disabled"
+	LoginFailureInvalidTwoFactor          = "invalid_two_factor_code"
+	LoginFailureInvalidRecoveryCode       = "invalid_recovery_code"
+	LoginFailureTwoFactorAttemptsExceeded = "two_factor_attempts_exceeded"
+)
+
+// クエリの結果（db_query_duration_seconds の result ラベル）。
+const (
+	queryResultOK    = "ok"
+	queryResultError = "error"
+)
+
+// アプリケーションのメトリクスをまとめた構造体。
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	dbQueryDuration *prometheus.HistogramVec
+	expensesCreated prometheus.Counter
+	receiptsParsed  prometheus.Counter
+	loginFailures   *prometheus.CounterVec
+}
+
+// registry にメトリクスを登録した Metrics を作成する。
+// テストでは prometheus.NewRegistry() で作成した、メモリ上のレジストリを渡す。
+func New(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Latency of database queries by query name and result.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"query", "result"}),
+		expensesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "expenses_created_total",
+			Help:      "Number of expenses created.",
+		}),
+		receiptsParsed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "receipts_parsed_total",
+			Help:      "Number of receipts parsed and stored.",
+		}),
+		loginFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "login_failures_total",
+			Help:      "Number of failed logins by reason.",
+		}, []string{"reason"}),
+	}
+
+	registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.dbQueryDuration,
+		m.expensesCreated,
+		m.receiptsParsed,
+		m.loginFailures,
+	)
+	return m
+}
+
+// /metrics で公開するためのハンドラーを返す。
+// 一部のメトリクスの収集に失敗しても、残りのメトリクスは返す。
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// HTTPリクエストの件数と処理時間を記録する。
+// route にはパスではなく、/users/:id のようなルーティングの定義を渡す。
+func (m *Metrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	labels := prometheus.Labels{
+		"method": method,
+		"route":  route,
+		"status": strconv.Itoa(status),
+	}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// クエリの処理時間を記録する。
+// 該当する行がないことは正常な結果として扱う。
+func (m *Metrics) ObserveQuery(ctx context.Context, query string, duration time.Duration, err error) {
+	result := queryResultOK
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		result = queryResultError
+	}
+	m.dbQueryDuration.WithLabelValues(query, result).Observe(duration.Seconds())
+}
+
+// 支出が作成されたことを記録する。
+func (m *Metrics) ExpenseCreated() {
+	m.expensesCreated.Inc()
+}
+
+// レシートが読み込まれたことを記録する。
+func (m *Metrics) ReceiptParsed() {
+	m.receiptsParsed.Inc()
+}
+
+// ログインに失敗したことを記録する。reason には LoginFailure で始まる定数を渡す。
+func (m *Metrics) LoginFailed(reason string) {
+	m.loginFailures.WithLabelValues(reason).Inc()
+}
+
+// 有効なセッションの数を、収集のたびに count で取得して公開する。
+func (m *Metrics) RegisterActiveSessions(count func(ctx context.Context) (int64, error), timeout time.Duration) error {
+	return m.registry.Register(&activeSessionsCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active_sessions"),
+			"Number of sessions that have not expired.",
+			nil, nil,
+		),
+		count:   count,
+		timeout: timeout,
+	})
+}
+
+// 有効なセッションの数を、DBから取得して公開する。
+// ログアウトせずに期限切れになったセッションがあるため、件数の増減ではなくDBの値を使う。
+type activeSessionsCollector struct {
+	desc    *prometheus.Desc
+	count   func(ctx context.Context) (int64, error)
+	timeout time.Duration
+}
+
+func (collector *activeSessionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+func (collector *activeSessionsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collector.timeout)
+	defer cancel()
+
+	n, err := collector.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.desc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(n))
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	// Arrange
+	m := New(prometheus.NewRegistry())
+
+	// Act
+	m.ObserveHTTPRequest(http.MethodGet, "/users/:id", http.StatusOK, 10*time.Millisecond)
+	m.ObserveHTTPRequest(http.MethodGet, "/users/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+
+	// Assert
+	require.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/users/:id", "200")))
+	// マッチしなかったパスは、1つの系列にまとめること。
+	require.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	require.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestObserveQuery(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantResult string
+	}{
+		{name: "OK", err: nil, wantResult: queryResultOK},
+		{name: "NoRows", err: sql.ErrNoRows, wantResult: queryResultOK},
+		{name: "Error", err: sql.ErrConnDone, wantResult: queryResultError},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			m := New(prometheus.NewRegistry())
+
+			// Act
+			m.ObserveQuery(context.Background(), "GetUser", time.Millisecond, tc.err)
+
+			// Assert
+			expected := `
+# HELP account_book_db_query_duration_seconds Latency of database queries by query name and result.
+# TYPE account_book_db_query_duration_seconds histogram
+`
+			require.NoError(t, testutil.CollectAndCompare(m.dbQueryDuration, strings.NewReader(expected+histogramLines(tc.wantResult)), "account_book_db_query_duration_seconds"))
+		})
+	}
+}
+
+// ObserveQuery に 1ms を1回記録した場合のヒストグラム。
+func histogramLines(result string) string {
+	labels := `query="GetUser",result="` + result + `"`
+	lines := ""
+	for _, le := range []string{"0.001", "0.0025", "0.005", "0.01", "0.025", "0.05", "0.1", "0.25", "0.5", "1", "2.5", "+Inf"} {
+		lines += "account_book_db_query_duration_seconds_bucket{" + labels + `,le="` + le + "\"} 1\n"
+	}
+	lines += "account_book_db_query_duration_seconds_sum{" + labels + "} 0.001\n"
+	lines += "account_book_db_query_duration_seconds_count{" + labels + "} 1\n"
+	return lines
+}
+
+func TestBusinessCounters(t *testing.T) {
+	// Arrange
+	m := New(prometheus.NewRegistry())
+
+	// Act
+	m.ExpenseCreated()
+	m.ExpenseCreated()
+	m.ReceiptParsed()
+	m.LoginFailed(LoginFailureWrongPassword)
+
+	// Assert
+	require.Equal(t, 2.0, testutil.ToFloat64(m.expensesCreated))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.receiptsParsed))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.loginFailures.WithLabelValues(LoginFailureWrongPassword)))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.loginFailures.WithLabelValues(LoginFailureUnknownUser)))
+}
+
+func TestActiveSessions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		count         func(ctx context.Context) (int64, error)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			count: func(ctx context.Context) (int64, error) {
+				return 3, nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "account_book_active_sessions 3\n")
+			},
+		},
+		{
+			name: "CountFailed",
+			count: func(ctx context.Context) (int64, error) {
+				return 0, errors.New("db is down")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// 他のメトリクスは返すこと。
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "account_book_active_sessions")
+				require.Contains(t, recorder.Body.String(), "account_book_expenses_created_total 0\n")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			m := New(prometheus.NewRegistry())
+			require.NoError(t, m.RegisterActiveSessions(tc.count, time.Second))
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+			require.NoError(t, err)
+
+			// Act
+			m.Handler().ServeHTTP(recorder, request)
+
+			// Assert
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/kokoichi206/account-book-api/db/migration"
+	"github.com/kokoichi206/account-book-api/util"
+	"go.uber.org/zap"
+)
+
+const migrateUsage = "usage: migrate up|down|status|goto N"
+
+// migrate サブコマンドを実行する。
+//
+//	migrate up      最新のバージョンまで適用する
+//	migrate down    最後に適用したマイグレーションを１つ取り消す
+//	migrate status  適用済みのバージョンと未適用のマイグレーションを表示する
+//	migrate goto N  バージョン N まで適用または取り消す（0 で全て取り消す）
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+	logger, err := util.NewLogger(config)
+	if err != nil {
+		return fmt.Errorf("cannot create logger: %w", err)
+	}
+	defer logger.Sync()
+
+	conn, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer conn.Close()
+
+	migrator, err := migration.NewMigrator(conn, migrationDialect(config), logger)
+	if err != nil {
+		return fmt.Errorf("cannot load migrations: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(status)
+		return nil
+	case "goto":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version [%s]: %w", args[1], err)
+		}
+		return migrator.Goto(ctx, uint(version))
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func printMigrationStatus(status migration.Status) {
+	fmt.Printf("current: %d\n", status.Current)
+	fmt.Printf("latest:  %d\n", status.Latest)
+	if status.Dirty {
+		fmt.Println("dirty:   true")
+	}
+	if len(status.Pending) == 0 {
+		fmt.Println("pending: none")
+		return
+	}
+	fmt.Printf("pending: %v\n", status.Pending)
+}
+
+// AUTO_MIGRATE が有効な場合に、起動時に未適用のマイグレーションを適用する。
+// PostgreSQL の場合は、複数のレプリカが同時に起動しても advisory lock により１つずつ実行される。
+func autoMigrate(ctx context.Context, config util.Config, conn *sql.DB, logger *zap.Logger) error {
+	if !config.AutoMigrate {
+		return nil
+	}
+	migrator, err := migration.NewMigrator(conn, migrationDialect(config), logger)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(ctx)
+}
+
+// DB_DRIVER に応じたマイグレーションのSQLファイルの種類。
+func migrationDialect(config util.Config) migration.Dialect {
+	if config.DBDriver == util.DBDriverSQLite {
+		return migration.SQLite
+	}
+	return migration.PostgreSQL
+}
@@ -1,16 +1,103 @@
 package util
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// DB_DRIVER に指定できる値。
+const (
+	DBDriverPostgres = "postgres"
+	// １人で使う場合に、PostgreSQL の代わりに使う。
+	DBDriverSQLite = "sqlite3"
+)
+
 type Config struct {
+	// 使うDB（postgres, sqlite3）。
 	DBDriver        string        `mapstructure:"DB_DRIVER"`
 	DBSource        string        `mapstructure:"DB_SOURCE"`
 	ServerAddress   string        `mapstructure:"SERVER_ADDRESS"`
 	SessionDuration time.Duration `mapstructure:"SESSION_DURATION"`
+	// 一覧などの参照のみのクエリを実行するレプリカ（postgres のみ）。
+	// 未指定の場合は、全てのクエリを DB_SOURCE で実行する。
+	DBReplicaSource string `mapstructure:"DB_REPLICA_SOURCE"`
+	// レプリカに接続できるかを確認する間隔。異常の間はプライマリで実行する。
+	ReplicaHealthCheckInterval time.Duration `mapstructure:"REPLICA_HEALTH_CHECK_INTERVAL"`
+	// 更新したユーザーの参照を、レプリカの遅延に関わらずプライマリで実行する期間。
+	ReadYourWritesWindow time.Duration `mapstructure:"READ_YOUR_WRITES_WINDOW"`
+	// TOTPの秘密鍵を暗号化するための鍵（hex形式の32byte）。
+	// リポジトリには含めず、環境変数などで渡す。サーバーの起動時のみ必須とする。
+	TOTPEncryptionKey string `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	// セッションを保持するCookieの名前。
+	CookieName string `mapstructure:"COOKIE_NAME"`
+	// CookieのDomain属性。未指定の場合はリクエスト先のホストのみに送信される。
+	CookieDomain string `mapstructure:"COOKIE_DOMAIN"`
+	// CookieのSecure属性。HTTPのlocalhostで開発する時のみfalseにする。
+	CookieSecure bool `mapstructure:"COOKIE_SECURE"`
+	// CookieのSameSite属性（lax, strict, none）。未指定の場合は付与しない。
+	CookieSameSite string `mapstructure:"COOKIE_SAMESITE"`
+	// メールを送信するSMTPサーバーのホスト。未指定の場合はメールアドレスの変更を受け付けない。
+	SMTPHost string `mapstructure:"SMTP_HOST"`
+	// SMTPサーバーのポート。
+	SMTPPort int `mapstructure:"SMTP_PORT"`
+	// SMTPサーバーの認証に使うユーザー名。未指定の場合は認証しない。
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	// 送信するメールの From。
+	MailFrom string `mapstructure:"MAIL_FROM"`
+	// アカウントの削除を受け付けてから、実際に個人データを削除するまでの猶予期間。
+	AccountDeletionGracePeriod time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	// 猶予期間を過ぎたアカウントを削除するバックグラウンド処理の実行間隔。
+	AccountDeletionInterval time.Duration `mapstructure:"ACCOUNT_DELETION_INTERVAL"`
+	// ゴミ箱に移した支出などを、完全に削除するまでの保持期間。
+	TrashRetentionPeriod time.Duration `mapstructure:"TRASH_RETENTION_PERIOD"`
+	// 保持期間を過ぎたものをゴミ箱から削除するバックグラウンド処理の実行間隔。
+	TrashPurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
+	// Idempotency-Key ごとにレスポンスを保存し、再送時に返す期間。
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	// 期限を過ぎた Idempotency-Key を削除するバックグラウンド処理の実行間隔。
+	IdempotencyPurgeInterval time.Duration `mapstructure:"IDEMPOTENCY_PURGE_INTERVAL"`
+	// 同じ内容の支出・レシートを、二重登録の疑いがあるとみなす期間。
+	DuplicateWindow time.Duration `mapstructure:"DUPLICATE_WINDOW"`
+	// /docs で Swagger UI を提供するか。
+	SwaggerUIEnabled bool `mapstructure:"SWAGGER_UI_ENABLED"`
+	// リクエスト全体（ボディを含む）を読み込むまでのタイムアウト。
+	HTTPReadTimeout time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	// リクエストのヘッダーを読み込むまでのタイムアウト。
+	HTTPReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	// レスポンスを書き込み終えるまでのタイムアウト。
+	HTTPWriteTimeout time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	// Keep-Alive の接続で、次のリクエストを待つ時間。
+	HTTPIdleTimeout time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	// 終了のシグナルを受け取ってから、処理中のリクエストの完了を待つ時間。
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// readyz でDBなどの依存先を確認する際のタイムアウト。
+	HealthCheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	// これより時間のかかったクエリを warn レベルでログに出力する。
+	SlowQueryThreshold time.Duration `mapstructure:"SLOW_QUERY_THRESHOLD"`
+	// 出力するログの最低レベル（debug, info, warn, error）。
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+	// ログのエンコーディング（json, console）。
+	LogEncoding string `mapstructure:"LOG_ENCODING"`
+	// 同じメッセージのログを、1秒ごとに最初の何件まで出力するか。0の場合はサンプリングしない。
+	LogSamplingInitial int `mapstructure:"LOG_SAMPLING_INITIAL"`
+	// LOG_SAMPLING_INITIAL を超えた後、何件ごとに1件出力するか。0の場合は出力しない。
+	LogSamplingThereafter int `mapstructure:"LOG_SAMPLING_THEREAFTER"`
+	// ログを出力するファイル。未指定の場合は標準出力に出力する。
+	LogFile string `mapstructure:"LOG_FILE"`
+	// ログのファイルをローテーションするサイズ（MB）。
+	LogMaxSizeMB int `mapstructure:"LOG_MAX_SIZE_MB"`
+	// ローテーションした古いファイルを残す数。0の場合は数では削除しない。
+	LogMaxBackups int `mapstructure:"LOG_MAX_BACKUPS"`
+	// ローテーションした古いファイルを残す日数。0の場合は日数では削除しない。
+	LogMaxAgeDays int `mapstructure:"LOG_MAX_AGE_DAYS"`
+	// 起動時に、未適用のマイグレーションを適用するか。
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -18,6 +105,27 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("COOKIE_NAME", "session")
+	viper.SetDefault("COOKIE_SECURE", true)
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour)
+	viper.SetDefault("ACCOUNT_DELETION_INTERVAL", time.Hour)
+	viper.SetDefault("TRASH_RETENTION_PERIOD", 30*24*time.Hour)
+	viper.SetDefault("TRASH_PURGE_INTERVAL", time.Hour)
+	viper.SetDefault("HTTP_READ_TIMEOUT", 10*time.Second)
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT", 5*time.Second)
+	// データのエクスポートなど、レスポンスが大きいものがあるため長めにする。
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", 2*time.Minute)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 20*time.Second)
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+	viper.SetDefault("SLOW_QUERY_THRESHOLD", 200*time.Millisecond)
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_ENCODING", LogEncodingJSON)
+	viper.SetDefault("LOG_MAX_SIZE_MB", 100)
+	viper.SetDefault("LOG_MAX_BACKUPS", 7)
+	viper.SetDefault("LOG_MAX_AGE_DAYS", 28)
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
@@ -26,5 +134,167 @@ func LoadConfig(path string) (config Config, err error) {
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	err = config.Validate()
 	return
 }
+
+// 設定値が正しいか確かめる。
+// 起動してからCookieが保存されないなどの不具合に気づくことがないよう、起動時に呼び出す。
+func (config Config) Validate() error {
+	if config.DBDriver != DBDriverPostgres && config.DBDriver != DBDriverSQLite {
+		return fmt.Errorf("invalid DB_DRIVER [%s]", config.DBDriver)
+	}
+
+	if config.DBReplicaSource != "" {
+		if config.DBDriver != DBDriverPostgres {
+			return fmt.Errorf("DB_REPLICA_SOURCE is not supported with DB_DRIVER [%s]", config.DBDriver)
+		}
+		if config.ReplicaHealthCheckInterval <= 0 {
+			return errors.New("REPLICA_HEALTH_CHECK_INTERVAL must be positive")
+		}
+		if config.ReadYourWritesWindow <= 0 {
+			return errors.New("READ_YOUR_WRITES_WINDOW must be positive")
+		}
+	}
+
+	if config.SMTPHost != "" {
+		if config.SMTPPort <= 0 || config.SMTPPort > 65535 {
+			return fmt.Errorf("invalid SMTP_PORT [%d]", config.SMTPPort)
+		}
+		if config.MailFrom == "" {
+			return errors.New("MAIL_FROM is required when SMTP_HOST is set")
+		}
+	}
+
+	if config.SessionDuration <= 0 {
+		return errors.New("SESSION_DURATION must be positive")
+	}
+
+	if config.AccountDeletionGracePeriod < 0 {
+		return errors.New("ACCOUNT_DELETION_GRACE_PERIOD must not be negative")
+	}
+	if config.AccountDeletionInterval <= 0 {
+		return errors.New("ACCOUNT_DELETION_INTERVAL must be positive")
+	}
+
+	// 0 の場合は、ゴミ箱に移した直後に削除されて復元できなくなるため許可しない。
+	if config.TrashRetentionPeriod <= 0 {
+		return errors.New("TRASH_RETENTION_PERIOD must be positive")
+	}
+	if config.TrashPurgeInterval <= 0 {
+		return errors.New("TRASH_PURGE_INTERVAL must be positive")
+	}
+	if config.IdempotencyKeyTTL <= 0 {
+		return errors.New("IDEMPOTENCY_KEY_TTL must be positive")
+	}
+	if config.IdempotencyPurgeInterval <= 0 {
+		return errors.New("IDEMPOTENCY_PURGE_INTERVAL must be positive")
+	}
+	if config.DuplicateWindow <= 0 {
+		return errors.New("DUPLICATE_WINDOW must be positive")
+	}
+
+	// 0 はタイムアウトなしを意味するため、遅いクライアントに接続を占有されないよう許可しない。
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", config.HTTPReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", config.HTTPReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", config.HTTPWriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", config.HTTPIdleTimeout},
+		{"SHUTDOWN_TIMEOUT", config.ShutdownTimeout},
+		{"HEALTH_CHECK_TIMEOUT", config.HealthCheckTimeout},
+		{"SLOW_QUERY_THRESHOLD", config.SlowQueryThreshold},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			return fmt.Errorf("%s must be positive", timeout.name)
+		}
+	}
+
+	if _, err := ParseLogLevel(config.LogLevel); err != nil {
+		return err
+	}
+	if config.LogEncoding != LogEncodingJSON && config.LogEncoding != LogEncodingConsole {
+		return fmt.Errorf("invalid LOG_ENCODING [%s]", config.LogEncoding)
+	}
+	if config.LogSamplingInitial < 0 || config.LogSamplingThereafter < 0 {
+		return errors.New("LOG_SAMPLING_INITIAL and LOG_SAMPLING_THEREAFTER must not be negative")
+	}
+	if config.LogFile != "" && config.LogMaxSizeMB <= 0 {
+		return errors.New("LOG_MAX_SIZE_MB must be positive")
+	}
+	if config.LogMaxBackups < 0 || config.LogMaxAgeDays < 0 {
+		return errors.New("LOG_MAX_BACKUPS and LOG_MAX_AGE_DAYS must not be negative")
+	}
+
+	// 必須かどうかは ValidateServer で確かめる。
+	if config.TOTPEncryptionKey != "" {
+		if _, err := DecodeEncryptionKey(config.TOTPEncryptionKey); err != nil {
+			return fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: %w", err)
+		}
+	}
+
+	if !isCookieToken(config.CookieName) {
+		return fmt.Errorf("invalid COOKIE_NAME [%s]", config.CookieName)
+	}
+
+	// ホスト名のみを指定する（ポートやスキームを含めるとブラウザに無視される）。
+	if strings.ContainsAny(config.CookieDomain, ":/ ") {
+		return fmt.Errorf("COOKIE_DOMAIN must be a host name without scheme or port, got [%s]", config.CookieDomain)
+	}
+
+	sameSite, err := ParseSameSite(config.CookieSameSite)
+	if err != nil {
+		return err
+	}
+	// SameSite=None は Secure でないとブラウザに拒否される。
+	if sameSite == http.SameSiteNoneMode && !config.CookieSecure {
+		return errors.New("COOKIE_SAMESITE=none requires COOKIE_SECURE=true")
+	}
+
+	return nil
+}
+
+// サーバーの起動時にのみ必要な設定を確かめる。
+// マイグレーションなど、サーバーを起動しないコマンドでは確かめない。
+func (config Config) ValidateServer() error {
+	if config.TOTPEncryptionKey == "" {
+		return errors.New("TOTP_ENCRYPTION_KEY is required (generate one with `openssl rand -hex 32`)")
+	}
+	return nil
+}
+
+// 設定ファイルのSameSiteの値を、http.SameSite に変換する。
+// 未指定の場合はブラウザのデフォルトの挙動に任せる。
+func ParseSameSite(s string) (http.SameSite, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return http.SameSiteDefaultMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return http.SameSiteDefaultMode, fmt.Errorf("invalid COOKIE_SAMESITE [%s]", s)
+}
+
+// RFC 6265 の cookie-name として使える文字列か判定する。
+func isCookieToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	key, err := DecodeEncryptionKey(hex.EncodeToString([]byte(RandomString(32))))
+	require.NoError(t, err)
+
+	plaintext := RandomString(16)
+
+	ciphertext, err := Encrypt(key, plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, plaintext, ciphertext)
+
+	decrypted, err := Decrypt(key, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// 異なる鍵では復号できないことを確認する。
+	wrongKey, err := DecodeEncryptionKey(hex.EncodeToString([]byte(RandomString(32))))
+	require.NoError(t, err)
+	_, err = Decrypt(wrongKey, ciphertext)
+	require.Error(t, err)
+}
+
+func TestDecodeEncryptionKey(t *testing.T) {
+	_, err := DecodeEncryptionKey("not hex")
+	require.Error(t, err)
+
+	// 鍵長が 32 byte でない場合はエラーとする。
+	_, err = DecodeEncryptionKey(hex.EncodeToString([]byte(RandomString(16))))
+	require.Error(t, err)
+}
+
+func TestHashToken(t *testing.T) {
+	token := RandomString(32)
+
+	hashed := HashToken(token)
+	require.Len(t, hashed, 64)
+	require.Equal(t, hashed, HashToken(token))
+	require.NotEqual(t, hashed, HashToken(RandomString(32)))
+}
+
+func TestGenerateToken(t *testing.T) {
+	token, err := GenerateToken(32)
+	require.NoError(t, err)
+	// base64url（パディングなし）で 32byte は 43 文字になる。
+	require.Len(t, token, 43)
+	require.NotContains(t, token, "=")
+
+	other, err := GenerateToken(32)
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+}
+
+func TestGenerateEncryptionKey(t *testing.T) {
+	key, err := GenerateEncryptionKey()
+	require.NoError(t, err)
+	_, err = DecodeEncryptionKey(key)
+	require.NoError(t, err)
+
+	other, err := GenerateEncryptionKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
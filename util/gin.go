@@ -1,24 +1,54 @@
 package util
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// リクエストの処理が終わるたびに呼び出される関数。メトリクスの記録に使う。
+// route には /users/:id のようなルーティングの定義が渡され、マッチしなかった場合は空文字になる。
+type RequestObserver func(method string, route string, status int, duration time.Duration)
+
 // zapをgin用にカスタマイズしたLogger。
-func GinLogger(logger *zap.Logger) gin.HandlerFunc {
+// ログと同じ値を observe にも渡す（nil の場合は渡さない）。
+// quietPaths のリクエストは、死活監視などで頻繁に呼ばれるため debug レベルで出力する。
+func GinLogger(logger *zap.Logger, observe RequestObserver, quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+
 	return func(c *gin.Context) {
+		start := time.Now()
 		path := c.Request.URL.Path
 		c.Next()
-		logger.Info(path,
+		latency := time.Since(start)
+
+		if observe != nil {
+			observe(c.Request.Method, c.FullPath(), c.Writer.Status(), latency)
+		}
+
+		// リクエストIDなどを含む、リクエストごとのLoggerがあればそれを使う。
+		l, ok := loggerFromContext(c)
+		if !ok {
+			l = logger
+		}
+		log := l.Info
+		if quiet[path] {
+			log = l.Debug
+		}
+		log(path,
 			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", latency),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
 			zap.String("query", c.Request.URL.RawQuery),
@@ -30,7 +60,8 @@ func GinLogger(logger *zap.Logger) gin.HandlerFunc {
 }
 
 // panicから復帰しStatusCode500で返すようなmiddleware。
-func GinRecovery(logger *zap.Logger, stack bool) gin.HandlerFunc {
+// respond には、他のエラーと同じ形式のレスポンスを返す関数を渡す（nil の場合はボディなしで返す）。
+func GinRecovery(logger *zap.Logger, stack bool, respond gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -44,11 +75,16 @@ func GinRecovery(logger *zap.Logger, stack bool) gin.HandlerFunc {
 					}
 				}
 
-				httpRequest, _ := httputil.DumpRequest(c.Request, false)
+				l, ok := loggerFromContext(c)
+				if !ok {
+					l = logger
+				}
+
+				httpRequest := dumpRequest(c.Request)
 				if brokenPipe {
-					logger.Error(c.Request.URL.Path,
+					l.Error(c.Request.URL.Path,
 						zap.Any("error", err),
-						zap.String("request", string(httpRequest)),
+						zap.String("request", httpRequest),
 					)
 					c.Error(err.(error))
 					c.Abort()
@@ -56,20 +92,53 @@ func GinRecovery(logger *zap.Logger, stack bool) gin.HandlerFunc {
 				}
 
 				if stack {
-					logger.Error("[Recovery from panic]",
+					l.Error("[Recovery from panic]",
 						zap.Any("error", err),
-						zap.String("request", string(httpRequest)),
+						zap.String("request", httpRequest),
 						zap.String("stack", string(debug.Stack())),
 					)
 				} else {
-					logger.Error("[Recovery from panic]",
+					l.Error("[Recovery from panic]",
 						zap.Any("error", err),
-						zap.String("request", string(httpRequest)),
+						zap.String("request", httpRequest),
 					)
 				}
+				if respond != nil {
+					respond(c)
+				}
 				c.AbortWithStatus(http.StatusInternalServerError)
 			}
 		}()
 		c.Next()
 	}
 }
+
+// パニックのログに出力する際に、値を伏せるヘッダー。
+// APIキーやセッション、CSRFトークンをログから盗まれないようにする。
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-CSRF-Token"}
+
+// リクエストラインとヘッダーを、認証情報を伏せて文字列にする。
+// ボディにはパスワードなどが含まれるため出力しない。
+func dumpRequest(r *http.Request) string {
+	dump := r.Clone(r.Context())
+	dump.Body = nil
+	for _, key := range redactedHeaders {
+		if _, ok := dump.Header[http.CanonicalHeaderKey(key)]; ok {
+			dump.Header.Set(key, redactedValue)
+		}
+	}
+	httpRequest, _ := httputil.DumpRequest(dump, false)
+	return string(httpRequest)
+}
+
+// ctx が gin.Context の場合は、リクエストの context を返す。
+// gin.Context は文字列以外のキーを参照できないため、context に保持した値はこちらから取得する。
+func RequestContext(ctx context.Context) context.Context {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return context.Background()
+		}
+		return c.Request.Context()
+	}
+	return ctx
+}
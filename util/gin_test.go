@@ -0,0 +1,133 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGinLogger(t *testing.T) {
+	testCases := []struct {
+		name      string
+		path      string
+		wantLevel zapcore.Level
+	}{
+		{name: "Info", path: "/users", wantLevel: zapcore.InfoLevel},
+		{name: "QuietPath", path: "/healthz", wantLevel: zapcore.DebugLevel},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			core, logs := observer.New(zapcore.DebugLevel)
+			router := gin.New()
+			router.Use(GinLogger(zap.New(core), nil, "/healthz"))
+			router.GET(tc.path, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			// Act
+			router.ServeHTTP(httptest.NewRecorder(), request)
+
+			// Assert
+			entries := logs.All()
+			require.Len(t, entries, 1)
+			require.Equal(t, tc.wantLevel, entries[0].Level)
+			require.Equal(t, tc.path, entries[0].Message)
+		})
+	}
+}
+
+func TestGinLoggerObserve(t *testing.T) {
+	// Arrange
+	var gotMethod, gotRoute string
+	var gotStatus int
+	router := gin.New()
+	router.Use(GinLogger(zap.NewNop(), func(method string, route string, status int, duration time.Duration) {
+		gotMethod, gotRoute, gotStatus = method, route, status
+	}))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	request, err := http.NewRequest(http.MethodGet, "/users/123", nil)
+	require.NoError(t, err)
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	// Assert
+	require.Equal(t, http.MethodGet, gotMethod)
+	// パスではなく、ルーティングの定義を渡すこと。
+	require.Equal(t, "/users/:id", gotRoute)
+	require.Equal(t, http.StatusNoContent, gotStatus)
+}
+
+// リクエストごとのLoggerがある場合は、それを使って出力すること。
+func TestGinRecoveryUsesRequestLogger(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zapcore.DebugLevel)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		logger := zap.New(core).With(zap.String("request_id", "id"))
+		c.Request = c.Request.WithContext(WithLogger(c.Request.Context(), logger))
+	}, GinRecovery(zap.NewNop(), false, nil))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("unexpected")
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/panic", nil)
+	require.NoError(t, err)
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	entries := logs.FilterField(zap.String("request_id", "id")).All()
+	require.Len(t, entries, 1)
+	require.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+}
+
+// パニックのログに、認証情報のヘッダーを出力しないこと。
+func TestGinRecoveryRedactsHeaders(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zapcore.DebugLevel)
+	router := gin.New()
+	router.Use(GinRecovery(zap.New(core), false, nil))
+	router.POST("/panic", func(c *gin.Context) {
+		panic("unexpected")
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/panic", strings.NewReader(`{"password":"secret-password"}`))
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer abk_secret-api-key")
+	request.Header.Set("Cookie", "session=secret-session")
+	request.Header.Set("X-CSRF-Token", "secret-csrf-token")
+	request.Header.Set("User-Agent", "test-agent")
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	dump := entries[0].ContextMap()["request"].(string)
+	require.Contains(t, dump, "POST /panic")
+	require.Contains(t, dump, "test-agent")
+	require.NotContains(t, dump, "secret")
+	require.Contains(t, dump, "Authorization: "+redactedValue)
+	// 元のリクエストのヘッダーは書き換えない。
+	require.Equal(t, "Bearer abk_secret-api-key", request.Header.Get("Authorization"))
+}
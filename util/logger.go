@@ -1,27 +1,87 @@
 package util
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// zapのLoggerの初期化を行う。
+// ログのエンコーディング。
+const (
+	LogEncodingJSON    = "json"
+	LogEncodingConsole = "console"
+)
+
+// 設定を読み込む前やテストで使う、debug レベルで標準出力にJSONで出力するLoggerを作成する。
+// サーバーでは、設定に従って NewLogger で作成する。
 func InitLogger() *zap.Logger {
 
 	sy := getLogWriter()
 	encoder := getEncoder()
 
-	// TODO: 常にzapcore.DebugLevelでいいか？
 	core := zapcore.NewCore(encoder, sy, zapcore.DebugLevel)
 	lg := zap.New(core, zap.AddCaller())
 
 	return lg
 }
 
+// 設定に従って、レベル、エンコーディング、サンプリング、出力先を指定したLoggerを作成する。
+func NewLogger(config Config) (*zap.Logger, error) {
+	level, err := ParseLogLevel(config.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoder zapcore.Encoder
+	switch config.LogEncoding {
+	case LogEncodingJSON:
+		encoder = getEncoder()
+	case LogEncodingConsole:
+		encoder = zapcore.NewConsoleEncoder(getEncoderConfig())
+	default:
+		return nil, fmt.Errorf("invalid LOG_ENCODING [%s]", config.LogEncoding)
+	}
+
+	writer := getLogWriter()
+	// ファイルに出力する場合は、サイズや日数に応じてローテーションする。
+	if config.LogFile != "" {
+		writer = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   config.LogFile,
+			MaxSize:    config.LogMaxSizeMB,
+			MaxBackups: config.LogMaxBackups,
+			MaxAge:     config.LogMaxAgeDays,
+		})
+	}
+
+	core := zapcore.NewCore(encoder, writer, level)
+	// 同じメッセージが大量に出力される場合に、1秒ごとに最初の数件と、以降は一定間隔のみを出力する。
+	if config.LogSamplingInitial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, config.LogSamplingInitial, config.LogSamplingThereafter)
+	}
+
+	return zap.New(core, zap.AddCaller()), nil
+}
+
+// debug, info, warn, error などのログレベルを変換する。
+func ParseLogLevel(s string) (zapcore.Level, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return level, fmt.Errorf("invalid LOG_LEVEL [%s]", s)
+	}
+	return level, nil
+}
+
 // jsonエンコーダーの設定。
 func getEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(getEncoderConfig())
+}
+
+func getEncoderConfig() zapcore.EncoderConfig {
 
 	encoderConfig := zap.NewProductionEncoderConfig()
 
@@ -30,7 +90,7 @@ func getEncoder() zapcore.Encoder {
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
 
-	return zapcore.NewJSONEncoder(encoderConfig)
+	return encoderConfig
 }
 
 // ログの出力先を取得する。
@@ -38,3 +98,25 @@ func getLogWriter() zapcore.WriteSyncer {
 	// 標準出力に出力するようにする。
 	return zapcore.AddSync(os.Stdout)
 }
+
+// context にLoggerを保持する際のキー。
+type loggerContextKey struct{}
+
+// リクエストごとのLoggerを保持した context を返す。
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// ctx に保持されたリクエストごとのLoggerを返す。
+// 保持されていない場合は、グローバルのLoggerを返す。
+func Logger(ctx context.Context) *zap.Logger {
+	if logger, ok := loggerFromContext(ctx); ok {
+		return logger
+	}
+	return zap.L()
+}
+
+func loggerFromContext(ctx context.Context) (*zap.Logger, bool) {
+	logger, ok := RequestContext(ctx).Value(loggerContextKey{}).(*zap.Logger)
+	return logger, ok
+}
@@ -0,0 +1,152 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLogger(t *testing.T) {
+	requestLogger := zap.NewNop().With(zap.String("request_id", "id"))
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		ctx  func() context.Context
+		want *zap.Logger
+	}{
+		{
+			name: "Context",
+			ctx: func() context.Context {
+				return WithLogger(context.Background(), requestLogger)
+			},
+			want: requestLogger,
+		},
+		{
+			name: "GinContext",
+			ctx: func() context.Context {
+				c := &gin.Context{Request: request.WithContext(WithLogger(request.Context(), requestLogger))}
+				return c
+			},
+			want: requestLogger,
+		},
+		{
+			name: "FallbackToGlobal",
+			ctx: func() context.Context {
+				return context.Background()
+			},
+			want: zap.L(),
+		},
+		{
+			name: "GinContextWithoutRequest",
+			ctx: func() context.Context {
+				return &gin.Context{}
+			},
+			want: zap.L(),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			logger := Logger(tc.ctx())
+
+			// Assert
+			require.Same(t, tc.want, logger)
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(config *Config)
+		writeLog func(logger *zap.Logger)
+		checkLog func(t *testing.T, lines []string)
+	}{
+		{
+			name:   "Level",
+			modify: func(config *Config) {},
+			writeLog: func(logger *zap.Logger) {
+				logger.Debug("debug message")
+				logger.Info("info message")
+			},
+			checkLog: func(t *testing.T, lines []string) {
+				require.Len(t, lines, 1)
+				var entry map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+				require.Equal(t, "info message", entry["msg"])
+				require.Equal(t, "INFO", entry["level"])
+			},
+		},
+		{
+			name: "Console",
+			modify: func(config *Config) {
+				config.LogEncoding = LogEncodingConsole
+			},
+			writeLog: func(logger *zap.Logger) {
+				logger.Info("info message", zap.String("key", "value"))
+			},
+			checkLog: func(t *testing.T, lines []string) {
+				require.Len(t, lines, 1)
+				require.False(t, json.Valid([]byte(lines[0])))
+				require.Contains(t, lines[0], "INFO")
+				require.Contains(t, lines[0], `info message	{"key": "value"}`)
+			},
+		},
+		{
+			name: "Sampling",
+			modify: func(config *Config) {
+				config.LogSamplingInitial = 2
+				config.LogSamplingThereafter = 5
+			},
+			writeLog: func(logger *zap.Logger) {
+				for i := 0; i < 12; i++ {
+					logger.Info("repeated message")
+				}
+			},
+			checkLog: func(t *testing.T, lines []string) {
+				// 最初の2件と、以降は5件ごとに1件（7件目と12件目）。
+				require.Len(t, lines, 4)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			file := filepath.Join(t.TempDir(), "api.log")
+			config := Config{
+				LogLevel:     "info",
+				LogEncoding:  LogEncodingJSON,
+				LogFile:      file,
+				LogMaxSizeMB: 1,
+			}
+			tc.modify(&config)
+			logger, err := NewLogger(config)
+			require.NoError(t, err)
+
+			// Act
+			tc.writeLog(logger)
+			require.NoError(t, logger.Sync())
+
+			// Assert
+			data, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			tc.checkLog(t, strings.Split(strings.TrimSpace(string(data)), "\n"))
+		})
+	}
+}
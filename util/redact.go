@@ -0,0 +1,209 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// ログに出力する際の値の伏せ方を指定する、構造体のタグのキー。
+//
+//	Password string `json:"password" log:"secret"`
+const redactTagKey = "log"
+
+// log タグに指定できる値。
+const (
+	// 値を [SECRET] に置き換える。パスワードやトークン、金額など。
+	redactSecret = "secret"
+	// メールアドレスのローカル部を伏せる（t***@example.com）。
+	redactEmail = "email"
+	// 項目自体を出力しない。
+	redactOmit = "omit"
+)
+
+// 伏せた値の代わりに出力する文字列。
+const redactedValue = "[SECRET]"
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// 構造体の log タグに従って値を伏せた、ログ出力用の値を返す。
+// 返した値はJSONに変換する際に値を伏せるため、zap.Any などにそのまま渡せる。
+// 入れ子の構造体、スライス、マップ、ポインタの中の構造体も伏せる。
+func Redact(v interface{}) json.Marshaler {
+	return redacted{v: v}
+}
+
+// log タグに従って値を伏せたJSONの文字列を返す。
+// 変換に失敗した場合は空文字を返す。
+func RedactedJSONString(v interface{}) string {
+	bytes, err := json.Marshal(Redact(v))
+	if err != nil {
+		return ""
+	}
+	return string(bytes)
+}
+
+type redacted struct {
+	v interface{}
+}
+
+func (r redacted) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactValue(reflect.ValueOf(r.v)))
+}
+
+// JSONに変換した際に、構造体のフィールドの順序を保つためのオブジェクト。
+type orderedObject []objectField
+
+type objectField struct {
+	name  string
+	value interface{}
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func redactValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	// 非公開の型に埋め込まれたフィールドなど、値を取り出せないものは伏せる。
+	if !v.CanInterface() {
+		return redactedValue
+	}
+	// time.Time など、独自にJSONへ変換する型はそのまま出力する。
+	if v.Type().Implements(jsonMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem())
+	case reflect.Struct:
+		return redactStruct(v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		// []byte は encoding/json と同様に base64 の文字列にする。
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = redactValue(v.Index(i))
+		}
+		return values
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value())
+		}
+		return values
+	}
+	return v.Interface()
+}
+
+// json タグに従ってフィールドを並べ、log タグに従って値を伏せる。
+func redactStruct(v reflect.Value) orderedObject {
+	t := v.Type()
+	object := orderedObject{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// 非公開のフィールドはJSONに出力されない。
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name, opts := parseJSONTag(field.Tag.Get("json"))
+		if name == "-" && opts == "" {
+			continue
+		}
+		value := v.Field(i)
+		// 名前のない埋め込みの構造体は、encoding/json と同様にフィールドを展開する。
+		if field.Anonymous && name == "" {
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				object = append(object, redactStruct(value)...)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "omitempty") && value.IsZero() {
+			continue
+		}
+
+		switch field.Tag.Get(redactTagKey) {
+		case redactOmit:
+			continue
+		case redactSecret:
+			object = append(object, objectField{name: name, value: redactedValue})
+		case redactEmail:
+			object = append(object, objectField{name: name, value: MaskEmail(value.String())})
+		default:
+			object = append(object, objectField{name: name, value: redactValue(value)})
+		}
+	}
+	return object
+}
+
+func parseJSONTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// メールアドレスのローカル部の先頭1文字以外を伏せる。
+// 問い合わせの際にドメインと先頭の文字で絞り込めるよう、全ては伏せない。
+// log:"email" と同じ伏せ方で、構造体以外からログに出力する際に使う。
+func MaskEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redactedValue
+	}
+	_, size := utf8.DecodeRuneInString(email)
+	return email[:size] + "***" + email[at:]
+}
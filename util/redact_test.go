@@ -0,0 +1,121 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type redactTestItem struct {
+	Name  string `json:"name"`
+	Price int    `json:"price" log:"secret"`
+}
+
+type redactTestEmbedded struct {
+	Token string `json:"token" log:"secret"`
+	Kind  string `json:"kind"`
+}
+
+type redactTestRequest struct {
+	redactTestEmbedded
+	Email      string            `json:"email" log:"email"`
+	Password   string            `json:"password" log:"secret"`
+	Note       string            `json:"note,omitempty"`
+	Internal   string            `json:"internal" log:"omit"`
+	Ignored    string            `json:"-"`
+	Items      []redactTestItem  `json:"items"`
+	Owner      *redactTestItem   `json:"owner"`
+	Labels     map[string]string `json:"labels"`
+	CreatedAt  time.Time         `json:"created_at"`
+	unexported string
+}
+
+func TestRedactedJSONString(t *testing.T) {
+	createdAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name: "Struct",
+			input: redactTestRequest{
+				redactTestEmbedded: redactTestEmbedded{Token: "token", Kind: "kind"},
+				Email:              "this.is.test@example.com",
+				Password:           "password",
+				Internal:           "internal",
+				Ignored:            "ignored",
+				Items:              []redactTestItem{{Name: "apple", Price: 100}},
+				Owner:              &redactTestItem{Name: "owner", Price: 200},
+				Labels:             map[string]string{"key": "value"},
+				CreatedAt:          createdAt,
+				unexported:         "unexported",
+			},
+			// フィールドの順序は構造体の定義順。
+			expected: `{"token":"[SECRET]","kind":"kind","email":"t***@example.com","password":"[SECRET]",` +
+				`"items":[{"name":"apple","price":"[SECRET]"}],"owner":{"name":"owner","price":"[SECRET]"},` +
+				`"labels":{"key":"value"},"created_at":"2022-05-01T12:00:00Z"}`,
+		},
+		{
+			name:     "Pointer",
+			input:    &redactTestItem{Name: "apple", Price: 100},
+			expected: `{"name":"apple","price":"[SECRET]"}`,
+		},
+		{
+			name:     "Slice",
+			input:    []redactTestItem{{Name: "apple", Price: 100}},
+			expected: `[{"name":"apple","price":"[SECRET]"}]`,
+		},
+		{
+			name:     "InvalidEmail",
+			input:    redactTestRequest{Email: "invalid"},
+			expected: `{"token":"[SECRET]","kind":"","email":"[SECRET]","password":"[SECRET]","items":null,"owner":null,"labels":null,"created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:     "Nil",
+			input:    nil,
+			expected: `null`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := RedactedJSONString(tc.input)
+
+			// Assert
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	require.Equal(t, "t***@example.com", MaskEmail("test@example.com"))
+	require.Equal(t, "テ***@example.com", MaskEmail("テスト@example.com"))
+	require.Equal(t, redactedValue, MaskEmail("@example.com"))
+	require.Equal(t, "", MaskEmail(""))
+}
+
+// zap のフィールドとして渡した場合も、値が伏せられること。
+func TestRedactWithZap(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	// Act
+	logger.Info("request", zap.Any("body", Redact(redactTestItem{Name: "apple", Price: 100})))
+
+	// Assert
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	body, err := entries[0].ContextMap()["body"].(interface{ MarshalJSON() ([]byte, error) }).MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"apple","price":"[SECRET]"}`, string(body))
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"go.uber.org/zap"
+)
+
+// １回の実行で削除するアカウントの最大数。
+// 削除対象が溜まっていても、１回の処理が長引かないようにする。
+const accountDeletionBatchSize = 100
+
+// 削除の猶予期間を過ぎたアカウントの個人データを、定期的に削除するワーカー。
+type AccountDeletionWorker struct {
+	periodic
+	store db.Store
+}
+
+// AccountDeletionWorker を作成する。
+func NewAccountDeletionWorker(store db.Store, interval time.Duration, logger *zap.Logger) *AccountDeletionWorker {
+	return &AccountDeletionWorker{
+		periodic: periodic{
+			interval: interval,
+			logger:   logger,
+		},
+		store: store,
+	}
+}
+
+// ctx がキャンセルされるまで、一定間隔で削除処理を実行する。
+func (worker *AccountDeletionWorker) Run(ctx context.Context) {
+	worker.run(ctx, "failed to delete accounts", func(ctx context.Context, now time.Time) error {
+		_, err := worker.RunOnce(ctx, now)
+		return err
+	})
+}
+
+// now の時点で猶予期間を過ぎたアカウントを削除し、削除した件数を返す。
+// 一部のアカウントの削除に失敗しても、残りのアカウントの削除は続ける。
+func (worker *AccountDeletionWorker) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	arg := db.ListUsersDueForDeletionParams{
+		Now:      now,
+		MaxUsers: accountDeletionBatchSize,
+	}
+	users, err := worker.store.ListUsersDueForDeletion(ctx, arg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to ListUsersDueForDeletion: %w", err)
+	}
+
+	deleted := 0
+	var lastErr error
+	for _, user := range users {
+		if err := worker.store.DeleteUserTx(ctx, user.ID); err != nil {
+			lastErr = fmt.Errorf("failed to DeleteUserTx for user [%d]: %w", user.ID, err)
+			worker.logger.Error("failed to delete account", zap.Int64("user_id", user.ID), zap.Error(err))
+			continue
+		}
+		deleted++
+		worker.logger.Info("account was deleted", zap.Int64("user_id", user.ID))
+	}
+	return deleted, lastErr
+}
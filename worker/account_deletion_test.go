@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAccountDeletionWorkerRunOnce(t *testing.T) {
+	now := time.Now()
+	users := []db.User{
+		{ID: 1},
+		{ID: 2},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		expectDeleted int
+		expectError   bool
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUsersDueForDeletionParams{
+					Now:      now,
+					MaxUsers: accountDeletionBatchSize,
+				}
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(users, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(len(users)).
+					Return(nil)
+			},
+			expectDeleted: len(users),
+		},
+		{
+			name: "ContinueAfterError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(users, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(users[0].ID)).
+					Times(1).
+					Return(sql.ErrConnDone)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(users[1].ID)).
+					Times(1).
+					Return(nil)
+			},
+			expectDeleted: 1,
+			expectError:   true,
+		},
+		{
+			name: "DBErrorWhenListUsersDueForDeletion",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.User{}, sql.ErrConnDone)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectDeleted: 0,
+			expectError:   true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			worker := NewAccountDeletionWorker(store, time.Hour, zap.NewNop())
+
+			// Act
+			deleted, err := worker.RunOnce(context.Background(), now)
+
+			// Assert
+			require.Equal(t, tc.expectDeleted, deleted)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAccountDeletionWorkerStatus(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	ran := make(chan struct{})
+	store.EXPECT().
+		ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.ListUsersDueForDeletionParams) ([]db.User, error) {
+			close(ran)
+			return []db.User{}, sql.ErrConnDone
+		})
+
+	worker := NewAccountDeletionWorker(store, time.Hour, zap.NewNop())
+	require.False(t, worker.Status().Running)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+	<-ran
+	// 1回目の処理の結果が記録されるまで待つ。
+	require.Eventually(t, func() bool {
+		return !worker.Status().LastRunAt.IsZero()
+	}, time.Second, 10*time.Millisecond)
+	running := worker.Status()
+	cancel()
+	<-done
+
+	// Assert
+	require.True(t, running.Running)
+	require.ErrorIs(t, running.LastError, sql.ErrConnDone)
+	require.False(t, worker.Status().Running)
+}
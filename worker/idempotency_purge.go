@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"go.uber.org/zap"
+)
+
+// 有効期限を過ぎた Idempotency-Key を、定期的に削除するワーカー。
+type IdempotencyKeyPurgeWorker struct {
+	periodic
+	store db.Store
+}
+
+// IdempotencyKeyPurgeWorker を作成する。
+func NewIdempotencyKeyPurgeWorker(store db.Store, interval time.Duration, logger *zap.Logger) *IdempotencyKeyPurgeWorker {
+	return &IdempotencyKeyPurgeWorker{
+		periodic: periodic{
+			interval: interval,
+			logger:   logger,
+		},
+		store: store,
+	}
+}
+
+// ctx がキャンセルされるまで、一定間隔で削除処理を実行する。
+func (worker *IdempotencyKeyPurgeWorker) Run(ctx context.Context) {
+	worker.run(ctx, "failed to purge idempotency keys", func(ctx context.Context, now time.Time) error {
+		_, err := worker.RunOnce(ctx, now)
+		return err
+	})
+}
+
+// now の時点で有効期限を過ぎた Idempotency-Key を削除し、削除した件数を返す。
+func (worker *IdempotencyKeyPurgeWorker) RunOnce(ctx context.Context, now time.Time) (int64, error) {
+	n, err := worker.store.PurgeExpiredIdempotencyKeys(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to PurgeExpiredIdempotencyKeys: %w", err)
+	}
+	if n > 0 {
+		worker.logger.Info("idempotency keys were purged", zap.Int64("count", n))
+	}
+	return n, nil
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyKeyPurgeWorkerRunOnce(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		buildStubs  func(store *mockdb.MockStore)
+		expectCount int64
+		expectError bool
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeExpiredIdempotencyKeys(gomock.Any(), gomock.Eq(now)).
+					Times(1).
+					Return(int64(5), nil)
+			},
+			expectCount: 5,
+		},
+		{
+			name: "Empty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeExpiredIdempotencyKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+		},
+		{
+			name: "DBError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeExpiredIdempotencyKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			expectError: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			worker := NewIdempotencyKeyPurgeWorker(store, time.Hour, zap.NewNop())
+
+			// Act
+			count, err := worker.RunOnce(context.Background(), now)
+
+			// Assert
+			require.Equal(t, tc.expectCount, count)
+			if tc.expectError {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
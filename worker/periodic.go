@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ワーカーの状態。readyz などで外部に公開する。
+type Status struct {
+	// Run を実行中か。
+	Running bool
+	// 最後に処理を実行した時刻。一度も実行していない場合はゼロ値。
+	LastRunAt time.Time
+	// 最後の処理のエラー。成功した場合は nil。
+	LastError error
+}
+
+// 一定間隔で処理を実行し、その状態を記録する。
+// 各ワーカーに埋め込み、Status を公開する。
+type periodic struct {
+	interval time.Duration
+	logger   *zap.Logger
+
+	// 別のgoroutineから Status で参照されるため、mu で保護する。
+	mu     sync.Mutex
+	status Status
+}
+
+// ctx がキャンセルされるまで、一定間隔で fn を実行する。
+// fn がエラーを返した場合は、errMsg と共にログに出力する。
+func (p *periodic) run(ctx context.Context, errMsg string, fn func(ctx context.Context, now time.Time) error) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.setRunning(true)
+	defer p.setRunning(false)
+
+	for {
+		now := time.Now()
+		err := fn(ctx, now)
+		if err != nil {
+			p.logger.Error(errMsg, zap.Error(err))
+		}
+		p.mu.Lock()
+		p.status.LastRunAt = now
+		p.status.LastError = err
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ワーカーの現在の状態を返す。
+func (p *periodic) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+func (p *periodic) setRunning(running bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Running = running
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"go.uber.org/zap"
+)
+
+// レプリカに接続できるかを定期的に確かめ、ReplicaStore の振り分けに反映するワーカー。
+type ReplicaHealthWorker struct {
+	periodic
+	store *db.ReplicaStore
+}
+
+// ReplicaHealthWorker を作成する。
+func NewReplicaHealthWorker(store *db.ReplicaStore, interval time.Duration, logger *zap.Logger) *ReplicaHealthWorker {
+	return &ReplicaHealthWorker{
+		periodic: periodic{
+			interval: interval,
+			logger:   logger,
+		},
+		store: store,
+	}
+}
+
+// ctx がキャンセルされるまで、一定間隔でレプリカを確認する。
+func (worker *ReplicaHealthWorker) Run(ctx context.Context) {
+	worker.run(ctx, "replica is unhealthy", func(ctx context.Context, now time.Time) error {
+		return worker.RunOnce(ctx)
+	})
+}
+
+// レプリカを確認する。異常の間、参照のクエリはプライマリで実行される。
+func (worker *ReplicaHealthWorker) RunOnce(ctx context.Context) error {
+	wasHealthy := worker.store.ReplicaHealthy()
+	if err := worker.store.CheckReplica(ctx); err != nil {
+		return fmt.Errorf("failed to ping replica: %w", err)
+	}
+	if !wasHealthy {
+		worker.logger.Info("replica recovered")
+	}
+	return nil
+}
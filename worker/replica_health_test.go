@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReplicaHealthWorkerRunOnce(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(replica *mockdb.MockStore)
+		expectError   bool
+		expectHealthy bool
+	}{
+		{
+			name: "OK",
+			buildStubs: func(replica *mockdb.MockStore) {
+				replica.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			expectHealthy: true,
+		},
+		{
+			name: "Unhealthy",
+			buildStubs: func(replica *mockdb.MockStore) {
+				replica.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			expectError: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			replica := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(replica)
+			store := db.NewReplicaStore(mockdb.NewMockStore(ctrl), replica)
+			worker := NewReplicaHealthWorker(store, time.Minute, zap.NewNop())
+
+			// Act
+			err := worker.RunOnce(context.Background())
+
+			// Assert
+			if tc.expectError {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectHealthy, store.ReplicaHealthy())
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"go.uber.org/zap"
+)
+
+// ゴミ箱での保持期間を過ぎた支出・レシート・カテゴリーを、定期的に完全に削除するワーカー。
+type TrashPurgeWorker struct {
+	periodic
+	store     db.Store
+	retention time.Duration
+}
+
+// TrashPurgeWorker を作成する。
+func NewTrashPurgeWorker(store db.Store, retention, interval time.Duration, logger *zap.Logger) *TrashPurgeWorker {
+	return &TrashPurgeWorker{
+		periodic: periodic{
+			interval: interval,
+			logger:   logger,
+		},
+		store:     store,
+		retention: retention,
+	}
+}
+
+// ctx がキャンセルされるまで、一定間隔で削除処理を実行する。
+func (worker *TrashPurgeWorker) Run(ctx context.Context) {
+	worker.run(ctx, "failed to purge trash", func(ctx context.Context, now time.Time) error {
+		_, err := worker.RunOnce(ctx, now)
+		return err
+	})
+}
+
+// now の時点で保持期間を過ぎたものをゴミ箱から削除し、削除した件数を返す。
+func (worker *TrashPurgeWorker) RunOnce(ctx context.Context, now time.Time) (db.PurgeTrashTxResult, error) {
+	result, err := worker.store.PurgeTrashTx(ctx, now.Add(-worker.retention))
+	if err != nil {
+		return result, fmt.Errorf("failed to PurgeTrashTx: %w", err)
+	}
+	if result != (db.PurgeTrashTxResult{}) {
+		worker.logger.Info("trash was purged",
+			zap.Int64("expenses", result.Expenses),
+			zap.Int64("food_receipts", result.FoodReceipts),
+			zap.Int64("categories", result.Categories),
+		)
+	}
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/kokoichi206/account-book-api/db/mock"
+	db "github.com/kokoichi206/account-book-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTrashPurgeWorkerRunOnce(t *testing.T) {
+	now := time.Now()
+	retention := 30 * 24 * time.Hour
+	purged := db.PurgeTrashTxResult{Expenses: 3, FoodReceipts: 1, Categories: 2}
+
+	testCases := []struct {
+		name         string
+		buildStubs   func(store *mockdb.MockStore)
+		expectResult db.PurgeTrashTxResult
+		expectError  bool
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeTrashTx(gomock.Any(), gomock.Eq(now.Add(-retention))).
+					Times(1).
+					Return(purged, nil)
+			},
+			expectResult: purged,
+		},
+		{
+			name: "Empty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeTrashTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PurgeTrashTxResult{}, nil)
+			},
+		},
+		{
+			name: "DBError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PurgeTrashTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PurgeTrashTxResult{}, sql.ErrConnDone)
+			},
+			expectError: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			worker := NewTrashPurgeWorker(store, retention, time.Hour, zap.NewNop())
+
+			// Act
+			result, err := worker.RunOnce(context.Background(), now)
+
+			// Assert
+			require.Equal(t, tc.expectResult, result)
+			if tc.expectError {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}